@@ -0,0 +1,9 @@
+package adapter
+
+import "context"
+
+// AlertNotifier 告警通知接口，由飞书、钉钉、Slack 机器人实现
+type AlertNotifier interface {
+	// Notify 发送一条告警，key 相同的告警在最短发送间隔内只发送一次
+	Notify(ctx context.Context, key, title, content string) error
+}
@@ -1,11 +1,58 @@
 package adapter
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 短信服务商返回的错误，各服务商实现统一映射为以下错误，其余错误原样返回
+var (
+	// ErrSMSInvalidPhone 手机号格式错误或不是可接收短信的号码
+	ErrSMSInvalidPhone = errors.New("sms invalid phone number")
+	// ErrSMSRateLimited 服务商侧对该号码的发送频率限制
+	ErrSMSRateLimited = errors.New("sms rate limited by provider")
+)
 
 // CodeService 验证码服务接口，支持邮件与短信
 type CodeService interface {
 	// SendEmailCode 发送邮件验证码
-	SendEmailCode(ctx context.Context, email, code string) error
+	SendEmailCode(ctx context.Context, email string, msg *VerificationEmail) error
 	// SendSMSCode 发送短信验证码
 	SendSMSCode(ctx context.Context, phone, code string) error
+	// SendContactReminder 发送绑定备用联系方式的提醒邮件
+	SendContactReminder(ctx context.Context, email string, reminder *ContactReminder) error
+	// SendMagicLink 发送免密登录链接邮件
+	SendMagicLink(ctx context.Context, email string, msg *MagicLinkEmail) error
+	// SendDataExportReady 发送数据导出完成的通知邮件
+	SendDataExportReady(ctx context.Context, email string, msg *DataExportEmail) error
+}
+
+// VerificationEmail 验证码邮件内容，按用途与语言选择模板
+type VerificationEmail struct {
+	Code      string
+	Purpose   string        // 使用场景，与 types.Purpose* 对应，为空时使用通用模板
+	Language  string        // 收件人语言，如 zh、en，为空时使用默认语言
+	ExpiresIn time.Duration // 验证码有效期
+}
+
+// ContactReminder 提醒邮件内容
+type ContactReminder struct {
+	UserName       string
+	BindURL        string // 绑定联系方式的页面地址，为空时邮件中不展示按钮
+	UnsubscribeURL string // 退订链接
+}
+
+// MagicLinkEmail 免密登录邮件内容，按语言选择模板
+type MagicLinkEmail struct {
+	URL       string        // 带一次性令牌的登录链接
+	Language  string        // 收件人语言，如 zh、en，为空时使用默认语言
+	ExpiresIn time.Duration // 链接有效期
+}
+
+// DataExportEmail 数据导出完成邮件内容，按语言选择模板
+type DataExportEmail struct {
+	URL       string        // 导出文件的下载链接
+	Language  string        // 收件人语言，如 zh、en，为空时使用默认语言
+	ExpiresIn time.Duration // 链接有效期
 }
@@ -2,13 +2,50 @@ package adapter
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
+)
 
-	sts "github.com/tencentyun/qcloud-cos-sts-sdk/go"
+var (
+	// ErrFileNotFound 存储中的文件不存在
+	ErrFileNotFound = errors.New("cos file not found")
+	// ErrStorageNotSupported 当前存储服务不支持该操作，如本地存储不能颁发临时凭证
+	ErrStorageNotSupported = errors.New("operation not supported by storage driver")
 )
 
-// COSService 定义COS服务的业务接口
+// CredentialPolicy 临时凭证的权限范围
+type CredentialPolicy struct {
+	ResourcePath string   // 允许访问的资源路径，以 * 结尾时为该前缀下的所有文件
+	Actions      []string // 允许的操作：put、get、multipart，见 configs.STSAction*
+}
+
+// TemporaryCredentials 前端直传使用的临时凭证，只允许访问指定的资源路径
+type TemporaryCredentials struct {
+	SecretID     string
+	SecretKey    string
+	SessionToken string
+	Expiration   time.Time
+	Provider     string // 存储服务：cos、s3、minio、oss
+	Region       string
+	Bucket       string
+	Endpoint     string // 服务地址，cos 为空
+	BaseURL      string // 文件访问地址前缀
+	AccountID    string // cos 的 AppID，其他存储服务为空
+}
+
+// FileObject COS文件的内容流，读取完成后需要关闭 Body
+type FileObject struct {
+	Body          io.ReadCloser
+	ContentType   string
+	ContentLength int64
+}
+
+// COSService 对象存储服务接口，由 infra/cos 按配置的 driver 选择实现（cos、s3、minio、oss、local）
 type COSService interface {
-	GetTemporaryCredentials(resourcePath string, durationSeconds int64) (*sts.CredentialResult, error)
+	// GetTemporaryCredentials 颁发只能按 policy 访问指定资源的临时凭证
+	// 不支持临时凭证的存储服务返回 ErrStorageNotSupported
+	GetTemporaryCredentials(ctx context.Context, policy CredentialPolicy, durationSeconds int64) (*TemporaryCredentials, error)
 
 	// UploadFile 上传文件到COS
 	// resourcePath: 存储路径，如 "user/123/avatar/avatar.jpg"
@@ -16,4 +53,19 @@ type COSService interface {
 	// contentType: 文件类型，如 "image/jpeg"
 	// 返回: 完整URL
 	UploadFile(ctx context.Context, resourcePath string, fileData []byte, contentType string) (string, error)
+
+	// DeleteFile 删除COS上的文件
+	// resourcePath: 存储路径，对象不存在时不报错
+	DeleteFile(ctx context.Context, resourcePath string) error
+
+	// DownloadFile 下载COS上的文件内容
+	DownloadFile(ctx context.Context, resourcePath string) ([]byte, error)
+
+	// GetPresignedURL 生成私有文件的临时下载链接
+	// expires: 链接有效期
+	GetPresignedURL(ctx context.Context, resourcePath string, expires time.Duration) (string, error)
+
+	// OpenFile 以流的方式读取COS上的文件，用于转发下载大文件
+	// 文件不存在时返回 ErrFileNotFound
+	OpenFile(ctx context.Context, resourcePath string) (*FileObject, error)
 }
@@ -1,18 +1,23 @@
 package adapter
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrCozeNotConfigured 未配置 coze token 时调用工作流返回该错误
+var ErrCozeNotConfigured = errors.New("coze not configured")
 
 type RunWorkflowReq struct {
-	WorkflowID string `json:"workflow_id"`
-	AK         string `json:"ak"`
-	SK         string `json:"sk"`
-	// ... extra
+	WorkflowID string         `json:"workflow_id"`
+	Parameters map[string]any `json:"parameters,omitempty"` // 工作流开始节点的输入参数
 }
 type RunWorkflowResult struct {
-	Result string `json:"result"`
+	Result   string `json:"result"`    // 工作流输出，通常是一段 JSON 字符串
+	DebugURL string `json:"debug_url"` // coze 控制台上的调试链接，便于排查
 }
 
-// 这边我随便yy了个service，实际我们项目根本不需要
+// CozeService 第三方工作流服务
 // 这里展示了面对第三方接口时 api rpc 或者sdk包装 我们如何处理的
 type CozeService interface {
 	RunWorkflow(ctx context.Context, req *RunWorkflowReq) (*RunWorkflowResult, error)
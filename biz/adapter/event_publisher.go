@@ -0,0 +1,13 @@
+package adapter
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// EventPublisher 领域事件投递接口，由 Redis Stream 等消息通道实现
+type EventPublisher interface {
+	// Publish 投递一条事件，返回 nil 表示消息通道已持久化该事件
+	Publish(ctx context.Context, event *entity.OutboxEvent) error
+}
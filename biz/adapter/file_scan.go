@@ -0,0 +1,16 @@
+package adapter
+
+import "context"
+
+// FileScanResult 病毒扫描结果
+type FileScanResult struct {
+	Infected  bool
+	Source    string // 判定来源，如 clamav、http
+	Signature string // 命中的病毒特征名
+}
+
+// FileScanner 上传文件的病毒扫描接口，可由 ClamAV 或云端扫描服务实现
+type FileScanner interface {
+	// Scan 扫描文件内容，扫描服务本身出错时返回 error
+	Scan(ctx context.Context, filename string, data []byte) (*FileScanResult, error)
+}
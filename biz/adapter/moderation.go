@@ -0,0 +1,16 @@
+package adapter
+
+import "context"
+
+// ModerationResult 内容审核结果
+type ModerationResult struct {
+	Blocked  bool
+	Source   string // 判定来源，如 keyword、openai
+	Category string // 违规类别，关键词命中时为命中的关键词
+}
+
+// ModerationService 内容审核接口，可由本地关键词或第三方审核服务实现
+type ModerationService interface {
+	// Check 审核一段文本，审核服务本身出错时返回 error
+	Check(ctx context.Context, text string) (*ModerationResult, error)
+}
@@ -0,0 +1,41 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOAuthCodeInvalid 授权码无效或已使用，各平台实现统一返回该错误
+var ErrOAuthCodeInvalid = errors.New("oauth authorization code invalid")
+
+// OAuthUser 第三方平台返回的用户信息
+type OAuthUser struct {
+	Provider    string
+	ProviderUID string // 平台内的唯一标识，用于关联本地用户
+	OpenID      string
+	NickName    string
+	Avatar      string
+	Email       string // 平台提供且已验证的邮箱，没有时为空
+}
+
+// OAuthToken 授权码换取的访问令牌
+type OAuthToken struct {
+	AccessToken string
+	OpenID      string // 微信在换取令牌时返回用户标识，获取用户信息时需要带上
+	UnionID     string
+}
+
+// OAuthProvider 第三方登录平台，新增平台只需实现该接口并在 infra/oauth 中按配置注册
+type OAuthProvider interface {
+	// Name 平台名称，与 entity.OAuthProvider* 对应
+	Name() string
+
+	// AuthURL 构造跳转到第三方授权页面的地址，state 原样回传用于防 CSRF
+	AuthURL(state string) string
+
+	// Exchange 用授权码换取访问令牌，授权码无效时返回 ErrOAuthCodeInvalid
+	Exchange(ctx context.Context, code string) (*OAuthToken, error)
+
+	// GetUserInfo 用访问令牌获取第三方用户信息
+	GetUserInfo(ctx context.Context, token *OAuthToken) (*OAuthUser, error)
+}
@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidPaymentSignature 支付回调签名校验失败，各支付渠道实现统一返回该错误
+var ErrInvalidPaymentSignature = errors.New("invalid payment webhook signature")
+
+// 支付事件类型，由各支付渠道的事件归一化而来
+const (
+	PaymentEventPaid    = "paid"    // 支付成功
+	PaymentEventFailed  = "failed"  // 支付失败
+	PaymentEventExpired = "expired" // 支付会话过期
+)
+
+type CheckoutReq struct {
+	OrderID     string // 业务侧订单号（账单ID），回调时原样带回
+	ProductName string // 展示在支付页面上的商品名
+	Amount      int64  // 金额，最小货币单位（如分）
+	Currency    string // 币种，如 cny
+	SuccessURL  string // 支付成功后跳转地址
+	CancelURL   string // 取消支付后跳转地址
+}
+
+type CheckoutSession struct {
+	SessionID string    // 支付渠道侧的会话ID
+	URL       string    // 支付页面地址
+	ExpiresAt time.Time // 会话过期时间
+}
+
+type PaymentEvent struct {
+	EventID   string // 支付渠道侧的事件ID
+	Type      string // 归一化后的事件类型，不关心的事件为空
+	OrderID   string // 业务侧订单号
+	SessionID string // 支付渠道侧的会话ID
+}
+
+// PaymentService 支付渠道接口，目前实现了 Stripe，可扩展微信支付等
+type PaymentService interface {
+	// Provider 支付渠道名称
+	Provider() string
+
+	// CreateCheckoutSession 创建支付会话，返回支付页面地址
+	CreateCheckoutSession(ctx context.Context, req *CheckoutReq) (*CheckoutSession, error)
+
+	// ParseWebhookEvent 校验回调签名并解析事件
+	ParseWebhookEvent(ctx context.Context, payload []byte, signature string) (*PaymentEvent, error)
+}
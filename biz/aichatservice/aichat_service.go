@@ -3,62 +3,211 @@ package aichatservice
 import (
 	"context"
 	"errors"
+	"fmt"
+	"forge/biz/adapter"
 	"forge/biz/entity"
 	"forge/biz/repo"
 	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/pkg/docparse"
 	"forge/pkg/log/zlog"
-	"forge/util"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ConversationRestoreWindow 软删除后可恢复的期限，超过期限的会话由清理任务彻底删除
+	ConversationRestoreWindow = 7 * 24 * time.Hour
+	// forkTitleSuffix 分支会话未指定标题时在原标题后追加
+	forkTitleSuffix = "（分支）"
+	// summaryTitleSuffix 总结导图时新建会话的标题在导图标题后追加
+	summaryTitleSuffix = "（总结）"
+	// summaryRequestMessage 总结导图时保存到会话中的用户消息
+	summaryRequestMessage = "总结这张导图"
+	// maxSearchKeywordLength 搜索关键词的最大字符数
+	maxSearchKeywordLength = 100
+	// searchSnippetRadius 搜索结果摘要中关键词前后保留的字符数
+	searchSnippetRadius = 40
+	// maxReorderConversations 一次调整顺序的会话数上限
+	maxReorderConversations = 500
 )
 
 var (
-	CONVERSATION_ID_NOT_NULL    = errors.New("会话ID不能为空")
-	USER_ID_NOT_NULL            = errors.New("用户ID不能为空")
-	MAP_ID_NOT_NULL             = errors.New("导图ID不能为空")
-	CONVERSATION_TITLE_NOT_NULL = errors.New("会话标题不能为空")
-	CONVERSATION_NOT_EXIST      = errors.New("该会话不存在")
-	AI_CHAT_PERMISSION_DENIED   = errors.New("会话权限不足")
-	MIND_MAP_NOT_EXIST          = errors.New("该导图不存在")
+	CONVERSATION_ID_NOT_NULL     = errors.New("会话ID不能为空")
+	USER_ID_NOT_NULL             = errors.New("用户ID不能为空")
+	MAP_ID_NOT_NULL              = errors.New("导图ID不能为空")
+	CONVERSATION_TITLE_NOT_NULL  = errors.New("会话标题不能为空")
+	CONVERSATION_NOT_EXIST       = errors.New("该会话不存在")
+	AI_CHAT_PERMISSION_DENIED    = errors.New("会话权限不足")
+	MIND_MAP_NOT_EXIST           = errors.New("该导图不存在")
+	CONVERSATION_RESTORE_EXPIRED = errors.New("会话已超过可恢复期限")
+	AI_MODEL_NOT_SUPPORTED       = errors.New("不支持该模型")
+	MESSAGE_NOT_EXIST            = errors.New("该消息不存在")
+	CONVERSATION_MAP_MISMATCH    = errors.New("该会话不属于该导图")
+	CONTENT_INPUT_BLOCKED        = errors.New("消息包含违规内容")
+	CONTENT_OUTPUT_BLOCKED       = errors.New("AI回复未通过内容审核")
+	SEARCH_KEYWORD_INVALID       = errors.New("搜索关键词不能为空且不超过100个字符")
+	CONVERSATION_ORDER_INVALID   = errors.New("会话顺序中有重复或不属于该导图的会话")
+	AI_VISION_NOT_SUPPORTED      = errors.New("当前模型不支持图片输入")
+	AI_IMAGE_INVALID             = errors.New("图片需提供 key 或有效的 base64 内容")
+	AI_IMAGE_LIMIT_EXCEEDED      = errors.New("图片数量或大小超出限制")
 )
 
 type AiChatService struct {
-	aiChatRepo repo.AiChatRepo
-	einoServer repo.EinoServer
+	aiChatRepo     repo.AiChatRepo
+	einoServer     repo.EinoServer
+	quotaService   types.IQuotaService
+	mindMapService types.IMindMapService
+	presetService  types.IPromptPresetService
+	cosService     types.ICOSService
+	moderation     adapter.ModerationService // 为 nil 时不审核
+	auditService   types.IAuditService
+	config         configs.AiChatConfig
+}
+
+func NewAiChatService(aiChatRepo repo.AiChatRepo, einoServer repo.EinoServer, quotaService types.IQuotaService, mindMapService types.IMindMapService,
+	presetService types.IPromptPresetService, cosService types.ICOSService, moderation adapter.ModerationService, auditService types.IAuditService,
+	cfg configs.AiChatConfig) *AiChatService {
+	return &AiChatService{aiChatRepo: aiChatRepo, einoServer: einoServer, quotaService: quotaService, mindMapService: mindMapService,
+		presetService: presetService, cosService: cosService, moderation: moderation, auditService: auditService, config: cfg}
 }
 
-func NewAiChatService(aiChatRepo repo.AiChatRepo, einoServer repo.EinoServer) *AiChatService {
-	return &AiChatService{aiChatRepo: aiChatRepo, einoServer: einoServer}
+// acquireAI 调用AI前先占用并发名额，再校验 token 用量、消耗套餐的调用次数
+// 配额不足时立即释放名额；成功后调用方需在AI调用结束后调用 release，AI调用失败时调用 refund 退还调用次数
+func (a *AiChatService) acquireAI(ctx context.Context) (release func(), refund func(), err error) {
+	release, err = a.quotaService.AcquireAISlot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := a.quotaService.CheckAITokenQuota(ctx); err != nil {
+		release()
+		return nil, nil, err
+	}
+	refund, err = a.quotaService.ConsumeAIQuota(ctx)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return release, refund, nil
 }
 
 func (a *AiChatService) ProcessUserMessage(ctx context.Context, req *types.ProcessUserMessageParams) (types.AgentResponse, error) {
+	return a.processUserMessage(ctx, req, a.einoServer.SendMessage)
+}
+
+// sendFunc 调用ai获取回复，区分普通与流式调用
+type sendFunc func(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams) (types.AgentResponse, error)
+
+// ProcessUserMessageStream 流式处理用户消息，模型输出结束后与非流式一样保存完整的聊天记录
+// onDelta 出错（如客户端断开）时中止调用，本轮消息不保存
+func (a *AiChatService) ProcessUserMessageStream(ctx context.Context, req *types.ProcessUserMessageParams, onDelta func(delta string) error) (types.AgentResponse, error) {
+	return a.processUserMessage(ctx, req, func(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams) (types.AgentResponse, error) {
+		return a.einoServer.StreamMessage(ctx, modelName, messages, params, onDelta)
+	})
+}
+
+// processUserMessage 校验会话、占用ai名额后调用 send 获取ai回复，并保存本轮聊天记录
+func (a *AiChatService) processUserMessage(ctx context.Context, req *types.ProcessUserMessageParams, send sendFunc) (types.AgentResponse, error) {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
 		return types.AgentResponse{}, AI_CHAT_PERMISSION_DENIED
 	}
 
+	if !a.einoServer.SupportModel(req.Model) {
+		zlog.CtxWarnf(ctx, "不支持的模型: %s", req.Model)
+		return types.AgentResponse{}, AI_MODEL_NOT_SUPPORTED
+	}
+
+	//消息附带图片时需要支持图片的提供方，所选提供方不支持时改用配置的提供方
+	visionModel, vision := a.einoServer.VisionModel(req.Model)
+	if err := a.checkImages(ctx, req.Images, vision); err != nil {
+		return types.AgentResponse{}, err
+	}
+
 	conversation, err := a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
 	if err != nil {
 		return types.AgentResponse{}, err
 	}
 
-	//更新导图提示词
+	//更新导图提示词 会话选择了预设时加上预设的系统提示词
+	//模型参数优先使用请求指定的值，其次是预设的值，最后是配置的默认值
 	conversation.ProcessSystemPrompt(req.MapData)
+	params := a.defaultModelParams()
+	if preset := a.conversationPreset(ctx, conversation); preset != nil {
+		conversation.ApplyPersona(preset.SystemPrompt)
+		params = params.Merge(preset.Params)
+	}
+	params = params.Merge(req.Params).Clamp(a.config.ModelParams.MaxTokensCap())
+
+	//审核用户输入 未通过时不调用ai也不保存
+	if err := a.moderate(ctx, conversation.ConversationID, moderationSceneInput, req.Message); err != nil {
+		return types.AgentResponse{}, err
+	}
 
 	//添加用户聊天记录
-	conversation.AddMessage(req.Message, entity.USER, "", nil)
+	userMessage := conversation.AddMessage(req.Message, entity.USER, "", nil)
+
+	//占用ai并发名额并消耗套餐的ai调用次数
+	release, refund, err := a.acquireAI(ctx)
+	if err != nil {
+		return types.AgentResponse{}, err
+	}
+	defer release()
 
-	//调用ai 返回ai消息
-	aiMsg, err := a.einoServer.SendMessage(ctx, conversation.Messages)
+	//保存本条消息附带的图片
+	if len(req.Images) > 0 {
+		if userMessage.Images, err = a.resolveImages(ctx, conversation.ConversationID, req.Images); err != nil {
+			refund()
+			return types.AgentResponse{}, err
+		}
+	}
+
+	//调用ai 返回ai消息 只发送最近的一段聊天记录
+	//聊天记录中有图片时交给支持图片的提供方，没有支持图片的提供方时只发送文字
+	maxMessages, maxTokens := a.config.HistoryWindow()
+	window := conversation.HistoryWindow(maxMessages, maxTokens)
+	modelName := req.Model
+	if vision && a.loadHistoryImages(ctx, window) {
+		modelName = visionModel
+	}
+	aiMsg, err := send(ctx, modelName, window, params)
 	if err != nil {
+		//ai调用失败（含流式输出中断）不计入调用次数
+		refund()
+		return types.AgentResponse{}, err
+	}
+	a.quotaService.RecordAITokenUsage(ctx, entity.AiUsageSceneChat, aiMsg.Provider, aiMsg.Model, aiMsg.Usage)
+
+	//审核ai回复 未通过时本轮对话不保存，导图操作也不应用
+	//流式输出时文本片段已发给客户端，由客户端在收到错误后撤回
+	if err := a.moderate(ctx, conversation.ConversationID, moderationSceneOutput, aiMsg.Content); err != nil {
 		return types.AgentResponse{}, err
 	}
 
-	//添加ai消息
-	conversation.AddMessage(aiMsg.Content, entity.ASSISTANT, "", aiMsg.ToolCalls)
+	//添加ai消息 记录实际使用的提供方
+	message := conversation.AddMessage(aiMsg.Content, entity.ASSISTANT, "", aiMsg.ToolCalls)
+	message.Provider = aiMsg.Provider
+	message.Model = aiMsg.Model
 	if aiMsg.NewMapJson != "" {
 		conversation.AddMessage(aiMsg.NewMapJson, entity.TOOL, aiMsg.ToolCallID, nil)
 	}
 
+	//应用ai提出的导图操作 失败时整批不生效，原因写入工具结果供ai下一轮参考
+	if aiMsg.OperationsCallID != "" {
+		result := fmt.Sprintf("已应用 %d 个导图操作", len(aiMsg.Operations))
+		changes, err := a.applyMindMapOperations(ctx, conversation.MapID, aiMsg.Operations)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "应用导图操作失败: %v", err)
+			aiMsg.EditError = err.Error()
+			result = "导图操作未应用：" + err.Error()
+		} else {
+			aiMsg.Changes = changes
+		}
+		conversation.AddMessage(result, entity.TOOL, aiMsg.OperationsCallID, nil)
+	}
+
 	//更新会话聊天记录
 	err = a.aiChatRepo.UpdateConversationMessage(ctx, conversation)
 	if err != nil {
@@ -68,6 +217,49 @@ func (a *AiChatService) ProcessUserMessage(ctx context.Context, req *types.Proce
 	return aiMsg, nil
 }
 
+// defaultModelParams 配置的模型参数默认值
+func (a *AiChatService) defaultModelParams() entity.ModelParams {
+	cfg := a.config.ModelParams
+	return entity.ModelParams{
+		Temperature:      cfg.Temperature,
+		TopP:             cfg.TopP,
+		MaxTokens:        cfg.MaxTokens,
+		FrequencyPenalty: cfg.FrequencyPenalty,
+	}
+}
+
+// conversationPreset 会话使用的预设，预设已删除或不可用时按默认处理
+func (a *AiChatService) conversationPreset(ctx context.Context, conversation *entity.Conversation) *entity.PromptPreset {
+	if conversation.PresetID == "" {
+		return nil
+	}
+	preset, err := a.presetService.GetUsablePreset(ctx, conversation.PresetID)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "会话 %s 的预设 %s 不可用: %v", conversation.ConversationID, conversation.PresetID, err)
+		return nil
+	}
+	return preset
+}
+
+// applyMindMapOperations 在导图副本上依次执行操作，全部成功后再保存，返回变更列表供前端展示
+func (a *AiChatService) applyMindMapOperations(ctx context.Context, mapID string, ops []entity.MindMapOperation) ([]entity.MindMapChange, error) {
+	mindMap, err := a.mindMapService.GetMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := mindMap.Data.Clone()
+	changes, err := data.ApplyOperations(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.mindMapService.UpdateMindMap(ctx, mapID, &types.UpdateMindMapParams{Data: &data}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
 func (a *AiChatService) SaveNewConversation(ctx context.Context, req *types.SaveNewConversationParams) (string, error) {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
@@ -79,6 +271,12 @@ func (a *AiChatService) SaveNewConversation(ctx context.Context, req *types.Save
 	if err != nil {
 		return "", err
 	}
+	if req.PresetID != "" {
+		if _, err := a.presetService.GetUsablePreset(ctx, req.PresetID); err != nil {
+			return "", err
+		}
+		conversation.PresetID = req.PresetID
+	}
 	//初始化系统提示词
 	conversation.ProcessSystemPrompt(req.MapData)
 
@@ -101,7 +299,14 @@ func (a *AiChatService) GetConversationList(ctx context.Context, req *types.GetC
 		return nil, err
 	}
 
-	return conversationList, nil
+	//已归档的会话与未归档的会话分开列出
+	filtered := make([]*entity.Conversation, 0, len(conversationList))
+	for _, conversation := range conversationList {
+		if conversation.Archived == req.Archived {
+			filtered = append(filtered, conversation)
+		}
+	}
+	return filtered, nil
 }
 
 func (a *AiChatService) DelConversation(ctx context.Context, req *types.DelConversationParams) error {
@@ -119,6 +324,41 @@ func (a *AiChatService) DelConversation(ctx context.Context, req *types.DelConve
 	return nil
 }
 
+func (a *AiChatService) RestoreConversation(ctx context.Context, req *types.RestoreConversationParams) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
+		return AI_CHAT_PERMISSION_DENIED
+	}
+
+	err := a.aiChatRepo.RestoreConversation(ctx, req.ConversationID, user.UserID, time.Now().Add(-ConversationRestoreWindow))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListPurgeableConversations 查询超过恢复期限的已删除会话，供定时任务调用
+func (a *AiChatService) ListPurgeableConversations(ctx context.Context) ([]string, error) {
+	conversationIDs, err := a.aiChatRepo.ListDeletedConversationIDs(ctx, time.Now().Add(-ConversationRestoreWindow))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "查询待清理会话失败: %v", err)
+		return nil, err
+	}
+	return conversationIDs, nil
+}
+
+// PurgeConversations 彻底删除会话及其聊天记录，供定时任务在清理完关联文件后调用
+// 会话删除后无法再找到其关联文件，因此文件清理失败时不能调用
+func (a *AiChatService) PurgeConversations(ctx context.Context, conversationIDs []string) error {
+	if err := a.aiChatRepo.PurgeConversations(ctx, conversationIDs); err != nil {
+		zlog.CtxErrorf(ctx, "清理已删除会话失败: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (a *AiChatService) GetConversation(ctx context.Context, req *types.GetConversationParams) (*entity.Conversation, error) {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
@@ -134,6 +374,55 @@ func (a *AiChatService) GetConversation(ctx context.Context, req *types.GetConve
 	return conversation, nil
 }
 
+// GetConversationMessages 按游标分页获取会话的聊天记录
+func (a *AiChatService) GetConversationMessages(ctx context.Context, req *types.GetConversationMessagesParams) (*types.ConversationMessages, error) {
+	conversation, err := a.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+	if err != nil {
+		return nil, err
+	}
+
+	messages, hasMore, ok := conversation.MessagesBefore(req.BeforeMessageID, req.Limit)
+	if !ok {
+		zlog.CtxWarnf(ctx, "消息 %s 不存在于会话 %s", req.BeforeMessageID, req.ConversationID)
+		return nil, MESSAGE_NOT_EXIST
+	}
+	return &types.ConversationMessages{
+		Title:    conversation.Title,
+		PresetID: conversation.PresetID,
+		Messages: messages,
+		HasMore:  hasMore,
+	}, nil
+}
+
+// ForkConversation 把会话截至某条消息的聊天记录复制为同一导图下的新会话，原会话不变
+func (a *AiChatService) ForkConversation(ctx context.Context, req *types.ForkConversationParams) (string, error) {
+	conversation, err := a.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+	if err != nil {
+		return "", err
+	}
+
+	index := conversation.MessageIndex(req.MessageID)
+	if index < 0 {
+		zlog.CtxWarnf(ctx, "消息 %s 不存在于会话 %s", req.MessageID, req.ConversationID)
+		return "", MESSAGE_NOT_EXIST
+	}
+
+	title := req.Title
+	if title == "" {
+		title = conversation.Title + forkTitleSuffix
+	}
+	forked, err := conversation.Fork(index, title)
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.aiChatRepo.SaveConversation(ctx, forked); err != nil {
+		return "", err
+	}
+	zlog.CtxInfof(ctx, "会话 %s 从消息 %s 分支为 %s", req.ConversationID, req.MessageID, forked.ConversationID)
+	return forked.ConversationID, nil
+}
+
 func (a *AiChatService) UpdateConversationTitle(ctx context.Context, req *types.UpdateConversationTitleParams) error {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
@@ -155,30 +444,241 @@ func (a *AiChatService) UpdateConversationTitle(ctx context.Context, req *types.
 	return nil
 }
 
-func (a *AiChatService) GenerateMindMap(ctx context.Context, req *types.GenerateMindMapParams) (string, error) {
+// SetConversationPreset 设置会话使用的预设，预设ID为空时恢复默认，从下一条消息开始生效
+func (a *AiChatService) SetConversationPreset(ctx context.Context, req *types.SetConversationPresetParams) error {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
-		return "", AI_CHAT_PERMISSION_DENIED
+		return AI_CHAT_PERMISSION_DENIED
+	}
+
+	conversation, err := a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
+	if err != nil {
+		return err
+	}
+	if req.PresetID != "" {
+		if _, err := a.presetService.GetUsablePreset(ctx, req.PresetID); err != nil {
+			return err
+		}
+	}
+
+	conversation.PresetID = req.PresetID
+	return a.aiChatRepo.UpdateConversationPreset(ctx, conversation)
+}
+
+// ArchiveConversation 归档或取消归档会话，归档不影响继续对话
+func (a *AiChatService) ArchiveConversation(ctx context.Context, req *types.ArchiveConversationParams) error {
+	conversation, err := a.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+	if err != nil {
+		return err
+	}
+	if conversation.Archived == req.Archived {
+		return nil
+	}
+	return a.aiChatRepo.SetConversationArchived(ctx, conversation.ConversationID, conversation.UserID, req.Archived)
+}
+
+// PinConversation 置顶或取消置顶会话
+func (a *AiChatService) PinConversation(ctx context.Context, req *types.PinConversationParams) error {
+	conversation, err := a.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+	if err != nil {
+		return err
+	}
+	if conversation.Pinned == req.Pinned {
+		return nil
+	}
+	return a.aiChatRepo.SetConversationPinned(ctx, conversation.ConversationID, conversation.UserID, req.Pinned)
+}
+
+// ReorderConversations 按给定顺序调整导图下会话的排列顺序，会话必须都属于该导图且不能重复
+func (a *AiChatService) ReorderConversations(ctx context.Context, req *types.ReorderConversationsParams) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
+		return AI_CHAT_PERMISSION_DENIED
+	}
+	if len(req.ConversationIDs) == 0 || len(req.ConversationIDs) > maxReorderConversations {
+		return CONVERSATION_ORDER_INVALID
+	}
+
+	conversations, err := a.aiChatRepo.GetMapAllConversation(ctx, req.MapID, user.UserID)
+	if err != nil {
+		return err
 	}
+	owned := make(map[string]bool, len(conversations))
+	for _, conversation := range conversations {
+		owned[conversation.ConversationID] = true
+	}
+	seen := make(map[string]bool, len(req.ConversationIDs))
+	for _, conversationID := range req.ConversationIDs {
+		if !owned[conversationID] || seen[conversationID] {
+			return CONVERSATION_ORDER_INVALID
+		}
+		seen[conversationID] = true
+	}
+
+	return a.aiChatRepo.UpdateConversationOrder(ctx, user.UserID, req.MapID, req.ConversationIDs)
+}
 
-	if req.File == nil {
-		resp, err := a.einoServer.GenerateMindMap(ctx, req.Text, user.UserID)
+// SearchConversations 按关键词搜索当前用户的会话标题与聊天内容，结果带上第一条匹配消息的摘要
+func (a *AiChatService) SearchConversations(ctx context.Context, req *types.SearchConversationsParams) (*types.ConversationSearchResult, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
+		return nil, AI_CHAT_PERMISSION_DENIED
+	}
+
+	keyword := strings.TrimSpace(req.Keyword)
+	if keyword == "" || utf8.RuneCountInString(keyword) > maxSearchKeywordLength {
+		return nil, SEARCH_KEYWORD_INVALID
+	}
+
+	query := repo.NewConversationSearchQuery(user.UserID, keyword, req.Archived, req.Page, req.PageSize)
+	conversations, total, err := a.aiChatRepo.SearchConversations(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*types.ConversationSearchHit, 0, len(conversations))
+	for _, conversation := range conversations {
+		messageID, snippet := conversation.SearchSnippet(keyword, searchSnippetRadius)
+		hits = append(hits, &types.ConversationSearchHit{
+			Conversation: conversation,
+			MessageID:    messageID,
+			Snippet:      snippet,
+		})
+	}
+	return &types.ConversationSearchResult{
+		List:     hits,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+func (a *AiChatService) GenerateMindMap(ctx context.Context, req *types.GenerateMindMapParams) (*types.GenerateMindMapResult, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
+		return nil, AI_CHAT_PERMISSION_DENIED
+	}
+
+	text := req.Text
+	if req.File != nil {
+		var err error
+		document := a.config.Document
+		// 解析之前先扫描病毒，超出大小上限的文件由解析时拒绝，不再扫描
+		if req.File.Size <= document.MaxFileSize() {
+			if err = a.cosService.ScanUpload(ctx, entity.FilePurposeDocument, req.File); err != nil {
+				return nil, err
+			}
+		}
+		text, err = docparse.Extract(ctx, req.File, docparse.Limits{
+			MaxFileSize:   document.MaxFileSize(),
+			MaxPages:      document.PageLimit(),
+			MaxTextLength: document.TextLimit(),
+		})
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return resp, nil
-	} else {
-		text, err := util.ParseFile(ctx, req.File)
+	}
+
+	//相同输入命中缓存时不调用ai，也不消耗配额
+	mapJson, cached, err := a.generateCached(ctx, user.UserID, text, func(ctx context.Context) (string, error) {
+		release, refund, err := a.acquireAI(ctx)
 		if err != nil {
 			return "", err
 		}
+		defer release()
 
 		resp, err := a.einoServer.GenerateMindMap(ctx, text, user.UserID)
-
 		if err != nil {
+			refund()
 			return "", err
 		}
-		return resp, nil
+		a.quotaService.RecordAITokenUsage(ctx, entity.AiUsageSceneGenerate, resp.Provider, resp.Model, resp.Usage)
+		return resp.MapJson, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &types.GenerateMindMapResult{MapJson: mapJson, Cached: cached}, nil
+}
+
+// SummarizeMindMap 按导图的节点树生成总结与各分支要点，并作为ai消息保存到关联的会话
+// 未指定会话时为该导图新建一个会话
+func (a *AiChatService) SummarizeMindMap(ctx context.Context, req *types.SummarizeMindMapParams) (*types.MindMapSummaryResult, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
+		return nil, AI_CHAT_PERMISSION_DENIED
+	}
+	if req.MapID == "" {
+		return nil, MAP_ID_NOT_NULL
+	}
+	if !a.einoServer.SupportModel(req.Model) {
+		zlog.CtxWarnf(ctx, "不支持的模型: %s", req.Model)
+		return nil, AI_MODEL_NOT_SUPPORTED
+	}
+
+	mindMap, err := a.mindMapService.GetMindMap(ctx, req.MapID)
+	if err != nil {
+		return nil, err
+	}
+	outline := mindMap.Data.Outline()
+
+	//关联的会话 新建的会话在总结成功后才保存
+	var conversation *entity.Conversation
+	isNew := req.ConversationID == ""
+	if isNew {
+		conversation, err = entity.NewConversation(user.UserID, mindMap.MapID, mindMap.Title+summaryTitleSuffix)
+		if err != nil {
+			return nil, err
+		}
+		conversation.ProcessSystemPrompt(outline)
+	} else {
+		conversation, err = a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if conversation.MapID != mindMap.MapID {
+			zlog.CtxWarnf(ctx, "会话 %s 不属于导图 %s", req.ConversationID, req.MapID)
+			return nil, CONVERSATION_MAP_MISMATCH
+		}
+	}
+
+	release, refund, err := a.acquireAI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	output, err := a.einoServer.SummarizeMindMap(ctx, req.Model, mindMap.Title, outline)
+	if err != nil {
+		refund()
+		return nil, err
+	}
+	a.quotaService.RecordAITokenUsage(ctx, entity.AiUsageSceneSummary, output.Provider, output.Model, output.Usage)
+
+	conversation.AddMessage(summaryRequestMessage, entity.USER, "", nil)
+	message := conversation.AddMessage(output.Summary.Markdown(), entity.ASSISTANT, "", nil)
+	message.Provider = output.Provider
+	message.Model = output.Model
+
+	if isNew {
+		err = a.aiChatRepo.SaveConversation(ctx, conversation)
+	} else {
+		err = a.aiChatRepo.UpdateConversationMessage(ctx, conversation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MindMapSummaryResult{
+		ConversationID: conversation.ConversationID,
+		MessageID:      message.MessageID,
+		Summary:        output.Summary,
+		Provider:       output.Provider,
+		Model:          output.Model,
+	}, nil
 }
@@ -0,0 +1,57 @@
+package aichatservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/infra/configs"
+)
+
+// generateCacheKey 生成导图缓存的 key：按用户区分（生成结果中带有用户ID），
+// 对规范化后的输入文本与生成提示词取哈希，提示词修改（包括热更新）后旧缓存自然失效；未开启缓存时返回空
+func (a *AiChatService) generateCacheKey(userID, text string) string {
+	if a.config.GenerateCache.Disabled {
+		return ""
+	}
+	prompt := configs.Config().GetAiChatConfig().GenerateSystemPrompt
+	sum := sha256.Sum256([]byte(prompt + "\n" + normalizeGenerateText(text)))
+	return fmt.Sprintf(constant.REDIS_GENERATE_MINDMAP_CACHE_KEY, userID, hex.EncodeToString(sum[:]))
+}
+
+// generateCached 读取相同输入缓存的导图，未命中时调用 generate 生成并缓存，cached 表示结果是否来自缓存
+// 同一用户同时提交的相同输入只生成一次；读写缓存失败不影响本次结果，未开启缓存时直接生成
+// 导图按原始 JSON 缓存，与直接写入字符串的旧缓存格式相同
+func (a *AiChatService) generateCached(ctx context.Context, userID, text string, generate func(ctx context.Context) (string, error)) (mapJson string, cached bool, err error) {
+	key := a.generateCacheKey(userID, text)
+	if key == "" {
+		mapJson, err = generate(ctx)
+		return mapJson, false, err
+	}
+	raw, cached, err := cache.GetOrLoad(ctx, key, a.config.GenerateCache.TTL(), func(ctx context.Context) (json.RawMessage, error) {
+		mapJson, err := generate(ctx)
+		if err != nil || mapJson == "" {
+			return nil, err
+		}
+		return json.RawMessage(mapJson), nil
+	})
+	return string(raw), cached, err
+}
+
+// normalizeGenerateText 统一换行并去掉每行首尾与多余的空白，只有空白差异的文本视为相同输入
+func normalizeGenerateText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+	return strings.Join(normalized, "\n")
+}
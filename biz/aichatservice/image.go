@@ -0,0 +1,103 @@
+package aichatservice
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// checkImages 校验图片数量与是否有支持图片的提供方，在占用ai名额之前调用
+func (a *AiChatService) checkImages(ctx context.Context, images []types.ImageInput, vision bool) error {
+	if len(images) == 0 {
+		return nil
+	}
+	if len(images) > a.config.ImageInput.ImageLimit() {
+		return AI_IMAGE_LIMIT_EXCEEDED
+	}
+	if !vision {
+		zlog.CtxWarnf(ctx, "没有支持图片输入的ai提供方")
+		return AI_VISION_NOT_SUPPORTED
+	}
+	for _, image := range images {
+		if (image.Key == "") == (image.Data == "") {
+			return AI_IMAGE_INVALID
+		}
+	}
+	return nil
+}
+
+// resolveImages 保存以 base64 上传的图片、读取按 key 引用的图片，返回的图片带有内容
+func (a *AiChatService) resolveImages(ctx context.Context, conversationID string, inputs []types.ImageInput) ([]entity.MessageImage, error) {
+	maxSize := a.config.ImageInput.MaxImageSize()
+	images := make([]entity.MessageImage, 0, len(inputs))
+	for _, input := range inputs {
+		var (
+			image *entity.MessageImage
+			err   error
+		)
+		if input.Key != "" {
+			image, err = a.cosService.LoadChatImage(ctx, input.Key)
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(image.Data)) > maxSize {
+				return nil, AI_IMAGE_LIMIT_EXCEEDED
+			}
+		} else {
+			data, err := decodeImageData(input.Data)
+			if err != nil {
+				zlog.CtxWarnf(ctx, "图片不是有效的 base64: %v", err)
+				return nil, AI_IMAGE_INVALID
+			}
+			if int64(len(data)) > maxSize {
+				return nil, AI_IMAGE_LIMIT_EXCEEDED
+			}
+			image, err = a.cosService.UploadChatImage(ctx, conversationID, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		images = append(images, *image)
+	}
+	return images, nil
+}
+
+// loadHistoryImages 从最新的消息往前读取聊天记录中图片的内容，最多读取配置的张数
+// 超出张数或读取失败（如文件已删除）的图片不发送给模型，返回是否有要发送的图片
+func (a *AiChatService) loadHistoryImages(ctx context.Context, messages []*entity.Message) bool {
+	remaining := a.config.ImageInput.ImageLimit()
+	loaded := false
+	for i := len(messages) - 1; i >= 0 && remaining > 0; i-- {
+		images := messages[i].Images
+		for j := range images {
+			if remaining == 0 {
+				break
+			}
+			if images[j].Data == nil {
+				image, err := a.cosService.LoadChatImage(ctx, images[j].Key)
+				if err != nil {
+					zlog.CtxWarnf(ctx, "读取聊天记录中的图片 %s 失败: %v", images[j].Key, err)
+					continue
+				}
+				images[j].Data = image.Data
+			}
+			remaining--
+			loaded = true
+		}
+	}
+	return loaded
+}
+
+// decodeImageData 解码 base64 图片，兼容 data:image/png;base64, 前缀
+func decodeImageData(data string) ([]byte, error) {
+	if strings.HasPrefix(data, "data:") {
+		if i := strings.Index(data, ","); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+}
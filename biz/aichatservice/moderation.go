@@ -0,0 +1,52 @@
+package aichatservice
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 内容审核场景
+const (
+	moderationSceneInput  = "input"  // 用户输入
+	moderationSceneOutput = "output" // ai回复
+)
+
+// moderate 审核一段内容，违规时记录到审计日志并返回对应错误；未配置审核服务时直接通过
+func (a *AiChatService) moderate(ctx context.Context, conversationID, scene, text string) error {
+	if a.moderation == nil || text == "" {
+		return nil
+	}
+
+	result, err := a.moderation.Check(ctx, text)
+	if err != nil {
+		// 审核服务出错时的放行或拒绝由审核服务按配置处理，这里只兜底放行
+		zlog.CtxWarnf(ctx, "内容审核失败: %v", err)
+		return nil
+	}
+	if !result.Blocked {
+		return nil
+	}
+
+	blockedErr := CONTENT_INPUT_BLOCKED
+	if scene == moderationSceneOutput {
+		blockedErr = CONTENT_OUTPUT_BLOCKED
+	}
+	zlog.CtxWarnf(ctx, "会话 %s 的%s内容未通过审核: %s/%s", conversationID, scene, result.Source, result.Category)
+	if a.auditService != nil {
+		var userID string
+		if user, ok := entity.GetUser(ctx); ok {
+			userID = user.UserID
+		}
+		a.auditService.Record(ctx, &types.AuditEvent{
+			UserID: userID,
+			Action: entity.AuditActionContentBlocked,
+			Detail: fmt.Sprintf("conversation: %s, scene: %s, source: %s, category: %s", conversationID, scene, result.Source, result.Category),
+			Err:    blockedErr,
+		})
+	}
+	return blockedErr
+}
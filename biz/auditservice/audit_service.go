@@ -0,0 +1,125 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrInvalidParams    = errors.New("参数无效")
+	ErrPermissionDenied = errors.New("权限不足")
+	ErrInternalError    = errors.New("内部错误")
+)
+
+const (
+	// 与数据库列宽一致
+	maxDetailLength    = 255
+	maxUserAgentLength = 512
+)
+
+// AuditServiceImpl 安全审计服务实现
+type AuditServiceImpl struct {
+	auditRepo repo.AuditLogRepo
+}
+
+func NewAuditServiceImpl(auditRepo repo.AuditLogRepo) *AuditServiceImpl {
+	return &AuditServiceImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record 记录安全事件：已登录时操作人为当前用户，否则为事件所属的用户
+func (a *AuditServiceImpl) Record(ctx context.Context, event *types.AuditEvent) {
+	if event == nil || event.Action == "" {
+		return
+	}
+
+	logID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate audit log id failed: %v", err)
+		return
+	}
+
+	log := &entity.AuditLog{
+		LogID:     logID,
+		UserID:    event.UserID,
+		ActorID:   event.UserID,
+		Action:    event.Action,
+		Result:    entity.AuditResultSuccess,
+		Detail:    event.Detail,
+		IP:        entity.GetClientIP(ctx),
+		UserAgent: truncate(entity.GetUserAgent(ctx), maxUserAgentLength),
+		CreatedAt: time.Now(),
+	}
+	if currentUser, ok := entity.GetUser(ctx); ok {
+		log.ActorID = currentUser.UserID
+	}
+	if event.Err != nil {
+		log.Result = entity.AuditResultFailure
+		if log.Detail != "" {
+			log.Detail += "; "
+		}
+		log.Detail += event.Err.Error()
+	}
+	log.Detail = truncate(log.Detail, maxDetailLength)
+
+	if err := a.auditRepo.CreateAuditLog(ctx, log); err != nil {
+		zlog.CtxErrorf(ctx, "record audit log %s for user %s failed: %v", event.Action, event.UserID, err)
+	}
+}
+
+// ListMyAuditLogs 当前用户的安全事件
+func (a *AuditServiceImpl) ListMyAuditLogs(ctx context.Context, page, pageSize int) ([]*entity.AuditLog, int64, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for list audit logs")
+		return nil, 0, ErrPermissionDenied
+	}
+
+	query := repo.NewAuditLogQuery(currentUser.UserID, "", "", "", page, pageSize)
+	logs, total, err := a.auditRepo.ListAuditLogs(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list audit logs failed: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return logs, total, nil
+}
+
+// ListAuditLogs 按条件查询安全事件（管理接口）
+func (a *AuditServiceImpl) ListAuditLogs(ctx context.Context, params *types.ListAuditLogsParams) ([]*entity.AuditLog, int64, error) {
+	if params == nil {
+		return nil, 0, ErrInvalidParams
+	}
+	if params.Result != "" && params.Result != entity.AuditResultSuccess && params.Result != entity.AuditResultFailure {
+		return nil, 0, ErrInvalidParams
+	}
+
+	query := repo.NewAuditLogQuery(params.UserID, params.ActorID, params.Action, params.Result, params.Page, params.PageSize)
+	logs, total, err := a.auditRepo.ListAuditLogs(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list audit logs failed: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return logs, total, nil
+}
+
+// truncate 按字节截断过长的文本，避免截断在多字节字符中间
+func truncate(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	text = text[:maxLength]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
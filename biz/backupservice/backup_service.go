@@ -0,0 +1,390 @@
+package backupservice
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrInvalidParams   = errors.New("参数无效")
+	ErrBackupNotFound  = errors.New("备份不存在")
+	ErrBackupCorrupted = errors.New("备份文件校验失败")
+	ErrInternalError   = errors.New("内部错误")
+)
+
+const (
+	backupFormatVersion   = 1
+	backupContentType     = "application/gzip"
+	defaultBackupPrefix   = "backup"
+	defaultRetentionDays  = 7
+	backupUserBatchSize   = 100 // 每批处理的用户数
+	backupMindMapPageSize = 99  // 分页读取导图，与仓储层分页上限一致
+	purgeBatchSize        = 100 // 每批清理的过期备份数
+)
+
+// backupDocument 备份文件内容，gzip 压缩后上传
+type backupDocument struct {
+	Version   int             `json:"version"`
+	UserID    string          `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	MindMaps  []backupMindMap `json:"mind_maps"`
+}
+
+// backupMindMap 一张导图及其所有会话
+type backupMindMap struct {
+	MindMap       *entity.MindMap        `json:"mind_map"`
+	Conversations []*entity.Conversation `json:"conversations"`
+}
+
+// BackupServiceImpl 备份服务实现
+type BackupServiceImpl struct {
+	cosService  adapter.COSService
+	backupRepo  repo.BackupRepo
+	userRepo    repo.UserRepo
+	mindMapRepo repo.IMindMapRepo
+	aiChatRepo  repo.AiChatRepo
+	config      configs.BackupConfig
+}
+
+func NewBackupServiceImpl(cosService adapter.COSService, backupRepo repo.BackupRepo, userRepo repo.UserRepo, mindMapRepo repo.IMindMapRepo, aiChatRepo repo.AiChatRepo, cfg configs.BackupConfig) *BackupServiceImpl {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultBackupPrefix
+	}
+	if cfg.RetentionDays <= 0 {
+		cfg.RetentionDays = defaultRetentionDays
+	}
+	return &BackupServiceImpl{
+		cosService:  cosService,
+		backupRepo:  backupRepo,
+		userRepo:    userRepo,
+		mindMapRepo: mindMapRepo,
+		aiChatRepo:  aiChatRepo,
+		config:      cfg,
+	}
+}
+
+// RunBackup 为所有有内容的用户各生成一份备份，单个用户失败不影响其他用户
+func (s *BackupServiceImpl) RunBackup(ctx context.Context) (int, error) {
+	var (
+		afterUserID string
+		count       int
+	)
+	for {
+		userIDs, err := s.userRepo.ListUserIDs(ctx, afterUserID, backupUserBatchSize)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to list user ids: %v", err)
+			return count, ErrInternalError
+		}
+		if len(userIDs) == 0 {
+			return count, nil
+		}
+
+		for _, userID := range userIDs {
+			backup, err := s.backupUser(ctx, userID)
+			if err != nil {
+				zlog.CtxErrorf(ctx, "failed to backup user %s: %v", userID, err)
+				continue
+			}
+			if backup != nil {
+				count++
+			}
+		}
+		afterUserID = userIDs[len(userIDs)-1]
+	}
+}
+
+// backupUser 导出用户的导图与会话并上传，用户没有导图时跳过并返回 nil
+func (s *BackupServiceImpl) backupUser(ctx context.Context, userID string) (*entity.Backup, error) {
+	doc, conversationCount, err := s.exportUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.MindMaps) == 0 {
+		return nil, nil
+	}
+
+	data, err := encodeBackup(doc)
+	if err != nil {
+		return nil, err
+	}
+	sum := checksum(data)
+
+	backupID, err := util.GenerateStringID()
+	if err != nil {
+		return nil, fmt.Errorf("generate backup id failed: %w", err)
+	}
+	resourcePath := path.Join(s.config.Prefix, doc.CreatedAt.Format("20060102"), userID, backupID+".json.gz")
+
+	if _, err := s.cosService.UploadFile(ctx, resourcePath, data, backupContentType); err != nil {
+		return nil, err
+	}
+
+	// 回读校验，确认对象存储中的内容与本地一致后才记录备份
+	if err := s.verify(ctx, resourcePath, sum); err != nil {
+		_ = s.cosService.DeleteFile(ctx, resourcePath)
+		return nil, err
+	}
+
+	backup := &entity.Backup{
+		BackupID:          backupID,
+		UserID:            userID,
+		ResourcePath:      resourcePath,
+		Size:              int64(len(data)),
+		SHA256:            sum,
+		MindMapCount:      int64(len(doc.MindMaps)),
+		ConversationCount: conversationCount,
+	}
+	if err := s.backupRepo.CreateBackup(ctx, backup); err != nil {
+		_ = s.cosService.DeleteFile(ctx, resourcePath)
+		return nil, err
+	}
+	return backup, nil
+}
+
+// exportUser 读取用户的全部导图及各导图下的会话
+func (s *BackupServiceImpl) exportUser(ctx context.Context, userID string) (*backupDocument, int64, error) {
+	doc := &backupDocument{
+		Version:   backupFormatVersion,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	var conversationCount int64
+
+	for page := 1; ; page++ {
+		mindMaps, total, err := s.mindMapRepo.ListMindMaps(ctx, repo.NewMindMapQueryForList(userID, page, backupMindMapPageSize))
+		if err != nil {
+			return nil, 0, fmt.Errorf("list mindmaps failed: %w", err)
+		}
+		for _, mindMap := range mindMaps {
+			conversations, err := s.aiChatRepo.GetMapAllConversation(ctx, mindMap.MapID, userID)
+			if err != nil {
+				return nil, 0, fmt.Errorf("list conversations of map %s failed: %w", mindMap.MapID, err)
+			}
+			doc.MindMaps = append(doc.MindMaps, backupMindMap{MindMap: mindMap, Conversations: conversations})
+			conversationCount += int64(len(conversations))
+		}
+		if len(mindMaps) == 0 || int64(page*backupMindMapPageSize) >= total {
+			return doc, conversationCount, nil
+		}
+	}
+}
+
+// PurgeExpiredBackups 删除超过保留天数的备份，返回删除的数量
+func (s *BackupServiceImpl) PurgeExpiredBackups(ctx context.Context) (int, error) {
+	before := time.Now().AddDate(0, 0, -s.config.RetentionDays)
+	var count int
+	for {
+		backups, err := s.backupRepo.ListBackupsBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to list expired backups: %v", err)
+			return count, ErrInternalError
+		}
+		if len(backups) == 0 {
+			return count, nil
+		}
+
+		backupIDs := make([]string, 0, len(backups))
+		for _, backup := range backups {
+			// 对象删除失败时保留记录，下次再试
+			if err := s.cosService.DeleteFile(ctx, backup.ResourcePath); err != nil {
+				zlog.CtxErrorf(ctx, "failed to delete backup object %s: %v", backup.ResourcePath, err)
+				continue
+			}
+			backupIDs = append(backupIDs, backup.BackupID)
+		}
+		if len(backupIDs) == 0 {
+			return count, nil
+		}
+		if err := s.backupRepo.DeleteBackups(ctx, backupIDs); err != nil {
+			zlog.CtxErrorf(ctx, "failed to delete backup records: %v", err)
+			return count, ErrInternalError
+		}
+		count += len(backupIDs)
+	}
+}
+
+// ListBackups 获取用户的备份记录（管理接口）
+func (s *BackupServiceImpl) ListBackups(ctx context.Context, userID string) ([]*entity.Backup, error) {
+	if userID == "" {
+		return nil, ErrInvalidParams
+	}
+	backups, err := s.backupRepo.ListBackups(ctx, userID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list backups: %v", err)
+		return nil, ErrInternalError
+	}
+	return backups, nil
+}
+
+// RestoreBackup 从备份中恢复用户已不存在的导图（管理接口）
+// 被误删的导图会以新的导图ID重新创建，连同其下的会话一起恢复；仍然存在的导图不做改动
+func (s *BackupServiceImpl) RestoreBackup(ctx context.Context, backupID string) (*types.RestoreBackupResult, error) {
+	if backupID == "" {
+		return nil, ErrInvalidParams
+	}
+	backup, err := s.backupRepo.GetBackup(ctx, backupID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get backup: %v", err)
+		return nil, ErrInternalError
+	}
+	if backup == nil {
+		return nil, ErrBackupNotFound
+	}
+
+	doc, err := s.load(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.RestoreBackupResult{}
+	for _, item := range doc.MindMaps {
+		if item.MindMap == nil {
+			continue
+		}
+		_, err := s.mindMapRepo.GetMindMap(ctx, repo.NewMindMapQueryByID(backup.UserID, item.MindMap.MapID))
+		if err == nil {
+			result.SkippedMindMaps++
+			continue
+		}
+		if !errors.Is(err, repo.ErrMindMapNotFound) {
+			zlog.CtxErrorf(ctx, "failed to get mindmap %s: %v", item.MindMap.MapID, err)
+			return result, ErrInternalError
+		}
+
+		conversationCount, err := s.restoreMindMap(ctx, backup.UserID, item)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to restore mindmap %s: %v", item.MindMap.MapID, err)
+			return result, ErrInternalError
+		}
+		result.RestoredMindMaps++
+		result.RestoredConversations += conversationCount
+	}
+
+	zlog.CtxInfof(ctx, "backup %s restored for user %s: %d mindmaps, %d conversations, %d skipped",
+		backupID, backup.UserID, result.RestoredMindMaps, result.RestoredConversations, result.SkippedMindMaps)
+	return result, nil
+}
+
+// restoreMindMap 以新ID重新创建导图及其会话
+func (s *BackupServiceImpl) restoreMindMap(ctx context.Context, userID string, item backupMindMap) (int64, error) {
+	mapID, err := util.GenerateStringID()
+	if err != nil {
+		return 0, err
+	}
+	mindMap := *item.MindMap
+	mindMap.MapID = mapID
+	mindMap.UserID = userID
+	mindMap.DeletedAt = nil
+	if err := s.mindMapRepo.CreateMindMap(ctx, &mindMap); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, conversation := range item.Conversations {
+		conversationID, err := util.GenerateStringID()
+		if err != nil {
+			return count, err
+		}
+		restored := *conversation
+		restored.ConversationID = conversationID
+		restored.UserID = userID
+		restored.MapID = mapID
+		restored.DeletedAt = nil
+		if err := s.aiChatRepo.SaveConversation(ctx, &restored); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// load 下载备份并校验完整性后解析
+func (s *BackupServiceImpl) load(ctx context.Context, backup *entity.Backup) (*backupDocument, error) {
+	data, err := s.cosService.DownloadFile(ctx, backup.ResourcePath)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+	if checksum(data) != backup.SHA256 {
+		zlog.CtxErrorf(ctx, "backup %s checksum mismatch", backup.BackupID)
+		return nil, ErrBackupCorrupted
+	}
+
+	doc, err := decodeBackup(data)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to decode backup %s: %v", backup.BackupID, err)
+		return nil, ErrBackupCorrupted
+	}
+	if doc.UserID != backup.UserID {
+		zlog.CtxErrorf(ctx, "backup %s belongs to user %s, record says %s", backup.BackupID, doc.UserID, backup.UserID)
+		return nil, ErrBackupCorrupted
+	}
+	return doc, nil
+}
+
+// verify 回读对象存储中的备份并比对校验和
+func (s *BackupServiceImpl) verify(ctx context.Context, resourcePath, expected string) error {
+	data, err := s.cosService.DownloadFile(ctx, resourcePath)
+	if err != nil {
+		return err
+	}
+	if checksum(data) != expected {
+		return fmt.Errorf("%w: %s", ErrBackupCorrupted, resourcePath)
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func encodeBackup(doc *backupDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode backup failed: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress backup failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeBackup(data []byte) (*backupDocument, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, err
+	}
+	var doc backupDocument
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", doc.Version)
+	}
+	return &doc, nil
+}
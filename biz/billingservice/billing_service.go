@@ -0,0 +1,255 @@
+package billingservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrBillingDisabled   = errors.New("支付功能未开启")
+	ErrInvalidProduct    = errors.New("无效的商品")
+	ErrInvalidSignature  = errors.New("支付回调签名无效")
+	ErrInvoiceNotFound   = errors.New("账单不存在")
+	ErrPermissionDenied  = errors.New("权限不足")
+	ErrInternalError     = errors.New("内部错误")
+	ErrPaymentProvider   = errors.New("支付渠道调用失败")
+	ErrUnsupportedPlan   = errors.New("商品对应的套餐无效")
+	ErrInvalidWebhookReq = errors.New("无效的支付回调")
+)
+
+// BillingServiceImpl 支付服务实现
+type BillingServiceImpl struct {
+	paymentService adapter.PaymentService // 未配置支付渠道时为 nil
+	invoiceRepo    repo.InvoiceRepo
+	userRepo       repo.UserRepo
+	quotaService   types.IQuotaService
+	config         configs.BillingConfig
+}
+
+func NewBillingServiceImpl(paymentService adapter.PaymentService, invoiceRepo repo.InvoiceRepo, userRepo repo.UserRepo, quotaService types.IQuotaService, cfg configs.BillingConfig) *BillingServiceImpl {
+	return &BillingServiceImpl{
+		paymentService: paymentService,
+		invoiceRepo:    invoiceRepo,
+		userRepo:       userRepo,
+		quotaService:   quotaService,
+		config:         cfg,
+	}
+}
+
+// CreateCheckout 创建账单并向支付渠道申请支付会话
+func (s *BillingServiceImpl) CreateCheckout(ctx context.Context, req *types.CreateCheckoutParams) (*entity.Invoice, error) {
+	if s.paymentService == nil {
+		return nil, ErrBillingDisabled
+	}
+
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	product, ok := s.config.Products[req.Product]
+	if !ok || product.Amount <= 0 || product.PeriodDays <= 0 {
+		zlog.CtxWarnf(ctx, "invalid product: %s", req.Product)
+		return nil, ErrInvalidProduct
+	}
+	// 商品对应的套餐必须存在且不是免费版
+	if _, err := s.quotaService.GetPlanLimits(product.Plan); err != nil || product.Plan == entity.PlanFree {
+		zlog.CtxErrorf(ctx, "product %s configured with unsupported plan: %s", req.Product, product.Plan)
+		return nil, ErrUnsupportedPlan
+	}
+
+	invoiceID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate invoice ID: %v", err)
+		return nil, ErrInternalError
+	}
+
+	invoice := &entity.Invoice{
+		InvoiceID:  invoiceID,
+		UserID:     user.UserID,
+		Product:    req.Product,
+		Plan:       product.Plan,
+		PeriodDays: product.PeriodDays,
+		Amount:     product.Amount,
+		Currency:   product.Currency,
+		Status:     entity.InvoiceStatusPending,
+		Provider:   s.paymentService.Provider(),
+		CreatedAt:  time.Now(),
+	}
+	// 先落库再请求支付渠道，保证回调到达时一定能找到账单
+	if err := s.invoiceRepo.CreateInvoice(ctx, invoice); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create invoice: %v", err)
+		return nil, ErrInternalError
+	}
+
+	session, err := s.paymentService.CreateCheckoutSession(ctx, &adapter.CheckoutReq{
+		OrderID:     invoiceID,
+		ProductName: product.Name,
+		Amount:      product.Amount,
+		Currency:    product.Currency,
+		SuccessURL:  s.config.SuccessURL,
+		CancelURL:   s.config.CancelURL,
+	})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to create checkout session, invoiceID: %s, error: %v", invoiceID, err)
+		if _, tErr := s.invoiceRepo.TransitInvoiceStatus(ctx, invoiceID, entity.InvoiceStatusPending, entity.InvoiceStatusFailed, nil); tErr != nil {
+			zlog.CtxErrorf(ctx, "failed to mark invoice failed: %v", tErr)
+		}
+		return nil, ErrPaymentProvider
+	}
+
+	if err := s.invoiceRepo.UpdateInvoiceCheckout(ctx, invoiceID, session.SessionID, session.URL); err != nil {
+		zlog.CtxErrorf(ctx, "failed to save checkout session: %v", err)
+		return nil, ErrInternalError
+	}
+	invoice.ProviderSessionID = session.SessionID
+	invoice.CheckoutURL = session.URL
+
+	zlog.CtxInfof(ctx, "checkout created, userID: %s, invoiceID: %s, product: %s", user.UserID, invoiceID, req.Product)
+	return invoice, nil
+}
+
+// ListInvoices 获取当前用户的账单列表
+func (s *BillingServiceImpl) ListInvoices(ctx context.Context) ([]*entity.Invoice, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	invoices, err := s.invoiceRepo.ListInvoices(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list invoices: %v", err)
+		return nil, ErrInternalError
+	}
+	return invoices, nil
+}
+
+// HandlePaymentWebhook 处理支付回调
+// 支付渠道会重试失败的回调，因此这里需要幂等：账单状态只允许从 pending 流转一次
+func (s *BillingServiceImpl) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s.paymentService == nil {
+		return ErrBillingDisabled
+	}
+
+	event, err := s.paymentService.ParseWebhookEvent(ctx, payload, signature)
+	if err != nil {
+		if errors.Is(err, adapter.ErrInvalidPaymentSignature) {
+			zlog.CtxWarnf(ctx, "invalid payment webhook signature")
+			return ErrInvalidSignature
+		}
+		zlog.CtxErrorf(ctx, "failed to parse payment webhook: %v", err)
+		return ErrInvalidWebhookReq
+	}
+	// 不关心的事件直接确认
+	if event.Type == "" {
+		return nil
+	}
+
+	invoice, err := s.invoiceRepo.GetInvoice(ctx, event.OrderID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get invoice: %v", err)
+		return ErrInternalError
+	}
+	if invoice == nil {
+		zlog.CtxWarnf(ctx, "invoice not found for payment event, eventID: %s, orderID: %s", event.EventID, event.OrderID)
+		return ErrInvoiceNotFound
+	}
+
+	switch event.Type {
+	case adapter.PaymentEventPaid:
+		return s.activateInvoice(ctx, invoice)
+	case adapter.PaymentEventFailed:
+		return s.closeInvoice(ctx, invoice, entity.InvoiceStatusFailed)
+	case adapter.PaymentEventExpired:
+		return s.closeInvoice(ctx, invoice, entity.InvoiceStatusExpired)
+	}
+	return nil
+}
+
+// activateInvoice 账单支付成功：标记账单已支付并开通/续期套餐
+func (s *BillingServiceImpl) activateInvoice(ctx context.Context, invoice *entity.Invoice) error {
+	now := time.Now()
+	updated, err := s.invoiceRepo.TransitInvoiceStatus(ctx, invoice.InvoiceID, entity.InvoiceStatusPending, entity.InvoiceStatusPaid, &now)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to mark invoice paid: %v", err)
+		return ErrInternalError
+	}
+	if !updated {
+		// 重复回调，或账单已过期后才完成支付（需人工处理）
+		zlog.CtxWarnf(ctx, "invoice %s is not pending (status: %s), skip activation", invoice.InvoiceID, invoice.Status)
+		return nil
+	}
+
+	if err := s.extendPlan(ctx, invoice, now); err != nil {
+		// 套餐开通失败时回滚账单状态，让支付渠道重试回调
+		if _, rbErr := s.invoiceRepo.TransitInvoiceStatus(ctx, invoice.InvoiceID, entity.InvoiceStatusPaid, entity.InvoiceStatusPending, nil); rbErr != nil {
+			zlog.CtxErrorf(ctx, "failed to rollback invoice %s, need manual fix: %v", invoice.InvoiceID, rbErr)
+		}
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "invoice paid, userID: %s, invoiceID: %s, plan: %s", invoice.UserID, invoice.InvoiceID, invoice.Plan)
+	return nil
+}
+
+// extendPlan 开通或续期套餐：同一套餐未到期时在原到期时间上顺延
+func (s *BillingServiceImpl) extendPlan(ctx context.Context, invoice *entity.Invoice, now time.Time) error {
+	user, err := s.userRepo.GetUser(ctx, repo.NewUserQueryByID(invoice.UserID))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get user: %v", err)
+		return ErrInternalError
+	}
+	if user == nil {
+		zlog.CtxErrorf(ctx, "user %s of invoice %s not found", invoice.UserID, invoice.InvoiceID)
+		return ErrInternalError
+	}
+
+	start := now
+	if user.GetPlan() == invoice.Plan && user.PlanExpiresAt != nil && user.PlanExpiresAt.After(now) {
+		start = *user.PlanExpiresAt
+	}
+	expiresAt := start.AddDate(0, 0, invoice.PeriodDays)
+
+	err = s.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:        invoice.UserID,
+		Plan:          &invoice.Plan,
+		PlanExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to update user plan: %v", err)
+		return ErrInternalError
+	}
+	return nil
+}
+
+// closeInvoice 账单支付失败或过期
+func (s *BillingServiceImpl) closeInvoice(ctx context.Context, invoice *entity.Invoice, status string) error {
+	if _, err := s.invoiceRepo.TransitInvoiceStatus(ctx, invoice.InvoiceID, entity.InvoiceStatusPending, status, nil); err != nil {
+		zlog.CtxErrorf(ctx, "failed to update invoice status: %v", err)
+		return ErrInternalError
+	}
+	zlog.CtxInfof(ctx, "invoice %s closed with status %s", invoice.InvoiceID, status)
+	return nil
+}
+
+// ExpirePlans 将到期的付费套餐降级为免费版
+func (s *BillingServiceImpl) ExpirePlans(ctx context.Context) (int64, error) {
+	count, err := s.userRepo.DowngradeExpiredPlans(ctx, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to downgrade expired plans: %v", err)
+		return 0, ErrInternalError
+	}
+	return count, nil
+}
@@ -0,0 +1,104 @@
+package captchaservice
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+
+	"github.com/google/uuid"
+)
+
+// 错误定义
+var (
+	// ErrCaptchaRequired 表示需要先完成图形验证码
+	ErrCaptchaRequired = errors.New("captcha required")
+	// ErrCaptchaIncorrect 表示图形验证码错误或已失效
+	ErrCaptchaIncorrect = errors.New("captcha incorrect")
+	ErrInternalError    = errors.New("internal error")
+)
+
+// CaptchaServiceImpl 图形验证码服务实现
+type CaptchaServiceImpl struct {
+	config configs.CaptchaConfig
+}
+
+func NewCaptchaServiceImpl(config configs.CaptchaConfig) *CaptchaServiceImpl {
+	return &CaptchaServiceImpl{config: config}
+}
+
+// NewChallenge 生成图形验证码
+func (c *CaptchaServiceImpl) NewChallenge(ctx context.Context) (*types.CaptchaChallenge, error) {
+	code, err := util.GenerateCaptchaCode(c.config.CodeLength())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate captcha code failed: %v", err)
+		return nil, ErrInternalError
+	}
+	img, err := util.DrawCaptcha(code)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "draw captcha failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	captchaID := uuid.New().String()
+	key := fmt.Sprintf(constant.REDIS_CAPTCHA_KEY, captchaID)
+	if err := cache.SetRedis(ctx, key, code, c.config.Expiration()); err != nil {
+		zlog.CtxErrorf(ctx, "store captcha failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	return &types.CaptchaChallenge{
+		CaptchaID: captchaID,
+		Image:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(img),
+		ExpiresIn: int64(c.config.Expiration().Seconds()),
+	}, nil
+}
+
+// Verify 校验图形验证码，题目只能使用一次，避免对同一题目反复猜测
+func (c *CaptchaServiceImpl) Verify(ctx context.Context, captchaID, answer string) error {
+	if captchaID == "" || answer == "" {
+		return ErrCaptchaRequired
+	}
+
+	key := fmt.Sprintf(constant.REDIS_CAPTCHA_KEY, captchaID)
+	code, err := cache.GetDelRedis(ctx, key)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "load captcha failed: %v", err)
+		return ErrInternalError
+	}
+	if code == "" || !strings.EqualFold(code, strings.TrimSpace(answer)) {
+		zlog.CtxWarnf(ctx, "captcha incorrect or expired: %s", captchaID)
+		return ErrCaptchaIncorrect
+	}
+	return nil
+}
+
+// Required 同一来源IP在统计窗口内请求该接口超过免验证次数后需要图形验证码
+// Redis 不可用时不强制验证，避免影响正常登录注册
+func (c *CaptchaServiceImpl) Required(ctx context.Context, route string) bool {
+	if !c.config.Enable || !slices.Contains(c.config.Routes, route) {
+		return false
+	}
+	ip := entity.GetClientIP(ctx)
+	if ip == "" {
+		return false
+	}
+
+	key := fmt.Sprintf(constant.REDIS_CAPTCHA_HITS_KEY, route, ip)
+	count, err := cache.IncrRedis(ctx, key, c.config.Window())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "incr captcha hits failed: %v", err)
+		return false
+	}
+	return count > c.config.FreeAttemptCount()
+}
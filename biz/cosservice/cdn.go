@@ -0,0 +1,33 @@
+package cosservice
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// SignDeliveryURL 头像等保存的访问地址位于 CDN base_url 下时，返回按 cdn.expire_seconds 签名的链接
+// 保存的地址不带签名，每次返回给前端时重新签名；未启用签名或外部链接原样返回
+// 签名时间按有效期的一半取整，同一时段内签出的链接相同，返回链接的接口 ETag 不变，链接剩余有效期至少为有效期的一半
+func (s *COSServiceImpl) SignDeliveryURL(ctx context.Context, rawURL string) string {
+	cdn := s.config.CDN
+	baseURL := strings.TrimSuffix(s.config.DeliveryBaseURL(), "/")
+	if !cdn.Enabled() || s.config.DriverName() == configs.StorageDriverLocal || baseURL == "" ||
+		!strings.HasPrefix(rawURL, baseURL+"/") {
+		return rawURL
+	}
+
+	expiration := cdn.Expiration()
+	issuedAt := time.Now().Truncate(expiration / 2)
+	signTime := cdn.SignTime(issuedAt.Add(expiration))
+	signedURL, err := util.SignCDNURL(rawURL, cdn.SignType, cdn.Key, signTime, cdn.SignParamName(), cdn.TimeParamName())
+	if err != nil {
+		zlog.CtxWarnf(ctx, "failed to sign cdn url: %v", err)
+		return rawURL
+	}
+	return signedURL
+}
@@ -3,8 +3,11 @@ package cosservice
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"path"
 	"path/filepath"
 	"strings"
@@ -13,6 +16,7 @@ import (
 
 	"forge/biz/adapter"
 	"forge/biz/entity"
+	"forge/biz/repo"
 	"forge/biz/types"
 	"forge/infra/configs"
 	"forge/pkg/log/zlog"
@@ -26,6 +30,8 @@ const (
 	MaxSTSDuration = 7200 // 2小时
 	// MaxAvatarSize 头像文件最大大小（5MB）
 	MaxAvatarSize = 5 * 1024 * 1024
+	// MaxAttachmentSize 附件文件最大大小（20MB）
+	MaxAttachmentSize = 20 * 1024 * 1024
 )
 
 // 错误定义
@@ -35,18 +41,44 @@ var (
 	ErrPermissionDenied    = errors.New("权限不足")
 	ErrInvalidResourcePath = errors.New("无效的资源路径")
 	ErrInvalidDuration     = errors.New("无效的有效期")
+	ErrInvalidImage        = errors.New("图片不存在或格式不支持")
+	ErrFileNotFound        = errors.New("文件不存在")
+	ErrDownloadLinkInvalid = errors.New("下载链接无效或已过期")
+	// ErrCredentialsNotSupported 当前存储服务（如本地存储）不能颁发临时凭证，需改为经服务端上传
+	ErrCredentialsNotSupported = errors.New("当前存储服务不支持临时凭证")
+	// ErrInvalidPurpose 临时凭证只能按 avatar、attachment、export 用途申请
+	ErrInvalidPurpose = errors.New("不支持的文件用途")
+	// ErrFileInfected 文件扫描出病毒，已隔离，不会保存或解析
+	ErrFileInfected = errors.New("文件包含病毒，已隔离")
+	// ErrFileScanFailed 扫描服务出错且配置为出错时拒绝文件
+	ErrFileScanFailed = errors.New("文件安全扫描失败，请稍后重试")
 )
 
+// chatImageExts 对话图片按类型保存的扩展名
+var chatImageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 // COSServiceImpl COS服务实现
 type COSServiceImpl struct {
-	cosService adapter.COSService
-	config     configs.COSConfig
+	cosService   adapter.COSService
+	config       configs.COSConfig
+	fileRepo     repo.FileRepo
+	quotaService types.IQuotaService
+	scanner      adapter.FileScanner // 为 nil 时不扫描
 }
 
-func NewCOSServiceImpl(cosService adapter.COSService, cfg configs.COSConfig) *COSServiceImpl {
+func NewCOSServiceImpl(cosService adapter.COSService, cfg configs.COSConfig, fileRepo repo.FileRepo, quotaService types.IQuotaService,
+	scanner adapter.FileScanner) *COSServiceImpl {
 	return &COSServiceImpl{
-		cosService: cosService,
-		config:     cfg,
+		cosService:   cosService,
+		config:       cfg,
+		fileRepo:     fileRepo,
+		quotaService: quotaService,
+		scanner:      scanner,
 	}
 }
 
@@ -59,67 +91,87 @@ func (s *COSServiceImpl) GetOSSCredentials(ctx context.Context, req *types.GetOS
 		return nil, ErrPermissionDenied
 	}
 
-	// 参数校验：资源路径
-	if req.ResourcePath == "" {
-		zlog.CtxErrorf(ctx, "resource path is required")
-		return nil, ErrInvalidResourcePath
+	// 参数校验：用途，未传时取资源路径 user/{userID}/{purpose}/ 中的目录，兼容只传资源路径的调用
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = purposeOfResourcePath(req.ResourcePath)
+	}
+	purposeConfig, ok := s.config.STSPurpose(purpose)
+	if !ok {
+		zlog.CtxWarnf(ctx, "unsupported credentials purpose: %s, path: %s", purpose, req.ResourcePath)
+		return nil, ErrInvalidPurpose
 	}
 
-	// 权限校验：确保资源路径以该用户的路径开头，后续可调整
-	expectedPrefix := fmt.Sprintf("user/%s/", user.UserID)
-	if !strings.HasPrefix(req.ResourcePath, expectedPrefix) {
-		zlog.CtxWarnf(ctx, "resource path does not match user, path: %s, userID: %s", req.ResourcePath, user.UserID)
+	// 权限校验：凭证只能访问该用户该用途目录下的文件，未传资源路径时为整个目录
+	prefix := path.Join("user", user.UserID, purpose) + "/"
+	resourcePath := req.ResourcePath
+	if resourcePath == "" {
+		resourcePath = prefix + "*"
+	}
+	if !inScope(resourcePath, prefix) {
+		zlog.CtxWarnf(ctx, "resource path out of scope, path: %s, userID: %s, purpose: %s", resourcePath, user.UserID, purpose)
 		return nil, ErrPermissionDenied
 	}
 
-	// 参数校验：有效期
+	// 参数校验：有效期，不超过该用途的上限
 	durationSeconds := req.DurationSeconds
 	if durationSeconds == 0 {
-		durationSeconds = s.config.STSDuration
+		durationSeconds = purposeConfig.DurationSeconds
 	}
-	if durationSeconds < MinSTSDuration || durationSeconds > MaxSTSDuration {
-		zlog.CtxErrorf(ctx, "invalid duration seconds: %d, must be between %d and %d", durationSeconds, MinSTSDuration, MaxSTSDuration)
+	maxDuration := min(purposeConfig.MaxDurationSeconds, MaxSTSDuration)
+	if durationSeconds < MinSTSDuration || durationSeconds > maxDuration {
+		zlog.CtxErrorf(ctx, "invalid duration seconds: %d, must be between %d and %d", durationSeconds, MinSTSDuration, maxDuration)
 		return nil, ErrInvalidDuration
 	}
 
 	// 调用基础设施层获取临时凭证
-	result, err := s.cosService.GetTemporaryCredentials(req.ResourcePath, durationSeconds)
+	result, err := s.cosService.GetTemporaryCredentials(ctx, adapter.CredentialPolicy{
+		ResourcePath: resourcePath,
+		Actions:      purposeConfig.Actions,
+	}, durationSeconds)
 	if err != nil {
+		if errors.Is(err, adapter.ErrStorageNotSupported) {
+			zlog.CtxWarnf(ctx, "storage driver %s does not support temporary credentials", s.config.DriverName())
+			return nil, ErrCredentialsNotSupported
+		}
 		zlog.CtxErrorf(ctx, "failed to get credentials: %v", err)
 		return nil, ErrInternalError
 	}
 
-	// 解析过期时间
-	expiration, err := parseExpiration(result.Expiration)
-	if err != nil {
-		zlog.CtxErrorf(ctx, "failed to parse expiration: %v", err)
-		return nil, ErrInternalError
-	}
-
 	// 构建响应
 	credentials := &types.OSSCredentials{
-		AccessKeyID:     result.Credentials.TmpSecretID,
-		SecretAccessKey: result.Credentials.TmpSecretKey,
-		SessionToken:    result.Credentials.SessionToken,
-		Expiration:      expiration,
-		Region:          s.config.Region,
-		BucketName:      s.config.Bucket,
-		Provider:        "cos",
-		BaseURL:         s.config.BaseURL,
-		AccountID:       s.config.AppID,
-	}
-
-	zlog.CtxInfof(ctx, "OSS credentials retrieved successfully, userID: %s, resource: %s", user.UserID, req.ResourcePath)
+		AccessKeyID:     result.SecretID,
+		SecretAccessKey: result.SecretKey,
+		SessionToken:    result.SessionToken,
+		Expiration:      result.Expiration.Unix(),
+		Region:          result.Region,
+		BucketName:      result.Bucket,
+		Provider:        result.Provider,
+		Endpoint:        result.Endpoint,
+		BaseURL:         result.BaseURL,
+		AccountID:       result.AccountID,
+		ResourcePath:    resourcePath,
+	}
+
+	zlog.CtxInfof(ctx, "OSS credentials retrieved successfully, userID: %s, resource: %s, actions: %v", user.UserID, resourcePath, purposeConfig.Actions)
 	return credentials, nil
 }
 
-// parseExpiration 解析过期时间字符串为Unix时间戳
-func parseExpiration(expiration string) (int64, error) {
-	t, err := time.Parse(time.RFC3339, expiration)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse expiration time '%s': %w", expiration, err)
+// purposeOfResourcePath 资源路径 user/{userID}/{purpose}/... 中的用途
+func purposeOfResourcePath(resourcePath string) string {
+	parts := strings.SplitN(resourcePath, "/", 4)
+	if len(parts) < 4 || parts[0] != "user" {
+		return ""
 	}
-	return t.Unix(), nil
+	return parts[2]
+}
+
+// inScope 资源路径在 prefix 目录下，先规范化路径防止 ../ 越过前缀，只允许末尾的 * 通配
+func inScope(resourcePath, prefix string) bool {
+	return len(resourcePath) > len(prefix) &&
+		strings.HasPrefix(resourcePath, prefix) &&
+		path.Clean(resourcePath) == resourcePath &&
+		!strings.Contains(strings.TrimSuffix(resourcePath, "*"), "*")
 }
 
 // UploadAvatar 上传用户头像到COS
@@ -152,6 +204,10 @@ func (s *COSServiceImpl) UploadAvatar(ctx context.Context, userID string, fileDa
 		zlog.CtxErrorf(ctx, "file size too large: %d bytes, max: %d", len(fileData), MaxAvatarSize)
 		return "", fmt.Errorf("%w: file size exceeds 5MB", ErrInvalidParams)
 	}
+	// 存储空间校验
+	if err := s.quotaService.CheckStorageQuota(ctx, int64(len(fileData))); err != nil {
+		return "", err
+	}
 
 	// 验证文件类型（包含文件内容验证）
 	contentType, err := validateImageType(fileData, filename)
@@ -179,16 +235,22 @@ func (s *COSServiceImpl) UploadAvatar(ctx context.Context, userID string, fileDa
 	// 构建存储路径（使用path.Join防止路径注入）
 	resourcePath := path.Join("user", userID, "avatar", uniqueFilename)
 
-	// 调用基础设施层上传文件
+	// 上传文件并记录到文件列表
 	zlog.CtxInfof(ctx, "uploading avatar, userID: %s, resourcePath: %s, filename: %s", userID, resourcePath, sanitizedFilename)
-	avatarURL, err := s.cosService.UploadFile(ctx, resourcePath, fileData, contentType)
-	if err != nil {
-		zlog.CtxErrorf(ctx, "failed to upload avatar, userID: %s, resourcePath: %s, error: %v", userID, resourcePath, err)
-		return "", ErrInternalError
+	file := &entity.File{
+		FileID:       avatarID,
+		UserID:       userID,
+		ResourcePath: resourcePath,
+		Filename:     sanitizedFilename,
+		ContentType:  contentType,
+		Purpose:      entity.FilePurposeAvatar,
+	}
+	if err := s.storeFile(ctx, file, fileData); err != nil {
+		return "", err
 	}
 
 	zlog.CtxInfof(ctx, "avatar uploaded successfully, userID: %s", userID)
-	return avatarURL, nil
+	return file.URL, nil
 }
 
 // validateImageType 验证是否为有效的图片类型（包含文件内容验证）
@@ -213,29 +275,36 @@ func validateImageType(fileData []byte, filename string) (string, error) {
 	}
 
 	// 验证文件真实类型（魔数检查）
-	var isValid bool
+	contentType, ok := detectImageType(fileData)
+	if !ok {
+		return "", fmt.Errorf("invalid image file: unrecognized file format")
+	}
+
+	if contentType != expectedContentType {
+		return "", fmt.Errorf("file extension mismatch: expected %s but file content does not match", ext)
+	}
+
+	return expectedContentType, nil
+}
+
+// detectImageType 按文件头识别图片类型，只支持 JPEG、PNG、GIF 与 WebP
+func detectImageType(fileData []byte) (string, bool) {
 	switch {
 	case bytes.HasPrefix(fileData, []byte{0xFF, 0xD8, 0xFF}):
 		// JPEG: FF D8 FF
-		isValid = (expectedContentType == "image/jpeg")
+		return "image/jpeg", true
 	case bytes.HasPrefix(fileData, []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}):
 		// PNG: 89 50 4E 47 0D 0A 1A 0A
-		isValid = (expectedContentType == "image/png")
+		return "image/png", true
 	case bytes.HasPrefix(fileData, []byte{0x47, 0x49, 0x46, 0x38}):
 		// GIF: 47 49 46 38 (GIF8)
-		isValid = (expectedContentType == "image/gif")
+		return "image/gif", true
 	case len(fileData) >= 12 && bytes.HasPrefix(fileData[8:], []byte("WEBP")):
 		// WebP: RIFF....WEBP
-		isValid = (expectedContentType == "image/webp")
+		return "image/webp", true
 	default:
-		return "", fmt.Errorf("invalid image file: unrecognized file format")
+		return "", false
 	}
-
-	if !isValid {
-		return "", fmt.Errorf("file extension mismatch: expected %s but file content does not match", ext)
-	}
-
-	return expectedContentType, nil
 }
 
 // sanitizeFilename 清理文件名，防止路径注入
@@ -263,3 +332,200 @@ func sanitizeFilename(filename string) (string, error) {
 
 	return cleanName, nil
 }
+
+// UploadAttachment 上传附件到COS并记录文件元数据
+func (s *COSServiceImpl) UploadAttachment(ctx context.Context, req *types.UploadAttachmentParams) (*entity.File, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	// 参数校验
+	if len(req.FileData) == 0 || req.Filename == "" {
+		zlog.CtxErrorf(ctx, "file data or filename is empty")
+		return nil, ErrInvalidParams
+	}
+	if len(req.FileData) > MaxAttachmentSize {
+		zlog.CtxErrorf(ctx, "file size too large: %d bytes, max: %d", len(req.FileData), MaxAttachmentSize)
+		return nil, fmt.Errorf("%w: file size exceeds 20MB", ErrInvalidParams)
+	}
+	// 套餐存储空间校验
+	if err := s.quotaService.CheckStorageQuota(ctx, int64(len(req.FileData))); err != nil {
+		return nil, err
+	}
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = entity.FilePurposeAttachment
+	}
+
+	sanitizedFilename, err := sanitizeFilename(req.Filename)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "invalid filename: %v", err)
+		return nil, fmt.Errorf("%w: invalid filename", ErrInvalidParams)
+	}
+
+	fileID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate file ID: %v", err)
+		return nil, ErrInternalError
+	}
+	file := &entity.File{
+		FileID:         fileID,
+		UserID:         user.UserID,
+		ResourcePath:   path.Join("user", user.UserID, purpose, fmt.Sprintf("%s_%s", fileID, sanitizedFilename)),
+		Filename:       sanitizedFilename,
+		ContentType:    http.DetectContentType(req.FileData),
+		Purpose:        purpose,
+		ConversationID: req.ConversationID,
+	}
+	if err := s.storeFile(ctx, file, req.FileData); err != nil {
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "attachment uploaded successfully, userID: %s, fileID: %s", user.UserID, fileID)
+	return file, nil
+}
+
+// storeFile 扫描并上传文件内容，记录文件元数据，补全 URL、大小、哈希与上传时间
+// 扫描出病毒时不上传，只记录隔离的文件并返回 ErrFileInfected
+func (s *COSServiceImpl) storeFile(ctx context.Context, file *entity.File, data []byte) error {
+	sum := sha256.Sum256(data)
+	file.Size = int64(len(data))
+	file.Hash = hex.EncodeToString(sum[:])
+	if err := s.scanFile(ctx, file, data); err != nil {
+		return err
+	}
+
+	fileURL, err := s.cosService.UploadFile(ctx, file.ResourcePath, data, file.ContentType)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload file, userID: %s, resourcePath: %s, error: %v", file.UserID, file.ResourcePath, err)
+		return ErrInternalError
+	}
+
+	file.URL = fileURL
+	file.Status = entity.FileStatusActive
+	file.CreatedAt = time.Now()
+	if err := s.fileRepo.CreateFile(ctx, file); err != nil {
+		// 元数据写入失败时删除已上传的对象，避免产生无主文件
+		zlog.CtxErrorf(ctx, "failed to create file record: %v", err)
+		if delErr := s.cosService.DeleteFile(ctx, file.ResourcePath); delErr != nil {
+			zlog.CtxWarnf(ctx, "failed to rollback uploaded file, path: %s, error: %v", file.ResourcePath, delErr)
+		}
+		return ErrInternalError
+	}
+	return nil
+}
+
+// ListConversationFiles 获取会话关联的文件列表
+func (s *COSServiceImpl) ListConversationFiles(ctx context.Context, conversationID string) ([]*entity.File, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	if conversationID == "" {
+		return nil, ErrInvalidParams
+	}
+
+	files, err := s.fileRepo.ListFiles(ctx, repo.FileQuery{
+		UserID:         user.UserID,
+		ConversationID: conversationID,
+		Status:         entity.FileStatusActive,
+	})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list conversation files: %v", err)
+		return nil, ErrInternalError
+	}
+	return files, nil
+}
+
+// DeleteConversationFiles 删除会话关联的所有文件（COS对象与元数据），返回文件已全部删除的会话ID
+// 由会话清理任务调用，不做用户校验
+func (s *COSServiceImpl) DeleteConversationFiles(ctx context.Context, conversationIDs []string) ([]string, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+
+	files, err := s.fileRepo.ListFiles(ctx, repo.FileQuery{ConversationIDs: conversationIDs})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list files of conversations: %v", err)
+		return nil, ErrInternalError
+	}
+
+	// 只删除COS对象已成功删除的记录，失败的留给下次清理，其会话也不返回，以便下次还能按会话找到这些文件
+	fileIDs := make([]string, 0, len(files))
+	failed := make(map[string]bool)
+	for _, file := range files {
+		if err := s.cosService.DeleteFile(ctx, file.ResourcePath); err != nil {
+			zlog.CtxWarnf(ctx, "failed to delete file, fileID: %s, path: %s, error: %v", file.FileID, file.ResourcePath, err)
+			failed[file.ConversationID] = true
+			continue
+		}
+		fileIDs = append(fileIDs, file.FileID)
+	}
+
+	if err := s.fileRepo.DeleteFiles(ctx, fileIDs); err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete file records: %v", err)
+		return nil, ErrInternalError
+	}
+	cleaned := make([]string, 0, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		if !failed[conversationID] {
+			cleaned = append(cleaned, conversationID)
+		}
+	}
+	return cleaned, nil
+}
+
+// UploadChatImage 保存对话中以 base64 上传的图片，按会话附件记录，会话清理时一并删除
+func (s *COSServiceImpl) UploadChatImage(ctx context.Context, conversationID string, data []byte) (*entity.MessageImage, error) {
+	contentType, ok := detectImageType(data)
+	if !ok {
+		zlog.CtxWarnf(ctx, "invalid chat image: unrecognized file format")
+		return nil, ErrInvalidImage
+	}
+
+	file, err := s.UploadAttachment(ctx, &types.UploadAttachmentParams{
+		ConversationID: conversationID,
+		Purpose:        entity.FilePurposeChatImage,
+		Filename:       "image" + chatImageExts[contentType],
+		FileData:       data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.MessageImage{Key: file.ResourcePath, ContentType: contentType, Data: data}, nil
+}
+
+// ownsResourcePath 资源路径是否在用户自己的目录下，先规范化路径防止 ../ 越过前缀校验
+func ownsResourcePath(userID, resourcePath string) bool {
+	return path.Clean(resourcePath) == resourcePath && strings.HasPrefix(resourcePath, fmt.Sprintf("user/%s/", userID))
+}
+
+// LoadChatImage 读取当前用户存储空间中的图片，用于对话中按 key 引用已上传的图片
+func (s *COSServiceImpl) LoadChatImage(ctx context.Context, key string) (*entity.MessageImage, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	// 只能引用自己目录下的文件
+	if !ownsResourcePath(user.UserID, key) {
+		zlog.CtxWarnf(ctx, "chat image key does not match user, key: %s, userID: %s", key, user.UserID)
+		return nil, ErrPermissionDenied
+	}
+
+	data, err := s.cosService.DownloadFile(ctx, key)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "failed to download chat image, key: %s, error: %v", key, err)
+		return nil, ErrInvalidImage
+	}
+	contentType, ok := detectImageType(data)
+	if !ok {
+		zlog.CtxWarnf(ctx, "invalid chat image: unrecognized file format, key: %s", key)
+		return nil, ErrInvalidImage
+	}
+	return &entity.MessageImage{Key: key, ContentType: contentType, Data: data}, nil
+}
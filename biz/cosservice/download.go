@@ -0,0 +1,91 @@
+package cosservice
+
+import (
+	"context"
+	"errors"
+	"path"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 下载链接的最短有效期
+const minDownloadExpiration = time.Minute
+
+// PresignDownload 为当前用户自己的文件生成有时效的下载链接
+// presign 模式返回存储服务的签名链接；proxy 模式与本地存储返回本服务的转发链接，令牌中带有文件路径与过期时间
+func (s *COSServiceImpl) PresignDownload(ctx context.Context, req *types.PresignDownloadParams) (*types.DownloadLink, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	if req.ResourcePath == "" {
+		return nil, ErrInvalidResourcePath
+	}
+	if !ownsResourcePath(user.UserID, req.ResourcePath) {
+		zlog.CtxWarnf(ctx, "download path does not match user, path: %s, userID: %s", req.ResourcePath, user.UserID)
+		return nil, ErrPermissionDenied
+	}
+
+	cfg := s.config.Download
+	ttl := cfg.DefaultExpiration()
+	if req.ExpireSeconds != 0 {
+		ttl = time.Duration(req.ExpireSeconds) * time.Second
+	}
+	if ttl < minDownloadExpiration || ttl > cfg.MaxExpiration() {
+		zlog.CtxWarnf(ctx, "invalid download expiration: %v, max: %v", ttl, cfg.MaxExpiration())
+		return nil, ErrInvalidDuration
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	link := &types.DownloadLink{Mode: configs.COSDownloadModePresign, ExpiresAt: expiresAt}
+	if s.config.ProxyDownload() {
+		link.Mode = configs.COSDownloadModeProxy
+		link.URL = cfg.ProxyDownloadURL(util.SignDownloadToken(cfg.Secret, req.ResourcePath, expiresAt))
+	} else {
+		presignedURL, err := s.cosService.GetPresignedURL(ctx, req.ResourcePath, ttl)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to presign download url: %v", err)
+			return nil, ErrInternalError
+		}
+		link.URL = presignedURL
+	}
+
+	zlog.CtxInfof(ctx, "download link created, userID: %s, path: %s, mode: %s, expiresAt: %v", user.UserID, req.ResourcePath, link.Mode, expiresAt)
+	return link, nil
+}
+
+// OpenDownload 校验转发下载令牌并打开文件，未开启 proxy 模式且不是本地存储时令牌一律无效
+func (s *COSServiceImpl) OpenDownload(ctx context.Context, token string) (*types.DownloadFile, error) {
+	if !s.config.ProxyDownload() {
+		return nil, ErrDownloadLinkInvalid
+	}
+	resourcePath, ok := util.ParseDownloadToken(s.config.Download.Secret, token, time.Now())
+	if !ok {
+		zlog.CtxWarnf(ctx, "invalid or expired download token")
+		return nil, ErrDownloadLinkInvalid
+	}
+
+	file, err := s.cosService.OpenFile(ctx, resourcePath)
+	if err != nil {
+		if errors.Is(err, adapter.ErrFileNotFound) {
+			return nil, ErrFileNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to open download file: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "proxy download started, path: %s, size: %d", resourcePath, file.ContentLength)
+	return &types.DownloadFile{
+		Body:          file.Body,
+		ContentType:   file.ContentType,
+		ContentLength: file.ContentLength,
+		FileName:      path.Base(resourcePath),
+	}, nil
+}
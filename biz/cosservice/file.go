@@ -0,0 +1,71 @@
+package cosservice
+
+import (
+	"context"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// ListFiles 当前用户上传的文件，按上传时间倒序，每页默认 20 条、最多 99 条
+func (s *COSServiceImpl) ListFiles(ctx context.Context, req *types.ListFilesParams) ([]*entity.File, int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, 0, ErrPermissionDenied
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	files, total, err := s.fileRepo.ListUserFiles(ctx, user.UserID, req.Purpose, page, min(pageSize, 99))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list user files: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	for _, file := range files {
+		file.URL = s.SignDeliveryURL(ctx, file.URL)
+	}
+	return files, total, nil
+}
+
+// DeleteFile 删除自己的文件，先删除存储中的对象再删除记录，释放占用的存储空间
+func (s *COSServiceImpl) DeleteFile(ctx context.Context, fileID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	if fileID == "" {
+		return ErrFileNotFound
+	}
+
+	file, err := s.fileRepo.GetFile(ctx, user.UserID, fileID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get file: %v", err)
+		return ErrInternalError
+	}
+	if file == nil {
+		return ErrFileNotFound
+	}
+
+	// 对象删除失败时保留记录，避免存储中留下无主文件；隔离的文件没有保存对象
+	if !file.Quarantined() {
+		if err := s.cosService.DeleteFile(ctx, file.ResourcePath); err != nil {
+			zlog.CtxErrorf(ctx, "failed to delete file object, fileID: %s, path: %s, error: %v", file.FileID, file.ResourcePath, err)
+			return ErrInternalError
+		}
+	}
+	if err := s.fileRepo.DeleteFiles(ctx, []string{file.FileID}); err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete file record: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "file deleted, userID: %s, fileID: %s", user.UserID, file.FileID)
+	return nil
+}
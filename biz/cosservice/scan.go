@@ -0,0 +1,90 @@
+package cosservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 隔离记录中病毒特征的最大长度，与存储字段一致
+const maxScanResultLength = 255
+
+// ScanUpload 扫描不保存的上传文件，调用方在扫描通过后再读取内容
+func (s *COSServiceImpl) ScanUpload(ctx context.Context, purpose string, fh *multipart.FileHeader) error {
+	if s.scanner == nil {
+		return nil
+	}
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open upload file: %v", err)
+		return ErrInternalError
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to read upload file: %v", err)
+		return ErrInternalError
+	}
+
+	filename, err := sanitizeFilename(fh.Filename)
+	if err != nil {
+		filename = purpose
+	}
+	sum := sha256.Sum256(data)
+	return s.scanFile(ctx, &entity.File{
+		UserID:      user.UserID,
+		Filename:    filename,
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+		Hash:        hex.EncodeToString(sum[:]),
+		Purpose:     purpose,
+	}, data)
+}
+
+// scanFile 保存或解析之前扫描文件内容，感染时记录为隔离的文件，对象不会保存
+func (s *COSServiceImpl) scanFile(ctx context.Context, file *entity.File, data []byte) error {
+	if s.scanner == nil {
+		return nil
+	}
+	result, err := s.scanner.Scan(ctx, file.Filename, data)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "file scan failed, userID: %s, filename: %s, error: %v", file.UserID, file.Filename, err)
+		return ErrFileScanFailed
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	zlog.CtxWarnf(ctx, "infected file quarantined, userID: %s, filename: %s, hash: %s, source: %s, signature: %s",
+		file.UserID, file.Filename, file.Hash, result.Source, result.Signature)
+	if file.FileID == "" {
+		if file.FileID, err = util.GenerateStringID(); err != nil {
+			zlog.CtxErrorf(ctx, "failed to generate file ID: %v", err)
+			return ErrFileInfected
+		}
+	}
+	file.Status = entity.FileStatusQuarantined
+	file.ScanResult = result.Source + ":" + result.Signature
+	if len(file.ScanResult) > maxScanResultLength {
+		file.ScanResult = file.ScanResult[:maxScanResultLength]
+	}
+	file.CreatedAt = time.Now()
+	if err := s.fileRepo.CreateFile(ctx, file); err != nil {
+		zlog.CtxErrorf(ctx, "failed to record quarantined file: %v", err)
+	}
+	return ErrFileInfected
+}
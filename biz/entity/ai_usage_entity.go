@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// AI token 用量的调用场景
+const (
+	AiUsageSceneChat     = "chat"              // AI对话，含对话中修改导图的工具调用
+	AiUsageSceneGenerate = "generate_mindmap"  // 生成导图
+	AiUsageSceneSummary  = "summarize_mindmap" // 总结导图
+)
+
+// TokenUsage 模型调用消耗的 token 数
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Total 输入与输出 token 总数，配额按总数计算
+func (t TokenUsage) Total() int64 {
+	return t.PromptTokens + t.CompletionTokens
+}
+
+// Add 累加另一次调用的用量
+func (t *TokenUsage) Add(other TokenUsage) {
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+}
+
+// AiUsageRecord 单次AI调用的 token 用量记录
+type AiUsageRecord struct {
+	RecordID  string
+	UserID    string
+	Scene     string
+	Provider  string // 实际使用的模型提供方
+	Model     string
+	Usage     TokenUsage
+	CreatedAt time.Time
+}
@@ -5,7 +5,10 @@ import (
 	"forge/infra/configs"
 	"forge/util"
 	"github.com/cloudwego/eino/schema"
+	"strconv"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 var (
@@ -16,11 +19,22 @@ var (
 )
 
 type Message struct {
+	MessageID  string            `json:"message_id"` // 会话内按顺序编号，用于分页游标
 	Content    string            `json:"content"`
 	Role       string            `json:"role"`
 	ToolCallID string            `json:"tool_call_id"`
 	ToolCalls  []schema.ToolCall `json:"tool_calls"`
 	Timestamp  time.Time         `json:"timestamp"`
+	Provider   string            `json:"provider,omitempty"` // ai消息由哪个模型提供方生成
+	Model      string            `json:"model,omitempty"`
+	Images     []MessageImage    `json:"images,omitempty"` // 用户消息附带的图片
+}
+
+// MessageImage 消息附带的图片，保存在对象存储中
+type MessageImage struct {
+	Key         string `json:"key"` // 对象存储中的key，如 user/123/chat_image/xxx.png
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"-"` // 发送给模型前读取的图片内容，不保存
 }
 
 type Conversation struct {
@@ -28,9 +42,15 @@ type Conversation struct {
 	UserID         string
 	MapID          string
 	Title          string
+	PresetID       string // 会话使用的对话预设，为空表示默认
 	Messages       []*Message
+	Archived       bool // 已归档的会话不出现在会话列表中，仍可搜索与继续对话
+	ArchivedAt     *time.Time
+	Pinned         bool // 置顶的会话排在列表最前面
+	SortOrder      int  // 用户手动调整的顺序，从小到大排列，0 表示未调整（新建的会话）
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	DeletedAt      *time.Time
 }
 
 func NewConversation(userID, mapID, title string) (*Conversation, error) {
@@ -58,6 +78,7 @@ func (c *Conversation) AddMessage(content, role, ToolCallID string, ToolCalls []
 	now := time.Now()
 
 	message := &Message{
+		MessageID:  strconv.Itoa(len(c.Messages)),
 		Content:    content,
 		Role:       role,
 		ToolCallID: ToolCallID,
@@ -83,9 +104,173 @@ func (c *Conversation) ProcessSystemPrompt(mapData string) {
 		c.AddMessage(text, SYSTEM, "", nil)
 	} else {
 		c.Messages[0] = &Message{
+			MessageID: c.Messages[0].MessageID,
 			Content:   text,
 			Role:      SYSTEM,
 			Timestamp: time.Now(),
 		}
 	}
 }
+
+// ApplyPersona 把对话预设的系统提示词放在系统消息之前，需在 ProcessSystemPrompt 之后调用
+func (c *Conversation) ApplyPersona(persona string) {
+	if persona == "" || len(c.Messages) == 0 || c.Messages[0].Role != SYSTEM {
+		return
+	}
+	c.Messages[0].Content = persona + "\n\n" + c.Messages[0].Content
+}
+
+// MessageIndex 查找消息的位置，不存在时返回 -1
+func (c *Conversation) MessageIndex(messageID string) int {
+	for i, message := range c.Messages {
+		if message.MessageID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Fork 把第 index 条及之前的聊天记录复制到同一导图下的新会话
+// 第 index 条是带工具调用的ai消息时一并复制紧随其后的工具结果，保证新会话的记录完整
+func (c *Conversation) Fork(index int, title string) (*Conversation, error) {
+	forked, err := NewConversation(c.UserID, c.MapID, title)
+	if err != nil {
+		return nil, err
+	}
+	forked.PresetID = c.PresetID
+
+	end := index + 1
+	for end < len(c.Messages) && len(c.Messages[index].ToolCalls) > 0 && c.Messages[end].Role == TOOL {
+		end++
+	}
+	for _, message := range c.Messages[:end] {
+		copied := *message
+		forked.Messages = append(forked.Messages, &copied)
+	}
+	return forked, nil
+}
+
+// FillMessageIDs 为没有编号的历史消息按位置补上编号，编号与 AddMessage 的规则一致
+func (c *Conversation) FillMessageIDs() {
+	for i, message := range c.Messages {
+		if message.MessageID == "" {
+			message.MessageID = strconv.Itoa(i)
+		}
+	}
+}
+
+// MessagesBefore 按游标分页：返回 beforeMessageID 之前（不含）最近的 limit 条消息，按时间正序
+// beforeMessageID 为空时从最新的消息开始；limit <= 0 时不限制条数；hasMore 表示更早的消息是否还有
+func (c *Conversation) MessagesBefore(beforeMessageID string, limit int) (messages []*Message, hasMore bool, ok bool) {
+	end := len(c.Messages)
+	if beforeMessageID != "" {
+		if end = c.MessageIndex(beforeMessageID); end < 0 {
+			return nil, false, false
+		}
+	}
+
+	start := 0
+	if limit > 0 && end > limit {
+		start = end - limit
+	}
+	return c.Messages[start:end], start > 0, true
+}
+
+// HistoryWindow 发送给模型的聊天记录：系统提示词加上从最新往前、不超过 maxMessages 条且估算 token 数不超过 maxTokens 的消息
+// 最新一条消息总会带上；窗口不会以工具消息开头，避免工具结果缺少对应的工具调用
+func (c *Conversation) HistoryWindow(maxMessages, maxTokens int) []*Message {
+	var system *Message
+	history := c.Messages
+	if len(history) > 0 && history[0].Role == SYSTEM {
+		system = history[0]
+		history = history[1:]
+	}
+
+	start := len(history)
+	tokens := 0
+	for start > 0 {
+		message := history[start-1]
+		tokens += EstimateTokens(message.Content)
+		for _, toolCall := range message.ToolCalls {
+			tokens += EstimateTokens(toolCall.Function.Arguments)
+		}
+		if start < len(history) && (len(history)-start >= maxMessages || tokens > maxTokens) {
+			break
+		}
+		start--
+	}
+	for start < len(history)-1 && history[start].Role == TOOL {
+		start++
+	}
+
+	window := make([]*Message, 0, len(history)-start+1)
+	if system != nil {
+		window = append(window, system)
+	}
+	return append(window, history[start:]...)
+}
+
+// EstimateTokens 粗略估算文本的 token 数：中文等非 ASCII 字符按每字 1 个，ASCII 按每 4 个字符 1 个
+func EstimateTokens(text string) int {
+	runes := utf8.RuneCountInString(text)
+	nonASCII := 0
+	for _, r := range text {
+		if r >= utf8.RuneSelf {
+			nonASCII++
+		}
+	}
+	return nonASCII + (runes-nonASCII+3)/4
+}
+
+// SearchSnippet 在用户与ai的消息中查找第一条包含关键词的消息（忽略大小写），返回消息ID与关键词前后各 radius 个字符的摘要
+// 只有标题匹配时返回空
+func (c *Conversation) SearchSnippet(keyword string, radius int) (messageID, snippet string) {
+	lowerKeyword := lowerRunes(keyword)
+	for _, message := range c.Messages {
+		if message.Role != USER && message.Role != ASSISTANT {
+			continue
+		}
+		content := []rune(message.Content)
+		index := runeIndex(lowerRunes(message.Content), lowerKeyword)
+		if index < 0 {
+			continue
+		}
+		start := max(index-radius, 0)
+		end := min(index+len(lowerKeyword)+radius, len(content))
+		snippet = string(content[start:end])
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+		if end < len(content) {
+			snippet += "…"
+		}
+		return message.MessageID, snippet
+	}
+	return "", ""
+}
+
+// lowerRunes 逐个字符转小写，字符数与原文一致，便于按位置截取摘要
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// runeIndex 按字符查找子串的位置
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// 安全事件类型
+const (
+	AuditActionLogin              = "login"                // 密码、免密链接或第三方登录
+	AuditActionResetPassword      = "reset_password"       // 通过验证码重置密码
+	AuditActionUpdateAccount      = "update_account"       // 绑定/换绑手机号或邮箱
+	AuditActionUnbindAccount      = "unbind_account"       // 解绑手机号或邮箱
+	AuditActionUpdateAvatar       = "update_avatar"        // 修改头像
+	AuditActionMergeAccount       = "merge_account"        // 合并账号，保留账号与被合并账号各记录一条
+	AuditActionAdminUpdateStatus  = "admin_update_status"  // 管理员禁用/启用用户
+	AuditActionAdminUpdateRole    = "admin_update_role"    // 管理员修改角色
+	AuditActionAdminResetPassword = "admin_reset_password" // 管理员重置密码
+	AuditActionContentBlocked     = "content_blocked"      // AI对话的输入或回复未通过内容审核
+)
+
+// 安全事件结果
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditLog 账号安全事件记录
+type AuditLog struct {
+	LogID     string
+	UserID    string // 事件所属的用户，登录失败且账号不存在时为空
+	ActorID   string // 操作人，本人操作时与 UserID 相同，管理员操作时为管理员ID
+	Action    string
+	Result    string // success / failure
+	Detail    string // 补充说明，如登录方式、失败原因、修改后的角色
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
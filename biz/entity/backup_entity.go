@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Backup 用户内容（导图及其会话）的一次备份
+type Backup struct {
+	BackupID          string
+	UserID            string
+	ResourcePath      string // 对象存储中的key，如 backup/20250101/123/456.json.gz
+	Size              int64  // 压缩后的字节数
+	SHA256            string // 压缩后内容的 sha256，恢复前用于完整性校验
+	MindMapCount      int64
+	ConversationCount int64
+	CreatedAt         time.Time
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// 账单状态
+const (
+	InvoiceStatusPending = "pending" // 已创建支付会话，等待支付
+	InvoiceStatusPaid    = "paid"    // 支付成功，套餐已生效
+	InvoiceStatusFailed  = "failed"  // 支付失败
+	InvoiceStatusExpired = "expired" // 支付会话过期
+)
+
+// Invoice 账单，一次购买对应一条账单
+type Invoice struct {
+	InvoiceID         string
+	UserID            string
+	Product           string // 商品标识，对应配置中的 billing.products
+	Plan              string // 购买的套餐
+	PeriodDays        int    // 套餐时长（天）
+	Amount            int64  // 金额，最小货币单位（如分）
+	Currency          string
+	Status            string
+	Provider          string // 支付渠道，如 stripe
+	ProviderSessionID string // 支付渠道侧的会话ID
+	CheckoutURL       string // 支付页面地址
+	PaidAt            *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
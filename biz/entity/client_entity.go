@@ -0,0 +1,42 @@
+package entity
+
+import "context"
+
+type clientIPCtxKey struct{}
+
+type languageCtxKey struct{}
+
+type userAgentCtxKey struct{}
+
+// WithClientIP 记录请求来源IP，供登录限流、登录记录等使用
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPCtxKey{}, ip)
+}
+
+// GetClientIP 获取请求来源IP，非 HTTP 请求链路中为空
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPCtxKey{}).(string)
+	return ip
+}
+
+// WithLanguage 记录请求方的语言偏好（Accept-Language），用于选择邮件模板等
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageCtxKey{}, lang)
+}
+
+// GetLanguage 获取请求方的语言偏好，未携带时为空
+func GetLanguage(ctx context.Context) string {
+	lang, _ := ctx.Value(languageCtxKey{}).(string)
+	return lang
+}
+
+// WithUserAgent 记录请求方的 User-Agent，用于登录记录
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentCtxKey{}, userAgent)
+}
+
+// GetUserAgent 获取请求方的 User-Agent，未携带时为空
+func GetUserAgent(ctx context.Context) string {
+	userAgent, _ := ctx.Value(userAgentCtxKey{}).(string)
+	return userAgent
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// 数据导出状态
+const (
+	DataExportStatusPending = "pending" // 导出中
+	DataExportStatusReady   = "ready"   // 导出完成，可下载
+)
+
+// DataExport 用户数据导出的进度，保存在 Redis 中，下载链接过期后一并失效
+type DataExport struct {
+	Status      string     `json:"status"`
+	URL         string     `json:"url,omitempty"`        // 导出文件的临时下载链接，导出完成后才有
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // 下载链接过期时间
+	RequestedAt time.Time  `json:"requested_at"`
+}
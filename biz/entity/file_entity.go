@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// File 上传到对象存储的文件元数据
+type File struct {
+	FileID         string
+	UserID         string // 文件所有者
+	ResourcePath   string // 对象存储中的key，如 user/123/attachment/xxx.pdf
+	URL            string // 访问URL
+	Filename       string // 原始文件名
+	ContentType    string
+	Size           int64
+	Hash           string // 内容的 SHA-256（十六进制）
+	Purpose        string // 文件用途
+	ConversationID string // 关联的会话ID（可为空）
+	Status         string // 文件状态，见 FileStatus*
+	ScanResult     string // 隔离文件命中的病毒特征
+	CreatedAt      time.Time
+}
+
+// 文件用途常量
+const (
+	FilePurposeAttachment = "attachment" // 聊天/生成导图上传的附件
+	FilePurposeExport     = "export"     // 导出产物
+	FilePurposeChatImage  = "chat_image" // 对话中附带的图片
+	FilePurposeAvatar     = "avatar"     // 用户头像
+	FilePurposeDocument   = "document"   // 生成导图时上传的文档，只扫描不保存
+)
+
+// 文件状态常量
+const (
+	FileStatusActive      = "active"      // 正常
+	FileStatusQuarantined = "quarantined" // 扫描出病毒，对象未保存，只保留记录
+)
+
+// Quarantined 文件是否因病毒被隔离
+func (f *File) Quarantined() bool {
+	return f.Status == FileStatusQuarantined
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// InviteCode 邀请码，由已注册用户生成，有效期内最多可使用 MaxUses 次
+type InviteCode struct {
+	Code      string
+	UserID    string // 生成邀请码的用户
+	MaxUses   int
+	UsedCount int
+	ExpiresAt *time.Time // 为空表示长期有效
+	CreatedAt time.Time
+}
+
+// Usable 邀请码未过期且还有剩余次数
+func (c *InviteCode) Usable(now time.Time) bool {
+	if c.UsedCount >= c.MaxUses {
+		return false
+	}
+	return c.ExpiresAt == nil || now.Before(*c.ExpiresAt)
+}
+
+// Referral 邀请关系，记录邀请人和通过邀请码注册的用户，供后续发放奖励
+type Referral struct {
+	InviterID string
+	InviteeID string
+	Code      string
+	CreatedAt time.Time
+}
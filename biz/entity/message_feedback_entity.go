@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 对AI回复的评价
+const (
+	FeedbackRatingUp   = "up"   // 赞
+	FeedbackRatingDown = "down" // 踩
+)
+
+// MaxFeedbackCommentLength 评价补充说明的最大长度（字符数），与数据库列宽一致
+const MaxFeedbackCommentLength = 500
+
+// 错误定义
+var (
+	ErrInvalidFeedbackRating  = errors.New("评价只能是 up 或 down")
+	ErrInvalidFeedbackComment = errors.New("评价说明不超过500个字符")
+)
+
+// MessageFeedback 用户对一条AI回复的评价，每条回复只保留最新的一次评价
+// Prompt、Reply 等字段为评价时的快照，便于导出后分析提示词效果，不随会话后续变化
+type MessageFeedback struct {
+	ConversationID string
+	MessageID      string
+	UserID         string
+	Rating         string // FeedbackRating*
+	Comment        string
+	Prompt         string // 该回复之前的最后一条用户消息
+	Reply          string // AI回复内容
+	Provider       string
+	Model          string
+	PresetID       string // 评价时会话使用的对话预设
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Normalize 去掉说明首尾空白并校验评价与说明
+func (f *MessageFeedback) Normalize() error {
+	f.Comment = strings.TrimSpace(f.Comment)
+	if f.Rating != FeedbackRatingUp && f.Rating != FeedbackRatingDown {
+		return ErrInvalidFeedbackRating
+	}
+	if utf8.RuneCountInString(f.Comment) > MaxFeedbackCommentLength {
+		return ErrInvalidFeedbackComment
+	}
+	return nil
+}
@@ -18,6 +18,9 @@ type MindMap struct {
 	Desc      string
 	Data      MindMapData
 	Layout    string
+	FolderID  string   // 所有者整理导图的文件夹，为空表示不在文件夹中
+	Tags      []string // 所有者给导图打的标签，只在所有者查询时填充
+	Starred   bool     // 当前用户是否收藏，只在查询详情与列表时填充
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt *time.Time
@@ -26,6 +29,7 @@ type MindMap struct {
 
 // NodeData 节点数据值对象
 type NodeData struct {
+	ID   string // 节点ID，评论按节点ID关联；为空、过长或重复时保存导图时重新生成
 	Text string
 	// 可扩展其他节点属性，如颜色、图标等
 }
@@ -36,6 +40,15 @@ type MindMapData struct {
 	Children []MindMapData // 子节点（递归结构）
 }
 
+// CountNodes 导图的节点数量（含根节点）
+func (d MindMapData) CountNodes() int64 {
+	count := int64(1)
+	for _, child := range d.Children {
+		count += child.CountNodes()
+	}
+	return count
+}
+
 // 上下文助手
 type mindMapCtxKey struct{}
 
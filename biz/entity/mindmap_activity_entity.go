@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// 导图变更记录类型
+const (
+	MindMapActivityNodeAdded   = "node_added"   // 添加节点（含其子节点）
+	MindMapActivityNodeEdited  = "node_edited"  // 修改节点文本
+	MindMapActivityNodeDeleted = "node_deleted" // 删除节点及其子节点
+	MindMapActivityRenamed     = "renamed"      // 修改导图标题
+	MindMapActivityShared      = "shared"       // 创建分享链接
+	MindMapActivityMemberAdded = "member_added" // 添加协作者或修改协作者角色
+)
+
+// MaxActivitiesPerUpdate 单次修改最多记录的节点变更数，超出的部分不再记录
+const MaxActivitiesPerUpdate = 50
+
+// MindMapActivity 导图的一条变更记录
+type MindMapActivity struct {
+	ActivityID string
+	MapID      string
+	OwnerID    string // 导图所有者，用于注销与合并账号
+	ActorID    string // 执行变更的用户
+	Type       string
+	Path       []int  // 节点变更的节点路径，node_deleted 为删除前的位置
+	Text       string // 变更后的文本；node_deleted 为被删除节点的文本；member_added 为协作者角色
+	OldText    string // node_edited、renamed 修改前的文本
+	TargetID   string // member_added 的协作者用户ID
+	CreatedAt  time.Time
+}
+
+// ActivityTypeOfChange 节点变更对应的变更记录类型
+func ActivityTypeOfChange(op string) string {
+	switch op {
+	case MindMapOpAddNode:
+		return MindMapActivityNodeAdded
+	case MindMapOpDeleteSubtree:
+		return MindMapActivityNodeDeleted
+	default:
+		return MindMapActivityNodeEdited
+	}
+}
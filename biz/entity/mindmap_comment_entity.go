@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"forge/util"
+)
+
+// 评论的长度与数量上限
+const (
+	MaxCommentLength  = 1000 // 评论内容的最大字符数
+	MaxCommentsPerMap = 1000 // 每个导图最多的评论数，包括已解决的评论
+	MaxNodeIDLength   = 64   // 节点ID的最大长度，与评论表的列宽一致
+)
+
+var ErrInvalidCommentContent = errors.New("评论内容不能为空且不超过1000个字符")
+
+// MindMapComment 导图节点上的评论
+type MindMapComment struct {
+	CommentID  string
+	MapID      string
+	OwnerID    string // 导图所有者，用于注销与合并账号
+	NodeID     string
+	UserID     string // 评论者
+	Content    string
+	Resolved   bool
+	ResolvedBy string // 标记为已解决的用户，未解决时为空
+	ResolvedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NormalizeCommentContent 去掉首尾空白并校验长度
+func NormalizeCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > MaxCommentLength {
+		return "", ErrInvalidCommentContent
+	}
+	return content, nil
+}
+
+// EnsureNodeIDs 为没有ID、ID过长或ID重复的节点生成新ID，已有的有效ID保持不变
+func (d *MindMapData) EnsureNodeIDs() error {
+	return d.ensureNodeIDs(make(map[string]bool))
+}
+
+func (d *MindMapData) ensureNodeIDs(seen map[string]bool) error {
+	if id := d.Data.ID; id == "" || len(id) > MaxNodeIDLength || seen[id] {
+		newID, err := util.GenerateStringID()
+		if err != nil {
+			return err
+		}
+		d.Data.ID = newID
+	}
+	seen[d.Data.ID] = true
+	for i := range d.Children {
+		if err := d.Children[i].ensureNodeIDs(seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindNode 按ID查找节点，不存在时返回 false
+func (d MindMapData) FindNode(nodeID string) (NodeData, bool) {
+	if d.Data.ID == nodeID {
+		return d.Data, true
+	}
+	for _, child := range d.Children {
+		if node, ok := child.FindNode(nodeID); ok {
+			return node, true
+		}
+	}
+	return NodeData{}, false
+}
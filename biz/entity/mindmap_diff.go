@@ -0,0 +1,117 @@
+package entity
+
+// 子节点按文本做最长公共子序列匹配时的规模上限，超出时按位置逐个比较
+const maxDiffMatrixSize = 250000
+
+// DiffMindMapData 比较修改前后的导图，返回节点的增加、修改与删除，最多返回 limit 条
+// 同一层的子节点按文本匹配，未匹配的节点在相同位置成对时视为修改，其余为增加或删除；
+// 增加与删除只记录子树的根节点，路径中增加、修改为修改后的位置，删除为修改前的位置
+func DiffMindMapData(old, new MindMapData, limit int) []MindMapChange {
+	d := &mindMapDiff{limit: limit}
+	d.node(old, new, nil)
+	return d.changes
+}
+
+type mindMapDiff struct {
+	changes []MindMapChange
+	limit   int
+}
+
+func (d *mindMapDiff) full() bool {
+	return len(d.changes) >= d.limit
+}
+
+func (d *mindMapDiff) add(change MindMapChange) {
+	if !d.full() {
+		d.changes = append(d.changes, change)
+	}
+}
+
+// node 比较同一位置的两个节点
+func (d *mindMapDiff) node(old, new MindMapData, path []int) {
+	if old.Data.Text != new.Data.Text {
+		d.add(MindMapChange{Op: MindMapOpRenameNode, Path: path, Text: new.Data.Text, OldText: old.Data.Text})
+	}
+	d.children(old.Children, new.Children, path)
+}
+
+// children 比较两组子节点
+func (d *mindMapDiff) children(old, new []MindMapData, path []int) {
+	if d.full() {
+		return
+	}
+	var oldGap, newGap []int
+	flush := func() {
+		paired := min(len(oldGap), len(newGap))
+		for k := 0; k < paired; k++ {
+			d.node(old[oldGap[k]], new[newGap[k]], childPath(path, newGap[k]))
+		}
+		for _, i := range oldGap[paired:] {
+			d.add(MindMapChange{Op: MindMapOpDeleteSubtree, Path: childPath(path, i), Text: old[i].Data.Text})
+		}
+		for _, j := range newGap[paired:] {
+			d.add(MindMapChange{Op: MindMapOpAddNode, Path: childPath(path, j), Text: new[j].Data.Text})
+		}
+		oldGap, newGap = oldGap[:0], newGap[:0]
+	}
+
+	lcs := textLCS(old, new)
+	i, j := 0, 0
+	for i < len(old) && j < len(new) {
+		switch {
+		case lcs != nil && old[i].Data.Text == new[j].Data.Text:
+			flush()
+			d.node(old[i], new[j], childPath(path, j))
+			i++
+			j++
+		case lcs != nil && lcs[i+1][j] >= lcs[i][j+1]:
+			oldGap = append(oldGap, i)
+			i++
+		case lcs != nil:
+			newGap = append(newGap, j)
+			j++
+		default:
+			// 子节点过多时不做匹配，按位置成对比较
+			oldGap = append(oldGap, i)
+			newGap = append(newGap, j)
+			i++
+			j++
+		}
+	}
+	for ; i < len(old); i++ {
+		oldGap = append(oldGap, i)
+	}
+	for ; j < len(new); j++ {
+		newGap = append(newGap, j)
+	}
+	flush()
+}
+
+// textLCS lcs[i][j] 为 old[i:] 与 new[j:] 按文本的最长公共子序列长度，规模超出上限时返回 nil
+func textLCS(old, new []MindMapData) [][]int {
+	if (len(old)+1)*(len(new)+1) > maxDiffMatrixSize {
+		return nil
+	}
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i].Data.Text == new[j].Data.Text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs
+}
+
+// childPath 返回新的切片，不与上层路径共用底层数组
+func childPath(path []int, index int) []int {
+	child := make([]int, len(path)+1)
+	copy(child, path)
+	child[len(path)] = index
+	return child
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 文件夹的限制
+const (
+	MaxFolderNameLength = 50  // 名称最大字符数
+	MaxFolderDepth      = 5   // 最多嵌套层数，顶层文件夹为第 1 层
+	MaxFoldersPerUser   = 200 // 每个用户最多的文件夹数
+)
+
+var ErrInvalidFolderName = errors.New("文件夹名称不能为空且不超过50个字符")
+
+// MindMapFolder 整理导图的文件夹，只对所有者自己的导图生效
+type MindMapFolder struct {
+	FolderID  string
+	UserID    string
+	ParentID  string // 上级文件夹，为空表示顶层
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeFolderName 去掉首尾空白并校验长度
+func NormalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxFolderNameLength {
+		return "", ErrInvalidFolderName
+	}
+	return name, nil
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 导图的访问角色，所有者不保存在协作者表中
+const (
+	MindMapRoleOwner  = "owner"
+	MindMapRoleEditor = "editor" // 可以查看与修改，不能删除、分享或管理协作者
+	MindMapRoleViewer = "viewer" // 只能查看
+)
+
+// MaxMindMapMembers 每个导图最多的协作者数
+const MaxMindMapMembers = 50
+
+var ErrInvalidMemberRole = errors.New("协作者角色只能是 viewer 或 editor")
+
+// MindMapMember 导图的协作者
+type MindMapMember struct {
+	MapID     string
+	OwnerID   string // 导图所有者，按所有者查询导图
+	UserID    string
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ValidateMemberRole 协作者只能是查看者或编辑者
+func ValidateMemberRole(role string) error {
+	if role != MindMapRoleEditor && role != MindMapRoleViewer {
+		return ErrInvalidMemberRole
+	}
+	return nil
+}
+
+// CanEditMindMap 角色是否可以修改导图内容
+func CanEditMindMap(role string) bool {
+	return role == MindMapRoleOwner || role == MindMapRoleEditor
+}
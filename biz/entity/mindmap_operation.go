@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 导图编辑操作类型，由AI通过工具调用提出
+const (
+	MindMapOpAddNode       = "add_node"       // 在 Path 指向的节点下追加子节点
+	MindMapOpRenameNode    = "rename_node"    // 修改 Path 指向的节点文本
+	MindMapOpDeleteSubtree = "delete_subtree" // 删除 Path 指向的节点及其所有子节点
+)
+
+// MaxMindMapOperations 单次最多应用的操作数
+const MaxMindMapOperations = 50
+
+// 错误定义
+var (
+	ErrInvalidMindMapOperation = errors.New("无效的导图操作")
+	ErrInvalidNodePath         = errors.New("节点路径不存在")
+	ErrEmptyNodeText           = errors.New("节点文本不能为空")
+	ErrDeleteRootNode          = errors.New("不能删除根节点")
+	ErrTooManyOperations       = errors.New("导图操作过多")
+)
+
+// MindMapOperation 导图编辑操作
+// Path 为从根节点开始逐层的子节点下标（从0开始），空表示根节点；多个操作依次执行，后面的操作基于前面操作后的导图
+type MindMapOperation struct {
+	Op   string
+	Path []int
+	Text string // add_node 的新节点文本、rename_node 的新文本
+}
+
+// MindMapChange 已应用的导图变更，供前端展示动画
+type MindMapChange struct {
+	Op      string
+	Path    []int  // add_node 为新节点的路径，其余为被操作节点的路径
+	Text    string // 变更后的文本，delete_subtree 为被删除节点的文本
+	OldText string // rename_node 修改前的文本
+}
+
+// Clone 深拷贝导图数据，修改副本不影响原导图
+func (d MindMapData) Clone() MindMapData {
+	clone := MindMapData{Data: d.Data}
+	if d.Children != nil {
+		clone.Children = make([]MindMapData, len(d.Children))
+		for i, child := range d.Children {
+			clone.Children[i] = child.Clone()
+		}
+	}
+	return clone
+}
+
+// ApplyOperations 依次应用导图操作，任一操作无效时返回错误，此时导图可能已被部分修改，调用方应在副本上执行
+func (d *MindMapData) ApplyOperations(ops []MindMapOperation) ([]MindMapChange, error) {
+	if len(ops) > MaxMindMapOperations {
+		return nil, ErrTooManyOperations
+	}
+	changes := make([]MindMapChange, 0, len(ops))
+	for i, op := range ops {
+		change, err := d.applyOperation(op)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个操作 %s %v: %w", i+1, op.Op, op.Path, err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (d *MindMapData) applyOperation(op MindMapOperation) (MindMapChange, error) {
+	text := strings.TrimSpace(op.Text)
+	switch op.Op {
+	case MindMapOpAddNode:
+		if text == "" {
+			return MindMapChange{}, ErrEmptyNodeText
+		}
+		parent, err := d.node(op.Path)
+		if err != nil {
+			return MindMapChange{}, err
+		}
+		parent.Children = append(parent.Children, MindMapData{Data: NodeData{Text: text}})
+		path := append(append([]int{}, op.Path...), len(parent.Children)-1)
+		return MindMapChange{Op: op.Op, Path: path, Text: text}, nil
+	case MindMapOpRenameNode:
+		if text == "" {
+			return MindMapChange{}, ErrEmptyNodeText
+		}
+		node, err := d.node(op.Path)
+		if err != nil {
+			return MindMapChange{}, err
+		}
+		oldText := node.Data.Text
+		node.Data.Text = text
+		return MindMapChange{Op: op.Op, Path: op.Path, Text: text, OldText: oldText}, nil
+	case MindMapOpDeleteSubtree:
+		if len(op.Path) == 0 {
+			return MindMapChange{}, ErrDeleteRootNode
+		}
+		parent, err := d.node(op.Path[:len(op.Path)-1])
+		if err != nil {
+			return MindMapChange{}, err
+		}
+		index := op.Path[len(op.Path)-1]
+		if index < 0 || index >= len(parent.Children) {
+			return MindMapChange{}, ErrInvalidNodePath
+		}
+		removed := parent.Children[index].Data.Text
+		parent.Children = append(parent.Children[:index], parent.Children[index+1:]...)
+		return MindMapChange{Op: op.Op, Path: op.Path, Text: removed}, nil
+	}
+	return MindMapChange{}, ErrInvalidMindMapOperation
+}
+
+// node 按路径查找节点
+func (d *MindMapData) node(path []int) (*MindMapData, error) {
+	node := d
+	for _, index := range path {
+		if index < 0 || index >= len(node.Children) {
+			return nil, ErrInvalidNodePath
+		}
+		node = &node.Children[index]
+	}
+	return node, nil
+}
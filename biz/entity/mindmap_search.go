@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// 全文搜索的限制
+const (
+	MaxSearchQueryLength = 100 // 搜索词最大字符数
+	MaxSearchTerms       = 5   // 最多按几个关键词搜索
+	MinSearchTermLength  = 2   // 关键词最少字符数，与 MySQL ngram 分词长度一致
+	MaxSearchSnippets    = 3   // 每个导图最多返回的节点摘要数
+	searchSnippetRadius  = 20  // 摘要中关键词前后保留的字符数
+)
+
+var ErrInvalidSearchQuery = errors.New("搜索词至少包含一个不少于2个字符的关键词，且不超过100个字符")
+
+// ParseSearchTerms 按空白拆分搜索词，去掉过短与重复的关键词
+func ParseSearchTerms(query string) ([]string, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+	terms := make([]string, 0, MaxSearchTerms)
+	seen := make(map[string]struct{})
+	for _, term := range strings.Fields(query) {
+		// 双引号等布尔模式的运算符由存储层处理，这里只去掉对匹配无意义的引号
+		term = strings.ToLower(strings.Trim(term, `"'`))
+		if utf8.RuneCountInString(term) < MinSearchTermLength {
+			continue
+		}
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		terms = append(terms, term)
+		if len(terms) == MaxSearchTerms {
+			break
+		}
+	}
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+	return terms, nil
+}
+
+// SearchContent 导图全部节点文本，每个节点一行，用于建立全文索引
+func (d MindMapData) SearchContent() string {
+	var b strings.Builder
+	d.walk(func(node MindMapData) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(node.Data.Text)
+	})
+	return b.String()
+}
+
+// SearchSnippets 包含关键词的节点摘要（按导图中的顺序），关键词用 <em> 标出，其余文本做 HTML 转义
+func (d MindMapData) SearchSnippets(terms []string) []string {
+	var snippets []string
+	d.walk(func(node MindMapData) {
+		if len(snippets) >= MaxSearchSnippets {
+			return
+		}
+		if snippet, ok := HighlightTerms(node.Data.Text, terms, searchSnippetRadius); ok {
+			snippets = append(snippets, snippet)
+		}
+	})
+	return snippets
+}
+
+func (d MindMapData) walk(visit func(node MindMapData)) {
+	visit(d)
+	for _, child := range d.Children {
+		child.walk(visit)
+	}
+}
+
+// HighlightTerms 用 <em> 标出文本中的关键词（不区分大小写），没有关键词时返回 false
+// radius 大于0时只保留第一个关键词前后 radius 个字符
+func HighlightTerms(text string, terms []string, radius int) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// 个别字符转小写后长度变化，退化为区分大小写匹配
+		lower = runes
+	}
+
+	// 标出每个字符是否属于某个关键词
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			if string(lower[i:i+len(termRunes)]) != term {
+				continue
+			}
+			for j := i; j < i+len(termRunes); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if radius > 0 {
+		start = max(first-radius, 0)
+		end = min(first+radius*2, len(runes))
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString("<em>" + segment + "</em>")
+		} else {
+			b.WriteString(segment)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// 分享密码长度（字符数）
+const (
+	MinSharePasswordLength = 4
+	MaxSharePasswordLength = 32
+)
+
+var ErrInvalidSharePassword = errors.New("访问密码长度为4-32个字符")
+
+// MindMapShare 导图的只读分享链接
+// 链接中的令牌由分享ID与签名组成，不单独保存；设置了访问密码时只保存密码哈希
+type MindMapShare struct {
+	ShareID      string
+	MapID        string
+	OwnerID      string
+	PasswordHash string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// HasPassword 是否需要访问密码
+func (s *MindMapShare) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// Expired 分享链接在 now 时是否已过期
+func (s *MindMapShare) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// ValidateSharePassword 校验访问密码长度，空密码表示不设置密码
+func ValidateSharePassword(password string) error {
+	if password == "" {
+		return nil
+	}
+	if n := utf8.RuneCountInString(password); n < MinSharePasswordLength || n > MaxSharePasswordLength {
+		return ErrInvalidSharePassword
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// MindMapStar 用户收藏的导图，可以收藏自己的导图与与我共享的导图
+type MindMapStar struct {
+	UserID    string
+	MapID     string
+	CreatedAt time.Time
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "strings"
+
+// MindMapSummary AI对导图的总结
+type MindMapSummary struct {
+	Summary  string          // 整体总结
+	Branches []BranchSummary // 各一级分支的要点，按导图中的顺序
+}
+
+// BranchSummary 一级分支的要点
+type BranchSummary struct {
+	Title     string
+	KeyPoints []string
+}
+
+// Markdown 总结保存为聊天记录时的文本
+func (s *MindMapSummary) Markdown() string {
+	var b strings.Builder
+	b.WriteString(s.Summary)
+	for _, branch := range s.Branches {
+		b.WriteString("\n\n### ")
+		b.WriteString(branch.Title)
+		for _, point := range branch.KeyPoints {
+			b.WriteString("\n- ")
+			b.WriteString(point)
+		}
+	}
+	return b.String()
+}
+
+// Outline 导图的缩进大纲，根节点单独一行，子节点逐层缩进，用于构造提示词
+func (d MindMapData) Outline() string {
+	var b strings.Builder
+	b.WriteString(d.Data.Text)
+	for _, child := range d.Children {
+		child.writeOutline(&b, 0)
+	}
+	return b.String()
+}
+
+func (d MindMapData) writeOutline(b *strings.Builder, depth int) {
+	b.WriteString("\n")
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("- ")
+	b.WriteString(d.Data.Text)
+	for _, child := range d.Children {
+		child.writeOutline(b, depth+1)
+	}
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 标签的限制
+const (
+	MaxTagNameLength  = 20  // 名称最大字符数
+	MaxTagsPerMap     = 10  // 每个导图最多的标签数
+	MaxTagsPerUser    = 100 // 每个用户最多的标签数
+	MaxTagFilterCount = 5   // 列表一次最多按几个标签筛选
+)
+
+var (
+	ErrInvalidTagName = errors.New("标签名称不能为空且不超过20个字符")
+	ErrTooManyTags    = errors.New("每个导图最多10个标签")
+)
+
+// MindMapTag 用户给自己的导图打的标签，同一用户的标签名称唯一
+type MindMapTag struct {
+	TagID     string
+	UserID    string
+	Name      string
+	MapCount  int64 // 带有该标签的导图数，只在统计时填充
+	CreatedAt time.Time
+}
+
+// NormalizeTagNames 去掉首尾空白、去重并校验名称与数量，保持原有顺序
+func NormalizeTagNames(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || utf8.RuneCountInString(name) > MaxTagNameLength {
+			return nil, ErrInvalidTagName
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	if len(result) > MaxTagsPerMap {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
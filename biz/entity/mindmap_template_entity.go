@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTemplateCategoryLength 模板分类名称最大字符数
+const MaxTemplateCategoryLength = 20
+
+var ErrInvalidTemplateCategory = errors.New("模板分类不能为空且不超过20个字符")
+
+// MindMapTemplate 管理员维护的导图模板，所有用户可以基于模板创建自己的导图
+type MindMapTemplate struct {
+	TemplateID string
+	Title      string
+	Desc       string
+	Category   string
+	Layout     string
+	Data       MindMapData
+	Sort       int    // 同一分类中按从小到大排列
+	CreatedBy  string // 创建模板的管理员
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Normalize 去掉标题、描述与分类首尾空白并校验，标题等字段的规则与导图一致
+func (t *MindMapTemplate) Normalize() error {
+	t.Title = strings.TrimSpace(t.Title)
+	t.Desc = strings.TrimSpace(t.Desc)
+	t.Category = strings.TrimSpace(t.Category)
+	if t.Category == "" || utf8.RuneCountInString(t.Category) > MaxTemplateCategoryLength {
+		return ErrInvalidTemplateCategory
+	}
+	mindMap := MindMap{Title: t.Title, Desc: t.Desc, Layout: t.Layout, Data: t.Data}
+	return mindMap.Validate()
+}
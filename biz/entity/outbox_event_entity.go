@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// 领域事件类型，投递到 Redis Stream 时作为 Stream 名称的后缀
+const (
+	EventUserRegistered = "user.registered" // 注册成功
+	EventUserMerged     = "user.merged"     // 合并账号，Key 为保留的账号
+)
+
+// OutboxEvent 发件箱中的领域事件，与业务数据在同一个事务中写入，由后台任务投递
+// 投递至少一次，一般按写入顺序投递，投递失败重试时可能晚于之后的事件；消费方需按 EventID 去重
+// 失败次数达到上限的事件标记为投递失败，不再投递，需人工处理
+type OutboxEvent struct {
+	ID            uint64 // 自增ID，按写入顺序投递
+	EventID       string
+	Topic         string
+	Key           string // 事件所属的对象，如用户ID
+	Payload       string // JSON
+	Attempts      int    // 已投递失败的次数
+	LastError     string
+	NextAttemptAt time.Time // 投递失败后下次重试的时间
+	PublishedAt   *time.Time
+	FailedAt      *time.Time // 失败次数达到上限、放弃投递的时间
+	CreatedAt     time.Time
+}
+
+// UserRegisteredEvent 注册成功事件的内容
+type UserRegisteredEvent struct {
+	UserID       string    `json:"user_id"`
+	AccountType  string    `json:"account_type"`         // phone/email/username
+	InviterID    string    `json:"inviter_id,omitempty"` // 使用邀请码注册时为邀请人
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// UserMergedEvent 合并账号事件的内容
+type UserMergedEvent struct {
+	UserID        string    `json:"user_id"`        // 保留的账号
+	MergedUserID  string    `json:"merged_user_id"` // 被合并的账号，已标记删除
+	MindMaps      int64     `json:"mindmaps"`
+	Conversations int64     `json:"conversations"`
+	MergedAt      time.Time `json:"merged_at"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// PasswordHistory 用户设置过的密码，只保存哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	UserID       string
+	PasswordHash string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// 套餐常量
+const (
+	PlanFree = "free" // 免费版，新用户默认套餐
+	PlanPro  = "pro"  // 专业版
+)
+
+// PlanLimits 套餐配额，0 表示不限制
+type PlanLimits struct {
+	MaxMindMaps         int64 // 最多可创建的导图数量
+	MaxNodesPerMap      int64 // 单个导图最多的节点数量（含根节点）
+	MaxStorageBytes     int64 // 上传文件占用的最大存储空间（字节）
+	AIRequestsPerDay    int64 // 每日AI调用次数（对话与生成导图）
+	AIRequestsPerMinute int64 // 每分钟AI调用次数，用于限流
+	AIConcurrency       int64 // 同时进行中的AI生成任务数（对话与生成导图）
+	AITokensPerDay      int64 // 每日AI token 用量（输入与输出合计）
+	AITokensPerMonth    int64 // 每月AI token 用量（输入与输出合计）
+}
+
+// GetPlan 获取用户当前生效的套餐
+// 历史数据未设置套餐、或付费套餐已到期（定时任务尚未降级）时视为免费版
+func (u *User) GetPlan() string {
+	if u.Plan == "" {
+		return PlanFree
+	}
+	if u.PlanExpiresAt != nil && u.PlanExpiresAt.Before(time.Now()) {
+		return PlanFree
+	}
+	return u.Plan
+}
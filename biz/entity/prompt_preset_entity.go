@@ -0,0 +1,142 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 预设字段的最大长度（字符数），与数据库列宽一致
+const (
+	MaxPresetNameLength         = 32
+	MaxPresetDescriptionLength  = 255
+	MaxPresetSystemPromptLength = 4000
+)
+
+// 错误定义
+var (
+	ErrInvalidPresetName   = errors.New("预设名称不能为空且不超过32个字符")
+	ErrInvalidPresetPrompt = errors.New("预设的系统提示词不能为空且不超过4000个字符")
+	ErrInvalidPresetDesc   = errors.New("预设描述不超过255个字符")
+	ErrInvalidModelParams  = errors.New("模型参数超出范围")
+)
+
+// 模型参数的取值范围
+const (
+	MinTemperature      float32 = 0
+	MaxTemperature      float32 = 2
+	MinTopP             float32 = 0
+	MaxTopP             float32 = 1
+	MinFrequencyPenalty float32 = -2
+	MaxFrequencyPenalty float32 = 2
+)
+
+// ModelParams 模型调用参数，nil 表示使用提供方默认值
+type ModelParams struct {
+	Temperature      *float32 // 0 ~ 2
+	TopP             *float32 // 0 ~ 1
+	MaxTokens        *int     // 单次回复的最大 token 数
+	FrequencyPenalty *float32 // -2 ~ 2
+}
+
+// Validate 校验参数范围
+func (p ModelParams) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < MinTemperature || *p.Temperature > MaxTemperature) {
+		return ErrInvalidModelParams
+	}
+	if p.TopP != nil && (*p.TopP < MinTopP || *p.TopP > MaxTopP) {
+		return ErrInvalidModelParams
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return ErrInvalidModelParams
+	}
+	if p.FrequencyPenalty != nil && (*p.FrequencyPenalty < MinFrequencyPenalty || *p.FrequencyPenalty > MaxFrequencyPenalty) {
+		return ErrInvalidModelParams
+	}
+	return nil
+}
+
+// Merge 用 override 中指定的参数覆盖当前参数，未指定的保留原值
+func (p ModelParams) Merge(override ModelParams) ModelParams {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.MaxTokens != nil {
+		p.MaxTokens = override.MaxTokens
+	}
+	if override.FrequencyPenalty != nil {
+		p.FrequencyPenalty = override.FrequencyPenalty
+	}
+	return p
+}
+
+// Clamp 将超出范围的参数收敛到边界，maxTokensLimit 为单次回复 token 数的上限，0 表示不限制
+func (p ModelParams) Clamp(maxTokensLimit int) ModelParams {
+	if p.Temperature != nil {
+		p.Temperature = clampFloat32(*p.Temperature, MinTemperature, MaxTemperature)
+	}
+	if p.TopP != nil {
+		p.TopP = clampFloat32(*p.TopP, MinTopP, MaxTopP)
+	}
+	if p.MaxTokens != nil {
+		maxTokens := max(*p.MaxTokens, 1)
+		if maxTokensLimit > 0 {
+			maxTokens = min(maxTokens, maxTokensLimit)
+		}
+		p.MaxTokens = &maxTokens
+	}
+	if p.FrequencyPenalty != nil {
+		p.FrequencyPenalty = clampFloat32(*p.FrequencyPenalty, MinFrequencyPenalty, MaxFrequencyPenalty)
+	}
+	return p
+}
+
+// clampFloat32 返回新的指针，不修改调用方传入的值
+func clampFloat32(v, lo, hi float32) *float32 {
+	v = min(max(v, lo), hi)
+	return &v
+}
+
+// PromptPreset 对话预设（人设）：系统提示词与模型参数
+// OwnerID 为空的是管理员创建的全局预设，所有用户可用；否则只有创建者可用
+type PromptPreset struct {
+	PresetID     string
+	OwnerID      string
+	Name         string
+	Description  string
+	SystemPrompt string
+	Params       ModelParams
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IsGlobal 是否为全局预设
+func (p *PromptPreset) IsGlobal() bool {
+	return p.OwnerID == ""
+}
+
+// UsableBy 用户是否可以在会话中使用该预设
+func (p *PromptPreset) UsableBy(userID string) bool {
+	return p.IsGlobal() || p.OwnerID == userID
+}
+
+// Normalize 去掉名称、描述首尾空白并校验各字段
+func (p *PromptPreset) Normalize() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Description = strings.TrimSpace(p.Description)
+	p.SystemPrompt = strings.TrimSpace(p.SystemPrompt)
+	if p.Name == "" || utf8.RuneCountInString(p.Name) > MaxPresetNameLength {
+		return ErrInvalidPresetName
+	}
+	if utf8.RuneCountInString(p.Description) > MaxPresetDescriptionLength {
+		return ErrInvalidPresetDesc
+	}
+	if p.SystemPrompt == "" || utf8.RuneCountInString(p.SystemPrompt) > MaxPresetSystemPromptLength {
+		return ErrInvalidPresetPrompt
+	}
+	return p.Params.Validate()
+}
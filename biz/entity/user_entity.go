@@ -12,8 +12,17 @@ import (
 type User struct {
 	UserID   string `json:"user_id"`   // 用户ID
 	UserName string `json:"user_name"` // 用户名
-	Password string `json:"-"`         //密码 无json
-	Avatar   string `json:"avatar"`    // 头像URL
+	Password string `json:"-"`         //密码哈希 无json；从缓存读取的用户不含哈希，校验密码时需按 WithPassword 重新查询
+	// 是否设置了密码，从缓存读取的用户没有哈希，以此判断
+	PasswordSet bool   `json:"-"`
+	Avatar      string `json:"avatar"` // 头像URL
+
+	// 个人资料，均为选填
+	Nickname string     `json:"nickname"` // 昵称
+	Bio      string     `json:"bio"`      // 个人简介
+	Gender   string     `json:"gender"`   // 性别，见 UserGender 常量，空表示未设置
+	Birthday *time.Time `json:"birthday"` // 生日，只保留日期
+	Location string     `json:"location"` // 所在地
 
 	//登录方式  二选一
 	Phone string `json:"phone"` // 手机号
@@ -21,6 +30,16 @@ type User struct {
 
 	//用户状态 1：正常 0：禁用
 	Status int `json:"status"`
+	// 禁用原因与到期时间，到期时间为空表示需管理员手动恢复
+	SuspendReason  string     `json:"suspend_reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+
+	// 角色 user/admin
+	Role string `json:"role"`
+
+	// 套餐 free/pro
+	Plan          string     `json:"plan"`
+	PlanExpiresAt *time.Time `json:"plan_expires_at"` // 付费套餐到期时间，为空表示永久
 
 	// 时间信息
 	CreatedAt   time.Time  `json:"created_at"`    // 创建时间
@@ -30,6 +49,13 @@ type User struct {
 	PhoneVerified bool `json:"phone_verified"` // 手机号是否已验证
 	EmailVerified bool `json:"email_verified"` // 邮箱是否已验证
 
+	// 通知偏好
+	ReminderOptOut bool       `json:"reminder_opt_out"` // 是否退订绑定联系方式提醒邮件
+	ReminderSentAt *time.Time `json:"-"`                // 最近一次发送提醒邮件的时间
+
+	// 注销申请后计划彻底删除的时间，为空表示未申请注销；到期前重新登录可撤销
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+
 	Dogs []*Dog
 	// ... ex
 }
@@ -40,6 +66,72 @@ const (
 	UserStatusDisabled = 0 // 禁用
 )
 
+// Suspended 用户当前是否处于禁用状态，禁用到期后视为正常
+func (u *User) Suspended(now time.Time) bool {
+	if u.Status == UserStatusActive {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
+// 角色常量
+const (
+	UserRoleUser  = "user"  // 普通用户
+	UserRoleAdmin = "admin" // 管理员，可访问 /admin 接口
+)
+
+// HasRole 用户是否拥有任一角色，未设置角色的旧数据视为普通用户
+func (u *User) HasRole(roles ...string) bool {
+	role := u.Role
+	if role == "" {
+		role = UserRoleUser
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPassword 用户是否设置了密码，从缓存读取的用户不含哈希，按 PasswordSet 判断
+func (u *User) HasPassword() bool {
+	return u.PasswordSet || u.Password != ""
+}
+
+// UserBrief 用户的公开展示信息，供导图、AI对话等模块展示所有者，不包含联系方式等隐私字段
+type UserBrief struct {
+	UserID   string `json:"user_id"`
+	UserName string `json:"user_name"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// Brief 取出用户的公开展示信息
+func (u *User) Brief() *UserBrief {
+	return &UserBrief{
+		UserID:   u.UserID,
+		UserName: u.UserName,
+		Nickname: u.Nickname,
+		Avatar:   u.Avatar,
+	}
+}
+
+// DisplayName 展示名称，设置了昵称时优先使用昵称
+func (b *UserBrief) DisplayName() string {
+	if b.Nickname != "" {
+		return b.Nickname
+	}
+	return b.UserName
+}
+
+// 性别常量
+const (
+	UserGenderMale   = "male"
+	UserGenderFemale = "female"
+	UserGenderOther  = "other"
+)
+
 type Dog struct {
 	DogID   string
 	DogName string
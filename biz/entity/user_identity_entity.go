@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// 第三方登录平台
+const (
+	OAuthProviderWeChat = "wechat"
+	OAuthProviderGitHub = "github"
+	OAuthProviderGoogle = "google"
+)
+
+// UserIdentity 用户绑定的第三方账号
+type UserIdentity struct {
+	UserID      string
+	Provider    string // 第三方平台：wechat/github/google
+	ProviderUID string // 平台内的唯一标识，微信优先使用 unionid，GitHub 为数字ID，Google 为 sub
+	OpenID      string // 微信 openid，其他平台为空
+	NickName    string // 绑定时的第三方昵称
+	Avatar      string // 绑定时的第三方头像
+	CreatedAt   time.Time
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// UserQuotaOverride 管理员为单个用户单独设置的配额，优先于套餐配额
+// 字段为空表示沿用套餐配额，为 0 表示不限制
+type UserQuotaOverride struct {
+	UserID          string
+	MaxMindMaps     *int64
+	MaxNodesPerMap  *int64
+	MaxStorageBytes *int64
+	UpdatedBy       string // 设置配额的管理员
+	UpdatedAt       time.Time
+}
+
+// Empty 没有任何单独设置的配额
+func (o *UserQuotaOverride) Empty() bool {
+	return o.MaxMindMaps == nil && o.MaxNodesPerMap == nil && o.MaxStorageBytes == nil
+}
+
+// Apply 用单独设置的配额覆盖套餐配额
+func (o *UserQuotaOverride) Apply(limits PlanLimits) PlanLimits {
+	if o == nil {
+		return limits
+	}
+	if o.MaxMindMaps != nil {
+		limits.MaxMindMaps = *o.MaxMindMaps
+	}
+	if o.MaxNodesPerMap != nil {
+		limits.MaxNodesPerMap = *o.MaxNodesPerMap
+	}
+	if o.MaxStorageBytes != nil {
+		limits.MaxStorageBytes = *o.MaxStorageBytes
+	}
+	return limits
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// UserSession 一次成功登录产生的会话，续签令牌时沿用同一会话
+type UserSession struct {
+	SessionID      string
+	UserID         string
+	Device         string // 根据 User-Agent 识别的设备，如 Chrome / Windows
+	IP             string // 登录时的来源IP
+	UserAgent      string
+	RefreshTokenID string     // 当前有效的刷新令牌 jti，续签后更新
+	CreatedAt      time.Time  // 登录时间
+	LastActiveAt   time.Time  // 最近一次签发或续签令牌的时间
+	ExpiresAt      time.Time  // 刷新令牌过期时间，过期后会话失效
+	RevokedAt      *time.Time // 退出登录或被远程下线的时间
+}
+
+// Active 会话是否仍然有效
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,247 @@
+package erasureservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 错误定义
+var (
+	ErrInternalError = errors.New("内部错误")
+)
+
+// eraseBatchSize 每批处理的待删除账号数
+const eraseBatchSize = 50
+
+// AccountErasureServiceImpl 注销账号清理服务实现
+type AccountErasureServiceImpl struct {
+	cosService   adapter.COSService
+	userRepo     repo.UserRepo
+	identityRepo repo.UserIdentityRepo
+	sessionRepo  repo.UserSessionRepo
+	historyRepo  repo.PasswordHistoryRepo
+	auditRepo    repo.AuditLogRepo
+	inviteRepo   repo.InviteRepo
+	aiUsageRepo  repo.AiUsageRepo
+	presetRepo   repo.PromptPresetRepo
+	feedbackRepo repo.MessageFeedbackRepo
+	shareRepo    repo.IMindMapShareRepo
+	memberRepo   repo.IMindMapMemberRepo
+	folderRepo   repo.IMindMapFolderRepo
+	tagRepo      repo.IMindMapTagRepo
+	activityRepo repo.IMindMapActivityRepo
+	commentRepo  repo.IMindMapCommentRepo
+	starRepo     repo.IMindMapStarRepo
+	quotaRepo    repo.UserQuotaRepo
+	mindMapRepo  repo.IMindMapRepo
+	aiChatRepo   repo.AiChatRepo
+	fileRepo     repo.FileRepo
+	backupRepo   repo.BackupRepo
+	exporter     types.IDataExportService
+}
+
+func NewAccountErasureServiceImpl(cosService adapter.COSService, userRepo repo.UserRepo, identityRepo repo.UserIdentityRepo, mindMapRepo repo.IMindMapRepo,
+	aiChatRepo repo.AiChatRepo, fileRepo repo.FileRepo, backupRepo repo.BackupRepo, sessionRepo repo.UserSessionRepo,
+	historyRepo repo.PasswordHistoryRepo, auditRepo repo.AuditLogRepo, exporter types.IDataExportService, inviteRepo repo.InviteRepo,
+	aiUsageRepo repo.AiUsageRepo, presetRepo repo.PromptPresetRepo, feedbackRepo repo.MessageFeedbackRepo,
+	shareRepo repo.IMindMapShareRepo, memberRepo repo.IMindMapMemberRepo, folderRepo repo.IMindMapFolderRepo,
+	tagRepo repo.IMindMapTagRepo, activityRepo repo.IMindMapActivityRepo,
+	commentRepo repo.IMindMapCommentRepo, starRepo repo.IMindMapStarRepo, quotaRepo repo.UserQuotaRepo) *AccountErasureServiceImpl {
+	return &AccountErasureServiceImpl{
+		cosService:   cosService,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		mindMapRepo:  mindMapRepo,
+		aiChatRepo:   aiChatRepo,
+		fileRepo:     fileRepo,
+		backupRepo:   backupRepo,
+		sessionRepo:  sessionRepo,
+		historyRepo:  historyRepo,
+		auditRepo:    auditRepo,
+		exporter:     exporter,
+		inviteRepo:   inviteRepo,
+		aiUsageRepo:  aiUsageRepo,
+		presetRepo:   presetRepo,
+		feedbackRepo: feedbackRepo,
+		shareRepo:    shareRepo,
+		memberRepo:   memberRepo,
+		folderRepo:   folderRepo,
+		tagRepo:      tagRepo,
+		activityRepo: activityRepo,
+		commentRepo:  commentRepo,
+		starRepo:     starRepo,
+		quotaRepo:    quotaRepo,
+	}
+}
+
+// EraseDueAccounts 彻底删除冷静期已到的账号
+// 用户记录最后删除，任一步骤失败时保留用户记录，下次执行时重试
+// 按用户ID顺序遍历，失败的账号跳过，不影响其后的账号
+func (s *AccountErasureServiceImpl) EraseDueAccounts(ctx context.Context) (int, error) {
+	var count int
+	now := time.Now()
+	afterUserID := ""
+	for {
+		users, err := s.userRepo.ListDueDeletions(ctx, afterUserID, now, eraseBatchSize)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to list due account deletions: %v", err)
+			return count, ErrInternalError
+		}
+		if len(users) == 0 {
+			return count, nil
+		}
+
+		for _, user := range users {
+			if err := s.eraseUser(ctx, user); err != nil {
+				zlog.CtxErrorf(ctx, "failed to erase user %s: %v", user.UserID, err)
+				continue
+			}
+			count++
+		}
+		afterUserID = users[len(users)-1].UserID
+	}
+}
+
+// eraseUser 删除单个用户的所有数据
+func (s *AccountErasureServiceImpl) eraseUser(ctx context.Context, user *entity.User) error {
+	if err := s.eraseBackups(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.eraseFiles(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.exporter.DeleteUserExport(ctx, user.UserID); err != nil {
+		return fmt.Errorf("delete data export: %w", err)
+	}
+	if resourcePath, ok := avatarResourcePath(user); ok {
+		if err := s.cosService.DeleteFile(ctx, resourcePath); err != nil {
+			return fmt.Errorf("delete avatar %s: %w", resourcePath, err)
+		}
+	}
+	if err := s.aiChatRepo.EraseUserConversations(ctx, user.UserID); err != nil {
+		return err
+	}
+	// 其他用户对其导图的收藏按导图查找，需在删除导图之前删除
+	if err := s.starRepo.DeleteUserStars(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.mindMapRepo.EraseUserMindMaps(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.identityRepo.DeleteUserIdentities(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.DeleteUserSessions(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.historyRepo.DeleteUserPasswordHistory(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.auditRepo.DeleteUserAuditLogs(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.inviteRepo.DeleteUserInvites(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.aiUsageRepo.DeleteUserUsage(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.presetRepo.DeleteUserPresets(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.feedbackRepo.DeleteUserFeedback(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.shareRepo.DeleteUserShares(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.memberRepo.DeleteUserMembers(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.folderRepo.DeleteUserFolders(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.tagRepo.DeleteUserTags(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.activityRepo.DeleteUserActivities(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.commentRepo.DeleteUserComments(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.quotaRepo.DeleteUserQuota(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.userRepo.EraseUser(ctx, user.UserID); err != nil {
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "user erased after deletion grace period: %s", user.UserID)
+	return nil
+}
+
+// eraseBackups 删除用户的备份文件与备份记录
+func (s *AccountErasureServiceImpl) eraseBackups(ctx context.Context, userID string) error {
+	backups, err := s.backupRepo.ListBackups(ctx, userID)
+	if err != nil {
+		return err
+	}
+	backupIDs := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		if err := s.cosService.DeleteFile(ctx, backup.ResourcePath); err != nil {
+			return fmt.Errorf("delete backup object %s: %w", backup.ResourcePath, err)
+		}
+		backupIDs = append(backupIDs, backup.BackupID)
+	}
+	if len(backupIDs) == 0 {
+		return nil
+	}
+	return s.backupRepo.DeleteBackups(ctx, backupIDs)
+}
+
+// eraseFiles 删除用户上传的附件、导出文件及其记录
+func (s *AccountErasureServiceImpl) eraseFiles(ctx context.Context, userID string) error {
+	files, err := s.fileRepo.ListFiles(ctx, repo.FileQuery{UserID: userID})
+	if err != nil {
+		return err
+	}
+	fileIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		if err := s.cosService.DeleteFile(ctx, file.ResourcePath); err != nil {
+			return fmt.Errorf("delete file object %s: %w", file.ResourcePath, err)
+		}
+		fileIDs = append(fileIDs, file.FileID)
+	}
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	return s.fileRepo.DeleteFiles(ctx, fileIDs)
+}
+
+// avatarResourcePath 从头像URL中取出对象存储key
+// 只处理上传到本站存储桶的头像（user/{userID}/avatar/...），第三方平台头像等外部地址不处理
+func avatarResourcePath(user *entity.User) (string, bool) {
+	if user.Avatar == "" {
+		return "", false
+	}
+	u, err := url.Parse(user.Avatar)
+	if err != nil {
+		return "", false
+	}
+	resourcePath := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasPrefix(resourcePath, fmt.Sprintf("user/%s/avatar/", user.UserID)) {
+		return "", false
+	}
+	return resourcePath, true
+}
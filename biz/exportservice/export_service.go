@@ -0,0 +1,281 @@
+package exportservice
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// 错误定义
+var (
+	ErrExportDisabled   = errors.New("数据导出功能未开启")
+	ErrPermissionDenied = errors.New("权限不足")
+	ErrInternalError    = errors.New("内部错误")
+)
+
+const (
+	exportContentType     = "application/zip"
+	exportFileName        = "forge-export.zip"
+	defaultExportPrefix   = "export"
+	exportMindMapPageSize = 99 // 分页读取导图，与仓储层分页上限一致
+)
+
+// exportProfile 导出文件中的个人资料，不包含密码等内部字段
+type exportProfile struct {
+	UserID        string     `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	Nickname      string     `json:"nickname"`
+	Avatar        string     `json:"avatar"`
+	Bio           string     `json:"bio"`
+	Gender        string     `json:"gender"`
+	Birthday      *time.Time `json:"birthday"`
+	Location      string     `json:"location"`
+	Phone         string     `json:"phone"`
+	Email         string     `json:"email"`
+	Plan          string     `json:"plan"`
+	PlanExpiresAt *time.Time `json:"plan_expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// DataExportServiceImpl 数据导出服务实现
+type DataExportServiceImpl struct {
+	cosService  adapter.COSService
+	codeService adapter.CodeService
+	mindMapRepo repo.IMindMapRepo
+	aiChatRepo  repo.AiChatRepo
+	config      configs.DataExportConfig
+}
+
+func NewDataExportServiceImpl(cosService adapter.COSService, codeService adapter.CodeService, mindMapRepo repo.IMindMapRepo, aiChatRepo repo.AiChatRepo, cfg configs.DataExportConfig) *DataExportServiceImpl {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultExportPrefix
+	}
+	return &DataExportServiceImpl{
+		cosService:  cosService,
+		codeService: codeService,
+		mindMapRepo: mindMapRepo,
+		aiChatRepo:  aiChatRepo,
+		config:      cfg,
+	}
+}
+
+// RequestExport 申请导出当前用户的数据
+// 导出完成后发送邮件通知；未绑定邮箱的用户再次请求即可拿到下载链接
+func (s *DataExportServiceImpl) RequestExport(ctx context.Context) (*entity.DataExport, error) {
+	if !s.config.Enable {
+		return nil, ErrExportDisabled
+	}
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	key := fmt.Sprintf(constant.REDIS_DATA_EXPORT_KEY, user.UserID)
+	export, err := s.loadExport(ctx, key)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "load data export failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if export != nil {
+		return export, nil
+	}
+
+	// 进行中的标记在超时后自动过期，导出任务异常退出后可重新申请
+	export = &entity.DataExport{Status: entity.DataExportStatusPending, RequestedAt: time.Now()}
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+	started, err := cache.SetNXRedis(ctx, key, string(data), s.config.Timeout())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "mark data export pending failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if !started {
+		// 并发的请求已经开始导出
+		return export, nil
+	}
+
+	// 请求结束后 ctx 会被取消，这里只保留 ctx 中的日志信息
+	language := entity.GetLanguage(ctx)
+	go s.runExport(context.WithoutCancel(ctx), key, *user, export.RequestedAt, language)
+
+	zlog.CtxInfof(ctx, "data export started for user: %s", user.UserID)
+	return export, nil
+}
+
+// runExport 后台导出并上传，完成后记录下载链接并发送邮件；失败时清除进行中的标记，用户可重新申请
+func (s *DataExportServiceImpl) runExport(ctx context.Context, key string, user entity.User, requestedAt time.Time, language string) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout())
+	defer cancel()
+
+	export, err := s.export(ctx, &user, requestedAt)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "export data of user %s failed: %v", user.UserID, err)
+		if err := cache.DelRedis(context.WithoutCancel(ctx), key); err != nil {
+			zlog.CtxErrorf(ctx, "clear data export state failed: %v", err)
+		}
+		return
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "encode data export state failed: %v", err)
+		return
+	}
+	expiration := s.config.URLExpiration()
+	if err := cache.SetRedis(ctx, key, string(data), expiration); err != nil {
+		zlog.CtxErrorf(ctx, "save data export state failed: %v", err)
+	}
+
+	if user.Email != "" && s.codeService != nil {
+		if err := s.codeService.SendDataExportReady(ctx, user.Email, &adapter.DataExportEmail{
+			URL:       export.URL,
+			Language:  language,
+			ExpiresIn: expiration,
+		}); err != nil {
+			zlog.CtxErrorf(ctx, "send data export email to user %s failed: %v", user.UserID, err)
+		}
+	}
+	zlog.CtxInfof(ctx, "data export ready for user: %s", user.UserID)
+}
+
+// export 打包用户数据上传到 COS 并生成临时下载链接
+func (s *DataExportServiceImpl) export(ctx context.Context, user *entity.User, requestedAt time.Time) (*entity.DataExport, error) {
+	archive, err := s.buildArchive(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	resourcePath := s.resourcePath(user.UserID)
+	if _, err := s.cosService.UploadFile(ctx, resourcePath, archive, exportContentType); err != nil {
+		return nil, err
+	}
+
+	expiration := s.config.URLExpiration()
+	url, err := s.cosService.GetPresignedURL(ctx, resourcePath, expiration)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(expiration)
+	return &entity.DataExport{
+		Status:      entity.DataExportStatusReady,
+		URL:         url,
+		ExpiresAt:   &expiresAt,
+		RequestedAt: requestedAt,
+	}, nil
+}
+
+// buildArchive 生成 ZIP：profile.json、mindmaps.json、conversations.json
+func (s *DataExportServiceImpl) buildArchive(ctx context.Context, user *entity.User) ([]byte, error) {
+	mindMaps, conversations, err := s.listContent(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"profile.json", newExportProfile(user)},
+		{"mindmaps.json", mindMaps},
+		{"conversations.json", conversations},
+	}
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("create %s failed: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return nil, fmt.Errorf("encode %s failed: %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close zip failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// listContent 读取用户的全部导图及各导图下的会话
+func (s *DataExportServiceImpl) listContent(ctx context.Context, userID string) ([]*entity.MindMap, []*entity.Conversation, error) {
+	allMindMaps := make([]*entity.MindMap, 0)
+	allConversations := make([]*entity.Conversation, 0)
+	for page := 1; ; page++ {
+		mindMaps, total, err := s.mindMapRepo.ListMindMaps(ctx, repo.NewMindMapQueryForList(userID, page, exportMindMapPageSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("list mindmaps failed: %w", err)
+		}
+		for _, mindMap := range mindMaps {
+			conversations, err := s.aiChatRepo.GetMapAllConversation(ctx, mindMap.MapID, userID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("list conversations of map %s failed: %w", mindMap.MapID, err)
+			}
+			allConversations = append(allConversations, conversations...)
+		}
+		allMindMaps = append(allMindMaps, mindMaps...)
+		if len(mindMaps) == 0 || int64(page*exportMindMapPageSize) >= total {
+			return allMindMaps, allConversations, nil
+		}
+	}
+}
+
+// DeleteUserExport 删除用户的导出文件与导出进度，文件不存在时不报错
+func (s *DataExportServiceImpl) DeleteUserExport(ctx context.Context, userID string) error {
+	if err := s.cosService.DeleteFile(ctx, s.resourcePath(userID)); err != nil {
+		return err
+	}
+	return cache.DelRedis(ctx, fmt.Sprintf(constant.REDIS_DATA_EXPORT_KEY, userID))
+}
+
+// resourcePath 用户导出文件在 COS 中的路径，每个用户只保留最近一次导出的文件
+func (s *DataExportServiceImpl) resourcePath(userID string) string {
+	return path.Join(s.config.Prefix, userID, exportFileName)
+}
+
+// loadExport 读取导出进度，没有进行中或未过期的导出时返回 nil
+func (s *DataExportServiceImpl) loadExport(ctx context.Context, key string) (*entity.DataExport, error) {
+	value, err := cache.GetRedis(ctx, key)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var export entity.DataExport
+	if err := json.Unmarshal([]byte(value), &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func newExportProfile(user *entity.User) *exportProfile {
+	return &exportProfile{
+		UserID:        user.UserID,
+		UserName:      user.UserName,
+		Nickname:      user.Nickname,
+		Avatar:        user.Avatar,
+		Bio:           user.Bio,
+		Gender:        user.Gender,
+		Birthday:      user.Birthday,
+		Location:      user.Location,
+		Phone:         user.Phone,
+		Email:         user.Email,
+		Plan:          user.GetPlan(),
+		PlanExpiresAt: user.PlanExpiresAt,
+		CreatedAt:     user.CreatedAt,
+	}
+}
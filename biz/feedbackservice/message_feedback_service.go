@@ -0,0 +1,169 @@
+package feedbackservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 错误定义
+var (
+	ErrMessageNotFound     = errors.New("该消息不存在")
+	ErrMessageNotRateable  = errors.New("只能评价AI的回复")
+	ErrPermissionDenied    = errors.New("权限不足")
+	ErrInvalidExportPeriod = errors.New("导出日期格式为 YYYY-MM-DD，且开始日期不晚于结束日期")
+	ErrInternalError       = errors.New("内部错误")
+)
+
+// exportDateLayout 导出评价时日期参数的格式
+const exportDateLayout = "2006-01-02"
+
+// MessageFeedbackServiceImpl 消息评价服务实现
+type MessageFeedbackServiceImpl struct {
+	feedbackRepo repo.MessageFeedbackRepo
+	aiChatRepo   repo.AiChatRepo
+}
+
+func NewMessageFeedbackServiceImpl(feedbackRepo repo.MessageFeedbackRepo, aiChatRepo repo.AiChatRepo) *MessageFeedbackServiceImpl {
+	return &MessageFeedbackServiceImpl{
+		feedbackRepo: feedbackRepo,
+		aiChatRepo:   aiChatRepo,
+	}
+}
+
+// SubmitFeedback 评价AI回复，同时记录回复内容、对应的用户消息、模型与预设，导出后不必再回查会话
+func (s *MessageFeedbackServiceImpl) SubmitFeedback(ctx context.Context, params *types.SubmitFeedbackParams) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	conversation, err := s.aiChatRepo.GetConversation(ctx, params.ConversationID, user.UserID)
+	if err != nil {
+		return err
+	}
+	index := conversation.MessageIndex(params.MessageID)
+	if index < 0 {
+		return ErrMessageNotFound
+	}
+	message := conversation.Messages[index]
+	// 调用工具的中间消息没有给用户展示的内容，不能评价
+	if message.Role != entity.ASSISTANT || len(message.ToolCalls) > 0 {
+		return ErrMessageNotRateable
+	}
+
+	if params.Rating == "" {
+		if err := s.feedbackRepo.DeleteFeedback(ctx, params.ConversationID, params.MessageID, user.UserID); err != nil {
+			zlog.CtxErrorf(ctx, "delete message feedback failed: %v", err)
+			return ErrInternalError
+		}
+		return nil
+	}
+
+	now := time.Now()
+	feedback := &entity.MessageFeedback{
+		ConversationID: params.ConversationID,
+		MessageID:      params.MessageID,
+		UserID:         user.UserID,
+		Rating:         params.Rating,
+		Comment:        params.Comment,
+		Prompt:         lastUserMessage(conversation.Messages[:index]),
+		Reply:          message.Content,
+		Provider:       message.Provider,
+		Model:          message.Model,
+		PresetID:       conversation.PresetID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := feedback.Normalize(); err != nil {
+		return err
+	}
+	if err := s.feedbackRepo.SaveFeedback(ctx, feedback); err != nil {
+		zlog.CtxErrorf(ctx, "save message feedback failed: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "message %s in conversation %s rated %s", params.MessageID, params.ConversationID, params.Rating)
+	return nil
+}
+
+// ListConversationFeedback 当前用户在会话中的评价
+func (s *MessageFeedbackServiceImpl) ListConversationFeedback(ctx context.Context, conversationID string) (map[string]*entity.MessageFeedback, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	feedbacks, err := s.feedbackRepo.ListConversationFeedback(ctx, conversationID, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list conversation feedback failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	result := make(map[string]*entity.MessageFeedback, len(feedbacks))
+	for _, feedback := range feedbacks {
+		result[feedback.MessageID] = feedback
+	}
+	return result, nil
+}
+
+// ExportFeedback 导出评价（管理接口）
+func (s *MessageFeedbackServiceImpl) ExportFeedback(ctx context.Context, params *types.ExportFeedbackParams) ([]*entity.MessageFeedback, int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok || user.Role != entity.UserRoleAdmin {
+		return nil, 0, ErrPermissionDenied
+	}
+	if params.Rating != "" && params.Rating != entity.FeedbackRatingUp && params.Rating != entity.FeedbackRatingDown {
+		return nil, 0, entity.ErrInvalidFeedbackRating
+	}
+	since, until, err := parseExportPeriod(params.StartDate, params.EndDate)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := repo.NewMessageFeedbackQuery(params.Rating, params.PresetID, since, until, params.Page, params.PageSize)
+	feedbacks, total, err := s.feedbackRepo.ListFeedback(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "export message feedback failed: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return feedbacks, total, nil
+}
+
+// parseExportPeriod 把导出的日期范围转换为 [since, until) 的时间范围，结束日期包含当天
+func parseExportPeriod(startDate, endDate string) (since, until *time.Time, err error) {
+	if startDate != "" {
+		start, err := time.ParseInLocation(exportDateLayout, startDate, time.Local)
+		if err != nil {
+			return nil, nil, ErrInvalidExportPeriod
+		}
+		since = &start
+	}
+	if endDate != "" {
+		end, err := time.ParseInLocation(exportDateLayout, endDate, time.Local)
+		if err != nil {
+			return nil, nil, ErrInvalidExportPeriod
+		}
+		end = end.AddDate(0, 0, 1)
+		until = &end
+	}
+	if since != nil && until != nil && !since.Before(*until) {
+		return nil, nil, ErrInvalidExportPeriod
+	}
+	return since, until, nil
+}
+
+// lastUserMessage 最后一条用户消息的内容
+func lastUserMessage(messages []*entity.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == entity.USER {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
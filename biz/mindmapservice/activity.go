@@ -0,0 +1,91 @@
+package mindmapservice
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// ListMindMapActivities 导图的变更记录，按时间倒序，所有者与协作者都可以查看
+func (s *MindMapServiceImpl) ListMindMapActivities(ctx context.Context, mapID string, req *types.ListMindMapActivitiesParams) ([]*entity.MindMapActivity, int64, error) {
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+	activities, total, err := s.activityRepo.ListActivities(ctx, mindMap.MapID, req.Since, page, pageSize)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap activities: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return activities, total, nil
+}
+
+// normalizePage 页码从 1 开始，每页默认 20 条、最多 99 条，与导图列表一致
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return page, min(pageSize, 99)
+}
+
+// recordUpdateActivities 比较修改前后的标题与节点，记录本次修改的变更
+func (s *MindMapServiceImpl) recordUpdateActivities(ctx context.Context, existing *entity.MindMap, req *types.UpdateMindMapParams) {
+	var activities []*entity.MindMapActivity
+	if req.Title != nil && *req.Title != existing.Title {
+		activity := s.newActivity(ctx, existing, entity.MindMapActivityRenamed)
+		activity.Text = *req.Title
+		activity.OldText = existing.Title
+		activities = append(activities, activity)
+	}
+	if req.Data != nil {
+		for _, change := range entity.DiffMindMapData(existing.Data, *req.Data, entity.MaxActivitiesPerUpdate) {
+			activity := s.newActivity(ctx, existing, entity.ActivityTypeOfChange(change.Op))
+			activity.Path = change.Path
+			activity.Text = change.Text
+			activity.OldText = change.OldText
+			activities = append(activities, activity)
+		}
+	}
+	s.recordActivities(ctx, activities...)
+}
+
+// newActivity 当前用户对导图的一条变更记录
+func (s *MindMapServiceImpl) newActivity(ctx context.Context, mindMap *entity.MindMap, activityType string) *entity.MindMapActivity {
+	activity := &entity.MindMapActivity{
+		MapID:     mindMap.MapID,
+		OwnerID:   mindMap.UserID,
+		Type:      activityType,
+		CreatedAt: time.Now(),
+	}
+	if user, ok := entity.GetUser(ctx); ok {
+		activity.ActorID = user.UserID
+	}
+	return activity
+}
+
+// recordActivities 保存变更记录，变更已经生效，保存失败只记录日志
+func (s *MindMapServiceImpl) recordActivities(ctx context.Context, activities ...*entity.MindMapActivity) {
+	if len(activities) == 0 {
+		return
+	}
+	for _, activity := range activities {
+		activityID, err := util.GenerateStringID()
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to generate activity id: %v", err)
+			return
+		}
+		activity.ActivityID = activityID
+	}
+	if err := s.activityRepo.CreateActivities(ctx, activities); err != nil {
+		zlog.CtxErrorf(ctx, "failed to record mindmap activities, mapID: %s: %v", activities[0].MapID, err)
+	}
+}
@@ -0,0 +1,190 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// CreateMindMapComment 在导图节点上评论，所有者与协作者（包括查看者）都可以评论
+func (s *MindMapServiceImpl) CreateMindMapComment(ctx context.Context, mapID string, req *types.CreateMindMapCommentParams) (*entity.MindMapComment, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := entity.NormalizeCommentContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	if req.NodeID == "" {
+		return nil, ErrInvalidParams
+	}
+	if _, ok := mindMap.Data.FindNode(req.NodeID); !ok {
+		return nil, ErrCommentNodeNotFound
+	}
+
+	count, err := s.commentRepo.CountComments(ctx, mindMap.MapID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to count mindmap comments: %v", err)
+		return nil, ErrInternalError
+	}
+	if count >= entity.MaxCommentsPerMap {
+		return nil, ErrCommentLimitExceeded
+	}
+
+	commentID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate comment id: %v", err)
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	comment := &entity.MindMapComment{
+		CommentID: commentID,
+		MapID:     mindMap.MapID,
+		OwnerID:   mindMap.UserID,
+		NodeID:    req.NodeID,
+		UserID:    user.UserID,
+		Content:   content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.commentRepo.CreateComment(ctx, comment); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comment created, mapID: %s, nodeID: %s, commentID: %s", mindMap.MapID, req.NodeID, commentID)
+	return comment, nil
+}
+
+// ListMindMapComments 导图或某个节点的评论，按创建时间顺序
+func (s *MindMapServiceImpl) ListMindMapComments(ctx context.Context, mapID string, req *types.ListMindMapCommentsParams) ([]*entity.MindMapComment, int64, error) {
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := repo.MindMapCommentQuery{
+		MapID:  mindMap.MapID,
+		NodeID: req.NodeID,
+	}
+	switch req.Status {
+	case "":
+	case types.CommentStatusOpen, types.CommentStatusResolved:
+		resolved := req.Status == types.CommentStatusResolved
+		query.Resolved = &resolved
+	default:
+		return nil, 0, ErrInvalidParams
+	}
+	query.Page, query.PageSize = normalizePage(req.Page, req.PageSize)
+
+	comments, total, err := s.commentRepo.ListComments(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap comments: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return comments, total, nil
+}
+
+// ResolveMindMapComment 标记评论为已解决或重新打开，所有者、编辑者与评论者可以操作
+func (s *MindMapServiceImpl) ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) (*entity.MindMapComment, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	mindMap, role, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.getComment(ctx, mindMap.MapID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.CanEditMindMap(role) && comment.UserID != user.UserID {
+		zlog.CtxWarnf(ctx, "viewer cannot resolve others' comment, mapID: %s, commentID: %s", mapID, commentID)
+		return nil, ErrPermissionDenied
+	}
+	if comment.Resolved == resolved {
+		return comment, nil
+	}
+
+	now := time.Now()
+	comment.Resolved = resolved
+	comment.ResolvedBy = ""
+	comment.ResolvedAt = nil
+	if resolved {
+		comment.ResolvedBy = user.UserID
+		comment.ResolvedAt = &now
+	}
+	comment.UpdatedAt = now
+	if err := s.commentRepo.UpdateCommentResolved(ctx, comment); err != nil {
+		if errors.Is(err, repo.ErrMindMapCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comment resolved: %v, mapID: %s, commentID: %s", resolved, mapID, commentID)
+	return comment, nil
+}
+
+// DeleteMindMapComment 删除评论，所有者与评论者可以删除
+func (s *MindMapServiceImpl) DeleteMindMapComment(ctx context.Context, mapID, commentID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	mindMap, role, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	comment, err := s.getComment(ctx, mindMap.MapID, commentID)
+	if err != nil {
+		return err
+	}
+	if role != entity.MindMapRoleOwner && comment.UserID != user.UserID {
+		zlog.CtxWarnf(ctx, "cannot delete others' comment, mapID: %s, commentID: %s", mapID, commentID)
+		return ErrPermissionDenied
+	}
+
+	if err := s.commentRepo.DeleteComment(ctx, mindMap.MapID, commentID); err != nil {
+		if errors.Is(err, repo.ErrMindMapCommentNotFound) {
+			return ErrCommentNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap comment: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comment deleted, mapID: %s, commentID: %s", mapID, commentID)
+	return nil
+}
+
+// getComment 获取导图上的评论
+func (s *MindMapServiceImpl) getComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error) {
+	if commentID == "" {
+		return nil, ErrCommentNotFound
+	}
+	comment, err := s.commentRepo.GetComment(ctx, mapID, commentID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+	if comment == nil {
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
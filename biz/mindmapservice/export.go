@@ -0,0 +1,99 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/pkg/mindmapconv"
+)
+
+const (
+	defaultExportPrefix   = "mindmap_export"
+	defaultExportFileName = "mindmap"
+	maxExportFileNameLen  = 64 // 下载文件名（不含扩展名）的最大字符数
+)
+
+// ExportMindMap 将导图转换为指定格式，直接返回文件内容或上传到COS后返回临时下载链接
+func (s *MindMapServiceImpl) ExportMindMap(ctx context.Context, mapID string, req *types.ExportMindMapParams) (*types.MindMapExport, error) {
+	// 所有者与协作者都可以导出
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.exporter.Supported(req.Format) {
+		if req.Format == mindmapconv.FormatPNG {
+			return nil, mindmapconv.ErrPNGUnavailable
+		}
+		return nil, mindmapconv.ErrUnsupportedFormat
+	}
+
+	file, err := s.exporter.Export(req.Format, toExportNode(mindMap.Data))
+	if err != nil {
+		if errors.Is(err, mindmapconv.ErrImageTooLarge) {
+			return nil, err
+		}
+		zlog.CtxErrorf(ctx, "failed to export mindmap, mapID: %s, format: %s, err: %v", mindMap.MapID, req.Format, err)
+		return nil, ErrInternalError
+	}
+	export := &types.MindMapExport{
+		FileName:    exportFileName(mindMap.Title) + file.Ext,
+		ContentType: file.ContentType,
+	}
+	if !req.AsURL {
+		export.Data = file.Data
+		zlog.CtxInfof(ctx, "mindmap exported, mapID: %s, format: %s, size: %d", mindMap.MapID, req.Format, len(file.Data))
+		return export, nil
+	}
+
+	// 同一用户同一导图同一格式只保留最近一次导出的文件
+	user, _ := entity.GetUser(ctx)
+	resourcePath := path.Join(s.exportConfig.Prefix, user.UserID, mindMap.MapID+file.Ext)
+	if _, err := s.cosService.UploadFile(ctx, resourcePath, file.Data, file.ContentType); err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload mindmap export: %v", err)
+		return nil, ErrInternalError
+	}
+	expiration := s.exportConfig.URLExpiration()
+	url, err := s.cosService.GetPresignedURL(ctx, resourcePath, expiration)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to presign mindmap export: %v", err)
+		return nil, ErrInternalError
+	}
+	expiresAt := time.Now().Add(expiration)
+	export.URL = url
+	export.ExpiresAt = &expiresAt
+
+	zlog.CtxInfof(ctx, "mindmap exported to cos, mapID: %s, format: %s, path: %s", mindMap.MapID, req.Format, resourcePath)
+	return export, nil
+}
+
+func toExportNode(d entity.MindMapData) mindmapconv.Node {
+	node := mindmapconv.Node{Text: d.Data.Text}
+	for _, child := range d.Children {
+		node.Children = append(node.Children, toExportNode(child))
+	}
+	return node
+}
+
+// exportFileName 以导图标题作为下载文件名，去掉路径分隔符等文件名中不能出现的字符
+func exportFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if utf8.RuneCountInString(name) > maxExportFileNameLen {
+		name = string([]rune(name)[:maxExportFileNameLen])
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		return defaultExportFileName
+	}
+	return name
+}
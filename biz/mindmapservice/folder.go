@@ -0,0 +1,260 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// CreateMindMapFolder 创建文件夹，可以指定上级文件夹
+func (s *MindMapServiceImpl) CreateMindMapFolder(ctx context.Context, req *types.CreateMindMapFolderParams) (*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	name, err := entity.NormalizeFolderName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.loadFolderTree(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) >= entity.MaxFoldersPerUser {
+		return nil, ErrFolderLimitExceeded
+	}
+	if req.ParentID != "" {
+		if _, ok := folders[req.ParentID]; !ok {
+			return nil, ErrFolderNotFound
+		}
+		if folders.depth(req.ParentID)+1 > entity.MaxFolderDepth {
+			return nil, ErrFolderTooDeep
+		}
+	}
+
+	folderID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate folder id: %v", err)
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	folder := &entity.MindMapFolder{
+		FolderID:  folderID,
+		UserID:    user.UserID,
+		ParentID:  req.ParentID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.folderRepo.CreateFolder(ctx, folder); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap folder: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder created, folderID: %s, parentID: %s", folderID, req.ParentID)
+	return folder, nil
+}
+
+// ListMindMapFolders 自己的所有文件夹，由前端按 ParentID 组装层级
+func (s *MindMapServiceImpl) ListMindMapFolders(ctx context.Context) ([]*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	folders, err := s.folderRepo.ListFolders(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap folders: %v", err)
+		return nil, ErrInternalError
+	}
+	return folders, nil
+}
+
+// UpdateMindMapFolder 重命名文件夹或移动到其他文件夹下
+func (s *MindMapServiceImpl) UpdateMindMapFolder(ctx context.Context, folderID string, req *types.UpdateMindMapFolderParams) (*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	folders, err := s.loadFolderTree(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	folder, ok := folders[folderID]
+	if !ok {
+		return nil, ErrFolderNotFound
+	}
+
+	if req.Name != nil {
+		if folder.Name, err = entity.NormalizeFolderName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.ParentID != nil && *req.ParentID != folder.ParentID {
+		parentID := *req.ParentID
+		if parentID != "" {
+			if _, ok := folders[parentID]; !ok {
+				return nil, ErrFolderNotFound
+			}
+			// 新的上级不能是自身或自己的子文件夹
+			if folders.isAncestor(folderID, parentID) {
+				return nil, ErrFolderCycle
+			}
+			if folders.depth(parentID)+folders.height(folderID) > entity.MaxFolderDepth {
+				return nil, ErrFolderTooDeep
+			}
+		}
+		folder.ParentID = parentID
+	}
+
+	folder.UpdatedAt = time.Now()
+	if err := s.folderRepo.UpdateFolder(ctx, folder); err != nil {
+		if errors.Is(err, repo.ErrMindMapFolderNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap folder: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder updated, folderID: %s, parentID: %s", folderID, folder.ParentID)
+	return folder, nil
+}
+
+// DeleteMindMapFolder 删除文件夹，其中的子文件夹与导图移到上级文件夹，不会删除导图
+func (s *MindMapServiceImpl) DeleteMindMapFolder(ctx context.Context, folderID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	if folderID == "" {
+		return ErrFolderNotFound
+	}
+
+	if err := s.folderRepo.DeleteFolder(ctx, user.UserID, folderID); err != nil {
+		if errors.Is(err, repo.ErrMindMapFolderNotFound) {
+			return ErrFolderNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap folder: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder deleted, folderID: %s", folderID)
+	return nil
+}
+
+// MoveMindMap 将自己的导图移到文件夹中，folderID 为空时移出文件夹
+func (s *MindMapServiceImpl) MoveMindMap(ctx context.Context, mapID, folderID string) error {
+	// 文件夹属于所有者，协作者不能移动
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if folderID != "" {
+		if _, err := s.getFolder(ctx, mindMap.UserID, folderID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.mindMapRepo.UpdateMindMap(ctx, &repo.MindMapUpdateInfo{
+		MapID:    mindMap.MapID,
+		UserID:   mindMap.UserID,
+		FolderID: &folderID,
+	}); err != nil {
+		if errors.Is(err, repo.ErrMindMapNotFound) {
+			return ErrMindMapNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to move mindmap: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap moved, mapID: %s, folderID: %s", mapID, folderID)
+	return nil
+}
+
+// getFolder 获取用户自己的文件夹
+func (s *MindMapServiceImpl) getFolder(ctx context.Context, userID, folderID string) (*entity.MindMapFolder, error) {
+	folder, err := s.folderRepo.GetFolder(ctx, userID, folderID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap folder: %v", err)
+		return nil, ErrInternalError
+	}
+	if folder == nil {
+		return nil, ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+// folderTree 按ID索引的用户全部文件夹，用于校验层级；每个用户的文件夹数有上限，一次读出即可
+type folderTree map[string]*entity.MindMapFolder
+
+func (s *MindMapServiceImpl) loadFolderTree(ctx context.Context, userID string) (folderTree, error) {
+	folders, err := s.folderRepo.ListFolders(ctx, userID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap folders: %v", err)
+		return nil, ErrInternalError
+	}
+	tree := make(folderTree, len(folders))
+	for _, folder := range folders {
+		tree[folder.FolderID] = folder
+	}
+	return tree, nil
+}
+
+// depth 文件夹所在层数，顶层为 1
+func (t folderTree) depth(folderID string) int {
+	depth := 0
+	// 层数上限保证即使数据中存在环也能结束
+	for id := folderID; id != "" && depth <= entity.MaxFolderDepth; depth++ {
+		folder, ok := t[id]
+		if !ok {
+			break
+		}
+		id = folder.ParentID
+	}
+	return depth
+}
+
+// height 以该文件夹为根的子树层数，没有子文件夹时为 1
+func (t folderTree) height(folderID string) int {
+	return t.subtreeHeight(folderID, 1)
+}
+
+// subtreeHeight 超过层数上限后不再向下计算
+func (t folderTree) subtreeHeight(folderID string, level int) int {
+	height := level
+	if level > entity.MaxFolderDepth {
+		return height
+	}
+	for _, folder := range t {
+		if folder.ParentID == folderID {
+			height = max(height, t.subtreeHeight(folder.FolderID, level+1))
+		}
+	}
+	return height
+}
+
+// isAncestor ancestorID 是否为 folderID 自身或其上级
+func (t folderTree) isAncestor(ancestorID, folderID string) bool {
+	for depth := 0; folderID != "" && depth <= entity.MaxFolderDepth; depth++ {
+		if folderID == ancestorID {
+			return true
+		}
+		folder, ok := t[folderID]
+		if !ok {
+			return false
+		}
+		folderID = folder.ParentID
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package mindmapservice
+
+import (
+	"context"
+	"io"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/pkg/mindmapconv"
+)
+
+// MaxImportFileSize 导入文件大小上限（字节），.xmind 中可能带有图片，比纯文本大纲宽松
+const MaxImportFileSize = 5 << 20
+
+// ImportMindMap 解析上传的 xmind/mm/opml/md 文件，创建为当前用户的新导图
+func (s *MindMapServiceImpl) ImportMindMap(ctx context.Context, req *types.ImportMindMapParams) (*entity.MindMap, error) {
+	if req == nil || req.File == nil {
+		return nil, ErrInvalidParams
+	}
+	format := mindmapconv.FormatOf(req.File.Filename)
+	if format == "" {
+		return nil, mindmapconv.ErrUnsupportedImport
+	}
+	if req.File.Size > MaxImportFileSize {
+		zlog.CtxWarnf(ctx, "import file %s too large: %d bytes", req.File.Filename, req.File.Size)
+		return nil, ErrImportFileTooLarge
+	}
+
+	f, err := req.File.Open()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open import file: %v", err)
+		return nil, ErrInternalError
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, MaxImportFileSize))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to read import file: %v", err)
+		return nil, ErrInternalError
+	}
+
+	var data entity.MindMapData
+	if format == mindmapconv.FormatMarkdown {
+		// Markdown 大纲与纯文本大纲使用同一套解析规则
+		if len(content) > MaxOutlineLength {
+			return nil, ErrOutlineTooLarge
+		}
+		if data, _, err = parseOutline(string(content), req.Title); err != nil {
+			return nil, err
+		}
+	} else {
+		root, err := mindmapconv.Import(format, content, MaxOutlineNodes)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "failed to parse import file %s: %v", req.File.Filename, err)
+			return nil, err
+		}
+		data = fromImportNode(root)
+	}
+
+	zlog.CtxInfof(ctx, "mindmap file parsed successfully, format: %s, size: %d", format, len(content))
+	return s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:  defaultTitle(req.Title, &data),
+		Desc:   req.Desc,
+		Layout: req.Layout,
+		Data:   data,
+	})
+}
+
+func fromImportNode(n mindmapconv.Node) entity.MindMapData {
+	data := entity.MindMapData{
+		Data:     entity.NodeData{Text: n.Text},
+		Children: make([]entity.MindMapData, 0, len(n.Children)),
+	}
+	for _, child := range n.Children {
+		data.Children = append(data.Children, fromImportNode(child))
+	}
+	return data
+}
@@ -0,0 +1,70 @@
+package mindmapservice
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+)
+
+// listCursor 列表游标的内容，记录排序方式，换了排序方式的游标视为无效
+type listCursor struct {
+	Sort    string `json:"s"`
+	Value   string `json:"v"`
+	Starred bool   `json:"st,omitempty"` // 按收藏排序时最后一个导图是否已收藏
+	MapID   string `json:"id"`
+}
+
+// encodeListCursor 以当前页最后一个导图生成下一页的游标
+func encodeListCursor(query repo.MindMapQuery, last *entity.MindMap) string {
+	cursor := listCursor{Sort: listCursorSort(query), MapID: last.MapID}
+	if query.SortBy == repo.MindMapSortStarred {
+		cursor.Starred = last.Starred
+	}
+	switch query.SortBy {
+	case repo.MindMapSortCreatedAt:
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	case repo.MindMapSortTitle:
+		cursor.Value = last.Title
+	default:
+		cursor.Value = last.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor 解析游标，格式错误或与当前排序方式不一致时返回 ErrInvalidListCursor
+func decodeListCursor(token string, query repo.MindMapQuery) (*repo.MindMapCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidListCursor
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.MapID == "" || cursor.Sort != listCursorSort(query) {
+		return nil, ErrInvalidListCursor
+	}
+
+	after := &repo.MindMapCursor{MapID: cursor.MapID, Starred: cursor.Starred}
+	switch query.SortBy {
+	case repo.MindMapSortTitle:
+		after.Title = cursor.Value
+		return after, nil
+	case repo.MindMapSortCreatedAt:
+		after.CreatedAt, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	default:
+		after.UpdatedAt, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+	if err != nil {
+		return nil, ErrInvalidListCursor
+	}
+	return after, nil
+}
+
+func listCursorSort(query repo.MindMapQuery) string {
+	if query.Ascending {
+		return query.SortBy + ":asc"
+	}
+	return query.SortBy + ":desc"
+}
@@ -0,0 +1,140 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// AddMindMapMember 所有者按手机号或邮箱添加协作者，已是协作者时修改其角色
+func (s *MindMapServiceImpl) AddMindMapMember(ctx context.Context, mapID string, req *types.AddMindMapMemberParams) (*entity.MindMapMember, error) {
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if err := entity.ValidateMemberRole(req.Role); err != nil {
+		return nil, err
+	}
+
+	account := strings.TrimSpace(req.Account)
+	if account == "" {
+		return nil, ErrInvalidParams
+	}
+	// 手机号按存储格式（大陆号码不带区号，其他地区为 E.164）查找
+	var query repo.UserQuery
+	switch req.AccountType {
+	case types.AccountTypePhone:
+		phone, err := util.NormalizePhone(account)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "invalid member phone number: %s", account)
+			return nil, ErrInvalidParams
+		}
+		query = repo.NewUserQueryByPhone(phone)
+	case types.AccountTypeEmail:
+		query = repo.NewUserQueryByEmail(strings.ToLower(account))
+	default:
+		return nil, ErrInvalidParams
+	}
+	user, err := s.userRepo.GetUser(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get user by %s: %v", req.AccountType, err)
+		return nil, ErrInternalError
+	}
+	if user == nil {
+		return nil, ErrMemberUserNotFound
+	}
+	if user.UserID == mindMap.UserID {
+		return nil, ErrCannotAddSelf
+	}
+
+	existing, err := s.memberRepo.GetMember(ctx, mindMap.MapID, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap member: %v", err)
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	member := &entity.MindMapMember{
+		MapID:     mindMap.MapID,
+		OwnerID:   mindMap.UserID,
+		UserID:    user.UserID,
+		Role:      req.Role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if existing != nil {
+		member.CreatedAt = existing.CreatedAt
+	} else {
+		count, err := s.memberRepo.CountMembers(ctx, mindMap.MapID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to count mindmap members: %v", err)
+			return nil, ErrInternalError
+		}
+		if count >= entity.MaxMindMapMembers {
+			return nil, ErrMemberLimitExceeded
+		}
+	}
+	if err := s.memberRepo.SaveMember(ctx, member); err != nil {
+		zlog.CtxErrorf(ctx, "failed to save mindmap member: %v", err)
+		return nil, ErrInternalError
+	}
+	activity := s.newActivity(ctx, mindMap, entity.MindMapActivityMemberAdded)
+	activity.TargetID = user.UserID
+	activity.Text = req.Role
+	s.recordActivities(ctx, activity)
+
+	zlog.CtxInfof(ctx, "mindmap member saved, mapID: %s, memberID: %s, role: %s", mindMap.MapID, user.UserID, req.Role)
+	return member, nil
+}
+
+// ListMindMapMembers 导图的协作者，所有者与协作者都可以查看
+func (s *MindMapServiceImpl) ListMindMapMembers(ctx context.Context, mapID string) (*entity.MindMap, []*entity.MindMapMember, error) {
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	members, err := s.memberRepo.ListMembers(ctx, mindMap.MapID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap members: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	return mindMap, members, nil
+}
+
+// RemoveMindMapMember 所有者移除协作者，协作者也可以移除自己（退出协作）
+func (s *MindMapServiceImpl) RemoveMindMapMember(ctx context.Context, mapID, userID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	if userID == "" {
+		return ErrInvalidParams
+	}
+
+	if userID == user.UserID {
+		if _, _, err := s.getAccessibleMindMap(ctx, mapID); err != nil {
+			return err
+		}
+	} else if _, err := s.getOwnedMindMap(ctx, mapID); err != nil {
+		return err
+	}
+
+	if err := s.memberRepo.RemoveMember(ctx, mapID, userID); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to remove mindmap member: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap member removed, mapID: %s, memberID: %s, by: %s", mapID, userID, user.UserID)
+	return nil
+}
@@ -0,0 +1,65 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+)
+
+// stubUserRepo 只实现 GetUser，记录查询条件，查不到用户
+type stubUserRepo struct {
+	repo.UserRepo
+	queries []repo.UserQuery
+}
+
+func (r *stubUserRepo) GetUser(ctx context.Context, query repo.UserQuery) (*entity.User, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func TestAddMindMapMemberNormalizesAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		account     string
+		accountType string
+		want        repo.UserQuery
+		wantErr     error
+	}{
+		{name: "大陆号码带区号", account: "+86 138 0013 8000", accountType: types.AccountTypePhone, want: repo.UserQuery{Phone: "13800138000"}, wantErr: ErrMemberUserNotFound},
+		{name: "其他地区号码", account: "+44 7911 123456", accountType: types.AccountTypePhone, want: repo.UserQuery{Phone: "+447911123456"}, wantErr: ErrMemberUserNotFound},
+		{name: "邮箱大小写与空白", account: " Alice@Example.COM ", accountType: types.AccountTypeEmail, want: repo.UserQuery{Email: "alice@example.com"}, wantErr: ErrMemberUserNotFound},
+		{name: "无效号码", account: "12345", accountType: types.AccountTypePhone, wantErr: ErrInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &stubUserRepo{}
+			s := &MindMapServiceImpl{
+				userRepo:    userRepo,
+				mindMapRepo: &stubMindMapRepo{mindMaps: []*entity.MindMap{{MapID: "m1", UserID: "owner"}}},
+			}
+			ctx := entity.WithUser(context.Background(), &entity.User{UserID: "owner"})
+
+			_, err := s.AddMindMapMember(ctx, "m1", &types.AddMindMapMemberParams{
+				Account:     tt.account,
+				AccountType: tt.accountType,
+				Role:        entity.MindMapRoleViewer,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddMindMapMember() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == ErrInvalidParams {
+				if len(userRepo.queries) != 0 {
+					t.Errorf("GetUser() called with %+v for invalid account", userRepo.queries)
+				}
+				return
+			}
+			if len(userRepo.queries) != 1 || userRepo.queries[0] != tt.want {
+				t.Errorf("GetUser() queries = %+v, want %+v", userRepo.queries, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package mindmapservice
 import (
 	"context"
 	"errors"
+	"forge/biz/adapter"
 	"forge/biz/entity"
 	"forge/biz/repo"
 	"forge/biz/types"
+	"forge/infra/configs"
 	"forge/pkg/log/zlog"
+	"forge/pkg/mindmapconv"
 	"forge/util"
+	"strings"
 )
 
 // 错误定义
@@ -17,16 +21,75 @@ var (
 	ErrInvalidParams        = errors.New("参数无效")
 	ErrPermissionDenied     = errors.New("权限不足")
 	ErrInternalError        = errors.New("内部错误")
+	ErrEmptyOutline         = errors.New("大纲内容为空")
+	ErrOutlineTooLarge      = errors.New("大纲内容过大")
+	ErrShareNotFound        = errors.New("分享链接无效或已失效")
+	ErrShareExpired         = errors.New("分享链接已过期")
+	ErrShareLimitExceeded   = errors.New("该导图的分享链接数量已达上限")
+	ErrInvalidShareExpire   = errors.New("分享有效期超出范围")
+	ErrSharePasswordNeeded  = errors.New("需要输入访问密码")
+	ErrSharePasswordWrong   = errors.New("访问密码错误")
+	ErrShareTooManyAttempts = errors.New("访问密码错误次数过多")
+	ErrMemberNotFound       = errors.New("协作者不存在")
+	ErrMemberUserNotFound   = errors.New("该账号未注册")
+	ErrMemberLimitExceeded  = errors.New("该导图的协作者数量已达上限")
+	ErrCannotAddSelf        = errors.New("不能将自己添加为协作者")
+	ErrImportFileTooLarge   = errors.New("导入文件过大")
+	ErrFolderNotFound       = errors.New("文件夹不存在")
+	ErrFolderLimitExceeded  = errors.New("文件夹数量已达上限")
+	ErrFolderTooDeep        = errors.New("文件夹嵌套层数超出上限")
+	ErrFolderCycle          = errors.New("不能将文件夹移动到自身或其子文件夹中")
+	ErrTagNotFound          = errors.New("标签不存在")
+	ErrTagLimitExceeded     = errors.New("标签数量已达上限")
+	ErrTemplateNotFound     = errors.New("模板不存在")
+	ErrInvalidListCursor    = errors.New("分页游标无效")
+	ErrCommentNotFound      = errors.New("评论不存在")
+	ErrCommentNodeNotFound  = errors.New("评论的节点不存在")
+	ErrCommentLimitExceeded = errors.New("该导图的评论数量已达上限")
 )
 
 // MindMapServiceImpl 思维导图服务实现
 type MindMapServiceImpl struct {
-	mindMapRepo repo.IMindMapRepo
+	mindMapRepo  repo.IMindMapRepo
+	quotaService types.IQuotaService
+	shareRepo    repo.IMindMapShareRepo
+	memberRepo   repo.IMindMapMemberRepo
+	folderRepo   repo.IMindMapFolderRepo
+	tagRepo      repo.IMindMapTagRepo
+	templateRepo repo.IMindMapTemplateRepo
+	activityRepo repo.IMindMapActivityRepo
+	commentRepo  repo.IMindMapCommentRepo
+	starRepo     repo.IMindMapStarRepo
+	userRepo     repo.UserRepo
+	shareConfig  configs.MindMapShareConfig
+	cosService   adapter.COSService
+	exporter     *mindmapconv.Exporter
+	exportConfig configs.MindMapExportConfig
 }
 
-func NewMindMapServiceImpl(mindMapRepo repo.IMindMapRepo) *MindMapServiceImpl {
+func NewMindMapServiceImpl(mindMapRepo repo.IMindMapRepo, quotaService types.IQuotaService, shareRepo repo.IMindMapShareRepo,
+	memberRepo repo.IMindMapMemberRepo, folderRepo repo.IMindMapFolderRepo, tagRepo repo.IMindMapTagRepo, templateRepo repo.IMindMapTemplateRepo,
+	activityRepo repo.IMindMapActivityRepo, commentRepo repo.IMindMapCommentRepo, starRepo repo.IMindMapStarRepo, userRepo repo.UserRepo,
+	shareConfig configs.MindMapShareConfig, cosService adapter.COSService, exporter *mindmapconv.Exporter, exportConfig configs.MindMapExportConfig) *MindMapServiceImpl {
+	if exportConfig.Prefix == "" {
+		exportConfig.Prefix = defaultExportPrefix
+	}
 	return &MindMapServiceImpl{
-		mindMapRepo: mindMapRepo,
+		mindMapRepo:  mindMapRepo,
+		quotaService: quotaService,
+		shareRepo:    shareRepo,
+		memberRepo:   memberRepo,
+		folderRepo:   folderRepo,
+		tagRepo:      tagRepo,
+		templateRepo: templateRepo,
+		activityRepo: activityRepo,
+		commentRepo:  commentRepo,
+		starRepo:     starRepo,
+		userRepo:     userRepo,
+		shareConfig:  shareConfig,
+		cosService:   cosService,
+		exporter:     exporter,
+		exportConfig: exportConfig,
 	}
 }
 
@@ -39,6 +102,18 @@ func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.Creat
 		return nil, ErrPermissionDenied
 	}
 
+	// 套餐配额校验
+	if err := s.quotaService.CheckMindMapQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	// 指定了文件夹时确认文件夹属于自己
+	if req.FolderID != "" {
+		if _, err := s.getFolder(ctx, user.UserID, req.FolderID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 生成思维导图ID
 	mapID, err := util.GenerateStringID()
 	if err != nil {
@@ -48,12 +123,13 @@ func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.Creat
 
 	// 构建实体
 	mindMap := &entity.MindMap{
-		MapID:  mapID,
-		UserID: user.UserID, // 从JWT token中获取的用户ID
-		Title:  req.Title,
-		Desc:   req.Desc,
-		Layout: req.Layout,
-		Data:   req.Data,
+		MapID:    mapID,
+		UserID:   user.UserID, // 从JWT token中获取的用户ID
+		Title:    req.Title,
+		Desc:     req.Desc,
+		Layout:   req.Layout,
+		Data:     req.Data,
+		FolderID: req.FolderID,
 	}
 
 	// 实体校验
@@ -61,6 +137,14 @@ func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.Creat
 		zlog.CtxErrorf(ctx, "mindmap validation failed: %v", err)
 		return nil, err
 	}
+	if err := s.quotaService.CheckMindMapNodeQuota(ctx, user.UserID, mindMap.Data.CountNodes()); err != nil {
+		return nil, err
+	}
+	// 为节点生成ID，评论按节点ID关联
+	if err := mindMap.Data.EnsureNodeIDs(); err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate node ids: %v", err)
+		return nil, ErrInternalError
+	}
 
 	// 持久化
 	if err := s.mindMapRepo.CreateMindMap(ctx, mindMap); err != nil {
@@ -72,92 +156,264 @@ func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.Creat
 	return mindMap, nil
 }
 
-// GetMindMap 获取思维导图（用户只能获取自己的思维导图）
+// CreateMindMapFromText 根据纯文本大纲创建思维导图（不调用AI，按缩进/项目符号确定层级）
+func (s *MindMapServiceImpl) CreateMindMapFromText(ctx context.Context, req *types.CreateMindMapFromTextParams) (*entity.MindMap, error) {
+	// 参数校验
+	if req == nil || strings.TrimSpace(req.Text) == "" {
+		zlog.CtxErrorf(ctx, "outline text is required")
+		return nil, ErrEmptyOutline
+	}
+	if len(req.Text) > MaxOutlineLength {
+		zlog.CtxErrorf(ctx, "outline text too large: %d bytes, max: %d", len(req.Text), MaxOutlineLength)
+		return nil, ErrOutlineTooLarge
+	}
+
+	// 解析大纲，多个顶层节点时以标题作为根节点
+	data, count, err := parseOutline(req.Text, req.Title)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to parse outline: %v", err)
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "outline parsed successfully, nodes: %d", count)
+	return s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:  defaultTitle(req.Title, &data),
+		Desc:   req.Desc,
+		Layout: req.Layout,
+		Data:   data,
+	})
+}
+
+// defaultTitle 未填写标题时使用根节点文本；根节点为空且无标题时使用第一个子节点文本，并以标题补全根节点
+func defaultTitle(title string, data *entity.MindMapData) string {
+	if title == "" {
+		title = data.Data.Text
+		if title == "" && len(data.Children) > 0 {
+			title = data.Children[0].Data.Text
+		}
+		title = truncateTitle(title)
+	}
+	if data.Data.Text == "" {
+		data.Data.Text = title
+	}
+	return title
+}
+
+// truncateTitle 截断标题到实体允许的最大长度，避免截断半个字符
+func truncateTitle(title string) string {
+	const maxTitleLength = 100
+	if len(title) <= maxTitleLength {
+		return title
+	}
+	cut := 0
+	for i := range title {
+		if i > maxTitleLength {
+			break
+		}
+		cut = i
+	}
+	return title[:cut]
+}
+
+// GetMindMap 获取思维导图（所有者与协作者可以获取）
 func (s *MindMapServiceImpl) GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error) {
+	mindMap, role, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	// 标签属于所有者，协作者看不到
+	if role == entity.MindMapRoleOwner {
+		if err := s.fillMindMapTags(ctx, []*entity.MindMap{mindMap}); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.fillMindMapStars(ctx, []*entity.MindMap{mindMap}); err != nil {
+		return nil, err
+	}
+	return mindMap, nil
+}
+
+// getAccessibleMindMap 获取当前用户可以访问的导图及其角色，既不是所有者也不是协作者时视为不存在
+func (s *MindMapServiceImpl) getAccessibleMindMap(ctx context.Context, mapID string) (*entity.MindMap, string, error) {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return nil, ErrPermissionDenied
+		return nil, "", ErrPermissionDenied
 	}
 
 	// 参数校验
 	if mapID == "" {
 		zlog.CtxErrorf(ctx, "mapID is required")
-		return nil, ErrInvalidParams
+		return nil, "", ErrInvalidParams
 	}
 
-	// 构建查询条件（包含用户ID验证）
-	query := repo.NewMindMapQueryByID(user.UserID, mapID)
-
-	// 查询思维导图
-	mindMap, err := s.mindMapRepo.GetMindMap(ctx, query)
+	// 先按所有者查询
+	mindMap, err := s.mindMapRepo.GetMindMap(ctx, repo.NewMindMapQueryByID(user.UserID, mapID))
 	if err != nil {
 		zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
-		return nil, ErrInternalError
+		return nil, "", ErrInternalError
+	}
+	if mindMap != nil {
+		zlog.CtxInfof(ctx, "mindmap retrieved successfully, mapID: %s, userID: %s", mapID, user.UserID)
+		return mindMap, entity.MindMapRoleOwner, nil
+	}
+
+	// 再按协作者查询
+	member, err := s.memberRepo.GetMember(ctx, mapID, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap member: %v", err)
+		return nil, "", ErrInternalError
+	}
+	if member != nil {
+		mindMap, err = s.mindMapRepo.GetMindMap(ctx, repo.NewMindMapQueryByID(member.OwnerID, mapID))
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
+			return nil, "", ErrInternalError
+		}
 	}
 	if mindMap == nil {
 		zlog.CtxWarnf(ctx, "mindmap not found or permission denied, mapID: %s, userID: %s", mapID, user.UserID)
-		return nil, ErrMindMapNotFound
+		return nil, "", ErrMindMapNotFound
 	}
 
-	zlog.CtxInfof(ctx, "mindmap retrieved successfully, mapID: %s, userID: %s", mapID, user.UserID)
+	zlog.CtxInfof(ctx, "mindmap retrieved by member, mapID: %s, userID: %s, role: %s", mapID, user.UserID, member.Role)
+	return mindMap, member.Role, nil
+}
+
+// getOwnedMindMap 获取当前用户作为所有者的导图，协作者返回权限不足
+func (s *MindMapServiceImpl) getOwnedMindMap(ctx context.Context, mapID string) (*entity.MindMap, error) {
+	mindMap, role, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if role != entity.MindMapRoleOwner {
+		zlog.CtxWarnf(ctx, "mindmap operation requires owner, mapID: %s, role: %s", mapID, role)
+		return nil, ErrPermissionDenied
+	}
 	return mindMap, nil
 }
 
-// ListMindMaps 获取思维导图列表（用户只能获取自己的思维导图列表）
-func (s *MindMapServiceImpl) ListMindMaps(ctx context.Context, req *types.ListMindMapsParams) ([]*entity.MindMap, int64, error) {
+// ListMindMaps 获取思维导图列表（自己的导图和/或与我共享的导图）
+func (s *MindMapServiceImpl) ListMindMaps(ctx context.Context, req *types.ListMindMapsParams) (*types.MindMapList, error) {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return nil, 0, ErrPermissionDenied
+		return nil, ErrPermissionDenied
 	}
 
 	// 构建查询条件（强制包含用户ID）
-	query := repo.NewMindMapQueryForList(user.UserID, req.Page, req.PageSize)
-
-	// 添加可选筛选条件
-	if req.Title != "" {
-		query.Title = req.Title
+	query, err := newListQuery(user.UserID, req)
+	if err != nil {
+		return nil, err
 	}
-	if req.Layout != "" {
-		query.Layout = req.Layout
+	pageSize := query.PageSize
+	if query.After != nil {
+		// 游标分页多取一条，用于判断是否还有下一页
+		query.PageSize = pageSize + 1
 	}
 
 	// 查询列表
 	mindMaps, total, err := s.mindMapRepo.ListMindMaps(ctx, query)
 	if err != nil {
 		zlog.CtxErrorf(ctx, "failed to list mindmaps: %v", err)
-		return nil, 0, ErrInternalError
+		return nil, ErrInternalError
+	}
+	list := &types.MindMapList{Total: total}
+	if query.After != nil {
+		if len(mindMaps) > pageSize {
+			mindMaps = mindMaps[:pageSize]
+			list.HasMore = true
+		}
+	} else {
+		list.HasMore = int64(query.Page*pageSize) < total
+	}
+	// 按收藏排序时游标需要最后一个导图的收藏状态
+	if err := s.fillMindMapStars(ctx, mindMaps); err != nil {
+		return nil, err
+	}
+	// 偏移分页同样返回游标，前端可以从任意一页切换为游标分页
+	if list.HasMore && len(mindMaps) > 0 {
+		list.NextCursor = encodeListCursor(query, mindMaps[len(mindMaps)-1])
+	}
+	list.MindMaps = mindMaps
+
+	// 只为自己的导图填充标签
+	owned := make([]*entity.MindMap, 0, len(mindMaps))
+	for _, mindMap := range mindMaps {
+		if mindMap.UserID == user.UserID {
+			owned = append(owned, mindMap)
+		}
+	}
+	if err := s.fillMindMapTags(ctx, owned); err != nil {
+		return nil, err
 	}
 
 	zlog.CtxInfof(ctx, "mindmaps listed successfully, userID: %s, count: %d, total: %d", user.UserID, len(mindMaps), total)
-	return mindMaps, total, nil
+	return list, nil
 }
 
-// UpdateMindMap 更新思维导图（用户只能更新自己的思维导图）
-func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, req *types.UpdateMindMapParams) error {
-	// 从JWT token上下文中获取用户信息
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return ErrPermissionDenied
-	}
+// newListQuery 列表与标签统计共用的查询条件
+func newListQuery(userID string, req *types.ListMindMapsParams) (repo.MindMapQuery, error) {
+	query := repo.NewMindMapQueryForList(userID, req.Page, req.PageSize)
+	query.Scope = req.Scope
 
-	// 参数校验
-	if mapID == "" {
-		zlog.CtxErrorf(ctx, "mapID is required")
-		return ErrInvalidParams
+	// 添加可选筛选条件
+	if req.Title != "" {
+		query.Title = req.Title
+	}
+	if req.Layout != "" {
+		query.Layout = req.Layout
+	}
+	if req.FolderID != "" {
+		query.FolderID = req.FolderID
+	}
+	if len(req.Tags) > 0 {
+		if len(req.Tags) > entity.MaxTagFilterCount {
+			return query, ErrInvalidParams
+		}
+		query.Tags = req.Tags
+	}
+	query.Starred = req.Starred
+
+	// 排序：标题默认升序，时间默认降序
+	switch req.SortBy {
+	case "":
+		query.SortBy = repo.MindMapSortUpdatedAt
+	case repo.MindMapSortUpdatedAt, repo.MindMapSortCreatedAt, repo.MindMapSortTitle, repo.MindMapSortStarred:
+		query.SortBy = req.SortBy
+	default:
+		return query, ErrInvalidParams
+	}
+	switch req.Order {
+	case "":
+		query.Ascending = query.SortBy == repo.MindMapSortTitle
+	case types.SortOrderAsc, types.SortOrderDesc:
+		query.Ascending = req.Order == types.SortOrderAsc
+	default:
+		return query, ErrInvalidParams
 	}
+	if req.Cursor != "" {
+		cursor, err := decodeListCursor(req.Cursor, query)
+		if err != nil {
+			return query, err
+		}
+		query.After = cursor
+	}
+	return query, nil
+}
 
-	// 先获取现有思维导图（用于校验和构建临时实体）
-	existingMindMap, err := s.GetMindMap(ctx, mapID)
+// UpdateMindMap 更新思维导图（所有者与编辑者可以更新）
+func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, req *types.UpdateMindMapParams) error {
+	// 先获取现有思维导图（用于权限校验和构建临时实体）
+	existingMindMap, role, err := s.getAccessibleMindMap(ctx, mapID)
 	if err != nil {
-		return err // GetMindMap已经包含权限验证
+		return err
 	}
-	if existingMindMap == nil {
-		return ErrMindMapNotFound
+	if !entity.CanEditMindMap(role) {
+		zlog.CtxWarnf(ctx, "viewer cannot update mindmap, mapID: %s", mapID)
+		return ErrPermissionDenied
 	}
 
 	// 将更新应用到临时实体以进行校验（复用实体层的校验逻辑）
@@ -180,11 +436,23 @@ func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, re
 		zlog.CtxErrorf(ctx, "mindmap validation failed after update: %v", err)
 		return err
 	}
+	if req.Data != nil {
+		// 节点数只在增加时校验，配额调低后仍可以编辑、删减已有的导图
+		if count := req.Data.CountNodes(); count > existingMindMap.Data.CountNodes() {
+			if err := s.quotaService.CheckMindMapNodeQuota(ctx, existingMindMap.UserID, count); err != nil {
+				return err
+			}
+		}
+		if err := req.Data.EnsureNodeIDs(); err != nil {
+			zlog.CtxErrorf(ctx, "failed to generate node ids: %v", err)
+			return ErrInternalError
+		}
+	}
 
 	// 构建更新信息
 	updateInfo := &repo.MindMapUpdateInfo{
 		MapID:  mapID,
-		UserID: user.UserID, // 确保只能更新自己的思维导图
+		UserID: existingMindMap.UserID, // 协作者修改时按所有者更新
 		Title:  req.Title,
 		Desc:   req.Desc,
 		Layout: req.Layout,
@@ -199,12 +467,13 @@ func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, re
 		zlog.CtxErrorf(ctx, "failed to update mindmap: %v", err)
 		return ErrInternalError
 	}
+	s.recordUpdateActivities(ctx, existingMindMap, req)
 
-	zlog.CtxInfof(ctx, "mindmap updated successfully, mapID: %s, userID: %s", mapID, user.UserID)
+	zlog.CtxInfof(ctx, "mindmap updated successfully, mapID: %s, role: %s", mapID, role)
 	return nil
 }
 
-// DeleteMindMap 删除思维导图（用户只能删除自己的思维导图）
+// DeleteMindMap 删除思维导图（只有所有者可以删除，协作者返回权限不足）
 func (s *MindMapServiceImpl) DeleteMindMap(ctx context.Context, mapID string) error {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
@@ -213,10 +482,8 @@ func (s *MindMapServiceImpl) DeleteMindMap(ctx context.Context, mapID string) er
 		return ErrPermissionDenied
 	}
 
-	// 参数校验
-	if mapID == "" {
-		zlog.CtxErrorf(ctx, "mapID is required")
-		return ErrInvalidParams
+	if _, err := s.getOwnedMindMap(ctx, mapID); err != nil {
+		return err
 	}
 
 	// 执行删除（软删除，repo层已包含权限校验）
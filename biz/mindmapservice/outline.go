@@ -0,0 +1,155 @@
+package mindmapservice
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+)
+
+const (
+	// MaxOutlineLength 纯文本大纲最大长度（字节）
+	MaxOutlineLength = 64 * 1024
+	// MaxOutlineNodes 纯文本大纲最多解析出的节点数
+	MaxOutlineNodes = 2000
+	// outlineTabWidth 制表符按4个空格计算缩进
+	outlineTabWidth = 4
+	// maxHeadingLevel Markdown 标题最多6级
+	maxHeadingLevel = 6
+)
+
+// outlineNode 解析过程中的临时节点，子节点使用指针便于边解析边挂载
+type outlineNode struct {
+	text     string
+	indent   int
+	children []*outlineNode
+}
+
+// parseOutline 将缩进/项目符号形式的纯文本解析为思维导图树
+// 规则：
+//   - 每个非空行是一个节点，缩进更深的行挂在上方最近的缩进更浅的行下面
+//   - 行首的项目符号（- * + • ·）和有序编号（1. 1) 1、）会被去掉
+//   - Markdown 标题（# ~ ######）按标题级别确定层级，其下的列表项挂在标题下面
+//   - 只有一个顶层节点时它就是根节点；有多个顶层节点时用 defaultRoot 作为根节点
+func parseOutline(text, defaultRoot string) (entity.MindMapData, int, error) {
+	root := &outlineNode{text: defaultRoot, indent: -maxHeadingLevel - 1}
+	stack := []*outlineNode{root}
+	count := 0
+
+	for _, rawLine := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		indent, content := splitIndent(rawLine)
+		if level, heading, ok := parseHeading(content); ok {
+			// 标题层级高于任何缩进：# 为最顶层，其下的列表项都挂在标题下面
+			indent, content = level-maxHeadingLevel-1, heading
+		} else {
+			content = stripListMarker(content)
+		}
+		if content == "" {
+			continue
+		}
+
+		count++
+		if count > MaxOutlineNodes {
+			return entity.MindMapData{}, 0, ErrOutlineTooLarge
+		}
+
+		// 回退到比当前行缩进更浅的父节点
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		node := &outlineNode{text: content, indent: indent}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, node)
+		stack = append(stack, node)
+	}
+
+	if count == 0 {
+		return entity.MindMapData{}, 0, ErrEmptyOutline
+	}
+
+	// 唯一的顶层节点直接作为根节点
+	if len(root.children) == 1 {
+		root = root.children[0]
+	}
+
+	return root.toMindMapData(), count, nil
+}
+
+func (n *outlineNode) toMindMapData() entity.MindMapData {
+	data := entity.MindMapData{
+		Data:     entity.NodeData{Text: n.text},
+		Children: make([]entity.MindMapData, 0, len(n.children)),
+	}
+	for _, child := range n.children {
+		data.Children = append(data.Children, child.toMindMapData())
+	}
+	return data
+}
+
+// splitIndent 计算行首缩进宽度，返回缩进与去掉首尾空白后的内容
+func splitIndent(line string) (int, string) {
+	indent := 0
+	for i, r := range line {
+		switch r {
+		case ' ', '　':
+			indent++
+		case '\t':
+			indent += outlineTabWidth
+		default:
+			return indent, strings.TrimSpace(line[i:])
+		}
+	}
+	return indent, ""
+}
+
+// parseHeading 解析 Markdown 标题，返回标题级别与标题文本
+func parseHeading(content string) (int, string, bool) {
+	level := 0
+	for level < len(content) && content[level] == '#' {
+		level++
+	}
+	if level == 0 || level > maxHeadingLevel {
+		return 0, "", false
+	}
+	rest := content[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	return level, strings.TrimSpace(rest), true
+}
+
+// stripListMarker 去掉行首的项目符号与有序编号
+func stripListMarker(content string) string {
+	// 项目符号：- * + • ·（后面需跟空白）
+	if r, size := utf8.DecodeRuneInString(content); strings.ContainsRune("-*+•·", r) {
+		rest := content[size:]
+		if rest == "" {
+			return ""
+		}
+		if next, _ := utf8.DecodeRuneInString(rest); unicode.IsSpace(next) {
+			return strings.TrimSpace(rest)
+		}
+	}
+
+	// 有序编号：1. / 1) / 1、
+	digits := 0
+	for digits < len(content) && content[digits] >= '0' && content[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(content) {
+		rest := content[digits:]
+		for _, marker := range []string{".", ")", "、"} {
+			if !strings.HasPrefix(rest, marker) {
+				continue
+			}
+			// 1.5 这类小数不是编号
+			if after := rest[len(marker):]; after != "" && after[0] >= '0' && after[0] <= '9' {
+				return content
+			}
+			return strings.TrimSpace(rest[len(marker):])
+		}
+	}
+
+	return content
+}
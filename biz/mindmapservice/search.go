@@ -0,0 +1,47 @@
+package mindmapservice
+
+import (
+	"context"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// SearchMindMaps 在自己的导图与与我共享的导图中搜索标题与节点文本
+func (s *MindMapServiceImpl) SearchMindMaps(ctx context.Context, req *types.SearchMindMapsParams) ([]*types.MindMapSearchHit, int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, 0, ErrPermissionDenied
+	}
+	terms, err := entity.ParseSearchTerms(req.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := repo.NewMindMapQueryForList(user.UserID, req.Page, req.PageSize)
+	query.Scope = repo.MindMapScopeAll
+	query.Keywords = terms
+	mindMaps, total, err := s.mindMapRepo.SearchMindMaps(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to search mindmaps: %v", err)
+		return nil, 0, ErrInternalError
+	}
+
+	hits := make([]*types.MindMapSearchHit, 0, len(mindMaps))
+	for _, mindMap := range mindMaps {
+		hit := &types.MindMapSearchHit{
+			MindMap:  mindMap,
+			Snippets: mindMap.Data.SearchSnippets(terms),
+		}
+		if title, ok := entity.HighlightTerms(mindMap.Title, terms, 0); ok {
+			hit.Title = title
+		}
+		hits = append(hits, hit)
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps searched, userID: %s, terms: %v, count: %d, total: %d", user.UserID, terms, len(hits), total)
+	return hits, total, nil
+}
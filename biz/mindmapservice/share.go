@@ -0,0 +1,223 @@
+package mindmapservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 分享令牌签名的用途前缀，避免与其他使用同一密钥的签名混用
+const shareTokenPurpose = "mindmap_share:"
+
+// ShareMindMap 为自己的导图创建只读分享链接
+func (s *MindMapServiceImpl) ShareMindMap(ctx context.Context, mapID string, req *types.ShareMindMapParams) (*types.MindMapShareLink, error) {
+	// 只有所有者可以分享
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := entity.ValidateSharePassword(req.Password); err != nil {
+		return nil, err
+	}
+	ttl := s.shareConfig.DefaultExpiration()
+	if req.ExpireHours != 0 {
+		ttl = time.Duration(req.ExpireHours) * time.Hour
+	}
+	if ttl <= 0 || ttl > s.shareConfig.MaxExpiration() {
+		return nil, ErrInvalidShareExpire
+	}
+
+	now := time.Now()
+	count, err := s.shareRepo.CountMapShares(ctx, mindMap.MapID, now)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to count mindmap shares: %v", err)
+		return nil, ErrInternalError
+	}
+	if count >= s.shareConfig.ShareLimit() {
+		return nil, ErrShareLimitExceeded
+	}
+
+	shareID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate share id: %v", err)
+		return nil, ErrInternalError
+	}
+	share := &entity.MindMapShare{
+		ShareID:   shareID,
+		MapID:     mindMap.MapID,
+		OwnerID:   mindMap.UserID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if req.Password != "" {
+		if share.PasswordHash, err = util.HashPassword(req.Password); err != nil {
+			zlog.CtxErrorf(ctx, "failed to hash share password: %v", err)
+			return nil, ErrInternalError
+		}
+	}
+	if err := s.shareRepo.CreateShare(ctx, share); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap share: %v", err)
+		return nil, ErrInternalError
+	}
+	s.recordActivities(ctx, s.newActivity(ctx, mindMap, entity.MindMapActivityShared))
+
+	zlog.CtxInfof(ctx, "mindmap share created, mapID: %s, shareID: %s, expiresAt: %v", mindMap.MapID, shareID, share.ExpiresAt)
+	return s.shareLink(share), nil
+}
+
+// ListMindMapShares 自己导图仍有效的分享链接
+func (s *MindMapServiceImpl) ListMindMapShares(ctx context.Context, mapID string) ([]*types.MindMapShareLink, error) {
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shareRepo.ListMapShares(ctx, mindMap.MapID, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap shares: %v", err)
+		return nil, ErrInternalError
+	}
+	links := make([]*types.MindMapShareLink, 0, len(shares))
+	for _, share := range shares {
+		links = append(links, s.shareLink(share))
+	}
+	return links, nil
+}
+
+// RevokeMindMapShare 撤销自己导图的分享链接，撤销后链接立即失效
+func (s *MindMapServiceImpl) RevokeMindMapShare(ctx context.Context, mapID, shareID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	if mapID == "" || shareID == "" {
+		return ErrInvalidParams
+	}
+
+	if err := s.shareRepo.DeleteShare(ctx, shareID, mapID, user.UserID); err != nil {
+		if errors.Is(err, repo.ErrMindMapShareNotFound) {
+			return ErrShareNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap share: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap share revoked, mapID: %s, shareID: %s", mapID, shareID)
+	return nil
+}
+
+// GetSharedMindMap 通过分享链接查看导图，不需要登录
+// 令牌签名不对、分享已撤销或导图已删除时统一返回链接无效，不区分具体原因
+func (s *MindMapServiceImpl) GetSharedMindMap(ctx context.Context, token, password string) (*entity.MindMap, *entity.MindMapShare, error) {
+	shareID, ok := s.parseShareToken(token)
+	if !ok {
+		zlog.CtxWarnf(ctx, "invalid mindmap share token")
+		return nil, nil, ErrShareNotFound
+	}
+
+	share, err := s.shareRepo.GetShare(ctx, shareID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap share: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	if share == nil {
+		return nil, nil, ErrShareNotFound
+	}
+	if share.Expired(time.Now()) {
+		return nil, nil, ErrShareExpired
+	}
+	if share.HasPassword() {
+		if err := s.checkSharePassword(ctx, share, password); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 按分享创建者查询，导图已删除或已转移时链接失效
+	mindMap, err := s.mindMapRepo.GetMindMap(ctx, repo.NewMindMapQueryByID(share.OwnerID, share.MapID))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get shared mindmap: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	if mindMap == nil {
+		return nil, nil, ErrShareNotFound
+	}
+
+	zlog.CtxInfof(ctx, "shared mindmap viewed, mapID: %s, shareID: %s", share.MapID, share.ShareID)
+	return mindMap, share, nil
+}
+
+// checkSharePassword 校验访问密码，同一来源IP错误次数过多时暂时拒绝
+func (s *MindMapServiceImpl) checkSharePassword(ctx context.Context, share *entity.MindMapShare, password string) error {
+	if password == "" {
+		return ErrSharePasswordNeeded
+	}
+
+	failKey := fmt.Sprintf(constant.REDIS_MINDMAP_SHARE_FAIL_KEY, share.ShareID, entity.GetClientIP(ctx))
+	failures, err := cache.GetRedis(ctx, failKey)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get share password failures failed: %v", err)
+		return ErrInternalError
+	}
+	if n, _ := strconv.ParseInt(failures, 10, 64); n >= s.shareConfig.PasswordFailureLimit() {
+		zlog.CtxWarnf(ctx, "share password attempts exceeded, shareID: %s", share.ShareID)
+		return ErrShareTooManyAttempts
+	}
+
+	match, err := util.ComparePassword(share.PasswordHash, password)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "compare share password failed: %v", err)
+		return ErrInternalError
+	}
+	if !match {
+		if _, err := cache.IncrRedis(ctx, failKey, s.shareConfig.PasswordFailWindow()); err != nil {
+			zlog.CtxErrorf(ctx, "incr share password failures failed: %v", err)
+		}
+		return ErrSharePasswordWrong
+	}
+	return nil
+}
+
+func (s *MindMapServiceImpl) shareLink(share *entity.MindMapShare) *types.MindMapShareLink {
+	token := share.ShareID + "." + s.shareSignature(share.ShareID)
+	link := &types.MindMapShareLink{
+		Share: share,
+		Token: token,
+	}
+	if s.shareConfig.URL != "" {
+		link.URL = s.shareConfig.URL + "?token=" + url.QueryEscape(token)
+	}
+	return link
+}
+
+// parseShareToken 校验令牌签名并取出分享ID
+func (s *MindMapServiceImpl) parseShareToken(token string) (string, bool) {
+	shareID, signature, ok := strings.Cut(token, ".")
+	if !ok || shareID == "" || s.shareConfig.Secret == "" {
+		return "", false
+	}
+	return shareID, hmac.Equal([]byte(signature), []byte(s.shareSignature(shareID)))
+}
+
+// shareSignature 分享令牌签名：HMAC-SHA256(secret, purpose+shareID)
+func (s *MindMapServiceImpl) shareSignature(shareID string) string {
+	mac := hmac.New(sha256.New, []byte(s.shareConfig.Secret))
+	mac.Write([]byte(shareTokenPurpose + shareID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,173 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/cache/cachetest"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	zlog.InitLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// stubShareRepo 只实现 GetShare，调用其他方法会 panic
+type stubShareRepo struct {
+	repo.IMindMapShareRepo
+	shares map[string]*entity.MindMapShare
+}
+
+func (r *stubShareRepo) GetShare(ctx context.Context, shareID string) (*entity.MindMapShare, error) {
+	return r.shares[shareID], nil
+}
+
+// stubMindMapRepo 只实现 GetMindMap，按所有者与导图ID查找
+type stubMindMapRepo struct {
+	repo.IMindMapRepo
+	mindMaps []*entity.MindMap
+}
+
+func (r *stubMindMapRepo) GetMindMap(ctx context.Context, query repo.MindMapQuery) (*entity.MindMap, error) {
+	for _, m := range r.mindMaps {
+		if m.UserID == query.UserID && m.MapID == query.MapID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestShareToken(t *testing.T) {
+	s := &MindMapServiceImpl{shareConfig: configs.MindMapShareConfig{Secret: "secret", URL: "https://forge.example/share"}}
+	link := s.shareLink(&entity.MindMapShare{ShareID: "s1"})
+
+	if !strings.HasPrefix(link.Token, "s1.") {
+		t.Fatalf("shareLink() token = %s, want s1.<signature>", link.Token)
+	}
+	if link.URL != "https://forge.example/share?token="+url.QueryEscape(link.Token) {
+		t.Errorf("shareLink() url = %s", link.URL)
+	}
+	if shareID, ok := s.parseShareToken(link.Token); !ok || shareID != "s1" {
+		t.Errorf("parseShareToken() = %s, %v, want s1, true", shareID, ok)
+	}
+
+	other := &MindMapServiceImpl{shareConfig: configs.MindMapShareConfig{Secret: "other"}}
+	noSecret := &MindMapServiceImpl{}
+	signature := strings.TrimPrefix(link.Token, "s1.")
+	tests := []struct {
+		name  string
+		s     *MindMapServiceImpl
+		token string
+	}{
+		{name: "换了分享ID", s: s, token: "s2." + signature},
+		{name: "签名被修改", s: s, token: link.Token + "x"},
+		{name: "没有签名", s: s, token: "s1"},
+		{name: "空分享ID", s: s, token: "." + signature},
+		{name: "密钥不同", s: other, token: link.Token},
+		{name: "未配置密钥", s: noSecret, token: noSecret.shareLink(&entity.MindMapShare{ShareID: "s1"}).Token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.s.parseShareToken(tt.token); ok {
+				t.Errorf("parseShareToken(%q) ok = true, want false", tt.token)
+			}
+		})
+	}
+}
+
+func TestGetSharedMindMap(t *testing.T) {
+	hash, err := util.HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	now := time.Now()
+	shares := map[string]*entity.MindMapShare{
+		"open":     {ShareID: "open", MapID: "m1", OwnerID: "u1", ExpiresAt: now.Add(time.Hour)},
+		"locked":   {ShareID: "locked", MapID: "m1", OwnerID: "u1", PasswordHash: hash, ExpiresAt: now.Add(time.Hour)},
+		"expired":  {ShareID: "expired", MapID: "m1", OwnerID: "u1", ExpiresAt: now.Add(-time.Minute)},
+		"moved":    {ShareID: "moved", MapID: "m1", OwnerID: "u2", ExpiresAt: now.Add(time.Hour)},
+		"no-owner": {ShareID: "no-owner", MapID: "m2", OwnerID: "u1", ExpiresAt: now.Add(time.Hour)},
+	}
+	s := &MindMapServiceImpl{
+		shareRepo:   &stubShareRepo{shares: shares},
+		mindMapRepo: &stubMindMapRepo{mindMaps: []*entity.MindMap{{MapID: "m1", UserID: "u1"}}},
+		shareConfig: configs.MindMapShareConfig{Secret: "secret", MaxPasswordFailures: 2},
+	}
+	token := func(shareID string) string {
+		return s.shareLink(&entity.MindMapShare{ShareID: shareID}).Token
+	}
+	ctx := entity.WithClientIP(context.Background(), "203.0.113.1")
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantErr  error
+	}{
+		{name: "无需密码", token: token("open")},
+		{name: "密码正确", token: token("locked"), password: "pass1234"},
+		{name: "签名无效", token: "open.invalid", wantErr: ErrShareNotFound},
+		{name: "分享不存在", token: token("missing"), wantErr: ErrShareNotFound},
+		{name: "已过期", token: token("expired"), wantErr: ErrShareExpired},
+		{name: "未输入密码", token: token("locked"), wantErr: ErrSharePasswordNeeded},
+		// 导图已转移给其他用户或已删除时链接失效
+		{name: "导图已转移", token: token("moved"), wantErr: ErrShareNotFound},
+		{name: "导图已删除", token: token("no-owner"), wantErr: ErrShareNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachetest.Start(t)
+			mindMap, share, err := s.GetSharedMindMap(ctx, tt.token, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetSharedMindMap() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (mindMap == nil || share == nil || mindMap.MapID != share.MapID) {
+				t.Errorf("GetSharedMindMap() = %+v, %+v", mindMap, share)
+			}
+		})
+	}
+}
+
+func TestGetSharedMindMapPasswordAttempts(t *testing.T) {
+	hash, err := util.HashPassword("pass1234")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	s := &MindMapServiceImpl{
+		shareRepo: &stubShareRepo{shares: map[string]*entity.MindMapShare{
+			"locked": {ShareID: "locked", MapID: "m1", OwnerID: "u1", PasswordHash: hash, ExpiresAt: time.Now().Add(time.Hour)},
+		}},
+		mindMapRepo: &stubMindMapRepo{mindMaps: []*entity.MindMap{{MapID: "m1", UserID: "u1"}}},
+		shareConfig: configs.MindMapShareConfig{Secret: "secret", MaxPasswordFailures: 2},
+	}
+	token := s.shareLink(&entity.MindMapShare{ShareID: "locked"}).Token
+	ctx := entity.WithClientIP(context.Background(), "203.0.113.1")
+	cachetest.Start(t)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := s.GetSharedMindMap(ctx, token, "wrong"); !errors.Is(err, ErrSharePasswordWrong) {
+			t.Fatalf("attempt %d: GetSharedMindMap() error = %v, want ErrSharePasswordWrong", i+1, err)
+		}
+	}
+	// 达到上限后即使密码正确也暂时拒绝
+	if _, _, err := s.GetSharedMindMap(ctx, token, "pass1234"); !errors.Is(err, ErrShareTooManyAttempts) {
+		t.Errorf("GetSharedMindMap() error = %v, want ErrShareTooManyAttempts", err)
+	}
+	// 按来源IP计数，其他IP不受影响
+	otherCtx := entity.WithClientIP(context.Background(), "203.0.113.2")
+	if _, _, err := s.GetSharedMindMap(otherCtx, token, "pass1234"); err != nil {
+		t.Errorf("GetSharedMindMap() from other ip error = %v", err)
+	}
+}
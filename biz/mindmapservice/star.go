@@ -0,0 +1,76 @@
+package mindmapservice
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+)
+
+// StarMindMap 收藏导图，所有者与协作者都可以收藏，已收藏时不报错
+func (s *MindMapServiceImpl) StarMindMap(ctx context.Context, mapID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	mindMap, _, err := s.getAccessibleMindMap(ctx, mapID)
+	if err != nil {
+		return err
+	}
+
+	star := &entity.MindMapStar{
+		UserID:    user.UserID,
+		MapID:     mindMap.MapID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.starRepo.StarMindMap(ctx, star); err != nil {
+		zlog.CtxErrorf(ctx, "failed to star mindmap: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap starred, mapID: %s, userID: %s", mapID, user.UserID)
+	return nil
+}
+
+// UnstarMindMap 取消收藏，不再能访问导图（被移出协作）后也可以取消，未收藏时不报错
+func (s *MindMapServiceImpl) UnstarMindMap(ctx context.Context, mapID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+	if mapID == "" {
+		return ErrInvalidParams
+	}
+
+	if err := s.starRepo.UnstarMindMap(ctx, user.UserID, mapID); err != nil {
+		zlog.CtxErrorf(ctx, "failed to unstar mindmap: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap unstarred, mapID: %s, userID: %s", mapID, user.UserID)
+	return nil
+}
+
+// fillMindMapStars 批量填充当前用户是否收藏了这些导图
+func (s *MindMapServiceImpl) fillMindMapStars(ctx context.Context, mindMaps []*entity.MindMap) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok || len(mindMaps) == 0 {
+		return nil
+	}
+	mapIDs := make([]string, 0, len(mindMaps))
+	for _, mindMap := range mindMaps {
+		mapIDs = append(mapIDs, mindMap.MapID)
+	}
+	starred, err := s.starRepo.ListStarredMapIDs(ctx, user.UserID, mapIDs)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list starred mindmaps: %v", err)
+		return ErrInternalError
+	}
+	for _, mindMap := range mindMaps {
+		mindMap.Starred = starred[mindMap.MapID]
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// SetMindMapTags 替换自己导图的全部标签，不存在的标签自动创建，不再使用的标签自动删除
+func (s *MindMapServiceImpl) SetMindMapTags(ctx context.Context, mapID string, names []string) ([]string, error) {
+	// 标签属于所有者，协作者不能修改
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	names, err = entity.NormalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.ListTags(ctx, mindMap.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+	known := make(map[string]struct{}, len(existing))
+	for _, tag := range existing {
+		known[tag.Name] = struct{}{}
+	}
+	now := time.Now()
+	created := 0
+	tags := make([]*entity.MindMapTag, 0, len(names))
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			created++
+		}
+		tagID, err := util.GenerateStringID()
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to generate tag id: %v", err)
+			return nil, ErrInternalError
+		}
+		tags = append(tags, &entity.MindMapTag{TagID: tagID, UserID: mindMap.UserID, Name: name, CreatedAt: now})
+	}
+	if created > 0 && len(existing)+created > entity.MaxTagsPerUser {
+		return nil, ErrTagLimitExceeded
+	}
+
+	tags, err = s.tagRepo.EnsureTags(ctx, tags)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to ensure mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+	tagIDs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.TagID)
+	}
+	if err := s.tagRepo.SetMapTags(ctx, mindMap.UserID, mindMap.MapID, tagIDs); err != nil {
+		zlog.CtxErrorf(ctx, "failed to set mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap tags set, mapID: %s, tags: %v", mapID, names)
+	return names, nil
+}
+
+// RemoveMindMapTag 移除自己导图的一个标签
+func (s *MindMapServiceImpl) RemoveMindMapTag(ctx context.Context, mapID, name string) error {
+	mindMap, err := s.getOwnedMindMap(ctx, mapID)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.tagRepo.GetTagByName(ctx, mindMap.UserID, name)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap tag: %v", err)
+		return ErrInternalError
+	}
+	if tag == nil {
+		return ErrTagNotFound
+	}
+	if err := s.tagRepo.RemoveMapTag(ctx, mindMap.UserID, mindMap.MapID, tag.TagID); err != nil {
+		if errors.Is(err, repo.ErrMindMapTagNotFound) {
+			return ErrTagNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to remove mindmap tag: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap tag removed, mapID: %s, tag: %s", mapID, name)
+	return nil
+}
+
+// ListMindMapTags 自己的所有标签及各标签的导图数
+func (s *MindMapServiceImpl) ListMindMapTags(ctx context.Context) ([]*entity.MindMapTag, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	tags, err := s.tagRepo.ListTags(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+	return tags, nil
+}
+
+// CountMindMapTags 列表筛选条件下自己的导图中各标签的导图数，用于列表的标签筛选项
+func (s *MindMapServiceImpl) CountMindMapTags(ctx context.Context, req *types.ListMindMapsParams) ([]*entity.MindMapTag, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	query, err := newListQuery(user.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.mindMapRepo.CountMindMapTags(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to count mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+	return tags, nil
+}
+
+// fillMindMapTags 批量填充导图的标签，调用方保证都是当前用户自己的导图
+func (s *MindMapServiceImpl) fillMindMapTags(ctx context.Context, mindMaps []*entity.MindMap) error {
+	if len(mindMaps) == 0 {
+		return nil
+	}
+	mapIDs := make([]string, 0, len(mindMaps))
+	for _, mindMap := range mindMaps {
+		mapIDs = append(mapIDs, mindMap.MapID)
+	}
+	tags, err := s.tagRepo.ListMapTagNames(ctx, mapIDs)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap tag names: %v", err)
+		return ErrInternalError
+	}
+	for _, mindMap := range mindMaps {
+		mindMap.Tags = tags[mindMap.MapID]
+	}
+	return nil
+}
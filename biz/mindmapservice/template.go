@@ -0,0 +1,167 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// CreateMindMapTemplate 管理员创建导图模板
+func (s *MindMapServiceImpl) CreateMindMapTemplate(ctx context.Context, req *types.MindMapTemplateParams) (*entity.MindMapTemplate, error) {
+	admin, err := templateAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &entity.MindMapTemplate{
+		Title:    req.Title,
+		Desc:     req.Desc,
+		Category: req.Category,
+		Layout:   req.Layout,
+		Data:     req.Data,
+		Sort:     req.Sort,
+	}
+	if err := template.Normalize(); err != nil {
+		return nil, err
+	}
+
+	templateID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate template id: %v", err)
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	template.TemplateID = templateID
+	template.CreatedBy = admin.UserID
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	if err := s.templateRepo.CreateTemplate(ctx, template); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap template: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap template created, templateID: %s, category: %s", templateID, template.Category)
+	return template, nil
+}
+
+// UpdateMindMapTemplate 管理员修改导图模板，已经基于模板创建的导图不受影响
+func (s *MindMapServiceImpl) UpdateMindMapTemplate(ctx context.Context, templateID string, req *types.MindMapTemplateParams) (*entity.MindMapTemplate, error) {
+	if _, err := templateAdmin(ctx); err != nil {
+		return nil, err
+	}
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Title = req.Title
+	template.Desc = req.Desc
+	template.Category = req.Category
+	template.Layout = req.Layout
+	template.Data = req.Data
+	template.Sort = req.Sort
+	if err := template.Normalize(); err != nil {
+		return nil, err
+	}
+	template.UpdatedAt = time.Now()
+	if err := s.templateRepo.UpdateTemplate(ctx, template); err != nil {
+		if errors.Is(err, repo.ErrMindMapTemplateNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap template: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap template updated, templateID: %s", templateID)
+	return template, nil
+}
+
+// DeleteMindMapTemplate 管理员删除导图模板
+func (s *MindMapServiceImpl) DeleteMindMapTemplate(ctx context.Context, templateID string) error {
+	if _, err := templateAdmin(ctx); err != nil {
+		return err
+	}
+	if templateID == "" {
+		return ErrTemplateNotFound
+	}
+	if err := s.templateRepo.DeleteTemplate(ctx, templateID); err != nil {
+		if errors.Is(err, repo.ErrMindMapTemplateNotFound) {
+			return ErrTemplateNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap template: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap template deleted, templateID: %s", templateID)
+	return nil
+}
+
+// ListMindMapTemplates 模板列表及全部分类，category 为空时返回全部模板
+func (s *MindMapServiceImpl) ListMindMapTemplates(ctx context.Context, category string) ([]*entity.MindMapTemplate, []string, error) {
+	templates, err := s.templateRepo.ListTemplates(ctx, category)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap templates: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	categories, err := s.templateRepo.ListCategories(ctx)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap template categories: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	return templates, categories, nil
+}
+
+// CreateMindMapFromTemplate 基于模板创建自己的导图，与直接创建一样受套餐配额限制
+func (s *MindMapServiceImpl) CreateMindMapFromTemplate(ctx context.Context, templateID string, req *types.CreateMindMapFromTemplateParams) (*entity.MindMap, error) {
+	template, err := s.getTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := req.Title
+	if title == "" {
+		title = template.Title
+	}
+	zlog.CtxInfof(ctx, "creating mindmap from template, templateID: %s", templateID)
+	return s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:    title,
+		Desc:     template.Desc,
+		Layout:   template.Layout,
+		Data:     template.Data,
+		FolderID: req.FolderID,
+	})
+}
+
+func (s *MindMapServiceImpl) getTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error) {
+	if templateID == "" {
+		return nil, ErrTemplateNotFound
+	}
+	template, err := s.templateRepo.GetTemplate(ctx, templateID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap template: %v", err)
+		return nil, ErrInternalError
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// templateAdmin 模板只能由管理员维护
+func templateAdmin(ctx context.Context) (*entity.User, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	if user.Role != entity.UserRoleAdmin {
+		return nil, ErrPermissionDenied
+	}
+	return user, nil
+}
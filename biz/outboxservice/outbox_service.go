@@ -0,0 +1,177 @@
+package outboxservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrInvalidParams = errors.New("参数无效")
+)
+
+const (
+	// 与数据库列宽一致
+	maxErrorLength = 255
+	// 投递失败后的最长重试间隔
+	maxRetryBackoff = 10 * time.Minute
+	// 每次删除的已投递事件数
+	purgeBatchSize = 1000
+	// 发送告警的超时时间
+	alertTimeout = 10 * time.Second
+)
+
+// OutboxServiceImpl 事件发件箱服务实现
+type OutboxServiceImpl struct {
+	outboxRepo repo.OutboxRepo
+	// 未开启发件箱时为 nil
+	publisher adapter.EventPublisher
+	// 未配置告警时为 nil
+	notifier adapter.AlertNotifier
+	conf     configs.OutboxConfig
+}
+
+func NewOutboxServiceImpl(outboxRepo repo.OutboxRepo, publisher adapter.EventPublisher, notifier adapter.AlertNotifier, conf configs.OutboxConfig) *OutboxServiceImpl {
+	return &OutboxServiceImpl{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		notifier:   notifier,
+		conf:       conf,
+	}
+}
+
+// Record 写入发件箱，ctx 中的事务提交后事件才会被投递
+func (o *OutboxServiceImpl) Record(ctx context.Context, topic, key string, payload any) error {
+	if o.publisher == nil {
+		return nil
+	}
+	if topic == "" {
+		return ErrInvalidParams
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event payload: %w", topic, err)
+	}
+	eventID, err := util.GenerateStringID()
+	if err != nil {
+		return fmt.Errorf("generate event id: %w", err)
+	}
+	now := time.Now()
+	return o.outboxRepo.AddEvent(ctx, &entity.OutboxEvent{
+		EventID:       eventID,
+		Topic:         topic,
+		Key:           key,
+		Payload:       string(data),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
+
+// Relay 按写入顺序投递一批事件，投递成功后标记已投递
+// 投递成功但标记失败的事件会在下一轮重复投递，由消费方按 event_id 去重
+// 投递失败或遇到未到重试时间的事件时停止本轮投递，其后的事件等它投递成功后再投递，保证投递顺序；
+// 失败次数达到上限的事件放弃投递并告警，不再阻塞其后的事件
+func (o *OutboxServiceImpl) Relay(ctx context.Context) (int, error) {
+	if o.publisher == nil {
+		return 0, nil
+	}
+	events, err := o.outboxRepo.ListPendingEvents(ctx, o.conf.Batch())
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+		if event.NextAttemptAt.After(time.Now()) {
+			return published, nil
+		}
+		if publishErr := o.publisher.Publish(ctx, event); publishErr != nil {
+			attempts := event.Attempts + 1
+			lastError := truncate(publishErr.Error(), maxErrorLength)
+			if attempts >= o.conf.AttemptLimit() {
+				zlog.CtxErrorf(ctx, "publish outbox event %s (%s) failed %d times, give up: %v",
+					event.EventID, event.Topic, attempts, publishErr)
+				if err := o.outboxRepo.MarkEventDead(ctx, event.ID, lastError, time.Now()); err != nil {
+					return published, err
+				}
+				o.alertDeadEvent(ctx, event, attempts, publishErr)
+				continue
+			}
+			backoff := retryBackoff(o.conf.Interval(), attempts)
+			zlog.CtxWarnf(ctx, "publish outbox event %s (%s) failed, attempts: %d, retry in %s: %v",
+				event.EventID, event.Topic, attempts, backoff, publishErr)
+			if err := o.outboxRepo.MarkEventFailed(ctx, event.ID, lastError, time.Now().Add(backoff)); err != nil {
+				return published, err
+			}
+			return published, nil
+		}
+		if err := o.outboxRepo.MarkEventPublished(ctx, event.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// alertDeadEvent 放弃投递的事件需人工处理，告警 key 为事件类型，同类事件按间隔合并
+func (o *OutboxServiceImpl) alertDeadEvent(ctx context.Context, event *entity.OutboxEvent, attempts int, err error) {
+	if o.notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, alertTimeout)
+	defer cancel()
+
+	content := fmt.Sprintf("事件: %s\ntopic: %s\nkey: %s\n投递次数: %d\n错误: %v",
+		event.EventID, event.Topic, event.Key, attempts, err)
+	if notifyErr := o.notifier.Notify(ctx, "outbox:"+event.Topic, "[forge] 事件投递失败，已放弃投递", content); notifyErr != nil {
+		zlog.CtxErrorf(ctx, "send outbox alert failed: %v", notifyErr)
+	}
+}
+
+// PurgePublishedEvents 分批删除投递时间早于保留期的事件
+func (o *OutboxServiceImpl) PurgePublishedEvents(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-o.conf.Retention())
+	var total int64
+	for {
+		n, err := o.outboxRepo.PurgePublishedEvents(ctx, before, purgeBatchSize)
+		total += n
+		if err != nil || n < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+// retryBackoff 第 attempts 次失败后的重试间隔，从投递间隔开始逐次翻倍，最长 maxRetryBackoff
+func retryBackoff(interval time.Duration, attempts int) time.Duration {
+	backoff := interval
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// truncate 按字节截断过长的文本，避免截断在多字节字符中间
+func truncate(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	text = text[:maxLength]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
@@ -0,0 +1,132 @@
+package outboxservice
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	zlog.InitLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// stubOutboxRepo 在内存中保存事件，按 ListPendingEvents 的条件过滤
+type stubOutboxRepo struct {
+	repo.OutboxRepo
+	events []*entity.OutboxEvent
+}
+
+func (r *stubOutboxRepo) ListPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var events []*entity.OutboxEvent
+	for _, e := range r.events {
+		if e.PublishedAt == nil && e.FailedAt == nil && len(events) < limit {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func (r *stubOutboxRepo) find(id uint64) *entity.OutboxEvent {
+	for _, e := range r.events {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (r *stubOutboxRepo) MarkEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	r.find(id).PublishedAt = &publishedAt
+	return nil
+}
+
+func (r *stubOutboxRepo) MarkEventFailed(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error {
+	e := r.find(id)
+	e.Attempts++
+	e.LastError = lastError
+	e.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (r *stubOutboxRepo) MarkEventDead(ctx context.Context, id uint64, lastError string, failedAt time.Time) error {
+	e := r.find(id)
+	e.Attempts++
+	e.LastError = lastError
+	e.FailedAt = &failedAt
+	return nil
+}
+
+// stubPublisher 投递 failing 中的事件时返回错误
+type stubPublisher struct {
+	failing   map[string]bool
+	published []string
+}
+
+func (p *stubPublisher) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	if p.failing[event.EventID] {
+		return errors.New("stream unavailable")
+	}
+	p.published = append(p.published, event.EventID)
+	return nil
+}
+
+// stubNotifier 记录发送的告警
+type stubNotifier struct {
+	keys []string
+}
+
+func (n *stubNotifier) Notify(ctx context.Context, key, title, content string) error {
+	n.keys = append(n.keys, key)
+	return nil
+}
+
+func TestRelayGivesUpAfterMaxAttempts(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	outboxRepo := &stubOutboxRepo{events: []*entity.OutboxEvent{
+		{ID: 1, EventID: "e1", Topic: entity.EventUserRegistered, NextAttemptAt: past},
+		{ID: 2, EventID: "e2", Topic: entity.EventUserRegistered, NextAttemptAt: past},
+	}}
+	publisher := &stubPublisher{failing: map[string]bool{"e1": true}}
+	notifier := &stubNotifier{}
+	o := NewOutboxServiceImpl(outboxRepo, publisher, notifier, configs.OutboxConfig{MaxAttempts: 2})
+	ctx := context.Background()
+
+	// 第一次失败后等待重试，其后的事件不投递
+	if n, err := o.Relay(ctx); err != nil || n != 0 {
+		t.Fatalf("Relay() = %d, %v, want 0, nil", n, err)
+	}
+	if len(publisher.published) != 0 {
+		t.Fatalf("published = %v, want none before e1 succeeds", publisher.published)
+	}
+
+	// 达到上限后放弃投递 e1 并告警，继续投递 e2
+	outboxRepo.events[0].NextAttemptAt = past
+	if n, err := o.Relay(ctx); err != nil || n != 1 {
+		t.Fatalf("Relay() = %d, %v, want 1, nil", n, err)
+	}
+	if dead := outboxRepo.events[0]; dead.FailedAt == nil || dead.Attempts != 2 || dead.LastError == "" {
+		t.Errorf("e1 = %+v, want marked dead after 2 attempts", dead)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != "e2" {
+		t.Errorf("published = %v, want [e2]", publisher.published)
+	}
+	if len(notifier.keys) != 1 || notifier.keys[0] != "outbox:"+entity.EventUserRegistered {
+		t.Errorf("alerts = %v, want one for %s", notifier.keys, entity.EventUserRegistered)
+	}
+
+	// 放弃投递的事件不再重试
+	if n, err := o.Relay(ctx); err != nil || n != 0 {
+		t.Errorf("Relay() = %d, %v, want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,199 @@
+package presetservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrPresetNotFound      = errors.New("预设不存在")
+	ErrPermissionDenied    = errors.New("权限不足")
+	ErrUserPresetDisabled  = errors.New("未开放自定义预设")
+	ErrPresetLimitExceeded = errors.New("自定义预设数量已达上限")
+	ErrInternalError       = errors.New("内部错误")
+)
+
+// PromptPresetServiceImpl 对话预设服务实现
+type PromptPresetServiceImpl struct {
+	presetRepo repo.PromptPresetRepo
+	config     configs.PromptPresetConfig
+}
+
+func NewPromptPresetServiceImpl(presetRepo repo.PromptPresetRepo, cfg configs.PromptPresetConfig) *PromptPresetServiceImpl {
+	return &PromptPresetServiceImpl{
+		presetRepo: presetRepo,
+		config:     cfg,
+	}
+}
+
+// CreatePreset 创建预设：管理员创建全局预设，开启用户预设时用户可以创建自己的预设
+func (s *PromptPresetServiceImpl) CreatePreset(ctx context.Context, req *types.PromptPresetParams) (*entity.PromptPreset, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	preset := &entity.PromptPreset{
+		Name:         req.Name,
+		Description:  req.Description,
+		SystemPrompt: req.SystemPrompt,
+		Params:       req.Params,
+	}
+	if err := preset.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if req.Global {
+		if user.Role != entity.UserRoleAdmin {
+			return nil, ErrPermissionDenied
+		}
+	} else {
+		if !s.config.AllowUserPresets {
+			return nil, ErrUserPresetDisabled
+		}
+		count, err := s.presetRepo.CountUserPresets(ctx, user.UserID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "count prompt presets failed: %v", err)
+			return nil, ErrInternalError
+		}
+		if count >= s.config.UserPresetLimit() {
+			return nil, ErrPresetLimitExceeded
+		}
+		preset.OwnerID = user.UserID
+	}
+
+	presetID, err := util.GenerateStringID()
+	if err != nil {
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	preset.PresetID = presetID
+	preset.CreatedAt = now
+	preset.UpdatedAt = now
+	if err := s.presetRepo.CreatePreset(ctx, preset); err != nil {
+		zlog.CtxErrorf(ctx, "create prompt preset failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "prompt preset %s created by user %s, global: %v", presetID, user.UserID, req.Global)
+	return preset, nil
+}
+
+// UpdatePreset 修改预设的名称、描述、系统提示词与模型参数
+func (s *PromptPresetServiceImpl) UpdatePreset(ctx context.Context, presetID string, req *types.PromptPresetParams) (*entity.PromptPreset, error) {
+	preset, err := s.getManageablePreset(ctx, presetID, req.Global)
+	if err != nil {
+		return nil, err
+	}
+
+	preset.Name = req.Name
+	preset.Description = req.Description
+	preset.SystemPrompt = req.SystemPrompt
+	preset.Params = req.Params
+	if err := preset.Normalize(); err != nil {
+		return nil, err
+	}
+	preset.UpdatedAt = time.Now()
+	if err := s.presetRepo.UpdatePreset(ctx, preset); err != nil {
+		zlog.CtxErrorf(ctx, "update prompt preset failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return preset, nil
+}
+
+// DeletePreset 删除预设，使用该预设的会话在下次对话时按默认处理
+func (s *PromptPresetServiceImpl) DeletePreset(ctx context.Context, presetID string, global bool) error {
+	if _, err := s.getManageablePreset(ctx, presetID, global); err != nil {
+		return err
+	}
+	if err := s.presetRepo.DeletePreset(ctx, presetID); err != nil {
+		zlog.CtxErrorf(ctx, "delete prompt preset failed: %v", err)
+		return ErrInternalError
+	}
+	zlog.CtxInfof(ctx, "prompt preset %s deleted", presetID)
+	return nil
+}
+
+// ListPresets 当前用户可用的预设
+func (s *PromptPresetServiceImpl) ListPresets(ctx context.Context) ([]*entity.PromptPreset, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+	presets, err := s.presetRepo.ListPresets(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list prompt presets failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return presets, nil
+}
+
+// GetUsablePreset 查询当前用户可用的预设，其他用户的预设视为不存在
+func (s *PromptPresetServiceImpl) GetUsablePreset(ctx context.Context, presetID string) (*entity.PromptPreset, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+	preset, err := s.findPreset(ctx, presetID)
+	if err != nil {
+		return nil, err
+	}
+	if !preset.UsableBy(user.UserID) {
+		return nil, ErrPresetNotFound
+	}
+	return preset, nil
+}
+
+// getManageablePreset 查询当前用户可以修改的预设：管理接口只能操作全局预设，用户接口只能操作自己的预设
+func (s *PromptPresetServiceImpl) getManageablePreset(ctx context.Context, presetID string, global bool) (*entity.PromptPreset, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+	preset, err := s.findPreset(ctx, presetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if global {
+		if user.Role != entity.UserRoleAdmin {
+			return nil, ErrPermissionDenied
+		}
+		if !preset.IsGlobal() {
+			return nil, ErrPresetNotFound
+		}
+		return preset, nil
+	}
+	if preset.OwnerID != user.UserID {
+		// 全局预设与其他用户的预设都不能通过用户接口修改
+		if preset.IsGlobal() {
+			return nil, ErrPermissionDenied
+		}
+		return nil, ErrPresetNotFound
+	}
+	return preset, nil
+}
+
+func (s *PromptPresetServiceImpl) findPreset(ctx context.Context, presetID string) (*entity.PromptPreset, error) {
+	if presetID == "" {
+		return nil, ErrPresetNotFound
+	}
+	preset, err := s.presetRepo.GetPreset(ctx, presetID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get prompt preset failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if preset == nil {
+		return nil, ErrPresetNotFound
+	}
+	return preset, nil
+}
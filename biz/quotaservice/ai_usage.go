@@ -0,0 +1,101 @@
+package quotaservice
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// CheckAITokenQuota 校验当前用户今日、本月的AI token 用量
+// 用量在调用结束后才知道，单次调用可能超出上限少许，超出后的调用被拒绝；统计失败时放行
+func (s *QuotaServiceImpl) CheckAITokenQuota(ctx context.Context) error {
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if limits.AITokensPerDay <= 0 && limits.AITokensPerMonth <= 0 {
+		return nil
+	}
+
+	today, thisMonth, err := s.sumAITokenUsage(ctx, user.UserID, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to sum ai token usage: %v", err)
+		return nil
+	}
+	if limits.AITokensPerDay > 0 && today.Total() >= limits.AITokensPerDay {
+		zlog.CtxWarnf(ctx, "ai daily token quota exceeded, userID: %s, used: %d", user.UserID, today.Total())
+		return ErrAITokenQuotaExceeded
+	}
+	if limits.AITokensPerMonth > 0 && thisMonth.Total() >= limits.AITokensPerMonth {
+		zlog.CtxWarnf(ctx, "ai monthly token quota exceeded, userID: %s, used: %d", user.UserID, thisMonth.Total())
+		return ErrAITokenQuotaExceeded
+	}
+	return nil
+}
+
+// RecordAITokenUsage 记录一次AI调用的用量，记录失败只打日志，不影响已完成的调用
+func (s *QuotaServiceImpl) RecordAITokenUsage(ctx context.Context, scene, provider, model string, usage entity.TokenUsage) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return
+	}
+	recordID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate ai usage record id: %v", err)
+		return
+	}
+
+	// 流式对话结束时客户端可能已断开，记录用量不能因此失败
+	if err := s.aiUsageRepo.CreateUsageRecord(context.WithoutCancel(ctx), &entity.AiUsageRecord{
+		RecordID:  recordID,
+		UserID:    user.UserID,
+		Scene:     scene,
+		Provider:  provider,
+		Model:     model,
+		Usage:     usage,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "failed to record ai token usage: %v", err)
+	}
+}
+
+// GetAITokenUsage 获取当前用户今日、本月的AI token 用量与套餐上限
+func (s *QuotaServiceImpl) GetAITokenUsage(ctx context.Context) (*types.AITokenUsage, error) {
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	today, thisMonth, err := s.sumAITokenUsage(ctx, user.UserID, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to sum ai token usage: %v", err)
+		return nil, ErrInternalError
+	}
+	return &types.AITokenUsage{
+		Today:            today,
+		ThisMonth:        thisMonth,
+		AITokensPerDay:   limits.AITokensPerDay,
+		AITokensPerMonth: limits.AITokensPerMonth,
+	}, nil
+}
+
+// sumAITokenUsage 统计用户今日、本月（按服务器时区的自然日、自然月）的用量
+func (s *QuotaServiceImpl) sumAITokenUsage(ctx context.Context, userID string, now time.Time) (entity.TokenUsage, entity.TokenUsage, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	today, err := s.aiUsageRepo.SumUsage(ctx, userID, dayStart)
+	if err != nil {
+		return entity.TokenUsage{}, entity.TokenUsage{}, err
+	}
+	thisMonth, err := s.aiUsageRepo.SumUsage(ctx, userID, monthStart)
+	if err != nil {
+		return entity.TokenUsage{}, entity.TokenUsage{}, err
+	}
+	return today, thisMonth, nil
+}
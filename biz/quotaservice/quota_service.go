@@ -0,0 +1,485 @@
+package quotaservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 错误定义
+var (
+	ErrInvalidPlan          = errors.New("无效的套餐")
+	ErrPermissionDenied     = errors.New("权限不足")
+	ErrInternalError        = errors.New("内部错误")
+	ErrUserNotFound         = errors.New("用户不存在")
+	ErrMindMapQuotaExceeded = errors.New("导图数量已达套餐上限")
+	ErrMindMapNodesExceeded = errors.New("导图节点数量已达套餐上限")
+	ErrInvalidQuota         = errors.New("配额不能为负数")
+	ErrStorageQuotaExceeded = errors.New("存储空间已达套餐上限")
+	ErrAIQuotaExceeded      = errors.New("今日AI调用次数已达套餐上限")
+	ErrAIRateLimited        = errors.New("AI调用过于频繁，请稍后再试")
+	ErrAIConcurrencyLimited = errors.New("进行中的AI生成任务过多")
+	ErrAITokenQuotaExceeded = errors.New("AI token 用量已达套餐上限")
+)
+
+// defaultPlans 未在配置文件中声明的套餐使用的默认配额
+var defaultPlans = map[string]entity.PlanLimits{
+	entity.PlanFree: {
+		MaxMindMaps:         50,
+		MaxNodesPerMap:      500,
+		MaxStorageBytes:     100 * 1024 * 1024,
+		AIRequestsPerDay:    50,
+		AIRequestsPerMinute: 5,
+		AIConcurrency:       1,
+		AITokensPerDay:      200000,
+		AITokensPerMonth:    2000000,
+	},
+	entity.PlanPro: {
+		MaxMindMaps:         0,
+		MaxNodesPerMap:      5000,
+		MaxStorageBytes:     10 * 1024 * 1024 * 1024,
+		AIRequestsPerDay:    1000,
+		AIRequestsPerMinute: 30,
+		AIConcurrency:       3,
+		AITokensPerDay:      5000000,
+		AITokensPerMonth:    50000000,
+	},
+}
+
+const (
+	aiSlotTTL          = 5 * time.Minute        // 单个AI生成名额的最长占用时间，需大于AI调用超时
+	aiSlotWaitTimeout  = 5 * time.Second        // 名额已满时最多排队等待的时间
+	aiSlotPollInterval = 200 * time.Millisecond // 排队时的重试间隔
+)
+
+// QuotaServiceImpl 套餐配额服务实现
+type QuotaServiceImpl struct {
+	userRepo    repo.UserRepo
+	mindMapRepo repo.IMindMapRepo
+	fileRepo    repo.FileRepo
+	aiUsageRepo repo.AiUsageRepo
+	quotaRepo   repo.UserQuotaRepo
+	plans       map[string]entity.PlanLimits
+}
+
+func NewQuotaServiceImpl(userRepo repo.UserRepo, mindMapRepo repo.IMindMapRepo, fileRepo repo.FileRepo, aiUsageRepo repo.AiUsageRepo,
+	quotaRepo repo.UserQuotaRepo, planConfigs map[string]configs.PlanConfig) *QuotaServiceImpl {
+	plans := make(map[string]entity.PlanLimits, len(defaultPlans)+len(planConfigs))
+	for name, limits := range defaultPlans {
+		plans[name] = limits
+	}
+	// 配置文件中的套餐逐项覆盖默认值，也可以声明新的套餐
+	for name, cfg := range planConfigs {
+		plans[name] = applyPlanConfig(defaultPlans[name], cfg)
+	}
+
+	return &QuotaServiceImpl{
+		userRepo:    userRepo,
+		mindMapRepo: mindMapRepo,
+		fileRepo:    fileRepo,
+		aiUsageRepo: aiUsageRepo,
+		quotaRepo:   quotaRepo,
+		plans:       plans,
+	}
+}
+
+// applyPlanConfig 用配置覆盖套餐配额：大于 0 的项覆盖默认值，-1 表示不限制，未配置（0）的项保留默认值
+// 只配置部分项时其余项不会变为不限制
+func applyPlanConfig(limits entity.PlanLimits, cfg configs.PlanConfig) entity.PlanLimits {
+	override := func(dst *int64, value, unit int64) {
+		switch {
+		case value > 0:
+			*dst = value * unit
+		case value < 0:
+			*dst = 0
+		}
+	}
+	override(&limits.MaxMindMaps, cfg.MaxMindMaps, 1)
+	override(&limits.MaxNodesPerMap, cfg.MaxNodesPerMap, 1)
+	override(&limits.MaxStorageBytes, cfg.MaxStorageMB, 1024*1024)
+	override(&limits.AIRequestsPerDay, cfg.AIRequestsPerDay, 1)
+	override(&limits.AIRequestsPerMinute, cfg.AIRequestsPerMinute, 1)
+	override(&limits.AIConcurrency, cfg.AIConcurrency, 1)
+	override(&limits.AITokensPerDay, cfg.AITokensPerDay, 1)
+	override(&limits.AITokensPerMonth, cfg.AITokensPerMonth, 1)
+	return limits
+}
+
+// GetPlanLimits 获取套餐配额
+func (s *QuotaServiceImpl) GetPlanLimits(plan string) (entity.PlanLimits, error) {
+	limits, ok := s.plans[plan]
+	if !ok {
+		return entity.PlanLimits{}, ErrInvalidPlan
+	}
+	return limits, nil
+}
+
+// userLimits 获取当前用户及其配额
+func (s *QuotaServiceImpl) userLimits(ctx context.Context) (*entity.User, entity.PlanLimits, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, entity.PlanLimits{}, ErrPermissionDenied
+	}
+
+	limits, _, err := s.limitsOf(ctx, user)
+	if err != nil {
+		return nil, entity.PlanLimits{}, err
+	}
+	return user, limits, nil
+}
+
+// limitsOf 用户的套餐配额叠加管理员单独设置的配额，套餐已下线时按免费版处理
+func (s *QuotaServiceImpl) limitsOf(ctx context.Context, user *entity.User) (entity.PlanLimits, *entity.UserQuotaOverride, error) {
+	limits, err := s.GetPlanLimits(user.GetPlan())
+	if err != nil {
+		zlog.CtxWarnf(ctx, "unknown plan %s for user %s, fallback to free", user.GetPlan(), user.UserID)
+		limits = s.plans[entity.PlanFree]
+	}
+
+	override, err := s.quotaRepo.GetUserQuota(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get user quota: %v", err)
+		return entity.PlanLimits{}, nil, ErrInternalError
+	}
+	return override.Apply(limits), override, nil
+}
+
+// getUser 按ID查询用户，用于管理接口与按导图所有者校验配额
+func (s *QuotaServiceImpl) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	if userID == "" {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.GetUser(ctx, repo.NewUserQueryByID(userID))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get user: %v", err)
+		return nil, ErrInternalError
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// CheckMindMapQuota 校验当前用户是否还能创建导图
+func (s *QuotaServiceImpl) CheckMindMapQuota(ctx context.Context) error {
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if limits.MaxMindMaps <= 0 {
+		return nil
+	}
+
+	count, err := s.countMindMaps(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+	if count >= limits.MaxMindMaps {
+		zlog.CtxWarnf(ctx, "mindmap quota exceeded, userID: %s, count: %d, max: %d", user.UserID, count, limits.MaxMindMaps)
+		return ErrMindMapQuotaExceeded
+	}
+	return nil
+}
+
+// CheckMindMapNodeQuota 校验导图节点数是否超出所有者的配额，协作者编辑时同样按所有者计算
+func (s *QuotaServiceImpl) CheckMindMapNodeQuota(ctx context.Context, ownerID string, nodeCount int64) error {
+	owner, ok := entity.GetUser(ctx)
+	if !ok || owner.UserID != ownerID {
+		var err error
+		if owner, err = s.getUser(ctx, ownerID); err != nil {
+			return err
+		}
+	}
+	limits, _, err := s.limitsOf(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if limits.MaxNodesPerMap > 0 && nodeCount > limits.MaxNodesPerMap {
+		zlog.CtxWarnf(ctx, "mindmap node quota exceeded, ownerID: %s, count: %d, max: %d", ownerID, nodeCount, limits.MaxNodesPerMap)
+		return ErrMindMapNodesExceeded
+	}
+	return nil
+}
+
+// CheckStorageQuota 校验当前用户是否还能上传 size 字节的文件
+func (s *QuotaServiceImpl) CheckStorageQuota(ctx context.Context, size int64) error {
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if limits.MaxStorageBytes <= 0 {
+		return nil
+	}
+
+	used, err := s.fileRepo.SumFileSize(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to sum file size: %v", err)
+		return ErrInternalError
+	}
+	if used+size > limits.MaxStorageBytes {
+		zlog.CtxWarnf(ctx, "storage quota exceeded, userID: %s, used: %d, size: %d, max: %d", user.UserID, used, size, limits.MaxStorageBytes)
+		return ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+// ConsumeAIQuota 消耗一次AI调用次数，返回的 refund 用于AI调用失败时退还本次消耗的次数
+// 无法计数时拒绝调用，避免 Redis 故障期间超出套餐的次数限制
+func (s *QuotaServiceImpl) ConsumeAIQuota(ctx context.Context) (func(), error) {
+	noop := func() {}
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return noop, err
+	}
+	now := time.Now()
+
+	// 已计数的键，退还或超出限制时逐个减回
+	var counted []string
+	refund := func() {
+		// 请求 ctx 可能已经结束，退还次数不能因此失败
+		for _, key := range counted {
+			if err := cache.DecrRedis(context.WithoutCancel(ctx), key); err != nil {
+				zlog.CtxErrorf(ctx, "failed to refund ai usage, key: %s, error: %v", key, err)
+			}
+		}
+	}
+
+	if limits.AIRequestsPerMinute > 0 {
+		key := fmt.Sprintf(constant.REDIS_AI_MINUTE_USAGE_KEY, user.UserID, now.Format("200601021504"))
+		count, err := cache.IncrRedis(ctx, key, time.Minute)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to incr ai minute usage: %v", err)
+			return noop, ErrInternalError
+		}
+		if count > limits.AIRequestsPerMinute {
+			zlog.CtxWarnf(ctx, "ai rate limited, userID: %s, count: %d", user.UserID, count)
+			return noop, ErrAIRateLimited
+		}
+		counted = append(counted, key)
+	}
+
+	if limits.AIRequestsPerDay > 0 {
+		key := fmt.Sprintf(constant.REDIS_AI_DAILY_USAGE_KEY, user.UserID, now.Format("20060102"))
+		count, err := cache.IncrRedis(ctx, key, 24*time.Hour)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to incr ai daily usage: %v", err)
+			refund()
+			return noop, ErrInternalError
+		}
+		if count > limits.AIRequestsPerDay {
+			zlog.CtxWarnf(ctx, "ai daily quota exceeded, userID: %s, count: %d", user.UserID, count)
+			// 本次没有调用，每分钟的次数一并退还
+			refund()
+			return noop, ErrAIQuotaExceeded
+		}
+		counted = append(counted, key)
+	}
+	return refund, nil
+}
+
+// AcquireAISlot 占用一个AI生成并发名额
+// 名额满时每隔 aiSlotPollInterval 重试，最多排队 aiSlotWaitTimeout；
+// 名额带有过期时间，进程崩溃未释放的名额会在 aiSlotTTL 后自动回收；
+// Redis 不可用时拒绝调用，避免并发不受限制
+func (s *QuotaServiceImpl) AcquireAISlot(ctx context.Context) (func(), error) {
+	noop := func() {}
+	user, limits, err := s.userLimits(ctx)
+	if err != nil {
+		return noop, err
+	}
+	if limits.AIConcurrency <= 0 {
+		return noop, nil
+	}
+
+	key := fmt.Sprintf(constant.REDIS_AI_INFLIGHT_KEY, user.UserID)
+	slotID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate ai slot id: %v", err)
+		return noop, ErrInternalError
+	}
+
+	deadline := time.Now().Add(aiSlotWaitTimeout)
+	for {
+		ok, err := cache.AcquireSemaphore(ctx, key, slotID, limits.AIConcurrency, aiSlotTTL)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to acquire ai slot: %v", err)
+			return noop, ErrInternalError
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			zlog.CtxWarnf(ctx, "ai concurrency limited, userID: %s, max: %d", user.UserID, limits.AIConcurrency)
+			return noop, ErrAIConcurrencyLimited
+		}
+		select {
+		case <-ctx.Done():
+			return noop, ErrAIConcurrencyLimited
+		case <-time.After(aiSlotPollInterval):
+		}
+	}
+
+	release := func() {
+		// 请求 ctx 可能已经结束，释放名额不能因此失败
+		if err := cache.ReleaseSemaphore(context.WithoutCancel(ctx), key, slotID); err != nil {
+			zlog.CtxErrorf(ctx, "failed to release ai slot: %v", err)
+		}
+	}
+	return release, nil
+}
+
+// GetPlanUsage 获取当前用户的套餐与用量
+func (s *QuotaServiceImpl) GetPlanUsage(ctx context.Context) (*types.PlanUsage, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+	return s.planUsage(ctx, user)
+}
+
+// GetUserPlanUsage 获取指定用户的套餐、用量与单独设置的配额（管理接口）
+func (s *QuotaServiceImpl) GetUserPlanUsage(ctx context.Context, userID string) (*types.PlanUsage, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.planUsage(ctx, user)
+}
+
+func (s *QuotaServiceImpl) planUsage(ctx context.Context, user *entity.User) (*types.PlanUsage, error) {
+	limits, override, err := s.limitsOf(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	mindMapCount, err := s.countMindMaps(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBytes, err := s.fileRepo.SumFileSize(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to sum file size: %v", err)
+		return nil, ErrInternalError
+	}
+
+	var aiRequestsToday int64
+	key := fmt.Sprintf(constant.REDIS_AI_DAILY_USAGE_KEY, user.UserID, time.Now().Format("20060102"))
+	if value, err := cache.GetRedis(ctx, key); err != nil {
+		zlog.CtxErrorf(ctx, "failed to get ai daily usage: %v", err)
+	} else if value != "" {
+		aiRequestsToday, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	var planExpiresAt *time.Time
+	if user.GetPlan() != entity.PlanFree {
+		planExpiresAt = user.PlanExpiresAt
+	}
+
+	return &types.PlanUsage{
+		Plan:            user.GetPlan(),
+		PlanExpiresAt:   planExpiresAt,
+		Limits:          limits,
+		Override:        override,
+		MindMapCount:    mindMapCount,
+		StorageBytes:    storageBytes,
+		AIRequestsToday: aiRequestsToday,
+	}, nil
+}
+
+// SetUserPlan 修改用户套餐
+func (s *QuotaServiceImpl) SetUserPlan(ctx context.Context, userID, plan string) error {
+	if userID == "" {
+		return ErrUserNotFound
+	}
+	if _, ok := s.plans[plan]; !ok {
+		zlog.CtxWarnf(ctx, "invalid plan: %s", plan)
+		return ErrInvalidPlan
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 管理员设置的套餐永久有效，清除付费套餐的到期时间
+	noExpiry := time.Time{}
+	if err := s.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{UserID: userID, Plan: &plan, PlanExpiresAt: &noExpiry}); err != nil {
+		zlog.CtxErrorf(ctx, "failed to update user plan: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "user plan updated, userID: %s, plan: %s -> %s", userID, user.GetPlan(), plan)
+	return nil
+}
+
+// SetUserQuota 为用户单独设置导图数量、节点数量与存储空间配额，均为空时恢复为套餐配额
+func (s *QuotaServiceImpl) SetUserQuota(ctx context.Context, userID string, maxMindMaps, maxNodesPerMap, maxStorageBytes *int64) error {
+	for _, value := range []*int64{maxMindMaps, maxNodesPerMap, maxStorageBytes} {
+		if value != nil && *value < 0 {
+			return ErrInvalidQuota
+		}
+	}
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	override := &entity.UserQuotaOverride{
+		UserID:          userID,
+		MaxMindMaps:     maxMindMaps,
+		MaxNodesPerMap:  maxNodesPerMap,
+		MaxStorageBytes: maxStorageBytes,
+		UpdatedAt:       time.Now(),
+	}
+	if admin, ok := entity.GetUser(ctx); ok {
+		override.UpdatedBy = admin.UserID
+	}
+
+	if override.Empty() {
+		if err := s.quotaRepo.DeleteUserQuota(ctx, userID); err != nil {
+			zlog.CtxErrorf(ctx, "failed to delete user quota: %v", err)
+			return ErrInternalError
+		}
+		zlog.CtxInfof(ctx, "user quota reset to plan, userID: %s", userID)
+		return nil
+	}
+	if err := s.quotaRepo.SaveUserQuota(ctx, override); err != nil {
+		zlog.CtxErrorf(ctx, "failed to save user quota: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "user quota updated, userID: %s, maxMindMaps: %s, maxNodesPerMap: %s, maxStorageBytes: %s",
+		userID, formatQuota(maxMindMaps), formatQuota(maxNodesPerMap), formatQuota(maxStorageBytes))
+	return nil
+}
+
+// formatQuota 日志中区分未设置与具体数值
+func formatQuota(value *int64) string {
+	if value == nil {
+		return "plan"
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+// countMindMaps 统计用户已创建的导图数量
+func (s *QuotaServiceImpl) countMindMaps(ctx context.Context, userID string) (int64, error) {
+	_, total, err := s.mindMapRepo.ListMindMaps(ctx, repo.NewMindMapQueryForList(userID, 1, 1))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to count mindmaps: %v", err)
+		return 0, ErrInternalError
+	}
+	return total, nil
+}
@@ -0,0 +1,69 @@
+package quotaservice
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/cache/cachetest"
+	"forge/pkg/log/zlog"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	zlog.InitLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// stubQuotaRepo 没有单独设置配额的用户
+type stubQuotaRepo struct {
+	repo.UserQuotaRepo
+}
+
+func (stubQuotaRepo) GetUserQuota(ctx context.Context, userID string) (*entity.UserQuotaOverride, error) {
+	return nil, nil
+}
+
+func newTestQuotaService() (*QuotaServiceImpl, context.Context) {
+	s := NewQuotaServiceImpl(nil, nil, nil, nil, stubQuotaRepo{}, nil)
+	ctx := entity.WithUser(context.Background(), &entity.User{UserID: "u1"})
+	return s, ctx
+}
+
+func TestConsumeAIQuota(t *testing.T) {
+	s, ctx := newTestQuotaService()
+	cachetest.Start(t)
+
+	refund, err := s.ConsumeAIQuota(ctx)
+	if err != nil {
+		t.Fatalf("ConsumeAIQuota() error = %v", err)
+	}
+	if refund == nil {
+		t.Fatal("ConsumeAIQuota() refund = nil")
+	}
+}
+
+func TestConsumeAIQuotaRedisFailure(t *testing.T) {
+	s, ctx := newTestQuotaService()
+	redis := cachetest.Start(t)
+	redis.Fail("INCR", errors.New("ERR injected"))
+
+	// 无法计数时拒绝，不能绕过次数限制
+	if _, err := s.ConsumeAIQuota(ctx); !errors.Is(err, ErrInternalError) {
+		t.Errorf("ConsumeAIQuota() error = %v, want ErrInternalError", err)
+	}
+}
+
+func TestAcquireAISlotRedisFailure(t *testing.T) {
+	s, ctx := newTestQuotaService()
+	redis := cachetest.Start(t)
+	redis.Fail("EVALSHA", errors.New("ERR injected"))
+
+	if _, err := s.AcquireAISlot(ctx); !errors.Is(err, ErrInternalError) {
+		t.Errorf("AcquireAISlot() error = %v, want ErrInternalError", err)
+	}
+}
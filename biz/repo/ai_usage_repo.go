@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// AiUsageRepo AI token 用量仓储接口
+type AiUsageRepo interface {
+	// CreateUsageRecord 记录一次AI调用的用量
+	CreateUsageRecord(ctx context.Context, record *entity.AiUsageRecord) error
+
+	// SumUsage 统计用户在 since 之后的用量
+	SumUsage(ctx context.Context, userID string, since time.Time) (entity.TokenUsage, error)
+
+	// DeleteUserUsage 删除用户的所有用量记录，用于注销
+	DeleteUserUsage(ctx context.Context, userID string) error
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"forge/biz/entity"
 	"forge/biz/types"
+	"time"
 )
 
 type AiChatRepo interface {
 	//获取某个会话
 	GetConversation(ctx context.Context, conversationID, userID string) (*entity.Conversation, error)
 
-	//获取某个导图的所有会话
+	//获取某个导图的所有会话（含已归档的） 置顶的在前 其余按手动调整的顺序 未调整的按最近更新
 	GetMapAllConversation(ctx context.Context, mapID, userID string) ([]*entity.Conversation, error)
 
 	//保存某个会话实体
@@ -22,14 +23,79 @@ type AiChatRepo interface {
 	//更新某个会话的标题
 	UpdateConversationTitle(ctx context.Context, conversation *entity.Conversation) error
 
-	//删除某个会话
+	//更新某个会话使用的对话预设
+	UpdateConversationPreset(ctx context.Context, conversation *entity.Conversation) error
+
+	//归档或取消归档某个会话
+	SetConversationArchived(ctx context.Context, conversationID, userID string, archived bool) error
+
+	//置顶或取消置顶某个会话
+	SetConversationPinned(ctx context.Context, conversationID, userID string, pinned bool) error
+
+	//按 conversationIDs 的顺序调整导图下会话的排列顺序
+	UpdateConversationOrder(ctx context.Context, userID, mapID string, conversationIDs []string) error
+
+	//按关键词搜索用户的会话标题与聊天内容，按最近更新排序分页，返回当前页与总数
+	SearchConversations(ctx context.Context, query ConversationSearchQuery) ([]*entity.Conversation, int64, error)
+
+	//删除某个会话（软删除）
 	DeleteConversation(ctx context.Context, conversationID, userID string) error
+
+	//恢复某个在 deletedAfter 之后被软删除的会话
+	RestoreConversation(ctx context.Context, conversationID, userID string, deletedAfter time.Time) error
+
+	//查询在 deletedBefore 之前被软删除的会话ID
+	ListDeletedConversationIDs(ctx context.Context, deletedBefore time.Time) ([]string, error)
+
+	//彻底删除指定的已软删除会话及其聊天记录
+	PurgeConversations(ctx context.Context, conversationIDs []string) error
+
+	//物理删除用户的所有会话（含已软删除的），用于注销
+	EraseUserConversations(ctx context.Context, userID string) error
+}
+
+// ConversationSearchQuery 会话搜索条件
+type ConversationSearchQuery struct {
+	UserID   string
+	Keyword  string
+	Archived *bool // 为 nil 时不区分是否归档
+	Page     int   // 页码（从1开始）
+	PageSize int   // 每页大小（最大50）
+}
+
+// NewConversationSearchQuery 创建会话搜索条件，修正非法的分页参数
+func NewConversationSearchQuery(userID, keyword string, archived *bool, page, pageSize int) ConversationSearchQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 50 {
+		pageSize = 50
+	}
+	return ConversationSearchQuery{UserID: userID, Keyword: keyword, Archived: archived, Page: page, PageSize: pageSize}
 }
 
 type EinoServer interface {
-	//向ai发送消息
-	SendMessage(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error)
+	//向ai发送消息 modelName按提供方名称或模型名称选择提供方 为空时使用默认提供方
+	//超时或提供方出错时回退到下一个提供方 返回的回复中记录实际使用的提供方与模型
+	//params为本次调用的模型参数 未设置的使用提供方默认值
+	SendMessage(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams) (types.AgentResponse, error)
+
+	//流式向ai发送消息 onDelta依次收到模型输出的文本片段 返回完整的回复
+	//onDelta返回错误时中止调用 已输出文本片段后不再回退
+	StreamMessage(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams, onDelta func(delta string) error) (types.AgentResponse, error)
+
+	//请求选择的模型是否可用 为空表示使用默认提供方
+	SupportModel(modelName string) bool
+
+	//发送图片时使用的提供方 所选提供方支持图片时不变 否则为配置的支持图片的提供方 没有时返回 false
+	VisionModel(modelName string) (string, bool)
 
 	//生成导图
-	GenerateMindMap(ctx context.Context, text, userID string) (string, error)
+	GenerateMindMap(ctx context.Context, text, userID string) (types.GenerateMindMapOutput, error)
+
+	//按导图大纲总结导图 modelName同SendMessage
+	SummarizeMindMap(ctx context.Context, modelName, title, outline string) (types.SummarizeMindMapOutput, error)
 }
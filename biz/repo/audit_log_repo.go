@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// AuditLogRepo 安全事件仓储接口
+type AuditLogRepo interface {
+	// CreateAuditLog 记录一条安全事件
+	CreateAuditLog(ctx context.Context, log *entity.AuditLog) error
+
+	// ListAuditLogs 按条件分页查询安全事件，按发生时间倒序
+	ListAuditLogs(ctx context.Context, query AuditLogQuery) ([]*entity.AuditLog, int64, error)
+
+	// DeleteUserAuditLogs 删除用户的所有安全事件，用于注销
+	DeleteUserAuditLogs(ctx context.Context, userID string) error
+}
+
+// AuditLogQuery 安全事件查询条件，为空的条件不限制
+type AuditLogQuery struct {
+	UserID   string
+	ActorID  string
+	Action   string
+	Result   string
+	Page     int // 页码（从1开始）
+	PageSize int // 每页大小（最大99）
+}
+
+// NewAuditLogQuery 创建安全事件查询条件，修正非法的分页参数
+func NewAuditLogQuery(userID, actorID, action, result string, page, pageSize int) AuditLogQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 99 {
+		pageSize = 99
+	}
+	return AuditLogQuery{UserID: userID, ActorID: actorID, Action: action, Result: result, Page: page, PageSize: pageSize}
+}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"forge/biz/entity"
+	"time"
+)
+
+// BackupRepo 备份记录仓储接口
+type BackupRepo interface {
+	// CreateBackup 记录一次已上传的备份
+	CreateBackup(ctx context.Context, backup *entity.Backup) error
+
+	// GetBackup 获取备份记录，不存在时返回 nil
+	GetBackup(ctx context.Context, backupID string) (*entity.Backup, error)
+
+	// ListBackups 获取用户的备份记录，按创建时间倒序
+	ListBackups(ctx context.Context, userID string) ([]*entity.Backup, error)
+
+	// ListBackupsBefore 获取在 before 之前创建的备份记录，最多 limit 条
+	ListBackupsBefore(ctx context.Context, before time.Time, limit int) ([]*entity.Backup, error)
+
+	// DeleteBackups 删除备份记录
+	DeleteBackups(ctx context.Context, backupIDs []string) error
+}
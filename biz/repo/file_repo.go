@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+	"forge/biz/entity"
+)
+
+// FileRepo 文件元数据仓储接口
+type FileRepo interface {
+	// CreateFile 记录一个已上传的文件
+	CreateFile(ctx context.Context, file *entity.File) error
+
+	// ListFiles 根据查询条件获取文件列表
+	ListFiles(ctx context.Context, query FileQuery) ([]*entity.File, error)
+
+	// ListUserFiles 分页获取用户的文件，按上传时间倒序，purpose 为空时不限用途
+	ListUserFiles(ctx context.Context, userID, purpose string, page, pageSize int) ([]*entity.File, int64, error)
+
+	// GetFile 获取用户的文件，不存在时返回 nil
+	GetFile(ctx context.Context, userID, fileID string) (*entity.File, error)
+
+	// DeleteFiles 删除文件记录
+	DeleteFiles(ctx context.Context, fileIDs []string) error
+
+	// SumFileSize 统计用户文件占用的总空间（字节），隔离的文件没有保存，不计入
+	SumFileSize(ctx context.Context, userID string) (int64, error)
+}
+
+// FileQuery 文件查询条件，至少需要一个条件
+type FileQuery struct {
+	UserID          string   // 文件所有者
+	ConversationID  string   // 关联的会话
+	ConversationIDs []string // 关联的会话（批量）
+	Purpose         string   // 文件用途
+	Status          string   // 文件状态，为空时不限
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// InviteRepo 邀请码与邀请关系仓储接口
+type InviteRepo interface {
+	// CreateInviteCode 保存新生成的邀请码
+	CreateInviteCode(ctx context.Context, code *entity.InviteCode) error
+
+	// GetInviteCode 查询邀请码，不存在时返回 nil
+	GetInviteCode(ctx context.Context, code string) (*entity.InviteCode, error)
+
+	// ListInviteCodes 获取用户生成的邀请码，按生成时间倒序
+	ListInviteCodes(ctx context.Context, userID string) ([]*entity.InviteCode, error)
+
+	// CountInviteCodes 统计用户已生成的邀请码个数
+	CountInviteCodes(ctx context.Context, userID string) (int64, error)
+
+	// UseInviteCode 使用一次邀请码，邀请码不存在、已过期或次数已用完时返回 false
+	// 次数在一条更新语句中判断并扣减，并发注册时不会超用
+	UseInviteCode(ctx context.Context, code string, now time.Time) (bool, error)
+
+	// ReleaseInviteCode 退还一次使用次数，用于使用邀请码后注册失败
+	ReleaseInviteCode(ctx context.Context, code string) error
+
+	// CreateReferral 记录邀请关系
+	CreateReferral(ctx context.Context, referral *entity.Referral) error
+
+	// ListReferrals 获取用户邀请注册的记录，按注册时间倒序
+	ListReferrals(ctx context.Context, inviterID string) ([]*entity.Referral, error)
+
+	// DeleteUserInvites 删除用户生成的邀请码以及作为邀请人或被邀请人的邀请关系，用于注销
+	DeleteUserInvites(ctx context.Context, userID string) error
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+	"forge/biz/entity"
+	"time"
+)
+
+// InvoiceRepo 账单仓储接口
+type InvoiceRepo interface {
+	// CreateInvoice 创建账单
+	CreateInvoice(ctx context.Context, invoice *entity.Invoice) error
+
+	// GetInvoice 根据账单ID获取账单，不存在时返回 nil
+	GetInvoice(ctx context.Context, invoiceID string) (*entity.Invoice, error)
+
+	// ListInvoices 获取用户的账单列表，按创建时间倒序
+	ListInvoices(ctx context.Context, userID string) ([]*entity.Invoice, error)
+
+	// UpdateInvoiceCheckout 记录支付渠道返回的会话信息
+	UpdateInvoiceCheckout(ctx context.Context, invoiceID, sessionID, checkoutURL string) error
+
+	// TransitInvoiceStatus 仅当账单处于 fromStatus 时更新为 toStatus，返回是否更新成功
+	// 用于保证支付回调的幂等性
+	TransitInvoiceStatus(ctx context.Context, invoiceID, fromStatus, toStatus string, paidAt *time.Time) (bool, error)
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// MessageFeedbackRepo 消息评价仓储接口
+type MessageFeedbackRepo interface {
+	// SaveFeedback 保存评价，同一条消息已有评价时覆盖
+	SaveFeedback(ctx context.Context, feedback *entity.MessageFeedback) error
+
+	// DeleteFeedback 撤销用户对一条消息的评价
+	DeleteFeedback(ctx context.Context, conversationID, messageID, userID string) error
+
+	// ListConversationFeedback 用户在会话中的所有评价
+	ListConversationFeedback(ctx context.Context, conversationID, userID string) ([]*entity.MessageFeedback, error)
+
+	// ListFeedback 按条件分页查询评价，按评价时间倒序
+	ListFeedback(ctx context.Context, query MessageFeedbackQuery) ([]*entity.MessageFeedback, int64, error)
+
+	// DeleteUserFeedback 删除用户的所有评价，用于注销
+	DeleteUserFeedback(ctx context.Context, userID string) error
+}
+
+// MessageFeedbackQuery 消息评价查询条件，为空的条件不限制
+type MessageFeedbackQuery struct {
+	Rating   string
+	PresetID string
+	Since    *time.Time // 最后评价时间不早于
+	Until    *time.Time // 最后评价时间早于
+	Page     int        // 页码（从1开始）
+	PageSize int        // 每页大小（最大500）
+}
+
+// NewMessageFeedbackQuery 创建消息评价查询条件，修正非法的分页参数
+func NewMessageFeedbackQuery(rating, presetID string, since, until *time.Time, page, pageSize int) MessageFeedbackQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	if pageSize > 500 {
+		pageSize = 500
+	}
+	return MessageFeedbackQuery{Rating: rating, PresetID: presetID, Since: since, Until: until, Page: page, PageSize: pageSize}
+}
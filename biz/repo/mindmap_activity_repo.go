@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// IMindMapActivityRepo 导图变更记录仓储接口
+type IMindMapActivityRepo interface {
+	// CreateActivities 批量写入一次修改产生的变更记录
+	CreateActivities(ctx context.Context, activities []*entity.MindMapActivity) error
+	// ListActivities 导图在 since 之后的变更记录，since 为零值时不限制，按时间倒序分页
+	ListActivities(ctx context.Context, mapID string, since time.Time, page, pageSize int) ([]*entity.MindMapActivity, int64, error)
+	// DeleteUserActivities 删除用户导图的变更记录以及用户在其他导图上的变更记录，用于注销
+	DeleteUserActivities(ctx context.Context, userID string) error
+}
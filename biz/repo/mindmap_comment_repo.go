@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/entity"
+)
+
+var ErrMindMapCommentNotFound = errors.New("mindmap comment not found")
+
+// MindMapCommentQuery 评论列表查询条件
+type MindMapCommentQuery struct {
+	MapID    string
+	NodeID   string // 为空时查询整个导图的评论
+	Resolved *bool  // 为 nil 时不按是否解决筛选
+	Page     int
+	PageSize int
+}
+
+// IMindMapCommentRepo 导图评论仓储接口
+type IMindMapCommentRepo interface {
+	CreateComment(ctx context.Context, comment *entity.MindMapComment) error
+	// GetComment 评论不存在或不属于该导图时返回 nil
+	GetComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error)
+	// ListComments 按创建时间顺序分页
+	ListComments(ctx context.Context, query MindMapCommentQuery) ([]*entity.MindMapComment, int64, error)
+	CountComments(ctx context.Context, mapID string) (int64, error)
+	// UpdateCommentResolved 修改解决状态，评论不存在时返回 ErrMindMapCommentNotFound
+	UpdateCommentResolved(ctx context.Context, comment *entity.MindMapComment) error
+	// DeleteComment 评论不存在时返回 ErrMindMapCommentNotFound
+	DeleteComment(ctx context.Context, mapID, commentID string) error
+	// DeleteUserComments 删除用户导图上的评论以及用户在其他导图上的评论，用于注销
+	DeleteUserComments(ctx context.Context, userID string) error
+}
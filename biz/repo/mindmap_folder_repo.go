@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// MindMapFolderRoot 列表筛选时表示不在任何文件夹中的导图
+const MindMapFolderRoot = "root"
+
+// IMindMapFolderRepo 导图文件夹仓储接口
+type IMindMapFolderRepo interface {
+	CreateFolder(ctx context.Context, folder *entity.MindMapFolder) error
+	// GetFolder 文件夹不存在或不属于该用户时返回 nil
+	GetFolder(ctx context.Context, userID, folderID string) (*entity.MindMapFolder, error)
+	// ListFolders 用户的所有文件夹，按名称排序
+	ListFolders(ctx context.Context, userID string) ([]*entity.MindMapFolder, error)
+	CountFolders(ctx context.Context, userID string) (int64, error)
+	// UpdateFolder 修改名称与上级文件夹，不存在时返回 ErrMindMapFolderNotFound
+	UpdateFolder(ctx context.Context, folder *entity.MindMapFolder) error
+	// DeleteFolder 删除文件夹，其中的子文件夹与导图移到上级文件夹，不存在时返回 ErrMindMapFolderNotFound
+	DeleteFolder(ctx context.Context, userID, folderID string) error
+	// DeleteUserFolders 删除用户的所有文件夹，用于注销
+	DeleteUserFolders(ctx context.Context, userID string) error
+}
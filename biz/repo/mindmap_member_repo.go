@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// IMindMapMemberRepo 导图协作者仓储接口
+type IMindMapMemberRepo interface {
+	// SaveMember 按导图与用户唯一，已是协作者时更新角色
+	SaveMember(ctx context.Context, member *entity.MindMapMember) error
+	// GetMember 不是协作者时返回 nil
+	GetMember(ctx context.Context, mapID, userID string) (*entity.MindMapMember, error)
+	// ListMembers 导图的协作者，按加入时间排序
+	ListMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error)
+	CountMembers(ctx context.Context, mapID string) (int64, error)
+	// RemoveMember 不是协作者时返回 ErrMindMapMemberNotFound
+	RemoveMember(ctx context.Context, mapID, userID string) error
+	// DeleteUserMembers 删除用户作为协作者以及其导图的所有协作者记录，用于注销
+	DeleteUserMembers(ctx context.Context, userID string) error
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"forge/biz/entity"
+	"time"
 )
 
 // 哨兵错误定义
 var (
-	ErrMindMapNotFound = errors.New("mindmap not found or no permission")
+	ErrMindMapNotFound       = errors.New("mindmap not found or no permission")
+	ErrMindMapShareNotFound  = errors.New("mindmap share not found or no permission")
+	ErrMindMapMemberNotFound = errors.New("mindmap member not found")
+	ErrMindMapFolderNotFound = errors.New("mindmap folder not found or no permission")
+	ErrMindMapTagNotFound    = errors.New("mindmap tag not found")
 )
 
 // IMindMapRepo 思维导图仓储接口
@@ -18,16 +23,40 @@ type IMindMapRepo interface {
 	ListMindMaps(ctx context.Context, query MindMapQuery) ([]*entity.MindMap, int64, error)
 	UpdateMindMap(ctx context.Context, updateInfo *MindMapUpdateInfo) error
 	DeleteMindMap(ctx context.Context, mapID string, userID string) error
+	// SearchMindMaps 按标题与节点文本全文搜索，结果按相关度排序，查询条件中的 Keywords 必填
+	SearchMindMaps(ctx context.Context, query MindMapQuery) ([]*entity.MindMap, int64, error)
+	// CountMindMapTags 按查询条件（不含分页）统计自己的导图中各标签的导图数，只返回数量大于0的标签
+	CountMindMapTags(ctx context.Context, query MindMapQuery) ([]*entity.MindMapTag, error)
+	// EraseUserMindMaps 物理删除用户的所有导图（含已软删除的），用于注销
+	EraseUserMindMaps(ctx context.Context, userID string) error
 }
 
 // MindMapQuery 查询条件
 type MindMapQuery struct {
-	UserID   string // 用户ID（必填）
-	MapID    string // 思维导图ID
-	Title    string // 标题关键词（模糊查询）
-	Layout   string // 布局类型
-	Page     int    // 页码（从1开始）
-	PageSize int    // 每页大小（最大99）
+	UserID   string   // 用户ID（必填）
+	MapID    string   // 思维导图ID
+	Title    string   // 标题关键词（模糊查询）
+	Layout   string   // 布局类型
+	Scope    string   // 列表范围：owned/shared/all，为空时只查询自己的导图
+	FolderID string   // 文件夹筛选，只查询自己在该文件夹中的导图；MindMapFolderRoot 表示不在任何文件夹中
+	Tags     []string // 标签筛选，只查询自己同时带有这些标签的导图
+	Starred  *bool    // 收藏筛选，true 只查询自己收藏的导图，false 只查询未收藏的导图
+	Keywords []string // 全文搜索关键词，标题或节点文本需包含全部关键词，只用于 SearchMindMaps
+	Page     int      // 页码（从1开始）
+	PageSize int      // 每页大小（最大99）
+	// 排序与游标分页，只用于 ListMindMaps；指定 After 时只查询排在该导图之后的导图并忽略 Page
+	SortBy    string // updated_at/created_at/title/starred，为空时按 updated_at
+	Ascending bool
+	After     *MindMapCursor
+}
+
+// MindMapCursor 游标分页的位置：上一页最后一个导图的排序字段值与导图ID
+type MindMapCursor struct {
+	UpdatedAt time.Time
+	CreatedAt time.Time
+	Title     string
+	Starred   bool
+	MapID     string
 }
 
 // MindMapUpdateInfo 更新信息（部分更新）
@@ -38,8 +67,25 @@ type MindMapUpdateInfo struct {
 	Desc   *string             // 描述
 	Layout *string             // 布局
 	Data   *entity.MindMapData // 数据（全量更新）
+	// 所在文件夹，空字符串表示移出文件夹
+	FolderID *string
 }
 
+// 列表范围
+const (
+	MindMapScopeOwned  = "owned"  // 自己的导图
+	MindMapScopeShared = "shared" // 作为协作者的导图（与我共享）
+	MindMapScopeAll    = "all"    // 以上两者
+)
+
+// 列表排序字段
+const (
+	MindMapSortUpdatedAt = "updated_at"
+	MindMapSortCreatedAt = "created_at"
+	MindMapSortTitle     = "title"
+	MindMapSortStarred   = "starred" // 收藏的导图在前，其余按更新时间
+)
+
 // 查询构建函数
 func NewMindMapQueryByUserID(userID string) MindMapQuery {
 	return MindMapQuery{UserID: userID}
@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// IMindMapShareRepo 导图分享链接仓储接口
+type IMindMapShareRepo interface {
+	CreateShare(ctx context.Context, share *entity.MindMapShare) error
+	// GetShare 分享不存在时返回 nil
+	GetShare(ctx context.Context, shareID string) (*entity.MindMapShare, error)
+	// ListMapShares 导图在 now 时仍有效的分享，按创建时间倒序
+	ListMapShares(ctx context.Context, mapID string, now time.Time) ([]*entity.MindMapShare, error)
+	// CountMapShares 导图在 now 时仍有效的分享数
+	CountMapShares(ctx context.Context, mapID string, now time.Time) (int64, error)
+	// DeleteShare 删除用户的分享，分享不存在或不属于该用户时返回 ErrMindMapShareNotFound
+	DeleteShare(ctx context.Context, shareID, mapID, ownerID string) error
+	// DeleteUserShares 删除用户的所有分享，用于注销
+	DeleteUserShares(ctx context.Context, ownerID string) error
+}
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// IMindMapStarRepo 导图收藏仓储接口
+type IMindMapStarRepo interface {
+	// StarMindMap 已收藏时不做修改
+	StarMindMap(ctx context.Context, star *entity.MindMapStar) error
+	// UnstarMindMap 未收藏时不报错
+	UnstarMindMap(ctx context.Context, userID, mapID string) error
+	// ListStarredMapIDs 批量查询用户收藏了其中哪些导图
+	ListStarredMapIDs(ctx context.Context, userID string, mapIDs []string) (map[string]bool, error)
+	// DeleteUserStars 删除用户的收藏以及其他用户对其导图的收藏，用于注销
+	DeleteUserStars(ctx context.Context, userID string) error
+}
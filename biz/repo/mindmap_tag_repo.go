@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// IMindMapTagRepo 导图标签仓储接口
+type IMindMapTagRepo interface {
+	// EnsureTags 创建同一用户的一组标签，已存在的同名标签保留原有ID，返回按名称查询到的全部标签
+	EnsureTags(ctx context.Context, tags []*entity.MindMapTag) ([]*entity.MindMapTag, error)
+	// GetTagByName 标签不存在时返回 nil
+	GetTagByName(ctx context.Context, userID, name string) (*entity.MindMapTag, error)
+	// ListTags 用户的所有标签及其导图数，按名称排序
+	ListTags(ctx context.Context, userID string) ([]*entity.MindMapTag, error)
+	CountTags(ctx context.Context, userID string) (int64, error)
+	// SetMapTags 替换导图的全部标签，并清理不再被任何导图使用的标签
+	SetMapTags(ctx context.Context, userID, mapID string, tagIDs []string) error
+	// RemoveMapTag 移除导图的一个标签，导图没有该标签时返回 ErrMindMapTagNotFound
+	RemoveMapTag(ctx context.Context, userID, mapID, tagID string) error
+	// ListMapTagNames 批量查询导图的标签名称，按导图ID分组
+	ListMapTagNames(ctx context.Context, mapIDs []string) (map[string][]string, error)
+	// DeleteUserTags 删除用户的所有标签与导图标签关系，用于注销
+	DeleteUserTags(ctx context.Context, userID string) error
+}
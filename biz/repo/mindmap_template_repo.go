@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/entity"
+)
+
+var ErrMindMapTemplateNotFound = errors.New("mindmap template not found")
+
+// IMindMapTemplateRepo 导图模板仓储接口
+type IMindMapTemplateRepo interface {
+	CreateTemplate(ctx context.Context, template *entity.MindMapTemplate) error
+	// UpdateTemplate 模板不存在时返回 ErrMindMapTemplateNotFound
+	UpdateTemplate(ctx context.Context, template *entity.MindMapTemplate) error
+	// GetTemplate 模板不存在时返回 nil
+	GetTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error)
+	// ListTemplates 按分类筛选，category 为空时返回全部；按分类、Sort、创建时间排序
+	ListTemplates(ctx context.Context, category string) ([]*entity.MindMapTemplate, error)
+	// ListCategories 所有模板分类，按名称排序
+	ListCategories(ctx context.Context) ([]string, error)
+	// DeleteTemplate 模板不存在时返回 ErrMindMapTemplateNotFound
+	DeleteTemplate(ctx context.Context, templateID string) error
+}
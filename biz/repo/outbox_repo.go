@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// OutboxRepo 事件发件箱仓储接口
+type OutboxRepo interface {
+	// AddEvent 写入一条待投递的事件，需与业务数据在同一个事务中调用（见 TxManager）
+	AddEvent(ctx context.Context, event *entity.OutboxEvent) error
+
+	// ListPendingEvents 按写入顺序获取未投递的事件，包括未到重试时间的，不包括已放弃投递的
+	ListPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error)
+
+	// MarkEventPublished 标记事件已投递
+	MarkEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error
+
+	// MarkEventFailed 记录一次投递失败，nextAttemptAt 之前不再投递
+	MarkEventFailed(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error
+
+	// MarkEventDead 记录最后一次投递失败并放弃投递该事件
+	MarkEventDead(ctx context.Context, id uint64, lastError string, failedAt time.Time) error
+
+	// PurgePublishedEvents 删除投递时间早于 before 的事件，返回删除的条数
+	PurgePublishedEvents(ctx context.Context, before time.Time, limit int) (int64, error)
+}
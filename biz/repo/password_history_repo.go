@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// PasswordHistoryRepo 历史密码仓储接口
+type PasswordHistoryRepo interface {
+	// AddPasswordHistory 记录一次密码设置
+	AddPasswordHistory(ctx context.Context, history *entity.PasswordHistory) error
+
+	// ListPasswordHistory 获取用户最近设置的密码，按设置时间倒序
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]*entity.PasswordHistory, error)
+
+	// PrunePasswordHistory 只保留用户最近 keep 条记录
+	PrunePasswordHistory(ctx context.Context, userID string, keep int) error
+
+	// DeleteUserPasswordHistory 删除用户的所有历史密码，用于注销
+	DeleteUserPasswordHistory(ctx context.Context, userID string) error
+}
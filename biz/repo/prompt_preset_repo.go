@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// PromptPresetRepo 对话预设仓储接口
+type PromptPresetRepo interface {
+	// CreatePreset 保存新预设
+	CreatePreset(ctx context.Context, preset *entity.PromptPreset) error
+
+	// UpdatePreset 更新预设的名称、描述、系统提示词与模型参数
+	UpdatePreset(ctx context.Context, preset *entity.PromptPreset) error
+
+	// GetPreset 查询预设，不存在时返回 nil
+	GetPreset(ctx context.Context, presetID string) (*entity.PromptPreset, error)
+
+	// ListPresets 全局预设与 ownerID 创建的预设，全局预设在前
+	ListPresets(ctx context.Context, ownerID string) ([]*entity.PromptPreset, error)
+
+	// CountUserPresets 用户创建的预设数量
+	CountUserPresets(ctx context.Context, ownerID string) (int64, error)
+
+	// DeletePreset 删除预设
+	DeletePreset(ctx context.Context, presetID string) error
+
+	// DeleteUserPresets 删除用户创建的所有预设，用于注销
+	DeleteUserPresets(ctx context.Context, ownerID string) error
+}
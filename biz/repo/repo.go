@@ -25,6 +25,31 @@ type UserRepo interface {
 	// GetUser 根据查询条件获取用户，支持多种查询方式
 	GetUser(ctx context.Context, query UserQuery) (*entity.User, error)
 
+	// GetUsersByIDs 批量获取用户，不存在或已删除的用户不返回，返回顺序不保证与参数一致
+	GetUsersByIDs(ctx context.Context, userIDs []string) ([]*entity.User, error)
+
+	// DowngradeExpiredPlans 将付费套餐已到期的用户降级为免费版，返回降级的用户数
+	DowngradeExpiredPlans(ctx context.Context, now time.Time) (int64, error)
+
+	// ListUserIDs 按用户ID顺序分批获取用户ID，afterUserID 为空时从头开始
+	ListUserIDs(ctx context.Context, afterUserID string, limit int) ([]string, error)
+
+	// ListReminderCandidates 按用户ID顺序分批获取只绑定了邮箱、未绑定手机号且未退订提醒的正常用户
+	// 只返回从未提醒过或上次提醒早于 sentBefore 的用户
+	ListReminderCandidates(ctx context.Context, afterUserID string, sentBefore time.Time, limit int) ([]*entity.User, error)
+
+	// ListUsers 分页获取用户列表（管理接口），返回列表与总数
+	ListUsers(ctx context.Context, query UserListQuery) ([]*entity.User, int64, error)
+
+	// ListDueDeletions 按用户ID顺序分批获取计划删除时间早于 before 的已申请注销用户，afterUserID 为空时从头开始
+	ListDueDeletions(ctx context.Context, afterUserID string, before time.Time, limit int) ([]*entity.User, error)
+
+	// EraseUser 彻底删除用户记录（不可恢复，调用方需先清理用户的其他数据）
+	EraseUser(ctx context.Context, userID string) error
+
+	// MergeUser 在一个事务内将被合并账号的导图、AI对话迁移到保留账号，更新保留账号的资料，并标记被合并账号已删除
+	MergeUser(ctx context.Context, merge *UserMerge) (*UserMergeResult, error)
+
 	/*  根据第三方登录方式查询 后续可能有更多第三方登录方式
 	GetByThirdParty(ctx context.Context, platform string, id string) (*entity.User, error)
 	*/
@@ -58,6 +83,13 @@ type UserUpdateInfo struct {
 	UserName *string // 用户名
 	Avatar   *string // 头像URL
 
+	// 个人资料
+	Nickname *string    // 昵称
+	Bio      *string    // 个人简介
+	Gender   *string    // 性别
+	Birthday *time.Time // 生日，传入零值表示清除
+	Location *string    // 所在地
+
 	// 联系方式
 	Phone *string // 手机号
 	Email *string // 邮箱
@@ -65,13 +97,29 @@ type UserUpdateInfo struct {
 	Password *string // 密码
 
 	// 状态信息
-	Status        *int  // 用户状态 1:正常 0:禁用
-	PhoneVerified *bool // 手机号是否已验证
-	EmailVerified *bool // 邮箱是否已验证
+	Status        *int    // 用户状态 1:正常 0:禁用
+	Role          *string // 角色 user/admin
+	PhoneVerified *bool   // 手机号是否已验证
+	EmailVerified *bool   // 邮箱是否已验证
+
+	// 禁用
+	SuspendReason  *string    // 禁用原因
+	SuspendedUntil *time.Time // 禁用到期时间，传入零值表示清除
+
+	// 套餐
+	Plan          *string    // 套餐 free/pro
+	PlanExpiresAt *time.Time // 套餐到期时间，传入零值表示清除到期时间（永久有效）
 
 	// 时间信息
 	LastLoginAt *time.Time // 最后登录时间
 
+	// 通知偏好
+	ReminderOptOut *bool      // 是否退订绑定联系方式提醒
+	ReminderSentAt *time.Time // 最近一次发送提醒的时间
+
+	// 注销
+	DeletionScheduledAt *time.Time // 计划彻底删除的时间，传入零值表示撤销注销
+
 	// 第三方登录（暂不开放，后续扩展）
 	/*
 	   WechatOpenID  *string
@@ -81,23 +129,61 @@ type UserUpdateInfo struct {
 	*/
 }
 
+// UserMerge 合并账号
+type UserMerge struct {
+	FromUserID string          // 被合并的账号，合并后标记为已删除
+	Target     *UserUpdateInfo // 保留的账号及需要更新的资料（头像、联系方式），UserID 必填
+}
+
+// UserMergeResult 合并账号迁移的数据条数
+type UserMergeResult struct {
+	MindMaps      int64
+	Conversations int64
+}
+
 // UserQuery 用户查询条件
+// 按用户名、手机号、邮箱查询用于登录与注册查重，总是读主库；按用户ID查询可能读从库
 type UserQuery struct {
 	UserID   string // 根据用户ID查询
 	UserName string // 根据用户名查询
 	Phone    string // 根据手机号查询
 	Email    string // 根据邮箱查询
+	// WithPassword 需要密码哈希（校验密码）时设置，按用户ID查询时不读缓存与从库，缓存中的用户不含密码哈希
+	WithPassword bool
 	// Platform string // 第三方平台
 	// ThirdID  string // 第三方ID
 }
 
+// UserListQuery 用户列表查询条件（管理接口）
+type UserListQuery struct {
+	Keyword  string // 用户名/手机号/邮箱关键词（模糊查询）
+	Status   *int   // 用户状态
+	Role     string // 角色
+	Page     int    // 页码（从1开始）
+	PageSize int    // 每页大小（最大99）
+}
+
+// NewUserListQuery 创建用户列表查询条件，修正非法的分页参数
+func NewUserListQuery(keyword string, status *int, role string, page, pageSize int) UserListQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 99 {
+		pageSize = 99
+	}
+	return UserListQuery{Keyword: keyword, Status: status, Role: role, Page: page, PageSize: pageSize}
+}
+
 // NewUserQueryByID 创建根据用户ID查询的条件
 func NewUserQueryByID(userID string) UserQuery {
 	return UserQuery{UserID: userID}
 }
 
-// NewUserQueryByName 创建根据用户名查询的条件
-func NewUserQueryByName(username string) UserQuery {
+// NewUserQueryByUserName 创建根据用户名查询的条件
+func NewUserQueryByUserName(username string) UserQuery {
 	return UserQuery{UserName: username}
 }
 
@@ -0,0 +1,11 @@
+package repo
+
+import "context"
+
+// TxManager 事务管理，用于一个业务操作需要原子地调用多个仓储的场景，如注册时创建用户并扣减邀请码
+// 仓储接口本身仍不暴露事务，事务通过 ctx 传递给 fn 中调用的仓储方法
+type TxManager interface {
+	// WithinTransaction 在一个事务内执行 fn，fn 返回错误或 panic 时回滚
+	// fn 中需使用传入的 ctx 调用仓储；已在事务中时加入外层事务，由最外层提交或回滚
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/entity"
+)
+
+// ErrIdentityAlreadyBound 第三方账号已被绑定（唯一索引冲突）
+var ErrIdentityAlreadyBound = errors.New("identity already bound")
+
+// UserIdentityRepo 第三方账号绑定关系仓储
+type UserIdentityRepo interface {
+	// CreateIdentity 绑定第三方账号，同一平台账号已被绑定时返回 ErrIdentityAlreadyBound
+	CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error
+
+	// GetIdentity 根据平台与平台内唯一标识获取绑定关系，不存在时返回 nil
+	GetIdentity(ctx context.Context, provider, providerUID string) (*entity.UserIdentity, error)
+
+	// ListIdentities 获取用户绑定的所有第三方账号
+	ListIdentities(ctx context.Context, userID string) ([]*entity.UserIdentity, error)
+
+	// DeleteIdentity 解绑用户在某个平台的账号
+	DeleteIdentity(ctx context.Context, userID, provider string) error
+
+	// DeleteUserIdentities 删除用户绑定的所有第三方账号，用于注销
+	DeleteUserIdentities(ctx context.Context, userID string) error
+}
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// UserQuotaRepo 用户单独配额仓储接口
+type UserQuotaRepo interface {
+	// GetUserQuota 未单独设置时返回 nil
+	GetUserQuota(ctx context.Context, userID string) (*entity.UserQuotaOverride, error)
+
+	// SaveUserQuota 新增或覆盖用户的单独配额
+	SaveUserQuota(ctx context.Context, override *entity.UserQuotaOverride) error
+
+	// DeleteUserQuota 恢复为套餐配额，也用于注销
+	DeleteUserQuota(ctx context.Context, userID string) error
+}
@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+)
+
+// UserSessionRepo 登录会话仓储接口
+type UserSessionRepo interface {
+	// CreateSession 记录一次成功登录
+	CreateSession(ctx context.Context, session *entity.UserSession) error
+
+	// GetSession 获取会话，不存在时返回 nil
+	GetSession(ctx context.Context, sessionID string) (*entity.UserSession, error)
+
+	// ListSessions 获取用户最近的会话，按登录时间倒序
+	ListSessions(ctx context.Context, userID string, limit int) ([]*entity.UserSession, error)
+
+	// ListActiveSessions 获取用户所有未下线且未过期的会话
+	ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]*entity.UserSession, error)
+
+	// RenewSession 续签令牌后更新会话的刷新令牌、活跃时间与过期时间
+	RenewSession(ctx context.Context, sessionID, refreshTokenID string, lastActiveAt, expiresAt time.Time) error
+
+	// RevokeSession 标记会话已下线
+	RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error
+
+	// DeleteUserSessions 删除用户的所有会话记录，用于注销
+	DeleteUserSessions(ctx context.Context, userID string) error
+}
@@ -0,0 +1,9 @@
+package types
+
+import "context"
+
+// IAccountErasureService 彻底删除注销冷静期已到的账号及其数据
+type IAccountErasureService interface {
+	// EraseDueAccounts 删除冷静期已到的账号（导图、会话、文件、备份、头像与用户记录），返回删除的账号数
+	EraseDueAccounts(ctx context.Context) (int, error)
+}
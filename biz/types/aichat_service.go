@@ -11,62 +11,220 @@ type IAiChatService interface {
 	//处理用户消息
 	ProcessUserMessage(ctx context.Context, req *ProcessUserMessageParams) (AgentResponse, error)
 
+	//流式处理用户消息 模型输出的文本片段依次交给onDelta 结束后保存完整的消息
+	ProcessUserMessageStream(ctx context.Context, req *ProcessUserMessageParams, onDelta func(delta string) error) (AgentResponse, error)
+
 	//保存新的会话
 	SaveNewConversation(ctx context.Context, req *SaveNewConversationParams) (string, error)
 
 	//获取该导图的所有会话
 	GetConversationList(ctx context.Context, req *GetConversationListParams) ([]*entity.Conversation, error)
 
-	//删除某会话
+	//删除某会话（软删除）
 	DelConversation(ctx context.Context, req *DelConversationParams) error
 
+	//恢复某个已删除的会话
+	RestoreConversation(ctx context.Context, req *RestoreConversationParams) error
+
+	//查询超过恢复期限、待彻底删除的会话ID
+	ListPurgeableConversations(ctx context.Context) ([]string, error)
+
+	//彻底删除会话及其聊天记录，需先清理会话关联的文件
+	PurgeConversations(ctx context.Context, conversationIDs []string) error
+
 	//获取某会话的详细信息
 	GetConversation(ctx context.Context, req *GetConversationParams) (*entity.Conversation, error)
 
+	//按游标分页获取某会话的聊天记录
+	GetConversationMessages(ctx context.Context, req *GetConversationMessagesParams) (*ConversationMessages, error)
+
+	//把某会话截至某条消息的聊天记录复制为新会话 返回新会话ID
+	ForkConversation(ctx context.Context, req *ForkConversationParams) (string, error)
+
 	//更新某会话的标题
 	UpdateConversationTitle(ctx context.Context, req *UpdateConversationTitleParams) error
 
+	//设置某会话使用的对话预设 为空时恢复默认
+	SetConversationPreset(ctx context.Context, req *SetConversationPresetParams) error
+
+	//归档或取消归档某会话 归档的会话不出现在会话列表中
+	ArchiveConversation(ctx context.Context, req *ArchiveConversationParams) error
+
+	//置顶或取消置顶某会话 置顶的会话排在列表最前面
+	PinConversation(ctx context.Context, req *PinConversationParams) error
+
+	//按给定顺序调整导图下会话的排列顺序
+	ReorderConversations(ctx context.Context, req *ReorderConversationsParams) error
+
+	//按关键词搜索当前用户的会话标题与聊天内容
+	SearchConversations(ctx context.Context, req *SearchConversationsParams) (*ConversationSearchResult, error)
+
 	//生成导图
-	GenerateMindMap(ctx context.Context, req *GenerateMindMapParams) (string, error)
+	GenerateMindMap(ctx context.Context, req *GenerateMindMapParams) (*GenerateMindMapResult, error)
+
+	//总结导图 总结作为ai消息保存到关联的会话中
+	SummarizeMindMap(ctx context.Context, req *SummarizeMindMapParams) (*MindMapSummaryResult, error)
 }
 
 type ProcessUserMessageParams struct {
 	ConversationID string
 	Message        string
 	MapData        string
+	Model          string             // 按提供方名称或模型名称选择，为空时使用默认提供方
+	Params         entity.ModelParams // 本次请求指定的模型参数，优先于会话预设与配置的默认值
+	Images         []ImageInput       // 消息附带的图片
+}
+
+// ImageInput 消息附带的图片，Key 与 Data 二选一
+type ImageInput struct {
+	Key  string // 当前用户已上传到对象存储的图片
+	Data string // base64 编码的图片内容，可带 data URL 前缀
 }
 
 type SaveNewConversationParams struct {
-	Title   string
-	MapID   string
-	MapData string
+	Title    string
+	MapID    string
+	MapData  string
+	PresetID string // 可选，会话使用的对话预设
 }
 
 type GetConversationListParams struct {
-	MapID string
+	MapID    string
+	Archived bool // 为 true 时只返回已归档的会话，否则只返回未归档的会话
 }
 
 type DelConversationParams struct {
 	ConversationID string
 }
 
+type RestoreConversationParams struct {
+	ConversationID string
+}
+
 type GetConversationParams struct {
 	ConversationID string
 }
 
+type GetConversationMessagesParams struct {
+	ConversationID  string
+	BeforeMessageID string // 返回该消息之前的消息，为空时从最新的消息开始
+	Limit           int    // 最多返回的条数，0 表示全部
+}
+
+// ConversationMessages 一页聊天记录，按时间正序
+type ConversationMessages struct {
+	Title    string
+	PresetID string
+	Messages []*entity.Message
+	HasMore  bool // 是否还有更早的消息
+}
+
+type ForkConversationParams struct {
+	ConversationID string
+	MessageID      string // 复制到该消息为止（含）
+	Title          string // 新会话标题，为空时使用原标题加“（分支）”
+}
+
+type ArchiveConversationParams struct {
+	ConversationID string
+	Archived       bool // false 表示取消归档
+}
+
+type PinConversationParams struct {
+	ConversationID string
+	Pinned         bool // false 表示取消置顶
+}
+
+type ReorderConversationsParams struct {
+	MapID           string
+	ConversationIDs []string // 调整后的顺序，可以只包含导图下的部分会话
+}
+
+type SearchConversationsParams struct {
+	Keyword  string
+	Archived *bool // 为 nil 时不区分是否归档
+	Page     int
+	PageSize int
+}
+
+// ConversationSearchResult 会话搜索结果
+type ConversationSearchResult struct {
+	List     []*ConversationSearchHit
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+// ConversationSearchHit 匹配的会话，MessageID 与 Snippet 为第一条匹配的消息，只有标题匹配时为空
+type ConversationSearchHit struct {
+	Conversation *entity.Conversation
+	MessageID    string
+	Snippet      string
+}
+
 type UpdateConversationTitleParams struct {
 	ConversationID string
 	Title          string
 }
 
+type SetConversationPresetParams struct {
+	ConversationID string
+	PresetID       string // 为空时恢复默认
+}
+
 type AgentResponse struct {
 	NewMapJson string            `json:"new_map_json"`
 	Content    string            `json:"content"`
 	ToolCallID string            `json:"tool_call_id"`
 	ToolCalls  []schema.ToolCall `json:"tool_calls"`
+	Provider   string            `json:"provider"` // 实际使用的模型提供方
+	Model      string            `json:"model"`
+	Usage      entity.TokenUsage `json:"usage"` // 本轮模型调用的 token 用量
+
+	Operations       []entity.MindMapOperation `json:"operations"`         // ai通过 edit_mind_map 提出的导图操作
+	OperationsCallID string                    `json:"operations_call_id"` // edit_mind_map 的工具调用ID
+	Changes          []entity.MindMapChange    `json:"changes"`            // 已应用到导图的变更
+	EditError        string                    `json:"edit_error"`         // 导图操作未能应用的原因
+}
+
+// GenerateMindMapOutput 生成导图的模型输出
+type GenerateMindMapOutput struct {
+	MapJson  string
+	Provider string
+	Model    string
+	Usage    entity.TokenUsage
+}
+
+// SummarizeMindMapOutput 总结导图的模型输出
+type SummarizeMindMapOutput struct {
+	Summary  entity.MindMapSummary
+	Provider string
+	Model    string
+	Usage    entity.TokenUsage
+}
+
+type SummarizeMindMapParams struct {
+	MapID          string
+	ConversationID string // 总结保存到的会话，为空时新建会话
+	Model          string
+}
+
+// MindMapSummaryResult 导图总结及保存的位置
+type MindMapSummaryResult struct {
+	ConversationID string
+	MessageID      string
+	Summary        entity.MindMapSummary
+	Provider       string
+	Model          string
 }
 
 type GenerateMindMapParams struct {
 	Text string
 	File *multipart.FileHeader
 }
+
+// GenerateMindMapResult 生成导图的结果
+type GenerateMindMapResult struct {
+	MapJson string
+	Cached  bool // 相同输入在缓存有效期内生成过，直接返回缓存的结果
+}
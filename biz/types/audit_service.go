@@ -0,0 +1,37 @@
+package types
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// AuditEvent 一次账号安全事件，操作人、来源IP与 User-Agent 从请求上下文中获取
+type AuditEvent struct {
+	UserID string // 事件所属的用户
+	Action string // entity.AuditAction*
+	Detail string // 补充说明
+	Err    error  // 操作返回的错误，为 nil 表示成功
+}
+
+// ListAuditLogsParams 安全事件查询参数（管理接口）
+type ListAuditLogsParams struct {
+	UserID   string // 事件所属的用户，为空表示不限
+	ActorID  string // 操作人，为空表示不限
+	Action   string // 事件类型，为空表示不限
+	Result   string // success/failure，为空表示不限
+	Page     int
+	PageSize int
+}
+
+// IAuditService 账号安全审计服务，记录登录、重置密码、换绑联系方式、修改头像与管理员操作
+type IAuditService interface {
+	// Record 记录一条安全事件，写入失败只打日志，不影响业务结果
+	Record(ctx context.Context, event *AuditEvent)
+
+	// ListMyAuditLogs 当前用户的安全事件
+	ListMyAuditLogs(ctx context.Context, page, pageSize int) ([]*entity.AuditLog, int64, error)
+
+	// ListAuditLogs 按条件查询安全事件（管理接口）
+	ListAuditLogs(ctx context.Context, params *ListAuditLogsParams) ([]*entity.AuditLog, int64, error)
+}
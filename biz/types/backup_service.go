@@ -0,0 +1,28 @@
+package types
+
+import (
+	"context"
+	"forge/biz/entity"
+)
+
+// IBackupService 用户内容定时备份服务
+type IBackupService interface {
+	// RunBackup 为所有用户导出导图与会话并上传到对象存储，返回成功备份的用户数
+	RunBackup(ctx context.Context) (int, error)
+
+	// PurgeExpiredBackups 清理超过保留期的备份，返回清理的数量
+	PurgeExpiredBackups(ctx context.Context) (int, error)
+
+	// ListBackups 获取用户的备份记录（管理接口）
+	ListBackups(ctx context.Context, userID string) ([]*entity.Backup, error)
+
+	// RestoreBackup 从备份中恢复用户已删除的导图及其会话（管理接口）
+	RestoreBackup(ctx context.Context, backupID string) (*RestoreBackupResult, error)
+}
+
+// RestoreBackupResult 恢复结果
+type RestoreBackupResult struct {
+	RestoredMindMaps      int64 // 重新创建的导图数
+	RestoredConversations int64 // 重新创建的会话数
+	SkippedMindMaps       int64 // 仍然存在而跳过的导图数
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"context"
+	"forge/biz/entity"
+)
+
+type IBillingService interface {
+	// CreateCheckout 为当前用户创建账单与支付会话，返回的账单中包含支付页面地址
+	CreateCheckout(ctx context.Context, req *CreateCheckoutParams) (*entity.Invoice, error)
+
+	// ListInvoices 获取当前用户的账单列表
+	ListInvoices(ctx context.Context) ([]*entity.Invoice, error)
+
+	// HandlePaymentWebhook 处理支付渠道回调：校验签名、更新账单、开通套餐
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error
+
+	// ExpirePlans 将到期的付费套餐降级为免费版，供定时任务调用
+	ExpirePlans(ctx context.Context) (int64, error)
+}
+
+// CreateCheckoutParams 创建支付参数
+type CreateCheckoutParams struct {
+	Product string // 商品标识，对应配置中的 billing.products
+}
@@ -0,0 +1,29 @@
+package types
+
+import "context"
+
+// 需要图形验证码保护的接口
+const (
+	CaptchaRouteLogin    = "login"
+	CaptchaRouteRegister = "register"
+	CaptchaRouteSendCode = "send_code"
+)
+
+// CaptchaChallenge 图形验证码题目
+type CaptchaChallenge struct {
+	CaptchaID string
+	Image     string // PNG 图片的 data URL
+	ExpiresIn int64  // 有效期（秒）
+}
+
+// ICaptchaService 图形验证码服务，未登录接口在同一来源请求过多时要求先完成图形验证码
+type ICaptchaService interface {
+	// NewChallenge 生成图形验证码，答案保存在 Redis 中
+	NewChallenge(ctx context.Context) (*CaptchaChallenge, error)
+
+	// Verify 校验图形验证码，无论是否正确题目都会作废
+	Verify(ctx context.Context, captchaID, answer string) error
+
+	// Required 记录一次请求并判断该接口当前是否需要图形验证码
+	Required(ctx context.Context, route string) bool
+}
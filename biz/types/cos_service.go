@@ -2,6 +2,11 @@ package types
 
 import (
 	"context"
+	"io"
+	"mime/multipart"
+	"time"
+
+	"forge/biz/entity"
 )
 
 type ICOSService interface {
@@ -10,12 +15,82 @@ type ICOSService interface {
 	// UploadAvatar 上传用户头像
 	// 返回: 上传后的完整URL
 	UploadAvatar(ctx context.Context, userID string, fileData []byte, filename string) (string, error)
+
+	// UploadAttachment 上传附件并记录文件元数据
+	UploadAttachment(ctx context.Context, req *UploadAttachmentParams) (*entity.File, error)
+
+	// ListConversationFiles 获取当前用户在会话下的文件
+	ListConversationFiles(ctx context.Context, conversationID string) ([]*entity.File, error)
+
+	// DeleteConversationFiles 删除会话关联的文件，返回文件已全部删除的会话ID，供清理任务调用
+	DeleteConversationFiles(ctx context.Context, conversationIDs []string) ([]string, error)
+
+	// UploadChatImage 保存对话中上传的图片，返回的图片带有内容
+	UploadChatImage(ctx context.Context, conversationID string, data []byte) (*entity.MessageImage, error)
+
+	// LoadChatImage 按 key 读取当前用户上传的图片
+	LoadChatImage(ctx context.Context, key string) (*entity.MessageImage, error)
+
+	// SignDeliveryURL 位于 CDN base_url 下的访问地址附加 CDN 鉴权签名，其他链接原样返回
+	SignDeliveryURL(ctx context.Context, rawURL string) string
+
+	// PresignDownload 为当前用户自己的文件生成有时效的下载链接
+	PresignDownload(ctx context.Context, req *PresignDownloadParams) (*DownloadLink, error)
+
+	// OpenDownload 校验 proxy 模式的下载令牌并打开文件，不需要登录；调用方负责关闭 Body
+	OpenDownload(ctx context.Context, token string) (*DownloadFile, error)
+
+	// ListFiles 分页获取当前用户上传的文件
+	ListFiles(ctx context.Context, req *ListFilesParams) ([]*entity.File, int64, error)
+
+	// DeleteFile 删除当前用户的文件，同时删除存储中的对象
+	DeleteFile(ctx context.Context, fileID string) error
+
+	// ScanUpload 扫描不保存的上传文件（如生成导图的文档），感染时记录隔离文件并返回错误；未开启扫描时直接通过
+	ScanUpload(ctx context.Context, purpose string, file *multipart.FileHeader) error
+}
+
+// ListFilesParams 文件列表参数
+type ListFilesParams struct {
+	Purpose  string // 文件用途，为空时不限
+	Page     int
+	PageSize int
+}
+
+// PresignDownloadParams 生成下载链接参数
+type PresignDownloadParams struct {
+	ResourcePath  string // 资源路径，只能是当前用户目录下的文件
+	ExpireSeconds int64  // 有效期（秒），为 0 时使用配置的默认值
+}
+
+// DownloadLink 有时效的下载链接
+type DownloadLink struct {
+	URL       string
+	Mode      string // presign 为 COS 签名链接，proxy 为经服务端转发的链接
+	ExpiresAt time.Time
+}
+
+// DownloadFile 经服务端转发下载的文件
+type DownloadFile struct {
+	Body          io.ReadCloser
+	ContentType   string
+	ContentLength int64 // 未知时为 -1
+	FileName      string
+}
+
+// UploadAttachmentParams 上传附件参数
+type UploadAttachmentParams struct {
+	ConversationID string // 关联的会话ID（可为空）
+	Purpose        string // 文件用途，默认 attachment
+	Filename       string
+	FileData       []byte
 }
 
 // GetOSSCredentialsParams 获取OSS凭证参数
 type GetOSSCredentialsParams struct {
-	ResourcePath    string // 资源路径，如 user/123/avatar/profile.jpg
-	DurationSeconds int64  // 有效期（秒），范围900-7200（最短15分钟，最长2小时）
+	Purpose         string // 用途：avatar、attachment、export，为空时取资源路径中的用途目录
+	ResourcePath    string // 资源路径，如 user/123/avatar/profile.jpg，末尾可以为 *；为空时为该用途的整个目录
+	DurationSeconds int64  // 有效期（秒），最短900，上限由用途决定（不超过7200）
 }
 
 // OSSCredentials OSS凭证信息
@@ -26,7 +101,9 @@ type OSSCredentials struct {
 	Expiration      int64  // 过期时间（Unix时间戳）
 	Region          string // OSS地域
 	BucketName      string // 存储桶名称
-	Provider        string // 提供商（cos）
+	Provider        string // 提供商（cos、s3、minio、oss）
+	Endpoint        string // 服务地址，s3、minio、oss 直传时使用
 	BaseURL         string // 访问基础URL
 	AccountID       string // 账户ID
+	ResourcePath    string // 凭证允许访问的资源路径
 }
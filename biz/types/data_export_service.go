@@ -0,0 +1,17 @@
+package types
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// IDataExportService 用户导出个人数据
+type IDataExportService interface {
+	// RequestExport 申请导出当前用户的个人资料、导图与AI对话，导出在后台进行
+	// 已有未过期的导出文件或正在导出时直接返回当前进度，不会重复导出
+	RequestExport(ctx context.Context) (*entity.DataExport, error)
+
+	// DeleteUserExport 删除用户的导出文件与导出进度，用于注销
+	DeleteUserExport(ctx context.Context, userID string) error
+}
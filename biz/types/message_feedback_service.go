@@ -0,0 +1,37 @@
+package types
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// IMessageFeedbackService 用户对AI回复的评价（赞/踩）
+type IMessageFeedbackService interface {
+	// SubmitFeedback 评价会话中的一条AI回复，重复评价时覆盖；Rating 为空表示撤销评价
+	SubmitFeedback(ctx context.Context, params *SubmitFeedbackParams) error
+
+	// ListConversationFeedback 当前用户在会话中的评价，按消息ID索引
+	ListConversationFeedback(ctx context.Context, conversationID string) (map[string]*entity.MessageFeedback, error)
+
+	// ExportFeedback 按条件导出评价及评价时的对话快照（管理接口）
+	ExportFeedback(ctx context.Context, params *ExportFeedbackParams) ([]*entity.MessageFeedback, int64, error)
+}
+
+// SubmitFeedbackParams 评价参数
+type SubmitFeedbackParams struct {
+	ConversationID string
+	MessageID      string
+	Rating         string // entity.FeedbackRating*，为空表示撤销
+	Comment        string
+}
+
+// ExportFeedbackParams 导出评价的查询参数，为空的条件不限制
+type ExportFeedbackParams struct {
+	Rating    string
+	PresetID  string
+	StartDate string // 最后评价日期不早于，格式 2006-01-02
+	EndDate   string // 最后评价日期不晚于（含当天），格式同上
+	Page      int
+	PageSize  int
+}
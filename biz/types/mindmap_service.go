@@ -3,30 +3,114 @@ package types
 import (
 	"context"
 	"forge/biz/entity"
+	"mime/multipart"
+	"time"
 )
 
 type IMindMapService interface {
 	CreateMindMap(ctx context.Context, req *CreateMindMapParams) (*entity.MindMap, error)
+	CreateMindMapFromText(ctx context.Context, req *CreateMindMapFromTextParams) (*entity.MindMap, error)
+	ImportMindMap(ctx context.Context, req *ImportMindMapParams) (*entity.MindMap, error)
 	GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error)
-	ListMindMaps(ctx context.Context, req *ListMindMapsParams) ([]*entity.MindMap, int64, error)
+	ListMindMaps(ctx context.Context, req *ListMindMapsParams) (*MindMapList, error)
 	UpdateMindMap(ctx context.Context, mapID string, req *UpdateMindMapParams) error
 	DeleteMindMap(ctx context.Context, mapID string) error
+
+	// 只读分享链接：只有导图所有者可以创建、查看与撤销，访问分享链接不需要登录
+	ShareMindMap(ctx context.Context, mapID string, req *ShareMindMapParams) (*MindMapShareLink, error)
+	ListMindMapShares(ctx context.Context, mapID string) ([]*MindMapShareLink, error)
+	RevokeMindMapShare(ctx context.Context, mapID, shareID string) error
+	GetSharedMindMap(ctx context.Context, token, password string) (*entity.MindMap, *entity.MindMapShare, error)
+
+	// 协作者：所有者添加与移除，协作者可以查看列表与退出
+	AddMindMapMember(ctx context.Context, mapID string, req *AddMindMapMemberParams) (*entity.MindMapMember, error)
+	ListMindMapMembers(ctx context.Context, mapID string) (*entity.MindMap, []*entity.MindMapMember, error)
+	RemoveMindMapMember(ctx context.Context, mapID, userID string) error
+
+	// 文件夹：只整理自己的导图，删除文件夹时其中的子文件夹与导图移到上级
+	CreateMindMapFolder(ctx context.Context, req *CreateMindMapFolderParams) (*entity.MindMapFolder, error)
+	ListMindMapFolders(ctx context.Context) ([]*entity.MindMapFolder, error)
+	UpdateMindMapFolder(ctx context.Context, folderID string, req *UpdateMindMapFolderParams) (*entity.MindMapFolder, error)
+	DeleteMindMapFolder(ctx context.Context, folderID string) error
+	MoveMindMap(ctx context.Context, mapID, folderID string) error
+
+	// 标签：只标记自己的导图，列表可以按标签筛选并统计各标签的导图数
+	SetMindMapTags(ctx context.Context, mapID string, names []string) ([]string, error)
+	RemoveMindMapTag(ctx context.Context, mapID, name string) error
+	ListMindMapTags(ctx context.Context) ([]*entity.MindMapTag, error)
+	CountMindMapTags(ctx context.Context, req *ListMindMapsParams) ([]*entity.MindMapTag, error)
+
+	// 全文搜索：在自己的导图与与我共享的导图中搜索标题与节点文本
+	SearchMindMaps(ctx context.Context, req *SearchMindMapsParams) ([]*MindMapSearchHit, int64, error)
+
+	// 模板：管理员维护，所有用户可以浏览并基于模板创建自己的导图
+	CreateMindMapTemplate(ctx context.Context, req *MindMapTemplateParams) (*entity.MindMapTemplate, error)
+	UpdateMindMapTemplate(ctx context.Context, templateID string, req *MindMapTemplateParams) (*entity.MindMapTemplate, error)
+	DeleteMindMapTemplate(ctx context.Context, templateID string) error
+	ListMindMapTemplates(ctx context.Context, category string) ([]*entity.MindMapTemplate, []string, error)
+	CreateMindMapFromTemplate(ctx context.Context, templateID string, req *CreateMindMapFromTemplateParams) (*entity.MindMap, error)
+
+	// 变更记录：修改、分享与添加协作者时记录，所有者与协作者都可以查看
+	ListMindMapActivities(ctx context.Context, mapID string, req *ListMindMapActivitiesParams) ([]*entity.MindMapActivity, int64, error)
+
+	// 节点评论：所有者与协作者都可以评论；所有者、编辑者与评论者可以解决，所有者与评论者可以删除
+	CreateMindMapComment(ctx context.Context, mapID string, req *CreateMindMapCommentParams) (*entity.MindMapComment, error)
+	ListMindMapComments(ctx context.Context, mapID string, req *ListMindMapCommentsParams) ([]*entity.MindMapComment, int64, error)
+	ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) (*entity.MindMapComment, error)
+	DeleteMindMapComment(ctx context.Context, mapID, commentID string) error
+
+	// 收藏：可以收藏自己的导图与与我共享的导图，列表可以按收藏筛选与排序
+	StarMindMap(ctx context.Context, mapID string) error
+	UnstarMindMap(ctx context.Context, mapID string) error
+
+	// 导出为其他导图软件或图片格式，所有者与协作者都可以导出
+	ExportMindMap(ctx context.Context, mapID string, req *ExportMindMapParams) (*MindMapExport, error)
 }
 
 // 创建参数 - 服务层参数对象，无需json tag
 type CreateMindMapParams struct {
-	Title  string
+	Title    string
+	Desc     string
+	Layout   string
+	Data     entity.MindMapData
+	FolderID string // 创建到指定文件夹，为空表示不放入文件夹
+}
+
+// 纯文本大纲创建参数 - 服务层参数对象，无需json tag
+type CreateMindMapFromTextParams struct {
+	Title  string // 为空时使用大纲根节点文本
 	Desc   string
 	Layout string
-	Data   entity.MindMapData
+	Text   string // 缩进/项目符号形式的纯文本大纲
 }
 
 // 列表查询参数 - 服务层参数对象，无需json tag
 type ListMindMapsParams struct {
 	Title    string
 	Layout   string
+	Scope    string   // owned/shared/all，为空时只查询自己的导图
+	FolderID string   // 只查询自己在该文件夹中的导图，root 表示不在任何文件夹中
+	Tags     []string // 只查询自己同时带有这些标签的导图
+	Starred  *bool    // true 只查询收藏的导图，false 只查询未收藏的导图，nil 不筛选
 	Page     int
 	PageSize int
+	SortBy   string // updated_at/created_at/title/starred，为空时按 updated_at
+	Order    string // asc/desc，为空时标题升序、时间降序
+	Cursor   string // 上一页返回的游标，指定时忽略 Page
+}
+
+// 列表排序方向
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// MindMapList 导图列表的一页，HasMore 时 NextCursor 为下一页的游标
+type MindMapList struct {
+	MindMaps   []*entity.MindMap
+	Total      int64 // 符合筛选条件的导图总数，与分页方式无关
+	NextCursor string
+	HasMore    bool
 }
 
 // 更新参数 - 服务层参数对象，无需json tag
@@ -36,3 +120,115 @@ type UpdateMindMapParams struct {
 	Layout *string
 	Data   *entity.MindMapData
 }
+
+// 分享参数 - 服务层参数对象，无需json tag
+type ShareMindMapParams struct {
+	Password    string // 访问密码，为空表示不需要密码
+	ExpireHours int    // 有效期，0 表示使用默认有效期
+}
+
+// MindMapShareLink 分享记录与访问链接
+type MindMapShareLink struct {
+	Share *entity.MindMapShare
+	Token string
+	URL   string // 未配置分享页地址时为空
+}
+
+// 添加协作者参数 - 服务层参数对象，无需json tag
+type AddMindMapMemberParams struct {
+	Account     string // 协作者的手机号或邮箱
+	AccountType string // 手机号/邮箱
+	Role        string // viewer/editor
+}
+
+// 导出参数 - 服务层参数对象，无需json tag
+type ExportMindMapParams struct {
+	Format string // xmind/mm/opml/md/svg/png
+	AsURL  bool   // 上传到COS并返回临时下载链接，否则直接返回文件内容
+}
+
+// MindMapExport 导出结果：直接下载时 Data 为文件内容，链接方式时 URL 为临时下载链接
+type MindMapExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+	URL         string
+	ExpiresAt   *time.Time
+}
+
+// 导入参数 - 服务层参数对象，无需json tag
+type ImportMindMapParams struct {
+	Title  string // 为空时使用导图根节点文本
+	Desc   string
+	Layout string
+	File   *multipart.FileHeader // xmind/mm/opml/md 文件，按扩展名判断格式
+}
+
+// 创建文件夹参数 - 服务层参数对象，无需json tag
+type CreateMindMapFolderParams struct {
+	Name     string
+	ParentID string // 为空表示顶层
+}
+
+// 修改文件夹参数 - 服务层参数对象，无需json tag
+type UpdateMindMapFolderParams struct {
+	Name     *string
+	ParentID *string // 空字符串表示移到顶层
+}
+
+// 全文搜索参数 - 服务层参数对象，无需json tag
+type SearchMindMapsParams struct {
+	Query    string // 空白分隔的关键词，标题或节点文本需包含全部关键词
+	Page     int
+	PageSize int
+}
+
+// MindMapSearchHit 搜索结果：关键词用 <em> 标出，其余文本已做 HTML 转义
+type MindMapSearchHit struct {
+	MindMap  *entity.MindMap
+	Title    string   // 标题包含关键词时为标出关键词的标题，否则为空
+	Snippets []string // 包含关键词的节点摘要
+}
+
+// 变更记录查询参数 - 服务层参数对象，无需json tag
+type ListMindMapActivitiesParams struct {
+	Since    time.Time // 只查询该时间之后的记录，零值表示不限制
+	Page     int
+	PageSize int
+}
+
+// 创建评论参数 - 服务层参数对象，无需json tag
+type CreateMindMapCommentParams struct {
+	NodeID  string
+	Content string
+}
+
+// 评论列表查询参数 - 服务层参数对象，无需json tag
+type ListMindMapCommentsParams struct {
+	NodeID   string // 为空时查询整个导图的评论
+	Status   string // open/resolved，为空时不筛选
+	Page     int
+	PageSize int
+}
+
+// 评论状态筛选
+const (
+	CommentStatusOpen     = "open"
+	CommentStatusResolved = "resolved"
+)
+
+// 模板参数 - 服务层参数对象，无需json tag
+type MindMapTemplateParams struct {
+	Title    string
+	Desc     string
+	Category string
+	Layout   string
+	Data     entity.MindMapData
+	Sort     int
+}
+
+// 基于模板创建参数 - 服务层参数对象，无需json tag
+type CreateMindMapFromTemplateParams struct {
+	Title    string // 为空时使用模板标题
+	FolderID string // 创建到指定文件夹，为空表示不放入文件夹
+}
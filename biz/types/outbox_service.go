@@ -0,0 +1,17 @@
+package types
+
+import "context"
+
+// IOutboxService 事件发件箱服务：事件与业务数据在同一个事务中写入，由后台任务投递，保证业务提交后事件至少投递一次
+type IOutboxService interface {
+	// Record 记录一条领域事件，payload 按 JSON 编码；需使用 TxManager 事务中的 ctx 调用，事件随事务提交或回滚
+	// 未开启发件箱时不做处理
+	Record(ctx context.Context, topic, key string, payload any) error
+
+	// Relay 按写入顺序投递一批待投递的事件，返回投递成功的条数；投递失败的事件按退避时间重试，重试成功前不投递其后的事件；
+	// 失败次数达到上限的事件放弃投递并告警
+	Relay(ctx context.Context) (int, error)
+
+	// PurgePublishedEvents 删除超过保留期的已投递事件，返回删除的条数
+	PurgePublishedEvents(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"context"
+	"forge/biz/entity"
+)
+
+// IPromptPresetService 对话预设（人设）管理
+type IPromptPresetService interface {
+	// CreatePreset 创建预设，Global 为 true 时创建全局预设（仅管理员）
+	CreatePreset(ctx context.Context, req *PromptPresetParams) (*entity.PromptPreset, error)
+
+	// UpdatePreset 修改预设，全局预设仅管理员可修改，用户预设仅创建者可修改
+	UpdatePreset(ctx context.Context, presetID string, req *PromptPresetParams) (*entity.PromptPreset, error)
+
+	// DeletePreset 删除预设，权限同 UpdatePreset；已使用该预设的会话恢复为默认
+	DeletePreset(ctx context.Context, presetID string, global bool) error
+
+	// ListPresets 当前用户可用的预设：全局预设与自己创建的预设
+	ListPresets(ctx context.Context) ([]*entity.PromptPreset, error)
+
+	// GetUsablePreset 查询当前用户可用的预设，供会话选择预设时校验
+	GetUsablePreset(ctx context.Context, presetID string) (*entity.PromptPreset, error)
+}
+
+// PromptPresetParams 创建、修改预设的参数
+type PromptPresetParams struct {
+	Global       bool // 管理接口操作全局预设
+	Name         string
+	Description  string
+	SystemPrompt string
+	Params       entity.ModelParams
+}
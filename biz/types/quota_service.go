@@ -0,0 +1,72 @@
+package types
+
+import (
+	"context"
+	"forge/biz/entity"
+	"time"
+)
+
+// IQuotaService 套餐与配额服务，导图、AI对话、COS服务在消耗资源前统一调用
+type IQuotaService interface {
+	// GetPlanLimits 获取套餐配额
+	GetPlanLimits(plan string) (entity.PlanLimits, error)
+
+	// CheckMindMapQuota 校验当前用户是否还能创建导图
+	CheckMindMapQuota(ctx context.Context) error
+
+	// CheckMindMapNodeQuota 校验导图节点数是否超出导图所有者的配额
+	CheckMindMapNodeQuota(ctx context.Context, ownerID string, nodeCount int64) error
+
+	// CheckStorageQuota 校验当前用户是否还能上传 size 字节的文件
+	CheckStorageQuota(ctx context.Context, size int64) error
+
+	// ConsumeAIQuota 消耗一次AI调用次数，超出每日次数或每分钟限流时返回错误
+	// AI调用失败时调用返回的 refund 退还本次消耗的次数
+	ConsumeAIQuota(ctx context.Context) (refund func(), err error)
+
+	// AcquireAISlot 占用一个AI生成并发名额，已满时短暂排队，仍无空位返回错误
+	// 成功后必须调用返回的 release 释放名额
+	AcquireAISlot(ctx context.Context) (release func(), err error)
+
+	// CheckAITokenQuota 校验当前用户今日、本月的AI token 用量是否已达套餐上限
+	CheckAITokenQuota(ctx context.Context) error
+
+	// RecordAITokenUsage 记录当前用户一次AI调用的 token 用量
+	RecordAITokenUsage(ctx context.Context, scene, provider, model string, usage entity.TokenUsage)
+
+	// GetAITokenUsage 获取当前用户今日、本月的AI token 用量与套餐上限
+	GetAITokenUsage(ctx context.Context) (*AITokenUsage, error)
+
+	// GetPlanUsage 获取当前用户的套餐与用量
+	GetPlanUsage(ctx context.Context) (*PlanUsage, error)
+
+	// SetUserPlan 修改用户套餐（管理接口）
+	SetUserPlan(ctx context.Context, userID, plan string) error
+
+	// GetUserPlanUsage 获取指定用户的套餐、用量与单独设置的配额（管理接口）
+	GetUserPlanUsage(ctx context.Context, userID string) (*PlanUsage, error)
+
+	// SetUserQuota 为用户单独设置导图数量、节点数量与存储空间配额，为空的字段沿用套餐配额（管理接口）
+	SetUserQuota(ctx context.Context, userID string, maxMindMaps, maxNodesPerMap, maxStorageBytes *int64) error
+}
+
+// PlanUsage 套餐用量
+type PlanUsage struct {
+	Plan            string
+	PlanExpiresAt   *time.Time // 付费套餐到期时间，为空表示永久
+	Limits          entity.PlanLimits
+	MindMapCount    int64 // 已创建的导图数量
+	StorageBytes    int64 // 已占用的存储空间（字节）
+	AIRequestsToday int64 // 今日AI调用次数
+
+	// 管理员单独设置的配额，为空表示未设置；Limits 已叠加该配额
+	Override *entity.UserQuotaOverride
+}
+
+// AITokenUsage AI token 用量
+type AITokenUsage struct {
+	Today            entity.TokenUsage // 今日用量
+	ThisMonth        entity.TokenUsage // 本月用量
+	AITokensPerDay   int64             // 每日上限，0 表示不限制
+	AITokensPerMonth int64             // 每月上限，0 表示不限制
+}
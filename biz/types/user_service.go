@@ -3,10 +3,23 @@ package types
 import (
 	"context"
 	"forge/biz/entity"
+	"time"
 )
 
 type IUserService interface {
-	Login(ctx context.Context, account, accountType, password string) (*entity.User, string, error) // 返回用户、token、错误
+	Login(ctx context.Context, account, accountType, password string) (*entity.User, *AuthTokens, error) // 返回用户、令牌、错误
+
+	// RefreshToken 用刷新令牌换取新的访问令牌与刷新令牌，旧刷新令牌随即失效
+	RefreshToken(ctx context.Context, refreshToken string) (*AuthTokens, error)
+
+	// RevokeRefreshToken 吊销刷新令牌（退出登录）
+	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+
+	// RevokeAccessToken 将访问令牌加入黑名单直到其过期（退出登录）
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// IsAccessTokenRevoked 访问令牌是否已被加入黑名单，或其所属会话已被远程下线
+	IsAccessTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error)
 
 	// Register 基于手机号/邮箱进行注册
 	Register(ctx context.Context, req *RegisterParams) (*entity.User, error)
@@ -19,6 +32,8 @@ type IUserService interface {
 
 	// GetUserByID 根据用户ID获取用户信息（用于JWT鉴权等场景）
 	GetUserByID(ctx context.Context, userID string) (*entity.User, error)
+	// GetUsersByIDs 批量获取用户的公开展示信息（优先读缓存），供导图、AI对话等模块展示所有者，key 为用户ID，不存在的用户不返回
+	GetUsersByIDs(ctx context.Context, userIDs []string) (map[string]*entity.UserBrief, error)
 
 	// SendVerificationCode 发送验证码
 	// purpose: 使用场景，用于决定账号验证逻辑
@@ -30,20 +45,110 @@ type IUserService interface {
 	// UnbindAccount 解绑联系方式（手机号/邮箱）
 	UnbindAccount(ctx context.Context, req *UnbindAccountParams) error
 
+	// UpdateProfile 修改当前用户的个人资料，只更新传入的字段，返回更新后的用户
+	UpdateProfile(ctx context.Context, req *UpdateProfileParams) (*entity.User, error)
+
+	// ListSessions 获取当前用户最近的登录记录
+	ListSessions(ctx context.Context) ([]*entity.UserSession, error)
+
+	// RevokeSession 远程下线当前用户的某个登录会话
+	RevokeSession(ctx context.Context, sessionID string) error
+
+	// ListUsers 分页获取用户列表（管理接口）
+	ListUsers(ctx context.Context, req *ListUsersParams) ([]*entity.User, int64, error)
+
+	// SetUserStatus 禁用/启用用户（管理接口）
+	SetUserStatus(ctx context.Context, userID string, status int) error
+
+	// SuspendUser 禁用用户并下线其所有会话，until 为空表示需手动恢复（管理接口）
+	SuspendUser(ctx context.Context, userID, reason string, until *time.Time) error
+
+	// ReactivateUser 恢复被禁用的用户（管理接口）
+	ReactivateUser(ctx context.Context, userID string) error
+
+	// SetUserRole 修改用户角色（管理接口）
+	SetUserRole(ctx context.Context, userID, role string) error
+
+	// AdminResetPassword 为用户重置密码（管理接口）
+	AdminResetPassword(ctx context.Context, userID, newPassword string) error
+
+	// ListUserSessions 查看用户的登录记录（管理接口）
+	ListUserSessions(ctx context.Context, userID string) ([]*entity.UserSession, error)
+
+	// RequestAccountDeletion 申请注销账号，返回计划彻底删除的时间
+	RequestAccountDeletion(ctx context.Context, req *DeleteAccountParams) (time.Time, error)
+
 	// VerifyCode 验证验证码
 	VerifyCode(ctx context.Context, account, accountType, code string) error
 
 	// UpdateAvatar 更新用户头像
 	UpdateAvatar(ctx context.Context, userID, avatarURL string) error
+
+	// SendContactReminders 提醒只绑定了邮箱的用户绑定手机号，返回发送成功的数量
+	SendContactReminders(ctx context.Context) (int, error)
+
+	// UnsubscribeReminder 通过邮件中的签名链接退订提醒
+	UnsubscribeReminder(ctx context.Context, userID, token string) error
+
+	// SendMagicLink 向已注册的邮箱发送免密登录链接
+	SendMagicLink(ctx context.Context, email string) error
+
+	// LoginWithMagicLink 使用免密登录链接中的一次性令牌登录
+	LoginWithMagicLink(ctx context.Context, token string) (*entity.User, *AuthTokens, error)
+
+	// OAuthAuthorizeURL 生成第三方授权页面地址，purpose 为 login（登录）或 bind（绑定到当前用户）
+	OAuthAuthorizeURL(ctx context.Context, provider, purpose string) (string, error)
+
+	// LoginWithOAuth 第三方登录，未绑定的第三方账号自动注册
+	LoginWithOAuth(ctx context.Context, provider, code, state string) (*entity.User, *AuthTokens, error)
+
+	// BindOAuth 当前用户绑定第三方账号
+	BindOAuth(ctx context.Context, provider, code, state string) error
+
+	// UnbindOAuth 当前用户解绑第三方账号
+	UnbindOAuth(ctx context.Context, provider string) error
+
+	// ListOAuthBindings 当前用户绑定的第三方账号
+	ListOAuthBindings(ctx context.Context) ([]*entity.UserIdentity, error)
+
+	// UpdateNotificationPreference 修改当前用户的通知偏好
+	UpdateNotificationPreference(ctx context.Context, reminderEmail bool) error
+
+	// CreateInviteCode 当前用户生成一个邀请码
+	CreateInviteCode(ctx context.Context) (*entity.InviteCode, error)
+
+	// ListInviteCodes 当前用户生成的邀请码
+	ListInviteCodes(ctx context.Context) ([]*entity.InviteCode, error)
+
+	// ListReferrals 当前用户邀请注册的记录
+	ListReferrals(ctx context.Context) ([]*entity.Referral, error)
+
+	// MergeAccount 将用户另外注册的账号合并到当前账号，需验证两个账号的归属
+	MergeAccount(ctx context.Context, req *MergeAccountParams) (*MergeAccountResult, error)
 }
 
+// AuthTokens 登录/刷新后签发的令牌
+type AuthTokens struct {
+	AccessToken      string
+	RefreshToken     string
+	ExpiresIn        int64 // 访问令牌有效期（秒）
+	RefreshExpiresIn int64 // 刷新令牌有效期（秒）
+}
+
+// 第三方授权用途
+const (
+	OAuthPurposeLogin = "login"
+	OAuthPurposeBind  = "bind"
+)
+
 // 注册参数
 type RegisterParams struct {
 	UserName    string
 	Account     string
-	AccountType string // 手机号/邮箱
-	Code        string
+	AccountType string // 手机号/邮箱/用户名
+	Code        string // 使用用户名注册时无需验证码
 	Password    string
+	InviteCode  string // 邀请码，开启强制邀请注册时必填
 }
 
 // 重置密码参数
@@ -66,17 +171,65 @@ type UpdateAccountParams struct {
 	AccountType string // 手机号/邮箱
 	Code        string // 验证码
 	Password    string // 密码（如果用户没有密码则必填，如果有密码则可选）
+	OldCode     string // 原联系方式收到的确认码，开启换绑确认且已绑定同类联系方式时必填
 }
 
 // 解绑联系方式参数
+// UpdateProfileParams 修改个人资料参数，为 nil 的字段不修改，传入空字符串表示清空
+type UpdateProfileParams struct {
+	Nickname *string
+	Bio      *string
+	Gender   *string
+	Birthday *string // 格式 2006-01-02
+	Location *string
+}
+
+// ListUsersParams 用户列表查询参数（管理接口）
+type ListUsersParams struct {
+	Keyword  string // 用户名/昵称/手机号/邮箱关键词
+	Status   *int   // 用户状态，为空表示不限
+	Role     string // 角色，为空表示不限
+	Page     int
+	PageSize int
+}
+
+// DeleteAccountParams 注销账号参数，密码与验证码二选一
+type DeleteAccountParams struct {
+	Password    string // 登录密码
+	AccountType string // 使用验证码时接收验证码的联系方式类型：手机号/邮箱
+	Code        string // 验证码
+}
+
+// MergeAccountParams 合并账号参数，当前账号与待合并账号都需验证：密码与验证码二选一
+type MergeAccountParams struct {
+	// 当前账号
+	Password    string
+	AccountType string // 使用验证码时接收验证码的联系方式类型：手机号/邮箱
+	Code        string
+
+	// 待合并的账号
+	MergeAccount     string
+	MergeAccountType string // 手机号/邮箱/用户名，用户名只能使用密码验证
+	MergePassword    string
+	MergeCode        string
+}
+
+// MergeAccountResult 合并结果
+type MergeAccountResult struct {
+	MergedUserID  string // 被合并的账号，合并后无法再登录
+	MindMaps      int64  // 迁移的导图数
+	Conversations int64  // 迁移的AI对话数
+}
+
 type UnbindAccountParams struct {
 	Account     string // 需要解绑的手机号/邮箱
 	AccountType string // 手机号/邮箱
 }
 
 const (
-	AccountTypePhone = "phone"
-	AccountTypeEmail = "email"
+	AccountTypePhone    = "phone"
+	AccountTypeEmail    = "email"
+	AccountTypeUsername = "username" // 用户名，只能用于注册与密码登录，不能接收验证码
 )
 
 // 验证码使用场景
@@ -84,4 +237,9 @@ const (
 	PurposeRegister      = "register"       // 注册场景
 	PurposeResetPassword = "reset_password" // 重置密码场景
 	PurposeChangeAccount = "change_account" // 换绑联系方式场景（手机号/邮箱）
+	PurposeDeleteAccount = "delete_account" // 注销账号场景
+	// 换绑确认场景：向当前绑定的手机号/邮箱发送确认码，开启换绑确认时换绑前需要提供
+	PurposeConfirmChangeAccount = "confirm_change_account"
+	// 合并账号场景：向当前账号或待合并账号的手机号/邮箱发送验证码
+	PurposeMergeAccount = "merge_account"
 )
@@ -0,0 +1,118 @@
+package userservice
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// RequestAccountDeletion 申请注销账号：校验密码或验证码后标记为待注销
+// 冷静期内重新登录可撤销，冷静期结束后由定时任务彻底删除用户数据
+func (u *UserServiceImpl) RequestAccountDeletion(ctx context.Context, req *types.DeleteAccountParams) (time.Time, error) {
+	if req == nil || (req.Password == "" && req.Code == "") {
+		zlog.CtxErrorf(ctx, "invalid params for delete account: password or code is required")
+		return time.Time{}, ErrInvalidParams
+	}
+
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for delete account")
+		return time.Time{}, ErrPermissionDenied
+	}
+
+	if err := u.verifyOwnership(ctx, currentUser, req.Password, req.AccountType, req.Code); err != nil {
+		return time.Time{}, err
+	}
+
+	scheduledAt := time.Now().Add(u.deletionConfig.GracePeriod())
+	if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:              currentUser.UserID,
+		DeletionScheduledAt: &scheduledAt,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "schedule account deletion failed: %v", err)
+		return time.Time{}, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "account deletion scheduled for user: %s at %s", currentUser.UserID, scheduledAt.Format(time.RFC3339))
+	return scheduledAt, nil
+}
+
+// verifyOwnership 通过登录密码或验证码确认用户本人操作，有密码时优先校验密码
+// 使用验证码时需先向用户自己的手机号/邮箱发送验证码
+func (u *UserServiceImpl) verifyOwnership(ctx context.Context, user *entity.User, password, accountType, code string) error {
+	if password != "" {
+		// 第三方登录创建的用户没有密码，只能使用验证码
+		if !user.HasPassword() {
+			zlog.CtxWarnf(ctx, "user %s has no password, code required for verify ownership", user.UserID)
+			return ErrCredentialsIncorrect
+		}
+		hash, err := u.passwordHash(ctx, user)
+		if err != nil {
+			return err
+		}
+		match, err := util.ComparePassword(hash, password)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "compare password failed: %v", err)
+			return ErrInternalError
+		}
+		if !match {
+			zlog.CtxWarnf(ctx, "password incorrect for verify ownership, userID: %s", user.UserID)
+			return ErrCredentialsIncorrect
+		}
+	} else {
+		var contact string
+		switch accountType {
+		case types.AccountTypePhone:
+			contact = user.Phone
+		case types.AccountTypeEmail:
+			contact = user.Email
+		default:
+			zlog.CtxErrorf(ctx, "unsupported account type for verify ownership: %s", accountType)
+			return ErrUnsupportedAccountType
+		}
+		if contact == "" {
+			zlog.CtxErrorf(ctx, "%s is not bound, userID: %s", accountType, user.UserID)
+			return ErrInvalidParams
+		}
+		if err := u.VerifyCode(ctx, contact, accountType, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelPendingDeletion 撤销用户的注销申请，未申请注销时不做任何处理
+func (u *UserServiceImpl) cancelPendingDeletion(ctx context.Context, user *entity.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+
+	var cleared time.Time
+	if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:              user.UserID,
+		DeletionScheduledAt: &cleared,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "cancel account deletion failed: %v", err)
+		return ErrInternalError
+	}
+	user.DeletionScheduledAt = nil
+
+	zlog.CtxInfof(ctx, "account deletion cancelled by login for user: %s", user.UserID)
+	return nil
+}
+
+// isOwnContact 判断账号是否为用户自己绑定的手机号/邮箱
+func isOwnContact(user *entity.User, account, accountType string) bool {
+	switch accountType {
+	case types.AccountTypePhone:
+		return user.Phone != "" && user.Phone == account
+	case types.AccountTypeEmail:
+		return user.Email != "" && user.Email == account
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package userservice
+
+import (
+	"context"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// ListUsers 分页获取用户列表（管理接口）
+func (u *UserServiceImpl) ListUsers(ctx context.Context, req *types.ListUsersParams) ([]*entity.User, int64, error) {
+	if req == nil {
+		return nil, 0, ErrInvalidParams
+	}
+	if req.Status != nil && *req.Status != entity.UserStatusActive && *req.Status != entity.UserStatusDisabled {
+		return nil, 0, ErrInvalidParams
+	}
+	if req.Role != "" && !isValidRole(req.Role) {
+		return nil, 0, ErrInvalidRole
+	}
+
+	query := repo.NewUserListQuery(req.Keyword, req.Status, req.Role, req.Page, req.PageSize)
+	users, total, err := u.userRepo.ListUsers(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list users failed: %v", err)
+		return nil, 0, ErrInternalError
+	}
+	return users, total, nil
+}
+
+// SetUserStatus 管理员禁用/启用用户，禁用时不填写原因、不设到期时间
+func (u *UserServiceImpl) SetUserStatus(ctx context.Context, userID string, status int) error {
+	switch status {
+	case entity.UserStatusActive:
+		return u.ReactivateUser(ctx, userID)
+	case entity.UserStatusDisabled:
+		return u.SuspendUser(ctx, userID, "", nil)
+	}
+	return ErrInvalidParams
+}
+
+// SetUserRole 管理员修改用户角色
+func (u *UserServiceImpl) SetUserRole(ctx context.Context, userID, role string) error {
+	if userID == "" {
+		return ErrInvalidParams
+	}
+	if !isValidRole(role) {
+		return ErrInvalidRole
+	}
+	if err := u.checkManageTarget(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID: userID,
+		Role:   &role,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "update user role failed: %v", err)
+		return ErrInternalError
+	}
+
+	u.audit(ctx, userID, entity.AuditActionAdminUpdateRole, "role: "+role, nil)
+	zlog.CtxInfof(ctx, "user %s role set to %s by admin", userID, role)
+	return nil
+}
+
+// AdminResetPassword 管理员为用户重置密码，无需验证码
+func (u *UserServiceImpl) AdminResetPassword(ctx context.Context, userID, newPassword string) error {
+	if userID == "" || newPassword == "" {
+		return ErrInvalidParams
+	}
+	user, err := u.findUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.ValidatePasswordStrength(newPassword); err != nil {
+		zlog.CtxErrorf(ctx, "password strength validation failed: %v", err)
+		return err
+	}
+	if err := u.checkPasswordReuse(ctx, user, newPassword); err != nil {
+		return err
+	}
+	hash, err := util.HashPassword(newPassword)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "hash password failed: %v", err)
+		return ErrInternalError
+	}
+
+	if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:   userID,
+		Password: &hash,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "update password failed: %v", err)
+		return ErrInternalError
+	}
+	u.recordPasswordHistory(ctx, userID, hash)
+
+	u.audit(ctx, userID, entity.AuditActionAdminResetPassword, "", nil)
+	zlog.CtxInfof(ctx, "password of user %s reset by admin", userID)
+	return nil
+}
+
+// ListUserSessions 查看用户的登录记录（管理接口）
+func (u *UserServiceImpl) ListUserSessions(ctx context.Context, userID string) ([]*entity.UserSession, error) {
+	if userID == "" {
+		return nil, ErrInvalidParams
+	}
+	if _, err := u.findUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := u.sessionRepo.ListSessions(ctx, userID, maxListSessions)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list user sessions failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return sessions, nil
+}
+
+// GrantAdminRole 将配置中的管理员用户设为管理员角色，启动时执行，已是管理员的不做修改
+func (u *UserServiceImpl) GrantAdminRole(ctx context.Context, userIDs []string) {
+	role := entity.UserRoleAdmin
+	for _, userID := range userIDs {
+		user, err := u.findUserByID(ctx, userID)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "grant admin role to %s failed: %v", userID, err)
+			continue
+		}
+		if user.Role == entity.UserRoleAdmin {
+			continue
+		}
+		if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{UserID: userID, Role: &role}); err != nil {
+			zlog.CtxErrorf(ctx, "grant admin role to %s failed: %v", userID, err)
+			continue
+		}
+		zlog.CtxInfof(ctx, "admin role granted to configured user: %s", userID)
+	}
+}
+
+// checkManageTarget 校验被管理的用户存在，且不是当前管理员自己（避免把自己禁用或降级后无人可管理）
+func (u *UserServiceImpl) checkManageTarget(ctx context.Context, userID string) error {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+	if currentUser.UserID == userID {
+		zlog.CtxWarnf(ctx, "admin %s cannot manage self", userID)
+		return ErrCannotManageSelf
+	}
+	_, err := u.findUserByID(ctx, userID)
+	return err
+}
+
+// findUserByID 查询用户，不检查用户状态
+func (u *UserServiceImpl) findUserByID(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := u.userRepo.GetUser(ctx, repo.NewUserQueryByID(userID))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get user by ID: %v", err)
+		return nil, ErrInternalError
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func isValidRole(role string) bool {
+	switch role {
+	case entity.UserRoleUser, entity.UserRoleAdmin:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package userservice
+
+import (
+	"context"
+
+	"forge/biz/types"
+)
+
+// audit 记录账号安全事件，err 为 nil 表示操作成功；未注入审计服务时不记录
+func (u *UserServiceImpl) audit(ctx context.Context, userID, action, detail string, err error) {
+	if u.auditService == nil {
+		return
+	}
+	u.auditService.Record(ctx, &types.AuditEvent{
+		UserID: userID,
+		Action: action,
+		Detail: detail,
+		Err:    err,
+	})
+}
@@ -0,0 +1,136 @@
+package userservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 同一用户两次提醒的默认最小间隔
+	defaultReminderResendDays = 30
+	// 每批查询的用户数
+	reminderBatchSize = 100
+	// 退订签名的用途前缀，避免与其他签名混用
+	unsubscribeTokenPurpose = "contact_reminder:"
+)
+
+// SendContactReminders 给只绑定了邮箱的用户发送绑定手机号的提醒邮件，返回发送成功的数量
+// 已退订或在 resend_days 内提醒过的用户会被跳过；单个用户发送失败不影响其他用户
+func (u *UserServiceImpl) SendContactReminders(ctx context.Context) (int, error) {
+	if u.codeService == nil || u.reminderConfig.UnsubscribeURL == "" || u.reminderConfig.Secret == "" {
+		zlog.CtxWarnf(ctx, "contact reminder skipped: code service, unsubscribe_url or secret not configured")
+		return 0, nil
+	}
+
+	resendDays := u.reminderConfig.ResendDays
+	if resendDays <= 0 {
+		resendDays = defaultReminderResendDays
+	}
+	sentBefore := time.Now().AddDate(0, 0, -resendDays)
+
+	var (
+		afterUserID string
+		count       int
+	)
+	for {
+		users, err := u.userRepo.ListReminderCandidates(ctx, afterUserID, sentBefore, reminderBatchSize)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "list reminder candidates failed: %v", err)
+			return count, ErrInternalError
+		}
+		if len(users) == 0 {
+			return count, nil
+		}
+
+		for _, user := range users {
+			if err := u.sendContactReminder(ctx, user); err != nil {
+				zlog.CtxErrorf(ctx, "send contact reminder to user %s failed: %v", user.UserID, err)
+				continue
+			}
+			count++
+		}
+		afterUserID = users[len(users)-1].UserID
+	}
+}
+
+func (u *UserServiceImpl) sendContactReminder(ctx context.Context, user *entity.User) error {
+	err := u.codeService.SendContactReminder(ctx, user.Email, &adapter.ContactReminder{
+		UserName:       user.UserName,
+		BindURL:        u.reminderConfig.BindURL,
+		UnsubscribeURL: u.unsubscribeURL(user.UserID),
+	})
+	if err != nil {
+		return err
+	}
+
+	sentAt := time.Now()
+	return u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:         user.UserID,
+		ReminderSentAt: &sentAt,
+	})
+}
+
+// UnsubscribeReminder 通过邮件中的退订链接退订提醒，无需登录
+func (u *UserServiceImpl) UnsubscribeReminder(ctx context.Context, userID, token string) error {
+	if userID == "" || token == "" {
+		return ErrInvalidParams
+	}
+	if u.reminderConfig.Secret == "" || !hmac.Equal([]byte(token), []byte(u.unsubscribeToken(userID))) {
+		zlog.CtxWarnf(ctx, "invalid unsubscribe token for user: %s", userID)
+		return ErrInvalidUnsubscribeToken
+	}
+
+	return u.setReminderOptOut(ctx, userID, true)
+}
+
+// UpdateNotificationPreference 登录用户修改通知偏好
+func (u *UserServiceImpl) UpdateNotificationPreference(ctx context.Context, reminderEmail bool) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	return u.setReminderOptOut(ctx, user.UserID, !reminderEmail)
+}
+
+func (u *UserServiceImpl) setReminderOptOut(ctx context.Context, userID string, optOut bool) error {
+	// 检查用户是否存在（GetUserByID 包含状态检查）
+	if _, err := u.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+		UserID:         userID,
+		ReminderOptOut: &optOut,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "update reminder preference failed: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "reminder opt out set to %v for user: %s", optOut, userID)
+	return nil
+}
+
+// unsubscribeURL 生成带签名的退订链接
+func (u *UserServiceImpl) unsubscribeURL(userID string) string {
+	query := url.Values{}
+	query.Set("uid", userID)
+	query.Set("token", u.unsubscribeToken(userID))
+	return u.reminderConfig.UnsubscribeURL + "?" + query.Encode()
+}
+
+// unsubscribeToken 退订签名：HMAC-SHA256(secret, purpose+userID)
+func (u *UserServiceImpl) unsubscribeToken(userID string) string {
+	mac := hmac.New(sha256.New, []byte(u.reminderConfig.Secret))
+	mac.Write([]byte(unsubscribeTokenPurpose + userID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,152 @@
+package userservice
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+const (
+	// 邀请码长度，字符集不含易混淆的 0/O、1/I
+	inviteCodeLength = 8
+	// 生成邀请码碰撞时的最大重试次数
+	maxInviteCodeAttempts = 3
+)
+
+// CreateInviteCode 当前用户生成一个邀请码，每个用户可生成的个数有上限
+func (u *UserServiceImpl) CreateInviteCode(ctx context.Context) (*entity.InviteCode, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for create invite code")
+		return nil, ErrPermissionDenied
+	}
+
+	count, err := u.inviteRepo.CountInviteCodes(ctx, currentUser.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "count invite codes failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if count >= u.inviteConfig.CodeQuota() {
+		zlog.CtxWarnf(ctx, "invite code quota exceeded for user: %s", currentUser.UserID)
+		return nil, ErrInviteQuotaExceeded
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(u.inviteConfig.Expiration())
+	invite := &entity.InviteCode{
+		UserID:    currentUser.UserID,
+		MaxUses:   u.inviteConfig.CodeMaxUses(),
+		ExpiresAt: &expiresAt,
+		CreatedAt: now,
+	}
+	for attempt := 1; ; attempt++ {
+		if invite.Code, err = util.GenerateCaptchaCode(inviteCodeLength); err != nil {
+			zlog.CtxErrorf(ctx, "generate invite code failed: %v", err)
+			return nil, ErrInternalError
+		}
+		// 邀请码列有唯一索引，碰撞时重新生成
+		if err = u.inviteRepo.CreateInviteCode(ctx, invite); err == nil {
+			break
+		}
+		if attempt >= maxInviteCodeAttempts {
+			zlog.CtxErrorf(ctx, "create invite code failed: %v", err)
+			return nil, ErrInternalError
+		}
+	}
+
+	zlog.CtxInfof(ctx, "invite code created by user: %s", currentUser.UserID)
+	return invite, nil
+}
+
+// ListInviteCodes 当前用户生成的邀请码
+func (u *UserServiceImpl) ListInviteCodes(ctx context.Context) ([]*entity.InviteCode, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for list invite codes")
+		return nil, ErrPermissionDenied
+	}
+
+	codes, err := u.inviteRepo.ListInviteCodes(ctx, currentUser.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list invite codes failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return codes, nil
+}
+
+// ListReferrals 当前用户邀请注册的记录
+func (u *UserServiceImpl) ListReferrals(ctx context.Context) ([]*entity.Referral, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for list referrals")
+		return nil, ErrPermissionDenied
+	}
+
+	referrals, err := u.inviteRepo.ListReferrals(ctx, currentUser.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list referrals failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return referrals, nil
+}
+
+// findRegisterInviteCode 查询注册时填写的邀请码，未填写且不要求邀请码时返回 nil
+// 这里只做检查，使用次数在创建用户前扣减
+func (u *UserServiceImpl) findRegisterInviteCode(ctx context.Context, code string) (*entity.InviteCode, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		if u.inviteConfig.Required {
+			return nil, ErrInviteCodeRequired
+		}
+		return nil, nil
+	}
+
+	invite, err := u.inviteRepo.GetInviteCode(ctx, code)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get invite code failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if invite == nil || !invite.Usable(time.Now()) {
+		zlog.CtxWarnf(ctx, "invalid invite code: %s", code)
+		return nil, ErrInviteCodeInvalid
+	}
+	return invite, nil
+}
+
+// useInviteCode 扣减一次邀请码的使用次数，并发注册时次数可能在检查后被用完
+func (u *UserServiceImpl) useInviteCode(ctx context.Context, invite *entity.InviteCode) error {
+	if invite == nil {
+		return nil
+	}
+	used, err := u.inviteRepo.UseInviteCode(ctx, invite.Code, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "use invite code failed: %v", err)
+		return ErrInternalError
+	}
+	if !used {
+		zlog.CtxWarnf(ctx, "invite code used up: %s", invite.Code)
+		return ErrInviteCodeInvalid
+	}
+	return nil
+}
+
+// recordReferral 记录邀请关系，失败不影响注册结果
+func (u *UserServiceImpl) recordReferral(ctx context.Context, invite *entity.InviteCode, inviteeID string) {
+	if invite == nil {
+		return
+	}
+	if err := u.inviteRepo.CreateReferral(ctx, &entity.Referral{
+		InviterID: invite.UserID,
+		InviteeID: inviteeID,
+		Code:      invite.Code,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "record referral of user %s failed: %v", inviteeID, err)
+		return
+	}
+	zlog.CtxInfof(ctx, "user %s registered with invite code of user %s", inviteeID, invite.UserID)
+}
@@ -0,0 +1,96 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"forge/biz/entity"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+)
+
+// checkLoginAllowed 登录前检查账号是否被锁定、来源IP失败次数是否超限
+func (u *UserServiceImpl) checkLoginAllowed(ctx context.Context, account, accountType string) error {
+	lockKey := fmt.Sprintf(constant.REDIS_LOGIN_LOCK_KEY, accountType, normalizeAccount(account))
+	locked, err := cache.GetRedis(ctx, lockKey)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get login lock failed: %v", err)
+		return ErrInternalError
+	}
+	if locked != "" {
+		zlog.CtxWarnf(ctx, "login rejected, account locked: %s", account)
+		return ErrTooManyLoginAttempts
+	}
+
+	ip := entity.GetClientIP(ctx)
+	if ip == "" {
+		return nil
+	}
+	ipKey := fmt.Sprintf(constant.REDIS_LOGIN_FAIL_IP_KEY, ip)
+	count, err := cache.GetRedis(ctx, ipKey)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get login failures by ip failed: %v", err)
+		return ErrInternalError
+	}
+	if count != "" && parseCount(count) >= u.loginLimitConfig.IPMaxFailureCount() {
+		zlog.CtxWarnf(ctx, "login rejected, too many failures from ip: %s", ip)
+		return ErrTooManyLoginAttempts
+	}
+	return nil
+}
+
+// recordLoginFailure 记录一次登录失败，账号失败次数达到上限时锁定账号
+// 账号不存在时同样计数，避免通过是否锁定来探测账号是否注册
+func (u *UserServiceImpl) recordLoginFailure(ctx context.Context, account, accountType string) {
+	window := u.loginLimitConfig.Window()
+
+	if ip := entity.GetClientIP(ctx); ip != "" {
+		ipKey := fmt.Sprintf(constant.REDIS_LOGIN_FAIL_IP_KEY, ip)
+		if _, err := cache.IncrRedis(ctx, ipKey, window); err != nil {
+			zlog.CtxErrorf(ctx, "incr login failures by ip failed: %v", err)
+		}
+	}
+
+	account = normalizeAccount(account)
+	failKey := fmt.Sprintf(constant.REDIS_LOGIN_FAIL_ACCOUNT_KEY, accountType, account)
+	count, err := cache.IncrRedis(ctx, failKey, window)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "incr login failures by account failed: %v", err)
+		return
+	}
+	if count < u.loginLimitConfig.AccountMaxFailures() {
+		return
+	}
+
+	lockKey := fmt.Sprintf(constant.REDIS_LOGIN_LOCK_KEY, accountType, account)
+	if err := cache.SetRedis(ctx, lockKey, "1", u.loginLimitConfig.LockDuration()); err != nil {
+		zlog.CtxErrorf(ctx, "set login lock failed: %v", err)
+		return
+	}
+	// 锁定后重新计数，解锁后再次连续失败才会再次锁定
+	if err := cache.DelRedis(ctx, failKey); err != nil {
+		zlog.CtxErrorf(ctx, "reset login failures failed: %v", err)
+	}
+	zlog.CtxWarnf(ctx, "account locked after %d login failures: %s", count, account)
+}
+
+// resetLoginFailures 登录成功后清空账号的失败次数
+func (u *UserServiceImpl) resetLoginFailures(ctx context.Context, account, accountType string) {
+	failKey := fmt.Sprintf(constant.REDIS_LOGIN_FAIL_ACCOUNT_KEY, accountType, normalizeAccount(account))
+	if err := cache.DelRedis(ctx, failKey); err != nil {
+		zlog.CtxErrorf(ctx, "reset login failures failed: %v", err)
+	}
+}
+
+// normalizeAccount 邮箱大小写不敏感，统一后再作为限流计数的 key
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+func parseCount(value string) int64 {
+	var count int64
+	_, _ = fmt.Sscan(value, &count)
+	return count
+}
@@ -0,0 +1,134 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+)
+
+// SendMagicLink 向已注册的邮箱发送免密登录链接
+// 邮箱未注册或账号不可用时同样返回成功，避免通过该接口探测邮箱是否注册
+func (u *UserServiceImpl) SendMagicLink(ctx context.Context, email string) error {
+	if email == "" {
+		zlog.CtxErrorf(ctx, "invalid params for send magic link: email is empty")
+		return ErrInvalidParams
+	}
+	if u.codeService == nil || u.magicLinkConfig.VerifyURL == "" {
+		zlog.CtxErrorf(ctx, "magic link login is not configured")
+		return ErrInternalError
+	}
+
+	// 与验证码共用发送频率限制
+	if err := u.checkCodeSendLimit(ctx, email); err != nil {
+		return err
+	}
+
+	user, err := u.findUserByAccount(ctx, email, types.AccountTypeEmail)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			zlog.CtxWarnf(ctx, "magic link requested for unregistered email: %s", email)
+			return nil
+		}
+		return err
+	}
+	if user.Suspended(time.Now()) {
+		zlog.CtxWarnf(ctx, "magic link requested for disabled user: %s", user.UserID)
+		return nil
+	}
+
+	ttl := u.magicLinkConfig.Expiration()
+	token, claims, err := u.jwtUtil.GenerateMagicLinkToken(user.UserID, ttl)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate magic link token failed: %v", err)
+		return ErrInternalError
+	}
+	// 登记令牌，使用时原子删除，保证链接只能使用一次
+	key := fmt.Sprintf(constant.REDIS_MAGIC_LINK_KEY, claims.ID)
+	if err := cache.SetRedis(ctx, key, user.UserID, ttl); err != nil {
+		zlog.CtxErrorf(ctx, "store magic link token failed: %v", err)
+		return ErrInternalError
+	}
+
+	if err := u.codeService.SendMagicLink(ctx, email, &adapter.MagicLinkEmail{
+		URL:       u.magicLinkURL(token),
+		Language:  entity.GetLanguage(ctx),
+		ExpiresIn: ttl,
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "send magic link failed: %v", err)
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "magic link sent to user: %s", user.UserID)
+	return nil
+}
+
+// LoginWithMagicLink 使用邮件中的一次性令牌登录，令牌使用后立即失效
+func (u *UserServiceImpl) LoginWithMagicLink(ctx context.Context, token string) (*entity.User, *types.AuthTokens, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidParams
+	}
+
+	claims, err := u.jwtUtil.ValidateMagicLinkToken(token)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "invalid magic link token: %v", err)
+		return nil, nil, ErrInvalidMagicLink
+	}
+
+	key := fmt.Sprintf(constant.REDIS_MAGIC_LINK_KEY, claims.ID)
+	userID, err := cache.GetDelRedis(ctx, key)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "consume magic link token failed: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	if userID == "" || userID != claims.UserID {
+		zlog.CtxWarnf(ctx, "magic link token already used or revoked, user: %s", claims.UserID)
+		return nil, nil, ErrInvalidMagicLink
+	}
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 冷静期内重新登录即撤销注销
+	if err := u.cancelPendingDeletion(ctx, user); err != nil {
+		return nil, nil, err
+	}
+	// 能打开邮件中的链接，说明邮箱属于该用户
+	if !user.EmailVerified && user.Email != "" {
+		verified := true
+		if err := u.userRepo.UpdateUser(ctx, &repo.UserUpdateInfo{
+			UserID:        user.UserID,
+			EmailVerified: &verified,
+		}); err != nil {
+			zlog.CtxWarnf(ctx, "mark email verified by magic link failed: %v", err)
+		} else {
+			user.EmailVerified = true
+		}
+	}
+
+	tokens, err := u.issueTokens(ctx, user.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u.audit(ctx, user.UserID, entity.AuditActionLogin, "magic_link", nil)
+	zlog.CtxInfof(ctx, "magic link login success for user: %s", user.UserID)
+	return user, tokens, nil
+}
+
+// magicLinkURL 生成带令牌的登录链接
+func (u *UserServiceImpl) magicLinkURL(token string) string {
+	query := url.Values{}
+	query.Set("token", token)
+	return u.magicLinkConfig.VerifyURL + "?" + query.Encode()
+}
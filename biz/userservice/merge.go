@@ -0,0 +1,151 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// MergeAccount 合并同一用户分别用手机号、邮箱等注册的两个账号，当前账号保留
+// 被合并账号的导图、AI对话迁移到当前账号；当前账号未设置头像、未绑定手机号/邮箱时沿用被合并账号的
+// 被合并账号标记为已删除，登录会话被下线、第三方绑定被解除，之后可将第三方账号重新绑定到当前账号
+// 备份与上传的文件不迁移
+func (u *UserServiceImpl) MergeAccount(ctx context.Context, req *types.MergeAccountParams) (*types.MergeAccountResult, error) {
+	if req == nil || req.MergeAccount == "" || req.MergeAccountType == "" ||
+		(req.Password == "" && req.Code == "") || (req.MergePassword == "" && req.MergeCode == "") {
+		zlog.CtxErrorf(ctx, "invalid params for merge account: password or code is required for both accounts")
+		return nil, ErrInvalidParams
+	}
+
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for merge account")
+		return nil, ErrPermissionDenied
+	}
+	if err := u.verifyOwnership(ctx, currentUser, req.Password, req.AccountType, req.Code); err != nil {
+		return nil, err
+	}
+
+	source, err := u.findMergeSource(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if source.UserID == currentUser.UserID {
+		zlog.CtxWarnf(ctx, "user %s cannot merge self", currentUser.UserID)
+		return nil, ErrMergeSameAccount
+	}
+	// 禁用中的账号不能合并，避免通过合并绕过禁用
+	if source.Suspended(time.Now()) {
+		zlog.CtxWarnf(ctx, "suspended user %s cannot be merged", source.UserID)
+		return nil, ErrAccountSuspended
+	}
+
+	// 迁移数据、解除第三方绑定与记录合并事件在同一个事务中，任一步失败时整体回滚，之后可以重新合并
+	var result *repo.UserMergeResult
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.userRepo.MergeUser(ctx, &repo.UserMerge{
+			FromUserID: source.UserID,
+			Target:     mergeTargetUpdate(currentUser, source),
+		})
+		if err != nil {
+			return fmt.Errorf("merge user: %w", err)
+		}
+		if err := u.identityRepo.DeleteUserIdentities(ctx, source.UserID); err != nil {
+			return fmt.Errorf("delete identities: %w", err)
+		}
+		if err := u.outboxService.Record(ctx, entity.EventUserMerged, currentUser.UserID, &entity.UserMergedEvent{
+			UserID:        currentUser.UserID,
+			MergedUserID:  source.UserID,
+			MindMaps:      result.MindMaps,
+			Conversations: result.Conversations,
+			MergedAt:      time.Now(),
+		}); err != nil {
+			return fmt.Errorf("record merged event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "merge user %s into %s failed: %v", source.UserID, currentUser.UserID, err)
+		return nil, ErrInternalError
+	}
+	u.invalidateUserBrief(ctx, currentUser.UserID)
+	u.invalidateUserBrief(ctx, source.UserID)
+
+	// 下线会话失败不影响合并结果：被合并账号已标记删除，会话刷新时无法再找到用户
+	if err := u.revokeUserSessions(ctx, source.UserID); err != nil {
+		zlog.CtxErrorf(ctx, "revoke sessions of merged user %s failed: %v", source.UserID, err)
+	}
+
+	u.audit(ctx, currentUser.UserID, entity.AuditActionMergeAccount, "merged: "+source.UserID, nil)
+	u.audit(ctx, source.UserID, entity.AuditActionMergeAccount, "merged into: "+currentUser.UserID, nil)
+	zlog.CtxInfof(ctx, "user %s merged into %s, mindmaps: %d, conversations: %d",
+		source.UserID, currentUser.UserID, result.MindMaps, result.Conversations)
+	return &types.MergeAccountResult{
+		MergedUserID:  source.UserID,
+		MindMaps:      result.MindMaps,
+		Conversations: result.Conversations,
+	}, nil
+}
+
+// findMergeSource 查找并验证待合并的账号
+// 密码验证与登录共用失败计数，避免通过该接口猜测其他账号的密码
+func (u *UserServiceImpl) findMergeSource(ctx context.Context, req *types.MergeAccountParams) (*entity.User, error) {
+	account, err := normalizeContact(ctx, req.MergeAccount, req.MergeAccountType)
+	if err != nil {
+		return nil, err
+	}
+	usePassword := req.MergePassword != ""
+	if usePassword {
+		if err := u.checkLoginAllowed(ctx, account, req.MergeAccountType); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := u.findUserByAccount(ctx, account, req.MergeAccountType)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			zlog.CtxWarnf(ctx, "account to merge not found: %s", account)
+			if usePassword {
+				u.recordLoginFailure(ctx, account, req.MergeAccountType)
+			}
+			return nil, ErrCredentialsIncorrect
+		}
+		return nil, err
+	}
+
+	// 用户名没有可接收验证码的联系方式，由 verifyOwnership 拒绝
+	if err := u.verifyOwnership(ctx, source, req.MergePassword, req.MergeAccountType, req.MergeCode); err != nil {
+		if usePassword && errors.Is(err, ErrCredentialsIncorrect) {
+			u.recordLoginFailure(ctx, account, req.MergeAccountType)
+		}
+		return nil, err
+	}
+	if usePassword {
+		u.resetLoginFailures(ctx, account, req.MergeAccountType)
+	}
+	return source, nil
+}
+
+// mergeTargetUpdate 保留账号需要沿用的资料：只补充保留账号为空的头像与联系方式
+func mergeTargetUpdate(target, source *entity.User) *repo.UserUpdateInfo {
+	updateInfo := &repo.UserUpdateInfo{UserID: target.UserID}
+	if target.Avatar == "" && source.Avatar != "" {
+		updateInfo.Avatar = &source.Avatar
+	}
+	if target.Phone == "" && source.Phone != "" {
+		updateInfo.Phone = &source.Phone
+		updateInfo.PhoneVerified = &source.PhoneVerified
+	}
+	if target.Email == "" && source.Email != "" {
+		updateInfo.Email = &source.Email
+		updateInfo.EmailVerified = &source.EmailVerified
+	}
+	return updateInfo
+}
@@ -0,0 +1,340 @@
+package userservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+const (
+	// oauthStateTTL 授权 state 有效期，需覆盖用户在第三方页面扫码确认的时间
+	oauthStateTTL = 10 * time.Minute
+	// 默认昵称，第三方未返回昵称时使用
+	defaultOAuthUserName = "新用户"
+)
+
+// OAuthAuthorizeURL 生成跳转到第三方授权页面的地址
+// purpose 为 login 时无需登录；为 bind 时绑定到当前登录用户
+func (u *UserServiceImpl) OAuthAuthorizeURL(ctx context.Context, provider, purpose string) (string, error) {
+	p, ok := u.oauthProviders[provider]
+	if !ok {
+		return "", ErrUnsupportedOAuthProvider
+	}
+
+	var userID string
+	switch purpose {
+	case types.OAuthPurposeLogin:
+	case types.OAuthPurposeBind:
+		user, ok := entity.GetUser(ctx)
+		if !ok {
+			return "", ErrPermissionDenied
+		}
+		userID = user.UserID
+	default:
+		return "", ErrInvalidParams
+	}
+
+	state, err := newOAuthState()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate oauth state failed: %v", err)
+		return "", ErrInternalError
+	}
+	key := fmt.Sprintf(constant.REDIS_OAUTH_STATE_KEY, state)
+	if err := cache.SetRedis(ctx, key, oauthStateValue(provider, purpose, userID), oauthStateTTL); err != nil {
+		zlog.CtxErrorf(ctx, "store oauth state failed: %v", err)
+		return "", ErrInternalError
+	}
+
+	return p.AuthURL(state), nil
+}
+
+// LoginWithOAuth 第三方登录：已绑定的账号直接登录，未绑定的自动创建新用户
+func (u *UserServiceImpl) LoginWithOAuth(ctx context.Context, provider, code, state string) (*entity.User, *types.AuthTokens, error) {
+	if _, err := u.consumeOAuthState(ctx, provider, types.OAuthPurposeLogin, state); err != nil {
+		return nil, nil, err
+	}
+	oauthUser, err := u.exchangeOAuthCode(ctx, provider, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity, err := u.identityRepo.GetIdentity(ctx, oauthUser.Provider, oauthUser.ProviderUID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get user identity failed: %v", err)
+		return nil, nil, ErrInternalError
+	}
+
+	var user *entity.User
+	if identity != nil {
+		user, err = u.loadUser(ctx, identity.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 冷静期内重新登录即撤销注销
+		if err := u.cancelPendingDeletion(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		user, err = u.createOAuthUser(ctx, oauthUser)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tokens, err := u.issueTokens(ctx, user.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u.audit(ctx, user.UserID, entity.AuditActionLogin, "oauth: "+provider, nil)
+	zlog.CtxInfof(ctx, "oauth login success for user: %s, provider: %s", user.UserID, provider)
+	return user, tokens, nil
+}
+
+// createOAuthUser 第三方账号首次登录时创建用户并绑定
+func (u *UserServiceImpl) createOAuthUser(ctx context.Context, oauthUser *adapter.OAuthUser) (*entity.User, error) {
+	userID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate user id failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	userName := strings.TrimSpace(oauthUser.NickName)
+	if userName == "" {
+		userName = defaultOAuthUserName
+	}
+	user := &entity.User{
+		UserID:   userID,
+		UserName: userName,
+		Avatar:   oauthUser.Avatar,
+	}
+	// 平台提供的已验证邮箱未被其他账号使用时直接绑定，之后也可以用邮箱登录
+	if oauthUser.Email != "" {
+		existing, err := u.userRepo.GetUser(ctx, repo.UserQuery{Email: oauthUser.Email})
+		if err != nil {
+			zlog.CtxWarnf(ctx, "check oauth email failed: %v", err)
+		} else if existing == nil {
+			user.Email = oauthUser.Email
+		}
+	}
+	// 用户与绑定关系在同一个事务中创建，绑定失败时不留下没有登录方式的用户
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.CreateUser(ctx, user); err != nil {
+			zlog.CtxErrorf(ctx, "create oauth user failed: %v", err)
+			return ErrInternalError
+		}
+		return u.identityRepo.CreateIdentity(ctx, newUserIdentity(userID, oauthUser))
+	})
+	if err != nil {
+		// 并发首次登录时另一个请求已完成绑定，改为登录已绑定的用户
+		if errors.Is(err, repo.ErrIdentityAlreadyBound) {
+			identity, getErr := u.identityRepo.GetIdentity(ctx, oauthUser.Provider, oauthUser.ProviderUID)
+			if getErr == nil && identity != nil {
+				zlog.CtxWarnf(ctx, "oauth identity bound concurrently, login as user %s", identity.UserID)
+				return u.GetUserByID(ctx, identity.UserID)
+			}
+		}
+		if !errors.Is(err, ErrInternalError) {
+			zlog.CtxErrorf(ctx, "create user identity failed: %v", err)
+		}
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "oauth user created: %s, provider: %s", userID, oauthUser.Provider)
+	return user, nil
+}
+
+// BindOAuth 当前登录用户绑定第三方账号
+func (u *UserServiceImpl) BindOAuth(ctx context.Context, provider, code, state string) error {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+	stateUserID, err := u.consumeOAuthState(ctx, provider, types.OAuthPurposeBind, state)
+	if err != nil {
+		return err
+	}
+	// state 必须由当前用户申请，防止把他人的授权绑定到自己账号上
+	if stateUserID != currentUser.UserID {
+		zlog.CtxWarnf(ctx, "oauth state belongs to user %s, current user %s", stateUserID, currentUser.UserID)
+		return ErrInvalidOAuthState
+	}
+
+	oauthUser, err := u.exchangeOAuthCode(ctx, provider, code)
+	if err != nil {
+		return err
+	}
+
+	identity, err := u.identityRepo.GetIdentity(ctx, oauthUser.Provider, oauthUser.ProviderUID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get user identity failed: %v", err)
+		return ErrInternalError
+	}
+	if identity != nil {
+		if identity.UserID == currentUser.UserID {
+			return nil
+		}
+		return ErrAccountAlreadyInUse
+	}
+
+	if err := u.identityRepo.CreateIdentity(ctx, newUserIdentity(currentUser.UserID, oauthUser)); err != nil {
+		if errors.Is(err, repo.ErrIdentityAlreadyBound) {
+			// 唯一索引冲突：当前用户已绑定该平台的其他账号，或该账号刚被他人绑定
+			return ErrOAuthAlreadyBound
+		}
+		zlog.CtxErrorf(ctx, "create user identity failed: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "oauth bound for user: %s, provider: %s", currentUser.UserID, provider)
+	return nil
+}
+
+// UnbindOAuth 当前登录用户解绑第三方账号，解绑后必须仍有其他登录方式
+func (u *UserServiceImpl) UnbindOAuth(ctx context.Context, provider string) error {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	identities, err := u.identityRepo.ListIdentities(ctx, currentUser.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list user identities failed: %v", err)
+		return ErrInternalError
+	}
+
+	bound := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		return ErrInvalidParams
+	}
+
+	// 没有手机号/邮箱也没有其他第三方账号时，解绑后将无法登录
+	hasAccount := currentUser.Phone != "" || currentUser.Email != ""
+	if !hasAccount && len(identities) <= 1 {
+		zlog.CtxErrorf(ctx, "cannot unbind %s, no other login method, userID: %s", provider, currentUser.UserID)
+		return ErrCannotUnbindOnlyContact
+	}
+
+	if err := u.identityRepo.DeleteIdentity(ctx, currentUser.UserID, provider); err != nil {
+		zlog.CtxErrorf(ctx, "delete user identity failed: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "oauth unbound for user: %s, provider: %s", currentUser.UserID, provider)
+	return nil
+}
+
+// ListOAuthBindings 当前登录用户绑定的第三方账号
+func (u *UserServiceImpl) ListOAuthBindings(ctx context.Context) ([]*entity.UserIdentity, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	identities, err := u.identityRepo.ListIdentities(ctx, currentUser.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list user identities failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return identities, nil
+}
+
+// hasOAuthIdentity 用户是否绑定了第三方账号（解绑手机号/邮箱时判断是否还有其他登录方式）
+func (u *UserServiceImpl) hasOAuthIdentity(ctx context.Context, userID string) (bool, error) {
+	identities, err := u.identityRepo.ListIdentities(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(identities) > 0, nil
+}
+
+// consumeOAuthState 校验并作废 state，返回申请 state 的用户ID（登录场景为空）
+func (u *UserServiceImpl) consumeOAuthState(ctx context.Context, provider, purpose, state string) (string, error) {
+	if _, ok := u.oauthProviders[provider]; !ok {
+		return "", ErrUnsupportedOAuthProvider
+	}
+	if state == "" {
+		return "", ErrInvalidOAuthState
+	}
+
+	key := fmt.Sprintf(constant.REDIS_OAUTH_STATE_KEY, state)
+	value, err := cache.GetDelRedis(ctx, key)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "load oauth state failed: %v", err)
+		return "", ErrInternalError
+	}
+
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[0] != provider || parts[1] != purpose {
+		zlog.CtxWarnf(ctx, "invalid oauth state for provider %s, purpose %s", provider, purpose)
+		return "", ErrInvalidOAuthState
+	}
+	return parts[2], nil
+}
+
+func (u *UserServiceImpl) exchangeOAuthCode(ctx context.Context, provider, code string) (*adapter.OAuthUser, error) {
+	if code == "" {
+		return nil, ErrInvalidParams
+	}
+	p := u.oauthProviders[provider]
+	token, err := p.Exchange(ctx, code)
+	if err != nil {
+		if errors.Is(err, adapter.ErrOAuthCodeInvalid) {
+			return nil, ErrOAuthCodeInvalid
+		}
+		zlog.CtxErrorf(ctx, "exchange oauth code failed: %v", err)
+		return nil, ErrOAuthProvider
+	}
+	oauthUser, err := p.GetUserInfo(ctx, token)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get oauth user info failed: %v", err)
+		return nil, ErrOAuthProvider
+	}
+	if oauthUser.ProviderUID == "" {
+		zlog.CtxErrorf(ctx, "oauth provider %s returned empty user id", provider)
+		return nil, ErrOAuthProvider
+	}
+	return oauthUser, nil
+}
+
+func newUserIdentity(userID string, oauthUser *adapter.OAuthUser) *entity.UserIdentity {
+	return &entity.UserIdentity{
+		UserID:      userID,
+		Provider:    oauthUser.Provider,
+		ProviderUID: oauthUser.ProviderUID,
+		OpenID:      oauthUser.OpenID,
+		NickName:    oauthUser.NickName,
+		Avatar:      oauthUser.Avatar,
+	}
+}
+
+func oauthStateValue(provider, purpose, userID string) string {
+	return provider + ":" + purpose + ":" + userID
+}
+
+func newOAuthState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,64 @@
+package userservice
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// checkPasswordReuse 校验新密码不是当前密码，也不在最近设置过的密码中
+// 历史记录上线前设置的密码只有当前密码可比对
+func (u *UserServiceImpl) checkPasswordReuse(ctx context.Context, user *entity.User, newPassword string) error {
+	current, err := u.passwordHash(ctx, user)
+	if err != nil {
+		return err
+	}
+	hashes := make([]string, 0, u.passwordHistoryConfig.HistoryCount()+1)
+	if current != "" {
+		hashes = append(hashes, current)
+	}
+
+	histories, err := u.passwordHistoryRepo.ListPasswordHistory(ctx, user.UserID, u.passwordHistoryConfig.HistoryCount())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list password history failed: %v", err)
+		return ErrInternalError
+	}
+	for _, history := range histories {
+		// 最新一条通常就是当前密码，bcrypt 比对较慢，不重复比对
+		if history.PasswordHash != current {
+			hashes = append(hashes, history.PasswordHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		match, err := util.ComparePassword(hash, newPassword)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "compare password history failed: %v", err)
+			return ErrInternalError
+		}
+		if match {
+			zlog.CtxWarnf(ctx, "new password recently used by user: %s", user.UserID)
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory 记录新设置的密码并清理超出保留数量的旧记录
+// 密码已经修改成功，记录失败只打印日志
+func (u *UserServiceImpl) recordPasswordHistory(ctx context.Context, userID, hash string) {
+	if err := u.passwordHistoryRepo.AddPasswordHistory(ctx, &entity.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		zlog.CtxErrorf(ctx, "add password history failed, user: %s, err: %v", userID, err)
+		return
+	}
+	if err := u.passwordHistoryRepo.PrunePasswordHistory(ctx, userID, u.passwordHistoryConfig.HistoryCount()); err != nil {
+		zlog.CtxWarnf(ctx, "prune password history failed, user: %s, err: %v", userID, err)
+	}
+}
@@ -0,0 +1,99 @@
+package userservice
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 个人资料各字段的最大长度（字符数），与数据库列宽一致
+	maxNicknameLength = 32
+	maxBioLength      = 255
+	maxLocationLength = 64
+	// 生日的日期格式
+	birthdayLayout = "2006-01-02"
+)
+
+// UpdateProfile 修改当前用户的个人资料
+func (u *UserServiceImpl) UpdateProfile(ctx context.Context, req *types.UpdateProfileParams) (*entity.User, error) {
+	if req == nil {
+		return nil, ErrInvalidParams
+	}
+
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for update profile")
+		return nil, ErrPermissionDenied
+	}
+
+	updateInfo := &repo.UserUpdateInfo{UserID: currentUser.UserID}
+	var err error
+	if updateInfo.Nickname, err = trimProfileText(req.Nickname, maxNicknameLength); err != nil {
+		zlog.CtxWarnf(ctx, "nickname too long, userID: %s", currentUser.UserID)
+		return nil, err
+	}
+	if updateInfo.Bio, err = trimProfileText(req.Bio, maxBioLength); err != nil {
+		zlog.CtxWarnf(ctx, "bio too long, userID: %s", currentUser.UserID)
+		return nil, err
+	}
+	if updateInfo.Location, err = trimProfileText(req.Location, maxLocationLength); err != nil {
+		zlog.CtxWarnf(ctx, "location too long, userID: %s", currentUser.UserID)
+		return nil, err
+	}
+
+	if req.Gender != nil {
+		switch *req.Gender {
+		case "", entity.UserGenderMale, entity.UserGenderFemale, entity.UserGenderOther:
+			updateInfo.Gender = req.Gender
+		default:
+			zlog.CtxWarnf(ctx, "invalid gender: %s", *req.Gender)
+			return nil, ErrInvalidParams
+		}
+	}
+
+	if req.Birthday != nil {
+		// 空字符串表示清除生日
+		var birthday time.Time
+		if *req.Birthday != "" {
+			birthday, err = time.ParseInLocation(birthdayLayout, *req.Birthday, time.Local)
+			if err != nil || birthday.After(time.Now()) || birthday.Year() < 1900 {
+				zlog.CtxWarnf(ctx, "invalid birthday: %s", *req.Birthday)
+				return nil, ErrInvalidParams
+			}
+		}
+		updateInfo.Birthday = &birthday
+	}
+
+	if err := u.userRepo.UpdateUser(ctx, updateInfo); err != nil {
+		zlog.CtxErrorf(ctx, "update profile failed: %v", err)
+		return nil, ErrInternalError
+	}
+	u.invalidateUserBrief(ctx, currentUser.UserID)
+
+	user, err := u.GetUserByID(ctx, currentUser.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "profile updated for user: %s", currentUser.UserID)
+	return user, nil
+}
+
+// trimProfileText 去掉首尾空白并校验长度，nil 表示不修改
+func trimProfileText(value *string, maxLength int) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if utf8.RuneCountInString(trimmed) > maxLength {
+		return nil, ErrInvalidParams
+	}
+	return &trimmed, nil
+}
@@ -0,0 +1,73 @@
+package userservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/pkg/log/zlog"
+)
+
+// registerEnrichment 注册补全工作流的输出，只会填充用户未填写的字段
+type registerEnrichment struct {
+	UserName string `json:"user_name"`
+	Avatar   string `json:"avatar"`
+}
+
+// enrichRegisteredUser 注册成功后异步调用 coze 工作流补全用户资料（如默认昵称、头像）
+// 未配置工作流时直接跳过；工作流失败只记录日志
+func (u *UserServiceImpl) enrichRegisteredUser(ctx context.Context, user *entity.User, accountType string) {
+	workflowID := u.cozeConfig.RegisterWorkflowID
+	if u.cozeService == nil || workflowID == "" {
+		return
+	}
+
+	// 请求结束后 ctx 会被取消，这里只保留 ctx 中的日志信息
+	ctx = context.WithoutCancel(ctx)
+	userID, userName, avatar := user.UserID, user.UserName, user.Avatar
+
+	go func() {
+		result, err := u.cozeService.RunWorkflow(ctx, &adapter.RunWorkflowReq{
+			WorkflowID: workflowID,
+			Parameters: map[string]any{
+				"user_id":      userID,
+				"user_name":    userName,
+				"account_type": accountType,
+			},
+		})
+		if err != nil {
+			if !errors.Is(err, adapter.ErrCozeNotConfigured) {
+				zlog.CtxErrorf(ctx, "run register workflow failed, user: %s, err: %v", userID, err)
+			}
+			return
+		}
+
+		var enrichment registerEnrichment
+		if err := json.Unmarshal([]byte(result.Result), &enrichment); err != nil {
+			zlog.CtxWarnf(ctx, "decode register workflow result failed, user: %s, result: %s, err: %v", userID, result.Result, err)
+			return
+		}
+
+		updateInfo := &repo.UserUpdateInfo{UserID: userID}
+		if name := strings.TrimSpace(enrichment.UserName); userName == "" && name != "" {
+			updateInfo.UserName = &name
+		}
+		if url := strings.TrimSpace(enrichment.Avatar); avatar == "" && url != "" {
+			updateInfo.Avatar = &url
+		}
+		if updateInfo.UserName == nil && updateInfo.Avatar == nil {
+			return
+		}
+
+		if err := u.userRepo.UpdateUser(ctx, updateInfo); err != nil {
+			zlog.CtxErrorf(ctx, "update user by register workflow failed, user: %s, err: %v", userID, err)
+			return
+		}
+		u.invalidateUserBrief(ctx, userID)
+		zlog.CtxInfof(ctx, "user %s enriched by register workflow", userID)
+	}()
+}
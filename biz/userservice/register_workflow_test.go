@@ -0,0 +1,108 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/configs"
+	"forge/infra/coze"
+)
+
+// stubUserRepo 只实现 UpdateUser，把修改内容发送到 updates，调用其他方法会 panic
+// 返回错误使工作流协程不再清除缓存，避免与其他测试替换 Redis 客户端并发
+type stubUserRepo struct {
+	repo.UserRepo
+	updates chan *repo.UserUpdateInfo
+}
+
+func (r *stubUserRepo) UpdateUser(ctx context.Context, updateInfo *repo.UserUpdateInfo) error {
+	r.updates <- updateInfo
+	return errors.New("stub user repo")
+}
+
+func newEnrichTestService(cozeService adapter.CozeService, workflowID string) (*UserServiceImpl, *stubUserRepo) {
+	userRepo := &stubUserRepo{updates: make(chan *repo.UserUpdateInfo, 1)}
+	return &UserServiceImpl{
+		userRepo:    userRepo,
+		cozeService: cozeService,
+		cozeConfig:  configs.CozeConfig{RegisterWorkflowID: workflowID},
+	}, userRepo
+}
+
+func TestEnrichRegisteredUser(t *testing.T) {
+	mock := &coze.MockCozeService{
+		RunWorkflowFunc: func(ctx context.Context, req *adapter.RunWorkflowReq) (*adapter.RunWorkflowResult, error) {
+			return &adapter.RunWorkflowResult{Result: `{"user_name":" 默认昵称 ","avatar":"https://example.com/a.png"}`}, nil
+		},
+	}
+	u, userRepo := newEnrichTestService(mock, "wf-register")
+
+	// 已填写的用户名不会被工作流覆盖
+	u.enrichRegisteredUser(context.Background(), &entity.User{UserID: "u1", UserName: "alice"}, "email")
+
+	select {
+	case update := <-userRepo.updates:
+		if update.UserID != "u1" {
+			t.Errorf("UpdateUser() userID = %s, want u1", update.UserID)
+		}
+		if update.UserName != nil {
+			t.Errorf("UpdateUser() userName = %q, want unchanged", *update.UserName)
+		}
+		if update.Avatar == nil || *update.Avatar != "https://example.com/a.png" {
+			t.Errorf("UpdateUser() avatar = %v, want https://example.com/a.png", update.Avatar)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("user not updated by register workflow")
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("RunWorkflow() called %d times, want 1", len(calls))
+	}
+	if calls[0].WorkflowID != "wf-register" {
+		t.Errorf("RunWorkflow() workflowID = %s, want wf-register", calls[0].WorkflowID)
+	}
+	if calls[0].Parameters["user_id"] != "u1" || calls[0].Parameters["account_type"] != "email" {
+		t.Errorf("RunWorkflow() parameters = %v", calls[0].Parameters)
+	}
+}
+
+func TestEnrichRegisteredUserWorkflowFailed(t *testing.T) {
+	called := make(chan struct{})
+	mock := &coze.MockCozeService{
+		RunWorkflowFunc: func(ctx context.Context, req *adapter.RunWorkflowReq) (*adapter.RunWorkflowResult, error) {
+			defer close(called)
+			return nil, errors.New("workflow failed")
+		},
+	}
+	u, userRepo := newEnrichTestService(mock, "wf-register")
+
+	u.enrichRegisteredUser(context.Background(), &entity.User{UserID: "u1"}, "phone")
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("register workflow not called")
+	}
+	select {
+	case update := <-userRepo.updates:
+		t.Errorf("UpdateUser() called with %+v after workflow failed", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEnrichRegisteredUserNotConfigured(t *testing.T) {
+	mock := &coze.MockCozeService{}
+	u, _ := newEnrichTestService(mock, "")
+
+	u.enrichRegisteredUser(context.Background(), &entity.User{UserID: "u1"}, "email")
+
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("RunWorkflow() called %d times without workflow id, want 0", len(calls))
+	}
+}
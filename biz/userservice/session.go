@@ -0,0 +1,120 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 会话列表最多返回的登录记录数
+	maxListSessions = 50
+	// User-Agent 最大保存长度，与数据库列宽一致
+	maxUserAgentLength = 512
+)
+
+// ListSessions 获取当前用户最近的登录记录
+func (u *UserServiceImpl) ListSessions(ctx context.Context) ([]*entity.UserSession, error) {
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for list sessions")
+		return nil, ErrPermissionDenied
+	}
+
+	sessions, err := u.sessionRepo.ListSessions(ctx, currentUser.UserID, maxListSessions)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list user sessions failed: %v", err)
+		return nil, ErrInternalError
+	}
+	return sessions, nil
+}
+
+// RevokeSession 远程下线登录会话：
+// 刷新令牌立即失效，会话内已签发的访问令牌在过期前由鉴权中间件拒绝
+func (u *UserServiceImpl) RevokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return ErrInvalidParams
+	}
+
+	currentUser, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "user not found in context for revoke session")
+		return ErrPermissionDenied
+	}
+
+	session, err := u.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get user session failed: %v", err)
+		return ErrInternalError
+	}
+	if session == nil || session.UserID != currentUser.UserID {
+		zlog.CtxWarnf(ctx, "session %s not found for user %s", sessionID, currentUser.UserID)
+		return ErrSessionNotFound
+	}
+	if !session.Active(time.Now()) {
+		return nil
+	}
+
+	if err := u.revokeSession(ctx, session); err != nil {
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "session %s revoked by user %s", sessionID, currentUser.UserID)
+	return nil
+}
+
+// revokeUserSessions 下线用户所有未过期的会话，用于禁用账号
+// 升级前签发的令牌没有会话，无法逐个下线，禁用期间由鉴权中间件按用户状态拒绝
+func (u *UserServiceImpl) revokeUserSessions(ctx context.Context, userID string) error {
+	sessions, err := u.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		zlog.CtxErrorf(ctx, "list active sessions failed: %v", err)
+		return ErrInternalError
+	}
+	for _, session := range sessions {
+		if err := u.revokeSession(ctx, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeSession 先拦截访问令牌再删除刷新令牌，保证下线后会话内的令牌都不能再使用
+func (u *UserServiceImpl) revokeSession(ctx context.Context, session *entity.UserSession) error {
+	revokedKey := fmt.Sprintf(constant.REDIS_SESSION_REVOKED_KEY, session.SessionID)
+	if err := cache.SetRedis(ctx, revokedKey, "1", u.jwtUtil.TokenTTL()); err != nil {
+		zlog.CtxErrorf(ctx, "mark session revoked failed: %v", err)
+		return ErrInternalError
+	}
+	if session.RefreshTokenID != "" {
+		refreshKey := fmt.Sprintf(constant.REDIS_REFRESH_TOKEN_KEY, session.RefreshTokenID)
+		if err := cache.DelRedis(ctx, refreshKey); err != nil {
+			zlog.CtxErrorf(ctx, "revoke session refresh token failed: %v", err)
+			return ErrInternalError
+		}
+	}
+	if err := u.sessionRepo.RevokeSession(ctx, session.SessionID, time.Now()); err != nil {
+		zlog.CtxErrorf(ctx, "revoke user session failed: %v", err)
+		return ErrInternalError
+	}
+	return nil
+}
+
+// truncateUserAgent 截断过长的 User-Agent
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	userAgent = userAgent[:maxUserAgentLength]
+	// 避免截断在多字节字符中间
+	for !utf8.ValidString(userAgent) {
+		userAgent = userAgent[:len(userAgent)-1]
+	}
+	return userAgent
+}
@@ -0,0 +1,218 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// issueTokens 登录成功后创建登录会话并签发令牌
+func (u *UserServiceImpl) issueTokens(ctx context.Context, userID string) (*types.AuthTokens, error) {
+	sessionID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate session id failed: %v", err)
+		return nil, ErrInternalError
+	}
+
+	tokens, refreshClaims, err := u.signTokens(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 登录记录写入失败不影响登录，只是该会话无法在会话列表中查看与下线
+	userAgent := entity.GetUserAgent(ctx)
+	now := time.Now()
+	session := &entity.UserSession{
+		SessionID:      sessionID,
+		UserID:         userID,
+		Device:         util.ParseDevice(userAgent),
+		IP:             entity.GetClientIP(ctx),
+		UserAgent:      truncateUserAgent(userAgent),
+		RefreshTokenID: refreshClaims.ID,
+		CreatedAt:      now,
+		LastActiveAt:   now,
+		ExpiresAt:      refreshClaims.ExpiresAt.Time,
+	}
+	if err := u.sessionRepo.CreateSession(ctx, session); err != nil {
+		zlog.CtxErrorf(ctx, "create user session failed: %v", err)
+	}
+
+	return tokens, nil
+}
+
+// signTokens 签发属于 sessionID 会话的访问令牌与刷新令牌，刷新令牌的 jti 登记到 Redis，退出登录或使用后即删除
+func (u *UserServiceImpl) signTokens(ctx context.Context, userID, sessionID string) (*types.AuthTokens, *util.Claims, error) {
+	accessToken, err := u.jwtUtil.GenerateToken(userID, sessionID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate token failed: %v", err)
+		return nil, nil, ErrInternalError
+	}
+
+	refreshToken, claims, err := u.jwtUtil.GenerateRefreshToken(userID, sessionID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "generate refresh token failed: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	refreshTTL := u.jwtUtil.RefreshTokenTTL()
+	key := fmt.Sprintf(constant.REDIS_REFRESH_TOKEN_KEY, claims.ID)
+	if err := cache.SetRedis(ctx, key, userID, refreshTTL); err != nil {
+		zlog.CtxErrorf(ctx, "store refresh token failed: %v", err)
+		return nil, nil, ErrInternalError
+	}
+
+	return &types.AuthTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(u.jwtUtil.TokenTTL() / time.Second),
+		RefreshExpiresIn: int64(refreshTTL / time.Second),
+	}, claims, nil
+}
+
+// RefreshToken 用刷新令牌换取新的令牌对
+// 刷新令牌只能使用一次：Redis 中的登记被原子地取出并删除，重放旧令牌会失败
+func (u *UserServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (*types.AuthTokens, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidParams
+	}
+
+	claims, err := u.jwtUtil.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "validate refresh token failed: %v", err)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	key := fmt.Sprintf(constant.REDIS_REFRESH_TOKEN_KEY, claims.ID)
+	storedUserID, err := cache.GetDelRedis(ctx, key)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "load refresh token failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if storedUserID == "" || storedUserID != claims.UserID {
+		zlog.CtxWarnf(ctx, "refresh token revoked or reused for user: %s", claims.UserID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 用户被删除或禁用后不再续签
+	if _, err := u.GetUserByID(ctx, claims.UserID); err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrAccountSuspended) || errors.Is(err, ErrAccountPendingDeletion) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// 升级前签发的刷新令牌没有会话，续签时补建会话
+	if claims.SessionID == "" {
+		tokens, err := u.issueTokens(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		zlog.CtxInfof(ctx, "refresh token success for user: %s", claims.UserID)
+		return tokens, nil
+	}
+
+	// 会话被远程下线时，与之并发的续签也不能成功
+	session, err := u.sessionRepo.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "get user session failed: %v", err)
+		return nil, ErrInternalError
+	}
+	if session != nil && session.RevokedAt != nil {
+		zlog.CtxWarnf(ctx, "refresh token of revoked session: %s", claims.SessionID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tokens, refreshClaims, err := u.signTokens(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		if err := u.sessionRepo.RenewSession(ctx, claims.SessionID, refreshClaims.ID, time.Now(), refreshClaims.ExpiresAt.Time); err != nil {
+			zlog.CtxErrorf(ctx, "renew user session failed: %v", err)
+		}
+	}
+
+	zlog.CtxInfof(ctx, "refresh token success for user: %s", claims.UserID)
+	return tokens, nil
+}
+
+// RevokeRefreshToken 吊销刷新令牌，令牌无效或已过期时视为已吊销
+func (u *UserServiceImpl) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+
+	claims, err := u.jwtUtil.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		if errors.Is(err, util.ErrTokenExpired) {
+			return nil
+		}
+		return ErrInvalidRefreshToken
+	}
+
+	key := fmt.Sprintf(constant.REDIS_REFRESH_TOKEN_KEY, claims.ID)
+	if err := cache.DelRedis(ctx, key); err != nil {
+		zlog.CtxErrorf(ctx, "revoke refresh token failed: %v", err)
+		return ErrInternalError
+	}
+	// 退出登录即结束该会话
+	if claims.SessionID != "" {
+		if err := u.sessionRepo.RevokeSession(ctx, claims.SessionID, time.Now()); err != nil {
+			zlog.CtxErrorf(ctx, "end user session failed: %v", err)
+		}
+	}
+
+	zlog.CtxInfof(ctx, "refresh token revoked for user: %s", claims.UserID)
+	return nil
+}
+
+// RevokeAccessToken 将访问令牌的 jti 加入黑名单，过期时间与令牌一致，令牌过期后黑名单自动清除
+// 升级前签发的令牌没有 jti，无法单独吊销，只能等待过期
+func (u *UserServiceImpl) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf(constant.REDIS_TOKEN_BLACKLIST_KEY, tokenID)
+	if err := cache.SetRedis(ctx, key, "1", ttl); err != nil {
+		zlog.CtxErrorf(ctx, "blacklist access token failed: %v", err)
+		return ErrInternalError
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked 访问令牌是否在黑名单中，或其所属会话已被远程下线
+func (u *UserServiceImpl) IsAccessTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	if tokenID != "" {
+		key := fmt.Sprintf(constant.REDIS_TOKEN_BLACKLIST_KEY, tokenID)
+		value, err := cache.GetRedis(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		if value != "" {
+			return true, nil
+		}
+	}
+	if sessionID != "" {
+		key := fmt.Sprintf(constant.REDIS_SESSION_REVOKED_KEY, sessionID)
+		value, err := cache.GetRedis(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		if value != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,101 @@
+package userservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 用户展示信息的缓存时间，修改资料和头像时主动清除
+	userBriefCacheTTL = 10 * time.Minute
+	// 单次 IN 查询的最大用户数
+	userBatchSize = 500
+)
+
+// GetUsersByIDs 批量获取用户的公开展示信息：先读 Redis，未命中的用户一次 IN 查询后回填缓存
+// 缓存不可用时直接查询数据库
+func (u *UserServiceImpl) GetUsersByIDs(ctx context.Context, userIDs []string) (map[string]*entity.UserBrief, error) {
+	ids := uniqueUserIDs(userIDs)
+	briefs := make(map[string]*entity.UserBrief, len(ids))
+	if len(ids) == 0 {
+		return briefs, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf(constant.REDIS_USER_BRIEF_KEY, id)
+	}
+	cached, err := cache.MGetRedis(ctx, keys...)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "get user briefs from cache failed: %v", err)
+		cached = nil
+	}
+
+	missing := make([]string, 0, len(ids))
+	for i, id := range ids {
+		if cached != nil && cached[i] != "" {
+			var brief entity.UserBrief
+			if err := json.Unmarshal([]byte(cached[i]), &brief); err == nil {
+				briefs[id] = &brief
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return briefs, nil
+	}
+
+	toCache := make(map[string]string, len(missing))
+	for start := 0; start < len(missing); start += userBatchSize {
+		end := min(start+userBatchSize, len(missing))
+		users, err := u.userRepo.GetUsersByIDs(ctx, missing[start:end])
+		if err != nil {
+			zlog.CtxErrorf(ctx, "get users by ids failed: %v", err)
+			return nil, ErrInternalError
+		}
+		for _, user := range users {
+			brief := user.Brief()
+			briefs[user.UserID] = brief
+			if data, err := json.Marshal(brief); err == nil {
+				toCache[fmt.Sprintf(constant.REDIS_USER_BRIEF_KEY, user.UserID)] = string(data)
+			}
+		}
+	}
+	if err := cache.MSetRedis(ctx, toCache, userBriefCacheTTL); err != nil {
+		zlog.CtxWarnf(ctx, "cache user briefs failed: %v", err)
+	}
+	return briefs, nil
+}
+
+// invalidateUserBrief 用户名、昵称或头像修改后清除展示信息缓存
+func (u *UserServiceImpl) invalidateUserBrief(ctx context.Context, userID string) {
+	key := fmt.Sprintf(constant.REDIS_USER_BRIEF_KEY, userID)
+	if err := cache.DelRedis(ctx, key); err != nil {
+		zlog.CtxWarnf(ctx, "invalidate user brief cache failed: %v", err)
+	}
+}
+
+// uniqueUserIDs 去掉空值与重复的用户ID，保持原有顺序
+func uniqueUserIDs(userIDs []string) []string {
+	seen := make(map[string]struct{}, len(userIDs))
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
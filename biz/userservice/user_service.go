@@ -18,6 +18,7 @@ import (
 	"forge/biz/types"
 	"forge/constant"
 	"forge/infra/cache"
+	"forge/infra/configs"
 	"forge/pkg/log/zlog"
 	"forge/util"
 )
@@ -41,43 +42,159 @@ var (
 	ErrPermissionDenied = errors.New("permission denied")
 	// ErrVerificationCodeIncorrect 表示验证码错误
 	ErrVerificationCodeIncorrect = errors.New("verification code incorrect")
+	// ErrVerificationCodeExpired 表示验证码已过期或输错次数过多已作废，需要重新获取
+	ErrVerificationCodeExpired = errors.New("verification code expired")
 	// ErrAccountAlreadyInUse 表示账号（手机号/邮箱）已被使用
 	ErrAccountAlreadyInUse = errors.New("account already in use")
 	ErrEmailAlreadyInUse   = ErrAccountAlreadyInUse
 	// ErrPasswordRequired 表示密码必填
 	ErrPasswordRequired        = errors.New("password required")
 	ErrCannotUnbindOnlyContact = errors.New("cannot unbind only contact")
+	// ErrInvalidUnsubscribeToken 表示退订链接无效
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+	// ErrInvalidRefreshToken 表示刷新令牌无效、已过期或已被吊销
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrUnsupportedOAuthProvider 表示第三方登录平台不支持或未开启
+	ErrUnsupportedOAuthProvider = errors.New("unsupported oauth provider")
+	// ErrInvalidOAuthState 表示授权 state 无效或已过期
+	ErrInvalidOAuthState = errors.New("invalid oauth state")
+	// ErrOAuthCodeInvalid 表示授权码无效或已使用
+	ErrOAuthCodeInvalid = errors.New("oauth code invalid")
+	// ErrOAuthProvider 表示调用第三方平台失败
+	ErrOAuthProvider = errors.New("oauth provider error")
+	// ErrOAuthAlreadyBound 表示当前用户已绑定该平台的其他账号
+	ErrOAuthAlreadyBound = errors.New("oauth provider already bound")
+	// ErrTooManyLoginAttempts 表示登录失败次数过多，账号或来源IP暂时被锁定
+	ErrTooManyLoginAttempts = errors.New("too many login attempts")
+	// ErrVerificationCodeTooFrequent 表示验证码发送过于频繁，具体等待时间见 CodeRateLimitedError
+	ErrVerificationCodeTooFrequent = errors.New("verification code requested too frequently")
+	// ErrAccountPendingDeletion 表示账号已申请注销，重新登录可撤销
+	ErrAccountPendingDeletion = errors.New("account pending deletion")
+	// ErrSessionNotFound 表示登录会话不存在或不属于当前用户
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrInvalidRole 表示角色无效
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrCannotManageSelf 表示管理员不能禁用自己或修改自己的角色
+	ErrCannotManageSelf = errors.New("cannot manage self")
+	// ErrInvalidMagicLink 表示免密登录链接无效、已过期或已使用
+	ErrInvalidMagicLink = errors.New("invalid magic link")
+	// ErrPasswordReused 表示新密码与最近使用过的密码相同
+	ErrPasswordReused = errors.New("password recently used")
+	// ErrOldContactConfirmRequired 表示换绑需要原联系方式收到的确认码
+	ErrOldContactConfirmRequired = errors.New("old contact confirmation required")
+	// ErrAccountSuspended 账号已被管理员禁用
+	ErrAccountSuspended = errors.New("account suspended")
+	// ErrInviteCodeRequired 表示注册需要填写邀请码
+	ErrInviteCodeRequired = errors.New("invite code required")
+	// ErrInviteCodeInvalid 表示邀请码不存在、已过期或次数已用完
+	ErrInviteCodeInvalid = errors.New("invite code invalid")
+	// ErrInviteQuotaExceeded 表示用户生成的邀请码已达上限
+	ErrInviteQuotaExceeded = errors.New("invite code quota exceeded")
+	// ErrMergeSameAccount 表示待合并的账号就是当前账号
+	ErrMergeSameAccount = errors.New("cannot merge the same account")
 )
 
 // 最好的设计方案：
 // infra的所有函数都是通过接口来用的
 
 type UserServiceImpl struct {
-	userRepo    repo.UserRepo
-	cozeService adapter.CozeService
-	jwtUtil     *util.JWTUtil
-	codeService adapter.CodeService
+	userRepo     repo.UserRepo
+	identityRepo repo.UserIdentityRepo
+	sessionRepo  repo.UserSessionRepo
+	cozeService  adapter.CozeService
+	jwtUtil      *util.JWTUtil
+	codeService  adapter.CodeService
+	cozeConfig   configs.CozeConfig
+
+	reminderConfig   configs.ContactReminderConfig
+	loginLimitConfig configs.LoginLimitConfig
+	deletionConfig   configs.AccountDeletionConfig
+	magicLinkConfig  configs.MagicLinkConfig
+
+	passwordHistoryRepo   repo.PasswordHistoryRepo
+	passwordHistoryConfig configs.PasswordHistoryConfig
+	accountChangeConfig   configs.AccountChangeConfig
+	auditService          types.IAuditService
+	inviteRepo            repo.InviteRepo
+	inviteConfig          configs.InviteConfig
+	txManager             repo.TxManager
+	outboxService         types.IOutboxService
+	// 已开启的第三方登录平台，key 为平台名称
+	oauthProviders map[string]adapter.OAuthProvider
 }
 
 func NewUserServiceImpl(
 	userRepo repo.UserRepo,
 	cozeService adapter.CozeService,
 	jwtUtil *util.JWTUtil,
-	codeService adapter.CodeService) *UserServiceImpl {
+	codeService adapter.CodeService,
+	cozeConfig configs.CozeConfig,
+	reminderConfig configs.ContactReminderConfig,
+	identityRepo repo.UserIdentityRepo,
+	oauthProviders []adapter.OAuthProvider,
+	loginLimitConfig configs.LoginLimitConfig,
+	deletionConfig configs.AccountDeletionConfig,
+	sessionRepo repo.UserSessionRepo,
+	magicLinkConfig configs.MagicLinkConfig,
+	passwordHistoryRepo repo.PasswordHistoryRepo,
+	passwordHistoryConfig configs.PasswordHistoryConfig,
+	accountChangeConfig configs.AccountChangeConfig,
+	auditService types.IAuditService,
+	inviteRepo repo.InviteRepo,
+	inviteConfig configs.InviteConfig,
+	txManager repo.TxManager,
+	outboxService types.IOutboxService) *UserServiceImpl {
+	providers := make(map[string]adapter.OAuthProvider, len(oauthProviders))
+	for _, provider := range oauthProviders {
+		providers[provider.Name()] = provider
+	}
 	return &UserServiceImpl{
 		userRepo:    userRepo,
 		cozeService: cozeService,
 		jwtUtil:     jwtUtil,
 		codeService: codeService,
+		cozeConfig:  cozeConfig,
+
+		reminderConfig: reminderConfig,
+		identityRepo:   identityRepo,
+		oauthProviders: providers,
+
+		loginLimitConfig: loginLimitConfig,
+		deletionConfig:   deletionConfig,
+		sessionRepo:      sessionRepo,
+		magicLinkConfig:  magicLinkConfig,
+
+		passwordHistoryRepo:   passwordHistoryRepo,
+		passwordHistoryConfig: passwordHistoryConfig,
+		accountChangeConfig:   accountChangeConfig,
+		auditService:          auditService,
+		inviteRepo:            inviteRepo,
+		inviteConfig:          inviteConfig,
+		txManager:             txManager,
+		outboxService:         outboxService,
 	}
 }
 
+// codeConfig 验证码有效期与发送频率支持热更新，每次使用时读取当前配置
+func (u *UserServiceImpl) codeConfig() configs.VerificationCodeConfig {
+	return configs.Config().GetVerificationCodeConfig()
+}
+
 // Login 登录：根据账号和密码进行登录
-func (u *UserServiceImpl) Login(ctx context.Context, account, accountType, password string) (*entity.User, string, error) {
+func (u *UserServiceImpl) Login(ctx context.Context, account, accountType, password string) (*entity.User, *types.AuthTokens, error) {
 	// 参数校验
 	if account == "" || accountType == "" || password == "" {
 		zlog.CtxErrorf(ctx, "invalid params for login: account, accountType or password is empty")
-		return nil, "", ErrInvalidParams
+		return nil, nil, ErrInvalidParams
+	}
+	account, err := normalizeContact(ctx, account, accountType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 账号被锁定或来源IP失败次数过多时直接拒绝
+	if err := u.checkLoginAllowed(ctx, account, accountType); err != nil {
+		return nil, nil, err
 	}
 
 	// 根据账号类型查找用户
@@ -86,46 +203,44 @@ func (u *UserServiceImpl) Login(ctx context.Context, account, accountType, passw
 		// 如果用户不存在，返回错误
 		if errors.Is(err, ErrUserNotFound) {
 			zlog.CtxErrorf(ctx, "user not found: %s", account)
-			return nil, "", ErrCredentialsIncorrect
+			u.recordLoginFailure(ctx, account, accountType)
+			u.audit(ctx, "", entity.AuditActionLogin, accountType+": "+account, ErrUserNotFound)
+			return nil, nil, ErrCredentialsIncorrect
 		}
 		// 其他错误（数据库错误等）
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	// 验证密码
 	match, err := util.ComparePassword(user.Password, password)
 	if err != nil {
 		zlog.CtxErrorf(ctx, "compare password failed: %v", err)
-		return nil, "", ErrInternalError
+		return nil, nil, ErrInternalError
 	}
 	if !match {
 		zlog.CtxErrorf(ctx, "password incorrect for user: %s", user.UserID)
-		return nil, "", ErrCredentialsIncorrect
+		u.recordLoginFailure(ctx, account, accountType)
+		u.audit(ctx, user.UserID, entity.AuditActionLogin, "password", ErrCredentialsIncorrect)
+		return nil, nil, ErrCredentialsIncorrect
+	}
+	u.resetLoginFailures(ctx, account, accountType)
+
+	// 密码正确后再检查禁用状态，避免通过该接口探测账号状态
+	if err := u.checkUserStatus(ctx, user); err != nil {
+		u.audit(ctx, user.UserID, entity.AuditActionLogin, "password", err)
+		return nil, nil, err
 	}
 
-	// 生成JWT token
-	token, err := u.jwtUtil.GenerateToken(user.UserID)
+	// 冷静期内重新登录即撤销注销
+	if err := u.cancelPendingDeletion(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
+	// 签发访问令牌与刷新令牌
+	tokens, err := u.issueTokens(ctx, user.UserID)
 	if err != nil {
-		zlog.CtxErrorf(ctx, "generate token failed: %v", err)
-		return nil, "", ErrInternalError
-	}
-
-	// 方法一  通过注入的 cozeService 接口调用
-	//result, err := u.cozeService.RunWorkflow(ctx, &adapter.RunWorkflowReq{})
-	//if err != nil {
-	//	zlog.CtxErrorf(ctx, "run workflow failed: %v", err)
-	//} else {
-	//	zlog.CtxInfof(ctx, "result:%v", result)
-	//}
-
-	// 方法二
-	// result, err = coze.GetCozeService().RunWorkflow(ctx, &adapter.RunWorkflowReq{})
-	// if err != nil {
-	// 	zlog.CtxErrorf(ctx, "run workflow failed: %v", err)
-	// 	return nil, "", err
-	// }
-	// zlog.CtxInfof(ctx, "result:%v", result)
-	// ============================================================
+		return nil, nil, err
+	}
 
 	// 更新最后登录时间（可选）
 	// lastLoginAt := time.Now()
@@ -135,8 +250,9 @@ func (u *UserServiceImpl) Login(ctx context.Context, account, accountType, passw
 	// }
 	// _ = u.userRepo.UpdateUser(ctx, updateInfo)
 
+	u.audit(ctx, user.UserID, entity.AuditActionLogin, "password", nil)
 	zlog.CtxInfof(ctx, "login success for user: %s", user.UserID)
-	return user, token, nil
+	return user, tokens, nil
 }
 
 // Register 基于手机号/邮箱进行注册
@@ -146,6 +262,19 @@ func (u *UserServiceImpl) Register(ctx context.Context, req *types.RegisterParam
 		zlog.CtxErrorf(ctx, "invalid params for register")
 		return nil, ErrInvalidParams
 	}
+	account, err := normalizeContact(ctx, req.Account, req.AccountType)
+	if err != nil {
+		return nil, err
+	}
+	req.Account = account
+
+	// 用户名注册时先校验格式，格式不合法的用户名无法用于登录
+	if req.AccountType == types.AccountTypeUsername {
+		if err := util.ValidateUserName(req.Account); err != nil {
+			zlog.CtxWarnf(ctx, "invalid username for register: %s", req.Account)
+			return nil, err
+		}
+	}
 
 	// 检查账号是否已存在
 	existUser, err := u.findUserByAccount(ctx, req.Account, req.AccountType)
@@ -159,21 +288,23 @@ func (u *UserServiceImpl) Register(ctx context.Context, req *types.RegisterParam
 		}
 	} else if existUser != nil {
 		// 用户已存在，返回错误
-		var accountField string
-		if req.AccountType == types.AccountTypePhone {
-			accountField = "phone"
-		} else {
-			accountField = "email"
-		}
-		zlog.CtxErrorf(ctx, "%s already registered: %s", accountField, req.Account)
+		zlog.CtxErrorf(ctx, "%s already registered: %s", req.AccountType, req.Account)
 		return nil, ErrUserAlreadyExists
 	}
 
-	// 校验验证码 code（短信/邮箱）
-	if err := u.VerifyCode(ctx, req.Account, req.AccountType, req.Code); err != nil {
+	// 先检查邀请码，避免邀请码无效时消耗掉验证码
+	invite, err := u.findRegisterInviteCode(ctx, req.InviteCode)
+	if err != nil {
 		return nil, err
 	}
 
+	// 校验验证码 code（短信/邮箱），用户名注册没有可接收验证码的联系方式
+	if req.AccountType != types.AccountTypeUsername {
+		if err := u.VerifyCode(ctx, req.Account, req.AccountType, req.Code); err != nil {
+			return nil, err
+		}
+	}
+
 	//------------------------------------------------
 
 	// 验证密码强度  按照常规要求设置
@@ -210,15 +341,55 @@ func (u *UserServiceImpl) Register(ctx context.Context, req *types.RegisterParam
 	case types.AccountTypeEmail:
 		user.Email = req.Account
 		user.EmailVerified = true
+	case types.AccountTypeUsername:
+		user.UserName = req.Account
 	}
 
-	if err := u.userRepo.CreateUser(ctx, user); err != nil {
+	// 扣减邀请码、创建用户、记录邀请关系与注册事件在同一个事务中，创建失败时邀请码次数一并回滚
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.useInviteCode(ctx, invite); err != nil {
+			return err
+		}
+		if err := u.userRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		u.recordPasswordHistory(ctx, user.UserID, hash)
+		u.recordReferral(ctx, invite, user.UserID)
+
+		event := &entity.UserRegisteredEvent{UserID: user.UserID, AccountType: req.AccountType, RegisteredAt: time.Now()}
+		if invite != nil {
+			event.InviterID = invite.UserID
+		}
+		if err := u.outboxService.Record(ctx, entity.EventUserRegistered, user.UserID, event); err != nil {
+			zlog.CtxErrorf(ctx, "record user registered event failed: %v", err)
+			return ErrInternalError
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// 异步执行注册后的资料补全工作流，不影响注册结果
+	u.enrichRegisteredUser(ctx, user, req.AccountType)
+
 	return user, nil
 }
 
+// normalizeContact 校验手机号并转换为存储格式（大陆号码不带区号，其他地区为 E.164），
+// 邮箱、用户名原样返回；查找、发送验证码与保存前都需要先统一格式
+func normalizeContact(ctx context.Context, account, accountType string) (string, error) {
+	if accountType != types.AccountTypePhone {
+		return account, nil
+	}
+	phone, err := util.NormalizePhone(account)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "invalid phone number: %s", account)
+		return "", err
+	}
+	return phone, nil
+}
+
 // findUserByAccount 根据账号类型查找用户 抽离重复判断逻辑
 // 返回值说明：
 //   - 如果返回错误不为nil，表示数据库查询出错（内部错误）或账号类型不支持
@@ -235,6 +406,14 @@ func (u *UserServiceImpl) findUserByAccount(ctx context.Context, account, accoun
 	case types.AccountTypeEmail:
 		query = repo.NewUserQueryByEmail(account)
 		accountField = "email"
+	case types.AccountTypeUsername:
+		// 早期用户的用户名只是展示用的昵称，可能重复或含中文；
+		// 只有符合规则的用户名才参与查找，注册时据此保证用户名唯一
+		if util.ValidateUserName(account) != nil {
+			return nil, ErrUserNotFound
+		}
+		query = repo.NewUserQueryByUserName(account)
+		accountField = "username"
 	default:
 		zlog.CtxErrorf(ctx, "unsupported accountType: %s", accountType)
 		return nil, ErrUnsupportedAccountType
@@ -255,6 +434,23 @@ func (u *UserServiceImpl) findUserByAccount(ctx context.Context, account, accoun
 	return user, nil
 }
 
+// passwordHash 用户的密码哈希，没有设置密码时为空
+// 从缓存读取的用户不含哈希，按用户ID重新查询数据库
+func (u *UserServiceImpl) passwordHash(ctx context.Context, user *entity.User) (string, error) {
+	if user.Password != "" || !user.PasswordSet {
+		return user.Password, nil
+	}
+	stored, err := u.userRepo.GetUser(ctx, repo.UserQuery{UserID: user.UserID, WithPassword: true})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get password of user %s: %v", user.UserID, err)
+		return "", ErrInternalError
+	}
+	if stored == nil {
+		return "", ErrUserNotFound
+	}
+	return stored.Password, nil
+}
+
 // ResetPassword 重置密码
 func (u *UserServiceImpl) ResetPassword(ctx context.Context, req *types.ResetPasswordParams) error {
 	// 参数校验
@@ -266,6 +462,11 @@ func (u *UserServiceImpl) ResetPassword(ctx context.Context, req *types.ResetPas
 		zlog.CtxErrorf(ctx, "invalid params for reset password: missing required fields")
 		return ErrInvalidParams
 	}
+	account, err := normalizeContact(ctx, req.Account, req.AccountType)
+	if err != nil {
+		return err
+	}
+	req.Account = account
 
 	// 校验两次密码一致性
 	if req.NewPassword != req.ConfirmPassword {
@@ -281,6 +482,7 @@ func (u *UserServiceImpl) ResetPassword(ctx context.Context, req *types.ResetPas
 
 	// 校验验证码 code（短信/邮箱）
 	if err := u.VerifyCode(ctx, req.Account, req.AccountType, req.Code); err != nil {
+		u.audit(ctx, user.UserID, entity.AuditActionResetPassword, req.AccountType, err)
 		return err
 	}
 
@@ -289,6 +491,10 @@ func (u *UserServiceImpl) ResetPassword(ctx context.Context, req *types.ResetPas
 		zlog.CtxErrorf(ctx, "password strength validation failed: %v", err)
 		return err
 	}
+	// 不能使用最近用过的密码
+	if err := u.checkPasswordReuse(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
 
 	// 加密新密码
 	hash, err := util.HashPassword(req.NewPassword)
@@ -307,6 +513,8 @@ func (u *UserServiceImpl) ResetPassword(ctx context.Context, req *types.ResetPas
 		zlog.CtxErrorf(ctx, "update password failed: %v", err)
 		return ErrInternalError
 	}
+	u.recordPasswordHistory(ctx, user.UserID, hash)
+	u.audit(ctx, user.UserID, entity.AuditActionResetPassword, req.AccountType, nil)
 
 	zlog.CtxInfof(ctx, "reset password successfully for user: %s", user.UserID)
 	return nil
@@ -318,7 +526,21 @@ func (u *UserServiceImpl) GetVersion(ctx context.Context, req *types.GetVersionP
 }
 
 // GetUserByID 根据用户ID获取用户信息（用于JWT鉴权等场景）
+// 已申请注销的用户视为不可用，需要重新登录撤销注销后才能继续使用
 func (u *UserServiceImpl) GetUserByID(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt != nil {
+		zlog.CtxWarnf(ctx, "user is pending deletion: %s", userID)
+		return nil, ErrAccountPendingDeletion
+	}
+	return user, nil
+}
+
+// loadUser 查询用户并检查状态，不检查注销状态
+func (u *UserServiceImpl) loadUser(ctx context.Context, userID string) (*entity.User, error) {
 	// 参数校验
 	if userID == "" {
 		zlog.CtxErrorf(ctx, "userID is required")
@@ -339,9 +561,8 @@ func (u *UserServiceImpl) GetUserByID(ctx context.Context, userID string) (*enti
 	}
 
 	// 检查用户状态（业务逻辑应该在service层）
-	if user.Status != entity.UserStatusActive {
-		zlog.CtxWarnf(ctx, "user is disabled: %s", userID)
-		return nil, ErrPermissionDenied
+	if err := u.checkUserStatus(ctx, user); err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -354,6 +575,21 @@ func (u *UserServiceImpl) SendVerificationCode(ctx context.Context, account, acc
 		zlog.CtxErrorf(ctx, "invalid params for send verification code")
 		return ErrInvalidParams
 	}
+	account, err := normalizeContact(ctx, account, accountType)
+	if err != nil {
+		return err
+	}
+
+	// 用户名没有可接收验证码的联系方式
+	if accountType == types.AccountTypeUsername {
+		zlog.CtxErrorf(ctx, "verification code is not supported for username")
+		return ErrUnsupportedAccountType
+	}
+
+	// 先限流再校验账号，避免通过该接口高频探测账号是否注册
+	if err := u.checkCodeSendLimit(ctx, account); err != nil {
+		return err
+	}
 
 	// 根据使用场景进行账号验证
 	// 注册 换绑需要提供未被使用的账号   重置密码需要提供用户自己的 存在的账号
@@ -402,6 +638,32 @@ func (u *UserServiceImpl) SendVerificationCode(ctx context.Context, account, acc
 			return err
 		}
 
+	case types.PurposeDeleteAccount, types.PurposeConfirmChangeAccount:
+		// 注销账号、换绑确认场景：只能向当前用户自己的手机号/邮箱发送验证码
+		currentUser, ok := entity.GetUser(ctx)
+		if !ok {
+			zlog.CtxErrorf(ctx, "user not found in context for %s", purpose)
+			return ErrPermissionDenied
+		}
+		if !isOwnContact(currentUser, account, accountType) {
+			zlog.CtxWarnf(ctx, "account %s is not bound to user %s", account, currentUser.UserID)
+			return ErrPermissionDenied
+		}
+
+	case types.PurposeMergeAccount:
+		// 合并账号场景：发送到当前用户自己的联系方式，或其他已注册账号的联系方式
+		currentUser, ok := entity.GetUser(ctx)
+		if !ok {
+			zlog.CtxErrorf(ctx, "user not found in context for merge account")
+			return ErrPermissionDenied
+		}
+		if !isOwnContact(currentUser, account, accountType) {
+			if _, err := u.findUserByAccount(ctx, account, accountType); err != nil {
+				zlog.CtxWarnf(ctx, "account to merge not found: %s (type: %s)", account, accountType)
+				return err
+			}
+		}
+
 	default:
 		// 未指定场景或未知场景，不进行验证（向后兼容）
 		zlog.CtxWarnf(ctx, "unknown purpose for send verification code: %s, skipping validation", purpose)
@@ -412,12 +674,15 @@ func (u *UserServiceImpl) SendVerificationCode(ctx context.Context, account, acc
 
 	// 先将验证码存储到 Redis，并设置过期时间
 	key := fmt.Sprintf(constant.REDIS_VERIFICATION_CODE_KEY, account)
-	// TODO: 建议将过期时间（10分钟）配置化
-	expiration := 10 * time.Minute
-	if err := cache.SetRedis(ctx, key, code, expiration); err != nil {
+	if err := cache.SetRedis(ctx, key, code, u.codeConfig().Expiration()); err != nil {
 		zlog.CtxErrorf(ctx, "存储验证码到Redis失败: %v", err)
 		return ErrInternalError
 	}
+	// 新验证码重新计算输错次数
+	attemptsKey := fmt.Sprintf(constant.REDIS_CODE_ATTEMPTS_KEY, account)
+	if err := cache.DelRedis(ctx, attemptsKey); err != nil {
+		zlog.CtxErrorf(ctx, "重置验证码输错次数失败: %v", err)
+	}
 
 	var (
 		sendFunc func(context.Context, string, string) error
@@ -426,7 +691,14 @@ func (u *UserServiceImpl) SendVerificationCode(ctx context.Context, account, acc
 
 	switch accountType {
 	case types.AccountTypeEmail:
-		sendFunc = u.codeService.SendEmailCode
+		sendFunc = func(ctx context.Context, email, code string) error {
+			return u.codeService.SendEmailCode(ctx, email, &adapter.VerificationEmail{
+				Code:      code,
+				Purpose:   purpose,
+				Language:  entity.GetLanguage(ctx),
+				ExpiresIn: u.codeConfig().Expiration(),
+			})
+		}
 		errorLog = "send verification code failed"
 	case types.AccountTypePhone:
 		sendFunc = u.codeService.SendSMSCode
@@ -441,6 +713,13 @@ func (u *UserServiceImpl) SendVerificationCode(ctx context.Context, account, acc
 		if delErr := cache.DelRedis(ctx, key); delErr != nil {
 			zlog.CtxErrorf(ctx, "删除Redis中未发送成功的验证码失败: %v", delErr)
 		}
+		// 服务商明确拒绝的号码与频率限制需要告诉用户，其他错误统一返回内部错误
+		if errors.Is(err, adapter.ErrSMSInvalidPhone) {
+			return ErrInvalidParams
+		}
+		if errors.Is(err, adapter.ErrSMSRateLimited) {
+			return &CodeRateLimitedError{RetryAfter: time.Minute}
+		}
 		return ErrInternalError
 	}
 
@@ -463,11 +742,32 @@ func (u *UserServiceImpl) VerifyCode(ctx context.Context, account, accountType,
 
 	if storedCode == "" {
 		zlog.CtxWarnf(ctx, "verification code not found or expired for: %s", account)
-		return ErrVerificationCodeIncorrect
+		return ErrVerificationCodeExpired
 	}
 
+	attemptsKey := fmt.Sprintf(constant.REDIS_CODE_ATTEMPTS_KEY, account)
 	if storedCode != code {
 		zlog.CtxWarnf(ctx, "verification code mismatch for: %s", account)
+		// 6位验证码可以被穷举，输错达到上限后作废，必须重新获取
+		// 无法计数时按失败处理并尽量作废验证码，否则 Redis 故障期间可以无限次尝试
+		attempts, err := cache.IncrRedis(ctx, attemptsKey, u.codeConfig().Expiration())
+		if err != nil {
+			zlog.CtxErrorf(ctx, "incr verification code attempts failed: %v", err)
+			if err := cache.DelRedis(ctx, key); err != nil {
+				zlog.CtxErrorf(ctx, "delete verification code from redis failed: %v", err)
+			}
+			return ErrInternalError
+		}
+		if attempts >= u.codeConfig().MaxAttemptCount() {
+			zlog.CtxWarnf(ctx, "verification code invalidated after %d attempts for: %s", attempts, account)
+			if err := cache.DelRedis(ctx, key); err != nil {
+				zlog.CtxErrorf(ctx, "delete verification code from redis failed: %v", err)
+			}
+			if err := cache.DelRedis(ctx, attemptsKey); err != nil {
+				zlog.CtxErrorf(ctx, "delete verification code attempts failed: %v", err)
+			}
+			return ErrVerificationCodeExpired
+		}
 		return ErrVerificationCodeIncorrect
 	}
 
@@ -476,6 +776,9 @@ func (u *UserServiceImpl) VerifyCode(ctx context.Context, account, accountType,
 		zlog.CtxErrorf(ctx, "delete verification code from redis failed: %v", err)
 		// 不返回错误，因为验证码已经校验成功
 	}
+	if err := cache.DelRedis(ctx, attemptsKey); err != nil {
+		zlog.CtxErrorf(ctx, "delete verification code attempts failed: %v", err)
+	}
 
 	return nil
 }
@@ -518,6 +821,11 @@ func (u *UserServiceImpl) UpdateAccount(ctx context.Context, req *types.UpdateAc
 		zlog.CtxErrorf(ctx, "invalid params for update account: missing required fields")
 		return "", ErrInvalidParams
 	}
+	account, err := normalizeContact(ctx, req.Account, req.AccountType)
+	if err != nil {
+		return "", err
+	}
+	req.Account = account
 
 	// 从context获取当前用户（JWT中间件已注入）
 	currentUser, ok := entity.GetUser(ctx)
@@ -527,14 +835,21 @@ func (u *UserServiceImpl) UpdateAccount(ctx context.Context, req *types.UpdateAc
 	}
 
 	// 判断用户是否有密码
-	hasPassword := currentUser.Password != ""
+	hasPassword := currentUser.HasPassword()
 	if !hasPassword && req.Password == "" {
 		zlog.CtxErrorf(ctx, "password required for user without password: %s", currentUser.UserID)
 		return "", ErrPasswordRequired
 	}
 
+	// 开启换绑确认时，先校验发送到原联系方式的确认码
+	if err := u.confirmOldContact(ctx, currentUser, req); err != nil {
+		u.audit(ctx, currentUser.UserID, entity.AuditActionUpdateAccount, req.AccountType+": "+req.Account, err)
+		return "", err
+	}
+
 	// 验证验证码（验证发送到新联系方式的验证码）
 	if err := u.VerifyCode(ctx, req.Account, req.AccountType, req.Code); err != nil {
+		u.audit(ctx, currentUser.UserID, entity.AuditActionUpdateAccount, req.AccountType+": "+req.Account, err)
 		return "", err
 	}
 
@@ -569,6 +884,9 @@ func (u *UserServiceImpl) UpdateAccount(ctx context.Context, req *types.UpdateAc
 			zlog.CtxErrorf(ctx, "password strength validation failed: %v", err)
 			return "", err
 		}
+		if err := u.checkPasswordReuse(ctx, currentUser, req.Password); err != nil {
+			return "", err
+		}
 
 		// 加密密码
 		hash, err := util.HashPassword(req.Password)
@@ -584,11 +902,40 @@ func (u *UserServiceImpl) UpdateAccount(ctx context.Context, req *types.UpdateAc
 		zlog.CtxErrorf(ctx, "update account failed: %v", err)
 		return "", ErrInternalError
 	}
+	if updateInfo.Password != nil {
+		u.recordPasswordHistory(ctx, currentUser.UserID, *updateInfo.Password)
+	}
 
+	u.audit(ctx, currentUser.UserID, entity.AuditActionUpdateAccount, req.AccountType+": "+req.Account, nil)
 	zlog.CtxInfof(ctx, "account updated successfully, userID: %s, new account: %s", currentUser.UserID, req.Account)
 	return req.Account, nil
 }
 
+// confirmOldContact 开启换绑确认时，已绑定同类联系方式的用户需要提供原联系方式收到的确认码
+// 首次绑定或重新验证当前联系方式时不需要确认
+func (u *UserServiceImpl) confirmOldContact(ctx context.Context, currentUser *entity.User, req *types.UpdateAccountParams) error {
+	if !u.accountChangeConfig.ConfirmOldContact {
+		return nil
+	}
+
+	var oldContact string
+	switch req.AccountType {
+	case types.AccountTypePhone:
+		oldContact = currentUser.Phone
+	case types.AccountTypeEmail:
+		oldContact = currentUser.Email
+	}
+	if oldContact == "" || oldContact == req.Account {
+		return nil
+	}
+
+	if req.OldCode == "" {
+		zlog.CtxWarnf(ctx, "old %s confirmation required for update account, userID: %s", req.AccountType, currentUser.UserID)
+		return ErrOldContactConfirmRequired
+	}
+	return u.VerifyCode(ctx, oldContact, req.AccountType, req.OldCode)
+}
+
 // UnbindAccount 解绑联系方式（手机号/邮箱）
 func (u *UserServiceImpl) UnbindAccount(ctx context.Context, req *types.UnbindAccountParams) error {
 	// 参数校验
@@ -600,6 +947,11 @@ func (u *UserServiceImpl) UnbindAccount(ctx context.Context, req *types.UnbindAc
 		zlog.CtxErrorf(ctx, "invalid params for unbind account: missing required fields")
 		return ErrInvalidParams
 	}
+	account, err := normalizeContact(ctx, req.Account, req.AccountType)
+	if err != nil {
+		return err
+	}
+	req.Account = account
 
 	// 获取当前用户
 	currentUser, ok := entity.GetUser(ctx)
@@ -643,9 +995,19 @@ func (u *UserServiceImpl) UnbindAccount(ctx context.Context, req *types.UnbindAc
 		zlog.CtxErrorf(ctx, "%s mismatch for unbind, userID: %s, request %s: %s", accountLabel, currentUser.UserID, accountLabel, req.Account)
 		return ErrInvalidParams
 	}
-	if otherContact == "" {
-		zlog.CtxErrorf(ctx, "cannot unbind %s, no other contact bound, userID: %s", accountLabel, currentUser.UserID)
-		return ErrCannotUnbindOnlyContact
+	// 设置了密码且用户名符合规则时，解绑后仍可使用用户名登录
+	canLoginByUserName := currentUser.HasPassword() && util.ValidateUserName(currentUser.UserName) == nil
+	if otherContact == "" && !canLoginByUserName {
+		// 绑定了第三方账号时仍可通过第三方登录
+		hasIdentity, err := u.hasOAuthIdentity(ctx, currentUser.UserID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "list user identities failed: %v", err)
+			return ErrInternalError
+		}
+		if !hasIdentity {
+			zlog.CtxErrorf(ctx, "cannot unbind %s, no other contact bound, userID: %s", accountLabel, currentUser.UserID)
+			return ErrCannotUnbindOnlyContact
+		}
 	}
 
 	if req.AccountType == types.AccountTypePhone {
@@ -661,6 +1023,7 @@ func (u *UserServiceImpl) UnbindAccount(ctx context.Context, req *types.UnbindAc
 		return ErrInternalError
 	}
 
+	u.audit(ctx, currentUser.UserID, entity.AuditActionUnbindAccount, req.AccountType+": "+req.Account, nil)
 	zlog.CtxInfof(ctx, "account unbound successfully, userID: %s, accountType: %s", currentUser.UserID, req.AccountType)
 	return nil
 }
@@ -706,7 +1069,9 @@ func (u *UserServiceImpl) UpdateAvatar(ctx context.Context, userID, avatarURL st
 		zlog.CtxErrorf(ctx, "update avatar failed: %v", err)
 		return ErrInternalError
 	}
+	u.invalidateUserBrief(ctx, userID)
 
+	u.audit(ctx, userID, entity.AuditActionUpdateAvatar, "", nil)
 	zlog.CtxInfof(ctx, "update avatar successfully for user: %s", userID)
 	return nil
 }
@@ -757,8 +1122,10 @@ func validateAvatarURL(ctx context.Context, avatarURL string) error {
 			return fmt.Errorf("invalid URL: private/internal IP addresses are not allowed for security reasons")
 		}
 	} else {
-		// 如果是域名，解析为 IP 并检查
-		ips, err := net.LookupIP(host)
+		// 如果是域名，解析为 IP 并检查；DNS 卡住时不能拖住整个请求
+		lookupCtx, cancel := context.WithTimeout(ctx, configs.Config().GetTimeoutConfig().DNS())
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(lookupCtx, host)
 		if err != nil {
 			// 域名解析失败，拒绝URL（可能是恶意域名或网络问题）
 			zlog.CtxErrorf(ctx, "failed to resolve host %s: %v", host, err)
@@ -766,12 +1133,12 @@ func validateAvatarURL(ctx context.Context, avatarURL string) error {
 		}
 
 		// 检查所有解析出的 IP 地址
-		if len(ips) == 0 {
+		if len(addrs) == 0 {
 			return fmt.Errorf("invalid URL: host %s resolves to no IP addresses", host)
 		}
 
-		for _, resolvedIP := range ips {
-			if isPrivateIP(resolvedIP) {
+		for _, addr := range addrs {
+			if isPrivateIP(addr.IP) {
 				return fmt.Errorf("invalid URL: host %s resolves to private/internal IP address", host)
 			}
 		}
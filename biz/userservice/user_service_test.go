@@ -0,0 +1,90 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"forge/constant"
+	"forge/infra/cache/cachetest"
+	"forge/pkg/log/zlog"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	zlog.InitLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+func TestVerifyCode(t *testing.T) {
+	const account = "user@example.com"
+	codeKey := fmt.Sprintf(constant.REDIS_VERIFICATION_CODE_KEY, account)
+	attemptsKey := fmt.Sprintf(constant.REDIS_CODE_ATTEMPTS_KEY, account)
+	ctx := context.Background()
+	u := &UserServiceImpl{}
+
+	t.Run("验证码正确", func(t *testing.T) {
+		redis := cachetest.Start(t)
+		redis.Set(codeKey, "123456")
+		redis.Set(attemptsKey, "2")
+
+		if err := u.VerifyCode(ctx, account, "email", "123456"); err != nil {
+			t.Fatalf("VerifyCode() error = %v", err)
+		}
+		// 验证码只能使用一次，错误次数一并清除
+		if _, ok := redis.Get(codeKey); ok {
+			t.Error("code still exists after successful verification")
+		}
+		if _, ok := redis.Get(attemptsKey); ok {
+			t.Error("attempts still exist after successful verification")
+		}
+	})
+
+	t.Run("验证码不存在", func(t *testing.T) {
+		cachetest.Start(t)
+		if err := u.VerifyCode(ctx, account, "email", "123456"); !errors.Is(err, ErrVerificationCodeExpired) {
+			t.Errorf("VerifyCode() error = %v, want ErrVerificationCodeExpired", err)
+		}
+	})
+
+	t.Run("输错达到上限后作废", func(t *testing.T) {
+		redis := cachetest.Start(t)
+		redis.Set(codeKey, "123456")
+		maxAttempts := int(u.codeConfig().MaxAttemptCount())
+
+		for i := 1; i < maxAttempts; i++ {
+			if err := u.VerifyCode(ctx, account, "email", "000000"); !errors.Is(err, ErrVerificationCodeIncorrect) {
+				t.Fatalf("attempt %d: VerifyCode() error = %v, want ErrVerificationCodeIncorrect", i, err)
+			}
+		}
+		if redis.TTL(attemptsKey) <= 0 {
+			t.Error("attempts counter has no expiration")
+		}
+		if err := u.VerifyCode(ctx, account, "email", "000000"); !errors.Is(err, ErrVerificationCodeExpired) {
+			t.Fatalf("last attempt: VerifyCode() error = %v, want ErrVerificationCodeExpired", err)
+		}
+		if _, ok := redis.Get(codeKey); ok {
+			t.Error("code still exists after too many attempts")
+		}
+		// 作废后即使输入正确的验证码也需要重新获取
+		if err := u.VerifyCode(ctx, account, "email", "123456"); !errors.Is(err, ErrVerificationCodeExpired) {
+			t.Errorf("VerifyCode() after invalidation error = %v, want ErrVerificationCodeExpired", err)
+		}
+	})
+
+	t.Run("无法计数时作废验证码", func(t *testing.T) {
+		redis := cachetest.Start(t)
+		redis.Set(codeKey, "123456")
+		redis.Fail("INCR", errors.New("ERR injected"))
+
+		if err := u.VerifyCode(ctx, account, "email", "000000"); !errors.Is(err, ErrInternalError) {
+			t.Fatalf("VerifyCode() error = %v, want ErrInternalError", err)
+		}
+		if _, ok := redis.Get(codeKey); ok {
+			t.Error("code still exists after attempts counter failed")
+		}
+	})
+}
@@ -8,7 +8,7 @@ import (
 
 func main() {
 	initalize.Init()
-	// 释放资源 todo优雅退出
+	// 收到退出信号并处理完请求后释放资源
 	defer initalize.Eve()
 	router.RunServer()
 	zlog.Infof("server is done")
@@ -4,10 +4,64 @@ package constant
 const (
 	DEFAULT_CONFIG_FILE_PATH = "/conf/config.yaml"
 	LOGID                    = "log_id"
+	TRACEID                  = "trace_id"
 )
 
 // Redis Key 常量
 const (
 	// REDIS_VERIFICATION_CODE_KEY 验证码 Redis key
 	REDIS_VERIFICATION_CODE_KEY = "verification_code:%s"
+	// REDIS_AI_DAILY_USAGE_KEY 每日AI调用次数 Redis key，参数为用户ID与日期
+	REDIS_AI_DAILY_USAGE_KEY = "quota:ai:day:%s:%s"
+	// REDIS_AI_MINUTE_USAGE_KEY 每分钟AI调用次数 Redis key，参数为用户ID与分钟
+	REDIS_AI_MINUTE_USAGE_KEY = "quota:ai:minute:%s:%s"
+	// REDIS_AI_INFLIGHT_KEY 进行中的AI生成任务 Redis key（有序集合），参数为用户ID
+	REDIS_AI_INFLIGHT_KEY = "quota:ai:inflight:%s"
+	// REDIS_REFRESH_TOKEN_KEY 已签发且未吊销的刷新令牌 Redis key，参数为令牌ID（jti），值为用户ID
+	REDIS_REFRESH_TOKEN_KEY = "auth:refresh:%s"
+	// REDIS_TOKEN_BLACKLIST_KEY 已退出登录、尚未过期的访问令牌 Redis key，参数为令牌ID（jti）
+	REDIS_TOKEN_BLACKLIST_KEY = "auth:blacklist:%s"
+	// REDIS_SESSION_REVOKED_KEY 被远程下线的登录会话 Redis key，参数为会话ID，保留到会话内最后一个访问令牌过期
+	REDIS_SESSION_REVOKED_KEY = "auth:session_revoked:%s"
+	// REDIS_OAUTH_STATE_KEY 第三方授权 state Redis key，参数为 state，值为 平台:用途:用户ID
+	REDIS_OAUTH_STATE_KEY = "oauth:state:%s"
+	// REDIS_MAGIC_LINK_KEY 已发送且未使用的免密登录链接 Redis key，参数为令牌ID（jti），值为用户ID
+	REDIS_MAGIC_LINK_KEY = "auth:magic_link:%s"
+	// REDIS_CAPTCHA_KEY 图形验证码答案 Redis key，参数为验证码ID
+	REDIS_CAPTCHA_KEY = "captcha:answer:%s"
+	// REDIS_CAPTCHA_HITS_KEY 来源IP请求受保护接口的次数 Redis key，参数为接口与IP
+	REDIS_CAPTCHA_HITS_KEY = "captcha:hits:%s:%s"
+	// REDIS_LOGIN_FAIL_ACCOUNT_KEY 账号登录失败次数 Redis key，参数为账号类型与账号
+	REDIS_LOGIN_FAIL_ACCOUNT_KEY = "auth:login_fail:account:%s:%s"
+	// REDIS_LOGIN_FAIL_IP_KEY 来源IP登录失败次数 Redis key，参数为IP
+	REDIS_LOGIN_FAIL_IP_KEY = "auth:login_fail:ip:%s"
+	// REDIS_LOGIN_LOCK_KEY 账号登录锁定 Redis key，参数为账号类型与账号
+	REDIS_LOGIN_LOCK_KEY = "auth:login_lock:%s:%s"
+	// REDIS_CODE_SEND_ACCOUNT_KEY 账号验证码发送次数 Redis key，参数为统计窗口（minute/hour）与账号
+	REDIS_CODE_SEND_ACCOUNT_KEY = "verification_code:send:account:%s:%s"
+	// REDIS_CODE_SEND_IP_KEY 来源IP验证码发送次数 Redis key，参数为统计窗口（minute/hour）与IP
+	REDIS_CODE_SEND_IP_KEY = "verification_code:send:ip:%s:%s"
+	// REDIS_CODE_ATTEMPTS_KEY 当前验证码输错次数 Redis key，参数为账号
+	REDIS_CODE_ATTEMPTS_KEY = "verification_code:attempts:%s"
+	// REDIS_USER_BRIEF_KEY 用户公开展示信息缓存 Redis key，参数为用户ID，值为 JSON
+	REDIS_USER_BRIEF_KEY = "user:brief:%s"
+	// REDIS_USER_CACHE_KEY 用户信息缓存 Redis key，参数为用户ID，值为去掉密码哈希的用户记录 JSON
+	// 旧格式的缓存含密码哈希，换用新的 key，旧 key 到期后自动清除
+	REDIS_USER_CACHE_KEY = "user:cached:%s"
+	// REDIS_DATA_EXPORT_KEY 用户数据导出进度 Redis key，参数为用户ID，值为导出状态的 JSON
+	REDIS_DATA_EXPORT_KEY = "user:export:%s"
+	// REDIS_GENERATE_MINDMAP_CACHE_KEY 生成导图结果缓存 Redis key，参数为用户ID与输入文本的哈希，值为导图 JSON
+	REDIS_GENERATE_MINDMAP_CACHE_KEY = "aichat:generate:%s:%s"
+	// REDIS_MINDMAP_SHARE_FAIL_KEY 来源IP访问分享链接时密码错误次数 Redis key，参数为分享ID与IP
+	REDIS_MINDMAP_SHARE_FAIL_KEY = "mindmap:share:password_fail:%s:%s"
+	// REDIS_RATE_LIMIT_KEY 接口限流令牌桶 Redis key（哈希），参数为路由组与来源IP或用户ID
+	REDIS_RATE_LIMIT_KEY = "ratelimit:%s:%s"
+	// REDIS_JOB_LOCK_KEY 后台任务的分布式锁 Redis key，参数为任务名称，值为持有者令牌
+	REDIS_JOB_LOCK_KEY = "lock:job:%s"
+	// REDIS_JOB_RAN_KEY 后台任务某次计划执行已由某个实例执行的标记 Redis key，参数为任务名称、计划执行时间（秒级时间戳）
+	REDIS_JOB_RAN_KEY = "job:ran:%s:%d"
+	// REDIS_JOB_STATUS_KEY 单例后台任务最近一次执行状态的 Redis 哈希，字段为任务名称，值为 JSON
+	REDIS_JOB_STATUS_KEY = "job:status"
+	// REDIS_JOB_TASK_QUEUE 延迟任务队列名称，实际的键为 {job:task}:pending 等
+	REDIS_JOB_TASK_QUEUE = "job:task"
 )
@@ -0,0 +1,90 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+)
+
+// 告警机器人
+const (
+	providerFeishu   = "feishu"
+	providerDingTalk = "dingtalk"
+	providerSlack    = "slack"
+)
+
+// NewAlertNotifier 按配置创建告警通知，未开启时返回 nil
+func NewAlertNotifier(cfg configs.AlertConfig) (adapter.AlertNotifier, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("alert enabled but webhook_url is empty")
+	}
+
+	provider := strings.ToLower(cfg.Provider)
+	switch provider {
+	case providerFeishu, providerDingTalk, providerSlack:
+	default:
+		return nil, fmt.Errorf("unsupported alert provider: %s", cfg.Provider)
+	}
+	return &throttledNotifier{
+		notifier: &webhookNotifier{
+			provider:   provider,
+			webhookURL: cfg.WebhookURL,
+			secret:     cfg.Secret,
+			client:     httpclient.New(cfg.Timeout()),
+		},
+		interval: cfg.MinInterval(),
+		last:     make(map[string]*throttleState),
+	}, nil
+}
+
+// throttledNotifier 相同 key 的告警在最短间隔内只发送一次，被合并的次数附在下一次告警中
+// 避免同一个问题在高峰期刷屏
+type throttledNotifier struct {
+	notifier adapter.AlertNotifier
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]*throttleState
+}
+
+type throttleState struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+func (t *throttledNotifier) Notify(ctx context.Context, key, title, content string) error {
+	now := time.Now()
+	t.mu.Lock()
+	state, ok := t.last[key]
+	if ok && now.Sub(state.sentAt) < t.interval {
+		state.suppressed++
+		t.mu.Unlock()
+		return nil
+	}
+	suppressed := 0
+	if ok {
+		suppressed = state.suppressed
+	}
+	t.last[key] = &throttleState{sentAt: now}
+	// 顺带清理已过间隔且没有待报次数的记录，map 不会无限增长
+	for k, s := range t.last {
+		if s.suppressed == 0 && now.Sub(s.sentAt) >= t.interval {
+			delete(t.last, k)
+		}
+	}
+	t.mu.Unlock()
+
+	if suppressed > 0 {
+		content += fmt.Sprintf("\n\n上次告警后另有 %d 次相同告警未发送", suppressed)
+	}
+	return t.notifier.Notify(ctx, key, title, content)
+}
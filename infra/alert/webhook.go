@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 机器人返回错误时最多读取的响应体长度
+const webhookErrorBodyLimit = 1024
+
+// webhookNotifier 通过群机器人 webhook 发送文本消息
+// 飞书、钉钉开启签名校验时按各自的规则签名；Slack 的 webhook 地址本身即为凭证
+type webhookNotifier struct {
+	provider   string
+	webhookURL string
+	secret     string
+	client     *http.Client
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, _ string, title, content string) error {
+	text := title + "\n" + content
+	webhookURL := w.webhookURL
+	var payload map[string]any
+	switch w.provider {
+	case providerFeishu:
+		payload = map[string]any{"msg_type": "text", "content": map[string]string{"text": text}}
+		if w.secret != "" {
+			// 飞书：以 timestamp\nsecret 为密钥对空串做 HmacSHA256，时间戳为秒
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			mac := hmac.New(sha256.New, []byte(timestamp+"\n"+w.secret))
+			payload["timestamp"] = timestamp
+			payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		}
+	case providerDingTalk:
+		payload = map[string]any{"msgtype": "text", "text": map[string]string{"content": text}}
+		if w.secret != "" {
+			// 钉钉：以 secret 为密钥对 timestamp\nsecret 做 HmacSHA256，时间戳为毫秒，签名放在查询参数中
+			timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			mac := hmac.New(sha256.New, []byte(w.secret))
+			mac.Write([]byte(timestamp + "\n" + w.secret))
+			u, err := url.Parse(webhookURL)
+			if err != nil {
+				return fmt.Errorf("invalid dingtalk webhook url: %w", err)
+			}
+			query := u.Query()
+			query.Set("timestamp", timestamp)
+			query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+			u.RawQuery = query.Encode()
+			webhookURL = u.String()
+		}
+	default:
+		payload = map[string]any{"text": text}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send %s alert: %w", w.provider, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyLimit))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s webhook returned %d: %s", w.provider, resp.StatusCode, respBody)
+	}
+	// 飞书、钉钉签名或关键词校验失败时仍返回 200，错误码在响应体中
+	var result struct {
+		Code    *int   `json:"code"`
+		ErrCode *int   `json:"errcode"`
+		Msg     string `json:"msg"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if json.Unmarshal(respBody, &result) == nil {
+		if result.Code != nil && *result.Code != 0 {
+			return fmt.Errorf("%s webhook error %d: %s", w.provider, *result.Code, result.Msg)
+		}
+		if result.ErrCode != nil && *result.ErrCode != 0 {
+			return fmt.Errorf("%s webhook error %d: %s", w.provider, *result.ErrCode, result.ErrMsg)
+		}
+	}
+	return nil
+}
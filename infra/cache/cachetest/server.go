@@ -0,0 +1,357 @@
+// Package cachetest 测试用的内存 Redis，只实现字符串与过期相关的常用命令，
+// 用于在没有 Redis 的环境下测试依赖 cache 包的业务逻辑
+package cachetest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"forge/infra/cache"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Server 内存 Redis 服务
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	values   map[string]string
+	expireAt map[string]time.Time
+	failures map[string]error // 指定命令返回的错误，模拟 Redis 故障
+}
+
+type reply any
+
+// Start 启动内存 Redis 并让 cache 包连接它，测试结束时关闭并恢复原来的客户端
+func Start(t testing.TB) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cachetest: listen failed: %v", err)
+	}
+	s := &Server{
+		listener: listener,
+		values:   map[string]string{},
+		expireAt: map[string]time.Time{},
+		failures: map[string]error{},
+	}
+	go s.serve()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	restore := cache.UseClient(client)
+	t.Cleanup(func() {
+		restore()
+		_ = client.Close()
+		_ = listener.Close()
+	})
+	return s
+}
+
+// Get 直接读取键的值，键不存在或已过期时 ok 为 false
+func (s *Server) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key)
+}
+
+// Set 直接写入键的值，不设置过期时间
+func (s *Server) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	delete(s.expireAt, key)
+}
+
+// TTL 键的剩余过期时间，未设置过期时间时为 0
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at, ok := s.expireAt[key]; ok {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// Fail 之后执行 command 命令时返回 err，err 为 nil 时恢复正常
+func (s *Server) Fail(command string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	command = strings.ToUpper(command)
+	if err == nil {
+		delete(s.failures, command)
+		return
+	}
+	s.failures[command] = err
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var queued [][]string
+	inMulti := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			writeReply(w, "OK")
+		case name == "EXEC" && inMulti:
+			replies := make([]reply, 0, len(queued))
+			for _, cmd := range queued {
+				replies = append(replies, s.exec(cmd))
+			}
+			inMulti, queued = false, nil
+			writeReply(w, replies)
+		case name == "DISCARD" && inMulti:
+			inMulti, queued = false, nil
+			writeReply(w, "OK")
+		case inMulti:
+			queued = append(queued, args)
+			writeReply(w, "QUEUED")
+		default:
+			writeReply(w, s.exec(args))
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(args []string) reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := strings.ToUpper(args[0])
+	if err, ok := s.failures[name]; ok {
+		return err
+	}
+	switch name {
+	case "PING":
+		return "PONG"
+	case "GET":
+		if len(args) != 2 {
+			return errWrongArgs(name)
+		}
+		if v, ok := s.get(args[1]); ok {
+			return []byte(v)
+		}
+		return nil
+	case "SET":
+		return s.set(args)
+	case "DEL", "UNLINK":
+		var n int64
+		for _, key := range args[1:] {
+			if _, ok := s.get(key); ok {
+				n++
+			}
+			delete(s.values, key)
+			delete(s.expireAt, key)
+		}
+		return n
+	case "EXISTS":
+		var n int64
+		for _, key := range args[1:] {
+			if _, ok := s.get(key); ok {
+				n++
+			}
+		}
+		return n
+	case "INCR", "DECR":
+		if len(args) != 2 {
+			return errWrongArgs(name)
+		}
+		v, _ := s.get(args[1])
+		n, err := strconv.ParseInt(v, 10, 64)
+		if v != "" && err != nil {
+			return errors.New("ERR value is not an integer or out of range")
+		}
+		if name == "INCR" {
+			n++
+		} else {
+			n--
+		}
+		s.values[args[1]] = strconv.FormatInt(n, 10)
+		return n
+	case "EXPIRE", "PEXPIRE":
+		if len(args) != 3 {
+			return errWrongArgs(name)
+		}
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return errors.New("ERR value is not an integer or out of range")
+		}
+		if _, ok := s.get(args[1]); !ok {
+			return int64(0)
+		}
+		unit := time.Second
+		if name == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		s.expireAt[args[1]] = time.Now().Add(time.Duration(n) * unit)
+		return int64(1)
+	case "TTL", "PTTL":
+		if len(args) != 2 {
+			return errWrongArgs(name)
+		}
+		if _, ok := s.get(args[1]); !ok {
+			return int64(-2)
+		}
+		at, ok := s.expireAt[args[1]]
+		if !ok {
+			return int64(-1)
+		}
+		if name == "PTTL" {
+			return time.Until(at).Milliseconds()
+		}
+		return int64(time.Until(at).Seconds())
+	default:
+		return fmt.Errorf("ERR unknown command '%s'", args[0])
+	}
+}
+
+// get 读取未过期的键，已过期的键在读取时删除，需持有 mu
+func (s *Server) get(key string) (string, bool) {
+	if at, ok := s.expireAt[key]; ok && !time.Now().Before(at) {
+		delete(s.values, key)
+		delete(s.expireAt, key)
+	}
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// set SET key value [EX seconds|PX milliseconds] [NX|XX]
+func (s *Server) set(args []string) reply {
+	if len(args) < 3 {
+		return errWrongArgs("SET")
+	}
+	key, value := args[1], args[2]
+	var ttl time.Duration
+	var nx, xx bool
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return errors.New("ERR syntax error")
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return errors.New("ERR invalid expire time in 'set' command")
+			}
+			ttl = time.Duration(n) * time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				ttl = time.Duration(n) * time.Millisecond
+			}
+			i++
+		case "KEEPTTL":
+		default:
+			return errors.New("ERR syntax error")
+		}
+	}
+	_, exists := s.get(key)
+	if (nx && exists) || (xx && !exists) {
+		return nil
+	}
+	s.values[key] = value
+	delete(s.expireAt, key)
+	if ttl > 0 {
+		s.expireAt[key] = time.Now().Add(ttl)
+	}
+	return "OK"
+}
+
+func errWrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
+
+// readCommand 读取一条 RESP 数组格式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid array length %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("unexpected argument %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeReply 按 RESP 格式写入回复：string 为简单字符串，[]byte 为批量字符串，nil 为空值
+func writeReply(w *bufio.Writer, r reply) {
+	switch v := r.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case error:
+		w.WriteString("-" + v.Error() + "\r\n")
+	case string:
+		w.WriteString("+" + v + "\r\n")
+	case []byte:
+		w.WriteString("$" + strconv.Itoa(len(v)) + "\r\n")
+		w.Write(v)
+		w.WriteString("\r\n")
+	case int64:
+		w.WriteString(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case []reply:
+		w.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
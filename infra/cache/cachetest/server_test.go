@@ -0,0 +1,71 @@
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"forge/infra/cache"
+)
+
+func TestServer(t *testing.T) {
+	s := Start(t)
+	ctx := context.Background()
+
+	if err := cache.SetRedis(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("SetRedis() error = %v", err)
+	}
+	if got, err := cache.GetRedis(ctx, "k"); err != nil || got != "v" {
+		t.Errorf("GetRedis() = %q, %v, want v", got, err)
+	}
+	if ttl := s.TTL("k"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL() = %v, want (0, 1m]", ttl)
+	}
+	if ok, err := cache.SetNXRedis(ctx, "k", "v2", time.Minute); err != nil || ok {
+		t.Errorf("SetNXRedis() on existing key = %v, %v, want false", ok, err)
+	}
+	if got, err := cache.GetDelRedis(ctx, "k"); err != nil || got != "v" {
+		t.Errorf("GetDelRedis() = %q, %v, want v", got, err)
+	}
+	if _, ok := s.Get("k"); ok {
+		t.Error("key still exists after GetDelRedis()")
+	}
+
+	for want := int64(1); want <= 3; want++ {
+		if got, err := cache.IncrRedis(ctx, "n", time.Minute); err != nil || got != want {
+			t.Errorf("IncrRedis() = %d, %v, want %d", got, err, want)
+		}
+	}
+	if ttl := s.TTL("n"); ttl <= 0 {
+		t.Errorf("TTL() after IncrRedis() = %v, want > 0", ttl)
+	}
+}
+
+func TestServerExpire(t *testing.T) {
+	s := Start(t)
+	ctx := context.Background()
+	if err := cache.SetRedis(ctx, "k", "v", 10*time.Millisecond); err != nil {
+		t.Fatalf("SetRedis() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got, err := cache.GetRedis(ctx, "k"); err != nil || got != "" {
+		t.Errorf("GetRedis() after expiration = %q, %v, want empty", got, err)
+	}
+	if _, ok := s.Get("k"); ok {
+		t.Error("expired key still exists")
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	s := Start(t)
+	ctx := context.Background()
+	s.Fail("incr", errors.New("ERR injected"))
+	if _, err := cache.IncrRedis(ctx, "n", time.Minute); err == nil {
+		t.Error("IncrRedis() error = nil, want injected error")
+	}
+	s.Fail("incr", nil)
+	if _, err := cache.IncrRedis(ctx, "n", time.Minute); err != nil {
+		t.Errorf("IncrRedis() after recovery error = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// deadQueueSize 死信列表保留的消息数
+const deadQueueSize = 1000
+
+// moveDueScript 将 KEYS[1] 中分数不大于 ARGV[1] 的成员（最多 ARGV[3] 个）移到 KEYS[2]，分数改为 ARGV[2]，返回移动的成员
+// 用于取出到期的消息（待执行 -> 执行中，分数为租约到期时间）与回收租约到期的消息（执行中 -> 待执行）
+var moveDueScript = redis.NewScript(`
+local items = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, item in ipairs(items) do
+	redis.call('ZREM', KEYS[1], item)
+	redis.call('ZADD', KEYS[2], ARGV[2], item)
+end
+return items
+`)
+
+// retryScript 从执行中移除 ARGV[1]，以 ARGV[3] 为到期时间将 ARGV[2] 重新放入待执行
+// KEYS: 执行中、待执行
+var retryScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('ZADD', KEYS[2], ARGV[3], ARGV[2])
+end
+return 0
+`)
+
+// buryScript 从执行中移除 ARGV[1]，将 ARGV[2] 放入死信列表并只保留最近 ARGV[3] 条
+// KEYS: 执行中、死信列表
+var buryScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('LPUSH', KEYS[2], ARGV[2])
+	redis.call('LTRIM', KEYS[2], 0, ARGV[3] - 1)
+end
+return 0
+`)
+
+// DelayQueue 基于 Redis 有序集合的延迟队列：消息到期后才能取出，取出后在租约到期前未确认时重新放回待执行，保证至少执行一次
+// 待执行、执行中与死信分别保存在 {name}:pending、{name}:processing、{name}:dead，键名带 hash tag，cluster 模式下位于同一个槽
+type DelayQueue struct {
+	pending    string
+	processing string
+	dead       string
+}
+
+// DelayQueueStats 队列中各状态的消息数
+type DelayQueueStats struct {
+	Pending    int64
+	Processing int64
+	Dead       int64
+}
+
+func NewDelayQueue(name string) *DelayQueue {
+	return &DelayQueue{
+		pending:    "{" + name + "}:pending",
+		processing: "{" + name + "}:processing",
+		dead:       "{" + name + "}:dead",
+	}
+}
+
+// Push 放入一条消息，到 due 后才能取出；相同内容的消息只保留一条
+func (q *DelayQueue) Push(ctx context.Context, msg string, due time.Time) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.ZAdd(ctx, q.pending, &redis.Z{Score: float64(due.UnixMilli()), Member: msg}).Err()
+}
+
+// Claim 取出最多 limit 条已到期的消息，取出的消息需在 lease 内调用 Ack、Retry 或 Bury，否则重新放回待执行
+func (q *DelayQueue) Claim(ctx context.Context, lease time.Duration, limit int) ([]string, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	now := time.Now()
+	return moveDueScript.Run(ctx, redisClient, []string{q.pending, q.processing},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+}
+
+// Ack 确认消息已处理
+func (q *DelayQueue) Ack(ctx context.Context, msg string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.ZRem(ctx, q.processing, msg).Err()
+}
+
+// Retry 处理失败，将更新后的消息 next 在 due 时重新放回待执行；消息的租约已到期被回收时不做处理
+func (q *DelayQueue) Retry(ctx context.Context, msg, next string, due time.Time) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return retryScript.Run(ctx, redisClient, []string{q.processing, q.pending}, msg, next, due.UnixMilli()).Err()
+}
+
+// Bury 不再重试，将更新后的消息 dead 放入死信列表，死信列表只保留最近的 deadQueueSize 条
+func (q *DelayQueue) Bury(ctx context.Context, msg, dead string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return buryScript.Run(ctx, redisClient, []string{q.processing, q.dead}, msg, dead, deadQueueSize).Err()
+}
+
+// RequeueExpired 将租约已到期的消息放回待执行，返回放回的条数
+func (q *DelayQueue) RequeueExpired(ctx context.Context, limit int) (int, error) {
+	if redisClient == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	now := time.Now().UnixMilli()
+	items, err := moveDueScript.Run(ctx, redisClient, []string{q.processing, q.pending}, now, now, limit).StringSlice()
+	return len(items), err
+}
+
+// Stats 统计队列中各状态的消息数
+func (q *DelayQueue) Stats(ctx context.Context) (DelayQueueStats, error) {
+	if redisClient == nil {
+		return DelayQueueStats{}, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	pipe := redisClient.Pipeline()
+	pending := pipe.ZCard(ctx, q.pending)
+	processing := pipe.ZCard(ctx, q.processing)
+	dead := pipe.LLen(ctx, q.dead)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return DelayQueueStats{}, err
+	}
+	return DelayQueueStats{Pending: pending.Val(), Processing: processing.Val(), Dead: dead.Val()}, nil
+}
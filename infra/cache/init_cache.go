@@ -8,7 +8,8 @@ import (
 )
 
 var (
-	redisClient *redis.Client
+	// 按 redis.mode 为 *redis.Client、哨兵模式的 *redis.Client 或 *redis.ClusterClient
+	redisClient redis.UniversalClient
 )
 
 func MustInitCache(config configs.IConfig) {
@@ -18,3 +19,18 @@ func MustInitCache(config configs.IConfig) {
 		panic(err)
 	}
 }
+
+// Close 关闭 Redis 连接，服务退出时调用；未初始化时不做处理
+func Close() error {
+	if redisClient == nil {
+		return nil
+	}
+	return redisClient.Close()
+}
+
+// UseClient 替换 Redis 客户端，返回恢复原客户端的函数；测试中连接 cachetest 启动的内存 Redis
+func UseClient(client redis.UniversalClient) (restore func()) {
+	previous := redisClient
+	redisClient = client
+	return func() { redisClient = previous }
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"forge/pkg/log/zlog"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLockHeld 锁已被其他持有者占用
+var ErrLockHeld = errors.New("lock is held by another owner")
+
+// releaseLockScript 只有值仍为自己的令牌时才删除，锁过期后被他人获取时不会误删
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// renewLockScript 只有值仍为自己的令牌时才续期，返回 0 表示锁已丢失
+// ARGV: 令牌、过期时间（毫秒）
+var renewLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Lock 基于 Redis 的分布式锁，持有期间后台每 ttl/3 续期一次，持有者异常退出后锁在 ttl 后自动释放
+type Lock struct {
+	key   string
+	token string
+	ttl   time.Duration
+
+	// lost 续期失败或发现锁已被他人获取时关闭
+	lost     chan struct{}
+	lostOnce sync.Once
+	// stop 释放锁时关闭，结束续期
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// AcquireLock 获取 key 对应的锁，锁已被占用时返回 ErrLockHeld，不等待
+func AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := SetNXRedis(ctx, key, token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockHeld
+	}
+	l := &Lock{
+		key:   key,
+		token: token,
+		ttl:   ttl,
+		lost:  make(chan struct{}),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go l.heartbeat()
+	return l, nil
+}
+
+// Lost 锁丢失后关闭，持有者应尽快停止受锁保护的操作
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release 停止续期并释放锁，锁已过期或已被他人获取时不做处理；可重复调用
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return releaseLockScript.Run(ctx, redisClient, []string{l.key}, l.token).Err()
+}
+
+// heartbeat 定时续期，Redis 短暂不可用时继续重试，直到锁过期仍未续期成功才视为丢失
+func (l *Lock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	deadline := time.Now().Add(l.ttl)
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		renewed, err := l.renew()
+		if err != nil {
+			zlog.Warnf("分布式锁 %s 续期失败: %v", l.key, err)
+			if time.Now().Before(deadline) {
+				continue
+			}
+		}
+		if !renewed {
+			zlog.Errorf("分布式锁 %s 已丢失", l.key)
+			l.lostOnce.Do(func() { close(l.lost) })
+			return
+		}
+		deadline = time.Now().Add(l.ttl)
+	}
+}
+
+func (l *Lock) renew() (bool, error) {
+	ctx, cancel := withRedisTimeout(context.Background())
+	defer cancel()
+	result, err := renewLockScript.Run(ctx, redisClient, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// WithLock 持有 key 对应的锁执行 fn，锁已被占用时不执行并返回 false
+// fn 的 ctx 在锁丢失时取消；fn 返回后释放锁
+func WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context)) (bool, error) {
+	l, err := AcquireLock(ctx, key, ttl)
+	if errors.Is(err, ErrLockHeld) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := l.Release(context.WithoutCancel(ctx)); err != nil {
+			zlog.Warnf("释放分布式锁 %s 失败: %v", key, err)
+		}
+	}()
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel()
+		case <-fnCtx.Done():
+		}
+	}()
+	fn(fnCtx)
+	return true, nil
+}
+
+// lockToken 锁持有者的随机令牌，释放与续期时校验
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
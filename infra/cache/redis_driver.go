@@ -11,61 +11,110 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
-const (
-	redisAddr = "%s:%d"
-)
+// redisTimeout 单次 Redis 操作的超时时间
+var redisTimeout = time.Second
 
 func initRedis(config configs.IConfig) error {
 	redisConfig := config.GetRedisConfig()
-	if !config.GetRedisConfig().Enable {
-		zlog.Warnf("不使用Redis模式")
-		return nil
-	}
-	client := redis.NewClient(&redis.Options{
-		Network:            "",
-		Addr:               fmt.Sprintf(redisAddr, redisConfig.Host, redisConfig.Port),
-		Dialer:             nil,
-		OnConnect:          nil,
-		Username:           "",
-		Password:           redisConfig.Password,
-		DB:                 redisConfig.DB,
-		MaxRetries:         0,
-		MinRetryBackoff:    0,
-		MaxRetryBackoff:    0,
-		DialTimeout:        0,
-		ReadTimeout:        0,
-		WriteTimeout:       0,
-		PoolFIFO:           false,
-		PoolSize:           1000,
-		MinIdleConns:       1,
-		MaxConnAge:         0,
-		PoolTimeout:        0,
-		IdleTimeout:        0,
-		IdleCheckFrequency: 0,
-		TLSConfig:          nil,
-		Limiter:            nil,
-	})
+	redisTimeout = config.GetTimeoutConfig().Redis()
+	client := newRedisClient(redisConfig)
 	if _, err := client.Ping(context.Background()).Result(); err != nil {
-		zlog.Errorf("redis无法链接 %v", err)
+		zlog.Errorf("redis无法链接(%s模式 %v): %v", redisConfig.ModeName(), redisConfig.Addresses(), err)
+		_ = client.Close()
 		return err
 	}
+	zlog.Infof("redis连接成功，%s模式 %v", redisConfig.ModeName(), redisConfig.Addresses())
+	// 链路追踪，未开启时 hook 为空操作
+	client.AddHook(tracingHook{})
 	redisClient = client
 	return nil
 }
 
+// newRedisClient 按部署模式创建客户端：sentinel 模式通过哨兵发现主节点并在主从切换后自动重连，
+// cluster 模式按键所在的槽位路由到对应节点
+func newRedisClient(redisConfig configs.RedisConfig) redis.UniversalClient {
+	switch redisConfig.ModeName() {
+	case configs.RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       redisConfig.MasterName,
+			SentinelAddrs:    redisConfig.Addresses(),
+			SentinelPassword: redisConfig.SentinelPassword,
+			Username:         redisConfig.Username,
+			Password:         redisConfig.Password,
+			DB:               redisConfig.DB,
+			ReadTimeout:      redisTimeout,
+			WriteTimeout:     redisTimeout,
+			PoolSize:         1000,
+			MinIdleConns:     1,
+		})
+	case configs.RedisModeCluster:
+		// 连接池按节点分别创建
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        redisConfig.Addresses(),
+			Username:     redisConfig.Username,
+			Password:     redisConfig.Password,
+			ReadTimeout:  redisTimeout,
+			WriteTimeout: redisTimeout,
+			PoolSize:     1000,
+			MinIdleConns: 1,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:         redisConfig.Addresses()[0],
+			Username:     redisConfig.Username,
+			Password:     redisConfig.Password,
+			DB:           redisConfig.DB,
+			ReadTimeout:  redisTimeout,
+			WriteTimeout: redisTimeout,
+			PoolSize:     1000,
+			MinIdleConns: 1,
+		})
+	}
+}
+
+// Ping 检查 Redis 是否可用，用于就绪检查
+// cluster 模式检查所有主节点，任一主节点不可用时部分键无法读写
+func Ping(ctx context.Context) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	if cluster, ok := redisClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return client.Ping(ctx).Err()
+		})
+	}
+	return redisClient.Ping(ctx).Err()
+}
+
 // SetRedis 设置键值对，带过期时间
 func SetRedis(ctx context.Context, key string, value string, expiration time.Duration) error {
 	if redisClient == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
 	return redisClient.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNXRedis 键不存在时才设置，返回是否设置成功
+func SetNXRedis(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	if redisClient == nil {
+		return false, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.SetNX(ctx, key, value, expiration).Result()
+}
+
 // GetRedis 获取键对应的值
 func GetRedis(ctx context.Context, key string) (string, error) {
 	if redisClient == nil {
 		return "", fmt.Errorf("redis client not initialized")
 	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
 	result, err := redisClient.Get(ctx, key).Result()
 	if err == redis.Nil {
 		return "", nil // 键不存在
@@ -78,5 +127,298 @@ func DelRedis(ctx context.Context, key string) error {
 	if redisClient == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
 	return redisClient.Del(ctx, key).Err()
 }
+
+// GetDelRedis 原子地获取并删除键，键不存在时返回空字符串
+// 使用 MULTI/EXEC 而不是 GETDEL，兼容 6.2 以下的 Redis
+func GetDelRedis(ctx context.Context, key string) (string, error) {
+	if redisClient == nil {
+		return "", fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+
+	var get *redis.StringCmd
+	_, err := redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	result, err := get.Result()
+	if err == redis.Nil {
+		return "", nil // 键不存在
+	}
+	return result, err
+}
+
+// MGetRedis 批量获取多个键的值，返回值与 keys 一一对应，不存在的键为空字符串
+func MGetRedis(ctx context.Context, keys ...string) ([]string, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	// cluster 模式下 MGET 的键必须在同一槽位，改为 pipeline 逐个 GET，由客户端按节点分组发送
+	if _, ok := redisClient.(*redis.ClusterClient); ok {
+		return pipelinedGet(ctx, keys)
+	}
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]string, len(values))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			results[i] = s
+		}
+	}
+	return results, nil
+}
+
+func pipelinedGet(ctx context.Context, keys []string) ([]string, error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	results := make([]string, len(keys))
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		results[i] = value
+	}
+	return results, nil
+}
+
+// MSetRedis 通过 pipeline 批量设置键值对，所有键使用同一过期时间
+func MSetRedis(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, expiration)
+		}
+		return nil
+	})
+	return err
+}
+
+// IncrRedis 计数器自增1，首次创建时设置过期时间，返回自增后的值
+func IncrRedis(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	if redisClient == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	count, err := redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := redisClient.Expire(ctx, key, expiration).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// decrIfPositiveScript 计数器存在且大于 0 时减 1，键已过期时不重新创建
+var decrIfPositiveScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count > 0 then
+	return redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
+// DecrRedis 撤销一次 IncrRedis 的计数，计数器已过期或为 0 时不做处理
+func DecrRedis(ctx context.Context, key string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return decrIfPositiveScript.Run(ctx, redisClient, []string{key}).Err()
+}
+
+// TTLRedis 获取键的剩余过期时间，键不存在或未设置过期时间时返回 0
+func TTLRedis(ctx context.Context, key string) (time.Duration, error) {
+	if redisClient == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	ttl, err := redisClient.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// HSetRedis 设置哈希的一个字段
+func HSetRedis(ctx context.Context, key, field, value string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.HSet(ctx, key, field, value).Err()
+}
+
+// HGetRedis 获取哈希的一个字段，字段不存在时返回空字符串
+func HGetRedis(ctx context.Context, key, field string) (string, error) {
+	if redisClient == nil {
+		return "", fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	value, err := redisClient.HGet(ctx, key, field).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+// HGetAllRedis 获取哈希的所有字段，键不存在时返回空 map
+func HGetAllRedis(ctx context.Context, key string) (map[string]string, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.HGetAll(ctx, key).Result()
+}
+
+// XAddRedis 向 Stream 追加一条消息，maxLen 大于 0 时按近似长度裁剪旧消息，返回消息ID
+func XAddRedis(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	if redisClient == nil {
+		return "", fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// withRedisTimeout 为单次 Redis 操作设置超时，调用方截止时间更早时以调用方为准
+func withRedisTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, redisTimeout)
+}
+
+// acquireSemaphoreScript 先清理过期的名额，再在名额未满时占用一个
+// KEYS[1] 有序集合，成员为名额ID，分数为过期时间（毫秒时间戳）
+// ARGV: 名额ID、上限、当前时间、过期时间
+var acquireSemaphoreScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+	redis.call('PEXPIREAT', KEYS[1], ARGV[4])
+	return 1
+end
+return 0
+`)
+
+// AcquireSemaphore 分布式信号量：占用 key 下的一个名额，名额已满返回 false
+// 名额在 ttl 后自动过期，防止持有者异常退出后名额永远不释放
+func AcquireSemaphore(ctx context.Context, key, member string, limit int64, ttl time.Duration) (bool, error) {
+	if redisClient == nil {
+		return false, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	now := time.Now()
+	result, err := acquireSemaphoreScript.Run(ctx, redisClient, []string{key},
+		member, limit, now.UnixMilli(), now.Add(ttl).UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// ReleaseSemaphore 释放 AcquireSemaphore 占用的名额
+func ReleaseSemaphore(ctx context.Context, key, member string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	return redisClient.ZRem(ctx, key, member).Err()
+}
+
+// takeTokenScript 令牌桶：按距上次取令牌的时间补充令牌，再尝试取出一个
+// KEYS[1] 哈希，tokens 为剩余令牌数，ts 为上次取令牌的时间（毫秒时间戳）
+// ARGV: 每毫秒补充的令牌数、桶容量、当前时间、过期时间（毫秒）
+// 返回 {是否取到, 取不到时需要等待的毫秒数}
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, wait}
+`)
+
+// TakeToken 令牌桶限流：从 key 的桶中取一个令牌，桶容量为 burst，每秒补充 rate 个
+// 取不到时返回 false 与下一个令牌补充所需的时长；桶在补满后过期，不占用内存
+func TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	if redisClient == nil {
+		return false, 0, fmt.Errorf("redis client not initialized")
+	}
+	ctx, cancel := withRedisTimeout(ctx)
+	defer cancel()
+	ttl := time.Duration(float64(burst)/rate*float64(time.Second)) + time.Second
+	result, err := takeTokenScript.Run(ctx, redisClient, []string{key},
+		rate/1000, burst, time.Now().UnixMilli(), ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected take token result: %v", result)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
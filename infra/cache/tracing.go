@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"forge/pkg/tracing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type tracingSpanKey struct{}
+
+// tracingHook 每条 Redis 命令（或每次 pipeline）创建 client span，不记录键值
+// 键不存在（redis.Nil）不视为失败
+type tracingHook struct{}
+
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, "redis."+cmd.Name(), 1), nil
+}
+
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endSpan(ctx, cmd.Err())
+	return nil
+}
+
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, "redis.pipeline", len(cmds)), nil
+}
+
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+			break
+		}
+	}
+	endSpan(ctx, err)
+	return nil
+}
+
+func startSpan(ctx context.Context, name string, commands int) context.Context {
+	ctx, span := tracing.Start(ctx, name, tracing.SpanKindClient,
+		tracing.String("db.system", "redis"),
+		tracing.Int("db.redis.commands", commands),
+	)
+	if span == nil {
+		return ctx
+	}
+	// 单独保存，AfterProcess 不会取到调用方自己的 span
+	return context.WithValue(ctx, tracingSpanKey{}, span)
+}
+
+func endSpan(ctx context.Context, err error) {
+	span, ok := ctx.Value(tracingSpanKey{}).(*tracing.Span)
+	if !ok {
+		return
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+	}
+	span.End()
+}
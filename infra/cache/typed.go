@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"forge/pkg/log/zlog"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// loadGroup 合并同一实例内对同一个键的并发加载
+var loadGroup singleflight.Group
+
+// GetJSON 读取键的值并按 JSON 解码到 T，键不存在时 found 为 false
+func GetJSON[T any](ctx context.Context, key string) (value T, found bool, err error) {
+	data, err := GetRedis(ctx, key)
+	if err != nil || data == "" {
+		return value, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// SetJSON 将 value 按 JSON 编码后写入，带过期时间
+func SetJSON[T any](ctx context.Context, key string, value T, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return SetRedis(ctx, key, string(data), expiration)
+}
+
+// GetOrLoad 先读缓存，未命中时调用 load 加载并回填，cached 表示结果是否来自缓存
+// 同一实例内同一个键的并发未命中只调用一次 load，其余请求等待并共享结果，避免缓存失效瞬间大量请求同时打到数据库；
+// 共享的结果可能被多个调用方同时持有，调用方不要修改
+// load 使用不随调用方取消的 ctx，先到的请求断开不影响等待中的请求；load 返回错误或零值（如 nil 指针）时不写入缓存
+// Redis 不可用或缓存内容无法解码时按未命中处理
+func GetOrLoad[T any](ctx context.Context, key string, expiration time.Duration, load func(ctx context.Context) (T, error)) (value T, cached bool, err error) {
+	value, found, err := GetJSON[T](ctx, key)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "get cache %s failed: %v", key, err)
+	} else if found {
+		return value, true, nil
+	}
+
+	ch := loadGroup.DoChan(key, func() (any, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return loaded, err
+		}
+		if !reflect.ValueOf(&loaded).Elem().IsZero() {
+			if err := SetJSON(loadCtx, key, loaded, expiration); err != nil {
+				zlog.CtxWarnf(ctx, "set cache %s failed: %v", key, err)
+			}
+		}
+		return loaded, nil
+	})
+	select {
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	case result := <-ch:
+		value, _ = result.Val.(T)
+		return value, false, result.Err
+	}
+}
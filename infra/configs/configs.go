@@ -2,8 +2,13 @@ package configs
 
 import (
 	"flag"
+	"fmt"
 	"forge/constant"
 	"forge/pkg/log/zlog"
+	"math"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
@@ -13,6 +18,7 @@ type IConfig interface {
 	GetRedisConfig() RedisConfig
 	GetDBConfig() DBConfig
 	GetAppConfig() ApplicationConfig
+	GetServerConfig() ServerConfig
 	GetLoggerConfig() LoggerConfig
 	GetJWTConfig() JWTConfig
 	GetSnowflakeConfig() SnowflakeConfig
@@ -20,14 +26,53 @@ type IConfig interface {
 	GetCOSConfig() COSConfig
 	GetAiChatConfig() AiChatConfig
 	GetSMSConfig() SMSConfig
+	GetPlanConfigs() map[string]PlanConfig // 套餐配额，key 为套餐名
+	GetAdminConfig() AdminConfig
+	GetBillingConfig() BillingConfig
+	GetCozeConfig() CozeConfig
+	GetLoopConfig() LoopConfig
+	GetTracingConfig() TracingConfig
+	GetCookieAuthConfig() CookieAuthConfig
+	GetTimeoutConfig() TimeoutConfig
+	GetBackupConfig() BackupConfig
+	GetContactReminderConfig() ContactReminderConfig
+	GetHTTPClientConfig() HTTPClientConfig
+	GetOAuthConfig() OAuthConfig
+	GetLoginLimitConfig() LoginLimitConfig
+	GetVerificationCodeConfig() VerificationCodeConfig
+	GetAccountDeletionConfig() AccountDeletionConfig
+	GetMagicLinkConfig() MagicLinkConfig
+	GetPasswordHistoryConfig() PasswordHistoryConfig
+	GetPasswordPolicyConfig() PasswordPolicyConfig
+	GetCaptchaConfig() CaptchaConfig
+	GetRateLimitConfig() RateLimitConfig
+	GetAccountChangeConfig() AccountChangeConfig
+	GetUserCacheConfig() UserCacheConfig
+	GetDataExportConfig() DataExportConfig
+	GetInviteConfig() InviteConfig
+	GetPromptPresetConfig() PromptPresetConfig
+	GetModerationConfig() ModerationConfig
+	GetMindMapShareConfig() MindMapShareConfig
+	GetMindMapExportConfig() MindMapExportConfig
+	GetFileScanConfig() FileScanConfig
+	GetAlertConfig() AlertConfig
+	GetBodyLimitConfig() BodyLimitConfig
+	GetOpenAPIConfig() OpenAPIConfig
+	GetOutboxConfig() OutboxConfig
 }
 
 var (
-	conf = new(config)
+	// conf 当前生效的配置，热更新时整体替换，每次 Config() 拿到的都是完整的一份
+	conf atomic.Pointer[config]
 )
 
+func init() {
+	conf.Store(new(config))
+}
+
+// Config 当前生效的配置，需要跟随热更新的配置项应在使用时读取，不要在初始化时保存
 func Config() IConfig {
-	return conf
+	return conf.Load()
 }
 func MustInit(path string) {
 	mustInit(path)
@@ -46,6 +91,8 @@ func (c *config) GetAppConfig() ApplicationConfig {
 	return c.AppConfig
 }
 
+func (c *config) GetServerConfig() ServerConfig { return c.ServerConfig }
+
 func (c *config) GetLoggerConfig() LoggerConfig {
 	return c.LogConfig
 }
@@ -76,56 +123,193 @@ func (c *config) GetAiChatConfig() AiChatConfig { return c.AiChatConfig }
 // sms配置读取
 func (c *config) GetSMSConfig() SMSConfig { return c.SMSConfig }
 
+// 套餐配额读取
+func (c *config) GetPlanConfigs() map[string]PlanConfig { return c.PlanConfigs }
+
+// 管理员配置读取
+func (c *config) GetAdminConfig() AdminConfig { return c.AdminConfig }
+
+// 支付配置读取
+func (c *config) GetBillingConfig() BillingConfig { return c.BillingConfig }
+
+// coze配置读取
+func (c *config) GetCozeConfig() CozeConfig { return c.CozeConfig }
+
+// cozeloop配置读取
+func (c *config) GetLoopConfig() LoopConfig { return c.LoopConfig }
+
+// OpenTelemetry链路追踪配置读取
+func (c *config) GetTracingConfig() TracingConfig { return c.TracingConfig }
+
+// cookie鉴权配置读取
+func (c *config) GetCookieAuthConfig() CookieAuthConfig { return c.CookieAuthConfig }
+
+// 超时配置读取
+func (c *config) GetTimeoutConfig() TimeoutConfig { return c.TimeoutConfig }
+
+// 备份配置读取
+func (c *config) GetBackupConfig() BackupConfig { return c.BackupConfig }
+
+// 绑定联系方式提醒配置读取
+func (c *config) GetContactReminderConfig() ContactReminderConfig { return c.ContactReminderConfig }
+
+// 外部调用连接池配置读取
+func (c *config) GetHTTPClientConfig() HTTPClientConfig { return c.HTTPClientConfig }
+
+// 第三方登录配置读取
+func (c *config) GetOAuthConfig() OAuthConfig { return c.OAuthConfig }
+
+func (c *config) GetLoginLimitConfig() LoginLimitConfig { return c.LoginLimitConfig }
+
+func (c *config) GetVerificationCodeConfig() VerificationCodeConfig { return c.VerificationCodeConfig }
+
+func (c *config) GetAccountDeletionConfig() AccountDeletionConfig { return c.AccountDeletionConfig }
+
+func (c *config) GetMagicLinkConfig() MagicLinkConfig { return c.MagicLinkConfig }
+
+func (c *config) GetPasswordHistoryConfig() PasswordHistoryConfig { return c.PasswordHistoryConfig }
+
+func (c *config) GetPasswordPolicyConfig() PasswordPolicyConfig { return c.PasswordPolicyConfig }
+
+func (c *config) GetCaptchaConfig() CaptchaConfig { return c.CaptchaConfig }
+
+func (c *config) GetRateLimitConfig() RateLimitConfig { return c.RateLimitConfig }
+
+func (c *config) GetAccountChangeConfig() AccountChangeConfig { return c.AccountChangeConfig }
+
+func (c *config) GetUserCacheConfig() UserCacheConfig { return c.UserCacheConfig }
+
+func (c *config) GetDataExportConfig() DataExportConfig { return c.DataExportConfig }
+
+func (c *config) GetInviteConfig() InviteConfig { return c.InviteConfig }
+
+func (c *config) GetPromptPresetConfig() PromptPresetConfig { return c.PromptPresetConfig }
+
+func (c *config) GetModerationConfig() ModerationConfig { return c.ModerationConfig }
+
+func (c *config) GetMindMapShareConfig() MindMapShareConfig { return c.MindMapShareConfig }
+
+func (c *config) GetMindMapExportConfig() MindMapExportConfig { return c.MindMapExportConfig }
+
+func (c *config) GetFileScanConfig() FileScanConfig { return c.FileScanConfig }
+
+func (c *config) GetAlertConfig() AlertConfig { return c.AlertConfig }
+
+func (c *config) GetBodyLimitConfig() BodyLimitConfig { return c.BodyLimitConfig }
+
+func (c *config) GetOpenAPIConfig() OpenAPIConfig { return c.OpenAPIConfig }
+
+func (c *config) GetOutboxConfig() OutboxConfig { return c.OutboxConfig }
+
 func mustInit(path string) *config {
 	// 初始化时间为东八区的时间
 	var cstZone = time.FixedZone("CST", 8*3600) // 东八
 	time.Local = cstZone
 
 	// 默认配置文件路径
-	var configPath string
+	var configPath, profile string
 	flag.StringVar(&configPath, "c", path+constant.DEFAULT_CONFIG_FILE_PATH, "配置文件绝对路径或相对路径")
+	flag.StringVar(&profile, "env", "", "配置环境，在配置文件之上合并同目录下的 config.{env}.yaml，默认取 app.env")
 	flag.Parse()
 	zlog.Infof("配置文件路径为 %s", configPath)
 	// 初始化配置文件
+	configFile = configPath
 	viper.SetConfigFile(configPath)
-	viper.WatchConfig()
-	// 观察配置文件变动
-	//viper.OnConfigChange(func(in fsnotify.Event) {
-	//	zlog.Warnf("配置文件发生变化")
-	//	if err := viper.Unmarshal(&configs.Conf); err != nil {
-	//		zlog.Errorf("无法反序列化配置文件 %v", err)
-	//	}
-	//	zlog.Debugf("%+v", configs.Conf)
-	//
-	//	Eve()
-	//	Init()
-	//})
-	// 将配置文件读入 viper
+	// FORGE_ 开头的环境变量覆盖配置文件中的值，数据库 DSN、各类密钥可以由部署环境或密钥管理服务注入
+	setupEnv()
+	// 先读取基础配置确定环境，app.env 可以写在基础配置中，也可以由 FORGE_APP_ENV 提供
 	if err := viper.ReadInConfig(); err != nil {
 		zlog.Panicf("无法读取配置文件 err: %v", err)
 	}
-	_config := config{}
-	// 解析到变量中
-	if err := viper.Unmarshal(&_config); err != nil {
-		zlog.Panicf("无法解析配置文件 err: %v", err)
+	if profile != "" {
+		viper.Set("app.env", profile)
+	} else {
+		profile = viper.GetString("app.env")
+	}
+	profileFile = profilePath(configPath, profile)
+	// 将基础配置与环境配置读入 viper
+	if err := readInConfig(); err != nil {
+		zlog.Panicf("无法读取 %s 环境的配置文件 err: %v", profile, err)
+	}
+	if profileExists() {
+		zlog.Infof("配置环境为 %s，已合并 %s", profile, profileFile)
+	} else if profile != "" {
+		zlog.Infof("配置环境为 %s，未找到 %s，只使用 %s", profile, profileFile, configPath)
 	}
-	zlog.Debugf("配置文件为 ： %+v", _config)
-	conf = &_config
-	return conf
+	_config, err := load()
+	if err != nil {
+		zlog.Panicf("%v", err)
+	}
+	zlog.Debugf("配置文件为 ： %s", redacted(_config))
+	conf.Store(_config)
+
+	// 观察配置文件变动，只有可以热更新的配置会生效，见 reload
+	watchConfig()
+	return _config
+}
 
+// load 将 viper 中的配置解析到结构体中，替换占位符后校验
+// 启动前检查配置，列出所有问题后退出，不带着缺失的密钥或错误的地址运行
+func load() (*config, error) {
+	_config := &config{}
+	// 解析到变量中
+	if err := viper.Unmarshal(_config); err != nil {
+		return nil, fmt.Errorf("无法解析配置文件 err: %w", err)
+	}
+	if err := interpolate(_config); err != nil {
+		return nil, fmt.Errorf("无法替换配置中的占位符 err: %w", err)
+	}
+	if err := _config.Validate(); err != nil {
+		return nil, err
+	}
+	return _config, nil
 }
 
 type config struct {
-	AppConfig       ApplicationConfig `mapstructure:"app"`
-	LogConfig       LoggerConfig      `mapstructure:"log"`
-	DBConfig        DBConfig          `mapstructure:"database"`
-	RedisConfig     RedisConfig       `mapstructure:"redis"`
-	JWTConfig       JWTConfig         `mapstructure:"jwt"`
-	SnowflakeConfig SnowflakeConfig   `mapstructure:"snowflake"`
-	SMTPConfig      SMTPConfig        `mapstructure:"smtp"`
-	COSConfig       COSConfig         `mapstructure:"cos"`
-	AiChatConfig    AiChatConfig      `mapstructure:"ai_client"`
-	SMSConfig       SMSConfig         `mapstructure:"sms"`
+	AppConfig              ApplicationConfig      `mapstructure:"app"`
+	ServerConfig           ServerConfig           `mapstructure:"server"`
+	LogConfig              LoggerConfig           `mapstructure:"log"`
+	DBConfig               DBConfig               `mapstructure:"database"`
+	RedisConfig            RedisConfig            `mapstructure:"redis"`
+	JWTConfig              JWTConfig              `mapstructure:"jwt"`
+	SnowflakeConfig        SnowflakeConfig        `mapstructure:"snowflake"`
+	SMTPConfig             SMTPConfig             `mapstructure:"smtp"`
+	COSConfig              COSConfig              `mapstructure:"cos"`
+	AiChatConfig           AiChatConfig           `mapstructure:"ai_client"`
+	SMSConfig              SMSConfig              `mapstructure:"sms"`
+	PlanConfigs            map[string]PlanConfig  `mapstructure:"plans"`
+	AdminConfig            AdminConfig            `mapstructure:"admin"`
+	BillingConfig          BillingConfig          `mapstructure:"billing"`
+	CozeConfig             CozeConfig             `mapstructure:"coze"`
+	LoopConfig             LoopConfig             `mapstructure:"cozeloop"`
+	TracingConfig          TracingConfig          `mapstructure:"tracing"`
+	CookieAuthConfig       CookieAuthConfig       `mapstructure:"cookie_auth"`
+	TimeoutConfig          TimeoutConfig          `mapstructure:"timeout"`
+	BackupConfig           BackupConfig           `mapstructure:"backup"`
+	ContactReminderConfig  ContactReminderConfig  `mapstructure:"contact_reminder"`
+	HTTPClientConfig       HTTPClientConfig       `mapstructure:"http_client"`
+	OAuthConfig            OAuthConfig            `mapstructure:"oauth"`
+	LoginLimitConfig       LoginLimitConfig       `mapstructure:"login_limit"`
+	VerificationCodeConfig VerificationCodeConfig `mapstructure:"verification_code"`
+	AccountDeletionConfig  AccountDeletionConfig  `mapstructure:"account_deletion"`
+	MagicLinkConfig        MagicLinkConfig        `mapstructure:"magic_link"`
+	PasswordHistoryConfig  PasswordHistoryConfig  `mapstructure:"password_history"`
+	PasswordPolicyConfig   PasswordPolicyConfig   `mapstructure:"password_policy"`
+	CaptchaConfig          CaptchaConfig          `mapstructure:"captcha"`
+	RateLimitConfig        RateLimitConfig        `mapstructure:"rate_limit"`
+	AccountChangeConfig    AccountChangeConfig    `mapstructure:"account_change"`
+	UserCacheConfig        UserCacheConfig        `mapstructure:"user_cache"`
+	DataExportConfig       DataExportConfig       `mapstructure:"data_export"`
+	InviteConfig           InviteConfig           `mapstructure:"invite"`
+	PromptPresetConfig     PromptPresetConfig     `mapstructure:"prompt_preset"`
+	ModerationConfig       ModerationConfig       `mapstructure:"moderation"`
+	MindMapShareConfig     MindMapShareConfig     `mapstructure:"mindmap_share"`
+	MindMapExportConfig    MindMapExportConfig    `mapstructure:"mindmap_export"`
+	FileScanConfig         FileScanConfig         `mapstructure:"file_scan"`
+	AlertConfig            AlertConfig            `mapstructure:"alert"`
+	BodyLimitConfig        BodyLimitConfig        `mapstructure:"body_limit"`
+	OpenAPIConfig          OpenAPIConfig          `mapstructure:"openapi"`
+	OutboxConfig           OutboxConfig           `mapstructure:"outbox"`
 }
 
 type ApplicationConfig struct {
@@ -134,25 +318,82 @@ type ApplicationConfig struct {
 	Env         string `mapstructure:"env"`
 	LogfilePath string `mapstructure:"logfilePath"`
 	Version     string `mapstructure:"version"`
+	// 收到退出信号后等待处理中请求完成的时长，超时后强制关闭，释放资源也以此为上限，默认 30 秒
+	ShutdownTimeoutSeconds int `mapstructure:"shutdown_timeout_seconds"`
+}
+
+func (c ApplicationConfig) ShutdownTimeout() time.Duration {
+	return durationOrDefault(c.ShutdownTimeoutSeconds, time.Second, 30*time.Second)
 }
+
+// IsDev 是否为开发环境
+func (c ApplicationConfig) IsDev() bool {
+	return strings.EqualFold(c.Env, "dev")
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	// TrustedProxies 可信的反向代理地址（IP 或 CIDR），只有来自这些地址的请求才按 X-Forwarded-For 取客户端 IP
+	// 默认为空，不信任任何代理，直接使用连接的对端地址；部署在 nginx 等代理之后时需配置代理的地址，否则客户端 IP 都是代理的地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
 type LoggerConfig struct {
-	Level    int8   `mapstructure:"level"`
+	Level    int8   `mapstructure:"level"` // 输出的最低级别：-1 debug、0 info、1 warn、2 error，支持热更新
 	Format   string `mapstructure:"format"`
 	Director string `mapstructure:"director"`
 	ShowLine bool   `mapstructure:"show-line"`
 }
 
 type DBConfig struct {
-	Driver      string `mapstructure:"driver"`
-	AutoMigrate bool   `mapstructure:"migrate"`
-	Dsn         string `mapstructure:"dsn"`
+	Driver string `mapstructure:"driver"`
+	// 启动时执行未执行的版本化迁移（infra/database/migrations）；关闭时需先通过 -migrate up 迁移，存在未执行的迁移时拒绝启动
+	// 开发环境另外按存储对象自动建表、加列，方便调试，其他环境不做自动迁移
+	Migrate bool   `mapstructure:"migrate"`
+	Dsn     string `mapstructure:"dsn"`
+	// 只读从库的 DSN 列表，配置后用户、导图列表、会话列表等只读查询轮询发往从库，从库出错时回退到主库
+	ReplicaDsns []string `mapstructure:"replica_dsns"`
 }
 type RedisConfig struct {
-	Enable   bool   `mapstructure:"enable"`
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	Enable bool `mapstructure:"enable"`
+	// 部署模式：standalone（默认）、sentinel、cluster
+	Mode string `mapstructure:"mode"`
+	// standalone 模式的地址，未配置 addrs 时使用
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+	// sentinel 模式为哨兵地址列表，cluster 模式为集群节点地址列表（host:port）
+	Addrs []string `mapstructure:"addrs"`
+	// sentinel 模式的主节点名称
+	MasterName string `mapstructure:"master_name"`
+	Username   string `mapstructure:"username"`
+	Password   string `mapstructure:"password"`
+	// 哨兵自身的密码，与数据节点的密码不同时配置
+	SentinelPassword string `mapstructure:"sentinel_password"`
+	// cluster 模式只有 0 号库，该项不生效
+	DB int `mapstructure:"db"`
+}
+
+// Redis 部署模式
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// ModeName 当前的部署模式，未配置时为 standalone
+func (c RedisConfig) ModeName() string {
+	if c.Mode == "" {
+		return RedisModeStandalone
+	}
+	return strings.ToLower(c.Mode)
+}
+
+// Addresses 连接地址，standalone 模式未配置 addrs 时为 host:port
+func (c RedisConfig) Addresses() []string {
+	if len(c.Addrs) > 0 || c.ModeName() != RedisModeStandalone {
+		return c.Addrs
+	}
+	return []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
 }
 
 type KafkaConfig struct {
@@ -161,8 +402,13 @@ type KafkaConfig struct {
 }
 
 type JWTConfig struct {
-	SecretKey   string `mapstructure:"secret_key"`
-	ExpireHours int    `mapstructure:"expire_hours"`
+	SecretKey     string `mapstructure:"secret_key"`
+	ExpireHours   int    `mapstructure:"expire_hours"`
+	Issuer        string `mapstructure:"issuer"`         // 签发方（iss），各环境应配置不同值
+	Audience      string `mapstructure:"audience"`       // 接收方（aud），为空时不校验
+	LeewaySeconds int    `mapstructure:"leeway_seconds"` // 校验 exp/nbf/iat 时允许的时钟偏差
+	// 刷新令牌有效期，默认 168 小时（7天）；启用刷新令牌后 expire_hours 建议调短
+	RefreshExpireHours int `mapstructure:"refresh_expire_hours"`
 }
 
 type SnowflakeConfig struct {
@@ -176,9 +422,18 @@ type SMTPConfig struct {
 	SmtpUser    string `mapstructure:"smtp_user"`
 	SmtpPass    string `mapstructure:"smtp_pass"`
 	EncodedName string `mapstructure:"encoded_name"`
+
+	// 邮件模板，目录结构为 {语言}/{模板名}.html 或 {语言}/{模板名}_{用途}.html，未找到时使用内置模板
+	TemplateDir     string `mapstructure:"template_dir"`
+	DefaultLanguage string `mapstructure:"default_language"` // 请求未携带或不支持的语言使用该语言，默认 zh
+	BrandName       string `mapstructure:"brand_name"`       // 邮件中展示的产品名称
+	LogoURL         string `mapstructure:"logo_url"`         // 邮件顶部的 Logo 图片地址，为空时不展示
+	SupportEmail    string `mapstructure:"support_email"`    // 邮件底部的联系邮箱，为空时不展示
 }
 
+// COSConfig 对象存储配置，driver 选择存储服务，顶层的密钥、地域、存储桶为腾讯云 COS 的配置
 type COSConfig struct {
+	Driver      string `mapstructure:"driver"` // cos（默认）、s3、minio、oss、local
 	SecretID    string `mapstructure:"secret_id"`
 	SecretKey   string `mapstructure:"secret_key"`
 	Region      string `mapstructure:"region"`
@@ -186,17 +441,1083 @@ type COSConfig struct {
 	AppID       string `mapstructure:"app_id"`
 	BaseURL     string `mapstructure:"base_url"`
 	STSDuration int64  `mapstructure:"sts_duration"`
+	// Download 文件下载链接
+	Download COSDownloadConfig `mapstructure:"download"`
+	// CDN base_url 指向 CDN 时的链接鉴权
+	CDN COSCDNConfig `mapstructure:"cdn"`
+	// STSPurposes 按文件用途覆盖临时凭证允许的操作与有效期，键为 avatar、attachment、export
+	STSPurposes map[string]STSPurposeConfig `mapstructure:"sts_purposes"`
+	// 其他存储服务，只读取 driver 对应的一项
+	S3    S3StorageConfig    `mapstructure:"s3"`
+	MinIO S3StorageConfig    `mapstructure:"minio"`
+	OSS   OSSStorageConfig   `mapstructure:"oss"`
+	Local LocalStorageConfig `mapstructure:"local"`
+}
+
+// 对象存储服务
+const (
+	StorageDriverCOS   = "cos"
+	StorageDriverS3    = "s3"
+	StorageDriverMinIO = "minio"
+	StorageDriverOSS   = "oss"
+	StorageDriverLocal = "local"
+)
+
+// DriverName 当前使用的存储服务，未配置时为 cos
+func (c COSConfig) DriverName() string {
+	if c.Driver == "" {
+		return StorageDriverCOS
+	}
+	return strings.ToLower(c.Driver)
+}
+
+// DeliveryBaseURL 当前存储服务的访问地址前缀，未配置时为空
+func (c COSConfig) DeliveryBaseURL() string {
+	switch c.DriverName() {
+	case StorageDriverS3:
+		return c.S3.BaseURL
+	case StorageDriverMinIO:
+		return c.MinIO.BaseURL
+	case StorageDriverOSS:
+		return c.OSS.BaseURL
+	case StorageDriverLocal:
+		return c.Local.BaseURL
+	default:
+		return c.BaseURL
+	}
+}
+
+// ProxyDownload 下载链接是否经服务端转发，本地存储无法生成签名链接，始终转发
+func (c COSConfig) ProxyDownload() bool {
+	return c.Download.Proxy() || c.DriverName() == StorageDriverLocal
+}
+
+// 临时凭证允许的操作
+const (
+	STSActionPut       = "put"       // 简单上传
+	STSActionGet       = "get"       // 下载
+	STSActionMultipart = "multipart" // 分片上传
+)
+
+// STSPurposeConfig 某一用途的临时凭证只能访问 user/{userID}/{purpose}/ 下的文件
+type STSPurposeConfig struct {
+	Actions            []string `mapstructure:"actions"`              // 允许的操作：put、get、multipart
+	DurationSeconds    int64    `mapstructure:"duration_seconds"`     // 默认有效期（秒），未配置时使用 sts_duration，不超过上限
+	MaxDurationSeconds int64    `mapstructure:"max_duration_seconds"` // 有效期上限（秒）
+}
+
+// defaultSTSPurposes 各用途默认的权限与有效期上限：头像只能上传，导出产物只能下载
+var defaultSTSPurposes = map[string]STSPurposeConfig{
+	"avatar":     {Actions: []string{STSActionPut}, MaxDurationSeconds: 900},
+	"attachment": {Actions: []string{STSActionPut, STSActionMultipart}, MaxDurationSeconds: 3600},
+	"export":     {Actions: []string{STSActionGet}, MaxDurationSeconds: 900},
+}
+
+// STSPurpose 用途的临时凭证配置，配置项覆盖默认值；不支持的用途返回 false
+func (c COSConfig) STSPurpose(purpose string) (STSPurposeConfig, bool) {
+	cfg, ok := defaultSTSPurposes[purpose]
+	if !ok {
+		return STSPurposeConfig{}, false
+	}
+	override := c.STSPurposes[purpose]
+	if len(override.Actions) > 0 {
+		cfg.Actions = override.Actions
+	}
+	if override.MaxDurationSeconds > 0 {
+		cfg.MaxDurationSeconds = override.MaxDurationSeconds
+	}
+	cfg.DurationSeconds = c.STSDuration
+	if override.DurationSeconds > 0 {
+		cfg.DurationSeconds = override.DurationSeconds
+	}
+	if cfg.DurationSeconds <= 0 || cfg.DurationSeconds > cfg.MaxDurationSeconds {
+		cfg.DurationSeconds = cfg.MaxDurationSeconds
+	}
+	return cfg, true
+}
+
+// S3StorageConfig S3 兼容的对象存储（AWS S3、MinIO）
+type S3StorageConfig struct {
+	Endpoint        string `mapstructure:"endpoint"` // 服务地址，s3 默认 https://s3.{region}.amazonaws.com，minio 必填，如 http://minio:9000
+	Region          string `mapstructure:"region"`   // 默认 us-east-1
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PathStyle       bool   `mapstructure:"path_style"`   // 按 endpoint/bucket/key 访问，minio 始终使用
+	RoleARN         string `mapstructure:"role_arn"`     // 颁发临时凭证时扮演的角色，s3 必填，minio 可为空
+	STSEndpoint     string `mapstructure:"sts_endpoint"` // s3 默认 https://sts.{region}.amazonaws.com，minio 默认与 endpoint 相同
+	BaseURL         string `mapstructure:"base_url"`     // 上传后返回的访问地址前缀，默认为存储桶地址
+}
+
+// OSSStorageConfig 阿里云对象存储
+type OSSStorageConfig struct {
+	Endpoint        string `mapstructure:"endpoint"` // 地域服务地址，如 https://oss-cn-hangzhou.aliyuncs.com
+	Region          string `mapstructure:"region"`   // 如 cn-hangzhou，颁发临时凭证时返回给前端
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	AccessKeySecret string `mapstructure:"access_key_secret"`
+	RoleARN         string `mapstructure:"role_arn"`     // 颁发临时凭证时扮演的 RAM 角色，为空时不支持临时凭证
+	STSEndpoint     string `mapstructure:"sts_endpoint"` // 默认 https://sts.aliyuncs.com
+	BaseURL         string `mapstructure:"base_url"`     // 上传后返回的访问地址前缀，默认为存储桶地址
+}
+
+// LocalStorageConfig 本地磁盘存储，适合单机部署；下载链接始终经服务端转发
+type LocalStorageConfig struct {
+	Dir     string `mapstructure:"dir"`      // 文件根目录，默认 data/storage
+	BaseURL string `mapstructure:"base_url"` // 上传后返回的访问地址前缀（需自行配置静态访问），未配置时返回资源路径
+}
+
+// COSDownloadConfig 文件下载链接配置
+// presign 模式返回 COS 的签名链接，浏览器直接从 COS 下载；
+// proxy 模式返回指向本服务的签名链接，由服务端从 COS 读取后转发，存储桶无需开放任何访问
+type COSDownloadConfig struct {
+	Mode             string `mapstructure:"mode"`               // presign（默认）/proxy
+	Secret           string `mapstructure:"secret"`             // proxy 链接签名密钥，未配置时复用JWT密钥
+	ProxyURL         string `mapstructure:"proxy_url"`          // proxy 链接地址，如 https://example.com/api/biz/v1/cos/download，未配置时返回相对路径
+	ExpireSeconds    int    `mapstructure:"expire_seconds"`     // 未指定有效期时的默认值，默认 600 秒
+	MaxExpireSeconds int    `mapstructure:"max_expire_seconds"` // 有效期上限，默认 3600 秒
+}
+
+// COSCDNConfig 存储桶为私有、由 CDN 回源时，base_url 下的链接按 CDN 的鉴权方式签名后返回
+// 头像等长期展示的链接按 expire_seconds 签名，下载链接按请求的有效期签名
+type COSCDNConfig struct {
+	SignType      string `mapstructure:"sign_type"`      // 鉴权方式：a（type-A）、d（type-D），为空时不签名
+	Key           string `mapstructure:"key"`            // CDN 控制台配置的鉴权密钥
+	ExpireSeconds int    `mapstructure:"expire_seconds"` // 头像等链接的有效期，默认 86400 秒
+	ValidSeconds  int    `mapstructure:"valid_seconds"`  // CDN 控制台配置的鉴权有效时长，签名时间取过期时间减去该时长，使链接在要求的时间失效
+	SignParam     string `mapstructure:"sign_param"`     // 签名参数名，默认 sign
+	TimeParam     string `mapstructure:"time_param"`     // type-D 的时间戳参数名，默认 t
+}
+
+// CDN 鉴权方式
+const (
+	CDNSignTypeA = "a"
+	CDNSignTypeD = "d"
+)
+
+// Enabled 是否对 CDN 链接签名
+func (c COSCDNConfig) Enabled() bool {
+	return c.SignType != "" && c.Key != ""
+}
+
+func (c COSCDNConfig) Expiration() time.Duration {
+	return durationOrDefault(c.ExpireSeconds, time.Second, 24*time.Hour)
+}
+
+// SignTime 链接在 expiresAt 失效时使用的签名时间
+func (c COSCDNConfig) SignTime(expiresAt time.Time) time.Time {
+	return expiresAt.Add(-time.Duration(c.ValidSeconds) * time.Second)
+}
+
+func (c COSCDNConfig) SignParamName() string {
+	if c.SignParam == "" {
+		return "sign"
+	}
+	return c.SignParam
+}
+
+func (c COSCDNConfig) TimeParamName() string {
+	if c.TimeParam == "" {
+		return "t"
+	}
+	return c.TimeParam
+}
+
+// 下载链接模式
+const (
+	COSDownloadModePresign = "presign"
+	COSDownloadModeProxy   = "proxy"
+)
+
+// 未配置 proxy_url 时返回的转发下载地址
+const defaultDownloadProxyPath = "/api/biz/v1/cos/download"
+
+func (c COSDownloadConfig) Proxy() bool {
+	return c.Mode == COSDownloadModeProxy
+}
+
+// ProxyDownloadURL 带令牌的转发下载地址
+func (c COSDownloadConfig) ProxyDownloadURL(token string) string {
+	proxyURL := c.ProxyURL
+	if proxyURL == "" {
+		proxyURL = defaultDownloadProxyPath
+	}
+	return proxyURL + "?token=" + url.QueryEscape(token)
+}
+
+func (c COSDownloadConfig) DefaultExpiration() time.Duration {
+	return durationOrDefault(c.ExpireSeconds, time.Second, 10*time.Minute)
+}
+
+func (c COSDownloadConfig) MaxExpiration() time.Duration {
+	return durationOrDefault(c.MaxExpireSeconds, time.Second, time.Hour)
 }
 
 type AiChatConfig struct {
-	ApiKey               string `mapstructure:"api_key"`
-	ModelName            string `mapstructure:"model_name"`
-	SystemPrompt         string `mapstructure:"system_prompt"`
-	UpdateSystemPrompt   string `mapstructure:"update_system_prompt"`
-	GenerateSystemPrompt string `mapstructure:"generate_system_prompt"`
+	ApiKey               string              `mapstructure:"api_key"`    // 未配置 providers 时使用的 ark 密钥，兼容旧配置
+	ModelName            string              `mapstructure:"model_name"` // 未配置 providers 时使用的 ark 模型
+	Providers            []AiProviderConfig  `mapstructure:"providers"`  // 模型提供方，按顺序回退，第一个为默认
+	SystemPrompt         string              `mapstructure:"system_prompt"`
+	UpdateSystemPrompt   string              `mapstructure:"update_system_prompt"`
+	GenerateSystemPrompt string              `mapstructure:"generate_system_prompt"`
+	SummarizePrompt      string              `mapstructure:"summarize_system_prompt"` // 总结导图的系统提示词，未配置时使用内置提示词
+	HistoryMaxMessages   int                 `mapstructure:"history_max_messages"`    // 每次发送给模型的历史消息条数上限（不含系统提示词），默认 40
+	HistoryMaxTokens     int                 `mapstructure:"history_max_tokens"`      // 每次发送给模型的历史消息估算 token 上限（不含系统提示词），默认 8000
+	Document             DocumentConfig      `mapstructure:"document"`                // 生成导图时上传文件的解析限制
+	ModelParams          ModelParamsConfig   `mapstructure:"model_params"`            // 对话模型参数的默认值与上限
+	GenerateCache        GenerateCacheConfig `mapstructure:"generate_cache"`          // 生成导图结果缓存
+	ImageInput           ImageInputConfig    `mapstructure:"image_input"`             // 对话中附带图片的限制与支持图片的提供方
+	Resilience           AiResilienceConfig  `mapstructure:"resilience"`              // 提供方调用失败时的重试与熔断
+}
+
+// AiResilienceConfig 模型调用的重试与熔断
+// 超时、网络错误、限流与 5xx 视为临时失败：先在同一提供方按指数退避重试，仍失败时回退到下一个提供方
+// 同一提供方连续临时失败达到阈值后熔断，冷却期内直接跳过，冷却结束后放行一次试探调用
+type AiResilienceConfig struct {
+	MaxAttempts            int `mapstructure:"max_attempts"`             // 每个提供方的最多调用次数（含首次），默认 2，1 表示不重试
+	BackoffBaseMs          int `mapstructure:"backoff_base_ms"`          // 首次重试前的等待时间，之后逐次翻倍并加随机抖动，默认 200ms
+	BackoffMaxMs           int `mapstructure:"backoff_max_ms"`           // 重试等待时间上限，默认 2000ms
+	BreakerThreshold       int `mapstructure:"breaker_threshold"`        // 连续临时失败多少次后熔断，默认 5
+	BreakerCooldownSeconds int `mapstructure:"breaker_cooldown_seconds"` // 熔断后的冷却时间，默认 30s
+	BudgetSeconds          int `mapstructure:"budget_seconds"`           // 一次请求在所有提供方上重试与回退的总时长上限，默认 300s
+}
+
+func (r AiResilienceConfig) Attempts() int {
+	return int(int64OrDefault(r.MaxAttempts, 2))
+}
+
+func (r AiResilienceConfig) BackoffBase() time.Duration {
+	return durationOrDefault(r.BackoffBaseMs, time.Millisecond, 200*time.Millisecond)
+}
+
+func (r AiResilienceConfig) BackoffMax() time.Duration {
+	return durationOrDefault(r.BackoffMaxMs, time.Millisecond, 2*time.Second)
+}
+
+func (r AiResilienceConfig) FailureThreshold() int {
+	return int(int64OrDefault(r.BreakerThreshold, 5))
+}
+
+func (r AiResilienceConfig) BreakerCooldown() time.Duration {
+	return durationOrDefault(r.BreakerCooldownSeconds, time.Second, 30*time.Second)
+}
+
+func (r AiResilienceConfig) Budget() time.Duration {
+	return durationOrDefault(r.BudgetSeconds, time.Second, 5*time.Minute)
+}
+
+// ImageInputConfig 对话中附带的图片
+// 所选提供方不支持图片时改用 vision_provider，未配置时使用第一个支持图片的提供方（providers 中 vision 为 true）
+type ImageInputConfig struct {
+	VisionProvider string `mapstructure:"vision_provider"` // 处理图片消息的提供方名称
+	MaxImages      int    `mapstructure:"max_images"`      // 每条消息附带的图片数上限，也是每次发送给模型的图片数上限，默认 4
+	MaxSizeMB      int    `mapstructure:"max_size_mb"`     // 单张图片大小上限，默认 5MB
+}
+
+func (i ImageInputConfig) ImageLimit() int {
+	return int(int64OrDefault(i.MaxImages, 4))
+}
+
+func (i ImageInputConfig) MaxImageSize() int64 {
+	return int64OrDefault(i.MaxSizeMB, 5) << 20
+}
+
+// GenerateCacheConfig 生成导图结果缓存，同一用户相同的输入文本在有效期内直接返回缓存的导图
+type GenerateCacheConfig struct {
+	Disabled   bool `mapstructure:"disabled"`
+	TTLMinutes int  `mapstructure:"ttl_minutes"` // 缓存有效期，默认 1440 分钟
+}
+
+func (g GenerateCacheConfig) TTL() time.Duration {
+	return durationOrDefault(g.TTLMinutes, time.Minute, 24*time.Hour)
+}
+
+// ModelParamsConfig 对话模型参数的默认值，请求与会话预设都未指定的参数使用这里的值，未配置时使用提供方默认值
+type ModelParamsConfig struct {
+	Temperature      *float32 `mapstructure:"temperature"`
+	TopP             *float32 `mapstructure:"top_p"`
+	MaxTokens        *int     `mapstructure:"max_tokens"`
+	FrequencyPenalty *float32 `mapstructure:"frequency_penalty"`
+	MaxTokensLimit   int      `mapstructure:"max_tokens_limit"` // 单次回复 max_tokens 的上限，超出时按上限处理，默认 8192
+}
+
+func (m ModelParamsConfig) MaxTokensCap() int {
+	return int(int64OrDefault(m.MaxTokensLimit, 8192))
+}
+
+// DocumentConfig 上传文件解析限制
+type DocumentConfig struct {
+	MaxFileSizeMB int `mapstructure:"max_file_size_mb"` // 文件大小上限，默认 20MB
+	MaxPages      int `mapstructure:"max_pages"`        // PDF 页数、PPTX 幻灯片数上限，默认 200
+	MaxTextLength int `mapstructure:"max_text_length"`  // 提取出的正文交给模型的最大字符数，超出部分截断，默认 50000
+}
+
+func (d DocumentConfig) MaxFileSize() int64 {
+	return int64OrDefault(d.MaxFileSizeMB, 20) << 20
+}
+
+func (d DocumentConfig) PageLimit() int {
+	return int(int64OrDefault(d.MaxPages, 200))
+}
+
+func (d DocumentConfig) TextLimit() int {
+	return int(int64OrDefault(d.MaxTextLength, 50000))
+}
+
+// defaultSummarizePrompt 总结导图的内置系统提示词
+const defaultSummarizePrompt = `你是思维导图分析助手。用户会给出一张思维导图的标题和缩进大纲，请先用一段话概括导图的整体内容，再按一级分支的顺序逐个提炼 1 到 5 条要点。
+只输出 JSON，不要注释、不要 Markdown 包裹，格式为：
+{"summary":"整体总结","branches":[{"title":"一级分支标题","key_points":["要点"]}]}`
+
+// SummarizeSystemPrompt 总结导图的系统提示词
+func (a AiChatConfig) SummarizeSystemPrompt() string {
+	if a.SummarizePrompt == "" {
+		return defaultSummarizePrompt
+	}
+	return a.SummarizePrompt
+}
+
+// HistoryWindow 发送给模型的历史消息条数与 token 上限
+func (a AiChatConfig) HistoryWindow() (maxMessages, maxTokens int) {
+	maxMessages, maxTokens = a.HistoryMaxMessages, a.HistoryMaxTokens
+	if maxMessages <= 0 {
+		maxMessages = 40
+	}
+	if maxTokens <= 0 {
+		maxTokens = 8000
+	}
+	return maxMessages, maxTokens
+}
+
+// AI 模型提供方类型，均通过 OpenAI 兼容的 chat/completions 接口调用
+const (
+	AiProviderArk      = "ark"
+	AiProviderOpenAI   = "openai" // OpenAI 及其他 OpenAI 兼容接口
+	AiProviderAzure    = "azure"
+	AiProviderOllama   = "ollama"
+	AiProviderDeepSeek = "deepseek"
+)
+
+// AiProviderConfig 单个模型提供方
+type AiProviderConfig struct {
+	Name           string `mapstructure:"name"`            // 提供方名称，唯一，请求按名称或模型选择提供方，为空时使用 type
+	Type           string `mapstructure:"type"`            // ark、openai、azure、ollama、deepseek
+	BaseURL        string `mapstructure:"base_url"`        // 接口地址，为空时使用各类型的默认地址；azure 为资源地址 https://{resource}.openai.azure.com
+	ApiKey         string `mapstructure:"api_key"`         // ollama 可不填
+	Model          string `mapstructure:"model"`           // 模型名称，azure 为部署名称
+	APIVersion     string `mapstructure:"api_version"`     // 仅 azure 使用，默认 2024-06-01
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // 单次调用超时，默认使用 timeout.ai_seconds
+	Vision         bool   `mapstructure:"vision"`          // 模型是否支持图片输入
+}
+
+// ProviderList 按回退顺序返回模型提供方，未配置 providers 时使用旧配置的 ark 模型
+func (a AiChatConfig) ProviderList() []AiProviderConfig {
+	if len(a.Providers) == 0 {
+		return []AiProviderConfig{{Name: AiProviderArk, Type: AiProviderArk, ApiKey: a.ApiKey, Model: a.ModelName}}
+	}
+	providers := make([]AiProviderConfig, 0, len(a.Providers))
+	for _, provider := range a.Providers {
+		if provider.Name == "" {
+			provider.Name = provider.Type
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// Timeout 单次调用超时，未配置时使用 fallback
+func (p AiProviderConfig) Timeout(fallback time.Duration) time.Duration {
+	return durationOrDefault(p.TimeoutSeconds, time.Second, fallback)
 }
 
+// SMSConfig 短信通道配置，driver 选择服务商，未配置时使用 http 通道
 type SMSConfig struct {
-	Key      string `mapstructure:"key"`
-	Endpoint string `mapstructure:"endpoint"`
+	Driver              string           `mapstructure:"driver"`               // http、aliyun、tencent、twilio
+	InternationalDriver string           `mapstructure:"international_driver"` // 发送境外号码（区号不是 country_code）使用的服务商，为空时与 driver 相同
+	Key                 string           `mapstructure:"key"`                  // http 通道的密钥
+	Endpoint            string           `mapstructure:"endpoint"`             // http 通道的请求地址模板，依次填入 key、验证码、手机号
+	CountryCode         string           `mapstructure:"country_code"`         // 国内号码的区号，手机号未带国际区号时补全，默认 86
+	Aliyun              AliyunSMSConfig  `mapstructure:"aliyun"`
+	Tencent             TencentSMSConfig `mapstructure:"tencent"`
+	Twilio              TwilioSMSConfig  `mapstructure:"twilio"`
+}
+
+// AliyunSMSConfig 阿里云短信，模板中验证码变量名为 code
+type AliyunSMSConfig struct {
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	AccessKeySecret string `mapstructure:"access_key_secret"`
+	RegionID        string `mapstructure:"region_id"` // 默认 cn-hangzhou
+	SignName        string `mapstructure:"sign_name"`
+	TemplateCode    string `mapstructure:"template_code"`
+}
+
+// TencentSMSConfig 腾讯云短信，模板只包含一个验证码变量 {1}
+type TencentSMSConfig struct {
+	SecretID   string `mapstructure:"secret_id"`
+	SecretKey  string `mapstructure:"secret_key"`
+	Region     string `mapstructure:"region"` // 默认 ap-guangzhou
+	SdkAppID   string `mapstructure:"sdk_app_id"`
+	SignName   string `mapstructure:"sign_name"`
+	TemplateID string `mapstructure:"template_id"`
+}
+
+// TwilioSMSConfig Twilio 短信，配置 content_sid 时使用内容模板（变量 {{1}} 为验证码），否则按 body 发送文本
+type TwilioSMSConfig struct {
+	AccountSID          string `mapstructure:"account_sid"`
+	AuthToken           string `mapstructure:"auth_token"`
+	From                string `mapstructure:"from"`                  // 发送号码，与 messaging_service_sid 二选一
+	MessagingServiceSID string `mapstructure:"messaging_service_sid"` // 消息服务
+	ContentSID          string `mapstructure:"content_sid"`           // 内容模板ID
+	Body                string `mapstructure:"body"`                  // 文本模板，%s 为验证码，默认 "Your verification code is %s"
+}
+
+// PlanConfig 单个套餐的配额，逐项覆盖代码内的默认值：未配置或为 0 的项使用默认值，-1 表示不限制
+// 代码内没有默认值的新套餐，未配置的项不限制
+type PlanConfig struct {
+	MaxMindMaps         int64 `mapstructure:"max_mind_maps"`
+	MaxNodesPerMap      int64 `mapstructure:"max_nodes_per_map"`
+	MaxStorageMB        int64 `mapstructure:"max_storage_mb"`
+	AIRequestsPerDay    int64 `mapstructure:"ai_requests_per_day"`
+	AIRequestsPerMinute int64 `mapstructure:"ai_requests_per_minute"`
+	AIConcurrency       int64 `mapstructure:"ai_concurrency"`
+	AITokensPerDay      int64 `mapstructure:"ai_tokens_per_day"`
+	AITokensPerMonth    int64 `mapstructure:"ai_tokens_per_month"`
+}
+
+// AdminConfig 管理员配置
+type AdminConfig struct {
+	UserIDs []string `mapstructure:"user_ids"` // 启动时授予管理员角色的用户ID，从配置中移除不会撤销已授予的角色
+}
+
+// BillingConfig 支付配置，secret_key 为空时不开启支付
+type BillingConfig struct {
+	Provider      string                          `mapstructure:"provider"` // 支付渠道，目前支持 stripe
+	SecretKey     string                          `mapstructure:"secret_key"`
+	WebhookSecret string                          `mapstructure:"webhook_secret"` // 回调签名密钥
+	SuccessURL    string                          `mapstructure:"success_url"`
+	CancelURL     string                          `mapstructure:"cancel_url"`
+	Products      map[string]BillingProductConfig `mapstructure:"products"` // 可购买的商品，key 为商品标识
+}
+
+// BillingProductConfig 可购买的商品
+type BillingProductConfig struct {
+	Name       string `mapstructure:"name"`        // 展示名称
+	Plan       string `mapstructure:"plan"`        // 购买后生效的套餐
+	PeriodDays int    `mapstructure:"period_days"` // 套餐时长（天）
+	Amount     int64  `mapstructure:"amount"`      // 金额，最小货币单位（如分）
+	Currency   string `mapstructure:"currency"`    // 币种，如 cny
+}
+
+// CozeConfig coze 工作流配置，token 为空时不调用工作流
+type CozeConfig struct {
+	BaseURL            string `mapstructure:"base_url"` // 默认 https://api.coze.cn
+	Token              string `mapstructure:"token"`    // 个人访问令牌或 OAuth access token
+	TimeoutSeconds     int    `mapstructure:"timeout_seconds"`
+	MaxRetries         int    `mapstructure:"max_retries"`          // 网络错误、429、5xx 时的重试次数
+	RegisterWorkflowID string `mapstructure:"register_workflow_id"` // 注册后补全用户资料的工作流，为空时不执行
+}
+
+// LoopConfig cozeloop 链路追踪配置，enable 为 false 时所有打点都是空操作
+// ref: https://loop.coze.cn/open/docs/cozeloop/sdk
+type LoopConfig struct {
+	Enable      bool   `mapstructure:"enable"`
+	APIToken    string `mapstructure:"api_token"`    // 个人访问令牌
+	WorkspaceID string `mapstructure:"workspace_id"` // 空间ID
+	BaseURL     string `mapstructure:"base_url"`     // 为空时使用 SDK 默认地址
+}
+
+// TracingConfig OpenTelemetry 链路追踪配置，span 以 OTLP/HTTP（JSON）上报到 collector
+// 与 cozeloop 互不影响，可以同时开启
+type TracingConfig struct {
+	Enabled         bool              `mapstructure:"enabled"`
+	ServiceName     string            `mapstructure:"service_name"`      // 上报的 service.name，默认 forge
+	Endpoint        string            `mapstructure:"endpoint"`          // collector 的 OTLP/HTTP 地址，如 http://otel-collector:4318，上报到 {endpoint}/v1/traces
+	Headers         map[string]string `mapstructure:"headers"`           // 上报时附带的请求头，如鉴权信息
+	SampleRatio     float64           `mapstructure:"sample_ratio"`      // 上游未决定是否采样时的采样比例（0~1），未配置时全部采样
+	BatchSize       int               `mapstructure:"batch_size"`        // 每次上报的最大 span 数，默认 512
+	QueueSize       int               `mapstructure:"queue_size"`        // 等待上报的 span 上限，超出时丢弃，默认 2048
+	FlushIntervalMs int               `mapstructure:"flush_interval_ms"` // 上报间隔，默认 5000 毫秒
+	TimeoutMs       int               `mapstructure:"timeout_ms"`        // 单次上报的超时，默认 10000 毫秒
+}
+
+func (c TracingConfig) Service() string {
+	if c.ServiceName == "" {
+		return "forge"
+	}
+	return c.ServiceName
+}
+
+func (c TracingConfig) Ratio() float64 {
+	if c.SampleRatio <= 0 || c.SampleRatio > 1 {
+		return 1
+	}
+	return c.SampleRatio
+}
+
+func (c TracingConfig) Batch() int {
+	if c.BatchSize <= 0 {
+		return 512
+	}
+	return c.BatchSize
+}
+
+func (c TracingConfig) Queue() int {
+	if c.QueueSize <= 0 {
+		return 2048
+	}
+	return c.QueueSize
+}
+
+func (c TracingConfig) FlushInterval() time.Duration {
+	return durationOrDefault(c.FlushIntervalMs, time.Millisecond, 5*time.Second)
+}
+
+func (c TracingConfig) Timeout() time.Duration {
+	return durationOrDefault(c.TimeoutMs, time.Millisecond, 10*time.Second)
+}
+
+// CookieAuthConfig cookie 鉴权配置
+// 开启后登录时 token 写入 HttpOnly cookie 而不是返回给前端，
+// 通过 cookie 鉴权的写请求需要在 X-CSRF-Token 头中带上 csrf cookie 的值
+type CookieAuthConfig struct {
+	Enable        bool   `mapstructure:"enable"`
+	TokenCookie   string `mapstructure:"token_cookie"`   // 默认 forge_token
+	RefreshCookie string `mapstructure:"refresh_cookie"` // 默认 forge_refresh
+	CSRFCookie    string `mapstructure:"csrf_cookie"`    // 默认 forge_csrf
+	Domain        string `mapstructure:"domain"`
+	Path          string `mapstructure:"path"`      // 默认 /
+	Secure        bool   `mapstructure:"secure"`    // 生产环境应开启，仅 https 下发送
+	SameSite      string `mapstructure:"same_site"` // lax/strict/none，默认 lax
+}
+
+// TimeoutConfig 各类下游调用的超时时间，未配置（0）时使用默认值
+// 通过 context.WithTimeout 作用到每一次调用，避免某个依赖卡住拖垮整条请求链路
+type TimeoutConfig struct {
+	DBMs        int `mapstructure:"db_ms"`        // 单次数据库操作，默认 5s
+	RedisMs     int `mapstructure:"redis_ms"`     // 单次 Redis 操作，默认 1s
+	SMTPSeconds int `mapstructure:"smtp_seconds"` // 发送一封邮件，默认 15s
+	AISeconds   int `mapstructure:"ai_seconds"`   // 单次模型调用，默认 120s
+	DNSMs       int `mapstructure:"dns_ms"`       // 单次域名解析，默认 2s
+}
+
+func (t TimeoutConfig) DB() time.Duration {
+	return durationOrDefault(t.DBMs, time.Millisecond, 5*time.Second)
+}
+
+func (t TimeoutConfig) Redis() time.Duration {
+	return durationOrDefault(t.RedisMs, time.Millisecond, time.Second)
+}
+
+func (t TimeoutConfig) SMTP() time.Duration {
+	return durationOrDefault(t.SMTPSeconds, time.Second, 15*time.Second)
+}
+
+func (t TimeoutConfig) AI() time.Duration {
+	return durationOrDefault(t.AISeconds, time.Second, 120*time.Second)
+}
+
+func (t TimeoutConfig) DNS() time.Duration {
+	return durationOrDefault(t.DNSMs, time.Millisecond, 2*time.Second)
+}
+
+// BackupConfig 用户内容定时备份配置
+type BackupConfig struct {
+	Enable        bool   `mapstructure:"enable"`
+	IntervalHours int    `mapstructure:"interval_hours"` // 备份间隔，默认 24 小时
+	RetentionDays int    `mapstructure:"retention_days"` // 备份保留天数，默认 7 天
+	Prefix        string `mapstructure:"prefix"`         // COS 中的备份目录，默认 backup
+}
+
+// ContactReminderConfig 提醒只绑定了邮箱的用户绑定手机号
+// 短信通道只能发送验证码模板，因此提醒仅通过邮件发送
+type ContactReminderConfig struct {
+	Enable         bool   `mapstructure:"enable"`
+	IntervalHours  int    `mapstructure:"interval_hours"`  // 任务执行间隔，默认 24 小时
+	ResendDays     int    `mapstructure:"resend_days"`     // 同一用户两次提醒的最小间隔，默认 30 天
+	Secret         string `mapstructure:"secret"`          // 退订链接签名密钥，为空时使用 JWT 密钥
+	BindURL        string `mapstructure:"bind_url"`        // 前端绑定联系方式页面地址
+	UnsubscribeURL string `mapstructure:"unsubscribe_url"` // 退订接口的完整地址，如 https://example.com/api/biz/v1/user/unsubscribe
+}
+
+// HTTPClientConfig COS、短信、AI、支付等外部调用共用的连接池配置，未配置（0）时使用默认值
+type HTTPClientConfig struct {
+	MaxIdleConns           int    `mapstructure:"max_idle_conns"`            // 全部主机的最大空闲连接数，默认 100
+	MaxIdleConnsPerHost    int    `mapstructure:"max_idle_conns_per_host"`   // 单个主机的最大空闲连接数，默认 20
+	MaxConnsPerHost        int    `mapstructure:"max_conns_per_host"`        // 单个主机的最大连接数，默认不限制
+	IdleConnTimeoutSeconds int    `mapstructure:"idle_conn_timeout_seconds"` // 空闲连接保留时间，默认 90s
+	DialTimeoutMs          int    `mapstructure:"dial_timeout_ms"`           // 建立连接超时，默认 5s
+	TLSHandshakeTimeoutMs  int    `mapstructure:"tls_handshake_timeout_ms"`  // TLS 握手超时，默认 5s
+	ProxyURL               string `mapstructure:"proxy_url"`                 // 出口代理，为空时读取 HTTP_PROXY/HTTPS_PROXY 环境变量
+}
+
+// OAuthConfig 第三方登录配置，未开启的平台不注册
+type OAuthConfig struct {
+	WeChat OAuthProviderConfig `mapstructure:"wechat"`
+	GitHub OAuthProviderConfig `mapstructure:"github"`
+	Google OAuthProviderConfig `mapstructure:"google"`
+}
+
+type OAuthProviderConfig struct {
+	Enable       bool   `mapstructure:"enable"`
+	ClientID     string `mapstructure:"client_id"`     // 微信为 AppID，GitHub/Google 为 Client ID
+	ClientSecret string `mapstructure:"client_secret"` // 微信为 AppSecret，GitHub/Google 为 Client Secret
+	RedirectURL  string `mapstructure:"redirect_url"`  // 授权回调地址（前端页面），需与平台配置一致
+}
+
+// LoginLimitConfig 登录失败限制，防止暴力破解密码
+type LoginLimitConfig struct {
+	MaxFailures   int `mapstructure:"max_failures"`    // 同一账号在统计窗口内允许的失败次数，超过后锁定，默认 5
+	LockMinutes   int `mapstructure:"lock_minutes"`    // 账号锁定时长，默认 15 分钟
+	IPMaxFailures int `mapstructure:"ip_max_failures"` // 同一IP在统计窗口内允许的失败次数，超过后该IP无法登录，默认 20
+	WindowMinutes int `mapstructure:"window_minutes"`  // 失败次数统计窗口，默认 15 分钟
+}
+
+func (l LoginLimitConfig) AccountMaxFailures() int64 {
+	return int64OrDefault(l.MaxFailures, 5)
+}
+
+func (l LoginLimitConfig) IPMaxFailureCount() int64 {
+	return int64OrDefault(l.IPMaxFailures, 20)
+}
+
+func (l LoginLimitConfig) LockDuration() time.Duration {
+	return durationOrDefault(l.LockMinutes, time.Minute, 15*time.Minute)
+}
+
+func (l LoginLimitConfig) Window() time.Duration {
+	return durationOrDefault(l.WindowMinutes, time.Minute, 15*time.Minute)
+}
+
+// VerificationCodeConfig 短信/邮箱验证码配置，发送限制按账号与来源IP分别计数
+type VerificationCodeConfig struct {
+	ExpireMinutes    int `mapstructure:"expire_minutes"`     // 验证码有效期，默认 10 分钟
+	AccountPerMinute int `mapstructure:"account_per_minute"` // 同一账号每分钟最多发送次数，默认 1
+	AccountPerHour   int `mapstructure:"account_per_hour"`   // 同一账号每小时最多发送次数，默认 5
+	IPPerMinute      int `mapstructure:"ip_per_minute"`      // 同一IP每分钟最多发送次数，默认 5
+	IPPerHour        int `mapstructure:"ip_per_hour"`        // 同一IP每小时最多发送次数，默认 30
+	MaxAttempts      int `mapstructure:"max_attempts"`       // 同一验证码允许输错的次数，达到后验证码作废，默认 5
+}
+
+func (v VerificationCodeConfig) Expiration() time.Duration {
+	return durationOrDefault(v.ExpireMinutes, time.Minute, 10*time.Minute)
+}
+
+func (v VerificationCodeConfig) AccountMinuteLimit() int64 {
+	return int64OrDefault(v.AccountPerMinute, 1)
+}
+
+func (v VerificationCodeConfig) AccountHourLimit() int64 {
+	return int64OrDefault(v.AccountPerHour, 5)
+}
+
+func (v VerificationCodeConfig) IPMinuteLimit() int64 {
+	return int64OrDefault(v.IPPerMinute, 5)
+}
+
+func (v VerificationCodeConfig) IPHourLimit() int64 {
+	return int64OrDefault(v.IPPerHour, 30)
+}
+
+func (v VerificationCodeConfig) MaxAttemptCount() int64 {
+	return int64OrDefault(v.MaxAttempts, 5)
+}
+
+// AccountDeletionConfig 注销账号配置，申请注销后在冷静期内重新登录即可撤销
+type AccountDeletionConfig struct {
+	GraceDays     int `mapstructure:"grace_days"`     // 冷静期天数，到期后彻底删除用户数据，默认 15 天
+	IntervalHours int `mapstructure:"interval_hours"` // 清理任务执行间隔，默认 1 小时
+}
+
+func (a AccountDeletionConfig) GracePeriod() time.Duration {
+	return durationOrDefault(a.GraceDays, 24*time.Hour, 15*24*time.Hour)
+}
+
+func (a AccountDeletionConfig) Interval() time.Duration {
+	return durationOrDefault(a.IntervalHours, time.Hour, time.Hour)
+}
+
+// MagicLinkConfig 邮件免密登录配置，未配置 verify_url 时不发送登录链接
+type MagicLinkConfig struct {
+	ExpireMinutes int    `mapstructure:"expire_minutes"` // 登录链接有效期，默认 15 分钟
+	VerifyURL     string `mapstructure:"verify_url"`     // 登录链接指向的地址，如 https://example.com/api/biz/v1/user/login/magic_link/verify
+}
+
+func (m MagicLinkConfig) Expiration() time.Duration {
+	return durationOrDefault(m.ExpireMinutes, time.Minute, 15*time.Minute)
+}
+
+// PasswordHistoryConfig 历史密码配置，修改或重置密码时不能使用最近用过的密码
+type PasswordHistoryConfig struct {
+	Count int `mapstructure:"count"` // 禁止重复使用的最近密码个数（含当前密码），默认 5
+}
+
+func (p PasswordHistoryConfig) HistoryCount() int {
+	return int(int64OrDefault(p.Count, 5))
+}
+
+// PasswordPolicyConfig 密码规则，未配置的长度与种类数使用默认值（长度8-16，至少3种字符）
+type PasswordPolicyConfig struct {
+	MinLength           int      `mapstructure:"min_length"`            // 最小长度，默认 8
+	MaxLength           int      `mapstructure:"max_length"`            // 最大长度，默认 16，不能超过 72
+	MinClasses          int      `mapstructure:"min_classes"`           // 大写、小写、数字、特殊字符中至少包含的种类数，默认 3
+	RequireUpper        bool     `mapstructure:"require_upper"`         // 必须包含大写字母
+	RequireLower        bool     `mapstructure:"require_lower"`         // 必须包含小写字母
+	RequireDigit        bool     `mapstructure:"require_digit"`         // 必须包含数字
+	RequireSpecial      bool     `mapstructure:"require_special"`       // 必须包含特殊字符
+	CommonPasswords     []string `mapstructure:"common_passwords"`      // 额外禁止使用的常见密码，内置列表始终生效
+	CommonPasswordsFile string   `mapstructure:"common_passwords_file"` // 常见密码文件，每行一个
+}
+
+func (p PasswordPolicyConfig) MinLen() int {
+	return int(int64OrDefault(p.MinLength, 8))
+}
+
+func (p PasswordPolicyConfig) MaxLen() int {
+	return int(int64OrDefault(p.MaxLength, 16))
+}
+
+func (p PasswordPolicyConfig) MinClassCount() int {
+	return int(int64OrDefault(p.MinClasses, 3))
+}
+
+// AccountChangeConfig 换绑联系方式配置
+type AccountChangeConfig struct {
+	ConfirmOldContact bool `mapstructure:"confirm_old_contact"` // 换绑前需要原手机号/邮箱收到的确认码，防止会话被盗用后悄悄换绑
+}
+
+// UserCacheConfig 用户信息缓存，开启后按用户ID查询用户时先读 Redis，修改用户时清除缓存
+type UserCacheConfig struct {
+	Enable               bool `mapstructure:"enable"`
+	TTLSeconds           int  `mapstructure:"ttl_seconds"`            // 缓存时间，默认 300 秒
+	StatsIntervalMinutes int  `mapstructure:"stats_interval_minutes"` // 输出命中率日志的间隔，默认 10 分钟
+}
+
+func (u UserCacheConfig) TTL() time.Duration {
+	return durationOrDefault(u.TTLSeconds, time.Second, 5*time.Minute)
+}
+
+func (u UserCacheConfig) StatsInterval() time.Duration {
+	return durationOrDefault(u.StatsIntervalMinutes, time.Minute, 10*time.Minute)
+}
+
+// DataExportConfig 用户导出个人数据
+// 每个用户只保留最近一次导出的文件，建议在存储桶中为导出目录配置生命周期规则，过期自动删除
+type DataExportConfig struct {
+	Enable           bool   `mapstructure:"enable"`
+	Prefix           string `mapstructure:"prefix"`             // COS 中的导出目录，默认 export
+	URLExpireMinutes int    `mapstructure:"url_expire_minutes"` // 下载链接有效期，默认 60 分钟
+	TimeoutMinutes   int    `mapstructure:"timeout_minutes"`    // 单次导出的最长时间，默认 30 分钟
+}
+
+func (d DataExportConfig) URLExpiration() time.Duration {
+	return durationOrDefault(d.URLExpireMinutes, time.Minute, time.Hour)
+}
+
+func (d DataExportConfig) Timeout() time.Duration {
+	return durationOrDefault(d.TimeoutMinutes, time.Minute, 30*time.Minute)
+}
+
+// InviteConfig 邀请码配置，已登录用户可生成邀请码邀请他人注册
+// 只作用于账号注册接口，第三方登录自动创建的账号不需要邀请码
+type InviteConfig struct {
+	Required   bool `mapstructure:"required"`    // 注册时必须填写邀请码
+	Quota      int  `mapstructure:"quota"`       // 每个用户最多生成的邀请码个数，默认 10
+	MaxUses    int  `mapstructure:"max_uses"`    // 每个邀请码可使用的次数，默认 1
+	ExpireDays int  `mapstructure:"expire_days"` // 邀请码有效期，默认 30 天
+}
+
+func (i InviteConfig) CodeQuota() int64 {
+	return int64OrDefault(i.Quota, 10)
+}
+
+func (i InviteConfig) CodeMaxUses() int {
+	return int(int64OrDefault(i.MaxUses, 1))
+}
+
+func (i InviteConfig) Expiration() time.Duration {
+	return durationOrDefault(i.ExpireDays, 24*time.Hour, 30*24*time.Hour)
+}
+
+// CaptchaConfig 图形验证码配置，同一IP请求受保护接口过多时要求先完成图形验证码
+type CaptchaConfig struct {
+	Enable        bool     `mapstructure:"enable"`
+	Routes        []string `mapstructure:"routes"`         // 需要保护的接口：login、register、send_code
+	FreeAttempts  int      `mapstructure:"free_attempts"`  // 统计窗口内无需图形验证码的请求次数，默认 3
+	WindowMinutes int      `mapstructure:"window_minutes"` // 请求次数统计窗口，默认 10 分钟
+	ExpireMinutes int      `mapstructure:"expire_minutes"` // 图形验证码有效期，默认 5 分钟
+	Length        int      `mapstructure:"length"`         // 验证码字符数，默认 4
+}
+
+func (c CaptchaConfig) FreeAttemptCount() int64 {
+	return int64OrDefault(c.FreeAttempts, 3)
+}
+
+func (c CaptchaConfig) Window() time.Duration {
+	return durationOrDefault(c.WindowMinutes, time.Minute, 10*time.Minute)
+}
+
+func (c CaptchaConfig) Expiration() time.Duration {
+	return durationOrDefault(c.ExpireMinutes, time.Minute, 5*time.Minute)
+}
+
+func (c CaptchaConfig) CodeLength() int {
+	return int(int64OrDefault(c.Length, 4))
+}
+
+// RateLimitConfig 接口限流配置，令牌桶保存在 Redis 中，多实例部署时共享
+// 未登录的路由组按来源IP计数，需要登录的路由组按用户ID计数
+type RateLimitConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Default 未单独配置的路由组使用的规则
+	Default RateLimitRule `mapstructure:"default"`
+	// Groups 按路由组覆盖规则，键为 user、user_auth、mindmap、share、cos、cos_public、aichat、admin
+	Groups map[string]RateLimitRule `mapstructure:"groups"`
+}
+
+// RateLimitRule 令牌桶规则，rate 不大于 0 时不限流
+type RateLimitRule struct {
+	Rate  float64 `mapstructure:"rate"`  // 每秒补充的令牌数，即平均每秒允许的请求数
+	Burst int     `mapstructure:"burst"` // 桶容量，即允许的突发请求数，默认为 rate 向上取整
+}
+
+// Rule 路由组的限流规则，未开启或不限流时返回 false
+func (c RateLimitConfig) Rule(group string) (RateLimitRule, bool) {
+	if !c.Enable {
+		return RateLimitRule{}, false
+	}
+	rule, ok := c.Groups[group]
+	if !ok {
+		rule = c.Default
+	}
+	if rule.Rate <= 0 {
+		return RateLimitRule{}, false
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = int(math.Ceil(rule.Rate))
+	}
+	return rule, true
+}
+
+// PromptPresetConfig 对话预设配置，全局预设由管理员维护，用户预设需要单独开启
+type PromptPresetConfig struct {
+	AllowUserPresets bool `mapstructure:"allow_user_presets"` // 允许用户创建自己的预设
+	MaxUserPresets   int  `mapstructure:"max_user_presets"`   // 每个用户最多创建的预设数，默认 20
+}
+
+func (p PromptPresetConfig) UserPresetLimit() int64 {
+	return int64OrDefault(p.MaxUserPresets, 20)
+}
+
+// ModerationConfig AI对话内容审核，开启后检查用户输入与模型回复
+// 配置的关键词先在本地匹配，未命中时再交给第三方审核服务
+type ModerationConfig struct {
+	Enabled     bool                   `mapstructure:"enabled"`
+	Provider    string                 `mapstructure:"provider"`     // 第三方审核服务，目前支持 openai，为空时只使用关键词
+	Keywords    []string               `mapstructure:"keywords"`     // 本地关键词，匹配时忽略大小写与空白
+	KeywordFile string                 `mapstructure:"keyword_file"` // 关键词文件，每行一个，# 开头的行为注释
+	FailClosed  bool                   `mapstructure:"fail_closed"`  // 审核服务出错时拒绝内容，默认放行
+	TimeoutMs   int                    `mapstructure:"timeout_ms"`   // 单次调用审核服务的超时时间，默认 3000 毫秒
+	OpenAI      OpenAIModerationConfig `mapstructure:"openai"`
+}
+
+// OpenAIModerationConfig OpenAI 兼容的 /moderations 接口
+type OpenAIModerationConfig struct {
+	ApiKey  string `mapstructure:"api_key"`
+	BaseURL string `mapstructure:"base_url"` // 默认 https://api.openai.com/v1
+	Model   string `mapstructure:"model"`    // 默认 omni-moderation-latest
+}
+
+func (m ModerationConfig) Timeout() time.Duration {
+	return durationOrDefault(m.TimeoutMs, time.Millisecond, 3*time.Second)
+}
+
+// FileScanConfig 上传文件的病毒扫描，开启后在保存文件与解析文档生成导图之前扫描
+type FileScanConfig struct {
+	Enabled    bool               `mapstructure:"enabled"`
+	Provider   string             `mapstructure:"provider"`    // clamav（clamd 守护进程）或 http（云端扫描接口）
+	FailClosed bool               `mapstructure:"fail_closed"` // 扫描服务出错时拒绝文件，默认放行
+	TimeoutMs  int                `mapstructure:"timeout_ms"`  // 单个文件的扫描超时，默认 30000 毫秒
+	ClamAV     ClamAVScanConfig   `mapstructure:"clamav"`
+	HTTP       HTTPFileScanConfig `mapstructure:"http"`
+}
+
+// ClamAVScanConfig clamd 守护进程，通过 INSTREAM 命令发送文件内容
+type ClamAVScanConfig struct {
+	Address string `mapstructure:"address"` // 如 tcp://127.0.0.1:3310 或 unix:///var/run/clamav/clamd.ctl，未写协议时按 tcp
+}
+
+// HTTPFileScanConfig 云端扫描接口：以 POST 发送文件内容，返回 {"infected": bool, "signature": string}
+type HTTPFileScanConfig struct {
+	URL    string `mapstructure:"url"`
+	ApiKey string `mapstructure:"api_key"` // 以 Authorization: Bearer 发送，为空时不发送
+}
+
+func (f FileScanConfig) Timeout() time.Duration {
+	return durationOrDefault(f.TimeoutMs, time.Millisecond, 30*time.Second)
+}
+
+// AlertConfig 告警机器人配置，目前用于接口 panic 告警
+type AlertConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	Provider           string `mapstructure:"provider"`             // feishu、dingtalk、slack
+	WebhookURL         string `mapstructure:"webhook_url"`          // 机器人的 webhook 地址
+	Secret             string `mapstructure:"secret"`               // 飞书、钉钉机器人开启签名校验时的密钥
+	TimeoutMs          int    `mapstructure:"timeout_ms"`           // 发送超时，默认 5000 毫秒
+	MinIntervalSeconds int    `mapstructure:"min_interval_seconds"` // 相同告警的最短发送间隔，期间重复的告警只计数，默认 60 秒
+}
+
+func (a AlertConfig) Timeout() time.Duration {
+	return durationOrDefault(a.TimeoutMs, time.Millisecond, 5*time.Second)
+}
+
+func (a AlertConfig) MinInterval() time.Duration {
+	return durationOrDefault(a.MinIntervalSeconds, time.Second, time.Minute)
+}
+
+// BodyLimitConfig 请求体大小限制，超出时在读取请求体之前拒绝，不缓冲超大的请求
+// multipart 上传使用全局上限，具体文件大小仍由各接口校验；其他请求体（JSON、表单）按路由组限制
+type BodyLimitConfig struct {
+	Enable         bool           `mapstructure:"enable"`
+	JSONMaxKB      int            `mapstructure:"json_max_kb"`      // 非 multipart 请求体的默认上限，默认 1024 KB
+	Groups         map[string]int `mapstructure:"groups"`           // 按路由组覆盖非 multipart 请求体的上限（KB），键与 rate_limit.groups 相同
+	MultipartMaxMB int            `mapstructure:"multipart_max_mb"` // multipart 请求体的全局上限，默认 16 MB，需大于各上传接口的文件上限
+}
+
+// JSONLimit 路由组非 multipart 请求体的上限（字节）
+func (b BodyLimitConfig) JSONLimit(group string) int64 {
+	if kb, ok := b.Groups[group]; ok && kb > 0 {
+		return int64(kb) << 10
+	}
+	return int64OrDefault(b.JSONMaxKB, 1024) << 10
+}
+
+// MultipartLimit multipart 请求体的上限（字节）
+func (b BodyLimitConfig) MultipartLimit() int64 {
+	return int64OrDefault(b.MultipartMaxMB, 16) << 20
+}
+
+// OpenAPIConfig 接口文档配置，文档由路由与 def 中的请求/响应类型生成
+type OpenAPIConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`           // 提供 /api/biz/v1/openapi.json
+	SwaggerUIEnvs   []string `mapstructure:"swagger_ui_envs"`   // 同时提供 /api/biz/v1/swagger 页面的环境（app.env），默认只有 dev
+	SwaggerUIAssets string   `mapstructure:"swagger_ui_assets"` // swagger-ui-dist 静态资源地址，内网部署时可改为自建地址，默认 https://unpkg.com/swagger-ui-dist@5
+}
+
+// SwaggerUIEnabled 当前环境是否提供 Swagger UI 页面
+func (o OpenAPIConfig) SwaggerUIEnabled(env string) bool {
+	if !o.Enabled {
+		return false
+	}
+	envs := o.SwaggerUIEnvs
+	if len(envs) == 0 {
+		envs = []string{"dev"}
+	}
+	for _, e := range envs {
+		if strings.EqualFold(e, env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o OpenAPIConfig) Assets() string {
+	if o.SwaggerUIAssets == "" {
+		return "https://unpkg.com/swagger-ui-dist@5"
+	}
+	return strings.TrimSuffix(o.SwaggerUIAssets, "/")
+}
+
+// MindMapShareConfig 导图分享链接配置
+type MindMapShareConfig struct {
+	Secret                string `mapstructure:"secret"`                   // 分享链接签名密钥，未配置时复用JWT密钥
+	URL                   string `mapstructure:"url"`                      // 分享页地址，链接为 url?token=，未配置时只返回令牌
+	DefaultExpireHours    int    `mapstructure:"default_expire_hours"`     // 未指定有效期时的默认值，默认 168 小时（7天）
+	MaxExpireHours        int    `mapstructure:"max_expire_hours"`         // 有效期上限，默认 720 小时（30天）
+	MaxSharesPerMap       int    `mapstructure:"max_shares_per_map"`       // 每个导图同时有效的分享链接数，默认 10
+	MaxPasswordFailures   int    `mapstructure:"max_password_failures"`    // 同一来源IP对同一分享的密码错误次数上限，默认 5
+	PasswordFailWindowMin int    `mapstructure:"password_fail_window_min"` // 密码错误次数的统计窗口，默认 15 分钟
+}
+
+func (m MindMapShareConfig) DefaultExpiration() time.Duration {
+	return durationOrDefault(m.DefaultExpireHours, time.Hour, 7*24*time.Hour)
+}
+
+func (m MindMapShareConfig) MaxExpiration() time.Duration {
+	return durationOrDefault(m.MaxExpireHours, time.Hour, 30*24*time.Hour)
+}
+
+func (m MindMapShareConfig) ShareLimit() int64 {
+	return int64OrDefault(m.MaxSharesPerMap, 10)
+}
+
+func (m MindMapShareConfig) PasswordFailureLimit() int64 {
+	return int64OrDefault(m.MaxPasswordFailures, 5)
+}
+
+func (m MindMapShareConfig) PasswordFailWindow() time.Duration {
+	return durationOrDefault(m.PasswordFailWindowMin, time.Minute, 15*time.Minute)
+}
+
+// MindMapExportConfig 导图导出配置
+type MindMapExportConfig struct {
+	FontFile         string `mapstructure:"font_file"`          // 渲染 PNG 使用的字体文件（TTF/OTF，需包含中文字形），未配置时不支持导出 PNG
+	Prefix           string `mapstructure:"prefix"`             // 以链接方式导出时文件在 COS 中的路径前缀，默认 mindmap_export
+	URLExpireMinutes int    `mapstructure:"url_expire_minutes"` // 下载链接有效期，默认 30 分钟
+}
+
+func (m MindMapExportConfig) URLExpiration() time.Duration {
+	return durationOrDefault(m.URLExpireMinutes, time.Minute, 30*time.Minute)
+}
+
+// OutboxConfig 事件发件箱：领域事件与业务数据在同一个事务中写入发件箱表，后台任务投递到 Redis Stream 后标记已投递
+// 投递至少一次，消费方需按 event_id 去重；未开启时不记录事件
+type OutboxConfig struct {
+	Enable          bool   `mapstructure:"enable"`
+	Stream          string `mapstructure:"stream"`           // Stream 名称前缀，事件写入 {stream}:{topic}，默认 forge:events
+	MaxLen          int    `mapstructure:"max_len"`          // 每个 Stream 保留的最大消息数（近似裁剪），默认 100000
+	IntervalSeconds int    `mapstructure:"interval_seconds"` // 投递任务执行间隔，默认 5 秒
+	BatchSize       int    `mapstructure:"batch_size"`       // 每轮最多投递的事件数，默认 100
+	RetentionDays   int    `mapstructure:"retention_days"`   // 已投递事件在发件箱表中的保留天数，默认 7 天
+	MaxAttempts     int    `mapstructure:"max_attempts"`     // 单个事件最多投递次数，用完后放弃投递并告警，默认 10 次
+}
+
+func (o OutboxConfig) StreamPrefix() string {
+	if o.Stream == "" {
+		return "forge:events"
+	}
+	return o.Stream
+}
+
+func (o OutboxConfig) StreamMaxLen() int64 {
+	return int64OrDefault(o.MaxLen, 100000)
+}
+
+func (o OutboxConfig) Interval() time.Duration {
+	return durationOrDefault(o.IntervalSeconds, time.Second, 5*time.Second)
+}
+
+func (o OutboxConfig) Batch() int {
+	return int(int64OrDefault(o.BatchSize, 100))
+}
+
+func (o OutboxConfig) Retention() time.Duration {
+	return durationOrDefault(o.RetentionDays, 24*time.Hour, 7*24*time.Hour)
+}
+
+func (o OutboxConfig) AttemptLimit() int {
+	return int(int64OrDefault(o.MaxAttempts, 10))
+}
+
+func int64OrDefault(value int, def int64) int64 {
+	if value <= 0 {
+		return def
+	}
+	return int64(value)
+}
+
+func durationOrDefault(value int, unit, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return time.Duration(value) * unit
 }
@@ -0,0 +1,249 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀，变量名为前缀加上大写的配置路径，层级之间用 _ 连接
+// 如 database.dsn 对应 FORGE_DATABASE_DSN，jwt.secret_key 对应 FORGE_JWT_SECRET_KEY；
+// 环境变量优先于配置文件，列表类型的配置项用逗号分隔
+const EnvPrefix = "FORGE"
+
+// setupEnv 开启环境变量覆盖，需要在 ReadInConfig 之前调用
+// viper 只会为已知的配置项读取环境变量，这里按 config 结构体的 mapstructure 标签把所有配置项都绑定上，
+// 配置文件中没有写的配置项也可以只通过环境变量提供；map 类型的配置项（如 plans）只能覆盖配置文件中已有的键
+func setupEnv() {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+	bindEnvs(reflect.TypeOf(config{}), "")
+}
+
+func bindEnvs(t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			bindEnvs(ft, key+".")
+		case reflect.Map:
+			// 键不固定，无法提前绑定
+		default:
+			_ = viper.BindEnv(key)
+		}
+	}
+}
+
+// placeholder 配置值中的占位符：${NAME} 读取环境变量，${NAME:-默认值} 在变量未设置时使用默认值，
+// ${file:/run/secrets/xxx} 读取文件内容（去掉首尾空白），用于挂载为文件的密钥；$${ 表示字面量 ${
+var placeholder = regexp.MustCompile(`\$\$\{|\$\{([^{}]*)\}`)
+
+// interpolate 替换配置中所有字符串里的占位符，环境变量未设置且没有默认值、或文件无法读取时返回错误
+// 在 Unmarshal 之后对结构体处理，不修改 viper 中的值，环境变量覆盖的值中同样可以使用占位符
+func interpolate(c *config) error {
+	return interpolateValue(reflect.ValueOf(c).Elem(), "")
+}
+
+func interpolateValue(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return interpolateValue(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+			if name == "" {
+				name = sf.Name
+			}
+			if err := interpolateValue(v.Field(i), joinPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map 的值不可寻址，复制出来替换后再写回
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := interpolateValue(elem, joinPath(path, fmt.Sprint(iter.Key()))); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.String:
+		if !strings.Contains(v.String(), "${") {
+			return nil
+		}
+		s, err := expand(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(s)
+	}
+	return nil
+}
+
+func expand(s string) (string, error) {
+	var firstErr error
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$${" {
+			return "${"
+		}
+		expr := m[2 : len(m)-1]
+		if file, ok := strings.CutPrefix(expr, "file:"); ok {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("读取密钥文件失败: %w", err)
+				}
+				return ""
+			}
+			return strings.TrimSpace(string(data))
+		}
+		name, def, hasDef := strings.Cut(expr, ":-")
+		if val, ok := os.LookupEnv(name); ok && val != "" {
+			return val
+		}
+		if !hasDef {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("环境变量 %s 未设置", name)
+			}
+			return ""
+		}
+		return def
+	})
+	return out, firstErr
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// 名称中包含这些词的配置项视为敏感信息，打印配置时隐藏
+var sensitiveWords = map[string]bool{
+	"password": true, "pass": true, "pwd": true, "secret": true, "token": true,
+	"key": true, "dsn": true, "dsns": true, "sign": true, "credential": true, "credentials": true,
+}
+
+// redacted 打印用的配置内容，密码、密钥、DSN 等敏感配置替换为 ******，
+// webhook 地址中带有令牌，整体隐藏；其他地址中的用户名密码只隐藏密码
+func redacted(c *config) string {
+	data, err := json.Marshal(redactValue(reflect.ValueOf(c).Elem(), ""))
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func redactValue(v reflect.Value, name string) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), name)
+	case reflect.Struct:
+		t := v.Type()
+		out := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			key, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+			if key == "" {
+				key = sf.Name
+			}
+			out[key] = redactValue(v.Field(i), key)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key())
+			// headers 等 map 的键同样可能是敏感信息，如 Authorization
+			if sensitive(name) {
+				out[key] = redactString(name, fmt.Sprint(iter.Value()))
+				continue
+			}
+			out[key] = redactValue(iter.Value(), key)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), name)
+		}
+		return out
+	case reflect.String:
+		return redactString(name, v.String())
+	}
+	return v.Interface()
+}
+
+func redactString(name, s string) string {
+	if s == "" {
+		return s
+	}
+	if sensitive(name) || strings.Contains(strings.ToLower(name), "webhook") {
+		return "******"
+	}
+	// 代理、回调等地址中可能带有用户名密码
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "******")
+			return u.String()
+		}
+	}
+	return s
+}
+
+// sensitive 按 _ - 分词后判断是否包含敏感词，如 secret_key、smtp_pass、access_key_secret、api_token、authorization
+func sensitive(name string) bool {
+	name = strings.ToLower(name)
+	if name == "authorization" || name == "headers" {
+		return true
+	}
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if sensitiveWords[word] {
+			return true
+		}
+	}
+	return false
+}
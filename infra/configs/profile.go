@@ -0,0 +1,114 @@
+package configs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"forge/pkg/log/zlog"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// 多环境配置：config.yaml 为各环境共用的基础配置，同目录下的 config.{env}.yaml 只写与基础配置不同的项，
+// 按层级合并到基础配置之上（同一层级的对象逐项合并，列表整体替换）；env 由 -env 参数指定，未指定时取 app.env
+// 优先级从低到高：config.yaml、config.{env}.yaml、FORGE_ 环境变量
+
+var (
+	configFile  string // 基础配置文件
+	profileFile string // 当前环境的配置文件，未选择环境时为空
+)
+
+// profilePath 环境配置文件路径，如 conf/config.yaml 与 prod 对应 conf/config.prod.yaml
+func profilePath(path, profile string) string {
+	if profile == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// readInConfig 读取基础配置并合并当前环境的配置，环境配置文件不存在时只使用基础配置
+func readInConfig() error {
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	if profileFile == "" {
+		return nil
+	}
+	f, err := os.Open(profileFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return viper.MergeConfig(f)
+}
+
+// profileExists 当前环境的配置文件是否存在
+func profileExists() bool {
+	if profileFile == "" {
+		return false
+	}
+	_, err := os.Stat(profileFile)
+	return err == nil
+}
+
+// watchConfig 监听基础配置与环境配置，任一文件修改后重新读取并热更新
+// 编辑器保存时通常连续产生多个事件，合并 500ms 内的事件只重新读取一次
+func watchConfig() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		zlog.Errorf("无法监听配置文件变化，配置不会热更新: %v", err)
+		return
+	}
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range []string{configFile, profileFile} {
+		if file == "" {
+			continue
+		}
+		file = filepath.Clean(file)
+		files[file] = true
+		dirs[filepath.Dir(file)] = true
+	}
+	// 监听目录而不是文件，编辑器以替换文件的方式保存、环境配置文件之后才创建时都能收到事件
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			zlog.Errorf("无法监听配置目录 %s，配置不会热更新: %v", dir, err)
+			_ = watcher.Close()
+			return
+		}
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !files[filepath.Clean(event.Name)] || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+				zlog.Warnf("配置文件发生变化: %s", event.Name)
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(500*time.Millisecond, reload)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zlog.Errorf("监听配置文件出错: %v", err)
+			}
+		}
+	}()
+}
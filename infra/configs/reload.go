@@ -0,0 +1,112 @@
+package configs
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"forge/pkg/log/zlog"
+)
+
+// 配置文件修改后可以热更新的配置：
+//   - log.level
+//   - rate_limit
+//   - verification_code
+//   - ai_client 的系统提示词，以及提供方不变时各提供方的模型名称
+//
+// 其余配置在启动时用于建立连接、创建客户端，修改后需要重启才能生效，热更新时忽略并输出警告
+
+var (
+	reloadMu    sync.Mutex
+	reloadHooks []func(IConfig)
+)
+
+// OnReload 注册热更新成功后的回调，用于把新配置应用到不会每次读取配置的组件，如日志级别、模型名称
+// 回调在监听配置文件的协程中依次执行
+func OnReload(hook func(IConfig)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// reload 重新读取配置文件，校验不通过时继续使用原配置；通过后只替换可以热更新的配置
+func reload() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := readInConfig(); err != nil {
+		zlog.Errorf("配置热更新失败，无法读取配置文件，继续使用原配置: %v", err)
+		return
+	}
+	next, err := load()
+	if err != nil {
+		zlog.Errorf("配置热更新失败，继续使用原配置: %v", err)
+		return
+	}
+	cur := conf.Load()
+	merged, ignored := mergeReloadable(cur, next)
+	if len(ignored) > 0 {
+		zlog.Warnf("以下配置的修改需要重启服务才能生效，本次已忽略: %s", strings.Join(ignored, ", "))
+	}
+	if reflect.DeepEqual(cur, merged) {
+		return
+	}
+	conf.Store(merged)
+	zlog.Infof("配置热更新成功")
+	for _, hook := range reloadHooks {
+		hook(merged)
+	}
+}
+
+// mergeReloadable 以当前配置为基础，写入 next 中可以热更新的配置，返回合并后的配置与被忽略修改的配置项
+func mergeReloadable(cur, next *config) (*config, []string) {
+	merged := *cur
+	merged.LogConfig.Level = next.LogConfig.Level
+	merged.RateLimitConfig = next.RateLimitConfig
+	merged.VerificationCodeConfig = next.VerificationCodeConfig
+
+	ai := &merged.AiChatConfig
+	ai.SystemPrompt = next.AiChatConfig.SystemPrompt
+	ai.UpdateSystemPrompt = next.AiChatConfig.UpdateSystemPrompt
+	ai.GenerateSystemPrompt = next.AiChatConfig.GenerateSystemPrompt
+	ai.SummarizePrompt = next.AiChatConfig.SummarizePrompt
+	// 模型客户端按提供方创建，只有提供方的名称、类型、地址、密钥都不变时才能切换模型
+	if sameProviders(cur.AiChatConfig, next.AiChatConfig) {
+		ai.ModelName = next.AiChatConfig.ModelName
+		ai.Providers = next.AiChatConfig.Providers
+	}
+
+	return &merged, changedSections(&merged, next)
+}
+
+// sameProviders 除模型名称外，两份配置的提供方是否相同
+// azure 的模型（部署名称）在接口地址中，修改后需要重新创建客户端
+func sameProviders(a, b AiChatConfig) bool {
+	pa, pb := a.ProviderList(), b.ProviderList()
+	if len(pa) != len(pb) {
+		return false
+	}
+	for i := range pa {
+		if pa[i].Type != AiProviderAzure {
+			pa[i].Model, pb[i].Model = "", ""
+		}
+		if pa[i] != pb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// changedSections 合并后仍与 next 不同的一级配置项，即修改未生效的配置
+func changedSections(merged, next *config) []string {
+	var changed []string
+	mv, nv := reflect.ValueOf(merged).Elem(), reflect.ValueOf(next).Elem()
+	t := mv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !reflect.DeepEqual(mv.Field(i).Interface(), nv.Field(i).Interface()) {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,449 @@
+package configs
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError 配置校验不通过的所有问题，启动时一次性列出，避免改一项重启一次
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败，共 %d 项问题：", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// problems 收集校验问题，key 为配置路径，如 jwt.secret_key
+type problems []string
+
+func (p *problems) addf(key, format string, args ...any) {
+	*p = append(*p, key+": "+fmt.Sprintf(format, args...))
+}
+
+// required 必填项为空时提示对应的环境变量，密钥一般不写在配置文件中
+func (p *problems) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		p.addf(key, "不能为空，可在配置文件中设置或通过环境变量 %s 提供", envName(key))
+	}
+}
+
+func (p *problems) port(key string, value int) {
+	if value < 1 || value > 65535 {
+		p.addf(key, "端口 %d 不在 1-65535 之间", value)
+	}
+}
+
+// url 配置了时必须是带协议与主机的 http(s) 地址
+func (p *problems) url(key, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.addf(key, "%q 不是有效的 http(s) 地址", value)
+	}
+}
+
+func (p *problems) requiredURL(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		p.required(key, value)
+		return
+	}
+	p.url(key, value)
+}
+
+// oneOf 配置了时必须为 options 之一，忽略大小写
+func (p *problems) oneOf(key, value string, options ...string) {
+	if value == "" {
+		return
+	}
+	for _, o := range options {
+		if strings.EqualFold(value, o) {
+			return
+		}
+	}
+	p.addf(key, "%q 不支持，可选值为 %s", value, strings.Join(options, "、"))
+}
+
+func (p *problems) nonNegative(key string, value int) {
+	if value < 0 {
+		p.addf(key, "不能为负数")
+	}
+}
+
+// envName 配置路径对应的环境变量名
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Validate 检查必填项、端口范围、地址格式以及开启某项功能时必须同时提供的配置
+// 只检查启动时就能确定的问题，连接数据库、调用第三方服务是否成功仍在各自初始化时检查
+func (c *config) Validate() error {
+	var p problems
+
+	p.port("app.port", c.AppConfig.Port)
+	p.nonNegative("app.shutdown_timeout_seconds", c.AppConfig.ShutdownTimeoutSeconds)
+	for _, proxy := range c.ServerConfig.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				p.addf("server.trusted_proxies", "%q 不是有效的 IP 或 CIDR", proxy)
+			}
+		}
+	}
+
+	p.required("database.driver", c.DBConfig.Driver)
+	p.oneOf("database.driver", c.DBConfig.Driver, "mysql")
+	p.required("database.dsn", c.DBConfig.Dsn)
+
+	c.validateRedis(&p)
+
+	p.required("jwt.secret_key", c.JWTConfig.SecretKey)
+	p.nonNegative("jwt.expire_hours", c.JWTConfig.ExpireHours)
+	p.nonNegative("jwt.refresh_expire_hours", c.JWTConfig.RefreshExpireHours)
+	p.nonNegative("jwt.leeway_seconds", c.JWTConfig.LeewaySeconds)
+
+	if c.SnowflakeConfig.NodeID < 0 || c.SnowflakeConfig.NodeID > 1023 {
+		p.addf("snowflake.node_id", "%d 不在 0-1023 之间", c.SnowflakeConfig.NodeID)
+	}
+
+	c.validateSMTP(&p)
+	c.validateStorage(&p)
+	c.validateAiChat(&p)
+	c.validateSMS(&p)
+	c.validateBilling(&p)
+	c.validateIntegrations(&p)
+	c.validateAuth(&p)
+
+	if c.PasswordPolicyConfig.MaxLen() > 72 {
+		p.addf("password_policy.max_length", "不能超过 72（bcrypt 只使用前 72 个字节）")
+	}
+	if c.PasswordPolicyConfig.MinLen() > c.PasswordPolicyConfig.MaxLen() {
+		p.addf("password_policy.min_length", "%d 大于 max_length %d", c.PasswordPolicyConfig.MinLen(), c.PasswordPolicyConfig.MaxLen())
+	}
+	if n := c.PasswordPolicyConfig.MinClasses; n > 4 {
+		p.addf("password_policy.min_classes", "%d 超过字符种类总数 4", n)
+	}
+
+	for _, route := range c.CaptchaConfig.Routes {
+		p.oneOf("captcha.routes", route, "login", "register", "send_code")
+	}
+	for group, rule := range c.RateLimitConfig.Groups {
+		if rule.Rate < 0 || rule.Burst < 0 {
+			p.addf("rate_limit.groups."+group, "rate 与 burst 不能为负数")
+		}
+	}
+	if c.RateLimitConfig.Default.Rate < 0 || c.RateLimitConfig.Default.Burst < 0 {
+		p.addf("rate_limit.default", "rate 与 burst 不能为负数")
+	}
+	for group, kb := range c.BodyLimitConfig.Groups {
+		if kb < 0 {
+			p.addf("body_limit.groups."+group, "不能为负数")
+		}
+	}
+
+	for name, plan := range c.PlanConfigs {
+		for key, value := range map[string]int64{
+			"max_mind_maps": plan.MaxMindMaps, "max_nodes_per_map": plan.MaxNodesPerMap, "max_storage_mb": plan.MaxStorageMB,
+			"ai_requests_per_day": plan.AIRequestsPerDay, "ai_requests_per_minute": plan.AIRequestsPerMinute,
+			"ai_concurrency": plan.AIConcurrency, "ai_tokens_per_day": plan.AITokensPerDay, "ai_tokens_per_month": plan.AITokensPerMonth,
+		} {
+			if value < -1 {
+				p.addf("plans."+name+"."+key, "不能小于 -1（-1 表示不限制）")
+			}
+		}
+	}
+
+	p.url("mindmap_share.url", c.MindMapShareConfig.URL)
+	p.url("magic_link.verify_url", c.MagicLinkConfig.VerifyURL)
+	p.url("openapi.swagger_ui_assets", c.OpenAPIConfig.SwaggerUIAssets)
+
+	if len(p) > 0 {
+		// map 类型的配置遍历顺序不固定，按配置路径排序后输出
+		sort.Strings(p)
+		return &ValidationError{Problems: p}
+	}
+	return nil
+}
+
+// validateRedis 必须开启 Redis；sentinel 模式需要主节点名称与哨兵地址，cluster 模式需要节点地址
+func (c *config) validateRedis(p *problems) {
+	redis := c.RedisConfig
+	if !redis.Enable {
+		// 令牌吊销、刷新令牌、验证码、登录失败锁定与限流都保存在 Redis 中，不开启时无法登录
+		p.addf("redis.enable", "必须开启，登录态、验证码与限流依赖 Redis")
+		return
+	}
+	p.oneOf("redis.mode", redis.Mode, RedisModeStandalone, RedisModeSentinel, RedisModeCluster)
+	p.nonNegative("redis.db", redis.DB)
+	switch redis.ModeName() {
+	case RedisModeStandalone:
+		if len(redis.Addrs) == 0 {
+			p.required("redis.host", redis.Host)
+			p.port("redis.port", redis.Port)
+		} else if len(redis.Addrs) > 1 {
+			p.addf("redis.addrs", "standalone 模式只能配置一个地址，多个节点请使用 sentinel 或 cluster 模式")
+		}
+	case RedisModeSentinel:
+		p.required("redis.master_name", redis.MasterName)
+		p.required("redis.addrs", strings.Join(redis.Addrs, ","))
+	case RedisModeCluster:
+		p.required("redis.addrs", strings.Join(redis.Addrs, ","))
+	}
+	for i, addr := range redis.Addrs {
+		host, port, err := net.SplitHostPort(addr)
+		if n, _ := strconv.Atoi(port); err != nil || host == "" || n <= 0 || n > 65535 {
+			p.addf(fmt.Sprintf("redis.addrs[%d]", i), "%q 不是 host:port 格式的地址", addr)
+		}
+	}
+}
+
+// validateSMTP 配置了 smtp_host 即视为开启邮件发送，账号与密码必须同时提供
+func (c *config) validateSMTP(p *problems) {
+	smtp := c.SMTPConfig
+	if smtp.SmtpHost != "" {
+		p.port("smtp.smtp_port", smtp.SmtpPort)
+		p.required("smtp.smtp_user", smtp.SmtpUser)
+		p.required("smtp.smtp_pass", smtp.SmtpPass)
+	}
+	p.url("smtp.logo_url", smtp.LogoURL)
+
+	if c.ContactReminderConfig.Enable {
+		if smtp.SmtpHost == "" {
+			p.addf("contact_reminder.enable", "提醒通过邮件发送，需要先配置 smtp.smtp_host")
+		}
+		p.requiredURL("contact_reminder.bind_url", c.ContactReminderConfig.BindURL)
+		p.requiredURL("contact_reminder.unsubscribe_url", c.ContactReminderConfig.UnsubscribeURL)
+	}
+}
+
+func (c *config) validateStorage(p *problems) {
+	cos := c.COSConfig
+	p.oneOf("cos.driver", cos.Driver, StorageDriverCOS, StorageDriverS3, StorageDriverMinIO, StorageDriverOSS, StorageDriverLocal)
+	switch cos.DriverName() {
+	case StorageDriverCOS:
+		// 默认的存储服务，开发环境可以不配置；配置了其中一项时其余各项必须同时提供
+		if cos.SecretID != "" || cos.SecretKey != "" || cos.Bucket != "" {
+			p.required("cos.secret_id", cos.SecretID)
+			p.required("cos.secret_key", cos.SecretKey)
+			p.required("cos.region", cos.Region)
+			p.required("cos.bucket", cos.Bucket)
+		}
+		p.url("cos.base_url", cos.BaseURL)
+	case StorageDriverS3, StorageDriverMinIO:
+		key, s3 := "cos.s3", cos.S3
+		if cos.DriverName() == StorageDriverMinIO {
+			key, s3 = "cos.minio", cos.MinIO
+			p.required(key+".endpoint", s3.Endpoint)
+		}
+		p.required(key+".bucket", s3.Bucket)
+		p.required(key+".access_key_id", s3.AccessKeyID)
+		p.required(key+".secret_access_key", s3.SecretAccessKey)
+		p.url(key+".endpoint", s3.Endpoint)
+		p.url(key+".sts_endpoint", s3.STSEndpoint)
+		p.url(key+".base_url", s3.BaseURL)
+	case StorageDriverOSS:
+		p.requiredURL("cos.oss.endpoint", cos.OSS.Endpoint)
+		p.required("cos.oss.bucket", cos.OSS.Bucket)
+		p.required("cos.oss.access_key_id", cos.OSS.AccessKeyID)
+		p.required("cos.oss.access_key_secret", cos.OSS.AccessKeySecret)
+		p.url("cos.oss.sts_endpoint", cos.OSS.STSEndpoint)
+		p.url("cos.oss.base_url", cos.OSS.BaseURL)
+	case StorageDriverLocal:
+		p.url("cos.local.base_url", cos.Local.BaseURL)
+	}
+
+	p.oneOf("cos.download.mode", cos.Download.Mode, COSDownloadModePresign, COSDownloadModeProxy)
+	p.url("cos.download.proxy_url", cos.Download.ProxyURL)
+	if cos.CDN.SignType != "" {
+		p.oneOf("cos.cdn.sign_type", cos.CDN.SignType, CDNSignTypeA, CDNSignTypeD)
+		p.required("cos.cdn.key", cos.CDN.Key)
+	}
+	for purpose, sts := range cos.STSPurposes {
+		if _, ok := defaultSTSPurposes[purpose]; !ok {
+			p.addf("cos.sts_purposes."+purpose, "不支持的用途，可选值为 avatar、attachment、export")
+		}
+		for _, action := range sts.Actions {
+			p.oneOf("cos.sts_purposes."+purpose+".actions", action, STSActionPut, STSActionGet, STSActionMultipart)
+		}
+	}
+}
+
+func (c *config) validateAiChat(p *problems) {
+	ai := c.AiChatConfig
+	names := make(map[string]bool, len(ai.Providers))
+	for i, provider := range ai.Providers {
+		key := fmt.Sprintf("ai_client.providers[%d]", i)
+		p.required(key+".type", provider.Type)
+		p.oneOf(key+".type", provider.Type, AiProviderArk, AiProviderOpenAI, AiProviderAzure, AiProviderOllama, AiProviderDeepSeek)
+		p.required(key+".model", provider.Model)
+		if !strings.EqualFold(provider.Type, AiProviderOllama) {
+			p.required(key+".api_key", provider.ApiKey)
+		}
+		if strings.EqualFold(provider.Type, AiProviderAzure) {
+			p.requiredURL(key+".base_url", provider.BaseURL)
+		} else {
+			p.url(key+".base_url", provider.BaseURL)
+		}
+
+		name := provider.Name
+		if name == "" {
+			name = provider.Type
+		}
+		if names[name] {
+			p.addf(key+".name", "%q 与其他提供方重名", name)
+		}
+		names[name] = true
+	}
+	if ai.ImageInput.VisionProvider != "" && len(ai.Providers) > 0 && !names[ai.ImageInput.VisionProvider] {
+		p.addf("ai_client.image_input.vision_provider", "%q 不在 providers 中", ai.ImageInput.VisionProvider)
+	}
+}
+
+// validateSMS 配置了 driver 或 http 通道的任一项即视为开启短信，所选服务商的密钥必须提供
+func (c *config) validateSMS(p *problems) {
+	sms := c.SMSConfig
+	drivers := []string{"sms.driver", "sms.international_driver"}
+	for i, driver := range []string{sms.Driver, sms.InternationalDriver} {
+		if i == 1 && (driver == "" || strings.EqualFold(driver, sms.Driver)) {
+			continue
+		}
+		if driver == "" && sms.Key == "" && sms.Endpoint == "" {
+			continue
+		}
+		p.oneOf(drivers[i], driver, "http", "aliyun", "tencent", "twilio")
+		switch strings.ToLower(driver) {
+		case "", "http":
+			p.required("sms.key", sms.Key)
+			p.required("sms.endpoint", sms.Endpoint)
+		case "aliyun":
+			p.required("sms.aliyun.access_key_id", sms.Aliyun.AccessKeyID)
+			p.required("sms.aliyun.access_key_secret", sms.Aliyun.AccessKeySecret)
+			p.required("sms.aliyun.sign_name", sms.Aliyun.SignName)
+			p.required("sms.aliyun.template_code", sms.Aliyun.TemplateCode)
+		case "tencent":
+			p.required("sms.tencent.secret_id", sms.Tencent.SecretID)
+			p.required("sms.tencent.secret_key", sms.Tencent.SecretKey)
+			p.required("sms.tencent.sdk_app_id", sms.Tencent.SdkAppID)
+			p.required("sms.tencent.sign_name", sms.Tencent.SignName)
+			p.required("sms.tencent.template_id", sms.Tencent.TemplateID)
+		case "twilio":
+			p.required("sms.twilio.account_sid", sms.Twilio.AccountSID)
+			p.required("sms.twilio.auth_token", sms.Twilio.AuthToken)
+			if sms.Twilio.From == "" && sms.Twilio.MessagingServiceSID == "" {
+				p.addf("sms.twilio.from", "from 与 messaging_service_sid 至少配置一项")
+			}
+		}
+	}
+}
+
+// validateBilling secret_key 为空时不开启支付，其余配置不检查
+func (c *config) validateBilling(p *problems) {
+	billing := c.BillingConfig
+	if billing.SecretKey == "" {
+		return
+	}
+	p.oneOf("billing.provider", billing.Provider, "stripe")
+	p.required("billing.webhook_secret", billing.WebhookSecret)
+	p.requiredURL("billing.success_url", billing.SuccessURL)
+	p.requiredURL("billing.cancel_url", billing.CancelURL)
+	for id, product := range billing.Products {
+		key := "billing.products." + id
+		p.required(key+".plan", product.Plan)
+		p.required(key+".currency", product.Currency)
+		if product.Amount <= 0 {
+			p.addf(key+".amount", "必须大于 0")
+		}
+		if product.PeriodDays <= 0 {
+			p.addf(key+".period_days", "必须大于 0")
+		}
+	}
+}
+
+// validateIntegrations 链路追踪、内容审核、病毒扫描、告警等开启后依赖外部服务的功能
+func (c *config) validateIntegrations(p *problems) {
+	p.url("coze.base_url", c.CozeConfig.BaseURL)
+	p.url("http_client.proxy_url", c.HTTPClientConfig.ProxyURL)
+
+	if c.LoopConfig.Enable {
+		p.required("cozeloop.api_token", c.LoopConfig.APIToken)
+		p.required("cozeloop.workspace_id", c.LoopConfig.WorkspaceID)
+		p.url("cozeloop.base_url", c.LoopConfig.BaseURL)
+	}
+
+	if c.TracingConfig.Enabled {
+		p.requiredURL("tracing.endpoint", c.TracingConfig.Endpoint)
+		if r := c.TracingConfig.SampleRatio; r < 0 || r > 1 {
+			p.addf("tracing.sample_ratio", "%v 不在 0-1 之间", r)
+		}
+	}
+
+	moderation := c.ModerationConfig
+	if moderation.Enabled {
+		p.oneOf("moderation.provider", moderation.Provider, "openai")
+		if strings.EqualFold(moderation.Provider, "openai") {
+			p.required("moderation.openai.api_key", moderation.OpenAI.ApiKey)
+			p.url("moderation.openai.base_url", moderation.OpenAI.BaseURL)
+		}
+		if moderation.Provider == "" && len(moderation.Keywords) == 0 && moderation.KeywordFile == "" {
+			p.addf("moderation.enabled", "开启审核时 provider、keywords、keyword_file 至少配置一项")
+		}
+	}
+
+	scan := c.FileScanConfig
+	if scan.Enabled {
+		p.required("file_scan.provider", scan.Provider)
+		p.oneOf("file_scan.provider", scan.Provider, "clamav", "http")
+		switch strings.ToLower(scan.Provider) {
+		case "clamav":
+			p.required("file_scan.clamav.address", scan.ClamAV.Address)
+		case "http":
+			p.requiredURL("file_scan.http.url", scan.HTTP.URL)
+		}
+	}
+
+	alert := c.AlertConfig
+	if alert.Enabled {
+		p.required("alert.provider", alert.Provider)
+		p.oneOf("alert.provider", alert.Provider, "feishu", "dingtalk", "slack")
+		p.requiredURL("alert.webhook_url", alert.WebhookURL)
+	}
+}
+
+// validateAuth cookie 鉴权与第三方登录
+func (c *config) validateAuth(p *problems) {
+	cookie := c.CookieAuthConfig
+	if cookie.Enable {
+		p.oneOf("cookie_auth.same_site", cookie.SameSite, "lax", "strict", "none")
+		if strings.EqualFold(cookie.SameSite, "none") && !cookie.Secure {
+			p.addf("cookie_auth.secure", "same_site 为 none 时必须开启，否则浏览器会拒绝 cookie")
+		}
+	}
+
+	providers := []struct {
+		key string
+		cfg OAuthProviderConfig
+	}{
+		{"oauth.wechat", c.OAuthConfig.WeChat},
+		{"oauth.github", c.OAuthConfig.GitHub},
+		{"oauth.google", c.OAuthConfig.Google},
+	}
+	for _, provider := range providers {
+		if !provider.cfg.Enable {
+			continue
+		}
+		p.required(provider.key+".client_id", provider.cfg.ClientID)
+		p.required(provider.key+".client_secret", provider.cfg.ClientSecret)
+		p.requiredURL(provider.key+".redirect_url", provider.cfg.RedirectURL)
+	}
+}
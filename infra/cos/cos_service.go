@@ -6,40 +6,46 @@ import (
 	"fmt"
 	"forge/biz/adapter"
 	"forge/infra/configs"
+	"forge/infra/httpclient"
 	"forge/pkg/log/zlog"
+	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/tencentyun/cos-go-sdk-v5"
 	sts "github.com/tencentyun/qcloud-cos-sts-sdk/go"
 )
 
+// 获取临时凭证的请求超时
+const stsReqTimeout = 10 * time.Second
+
 type cosServiceImpl struct {
 	config    configs.COSConfig
 	stsClient *sts.Client // 老大原先的 用于获取临时凭证
 	cosClient *cos.Client // COS上传客户端
 }
 
-// NewCOSService 创建COS服务实例（依赖注入模式）
-// 通过构造函数接收配置，返回接口类型，便于测试和依赖注入
-func NewCOSService(cfg configs.COSConfig) adapter.COSService {
+// newTencentCOSService 腾讯云 COS，使用官方 SDK
+func newTencentCOSService(cfg configs.COSConfig) (*cosServiceImpl, error) {
 	stsClient := sts.NewClient(
 		cfg.SecretID,
 		cfg.SecretKey,
-		nil,
+		httpclient.New(stsReqTimeout),
 	)
 
 	// 创建COS上传客户端
 	// 腾讯云COS的正确格式：{bucket}-{app_id}.cos.{region}.myqcloud.com    cfg.Bucket, cfg.AppID, cfg.Region
 	bucketURL, err := url.Parse(fmt.Sprintf("https://%s-%s.cos.%s.myqcloud.com", cfg.Bucket, cfg.AppID, cfg.Region))
 	if err != nil {
-		zlog.Errorf("invalid bucket URL: %v", err)
-		panic(fmt.Sprintf("invalid bucket URL: %v", err))
+		return nil, fmt.Errorf("invalid bucket URL: %w", err)
 	}
+	// 上传下载的文件大小不定，不设置整体超时，由调用方的 ctx 控制
 	cosClient := cos.NewClient(&cos.BaseURL{BucketURL: bucketURL}, &http.Client{
 		Transport: &cos.AuthorizationTransport{
 			SecretID:  cfg.SecretID,
 			SecretKey: cfg.SecretKey,
+			Transport: httpclient.Transport(),
 		},
 	})
 
@@ -50,11 +56,24 @@ func NewCOSService(cfg configs.COSConfig) adapter.COSService {
 	}
 
 	zlog.Infof("COS service created successfully, region: %s, bucket: %s", cfg.Region, cfg.Bucket)
-	return service
+	return service, nil
+}
+
+// cosObjectActions 各操作对应的对象级别权限
+var cosObjectActions = map[string][]string{
+	configs.STSActionPut: {"name/cos:PostObject", "name/cos:PutObject"},
+	configs.STSActionGet: {"name/cos:GetObject", "name/cos:HeadObject"},
+	configs.STSActionMultipart: {
+		"name/cos:InitiateMultipartUpload",
+		"name/cos:ListParts",
+		"name/cos:UploadPart",
+		"name/cos:CompleteMultipartUpload",
+		"name/cos:AbortMultipartUpload",
+	},
 }
 
 // GetTemporaryCredentials 获取COS临时凭证
-func (c *cosServiceImpl) GetTemporaryCredentials(resourcePath string, durationSeconds int64) (*sts.CredentialResult, error) {
+func (c *cosServiceImpl) GetTemporaryCredentials(ctx context.Context, policy adapter.CredentialPolicy, durationSeconds int64) (*adapter.TemporaryCredentials, error) {
 	// 构建对象级别的资源ARN（用于具体对象的操作）
 	resourceArn := fmt.Sprintf(
 		"qcs::cos:%s:uid/%s:%s-%s/%s",
@@ -62,7 +81,7 @@ func (c *cosServiceImpl) GetTemporaryCredentials(resourcePath string, durationSe
 		c.config.AppID,
 		c.config.Bucket,
 		c.config.AppID,
-		resourcePath,
+		policy.ResourcePath,
 	)
 
 	// 构建存储桶级别的资源ARN（用于存储桶级别的操作，如 ListMultipartUploads）
@@ -74,44 +93,36 @@ func (c *cosServiceImpl) GetTemporaryCredentials(resourcePath string, durationSe
 		c.config.AppID,
 	)
 
-	// 配置STS策略
+	// 配置STS策略：Statement 1 为对象级别的操作，分片上传时 Statement 2 授权列出存储桶中的分片上传任务
 	// 注意：ListMultipartUploads 需要存储桶级别权限，必须单独授权到存储桶ARN
+	var objectActions []string
+	var multipart bool
+	for _, action := range policy.Actions {
+		actions, ok := cosObjectActions[action]
+		if !ok {
+			return nil, fmt.Errorf("unsupported credential action: %s", action)
+		}
+		objectActions = append(objectActions, actions...)
+		multipart = multipart || action == configs.STSActionMultipart
+	}
+	statements := []sts.CredentialPolicyStatement{
+		{
+			Action:   objectActions,
+			Effect:   "allow",
+			Resource: []string{resourceArn},
+		},
+	}
+	if multipart {
+		statements = append(statements, sts.CredentialPolicyStatement{
+			Action:   []string{"name/cos:ListMultipartUploads"},
+			Effect:   "allow",
+			Resource: []string{bucketArn},
+		})
+	}
 	opt := &sts.CredentialOptions{
 		DurationSeconds: durationSeconds,
 		Region:          c.config.Region,
-		Policy: &sts.CredentialPolicy{
-			Statement: []sts.CredentialPolicyStatement{
-				{
-					// Statement 1: 对象级别的操作（文件上传、下载、分片上传等）
-					Action: []string{
-						// 简单上传操作
-						"name/cos:PostObject",
-						"name/cos:PutObject",
-						"name/cos:GetObject",
-						"name/cos:HeadObject",
-						// 分片上传操作（对象级别）
-						"name/cos:InitiateMultipartUpload",
-						"name/cos:ListParts",
-						"name/cos:UploadPart",
-						"name/cos:CompleteMultipartUpload",
-					},
-					Effect: "allow",
-					Resource: []string{
-						resourceArn,
-					},
-				},
-				{
-					// Statement 2: 存储桶级别的操作（列出所有分片上传任务）
-					Action: []string{
-						"name/cos:ListMultipartUploads",
-					},
-					Effect: "allow",
-					Resource: []string{
-						bucketArn,
-					},
-				},
-			},
-		},
+		Policy:          &sts.CredentialPolicy{Statement: statements},
 	}
 
 	// 请求临时凭证
@@ -119,8 +130,22 @@ func (c *cosServiceImpl) GetTemporaryCredentials(resourcePath string, durationSe
 	if err != nil {
 		return nil, fmt.Errorf("failed to get COS STS credentials: %w", err)
 	}
+	expiration, err := time.Parse(time.RFC3339, result.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expiration time '%s': %w", result.Expiration, err)
+	}
 
-	return result, nil
+	return &adapter.TemporaryCredentials{
+		SecretID:     result.Credentials.TmpSecretID,
+		SecretKey:    result.Credentials.TmpSecretKey,
+		SessionToken: result.Credentials.SessionToken,
+		Expiration:   expiration,
+		Provider:     configs.StorageDriverCOS,
+		Region:       c.config.Region,
+		Bucket:       c.config.Bucket,
+		BaseURL:      c.config.BaseURL,
+		AccountID:    c.config.AppID,
+	}, nil
 }
 
 // UploadFile 上传文件到COS
@@ -148,3 +173,62 @@ func (c *cosServiceImpl) UploadFile(ctx context.Context, resourcePath string, fi
 	zlog.CtxInfof(ctx, "file uploaded successfully to COS, path: %s", resourcePath)
 	return fullURL, nil
 }
+
+// DeleteFile 删除COS上的文件
+func (c *cosServiceImpl) DeleteFile(ctx context.Context, resourcePath string) error {
+	_, err := c.cosClient.Object.Delete(ctx, resourcePath)
+	if err != nil {
+		// 对象不存在视为删除成功
+		if cos.IsNotFoundError(err) {
+			return nil
+		}
+		zlog.CtxErrorf(ctx, "failed to delete file from COS, path: %s, error: %v", resourcePath, err)
+		return fmt.Errorf("failed to delete file from COS: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file deleted successfully from COS, path: %s", resourcePath)
+	return nil
+}
+
+// GetPresignedURL 生成带签名的临时下载链接
+func (c *cosServiceImpl) GetPresignedURL(ctx context.Context, resourcePath string, expires time.Duration) (string, error) {
+	presignedURL, err := c.cosClient.Object.GetPresignedURL(ctx, http.MethodGet, resourcePath, c.config.SecretID, c.config.SecretKey, expires, nil)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to presign COS url, path: %s, error: %v", resourcePath, err)
+		return "", fmt.Errorf("failed to presign COS url: %w", err)
+	}
+	return presignedURL.String(), nil
+}
+
+// OpenFile 以流的方式读取COS上的文件，调用方负责关闭 Body
+func (c *cosServiceImpl) OpenFile(ctx context.Context, resourcePath string) (*adapter.FileObject, error) {
+	resp, err := c.cosClient.Object.Get(ctx, resourcePath, nil)
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return nil, adapter.ErrFileNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to open file from COS, path: %s, error: %v", resourcePath, err)
+		return nil, fmt.Errorf("failed to open file from COS: %w", err)
+	}
+	return &adapter.FileObject{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// DownloadFile 下载COS上的文件内容
+func (c *cosServiceImpl) DownloadFile(ctx context.Context, resourcePath string) ([]byte, error) {
+	resp, err := c.cosClient.Object.Get(ctx, resourcePath, nil)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to download file from COS, path: %s, error: %v", resourcePath, err)
+		return nil, fmt.Errorf("failed to download file from COS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read COS object: %w", err)
+	}
+	return data, nil
+}
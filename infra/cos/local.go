@@ -0,0 +1,156 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+const defaultLocalStorageDir = "data/storage"
+
+// localService 本地磁盘存储，资源路径映射为根目录下的文件
+// 没有签名链接，下载链接指向本服务的转发下载接口；也不能颁发临时凭证
+type localService struct {
+	dir      string
+	baseURL  string
+	download configs.COSDownloadConfig
+}
+
+func newLocalService(cfg configs.LocalStorageConfig, download configs.COSDownloadConfig) (*localService, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultLocalStorageDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create local storage dir %s: %w", dir, err)
+	}
+
+	zlog.Infof("local storage created successfully, dir: %s", dir)
+	return &localService{dir: dir, baseURL: cfg.BaseURL, download: download}, nil
+}
+
+// filePath 资源路径对应的本地文件，先按根路径规范化，../ 不能越过根目录
+func (l *localService) filePath(resourcePath string) (string, error) {
+	clean := path.Clean("/" + resourcePath)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid resource path: %q", resourcePath)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clean)), nil
+}
+
+func (l *localService) GetTemporaryCredentials(ctx context.Context, policy adapter.CredentialPolicy, durationSeconds int64) (*adapter.TemporaryCredentials, error) {
+	return nil, adapter.ErrStorageNotSupported
+}
+
+// UploadFile 先写入临时文件再重命名，读取方不会看到写了一半的文件
+func (l *localService) UploadFile(ctx context.Context, resourcePath string, fileData []byte, contentType string) (string, error) {
+	filePath, err := l.filePath(resourcePath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create local storage dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(fileData); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write local file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write local file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", fmt.Errorf("failed to save local file: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file saved successfully to local storage, path: %s", resourcePath)
+	if l.baseURL == "" {
+		return resourcePath, nil
+	}
+	fullURL, err := url.JoinPath(l.baseURL, resourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to construct file URL: %w", err)
+	}
+	return fullURL, nil
+}
+
+func (l *localService) DeleteFile(ctx context.Context, resourcePath string) error {
+	filePath, err := l.filePath(resourcePath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		zlog.CtxErrorf(ctx, "failed to delete local file, path: %s, error: %v", resourcePath, err)
+		return fmt.Errorf("failed to delete local file: %w", err)
+	}
+	return nil
+}
+
+func (l *localService) DownloadFile(ctx context.Context, resourcePath string) ([]byte, error) {
+	filePath, err := l.filePath(resourcePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, adapter.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to read local file: %w", err)
+	}
+	return data, nil
+}
+
+// GetPresignedURL 返回带签名令牌的转发下载地址
+func (l *localService) GetPresignedURL(ctx context.Context, resourcePath string, expires time.Duration) (string, error) {
+	if l.download.Secret == "" {
+		return "", fmt.Errorf("download secret is required for local storage")
+	}
+	return l.download.ProxyDownloadURL(util.SignDownloadToken(l.download.Secret, resourcePath, time.Now().Add(expires))), nil
+}
+
+func (l *localService) OpenFile(ctx context.Context, resourcePath string) (*adapter.FileObject, error) {
+	filePath, err := l.filePath(resourcePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, adapter.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to open local file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, adapter.ErrFileNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(resourcePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &adapter.FileObject{Body: file, ContentType: contentType, ContentLength: info.Size()}, nil
+}
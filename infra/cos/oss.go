@@ -0,0 +1,340 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+// ref: https://help.aliyun.com/zh/oss/developer-reference/include-signatures-in-the-authorization-header
+// ref: https://help.aliyun.com/zh/ram/developer-reference/api-sts-2015-04-01-assumerole
+const (
+	ossDefaultSTSEndpoint = "https://sts.aliyuncs.com/"
+	ossSTSVersion         = "2015-04-01"
+	ossMinSTSDuration     = 900
+)
+
+// ossService 阿里云对象存储，请求使用 OSS V1 签名（HMAC-SHA1），临时凭证通过 STS AssumeRole 颁发
+type ossService struct {
+	cfg       configs.OSSStorageConfig
+	endpoint  *url.URL
+	client    *http.Client // 上传下载，不设置整体超时，由调用方的 ctx 控制
+	stsClient *http.Client
+}
+
+func newOSSService(cfg configs.OSSStorageConfig) (*ossService, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.AccessKeySecret == "" {
+		return nil, fmt.Errorf("oss storage requires endpoint, bucket, access_key_id and access_key_secret")
+	}
+	endpoint, err := parseEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.STSEndpoint == "" {
+		cfg.STSEndpoint = ossDefaultSTSEndpoint
+	}
+
+	zlog.Infof("oss storage created successfully, endpoint: %s, bucket: %s", endpoint.Host, cfg.Bucket)
+	return &ossService{
+		cfg:       cfg,
+		endpoint:  endpoint,
+		client:    httpclient.New(0),
+		stsClient: httpclient.New(storageAPITimeout),
+	}, nil
+}
+
+type ossAssumeRoleResp struct {
+	Credentials struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		AccessKeySecret string    `json:"AccessKeySecret"`
+		SecurityToken   string    `json:"SecurityToken"`
+		Expiration      time.Time `json:"Expiration"`
+	} `json:"Credentials"`
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+}
+
+// GetTemporaryCredentials 扮演配置的 RAM 角色颁发临时凭证，并以会话策略限制允许的操作与资源路径
+func (o *ossService) GetTemporaryCredentials(ctx context.Context, policy adapter.CredentialPolicy, durationSeconds int64) (*adapter.TemporaryCredentials, error) {
+	if o.cfg.RoleARN == "" {
+		return nil, adapter.ErrStorageNotSupported
+	}
+	sessionPolicy, err := o.sessionPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := ossRandomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("generate oss sts signature nonce failed: %w", err)
+	}
+
+	params := map[string]string{
+		"AccessKeyId":      o.cfg.AccessKeyID,
+		"Action":           "AssumeRole",
+		"DurationSeconds":  strconv.FormatInt(max(durationSeconds, ossMinSTSDuration), 10),
+		"Format":           "JSON",
+		"Policy":           sessionPolicy,
+		"RoleArn":          o.cfg.RoleARN,
+		"RoleSessionName":  "forge-" + strconv.FormatInt(time.Now().Unix(), 10),
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   nonce,
+		"SignatureVersion": "1.0",
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Version":          ossSTSVersion,
+	}
+	query := ossRPCCanonicalQuery(params)
+	signature := ossRPCSign(o.cfg.AccessKeySecret, query)
+	reqURL := o.cfg.STSEndpoint + "?Signature=" + ossPercentEncode(signature) + "&" + query
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create oss sts request failed: %w", err)
+	}
+	resp, err := o.stsClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request oss sts failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ossAssumeRoleResp
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode oss sts response failed, status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oss sts failed: %s %s, requestID: %s", result.Code, result.Message, result.RequestID)
+	}
+	return &adapter.TemporaryCredentials{
+		SecretID:     result.Credentials.AccessKeyID,
+		SecretKey:    result.Credentials.AccessKeySecret,
+		SessionToken: result.Credentials.SecurityToken,
+		Expiration:   result.Credentials.Expiration,
+		Provider:     configs.StorageDriverOSS,
+		Region:       o.cfg.Region,
+		Bucket:       o.cfg.Bucket,
+		Endpoint:     o.endpoint.String(),
+		BaseURL:      o.baseURL(),
+	}, nil
+}
+
+// ossObjectActions 各操作对应的对象级别权限，分片上传的各步骤包含在 oss:PutObject 中
+var ossObjectActions = map[string][]string{
+	configs.STSActionPut:       {"oss:PutObject"},
+	configs.STSActionGet:       {"oss:GetObject"},
+	configs.STSActionMultipart: {"oss:PutObject", "oss:AbortMultipartUpload", "oss:ListParts"},
+}
+
+// sessionPolicy 只允许对指定路径的对象执行允许的操作，分片上传需要存储桶级别的列举权限
+func (o *ossService) sessionPolicy(policy adapter.CredentialPolicy) (string, error) {
+	var objectActions []string
+	var multipart bool
+	for _, action := range policy.Actions {
+		actions, ok := ossObjectActions[action]
+		if !ok {
+			return "", fmt.Errorf("unsupported credential action: %s", action)
+		}
+		objectActions = append(objectActions, actions...)
+		multipart = multipart || action == configs.STSActionMultipart
+	}
+	statements := []map[string]any{
+		{
+			"Effect":   "Allow",
+			"Action":   objectActions,
+			"Resource": []string{"acs:oss:*:*:" + o.cfg.Bucket + "/" + policy.ResourcePath},
+		},
+	}
+	if multipart {
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"oss:ListMultipartUploads"},
+			"Resource": []string{"acs:oss:*:*:" + o.cfg.Bucket},
+		})
+	}
+	data, err := json.Marshal(map[string]any{"Version": "1", "Statement": statements})
+	if err != nil {
+		return "", fmt.Errorf("marshal oss session policy failed: %w", err)
+	}
+	return string(data), nil
+}
+
+func (o *ossService) UploadFile(ctx context.Context, resourcePath string, fileData []byte, contentType string) (string, error) {
+	resp, err := o.do(ctx, http.MethodPut, resourcePath, fileData, contentType)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload file to OSS, path: %s, error: %v", resourcePath, err)
+		return "", fmt.Errorf("failed to upload file to OSS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		err := storageResponseError("oss", resp)
+		zlog.CtxErrorf(ctx, "failed to upload file to OSS, path: %s, error: %v", resourcePath, err)
+		return "", err
+	}
+
+	fullURL, err := url.JoinPath(o.baseURL(), resourcePath)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to construct file URL: %v", err)
+		return "", fmt.Errorf("failed to construct file URL: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file uploaded successfully to OSS, path: %s", resourcePath)
+	return fullURL, nil
+}
+
+func (o *ossService) DeleteFile(ctx context.Context, resourcePath string) error {
+	resp, err := o.do(ctx, http.MethodDelete, resourcePath, nil, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete file from OSS, path: %s, error: %v", resourcePath, err)
+		return fmt.Errorf("failed to delete file from OSS: %w", err)
+	}
+	defer resp.Body.Close()
+	// 对象不存在视为删除成功
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		err := storageResponseError("oss", resp)
+		zlog.CtxErrorf(ctx, "failed to delete file from OSS, path: %s, error: %v", resourcePath, err)
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "file deleted successfully from OSS, path: %s", resourcePath)
+	return nil
+}
+
+func (o *ossService) DownloadFile(ctx context.Context, resourcePath string) ([]byte, error) {
+	object, err := o.OpenFile(ctx, resourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	data, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OSS object: %w", err)
+	}
+	return data, nil
+}
+
+// GetPresignedURL 生成 URL 中携带签名的下载链接
+func (o *ossService) GetPresignedURL(ctx context.Context, resourcePath string, expires time.Duration) (string, error) {
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	signature := o.signature(http.MethodGet, "", "", expiresAt, resourcePath)
+
+	u := objectURL(o.endpoint, o.cfg.Bucket, resourcePath, false)
+	u.RawQuery = url.Values{
+		"OSSAccessKeyId": {o.cfg.AccessKeyID},
+		"Expires":        {expiresAt},
+		"Signature":      {signature},
+	}.Encode()
+	return u.String(), nil
+}
+
+// OpenFile 以流的方式读取对象，调用方负责关闭 Body
+func (o *ossService) OpenFile(ctx context.Context, resourcePath string) (*adapter.FileObject, error) {
+	resp, err := o.do(ctx, http.MethodGet, resourcePath, nil, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open file from OSS, path: %s, error: %v", resourcePath, err)
+		return nil, fmt.Errorf("failed to open file from OSS: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, adapter.ErrFileNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		err := storageResponseError("oss", resp)
+		zlog.CtxErrorf(ctx, "failed to open file from OSS, path: %s, error: %v", resourcePath, err)
+		return nil, err
+	}
+	return &adapter.FileObject{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// do 对对象发起签名请求
+func (o *ossService) do(ctx context.Context, method, resourcePath string, body []byte, contentType string) (*http.Response, error) {
+	u := objectURL(o.endpoint, o.cfg.Bucket, resourcePath, false)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Date", date)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "OSS "+o.cfg.AccessKeyID+":"+o.signature(method, "", contentType, date, resourcePath))
+	return o.client.Do(req)
+}
+
+// baseURL 上传后返回的访问地址前缀，未配置时为存储桶地址
+func (o *ossService) baseURL() string {
+	if o.cfg.BaseURL != "" {
+		return o.cfg.BaseURL
+	}
+	return objectURL(o.endpoint, o.cfg.Bucket, "", false).String()
+}
+
+// signature OSS V1 签名：对 VERB、Content-MD5、Content-Type、Date（预签名时为过期时间）与资源做 HMAC-SHA1
+// 不使用 x-oss- 请求头，CanonicalizedOSSHeaders 为空
+func (o *ossService) signature(method, contentMD5, contentType, date, resourcePath string) string {
+	stringToSign := method + "\n" + contentMD5 + "\n" + contentType + "\n" + date + "\n" + "/" + o.cfg.Bucket + "/" + resourcePath
+	mac := hmac.New(sha1.New, []byte(o.cfg.AccessKeySecret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ossRPCCanonicalQuery STS 接口为 RPC 风格，参数按名称排序后编码拼接
+func ossRPCCanonicalQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, ossPercentEncode(k)+"="+ossPercentEncode(params[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func ossRPCSign(secret, canonicalQuery string) string {
+	stringToSign := http.MethodGet + "&" + ossPercentEncode("/") + "&" + ossPercentEncode(canonicalQuery)
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ossPercentEncode 阿里云要求的 RFC3986 编码：空格为 %20，* 编码，~ 不编码
+func ossPercentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+func ossRandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,107 @@
+package cos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 调用存储服务的管理类接口（临时凭证）的请求超时
+	storageAPITimeout = 10 * time.Second
+	// 存储服务返回错误时最多读取的响应体长度
+	storageErrorBodyLimit = 4096
+)
+
+// NewCOSService 按配置的 driver 创建对象存储服务（依赖注入模式），配置有误时 panic
+// 自建部署可以使用 S3 兼容存储或本地磁盘，不需要腾讯云密钥
+func NewCOSService(cfg configs.COSConfig) adapter.COSService {
+	service, err := newStorageService(cfg)
+	if err != nil {
+		zlog.Errorf("init object storage failed: %v", err)
+		panic(fmt.Sprintf("init object storage failed: %v", err))
+	}
+	return service
+}
+
+func newStorageService(cfg configs.COSConfig) (adapter.COSService, error) {
+	service, err := newDriverService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 本地存储的下载链接始终经服务端转发，不经过 CDN
+	if cfg.CDN.Enabled() && cfg.DriverName() != configs.StorageDriverLocal {
+		return newCDNService(service, cfg.DeliveryBaseURL(), cfg.CDN)
+	}
+	return service, nil
+}
+
+func newDriverService(cfg configs.COSConfig) (adapter.COSService, error) {
+	switch driver := cfg.DriverName(); driver {
+	case configs.StorageDriverCOS:
+		return newTencentCOSService(cfg)
+	case configs.StorageDriverS3, configs.StorageDriverMinIO:
+		s3Config := cfg.S3
+		if driver == configs.StorageDriverMinIO {
+			s3Config = cfg.MinIO
+		}
+		return newS3Service(driver, s3Config)
+	case configs.StorageDriverOSS:
+		return newOSSService(cfg.OSS)
+	case configs.StorageDriverLocal:
+		return newLocalService(cfg.Local, cfg.Download)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
+
+// escapeObjectKey 按 RFC3986 编码对象键，保留路径分隔符 /，S3 与 OSS 签名时的路径使用同样的编码
+func escapeObjectKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// objectURL 对象的访问地址，pathStyle 为 true 时存储桶在路径中，否则在域名中
+func objectURL(endpoint *url.URL, bucket, key string, pathStyle bool) *url.URL {
+	u := &url.URL{Scheme: endpoint.Scheme, Host: bucket + "." + endpoint.Host, Path: "/" + key, RawPath: "/" + escapeObjectKey(key)}
+	if pathStyle {
+		u.Host = endpoint.Host
+		u.Path = "/" + bucket + u.Path
+		u.RawPath = "/" + bucket + u.RawPath
+	}
+	return u
+}
+
+// parseEndpoint 解析服务地址，未写协议时按 https 处理
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid storage endpoint: %q", endpoint)
+	}
+	return u, nil
+}
+
+// storageResponseError 存储服务返回的错误状态，附带截断后的响应体
+func storageResponseError(provider string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, storageErrorBodyLimit))
+	return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,402 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+// ref: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+const (
+	s3DefaultRegion    = "us-east-1"
+	s3SignAlgorithm    = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	s3STSVersion       = "2011-06-15"
+	s3MinSTSDuration   = 900
+	s3AMZDateFormat    = "20060102T150405Z"
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// s3Service S3 兼容的对象存储（AWS S3、MinIO），请求使用 SigV4 签名，临时凭证通过 STS AssumeRole 颁发
+type s3Service struct {
+	driver    string
+	cfg       configs.S3StorageConfig
+	endpoint  *url.URL
+	stsURL    string
+	pathStyle bool
+	client    *http.Client // 上传下载，不设置整体超时，由调用方的 ctx 控制
+	stsClient *http.Client
+}
+
+func newS3Service(driver string, cfg configs.S3StorageConfig) (*s3Service, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s storage requires bucket, access_key_id and secret_access_key", driver)
+	}
+	if cfg.Region == "" {
+		cfg.Region = s3DefaultRegion
+	}
+	pathStyle := cfg.PathStyle
+	if cfg.Endpoint == "" {
+		if driver == configs.StorageDriverMinIO {
+			return nil, fmt.Errorf("minio storage requires endpoint")
+		}
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if driver == configs.StorageDriverMinIO {
+		pathStyle = true
+	}
+	endpoint, err := parseEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	stsURL := cfg.STSEndpoint
+	if stsURL == "" {
+		if driver == configs.StorageDriverMinIO {
+			stsURL = endpoint.Scheme + "://" + endpoint.Host + "/"
+		} else {
+			stsURL = fmt.Sprintf("https://sts.%s.amazonaws.com/", cfg.Region)
+		}
+	}
+
+	zlog.Infof("%s storage created successfully, endpoint: %s, bucket: %s", driver, endpoint.Host, cfg.Bucket)
+	return &s3Service{
+		driver:    driver,
+		cfg:       cfg,
+		endpoint:  endpoint,
+		stsURL:    stsURL,
+		pathStyle: pathStyle,
+		client:    httpclient.New(0),
+		stsClient: httpclient.New(storageAPITimeout),
+	}, nil
+}
+
+type s3AssumeRoleResp struct {
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"Credentials"`
+	} `xml:"AssumeRoleResult"`
+}
+
+// GetTemporaryCredentials 通过 STS AssumeRole 颁发临时凭证，并以会话策略限制允许的操作与资源路径
+// AWS 必须配置扮演的角色；MinIO 未配置时以当前密钥的身份颁发
+func (s *s3Service) GetTemporaryCredentials(ctx context.Context, policy adapter.CredentialPolicy, durationSeconds int64) (*adapter.TemporaryCredentials, error) {
+	if s.cfg.RoleARN == "" && s.driver != configs.StorageDriverMinIO {
+		return nil, adapter.ErrStorageNotSupported
+	}
+	sessionPolicy, err := s.sessionPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {s3STSVersion},
+		"RoleSessionName": {"forge-" + strconv.FormatInt(time.Now().Unix(), 10)},
+		"DurationSeconds": {strconv.FormatInt(max(durationSeconds, s3MinSTSDuration), 10)},
+		"Policy":          {sessionPolicy},
+	}
+	if s.cfg.RoleARN != "" {
+		form.Set("RoleArn", s.cfg.RoleARN)
+	}
+	body := []byte(form.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.stsURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create %s sts request failed: %w", s.driver, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.sign(req, "sts", sha256Hex(body), time.Now())
+
+	resp, err := s.stsClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request %s sts failed: %w", s.driver, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, storageResponseError(s.driver+" sts", resp)
+	}
+
+	var result s3AssumeRoleResp
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode %s sts response failed: %w", s.driver, err)
+	}
+	credentials := result.Result.Credentials
+	return &adapter.TemporaryCredentials{
+		SecretID:     credentials.AccessKeyID,
+		SecretKey:    credentials.SecretAccessKey,
+		SessionToken: credentials.SessionToken,
+		Expiration:   credentials.Expiration,
+		Provider:     s.driver,
+		Region:       s.cfg.Region,
+		Bucket:       s.cfg.Bucket,
+		Endpoint:     s.endpoint.String(),
+		BaseURL:      s.baseURL(),
+	}, nil
+}
+
+// s3ObjectActions 各操作对应的对象级别权限，分片上传的各步骤包含在 s3:PutObject 中
+var s3ObjectActions = map[string][]string{
+	configs.STSActionPut:       {"s3:PutObject"},
+	configs.STSActionGet:       {"s3:GetObject"},
+	configs.STSActionMultipart: {"s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"},
+}
+
+// sessionPolicy 只允许对指定路径的对象执行允许的操作，分片上传需要存储桶级别的列举权限
+func (s *s3Service) sessionPolicy(policy adapter.CredentialPolicy) (string, error) {
+	var objectActions []string
+	var multipart bool
+	for _, action := range policy.Actions {
+		actions, ok := s3ObjectActions[action]
+		if !ok {
+			return "", fmt.Errorf("unsupported credential action: %s", action)
+		}
+		objectActions = append(objectActions, actions...)
+		multipart = multipart || action == configs.STSActionMultipart
+	}
+	statements := []map[string]any{
+		{
+			"Effect":   "Allow",
+			"Action":   objectActions,
+			"Resource": []string{"arn:aws:s3:::" + s.cfg.Bucket + "/" + policy.ResourcePath},
+		},
+	}
+	if multipart {
+		statements = append(statements, map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:ListBucketMultipartUploads"},
+			"Resource": []string{"arn:aws:s3:::" + s.cfg.Bucket},
+		})
+	}
+	data, err := json.Marshal(map[string]any{"Version": "2012-10-17", "Statement": statements})
+	if err != nil {
+		return "", fmt.Errorf("marshal %s session policy failed: %w", s.driver, err)
+	}
+	return string(data), nil
+}
+
+func (s *s3Service) UploadFile(ctx context.Context, resourcePath string, fileData []byte, contentType string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPut, resourcePath, fileData, contentType)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload file to %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return "", fmt.Errorf("failed to upload file to %s: %w", s.driver, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		err := storageResponseError(s.driver, resp)
+		zlog.CtxErrorf(ctx, "failed to upload file to %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return "", err
+	}
+
+	fullURL, err := url.JoinPath(s.baseURL(), resourcePath)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to construct file URL: %v", err)
+		return "", fmt.Errorf("failed to construct file URL: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file uploaded successfully to %s, path: %s", s.driver, resourcePath)
+	return fullURL, nil
+}
+
+func (s *s3Service) DeleteFile(ctx context.Context, resourcePath string) error {
+	resp, err := s.do(ctx, http.MethodDelete, resourcePath, nil, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete file from %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return fmt.Errorf("failed to delete file from %s: %w", s.driver, err)
+	}
+	defer resp.Body.Close()
+	// 对象不存在视为删除成功
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		err := storageResponseError(s.driver, resp)
+		zlog.CtxErrorf(ctx, "failed to delete file from %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return err
+	}
+
+	zlog.CtxInfof(ctx, "file deleted successfully from %s, path: %s", s.driver, resourcePath)
+	return nil
+}
+
+func (s *s3Service) DownloadFile(ctx context.Context, resourcePath string) ([]byte, error) {
+	object, err := s.OpenFile(ctx, resourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	data, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s object: %w", s.driver, err)
+	}
+	return data, nil
+}
+
+// GetPresignedURL 生成查询参数签名的下载链接，最长 7 天
+func (s *s3Service) GetPresignedURL(ctx context.Context, resourcePath string, expires time.Duration) (string, error) {
+	expires = min(expires, s3MaxPresignExpiry)
+	now := time.Now().UTC()
+	amzDate := now.Format(s3AMZDateFormat)
+	scope := s.scope(now, "s3")
+
+	u := objectURL(s.endpoint, s.cfg.Bucket, resourcePath, s.pathStyle)
+	query := url.Values{
+		"X-Amz-Algorithm":     {s3SignAlgorithm},
+		"X-Amz-Credential":    {s.cfg.AccessKeyID + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.FormatInt(int64(expires/time.Second), 10)},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonicalQuery := s3CanonicalQuery(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.signature(now, "s3", scope, canonicalRequest)
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// OpenFile 以流的方式读取对象，调用方负责关闭 Body
+func (s *s3Service) OpenFile(ctx context.Context, resourcePath string) (*adapter.FileObject, error) {
+	resp, err := s.do(ctx, http.MethodGet, resourcePath, nil, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open file from %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return nil, fmt.Errorf("failed to open file from %s: %w", s.driver, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, adapter.ErrFileNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		err := storageResponseError(s.driver, resp)
+		zlog.CtxErrorf(ctx, "failed to open file from %s, path: %s, error: %v", s.driver, resourcePath, err)
+		return nil, err
+	}
+	return &adapter.FileObject{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// do 对对象发起签名请求
+func (s *s3Service) do(ctx context.Context, method, resourcePath string, body []byte, contentType string) (*http.Response, error) {
+	u := objectURL(s.endpoint, s.cfg.Bucket, resourcePath, s.pathStyle)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, "s3", sha256Hex(body), time.Now())
+	return s.client.Do(req)
+}
+
+// baseURL 上传后返回的访问地址前缀，未配置时为存储桶地址
+func (s *s3Service) baseURL() string {
+	if s.cfg.BaseURL != "" {
+		return s.cfg.BaseURL
+	}
+	return objectURL(s.endpoint, s.cfg.Bucket, "", s.pathStyle).String()
+}
+
+// sign 以请求头方式签名，签名的请求头为 host、x-amz-content-sha256、x-amz-date
+func (s *s3Service) sign(req *http.Request, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3AMZDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		s3CanonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now, service)
+	signature := s.signature(now, service, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SignAlgorithm, s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func (s *s3Service) scope(now time.Time, service string) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/" + service + "/aws4_request"
+}
+
+// signature SigV4 签名：以日期、地域、服务逐级派生签名密钥，对 StringToSign 做 HMAC-SHA256
+func (s *s3Service) signature(now time.Time, service, scope, canonicalRequest string) string {
+	stringToSign := s3SignAlgorithm + "\n" + now.Format(s3AMZDateFormat) + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// s3CanonicalQuery 参数按名称排序，名称与值按 RFC3986 编码
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, s3QueryEscape(k)+"="+s3QueryEscape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func s3QueryEscape(s string) string {
+	return strings.ReplaceAll(escapeObjectKey(s), "/", "%2F")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,49 +1,170 @@
 package coze
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
 	"forge/biz/adapter"
 	"forge/constant"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
 	"forge/pkg/log/zlog"
 	"forge/pkg/loop"
-	"net/http"
-	"time"
+)
+
+const (
+	defaultBaseURL    = "https://api.coze.cn"
+	defaultReqTimeout = 60 * time.Second // 工作流同步执行，耗时可能较长
+	defaultMaxRetries = 2
+	retryBaseInterval = 500 * time.Millisecond
+	workflowRunPath   = "/v1/workflow/run"
 )
 
 type cozeServiceImpl struct {
-	client *http.Client
+	baseURL    string
+	token      string
+	maxRetries int
+	client     *http.Client
 }
 
-var cs *cozeServiceImpl
-
-// 这种配置第三方的可以直接写死
-// 因为你大概一万年不会变
-const reqTimeout = time.Second * 10
+var cs adapter.CozeService
 
-func InitCozeService() {
-	client := http.DefaultClient
-	client.Timeout = reqTimeout
-	cs = &cozeServiceImpl{
-		client: client,
-	}
-	return
+// InitCozeService 根据配置初始化 coze 服务，使用共用连接池的 http.Client，不修改 http.DefaultClient
+func InitCozeService(cfg configs.CozeConfig) {
+	cs = NewCozeService(cfg)
 }
 
 func GetCozeService() adapter.CozeService {
 	return cs
 }
 
-func (c *cozeServiceImpl) RunWorkflow(ctx context.Context, req *adapter.RunWorkflowReq) (result *adapter.RunWorkflowResult, err error) {
+func NewCozeService(cfg configs.CozeConfig) adapter.CozeService {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	timeout := defaultReqTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries > 0 {
+		maxRetries = cfg.MaxRetries
+	}
+	return &cozeServiceImpl{
+		baseURL:    baseURL,
+		token:      cfg.Token,
+		maxRetries: maxRetries,
+		client:     httpclient.New(timeout),
+	}
+}
+
+// cozeWorkflowResp coze 工作流接口的响应，data 为工作流输出的 JSON 字符串
+type cozeWorkflowResp struct {
+	Code     int    `json:"code"`
+	Msg      string `json:"msg"`
+	Data     string `json:"data"`
+	DebugURL string `json:"debug_url"`
+}
+
+// retryableError 可以重试的错误（网络错误、429、5xx）
+type retryableError struct {
+	err error
+}
 
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// RunWorkflow 同步执行工作流
+// ref: https://www.coze.cn/open/docs/developer_guides/workflow_run
+func (c *cozeServiceImpl) RunWorkflow(ctx context.Context, req *adapter.RunWorkflowReq) (result *adapter.RunWorkflowResult, err error) {
 	// 这里最好打印下trace方便排查
-	ctx, sp := loop.GetNewSpan(ctx, "handler.login", constant.LoopSpanType_RPCCall)
+	ctx, sp := loop.GetNewSpan(ctx, "coze.run_workflow", constant.LoopSpanType_RPCCall)
 	defer func() {
-		zlog.CtxAllInOne(ctx, "handler.login", req, result, err)
+		zlog.CtxAllInOne(ctx, "coze.run_workflow", req, result, err)
 		loop.SetSpanAllInOne(ctx, sp, req, result, err)
 	}()
 
-	c.client.Post("xxxx", "application/json", nil)
-	// somethint
+	if c.token == "" {
+		return nil, adapter.ErrCozeNotConfigured
+	}
+	if req == nil || req.WorkflowID == "" {
+		return nil, fmt.Errorf("coze workflow id is empty")
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"workflow_id": req.WorkflowID,
+		"parameters":  req.Parameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode coze request failed: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err = c.doRunWorkflow(ctx, body)
+		var retryErr *retryableError
+		if err == nil || !errors.As(err, &retryErr) || attempt >= c.maxRetries {
+			return result, err
+		}
+
+		// 指数退避：500ms、1s、2s ...
+		interval := retryBaseInterval << attempt
+		zlog.CtxWarnf(ctx, "run coze workflow %s failed, retry after %v (%d/%d): %v", req.WorkflowID, interval, attempt+1, c.maxRetries, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *cozeServiceImpl) doRunWorkflow(ctx context.Context, body []byte) (*adapter.RunWorkflowResult, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+workflowRunPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build coze request failed: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		// 调用方主动取消或超时不再重试
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &retryableError{err: fmt.Errorf("request coze failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &retryableError{err: fmt.Errorf("read coze response failed: %w", err)}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &retryableError{err: fmt.Errorf("coze workflow failed: status %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coze workflow failed: status %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	var workflowResp cozeWorkflowResp
+	if err := json.Unmarshal(respBody, &workflowResp); err != nil {
+		return nil, fmt.Errorf("decode coze response failed: %w", err)
+	}
+	if workflowResp.Code != 0 {
+		return nil, fmt.Errorf("coze workflow failed: code %d, msg: %s", workflowResp.Code, workflowResp.Msg)
+	}
 
-	return &adapter.RunWorkflowResult{}, nil
+	return &adapter.RunWorkflowResult{
+		Result:   workflowResp.Data,
+		DebugURL: workflowResp.DebugURL,
+	}, nil
 }
@@ -0,0 +1,110 @@
+package coze
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	zlog.InitLogger(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// newTestServer 模拟 coze 工作流接口，前 failures 次请求返回 status
+func newTestServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if r.URL.Path != workflowRunPath || r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if n <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		var body struct {
+			WorkflowID string         `json:"workflow_id"`
+			Parameters map[string]any `json:"parameters"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.WorkflowID != "wf-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(cozeWorkflowResp{Data: `{"user_name":"` + body.Parameters["user_id"].(string) + `"}`, DebugURL: "https://debug"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestCozeService(baseURL string) adapter.CozeService {
+	return NewCozeService(configs.CozeConfig{BaseURL: baseURL, Token: "test-token", MaxRetries: 1})
+}
+
+func runTestWorkflow(s adapter.CozeService) (*adapter.RunWorkflowResult, error) {
+	return s.RunWorkflow(context.Background(), &adapter.RunWorkflowReq{
+		WorkflowID: "wf-1",
+		Parameters: map[string]any{"user_id": "u1"},
+	})
+}
+
+func TestRunWorkflow(t *testing.T) {
+	srv, requests := newTestServer(t, 0, 0)
+
+	result, err := runTestWorkflow(newTestCozeService(srv.URL))
+	if err != nil {
+		t.Fatalf("RunWorkflow() error = %v", err)
+	}
+	if result.Result != `{"user_name":"u1"}` || result.DebugURL != "https://debug" {
+		t.Errorf("RunWorkflow() result = %+v", result)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestRunWorkflowRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		wantErr      bool
+		wantRequests int32
+	}{
+		{name: "5xx 重试后成功", failures: 1, status: http.StatusBadGateway, wantRequests: 2},
+		{name: "429 超过重试次数", failures: 2, status: http.StatusTooManyRequests, wantErr: true, wantRequests: 2},
+		{name: "4xx 不重试", failures: 1, status: http.StatusBadRequest, wantErr: true, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newTestServer(t, tt.failures, tt.status)
+			_, err := runTestWorkflow(newTestCozeService(srv.URL))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRunWorkflowNotConfigured(t *testing.T) {
+	s := NewCozeService(configs.CozeConfig{})
+	if _, err := runTestWorkflow(s); !errors.Is(err, adapter.ErrCozeNotConfigured) {
+		t.Errorf("RunWorkflow() error = %v, want ErrCozeNotConfigured", err)
+	}
+}
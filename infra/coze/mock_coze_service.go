@@ -0,0 +1,37 @@
+package coze
+
+import (
+	"context"
+	"sync"
+
+	"forge/biz/adapter"
+)
+
+// MockCozeService 用于测试的 coze 服务，记录每次调用的请求
+// RunWorkflowFunc 为空时返回空结果
+type MockCozeService struct {
+	RunWorkflowFunc func(ctx context.Context, req *adapter.RunWorkflowReq) (*adapter.RunWorkflowResult, error)
+
+	mu    sync.Mutex
+	calls []*adapter.RunWorkflowReq
+}
+
+var _ adapter.CozeService = (*MockCozeService)(nil)
+
+func (m *MockCozeService) RunWorkflow(ctx context.Context, req *adapter.RunWorkflowReq) (*adapter.RunWorkflowResult, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, req)
+	m.mu.Unlock()
+
+	if m.RunWorkflowFunc != nil {
+		return m.RunWorkflowFunc(ctx, req)
+	}
+	return &adapter.RunWorkflowResult{}, nil
+}
+
+// Calls 返回已记录的调用请求
+func (m *MockCozeService) Calls() []*adapter.RunWorkflowReq {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*adapter.RunWorkflowReq(nil), m.calls...)
+}
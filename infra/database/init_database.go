@@ -1,14 +1,21 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"forge/infra/configs"
 	"gorm.io/gorm"
 )
 
-var db *gorm.DB
+var (
+	db *gorm.DB
+	// autoMigrate 开发环境按存储对象自动建表，其他环境的表结构只由版本化迁移修改
+	autoMigrate bool
+)
 
 func MustInitDatabase(config configs.IConfig) {
+	autoMigrate = config.GetAppConfig().IsDev()
 	switch config.GetDBConfig().Driver {
 	case "mysql":
 		err := initMysql(config)
@@ -22,3 +29,41 @@ func MustInitDatabase(config configs.IConfig) {
 func ForgeDB() *gorm.DB {
 	return db
 }
+
+// AutoMigrate 开发环境按存储对象自动建表、加列，其他环境不做处理
+// 修改存储对象的字段后需同时在 migrations 中新增迁移，否则其他环境不会生效
+func AutoMigrate(models ...any) error {
+	if !autoMigrate {
+		return nil
+	}
+	return db.AutoMigrate(models...)
+}
+
+// AutoMigrateEnabled 是否按存储对象自动迁移，用于 AutoMigrate 之外需要自动建立的索引等
+func AutoMigrateEnabled() bool {
+	return autoMigrate
+}
+
+// Ping 检查数据库是否可用，用于就绪检查
+func Ping(ctx context.Context) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close 关闭数据库连接池，服务退出时调用
+func Close() error {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return errors.Join(sqlDB.Close(), closeReplicas())
+}
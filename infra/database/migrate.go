@@ -0,0 +1,371 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/pkg/log/zlog"
+)
+
+// 版本化迁移：migrations 目录下的 {版本}_{名称}.up.sql 与 {版本}_{名称}.down.sql 编译进程序，
+// 按版本从小到大执行，已执行的版本记录在 schema_migrations 表中
+// 新增表、字段、索引时新建一个版本号更大的迁移，已发布的迁移文件不要修改
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	// schemaMigrationsTable 记录已执行迁移的表
+	schemaMigrationsTable = "schema_migrations"
+	// migrationLockName 多个实例同时启动时只有一个执行迁移，其余等待
+	migrationLockName = "forge:schema_migrations"
+	// migrationLockTimeout 等待其他实例迁移完成的最长时间（秒）
+	migrationLockTimeout = 600
+)
+
+// ErrDirtyMigration 上次执行迁移时失败，数据库可能处于执行了一半的状态，需要人工确认后处理
+var ErrDirtyMigration = errors.New("dirty migration")
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus 迁移的执行状态
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	dirty     bool
+	appliedAt time.Time
+}
+
+// loadMigrations 读取编译进程序的迁移文件，按版本排序；每个版本必须同时提供 up 与 down
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移文件名 %s 不符合 {版本}_{名称}.up|down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件 %s 的版本号无效: %w", entry.Name(), err)
+		}
+		data, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本 %d 对应多个名称: %s、%s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("迁移版本 %d_%s 缺少 up 或 down 文件", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+func MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			zlog.Infof("执行数据库迁移 %d_%s", m.Version, m.Name)
+			if _, err := conn.ExecContext(ctx, "INSERT INTO "+schemaMigrationsTable+" (version, name, dirty, applied_at) VALUES (?, ?, 1, ?)",
+				m.Version, m.Name, time.Now()); err != nil {
+				return err
+			}
+			if err := execStatements(ctx, conn, m.up); err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 失败，已标记为 dirty: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, "UPDATE "+schemaMigrationsTable+" SET dirty = 0 WHERE version = ?", m.Version); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown 按版本从大到小回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			zlog.Infof("回滚数据库迁移 %d_%s", m.Version, m.Name)
+			if _, err := conn.ExecContext(ctx, "UPDATE "+schemaMigrationsTable+" SET dirty = 1 WHERE version = ?", m.Version); err != nil {
+				return err
+			}
+			if err := execStatements(ctx, conn, m.down); err != nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败，已标记为 dirty: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM "+schemaMigrationsTable+" WHERE version = ?", m.Version); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatuses 所有迁移的执行状态，按版本排序
+func MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := migrationConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := queryApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Migration: m}
+		if a, ok := applied[m.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].Dirty = a.dirty
+			statuses[i].AppliedAt = a.appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// PendingMigrations 未执行的迁移，有执行失败的迁移时返回 ErrDirtyMigration
+func PendingMigrations(ctx context.Context) ([]Migration, error) {
+	statuses, err := MigrationStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.Dirty {
+			return nil, fmt.Errorf("%w: %d_%s", ErrDirtyMigration, s.Version, s.Name)
+		}
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// withMigrationLock 在同一个连接上获取 MySQL 命名锁后执行 fn，命名锁属于连接，迁移语句也在该连接上执行
+func withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := migrationConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("等待其他实例执行迁移超过 %d 秒", migrationLockTimeout)
+	}
+	defer func() {
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", migrationLockName).Scan(&released); err != nil {
+			zlog.Warnf("释放迁移锁失败: %v", err)
+		}
+	}()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func migrationConn(ctx context.Context) (*sql.Conn, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return sqlDB.Conn(ctx)
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+schemaMigrationsTable+" ("+
+		"version BIGINT NOT NULL PRIMARY KEY, "+
+		"name VARCHAR(255) NOT NULL, "+
+		"dirty TINYINT(1) NOT NULL DEFAULT 0, "+
+		"applied_at DATETIME(3) NOT NULL"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	return err
+}
+
+// appliedMigrations 已执行的迁移，有执行失败的迁移时返回 ErrDirtyMigration，不继续执行或回滚
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	applied, err := queryApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for version, a := range applied {
+		if a.dirty {
+			return nil, fmt.Errorf("%w: 版本 %d 上次执行失败，确认数据库状态并手动修复后，删除 %s 中该版本的记录再重试",
+				ErrDirtyMigration, version, schemaMigrationsTable)
+		}
+	}
+	return applied, nil
+}
+
+func queryApplied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, dirty, applied_at FROM "+schemaMigrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.dirty, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// execStatements 逐条执行迁移文件中的语句，DSN 不需要开启 multiStatements
+// MySQL 的 DDL 会隐式提交，无法放在事务中回滚，执行失败时由 dirty 标记提醒人工处理
+func execStatements(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// splitStatements 按分号拆分 SQL 语句，跳过注释，忽略引号与反引号内的分号和注释符
+func splitStatements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(b.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// 引号内原样保留，反斜杠转义的引号不结束字符串
+			j := i + 1
+			for ; j < len(script) && script[j] != c; j++ {
+				if script[j] == '\\' && c != '`' {
+					j++
+				}
+			}
+			end := min(j+1, len(script))
+			b.WriteString(script[i:end])
+			i = end - 1
+		case c == '-' && isDashComment(script[i:]), c == '#':
+			// 单行注释
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			b.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+	return stmts
+}
+
+// isDashComment s 是否以 -- 注释开头，MySQL 要求 -- 后跟空白或位于行尾，a--1 这样的表达式不是注释
+func isDashComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || s[2] == ' ' || s[2] == '\t' || s[2] == '\n' || s[2] == '\r'
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "按分号拆分",
+			script: "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n",
+			want:   []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"},
+		},
+		{
+			name:   "跳过注释",
+			script: "-- 建表\nCREATE TABLE a (id int); # 行尾注释\n/* 块注释; */ DROP TABLE b;",
+			want:   []string{"CREATE TABLE a (id int)", "DROP TABLE b"},
+		},
+		{
+			name:   "引号内的分号与注释符",
+			script: "INSERT INTO a VALUES ('x;y', \"-- z\", 'it\\'s #1');",
+			want:   []string{"INSERT INTO a VALUES ('x;y', \"-- z\", 'it\\'s #1')"},
+		},
+		{
+			name:   "反引号内的分号与注释符",
+			script: "CREATE TABLE `a#1` (`b;c` int, `d--e` int);",
+			want:   []string{"CREATE TABLE `a#1` (`b;c` int, `d--e` int)"},
+		},
+		{
+			name:   "-- 后没有空白时不是注释",
+			script: "UPDATE a SET n = n--1;",
+			want:   []string{"UPDATE a SET n = n--1"},
+		},
+		{
+			name:   "空脚本",
+			script: "-- 只有注释\n;\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("loadMigrations() returned no migrations")
+	}
+	for i, m := range migrations {
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("migration %d_%s is not after version %d", m.Version, m.Name, migrations[i-1].Version)
+		}
+		// 迁移文件都能拆出语句，注释或引号没有吞掉整个文件
+		if len(splitStatements(m.up)) == 0 || len(splitStatements(m.down)) == 0 {
+			t.Errorf("migration %d_%s has no statements", m.Version, m.Name)
+		}
+	}
+}
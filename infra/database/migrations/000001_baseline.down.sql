@@ -0,0 +1,26 @@
+-- 删除基线中的全部表，数据不可恢复
+DROP TABLE IF EXISTS `achobeta_forge_invoice`;
+DROP TABLE IF EXISTS `achobeta_forge_backup`;
+DROP TABLE IF EXISTS `achobeta_forge_file`;
+DROP TABLE IF EXISTS `achobeta_forge_conversation`;
+DROP TABLE IF EXISTS `achobeta_forge_user_map_stars`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_comment`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_activity`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_template`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_map_tag`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_tag`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_folder`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_members`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap_share`;
+DROP TABLE IF EXISTS `achobeta_forge_mindmap`;
+DROP TABLE IF EXISTS `achobeta_forge_referral`;
+DROP TABLE IF EXISTS `achobeta_forge_invite_code`;
+DROP TABLE IF EXISTS `achobeta_forge_message_feedback`;
+DROP TABLE IF EXISTS `achobeta_forge_prompt_preset`;
+DROP TABLE IF EXISTS `achobeta_forge_user_quota`;
+DROP TABLE IF EXISTS `achobeta_forge_ai_usage`;
+DROP TABLE IF EXISTS `achobeta_forge_audit_log`;
+DROP TABLE IF EXISTS `achobeta_forge_password_history`;
+DROP TABLE IF EXISTS `achobeta_forge_user_session`;
+DROP TABLE IF EXISTS `achobeta_forge_user_identity`;
+DROP TABLE IF EXISTS `achobeta_forge_user`;
@@ -0,0 +1,412 @@
+-- 基线：引入版本化迁移之前由 AutoMigrate 创建的全部表
+-- 字段类型、索引名称与 AutoMigrate 生成的一致，已有的库执行时跳过已存在的表
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_user` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` longtext,
+    `username` longtext,
+    `password` longtext,
+    `avatar` longtext,
+    `nickname` varchar(32),
+    `bio` varchar(255),
+    `gender` varchar(16),
+    `birthday` date,
+    `location` varchar(64),
+    `phone` longtext,
+    `email` longtext,
+    `status` bigint DEFAULT 1,
+    `role` varchar(16) DEFAULT 'user',
+    `phone_verified` boolean DEFAULT false,
+    `email_verified` boolean DEFAULT false,
+    `plan` varchar(32) DEFAULT 'free',
+    `plan_expires_at` datetime(3) NULL,
+    `suspend_reason` varchar(255),
+    `suspended_until` datetime(3) NULL,
+    `reminder_opt_out` boolean DEFAULT false,
+    `reminder_sent_at` datetime(3) NULL,
+    `deletion_scheduled_at` datetime(3) NULL,
+    `merged_into` varchar(64),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `is_deleted` tinyint,
+    `last_login_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_achobeta_forge_user_deletion_scheduled_at` (`deletion_scheduled_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_user_identity` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` varchar(64),
+    `provider` varchar(32),
+    `provider_uid` varchar(128),
+    `open_id` varchar(128),
+    `nickname` varchar(128),
+    `avatar` varchar(512),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_user_provider` (`user_id`, `provider`),
+    UNIQUE INDEX `idx_provider_uid` (`provider`, `provider_uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_user_session` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `session_id` varchar(64),
+    `user_id` varchar(64),
+    `device` varchar(64),
+    `ip` varchar(64),
+    `user_agent` varchar(512),
+    `refresh_token_id` varchar(64),
+    `created_at` datetime(3) NULL,
+    `last_active_at` datetime(3) NULL,
+    `expires_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_user_session_session_id` (`session_id`),
+    INDEX `idx_user_created` (`user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_password_history` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` varchar(64),
+    `password_hash` varchar(255),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_achobeta_forge_password_history_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_audit_log` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `log_id` varchar(64),
+    `user_id` varchar(64),
+    `actor_id` varchar(64),
+    `action` varchar(32),
+    `result` varchar(16),
+    `detail` varchar(255),
+    `ip` varchar(64),
+    `user_agent` varchar(512),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_audit_log_log_id` (`log_id`),
+    INDEX `idx_user_created` (`user_id`, `created_at`),
+    INDEX `idx_achobeta_forge_audit_log_actor_id` (`actor_id`),
+    INDEX `idx_achobeta_forge_audit_log_action` (`action`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_ai_usage` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `record_id` varchar(64),
+    `user_id` varchar(64),
+    `scene` varchar(32),
+    `provider` varchar(64),
+    `model` varchar(128),
+    `prompt_tokens` bigint,
+    `completion_tokens` bigint,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_ai_usage_record_id` (`record_id`),
+    INDEX `idx_user_created` (`user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_user_quota` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` varchar(64),
+    `max_mind_maps` bigint,
+    `max_nodes_per_map` bigint,
+    `max_storage_bytes` bigint,
+    `updated_by` varchar(64),
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_user_quota_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_prompt_preset` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `preset_id` varchar(64),
+    `owner_id` varchar(64),
+    `name` varchar(32),
+    `description` varchar(255),
+    `system_prompt` text,
+    `temperature` float,
+    `top_p` float,
+    `max_tokens` bigint,
+    `frequency_penalty` float,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_prompt_preset_preset_id` (`preset_id`),
+    INDEX `idx_achobeta_forge_prompt_preset_owner_id` (`owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_message_feedback` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `conversation_id` varchar(64),
+    `message_id` varchar(32),
+    `user_id` varchar(64),
+    `rating` varchar(8),
+    `comment` varchar(500),
+    `prompt` text,
+    `reply` mediumtext,
+    `provider` varchar(64),
+    `model` varchar(128),
+    `preset_id` varchar(64),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uk_conversation_message` (`conversation_id`, `message_id`),
+    INDEX `idx_achobeta_forge_message_feedback_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_message_feedback_rating` (`rating`),
+    INDEX `idx_achobeta_forge_message_feedback_updated_at` (`updated_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_invite_code` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `code` varchar(16),
+    `user_id` varchar(64),
+    `max_uses` bigint,
+    `used_count` bigint DEFAULT 0,
+    `expires_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_invite_code_code` (`code`),
+    INDEX `idx_achobeta_forge_invite_code_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_referral` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `inviter_id` varchar(64),
+    `invitee_id` varchar(64),
+    `code` varchar(16),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_achobeta_forge_referral_inviter_id` (`inviter_id`),
+    UNIQUE INDEX `idx_achobeta_forge_referral_invitee_id` (`invitee_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `map_id` varchar(64),
+    `user_id` varchar(64),
+    `title` varchar(100),
+    `desc` varchar(500),
+    `data` json,
+    `layout` varchar(50),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `is_deleted` tinyint DEFAULT 0,
+    `folder_id` varchar(64) DEFAULT '',
+    `content` mediumtext,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_map_id` (`map_id`),
+    INDEX `idx_achobeta_forge_mindmap_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_mindmap_folder_id` (`folder_id`),
+    FULLTEXT INDEX `ft_title_content` (`title`, `content`) WITH PARSER ngram
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_share` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `share_id` varchar(64),
+    `map_id` varchar(64),
+    `owner_id` varchar(64),
+    `password_hash` varchar(100),
+    `expires_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_share_share_id` (`share_id`),
+    INDEX `idx_map_expires` (`map_id`, `expires_at`),
+    INDEX `idx_achobeta_forge_mindmap_share_owner_id` (`owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_members` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `map_id` varchar(64),
+    `user_id` varchar(64),
+    `owner_id` varchar(64),
+    `role` varchar(16),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uk_map_user` (`map_id`, `user_id`),
+    INDEX `idx_achobeta_forge_mindmap_members_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_mindmap_members_owner_id` (`owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_folder` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `folder_id` varchar(64),
+    `user_id` varchar(64),
+    `parent_id` varchar(64),
+    `name` varchar(50),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_folder_folder_id` (`folder_id`),
+    INDEX `idx_achobeta_forge_mindmap_folder_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_tag` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `tag_id` varchar(64),
+    `user_id` varchar(64),
+    `name` varchar(20),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_tag_tag_id` (`tag_id`),
+    UNIQUE INDEX `uk_user_name` (`user_id`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_map_tag` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `map_id` varchar(64),
+    `tag_id` varchar(64),
+    `user_id` varchar(64),
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uk_map_tag` (`map_id`, `tag_id`),
+    INDEX `idx_achobeta_forge_mindmap_map_tag_tag_id` (`tag_id`),
+    INDEX `idx_achobeta_forge_mindmap_map_tag_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_template` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `template_id` varchar(64),
+    `title` varchar(100),
+    `desc` varchar(500),
+    `category` varchar(20),
+    `layout` varchar(50),
+    `data` json,
+    `sort` bigint DEFAULT 0,
+    `created_by` varchar(64),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_template_template_id` (`template_id`),
+    INDEX `idx_achobeta_forge_mindmap_template_category` (`category`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_activity` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `activity_id` varchar(64),
+    `map_id` varchar(64),
+    `owner_id` varchar(64),
+    `actor_id` varchar(64),
+    `type` varchar(32),
+    `path` varchar(1024),
+    `text` text,
+    `old_text` text,
+    `target_id` varchar(64),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_activity_activity_id` (`activity_id`),
+    INDEX `idx_map_created` (`map_id`, `created_at`),
+    INDEX `idx_achobeta_forge_mindmap_activity_owner_id` (`owner_id`),
+    INDEX `idx_achobeta_forge_mindmap_activity_actor_id` (`actor_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_mindmap_comment` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `comment_id` varchar(64),
+    `map_id` varchar(64),
+    `node_id` varchar(64),
+    `owner_id` varchar(64),
+    `user_id` varchar(64),
+    `content` text,
+    `resolved` boolean DEFAULT false,
+    `resolved_by` varchar(64),
+    `resolved_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_mindmap_comment_comment_id` (`comment_id`),
+    INDEX `idx_map_node` (`map_id`, `node_id`),
+    INDEX `idx_achobeta_forge_mindmap_comment_owner_id` (`owner_id`),
+    INDEX `idx_achobeta_forge_mindmap_comment_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_user_map_stars` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` varchar(64),
+    `map_id` varchar(64),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uk_user_map` (`user_id`, `map_id`),
+    INDEX `idx_achobeta_forge_user_map_stars_map_id` (`map_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_conversation` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `conversation_id` varchar(191),
+    `user_id` longtext NOT NULL,
+    `map_id` longtext NOT NULL,
+    `title` longtext NOT NULL,
+    `preset_id` varchar(64),
+    `messages` json,
+    `is_archived` tinyint DEFAULT 0,
+    `archived_at` datetime(3) NULL,
+    `is_pinned` tinyint DEFAULT 0,
+    `sort_order` bigint DEFAULT 0,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `is_deleted` tinyint DEFAULT 0,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    CONSTRAINT `uni_achobeta_forge_conversation_conversation_id` UNIQUE (`conversation_id`),
+    INDEX `idx_achobeta_forge_conversation_is_deleted` (`is_deleted`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_file` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `file_id` varchar(64),
+    `user_id` varchar(64),
+    `resource_path` varchar(512),
+    `url` varchar(1024),
+    `filename` varchar(255),
+    `content_type` varchar(128),
+    `size` bigint,
+    `hash` varchar(64),
+    `purpose` varchar(32),
+    `conversation_id` varchar(64),
+    `status` varchar(16) DEFAULT 'active',
+    `scan_result` varchar(255),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_file_file_id` (`file_id`),
+    INDEX `idx_achobeta_forge_file_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_file_conversation_id` (`conversation_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_backup` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `backup_id` varchar(64),
+    `user_id` varchar(64),
+    `resource_path` varchar(512),
+    `size` bigint,
+    `sha256` char(64),
+    `mind_map_count` bigint,
+    `conversation_count` bigint,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_backup_backup_id` (`backup_id`),
+    INDEX `idx_achobeta_forge_backup_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_backup_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `achobeta_forge_invoice` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `invoice_id` varchar(64),
+    `user_id` varchar(64),
+    `product` varchar(64),
+    `plan` varchar(32),
+    `period_days` bigint,
+    `amount` bigint,
+    `currency` varchar(8),
+    `status` varchar(16),
+    `provider` varchar(32),
+    `provider_session_id` varchar(255),
+    `checkout_url` varchar(1024),
+    `paid_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_invoice_invoice_id` (`invoice_id`),
+    INDEX `idx_achobeta_forge_invoice_user_id` (`user_id`),
+    INDEX `idx_achobeta_forge_invoice_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
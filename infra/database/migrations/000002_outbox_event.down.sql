@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `achobeta_forge_outbox_event`;
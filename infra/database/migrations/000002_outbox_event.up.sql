@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `achobeta_forge_outbox_event` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `event_id` varchar(64),
+    `topic` varchar(64),
+    `event_key` varchar(64),
+    `payload` text,
+    `attempts` bigint DEFAULT 0,
+    `last_error` varchar(255),
+    `next_attempt_at` datetime(3) NULL,
+    `published_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_achobeta_forge_outbox_event_event_id` (`event_id`),
+    INDEX `idx_achobeta_forge_outbox_event_published_at` (`published_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
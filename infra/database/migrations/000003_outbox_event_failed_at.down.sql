@@ -0,0 +1 @@
+ALTER TABLE `achobeta_forge_outbox_event` DROP COLUMN `failed_at`;
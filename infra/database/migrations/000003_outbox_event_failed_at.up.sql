@@ -0,0 +1 @@
+ALTER TABLE `achobeta_forge_outbox_event` ADD COLUMN `failed_at` datetime(3) NULL AFTER `published_at`;
@@ -11,13 +11,31 @@ import (
 // InitDataBases 初始化
 func initMysql(config configs.IConfig) error {
 	dsn := config.GetDBConfig().Dsn
-	_db, err := gorm.Open(mysql.Open(dsn))
+	// TranslateError 将唯一索引冲突等驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误
+	_db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		zlog.Panicf("MySQL无法连接数据库！: %v", err)
 		return err
 	}
 	zlog.Infof("MySQL连接数据库成功！")
+
+	// 每次数据库操作的超时时间
+	if err := registerTimeoutCallbacks(_db, config.GetTimeoutConfig().DB()); err != nil {
+		zlog.Panicf("注册数据库超时回调失败: %v", err)
+		return err
+	}
+	// 链路追踪，未开启时回调为空操作
+	if err := registerTracingCallbacks(_db); err != nil {
+		zlog.Panicf("注册数据库链路追踪回调失败: %v", err)
+		return err
+	}
 	db = _db
 
+	// 只读从库，未配置时只读查询使用主库
+	if err := initReplicas(config.GetDBConfig().ReplicaDsns); err != nil {
+		zlog.Panicf("MySQL从库配置错误: %v", err)
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"forge/pkg/log/zlog"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// replicaDownDuration 从库查询出错后暂停使用的时间，期间只读查询直接发往主库
+const replicaDownDuration = 30 * time.Second
+
+// replica 只读从库，只使用其连接池，查询仍由主库的 *gorm.DB 构建，超时、链路追踪等回调保持一致
+type replica struct {
+	index     int
+	pool      *sql.DB
+	downUntil atomic.Int64
+}
+
+var (
+	replicas    []*replica
+	replicaNext atomic.Uint64
+)
+
+// primaryKey 标记只读查询必须发往主库
+type primaryKey struct{}
+
+// WithPrimary 返回要求只读查询发往主库的 ctx，用于写入后立即读取等不能接受从库延迟的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// initReplicas 打开从库连接池，启动时不检查连通性，不可用的从库在查询出错后暂停使用
+func initReplicas(dsns []string) error {
+	for i, dsn := range dsns {
+		_db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			return err
+		}
+		sqlDB, err := _db.DB()
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, &replica{index: i, pool: sqlDB})
+	}
+	if len(replicas) > 0 {
+		zlog.Infof("MySQL从库已配置 %d 个", len(replicas))
+	}
+	return nil
+}
+
+// pickReplica 轮询选择一个可用的从库，没有可用的从库时返回 nil
+func pickReplica() *replica {
+	n := len(replicas)
+	if n == 0 {
+		return nil
+	}
+	now := time.Now().UnixNano()
+	start := replicaNext.Add(1)
+	for i := 0; i < n; i++ {
+		r := replicas[(start+uint64(i))%uint64(n)]
+		if r.downUntil.Load() <= now {
+			return r
+		}
+	}
+	return nil
+}
+
+// Read 执行只读查询：query 为基于主库构建好条件的查询，fn 在传入的 db 上执行查询（First、Find、Count 等）
+// 配置了从库时发往从库，从库出错（记录不存在除外）时暂停使用该从库并在主库上重新执行 fn；
+// 未配置从库、从库均不可用、ctx 由 WithPrimary 标记或在事务中时直接在主库（事务）上执行
+// 每次执行使用 query 的副本，fn 不会修改 query，可对同一个 query 多次调用
+func Read(ctx context.Context, query *gorm.DB, fn func(db *gorm.DB) error) error {
+	r := pickReplica()
+	if r == nil || usePrimary(ctx) || InTransaction(ctx) {
+		return fn(query.Session(&gorm.Session{Context: ctx}))
+	}
+
+	tx := query.Session(&gorm.Session{Context: ctx})
+	tx.Statement.ConnPool = r.pool
+	err := fn(tx)
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+	r.downUntil.Store(time.Now().Add(replicaDownDuration).UnixNano())
+	zlog.CtxWarnf(ctx, "MySQL从库 %d 查询失败，%s 内改用主库: %v", r.index, replicaDownDuration, err)
+	return fn(query.Session(&gorm.Session{Context: ctx}))
+}
+
+// closeReplicas 关闭从库连接池
+func closeReplicas() error {
+	var errs []error
+	for _, r := range replicas {
+		errs = append(errs, r.pool.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const timeoutCancelKey = "forge:timeout_cancel"
+
+// registerTimeoutCallbacks 为每次增删改查设置超时
+// 调用方 ctx 的截止时间更早时以调用方为准；Row/Rows 需要在回调结束后继续读取，不在这里处理
+func registerTimeoutCallbacks(db *gorm.DB, timeout time.Duration) error {
+	before := func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(timeoutCancelKey, cancel)
+	}
+	after := func(tx *gorm.DB) {
+		if cancel, ok := tx.InstanceGet(timeoutCancelKey); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("forge:timeout_before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("forge:timeout_after_create", after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("forge:timeout_before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("forge:timeout_after_query", after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("forge:timeout_before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("forge:timeout_after_update", after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("forge:timeout_before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("forge:timeout_after_delete", after); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"forge/pkg/tracing"
+
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "forge:tracing_span"
+
+// registerTracingCallbacks 每次数据库操作创建 client span，记录 SQL 与影响行数
+// SQL 为带占位符的语句，不含参数值；记录不存在不视为失败
+func registerTracingCallbacks(db *gorm.DB) error {
+	before := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, span := tracing.Start(ctx, "db."+operation, tracing.SpanKindClient,
+				tracing.String("db.system", "mysql"),
+				tracing.String("db.operation", operation),
+				tracing.String("db.sql.table", tx.Statement.Table),
+			)
+			if span == nil {
+				return
+			}
+			tx.Statement.Context = ctx
+			tx.InstanceSet(tracingSpanKey, span)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(tracingSpanKey)
+		if !ok {
+			return
+		}
+		span := value.(*tracing.Span)
+		span.SetAttributes(
+			tracing.String("db.statement", tx.Statement.SQL.String()),
+			tracing.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+		}
+		span.End()
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("forge:tracing_before_create", before("insert")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("forge:tracing_after_create", after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("forge:tracing_before_query", before("select")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("forge:tracing_after_query", after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("forge:tracing_before_update", before("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("forge:tracing_after_update", after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("forge:tracing_before_delete", before("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("forge:tracing_after_delete", after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("forge:tracing_before_raw", before("raw")); err != nil {
+		return err
+	}
+	if err := cb.Raw().After("gorm:raw").Register("forge:tracing_after_raw", after); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// txKey ctx 中保存当前事务
+type txKey struct{}
+
+// txState 事务及提交后需要执行的回调
+type txState struct {
+	tx *gorm.DB
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// WithinTransaction 在一个数据库事务内执行 fn，fn 返回错误或 panic 时回滚
+// fn 中使用传入的 ctx 调用的仓储方法都在该事务内执行；ctx 中已有事务时加入该事务，由最外层提交或回滚
+func WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+	state := &txState{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// Conn 仓储执行数据库操作使用的连接：ctx 中有事务时使用事务，否则使用 db
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTransaction ctx 中是否有事务
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit ctx 中有事务时在事务提交后执行 fn，回滚时不执行；没有事务时立即执行
+// 用于清除缓存等不能在提交前进行的操作，避免其他请求在提交前读到旧数据并重新写入缓存
+func AfterCommit(ctx context.Context, fn func()) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn()
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.afterCommit = append(state.afterCommit, fn)
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeDriver 记录事务与语句的 database/sql 驱动，不连接数据库
+type fakeDriver struct {
+	mu        sync.Mutex
+	begins    int
+	commits   int
+	rollbacks int
+	execs     []fakeExec
+}
+
+type fakeExec struct {
+	query string
+	inTx  bool
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+func (d *fakeDriver) counts() (begins, commits, rollbacks int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.begins, d.commits, d.rollbacks
+}
+
+type fakeConn struct {
+	d    *fakeDriver
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.begins++
+	c.inTx = true
+	return &fakeTx{c: c}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, fakeExec{query: query, inTx: c.inTx})
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{ c *fakeConn }
+
+func (t *fakeTx) Commit() error {
+	t.c.d.mu.Lock()
+	defer t.c.d.mu.Unlock()
+	t.c.d.commits++
+	t.c.inTx = false
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.c.d.mu.Lock()
+	defer t.c.d.mu.Unlock()
+	t.c.d.rollbacks++
+	t.c.inTx = false
+	return nil
+}
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c fakeConnector) Driver() driver.Driver                            { return c.d }
+
+// useFakeDB 把包内的 db 替换为连接 fakeDriver 的 gorm.DB，测试结束时恢复
+func useFakeDB(t *testing.T) *fakeDriver {
+	t.Helper()
+	d := &fakeDriver{}
+	sqlDB := sql.OpenDB(fakeConnector{d: d})
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	previous := db
+	db = gormDB
+	t.Cleanup(func() {
+		db = previous
+		_ = sqlDB.Close()
+	})
+	return d
+}
+
+func TestWithinTransactionCommit(t *testing.T) {
+	d := useFakeDB(t)
+	ctx := context.Background()
+
+	var order []string
+	err := WithinTransaction(ctx, func(ctx context.Context) error {
+		if !InTransaction(ctx) {
+			t.Error("InTransaction() = false inside transaction")
+		}
+		if err := Conn(ctx, db).Exec("UPDATE a SET n = 1").Error; err != nil {
+			return err
+		}
+		AfterCommit(ctx, func() { order = append(order, "first") })
+		AfterCommit(ctx, func() { order = append(order, "second") })
+		if len(order) != 0 {
+			t.Error("AfterCommit() hook ran before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction() error = %v", err)
+	}
+	if begins, commits, rollbacks := d.counts(); begins != 1 || commits != 1 || rollbacks != 0 {
+		t.Errorf("begins, commits, rollbacks = %d, %d, %d, want 1, 1, 0", begins, commits, rollbacks)
+	}
+	if len(d.execs) != 1 || !d.execs[0].inTx {
+		t.Errorf("execs = %+v, want one statement inside the transaction", d.execs)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("AfterCommit() hooks ran as %v, want [first second]", order)
+	}
+	if InTransaction(ctx) {
+		t.Error("InTransaction() = true outside transaction")
+	}
+}
+
+func TestWithinTransactionRollback(t *testing.T) {
+	d := useFakeDB(t)
+	errFailed := errors.New("failed")
+
+	hookRan := false
+	err := WithinTransaction(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { hookRan = true })
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithinTransaction() error = %v, want %v", err, errFailed)
+	}
+	if begins, commits, rollbacks := d.counts(); begins != 1 || commits != 0 || rollbacks != 1 {
+		t.Errorf("begins, commits, rollbacks = %d, %d, %d, want 1, 0, 1", begins, commits, rollbacks)
+	}
+	if hookRan {
+		t.Error("AfterCommit() hook ran after rollback")
+	}
+}
+
+func TestWithinTransactionPanic(t *testing.T) {
+	d := useFakeDB(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithinTransaction() did not re-panic")
+			}
+		}()
+		_ = WithinTransaction(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	}()
+	if begins, commits, rollbacks := d.counts(); begins != 1 || commits != 0 || rollbacks != 1 {
+		t.Errorf("begins, commits, rollbacks = %d, %d, %d, want 1, 0, 1", begins, commits, rollbacks)
+	}
+}
+
+func TestWithinTransactionNested(t *testing.T) {
+	d := useFakeDB(t)
+	errInner := errors.New("inner failed")
+
+	// 内层加入外层事务，内层失败时由外层决定回滚
+	err := WithinTransaction(context.Background(), func(ctx context.Context) error {
+		return WithinTransaction(ctx, func(ctx context.Context) error {
+			return errInner
+		})
+	})
+	if !errors.Is(err, errInner) {
+		t.Fatalf("WithinTransaction() error = %v, want %v", err, errInner)
+	}
+	if begins, commits, rollbacks := d.counts(); begins != 1 || commits != 0 || rollbacks != 1 {
+		t.Errorf("begins, commits, rollbacks = %d, %d, %d, want 1, 0, 1", begins, commits, rollbacks)
+	}
+}
+
+func TestAfterCommitWithoutTransaction(t *testing.T) {
+	ran := false
+	AfterCommit(context.Background(), func() { ran = true })
+	if !ran {
+		t.Error("AfterCommit() without transaction did not run immediately")
+	}
+}
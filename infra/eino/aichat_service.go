@@ -2,29 +2,38 @@ package eino
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"forge/biz/entity"
 	"forge/biz/repo"
 	"forge/biz/types"
+	"forge/infra/configs"
 	"forge/pkg/log/zlog"
-	"github.com/cloudwego/eino-ext/components/model/ark"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	einomodel "github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
-	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+	callbackutils "github.com/cloudwego/eino/utils/callbacks"
 )
 
 type AiChatClient struct {
-	ApiKey       string
-	ModelName    string
-	Agent        compose.Runnable[[]*schema.Message, types.AgentResponse]
-	ToolAiClient *ark.ChatModel
+	providers      []*aiProvider // 按回退顺序排列，第一个为默认提供方
+	visionProvider string        // 所选提供方不支持图片时使用的提供方，为空时使用第一个支持图片的提供方
+	resilience     configs.AiResilienceConfig
 }
 
 type State struct {
 	Content   string
 	ToolCalls []schema.ToolCall
+	Usage     entity.TokenUsage // 本轮各次模型调用（含工具中的调用）的用量合计
 }
 
 func initState(ctx context.Context) *State {
@@ -33,48 +42,100 @@ func initState(ctx context.Context) *State {
 	}
 }
 
-func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
+// NewAiChatClient 按配置创建各模型提供方，timeout 为未单独配置超时的提供方单次调用的超时时间
+func NewAiChatClient(cfg configs.AiChatConfig, timeout time.Duration) repo.EinoServer {
 	ctx := context.Background()
 
-	var aiChatClient AiChatClient
-
-	//初始化工具专用模型
-	toolModel, err := ark.NewChatModel(ctx, &ark.ChatModelConfig{
-		APIKey:   apiKey,
-		Model:    modelName,
-		Thinking: &model.Thinking{Type: model.ThinkingTypeDisabled},
+	aiChatClient := AiChatClient{resilience: cfg.Resilience}
+	names := make(map[string]bool)
+	for _, providerConfig := range cfg.ProviderList() {
+		if names[providerConfig.Name] {
+			panic(fmt.Errorf("ai提供方名称重复: %s", providerConfig.Name))
+		}
+		names[providerConfig.Name] = true
+		provider := newAiProvider(ctx, providerConfig, timeout)
+		provider.breaker = newCircuitBreaker(cfg.Resilience)
+		// 配置为 vision_provider 的提供方视为支持图片
+		if providerConfig.Name == cfg.ImageInput.VisionProvider {
+			provider.Vision = true
+		}
+		aiChatClient.providers = append(aiChatClient.providers, provider)
+	}
+	if visionProvider := cfg.ImageInput.VisionProvider; visionProvider != "" {
+		if !names[visionProvider] {
+			panic(fmt.Errorf("vision_provider 不是已配置的ai提供方: %s", visionProvider))
+		}
+		aiChatClient.visionProvider = visionProvider
+	}
+	// 配置热更新后切换各提供方的模型
+	configs.OnReload(func(cfg configs.IConfig) {
+		aiChatClient.updateModels(cfg.GetAiChatConfig())
 	})
-	if toolModel == nil || err != nil {
-		zlog.Errorf("ToolAi模型连接失败: %v", err)
-		panic(fmt.Errorf("ToolAi模型连接失败: %v", err))
+	// 各提供方的熔断状态，与调用计数一起通过 expvar 查看
+	if expvar.Get("ai_provider_breakers") == nil {
+		expvar.Publish("ai_provider_breakers", expvar.Func(aiChatClient.breakerStates))
+	}
+
+	return &aiChatClient
+}
+
+// updateModels 按名称更新各提供方的模型名称；提供方本身的变化需要重启，热更新时不会写入配置
+func (a *AiChatClient) updateModels(cfg configs.AiChatConfig) {
+	for _, providerConfig := range cfg.ProviderList() {
+		for _, p := range a.providers {
+			if p.Name != providerConfig.Name || p.ModelName() == providerConfig.Model {
+				continue
+			}
+			zlog.Warnf("ai提供方 %s 的模型修改为 %s", p.Name, providerConfig.Model)
+			model := providerConfig.Model
+			p.model.Store(&model)
+		}
+	}
+}
+
+// breakerStates 各提供方的熔断状态
+func (a *AiChatClient) breakerStates() any {
+	states := make(map[string]string, len(a.providers))
+	for _, p := range a.providers {
+		states[p.Name] = p.breaker.State()
 	}
+	return states
+}
 
-	//toolAiClient = toolModel
+func newAiProvider(ctx context.Context, cfg configs.AiProviderConfig, timeout time.Duration) *aiProvider {
+	provider := &aiProvider{
+		Name:    cfg.Name,
+		Timeout: cfg.Timeout(timeout),
+		Vision:  cfg.Vision,
+	}
+	provider.model.Store(&cfg.Model)
 
-	aiChatClient.ApiKey = apiKey
-	aiChatClient.ModelName = modelName
-	aiChatClient.ToolAiClient = toolModel
+	//初始化工具专用模型
+	toolModel, err := newProviderChatModel(ctx, cfg, false)
+	if toolModel == nil || err != nil {
+		zlog.Errorf("ToolAi模型连接失败: %s, %v", cfg.Name, err)
+		panic(fmt.Errorf("ToolAi模型连接失败: %s, %v", cfg.Name, err))
+	}
+	provider.ToolAiClient = toolModel
 
 	//构建agent
-	aiChatModel, err := ark.NewChatModel(ctx, &ark.ChatModelConfig{
-		APIKey:   apiKey,
-		Model:    modelName,
-		Thinking: &model.Thinking{Type: model.ThinkingTypeDisabled},
-	})
+	aiChatModel, err := newProviderChatModel(ctx, cfg, true)
 	if aiChatModel == nil || err != nil {
-		zlog.Errorf("ai模型连接失败: %v", err)
-		panic(fmt.Errorf("ai模型连接失败: %v", err))
+		zlog.Errorf("ai模型连接失败: %s, %v", cfg.Name, err)
+		panic(fmt.Errorf("ai模型连接失败: %s, %v", cfg.Name, err))
 	}
-	updateMindMapTool := aiChatClient.CreateUpdateMindMapTool()
-	infoTool, err := updateMindMapTool.Info(ctx)
-	if err != nil {
-		zlog.Errorf("ai绑定工具失败: %v", err)
-		panic(fmt.Errorf("ai绑定工具失败: %v", err))
+	updateMindMapTool := provider.CreateUpdateMindMapTool()
+	editMindMapTool := provider.CreateEditMindMapTool()
+	infosTool := make([]*schema.ToolInfo, 0, 2)
+	for _, t := range []tool.InvokableTool{updateMindMapTool, editMindMapTool} {
+		infoTool, err := t.Info(ctx)
+		if err != nil {
+			zlog.Errorf("ai绑定工具失败: %v", err)
+			panic(fmt.Errorf("ai绑定工具失败: %v", err))
+		}
+		infosTool = append(infosTool, infoTool)
 	}
 
-	infosTool := []*schema.ToolInfo{
-		infoTool,
-	}
 	err = aiChatModel.BindTools(infosTool)
 	if err != nil {
 		zlog.Errorf("ai绑定工具失败: %v", err)
@@ -84,6 +145,7 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 	ToolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
 		Tools: []tool.BaseTool{
 			updateMindMapTool,
+			editMindMapTool,
 		},
 	})
 
@@ -109,16 +171,32 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 
 		output = types.AgentResponse{}
 
-		if input[len(input)-1].Role == schema.Tool {
-			output.NewMapJson = input[len(input)-1].Content
-			output.ToolCallID = input[len(input)-1].ToolCallID
-
-		}
 		_ = compose.ProcessState[*State](ctx, func(ctx context.Context, state *State) error {
 			output.Content = state.Content
 			output.ToolCalls = state.ToolCalls
+			output.Usage = state.Usage
 			return nil
 		})
+
+		//按工具区分结果：update_mind_map 返回新导图json；edit_mind_map 的操作取自工具调用的参数
+		for _, msg := range input {
+			if msg.Role != schema.Tool {
+				continue
+			}
+			toolCall := findToolCall(output.ToolCalls, msg.ToolCallID)
+			if toolCall == nil || toolCall.Function.Name != editMindMapToolName {
+				output.NewMapJson = msg.Content
+				output.ToolCallID = msg.ToolCallID
+				continue
+			}
+			output.OperationsCallID = msg.ToolCallID
+			var params EditMindMapParams
+			if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &params); err != nil {
+				zlog.CtxWarnf(ctx, "解析导图操作失败: %v", err)
+				continue
+			}
+			output.Operations = operationsParams2Entity(params.Operations)
+		}
 		return output, nil
 	})
 
@@ -127,6 +205,7 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		//fmt.Println("工具使用测试:", input)
 		state.ToolCalls = input.ToolCalls
 		state.Content = input.Content
+		state.Usage.Add(tokenUsageOf(input))
 		return input, nil
 	}
 
@@ -192,32 +271,186 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		panic("编译错误" + err.Error())
 	}
 
-	aiChatClient.Agent = agent
+	provider.Agent = agent
 
-	return &aiChatClient
+	return provider
 }
 
-func (a *AiChatClient) SendMessage(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error) {
+// SendMessage 向ai发送消息，modelName 选择提供方，超时或提供方出错时回退到下一个提供方
+func (a *AiChatClient) SendMessage(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams) (types.AgentResponse, error) {
 
 	input := messagesDo2Input(messages)
+	opts := modelParamsOptions(params)
+	ctx = withRequestParams(ctx, params)
+	vision := hasImages(messages)
+
+	var resp types.AgentResponse
+	provider, err := a.callWithFallback(ctx, modelName, vision, func(ctx context.Context, p *aiProvider) error {
+		var err error
+		resp, err = p.Agent.Invoke(ctx, input, p.agentOptions(opts)...)
+		return err
+	}, nil)
+
+	if err != nil {
+		zlog.Errorf("模型调用失败%v", err)
+		return types.AgentResponse{}, err
+	}
+	resp.Provider = provider.Name
+	resp.Model = provider.ModelName()
+	return resp, nil
+}
 
-	resp, err := a.Agent.Invoke(ctx, input)
+// StreamMessage 以流式方式运行agent，通过模型节点的回调拿到输出的文本片段
+// 工具调用轮次的模型输出通常没有文本，更新后的导图随完整回复返回
+// 已经输出文本片段后不再回退到其他提供方，避免客户端收到两份回复
+func (a *AiChatClient) StreamMessage(ctx context.Context, modelName string, messages []*entity.Message, params entity.ModelParams, onDelta func(delta string) error) (types.AgentResponse, error) {
+	input := messagesDo2Input(messages)
+	opts := modelParamsOptions(params)
+	ctx = withRequestParams(ctx, params)
+	vision := hasImages(messages)
+
+	var (
+		resp    types.AgentResponse
+		emitted bool
+	)
+	provider, err := a.callWithFallback(ctx, modelName, vision, func(ctx context.Context, p *aiProvider) error {
+		var err error
+		resp, err = p.stream(ctx, input, p.agentOptions(opts), func(delta string) error {
+			emitted = true
+			return onDelta(delta)
+		})
+		return err
+	}, func() bool {
+		// stream 返回前已等待读取回调的协程结束，这里读取 emitted 不需要加锁
+		return !emitted
+	})
 
 	if err != nil {
 		zlog.Errorf("模型调用失败%v", err)
 		return types.AgentResponse{}, err
 	}
+	resp.Provider = provider.Name
+	resp.Model = provider.ModelName()
+	return resp, nil
+}
+
+// stream 用单个提供方流式运行agent，返回前等待所有文本片段交给 onDelta
+func (p *aiProvider) stream(ctx context.Context, input []*schema.Message, opts []compose.Option, onDelta func(delta string) error) (types.AgentResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		deltaErr error
+	)
+	handler := callbackutils.NewHandlerHelper().ChatModel(&callbackutils.ModelCallbackHandler{
+		OnEndWithStreamOutput: func(ctx context.Context, _ *callbacks.RunInfo, output *schema.StreamReader[*einomodel.CallbackOutput]) context.Context {
+			// 回调中的流是模型输出的副本，需要在单独的协程中读完并关闭，避免阻塞agent
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer output.Close()
+				for {
+					chunk, err := output.Recv()
+					if err != nil {
+						// 流结束或出错，模型调用的错误由agent返回
+						return
+					}
+					if chunk == nil || chunk.Message == nil || chunk.Message.Content == "" {
+						continue
+					}
+					mu.Lock()
+					if deltaErr == nil {
+						if deltaErr = onDelta(chunk.Message.Content); deltaErr != nil {
+							cancel()
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+			return ctx
+		},
+	}).Handler()
+
+	stream, err := p.Agent.Stream(ctx, input, append(opts, compose.WithCallbacks(handler).DesignateNode("model"))...)
+	if err != nil {
+		wg.Wait()
+		return types.AgentResponse{}, err
+	}
+	defer stream.Close()
+
+	var resp types.AgentResponse
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			wg.Wait()
+			if deltaErr != nil {
+				return types.AgentResponse{}, deltaErr
+			}
+			return types.AgentResponse{}, err
+		}
+		resp = chunk
+	}
+	wg.Wait()
+	if deltaErr != nil {
+		return types.AgentResponse{}, deltaErr
+	}
 	return resp, nil
 }
 
-// 传入文本生成导图
-func (a *AiChatClient) GenerateMindMap(ctx context.Context, text, userID string) (string, error) {
+// 传入文本生成导图，按配置顺序使用提供方
+func (a *AiChatClient) GenerateMindMap(ctx context.Context, text, userID string) (types.GenerateMindMapOutput, error) {
 	message := initGenerateMindMapMessage(text, userID)
 
-	resp, err := a.ToolAiClient.Generate(ctx, message)
+	var resp *schema.Message
+	provider, err := a.callWithFallback(ctx, "", false, func(ctx context.Context, p *aiProvider) error {
+		var err error
+		resp, err = p.ToolAiClient.Generate(ctx, message, p.modelOption())
+		return err
+	}, nil)
 	if err != nil {
 		zlog.Errorf("模型调用失败%v", err)
-		return "", err
+		return types.GenerateMindMapOutput{}, err
+	}
+	return types.GenerateMindMapOutput{
+		MapJson:  resp.Content,
+		Provider: provider.Name,
+		Model:    provider.ModelName(),
+		Usage:    tokenUsageOf(resp),
+	}, nil
+}
+
+// SummarizeMindMap 按导图大纲生成总结与各分支要点
+// 模型未按 JSON 格式回复时把整段回复作为总结，不返回分支要点
+func (a *AiChatClient) SummarizeMindMap(ctx context.Context, modelName, title, outline string) (types.SummarizeMindMapOutput, error) {
+	message := initSummarizeMindMapMessage(title, outline)
+
+	var resp *schema.Message
+	provider, err := a.callWithFallback(ctx, modelName, false, func(ctx context.Context, p *aiProvider) error {
+		var err error
+		resp, err = p.ToolAiClient.Generate(ctx, message, p.modelOption())
+		return err
+	}, nil)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "模型调用失败%v", err)
+		return types.SummarizeMindMapOutput{}, err
+	}
+
+	var output MindMapSummaryOutput
+	summary := entity.MindMapSummary{Summary: strings.TrimSpace(resp.Content)}
+	if err := json.Unmarshal([]byte(trimCodeFence(resp.Content)), &output); err != nil || output.Summary == "" {
+		zlog.CtxWarnf(ctx, "导图总结不是预期的JSON格式: %v", err)
+	} else {
+		summary = output.toEntity()
 	}
-	return resp.Content, nil
+	return types.SummarizeMindMapOutput{
+		Summary:  summary,
+		Provider: provider.Name,
+		Model:    provider.ModelName(),
+		Usage:    tokenUsageOf(resp),
+	}, nil
 }
@@ -0,0 +1,323 @@
+package eino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+	"forge/pkg/tracing"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/ark"
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+// errVisionNotSupported 发送图片时没有支持图片的提供方，调用方应先通过 VisionModel 确认
+var errVisionNotSupported = errors.New("没有支持图片输入的ai提供方")
+
+const (
+	defaultAzureAPIVersion = "2024-06-01"
+	// ollama 不校验密钥，而 ark 客户端没有密钥时会改用火山引擎的 AK/SK 鉴权，这里填一个占位密钥
+	ollamaPlaceholderAPIKey = "ollama"
+)
+
+// 各类型提供方的默认接口地址，ark 由 SDK 使用默认地址
+var defaultProviderBaseURLs = map[string]string{
+	configs.AiProviderOpenAI:   "https://api.openai.com/v1",
+	configs.AiProviderDeepSeek: "https://api.deepseek.com/v1",
+	configs.AiProviderOllama:   "http://localhost:11434/v1",
+}
+
+// aiProvider 单个模型提供方的agent与工具专用模型
+type aiProvider struct {
+	Name         string
+	model        atomic.Pointer[string] // 模型名称，配置热更新时替换，调用时通过 modelOption 传给客户端
+	Timeout      time.Duration          // 单次模型调用的超时时间
+	Vision       bool                   // 是否支持图片输入
+	breaker      *circuitBreaker
+	Agent        compose.Runnable[[]*schema.Message, types.AgentResponse]
+	ToolAiClient *ark.ChatModel
+}
+
+func (p *aiProvider) ModelName() string {
+	return *p.model.Load()
+}
+
+// modelOption 每次调用指定当前的模型名称，模型名称热更新后不需要重新创建客户端
+func (p *aiProvider) modelOption() einomodel.Option {
+	return einomodel.WithModel(p.ModelName())
+}
+
+// agentOptions 在 opts 之后追加 agent 中模型节点的模型名称，不修改 opts
+func (p *aiProvider) agentOptions(opts []compose.Option) []compose.Option {
+	return append(slices.Clip(opts), compose.WithChatModelOption(p.modelOption()).DesignateNode("model"))
+}
+
+// newProviderChatModel 创建提供方的模型客户端
+// 各提供方都兼容 OpenAI 的 chat/completions 接口，统一使用 ark 客户端，按类型设置接口地址与鉴权方式
+// requestParams 为 true 时按请求写入 ark 客户端不支持的模型参数，见 requestParamsTransport
+func newProviderChatModel(ctx context.Context, cfg configs.AiProviderConfig, requestParams bool) (*ark.ChatModel, error) {
+	modelConfig := &ark.ChatModelConfig{
+		APIKey:  cfg.ApiKey,
+		Model:   cfg.Model,
+		BaseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		// 流式输出耗时不定，不设置整体超时，由 ctx 控制
+		HTTPClient: httpclient.New(0),
+	}
+
+	switch cfg.Type {
+	case configs.AiProviderArk:
+		// 深度思考参数只有 ark 支持
+		modelConfig.Thinking = &model.Thinking{Type: model.ThinkingTypeDisabled}
+	case configs.AiProviderOpenAI, configs.AiProviderDeepSeek, configs.AiProviderOllama:
+		if modelConfig.BaseURL == "" {
+			modelConfig.BaseURL = defaultProviderBaseURLs[cfg.Type]
+		}
+		if cfg.Type == configs.AiProviderOllama && modelConfig.APIKey == "" {
+			modelConfig.APIKey = ollamaPlaceholderAPIKey
+		}
+	case configs.AiProviderAzure:
+		if modelConfig.BaseURL == "" || cfg.ApiKey == "" {
+			return nil, errors.New("azure 提供方需要配置 base_url 与 api_key")
+		}
+		apiVersion := cfg.APIVersion
+		if apiVersion == "" {
+			apiVersion = defaultAzureAPIVersion
+		}
+		// Azure 按部署区分模型，接口地址为 {base_url}/openai/deployments/{部署名称}/chat/completions
+		modelConfig.BaseURL += "/openai/deployments/" + url.PathEscape(cfg.Model)
+		modelConfig.HTTPClient = &http.Client{Transport: &azureTransport{
+			apiKey:     cfg.ApiKey,
+			apiVersion: apiVersion,
+			base:       httpclient.Transport(),
+		}}
+	default:
+		return nil, fmt.Errorf("不支持的ai提供方类型: %s", cfg.Type)
+	}
+
+	if requestParams {
+		modelConfig.HTTPClient = &http.Client{Transport: &requestParamsTransport{base: modelConfig.HTTPClient.Transport}}
+	}
+
+	return ark.NewChatModel(ctx, modelConfig)
+}
+
+type requestParamsKey struct{}
+
+// withRequestParams 将本次调用的模型参数放入 ctx，由 requestParamsTransport 写入请求体
+func withRequestParams(ctx context.Context, params entity.ModelParams) context.Context {
+	if params.FrequencyPenalty == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestParamsKey{}, params)
+}
+
+// requestParamsTransport ark 客户端只能在创建时设置 frequency_penalty，无法按调用指定
+// 这里从请求的 ctx 中取出本次调用的参数写入 chat/completions 请求体
+type requestParamsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *requestParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, ok := req.Context().Value(requestParamsKey{}).(entity.ModelParams)
+	if !ok || req.Body == nil || req.Method != http.MethodPost {
+		return t.base.RoundTrip(req)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err == nil {
+		body["frequency_penalty"], _ = json.Marshal(*params.FrequencyPenalty)
+		if patched, err := json.Marshal(body); err == nil {
+			data = patched
+		}
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return t.base.RoundTrip(req)
+}
+
+// azureTransport 将 OpenAI 格式的请求改为 Azure OpenAI 的鉴权方式并带上接口版本
+type azureTransport struct {
+	apiKey     string
+	apiVersion string
+	base       http.RoundTripper
+}
+
+func (t *azureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("api-version", t.apiVersion)
+	req.URL.RawQuery = query.Encode()
+	req.Header.Del("Authorization")
+	req.Header.Set("api-key", t.apiKey)
+	return t.base.RoundTrip(req)
+}
+
+// VisionModel 发送图片时使用的提供方：所选提供方支持图片时不变，否则依次使用配置的 vision_provider、第一个支持图片的提供方
+func (a *AiChatClient) VisionModel(modelName string) (string, bool) {
+	if index := max(a.findProvider(modelName), 0); a.providers[index].Vision {
+		return modelName, true
+	}
+	if a.visionProvider != "" {
+		return a.visionProvider, true
+	}
+	for _, p := range a.providers {
+		if p.Vision {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// hasImages 消息中是否有要发送给模型的图片
+func hasImages(messages []*entity.Message) bool {
+	for _, message := range messages {
+		for _, image := range message.Images {
+			if image.Data != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SupportModel 请求选择的模型是否可用，可按提供方名称或模型名称选择，为空表示使用默认提供方
+func (a *AiChatClient) SupportModel(modelName string) bool {
+	return modelName == "" || a.findProvider(modelName) >= 0
+}
+
+func (a *AiChatClient) findProvider(modelName string) int {
+	for i, p := range a.providers {
+		if p.Name == modelName || p.ModelName() == modelName {
+			return i
+		}
+	}
+	return -1
+}
+
+// providerOrder 本次调用依次尝试的提供方：请求选择的提供方在前，其余按配置顺序回退
+// vision 为 true 时只使用支持图片的提供方
+func (a *AiChatClient) providerOrder(modelName string, vision bool) []*aiProvider {
+	order := a.providers
+	if index := a.findProvider(modelName); index > 0 {
+		order = make([]*aiProvider, 0, len(a.providers))
+		order = append(order, a.providers[index])
+		order = append(order, a.providers[:index]...)
+		order = append(order, a.providers[index+1:]...)
+	}
+	if !vision {
+		return order
+	}
+	visionOrder := make([]*aiProvider, 0, len(order))
+	for _, p := range order {
+		if p.Vision {
+			visionOrder = append(visionOrder, p)
+		}
+	}
+	return visionOrder
+}
+
+// callWithFallback 依次用各提供方调用 call，返回最终成功的提供方
+// 临时失败（超时、网络错误、限流、5xx）时先在同一提供方退避重试，重试次数用完或提供方熔断后换下一个提供方，其他错误直接返回
+// 已熔断的提供方直接跳过；所有重试与回退不超过配置的总时长
+// canFallback 不为空时还需要它返回 true 才会重试或回退，用于流式输出已经发出部分内容的情况
+// vision 为 true 时只在支持图片的提供方之间回退
+func (a *AiChatClient) callWithFallback(ctx context.Context, modelName string, vision bool,
+	call func(ctx context.Context, p *aiProvider) error, canFallback func() bool) (*aiProvider, error) {
+	order := a.providerOrder(modelName, vision)
+	if len(order) == 0 {
+		return nil, errVisionNotSupported
+	}
+
+	budgetCtx, cancel := context.WithTimeout(ctx, a.resilience.Budget())
+	defer cancel()
+
+	lastErr := errCircuitOpen
+	for _, p := range order {
+		if !p.breaker.allow() {
+			recordProviderEvent(p.Name, callEventCircuitOpen)
+			continue
+		}
+		outcome, err := a.callProvider(ctx, budgetCtx, p, call, canFallback)
+		if err == nil {
+			return p, nil
+		}
+		lastErr = err
+		if !isTransient(outcome) || budgetCtx.Err() != nil || (canFallback != nil && !canFallback()) {
+			return nil, err
+		}
+		recordProviderEvent(p.Name, callEventFallback)
+		zlog.CtxWarnf(ctx, "ai提供方 %s 调用失败，尝试下一个提供方: %v", p.Name, err)
+	}
+	return nil, lastErr
+}
+
+// callProvider 用单个提供方调用 call，临时失败时按退避时间重试，返回最后一次调用的结果分类与错误
+func (a *AiChatClient) callProvider(ctx, budgetCtx context.Context, p *aiProvider,
+	call func(ctx context.Context, p *aiProvider) error, canFallback func() bool) (string, error) {
+	for attempt := 1; ; attempt++ {
+		callCtx, cancel := context.WithTimeout(budgetCtx, p.Timeout)
+		callCtx, span := tracing.Start(callCtx, "ai.call", tracing.SpanKindInternal,
+			tracing.String("gen_ai.system", p.Name),
+			tracing.String("gen_ai.request.model", p.ModelName()),
+			tracing.Int("ai.attempt", attempt),
+		)
+		err := call(callCtx, p)
+		cancel()
+
+		outcome := classifyError(ctx, err)
+		recordProviderEvent(p.Name, outcome)
+		span.SetAttributes(tracing.String("ai.outcome", outcome))
+		span.RecordError(err)
+		span.End()
+		switch {
+		case outcome == callOutcomeCanceled:
+			p.breaker.release()
+			return outcome, err
+		case isTransient(outcome):
+			if p.breaker.onFailure() {
+				zlog.CtxWarnf(ctx, "ai提供方 %s 连续调用失败，暂停使用", p.Name)
+				return outcome, err
+			}
+		default:
+			// 成功或与提供方可用性无关的错误（如参数错误）
+			p.breaker.onSuccess()
+			return outcome, err
+		}
+
+		if attempt >= a.resilience.Attempts() || (canFallback != nil && !canFallback()) {
+			return outcome, err
+		}
+		wait := backoff(a.resilience, attempt)
+		zlog.CtxWarnf(ctx, "ai提供方 %s 第 %d 次调用失败，%v 后重试: %v", p.Name, attempt, wait, err)
+		if !sleepCtx(budgetCtx, wait) {
+			return outcome, err
+		}
+		recordProviderEvent(p.Name, callEventRetry)
+	}
+}
@@ -0,0 +1,194 @@
+package eino
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"forge/infra/configs"
+
+	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+// 单次模型调用的结果分类，用于决定是否重试、回退，并记录到 ai_provider_events
+const (
+	callOutcomeSuccess     = "success"
+	callOutcomeTimeout     = "timeout"      // 单次调用超时
+	callOutcomeNetwork     = "network"      // 连接失败等网络错误
+	callOutcomeRateLimited = "rate_limited" // 提供方返回 429
+	callOutcomeServerError = "server_error" // 提供方返回 5xx
+	callOutcomeClientError = "client_error" // 提供方返回其他 4xx，如参数错误、密钥无效
+	callOutcomeCanceled    = "canceled"     // 请求本身已取消，如客户端断开
+	callOutcomeOther       = "other"        // 无法分类的错误
+)
+
+// 调用过程中的事件，与调用结果一起记录
+const (
+	callEventRetry       = "retry"        // 在同一提供方重试
+	callEventFallback    = "fallback"     // 回退到下一个提供方
+	callEventCircuitOpen = "circuit_open" // 提供方已熔断，跳过
+)
+
+// errCircuitOpen 所有可用的提供方都已熔断
+var errCircuitOpen = errors.New("ai服务暂时不可用，请稍后再试")
+
+// providerEvents 各提供方的调用结果与事件计数，键为 提供方名称.结果
+var providerEvents = expvar.NewMap("ai_provider_events")
+
+func recordProviderEvent(provider, event string) {
+	providerEvents.Add(provider+"."+event, 1)
+}
+
+// classifyError 对单次调用的错误分类；请求本身已取消时不再区分错误类型
+func classifyError(ctx context.Context, err error) string {
+	if err == nil {
+		return callOutcomeSuccess
+	}
+	if ctx.Err() != nil {
+		return callOutcomeCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return callOutcomeTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return callOutcomeTimeout
+		}
+		return callOutcomeNetwork
+	}
+	statusCode := 0
+	var apiErr *model.APIError
+	var reqErr *model.RequestError
+	if errors.As(err, &apiErr) {
+		statusCode = apiErr.HTTPStatusCode
+	} else if errors.As(err, &reqErr) {
+		statusCode = reqErr.HTTPStatusCode
+	}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return callOutcomeRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return callOutcomeServerError
+	case statusCode >= http.StatusBadRequest:
+		return callOutcomeClientError
+	}
+	return callOutcomeOther
+}
+
+// isTransient 临时失败可以重试或回退到其他提供方，并计入熔断
+func isTransient(outcome string) bool {
+	switch outcome {
+	case callOutcomeTimeout, callOutcomeNetwork, callOutcomeRateLimited, callOutcomeServerError:
+		return true
+	}
+	return false
+}
+
+// backoff 第 attempt 次重试前的等待时间：按指数增长到上限后取 [d/2, d] 之间的随机值，避免多个请求同时重试
+func backoff(cfg configs.AiResilienceConfig, attempt int) time.Duration {
+	d := cfg.BackoffBase()
+	for i := 1; i < attempt && d < cfg.BackoffMax(); i++ {
+		d *= 2
+	}
+	d = min(d, cfg.BackoffMax())
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+// sleepCtx 等待 d，ctx 结束时提前返回 false
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// 熔断器状态
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// circuitBreaker 单个提供方的熔断器：连续临时失败达到阈值后熔断，冷却结束后只放行一次试探调用
+// 试探成功时恢复，失败时重新熔断
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     string
+	openUntil time.Time
+}
+
+func newCircuitBreaker(cfg configs.AiResilienceConfig) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: cfg.FailureThreshold(),
+		cooldown:  cfg.BreakerCooldown(),
+		state:     breakerClosed,
+	}
+}
+
+// allow 是否可以调用该提供方，冷却结束后第一个调用方作为试探
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// 试探调用还未结束
+		return false
+	default:
+		return true
+	}
+}
+
+// onSuccess 调用成功或失败原因与提供方无关时恢复
+func (b *circuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.state = breakerClosed
+}
+
+// onFailure 记录一次临时失败，返回提供方是否已熔断
+func (b *circuitBreaker) onFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+	return b.state == breakerOpen
+}
+
+// release 试探调用因请求取消等原因没有结果时，把状态还给下一个调用方
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
+
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -2,25 +2,38 @@ package eino
 
 import (
 	"context"
+	"fmt"
+	"forge/biz/entity"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
-func (a *AiChatClient) UpdateMindMap(ctx context.Context, params *UpdateMindMapParams) (string, error) {
+const (
+	updateMindMapToolName = "update_mind_map"
+	editMindMapToolName   = "edit_mind_map"
+)
+
+func (p *aiProvider) UpdateMindMap(ctx context.Context, params *UpdateMindMapParams) (string, error) {
 	message := initToolUpdateMindMap(params.MapJson, params.Requirement)
 
-	resp, err := a.ToolAiClient.Generate(ctx, message)
+	resp, err := p.ToolAiClient.Generate(ctx, message, p.modelOption())
 	if err != nil {
 		return "", err
 	}
+	// 工具在agent中执行，用量计入本轮对话
+	_ = compose.ProcessState[*State](ctx, func(_ context.Context, state *State) error {
+		state.Usage.Add(tokenUsageOf(resp))
+		return nil
+	})
 	return resp.Content, nil
 }
 
-func (a *AiChatClient) CreateUpdateMindMapTool() tool.InvokableTool {
+func (p *aiProvider) CreateUpdateMindMapTool() tool.InvokableTool {
 	updateMindMapTool := utils.NewTool(
 		&schema.ToolInfo{
-			Name: "update_mind_map",
+			Name: updateMindMapToolName,
 			Desc: "用于修改导图,需要修改导图时调用该工具,返回完整新导图JSON",
 			ParamsOneOf: schema.NewParamsOneOfByParams(
 				map[string]*schema.ParameterInfo{
@@ -36,6 +49,56 @@ func (a *AiChatClient) CreateUpdateMindMapTool() tool.InvokableTool {
 					},
 				},
 			),
-		}, a.UpdateMindMap)
+		}, p.UpdateMindMap)
 	return updateMindMapTool
 }
+
+// EditMindMap 只校验操作格式，操作由aichat服务校验并应用到导图
+func (p *aiProvider) EditMindMap(ctx context.Context, params *EditMindMapParams) (string, error) {
+	if len(params.Operations) == 0 {
+		return "", fmt.Errorf("operations 不能为空")
+	}
+	if len(params.Operations) > entity.MaxMindMapOperations {
+		return "", fmt.Errorf("单次最多 %d 个操作", entity.MaxMindMapOperations)
+	}
+	return fmt.Sprintf("已提交 %d 个导图操作", len(params.Operations)), nil
+}
+
+func (p *aiProvider) CreateEditMindMapTool() tool.InvokableTool {
+	editMindMapTool := utils.NewTool(
+		&schema.ToolInfo{
+			Name: editMindMapToolName,
+			Desc: "以结构化操作小幅修改导图（新增节点、重命名节点、删除子树），优先于 update_mind_map 使用；操作按顺序执行，后面的操作基于前面操作后的导图",
+			ParamsOneOf: schema.NewParamsOneOfByParams(
+				map[string]*schema.ParameterInfo{
+					"operations": {
+						Type:     schema.Array,
+						Desc:     "导图操作列表",
+						Required: true,
+						ElemInfo: &schema.ParameterInfo{
+							Type: schema.Object,
+							SubParams: map[string]*schema.ParameterInfo{
+								"op": {
+									Type:     schema.String,
+									Desc:     "add_node：在 path 指向的节点下追加子节点；rename_node：修改 path 指向的节点文本；delete_subtree：删除 path 指向的节点及其子节点",
+									Enum:     []string{entity.MindMapOpAddNode, entity.MindMapOpRenameNode, entity.MindMapOpDeleteSubtree},
+									Required: true,
+								},
+								"path": {
+									Type:     schema.Array,
+									Desc:     "节点路径：从根节点开始逐层的 children 下标（从0开始），[] 表示根节点，例如 [0, 2] 表示 root.children[0].children[2]",
+									ElemInfo: &schema.ParameterInfo{Type: schema.Integer},
+									Required: true,
+								},
+								"text": {
+									Type: schema.String,
+									Desc: "add_node 的新节点文本或 rename_node 的新文本",
+								},
+							},
+						},
+					},
+				},
+			),
+		}, p.EditMindMap)
+	return editMindMapTool
+}
@@ -1,10 +1,14 @@
 package eino
 
 import (
+	"encoding/base64"
 	"fmt"
 	"forge/biz/entity"
 	"forge/infra/configs"
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"strings"
 )
 
 func messagesDo2Input(Messages []*entity.Message) []*schema.Message {
@@ -12,17 +16,44 @@ func messagesDo2Input(Messages []*entity.Message) []*schema.Message {
 	res := make([]*schema.Message, 0)
 
 	for _, msg := range Messages {
-		res = append(res, &schema.Message{
+		message := &schema.Message{
 			Content:    msg.Content,
 			ToolCalls:  msg.ToolCalls,
 			ToolCallID: msg.ToolCallID,
 			Role:       schema.RoleType(msg.Role),
-		})
+		}
+		if parts := imageParts(msg); len(parts) > 0 {
+			// 带图片的用户消息改用多模态内容，文字作为第一部分
+			message.Content = ""
+			message.UserInputMultiContent = append([]schema.MessageInputPart{{Type: schema.ChatMessagePartTypeText, Text: msg.Content}}, parts...)
+		}
+		res = append(res, message)
 	}
 
 	return res
 }
 
+// imageParts 用户消息中已读取内容的图片，未读取的图片不发送
+func imageParts(msg *entity.Message) []schema.MessageInputPart {
+	if msg.Role != entity.USER {
+		return nil
+	}
+	var parts []schema.MessageInputPart
+	for _, image := range msg.Images {
+		if image.Data == nil {
+			continue
+		}
+		data := base64.StdEncoding.EncodeToString(image.Data)
+		parts = append(parts, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeImageURL,
+			Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{Base64Data: &data, MIMEType: image.ContentType},
+			},
+		})
+	}
+	return parts
+}
+
 func initGenerateMindMapMessage(text, userID string) []*schema.Message {
 	res := make([]*schema.Message, 0)
 	res = append(res, &schema.Message{
@@ -36,6 +67,19 @@ func initGenerateMindMapMessage(text, userID string) []*schema.Message {
 	return res
 }
 
+func initSummarizeMindMapMessage(title, outline string) []*schema.Message {
+	res := make([]*schema.Message, 0)
+	res = append(res, &schema.Message{
+		Content: configs.Config().GetAiChatConfig().SummarizeSystemPrompt(),
+		Role:    schema.System,
+	})
+	res = append(res, &schema.Message{
+		Content: fmt.Sprintf("导图标题：%s\n导图大纲：\n%s", title, outline),
+		Role:    schema.User,
+	})
+	return res
+}
+
 func initToolUpdateMindMap(mapData, requirement string) []*schema.Message {
 	res := make([]*schema.Message, 0)
 	res = append(res, &schema.Message{
@@ -45,7 +89,96 @@ func initToolUpdateMindMap(mapData, requirement string) []*schema.Message {
 	return res
 }
 
+// modelParamsOptions 会话预设中的模型参数，只作用于对话模型节点，工具中的模型调用仍使用默认值
+func modelParamsOptions(params entity.ModelParams) []compose.Option {
+	var opts []einomodel.Option
+	if params.Temperature != nil {
+		opts = append(opts, einomodel.WithTemperature(*params.Temperature))
+	}
+	if params.TopP != nil {
+		opts = append(opts, einomodel.WithTopP(*params.TopP))
+	}
+	if params.MaxTokens != nil {
+		opts = append(opts, einomodel.WithMaxTokens(*params.MaxTokens))
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return []compose.Option{compose.WithChatModelOption(opts...).DesignateNode("model")}
+}
+
+// tokenUsageOf 读取模型回复中的 token 用量，提供方未返回时为 0
+func tokenUsageOf(msg *schema.Message) entity.TokenUsage {
+	if msg == nil || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return entity.TokenUsage{}
+	}
+	return entity.TokenUsage{
+		PromptTokens:     int64(msg.ResponseMeta.Usage.PromptTokens),
+		CompletionTokens: int64(msg.ResponseMeta.Usage.CompletionTokens),
+	}
+}
+
 type UpdateMindMapParams struct {
 	Requirement string `json:"requirement" jsonschema:"description=更改导图的要求"`
 	MapJson     string `json:"map_json" jsonschema:"description=当前最新的导图json数据,不要注释、不要 Markdown 包裹"`
 }
+
+type EditMindMapParams struct {
+	Operations []MindMapOperationParams `json:"operations"`
+}
+
+type MindMapOperationParams struct {
+	Op   string `json:"op"`
+	Path []int  `json:"path"`
+	Text string `json:"text"`
+}
+
+// operationsParams2Entity 工具参数转导图操作
+func operationsParams2Entity(params []MindMapOperationParams) []entity.MindMapOperation {
+	ops := make([]entity.MindMapOperation, 0, len(params))
+	for _, param := range params {
+		ops = append(ops, entity.MindMapOperation{Op: param.Op, Path: param.Path, Text: param.Text})
+	}
+	return ops
+}
+
+// findToolCall 按ID查找工具调用
+func findToolCall(toolCalls []schema.ToolCall, toolCallID string) *schema.ToolCall {
+	for i := range toolCalls {
+		if toolCalls[i].ID == toolCallID {
+			return &toolCalls[i]
+		}
+	}
+	return nil
+}
+
+// MindMapSummaryOutput 模型返回的导图总结
+type MindMapSummaryOutput struct {
+	Summary  string `json:"summary"`
+	Branches []struct {
+		Title     string   `json:"title"`
+		KeyPoints []string `json:"key_points"`
+	} `json:"branches"`
+}
+
+// toEntity 模型返回的导图总结转实体
+func (o *MindMapSummaryOutput) toEntity() entity.MindMapSummary {
+	summary := entity.MindMapSummary{Summary: o.Summary}
+	for _, branch := range o.Branches {
+		summary.Branches = append(summary.Branches, entity.BranchSummary{Title: branch.Title, KeyPoints: branch.KeyPoints})
+	}
+	return summary
+}
+
+// trimCodeFence 去掉模型回复外层的 Markdown 代码块
+func trimCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	if i := strings.Index(content, "\n"); i >= 0 {
+		content = content[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
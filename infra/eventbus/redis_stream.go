@@ -0,0 +1,40 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/cache"
+	"forge/infra/configs"
+)
+
+// NewEventPublisher 按配置创建事件投递，未开启发件箱时返回 nil
+func NewEventPublisher(cfg configs.OutboxConfig) adapter.EventPublisher {
+	if !cfg.Enable {
+		return nil
+	}
+	return &redisStreamPublisher{
+		prefix: cfg.StreamPrefix(),
+		maxLen: cfg.StreamMaxLen(),
+	}
+}
+
+// redisStreamPublisher 将事件写入 {prefix}:{topic} Stream，消费方可使用消费组读取
+type redisStreamPublisher struct {
+	prefix string
+	maxLen int64
+}
+
+// Publish 写入一条消息，字段为 event_id、topic、key、payload、created_at
+func (r *redisStreamPublisher) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	_, err := cache.XAddRedis(ctx, r.prefix+":"+event.Topic, r.maxLen, map[string]interface{}{
+		"event_id":   event.EventID,
+		"topic":      event.Topic,
+		"key":        event.Key,
+		"payload":    event.Payload,
+		"created_at": event.CreatedAt.Format(time.RFC3339Nano),
+	})
+	return err
+}
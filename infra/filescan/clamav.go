@@ -0,0 +1,103 @@
+package filescan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+)
+
+// ref: https://docs.clamav.net/manual/Usage/Scanning.html#clamd
+const (
+	// INSTREAM 每个分块的大小，clamd 按 StreamMaxLength 限制总长度
+	clamAVChunkSize = 64 * 1024
+	// 响应只有一行，如 stream: OK、stream: Eicar-Signature FOUND
+	clamAVReplyLimit = 1024
+)
+
+// clamAVScanner 通过 clamd 的 INSTREAM 命令扫描，每次扫描使用一个新连接
+type clamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func newClamAVScanner(cfg configs.ClamAVScanConfig, timeout time.Duration) (*clamAVScanner, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("file scan provider clamav requires address")
+	}
+	network, address := "tcp", cfg.Address
+	if n, a, ok := strings.Cut(cfg.Address, "://"); ok {
+		network, address = n, a
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("unsupported clamav address: %s", cfg.Address)
+	}
+	return &clamAVScanner{network: network, address: address, timeout: timeout}, nil
+}
+
+func (c *clamAVScanner) Scan(ctx context.Context, _ string, data []byte) (*adapter.FileScanResult, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("connect clamd failed: %w", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// 命令以 z 开头、\0 结尾；文件内容按 4 字节大端长度加数据分块发送，长度为 0 的分块表示结束
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("send clamd command failed: %w", err)
+	}
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += clamAVChunkSize {
+		chunk := data[start:min(start+clamAVChunkSize, len(data))]
+		binary.BigEndian.PutUint32(size, uint32(len(chunk)))
+		if _, err := conn.Write(size); err != nil {
+			return nil, fmt.Errorf("send clamd stream failed: %w", err)
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return nil, fmt.Errorf("send clamd stream failed: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, fmt.Errorf("send clamd stream failed: %w", err)
+	}
+
+	reply, err := io.ReadAll(io.LimitReader(conn, clamAVReplyLimit))
+	if err != nil {
+		return nil, fmt.Errorf("read clamd reply failed: %w", err)
+	}
+	return parseClamAVReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamAVReply 解析 stream: OK、stream: {特征名} FOUND 与 {原因} ERROR
+func parseClamAVReply(reply string) (*adapter.FileScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return &adapter.FileScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &adapter.FileScanResult{
+			Infected:  true,
+			Source:    providerClamAV,
+			Signature: strings.TrimSuffix(result, " FOUND"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("clamd scan failed: %s", reply)
+	}
+}
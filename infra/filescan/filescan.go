@@ -0,0 +1,69 @@
+package filescan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	providerClamAV = "clamav"
+	providerHTTP   = "http"
+	// 扫描服务返回错误时最多读取的响应长度
+	scanErrorBodyLimit = 4096
+)
+
+// NewFileScanner 按配置创建病毒扫描服务，未开启时返回 nil
+func NewFileScanner(cfg configs.FileScanConfig) (adapter.FileScanner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var scanner adapter.FileScanner
+	switch strings.ToLower(cfg.Provider) {
+	case providerClamAV:
+		s, err := newClamAVScanner(cfg.ClamAV, cfg.Timeout())
+		if err != nil {
+			return nil, err
+		}
+		scanner = s
+	case providerHTTP:
+		if cfg.HTTP.URL == "" {
+			return nil, fmt.Errorf("file scan provider http requires url")
+		}
+		scanner = newHTTPScanner(cfg.HTTP, httpclient.New(cfg.Timeout()))
+	default:
+		return nil, fmt.Errorf("unsupported file scan provider: %s", cfg.Provider)
+	}
+
+	zlog.Infof("file scan enabled, provider: %s, fail closed: %v", cfg.Provider, cfg.FailClosed)
+	return &guardedScanner{scanner: scanner, timeout: cfg.Timeout(), failClosed: cfg.FailClosed}, nil
+}
+
+// guardedScanner 限制单次扫描的时间，扫描服务出错时按 fail_closed 决定放行还是返回错误
+type guardedScanner struct {
+	scanner    adapter.FileScanner
+	timeout    time.Duration
+	failClosed bool
+}
+
+func (g *guardedScanner) Scan(ctx context.Context, filename string, data []byte) (*adapter.FileScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	result, err := g.scanner.Scan(ctx, filename, data)
+	if err != nil {
+		if g.failClosed {
+			return nil, err
+		}
+		zlog.CtxWarnf(ctx, "file scan failed, file allowed: %s, %v", filename, err)
+		return &adapter.FileScanResult{}, nil
+	}
+	return result, nil
+}
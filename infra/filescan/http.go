@@ -0,0 +1,61 @@
+package filescan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+)
+
+// httpScanner 云端扫描接口，请求体为文件内容，文件名放在 X-Filename 请求头
+type httpScanner struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func newHTTPScanner(cfg configs.HTTPFileScanConfig, client *http.Client) *httpScanner {
+	return &httpScanner{url: cfg.URL, apiKey: cfg.ApiKey, client: client}
+}
+
+type httpScanResp struct {
+	Infected  bool   `json:"infected"`
+	Signature string `json:"signature"`
+}
+
+func (h *httpScanner) Scan(ctx context.Context, filename string, data []byte) (*adapter.FileScanResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Filename", filename)
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("file scan request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, scanErrorBodyLimit))
+		return nil, fmt.Errorf("file scan returned %d: %s", resp.StatusCode, msg)
+	}
+
+	var result httpScanResp
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode file scan response: %w", err)
+	}
+	if !result.Infected {
+		return &adapter.FileScanResult{}, nil
+	}
+	return &adapter.FileScanResult{Infected: true, Source: providerHTTP, Signature: result.Signature}, nil
+}
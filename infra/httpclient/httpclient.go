@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/pkg/tracing"
+)
+
+// 连接池默认参数，未配置（0）时使用
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 20
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 5 * time.Second
+	defaultTLSHandshakeTimeout = 5 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+var (
+	transport *http.Transport
+	// traced 在 transport 之上为每次外部调用创建 client span，未开启链路追踪时直接转发
+	traced http.RoundTripper
+	once   sync.Once
+)
+
+// InitHTTPClient 根据配置初始化所有外部调用共用的连接池，需在各外部服务初始化之前调用
+func InitHTTPClient(cfg configs.HTTPClientConfig) {
+	once.Do(func() {
+		transport = newTransport(cfg)
+		traced = tracing.Transport(transport)
+		zlog.Infof("http client initialized, max idle conns per host: %d, max conns per host: %d",
+			transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	})
+}
+
+// Transport 获取共用的 Transport，各 SDK 需要自定义 RoundTripper 时以它为底层
+// 未初始化时使用默认参数
+func Transport() http.RoundTripper {
+	once.Do(func() {
+		transport = newTransport(configs.HTTPClientConfig{})
+		traced = tracing.Transport(transport)
+	})
+	return traced
+}
+
+// New 创建共用连接池的 http.Client
+// timeout 为整个请求（含读取响应体）的超时，流式接口传 0 并通过 ctx 控制超时
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: Transport(),
+		Timeout:   timeout,
+	}
+}
+
+func newTransport(cfg configs.HTTPClientConfig) *http.Transport {
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			zlog.Errorf("invalid http client proxy url %q, fallback to environment: %v", cfg.ProxyURL, err)
+		} else {
+			proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   durationOrDefault(cfg.DialTimeoutMs, time.Millisecond, defaultDialTimeout),
+		KeepAlive: defaultKeepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          intOrDefault(cfg.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   intOrDefault(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost, // 0 表示不限制
+		IdleConnTimeout:       durationOrDefault(cfg.IdleConnTimeoutSeconds, time.Second, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   durationOrDefault(cfg.TLSHandshakeTimeoutMs, time.Millisecond, defaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func intOrDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
+
+func durationOrDefault(value int, unit, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return time.Duration(value) * unit
+}
@@ -0,0 +1,399 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+)
+
+// jobLockTTL 单例任务锁的过期时间，持有期间自动续期，实例异常退出后最多等待该时长其他实例即可接手
+const jobLockTTL = time.Minute
+
+// Job 定时任务
+type Job struct {
+	Name string
+	// Schedule 执行时间表，格式见 ParseSchedule
+	Schedule string
+	Run      func(ctx context.Context) error
+	// Exclusive 多实例部署时每次计划执行只由一个实例执行；为 false 时各实例分别执行，如输出本实例的统计
+	Exclusive bool
+	// Retry 执行失败后的重试策略，零值表示不重试
+	Retry RetryPolicy
+	// Timeout 单次执行（含重试）的超时时间，为 0 时不限制
+	Timeout time.Duration
+}
+
+// RetryPolicy 重试策略，重试间隔从 Backoff 开始逐次翻倍，最长 MaxBackoff
+type RetryPolicy struct {
+	MaxAttempts int           // 最多执行次数（含第一次），小于等于 1 时不重试
+	Backoff     time.Duration // 第一次重试前的等待时间，默认 1 秒
+	MaxBackoff  time.Duration // 最长等待时间，默认 1 分钟
+}
+
+func (r RetryPolicy) attempts() int {
+	return max(r.MaxAttempts, 1)
+}
+
+// delay 第 failures 次失败后的重试间隔
+func (r RetryPolicy) delay(failures int) time.Duration {
+	backoff, maxBackoff := r.Backoff, r.MaxBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// Status 任务的执行状态；单例任务为所有实例中最近一次执行的状态，其他任务为本实例的状态
+type Status struct {
+	Name         string
+	Schedule     string
+	Exclusive    bool
+	Running      bool
+	NextRunAt    time.Time
+	LastRunAt    time.Time // 从未执行时为零值
+	LastDuration time.Duration
+	LastError    string // 最近一次执行成功时为空
+	LastAttempts int    // 最近一次执行的次数（含重试）
+	LastInstance string // 最近一次执行的实例（主机名:进程号）
+	Runs         int64
+	Failures     int64
+}
+
+// runRecord 单例任务保存在 Redis 中的执行记录
+type runRecord struct {
+	LastRunAt      time.Time `json:"last_run_at"`
+	LastDurationMs int64     `json:"last_duration_ms"`
+	LastError      string    `json:"last_error,omitempty"`
+	LastAttempts   int       `json:"last_attempts"`
+	LastInstance   string    `json:"last_instance"`
+	Runs           int64     `json:"runs"`
+	Failures       int64     `json:"failures"`
+}
+
+type registeredJob struct {
+	Job
+	schedule Schedule
+
+	mu      sync.Mutex
+	running bool
+	next    time.Time
+	record  runRecord
+}
+
+var (
+	mu       sync.Mutex
+	registry []*registeredJob
+	started  bool
+
+	// runCtx 服务退出时取消，任务执行完当前一次后退出
+	runCtx, cancelRun = context.WithCancel(context.Background())
+	wg                sync.WaitGroup
+
+	instance = instanceName()
+)
+
+// Register 注册定时任务，需在 Start 之前调用；名称重复或执行时间表无效时返回错误
+func Register(job Job) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return fmt.Errorf("job %s registered after start", job.Name)
+	}
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job name and run func are required")
+	}
+	for _, j := range registry {
+		if j.Name == job.Name {
+			return fmt.Errorf("duplicate job %s", job.Name)
+		}
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	registry = append(registry, &registeredJob{Job: job, schedule: schedule})
+	return nil
+}
+
+// Start 启动已注册的定时任务与延迟任务的处理，只能调用一次
+func Start() {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return
+	}
+	started = true
+	for _, j := range registry {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.loop(runCtx)
+		}()
+	}
+	startTaskWorker()
+}
+
+// Stop 通知任务退出并等待正在执行的任务完成，ctx 到期后不再等待
+func Stop(ctx context.Context) {
+	cancelRun()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		zlog.Warnf("等待后台任务退出超时")
+	}
+}
+
+// Stopping 服务是否正在退出，一次执行中循环处理多批数据的任务可据此提前结束
+func Stopping() bool {
+	return runCtx.Err() != nil
+}
+
+// ListStatus 按注册顺序返回所有定时任务的执行状态
+func ListStatus(ctx context.Context) ([]Status, error) {
+	mu.Lock()
+	jobs := append([]*registeredJob(nil), registry...)
+	mu.Unlock()
+
+	statuses := make([]Status, len(jobs))
+	for i, j := range jobs {
+		statuses[i] = j.status()
+	}
+
+	// 单例任务可能由其他实例执行，执行记录以 Redis 为准，是否正在执行以任务锁是否被持有为准
+	records, err := cache.HGetAllRedis(ctx, constant.REDIS_JOB_STATUS_KEY)
+	if err != nil {
+		return nil, err
+	}
+	var lockKeys []string
+	var exclusive []int
+	for i, j := range jobs {
+		if !j.Exclusive {
+			continue
+		}
+		lockKeys = append(lockKeys, fmt.Sprintf(constant.REDIS_JOB_LOCK_KEY, j.Name))
+		exclusive = append(exclusive, i)
+		if data, ok := records[j.Name]; ok {
+			var record runRecord
+			if err := json.Unmarshal([]byte(data), &record); err != nil {
+				zlog.CtxWarnf(ctx, "decode status of job %s failed: %v", j.Name, err)
+				continue
+			}
+			statuses[i].applyRecord(record)
+		}
+	}
+	locks, err := cache.MGetRedis(ctx, lockKeys...)
+	if err != nil {
+		return nil, err
+	}
+	for k, i := range exclusive {
+		statuses[i].Running = locks[k] != ""
+	}
+	return statuses, nil
+}
+
+func (s *Status) applyRecord(r runRecord) {
+	s.LastRunAt = r.LastRunAt
+	s.LastDuration = time.Duration(r.LastDurationMs) * time.Millisecond
+	s.LastError = r.LastError
+	s.LastAttempts = r.LastAttempts
+	s.LastInstance = r.LastInstance
+	s.Runs = r.Runs
+	s.Failures = r.Failures
+}
+
+func (j *registeredJob) status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := Status{
+		Name:      j.Name,
+		Schedule:  j.Job.Schedule,
+		Exclusive: j.Exclusive,
+		Running:   j.running,
+		NextRunAt: j.next,
+	}
+	s.applyRecord(j.record)
+	return s
+}
+
+// loop 按执行时间表等待并执行，上一次执行超过下一次计划时间时跳过错过的执行
+func (j *registeredJob) loop(ctx context.Context) {
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			zlog.Warnf("任务 %s 的执行时间表不会再匹配，停止调度", j.Name)
+			return
+		}
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		j.execute(ctx, next)
+	}
+}
+
+// execute 执行一次计划执行；单例任务先获取任务锁，再检查本次计划执行是否已由其他实例执行过
+// 各实例的时钟不完全一致，只加锁的话先后到期的实例会各执行一次，因此执行前按计划执行时间写入标记
+// 已开始的执行不随服务退出中断，只是不再重试
+func (j *registeredJob) execute(ctx context.Context, slot time.Time) {
+	execCtx := context.WithoutCancel(ctx)
+	if !j.Exclusive {
+		j.run(ctx, execCtx)
+		return
+	}
+	ran := false
+	ok, err := cache.WithLock(execCtx, fmt.Sprintf(constant.REDIS_JOB_LOCK_KEY, j.Name), jobLockTTL, func(lockCtx context.Context) {
+		first, err := cache.SetNXRedis(lockCtx, fmt.Sprintf(constant.REDIS_JOB_RAN_KEY, j.Name, slot.Unix()), instance, j.markerTTL(slot))
+		if err != nil {
+			zlog.Errorf("任务 %s 写入执行标记失败，跳过本次执行: %v", j.Name, err)
+			return
+		}
+		if !first {
+			return
+		}
+		ran = true
+		j.run(ctx, lockCtx)
+	})
+	if err != nil {
+		zlog.Errorf("任务 %s 获取锁失败，跳过本次执行: %v", j.Name, err)
+		return
+	}
+	if !ok || !ran {
+		zlog.Debugf("任务 %s 本次已由其他实例执行，跳过", j.Name)
+	}
+}
+
+// markerTTL 执行标记的有效期为一个执行周期，至少 1 分钟，最多 1 天
+func (j *registeredJob) markerTTL(slot time.Time) time.Duration {
+	period := j.schedule.Next(slot).Sub(slot)
+	return min(max(period, time.Minute), 24*time.Hour)
+}
+
+// run 执行任务并按重试策略重试，记录执行状态
+func (j *registeredJob) run(stopCtx, ctx context.Context) {
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+
+	start := time.Now()
+	attempts, err := runWithRetry(stopCtx, ctx, "任务 "+j.Name, j.Job.Run, j.Retry)
+	if err != nil {
+		zlog.Errorf("任务 %s 执行失败（共执行 %d 次）: %v", j.Name, attempts, err)
+	}
+	j.finish(ctx, start, attempts, err)
+}
+
+// finish 更新执行记录，单例任务同时写入 Redis，累计次数以 Redis 中的记录为准
+func (j *registeredJob) finish(ctx context.Context, start time.Time, attempts int, runErr error) {
+	// 执行超时或锁丢失后仍需保存状态
+	ctx = context.WithoutCancel(ctx)
+	j.mu.Lock()
+	record := j.record
+	j.running = false
+	j.mu.Unlock()
+
+	shared := j.Exclusive
+	if shared {
+		data, err := cache.HGetRedis(ctx, constant.REDIS_JOB_STATUS_KEY, j.Name)
+		if err == nil && data != "" {
+			_ = json.Unmarshal([]byte(data), &record)
+		}
+	}
+	record.LastRunAt = start
+	record.LastDurationMs = time.Since(start).Milliseconds()
+	record.LastError = ""
+	record.LastAttempts = attempts
+	record.LastInstance = instance
+	record.Runs++
+	if runErr != nil {
+		record.LastError = runErr.Error()
+		record.Failures++
+	}
+
+	j.mu.Lock()
+	j.record = record
+	j.mu.Unlock()
+	if !shared {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err := cache.HSetRedis(ctx, constant.REDIS_JOB_STATUS_KEY, j.Name, string(data)); err != nil {
+		zlog.Warnf("保存任务 %s 的执行状态失败: %v", j.Name, err)
+	}
+}
+
+// runWithRetry 执行 fn，失败时按重试策略等待后重试，返回执行次数与最后一次的错误
+// stopCtx 取消（服务退出）或 ctx 结束后不再重试
+func runWithRetry(stopCtx, ctx context.Context, name string, fn func(ctx context.Context) error, retry RetryPolicy) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := safeRun(ctx, fn)
+		if err == nil || attempt >= retry.attempts() || ctx.Err() != nil || stopCtx.Err() != nil {
+			return attempt, err
+		}
+		delay := retry.delay(attempt)
+		zlog.Warnf("%s 第 %d 次执行失败，%s 后重试: %v", name, attempt, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-stopCtx.Done():
+			timer.Stop()
+			return attempt, err
+		}
+	}
+}
+
+// safeRun 执行 fn，panic 时转换为错误，避免一个任务的 panic 导致服务退出
+func safeRun(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zlog.Errorf("后台任务 panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// instanceName 当前实例的名称，主机名:进程号
+func instanceName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
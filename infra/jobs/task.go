@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+const (
+	// 待执行的延迟任务的检查间隔
+	taskPollInterval = time.Second
+	// 延迟任务的默认超时时间
+	defaultTaskTimeout = 5 * time.Minute
+	// 任务超时后租约再保留的时间，超过租约仍未确认的任务由其他实例重新执行
+	taskLeaseMargin = time.Minute
+	// 每次回收租约到期的任务数
+	requeueBatchSize = 100
+)
+
+// ErrUnknownTask 提交的延迟任务类型没有注册处理函数
+var ErrUnknownTask = errors.New("unknown task type")
+
+// TaskHandler 延迟任务的处理函数，payload 为提交时的 JSON，返回错误时按重试策略重新执行
+// 任务至少执行一次，实例异常退出等情况下可能重复执行，处理函数需要幂等
+type TaskHandler func(ctx context.Context, payload json.RawMessage) error
+
+type taskHandler struct {
+	fn      TaskHandler
+	retry   RetryPolicy
+	timeout time.Duration
+}
+
+// task 延迟任务队列中的消息
+type task struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"` // 已执行失败的次数
+	LastError  string          `json:"last_error,omitempty"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+}
+
+var (
+	handlers  = map[string]*taskHandler{}
+	taskQueue = cache.NewDelayQueue(constant.REDIS_JOB_TASK_QUEUE)
+	// taskLease 取出任务后的租约，按最长的处理超时时间计算
+	taskLease time.Duration
+)
+
+// HandleTask 注册延迟任务的处理函数，需在 Start 之前调用；timeout 为 0 时使用默认的 5 分钟
+func HandleTask(taskType string, fn TaskHandler, retry RetryPolicy, timeout time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return fmt.Errorf("task %s registered after start", taskType)
+	}
+	if taskType == "" || fn == nil {
+		return fmt.Errorf("task type and handler are required")
+	}
+	if _, ok := handlers[taskType]; ok {
+		return fmt.Errorf("duplicate task %s", taskType)
+	}
+	if timeout <= 0 {
+		timeout = defaultTaskTimeout
+	}
+	handlers[taskType] = &taskHandler{fn: fn, retry: retry, timeout: timeout}
+	return nil
+}
+
+// Enqueue 提交延迟任务，delay 后由任一实例执行，返回任务ID
+// 执行失败时按注册时的重试策略重试，超过次数后放入死信列表
+func Enqueue(ctx context.Context, taskType string, payload any, delay time.Duration) (string, error) {
+	mu.Lock()
+	_, ok := handlers[taskType]
+	mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTask, taskType)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal task payload: %w", err)
+	}
+	id, err := util.GenerateStringID()
+	if err != nil {
+		return "", err
+	}
+	msg, err := json.Marshal(&task{ID: id, Type: taskType, Payload: data, EnqueuedAt: time.Now()})
+	if err != nil {
+		return "", err
+	}
+	if err := taskQueue.Push(ctx, string(msg), time.Now().Add(delay)); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// TaskQueueStats 延迟任务队列中待执行、执行中与死信的任务数
+func TaskQueueStats(ctx context.Context) (cache.DelayQueueStats, error) {
+	return taskQueue.Stats(ctx)
+}
+
+// startTaskWorker 注册了处理函数时启动延迟任务的处理，需持有 mu
+func startTaskWorker() {
+	if len(handlers) == 0 {
+		return
+	}
+	for _, h := range handlers {
+		taskLease = max(taskLease, h.timeout+taskLeaseMargin)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runTaskWorker(runCtx)
+	}()
+}
+
+// runTaskWorker 定时回收租约到期的任务，并逐个取出到期的任务执行，直到没有到期的任务
+func runTaskWorker(ctx context.Context) {
+	ticker := time.NewTicker(taskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		execCtx := context.WithoutCancel(ctx)
+		if n, err := taskQueue.RequeueExpired(execCtx, requeueBatchSize); err != nil {
+			zlog.Warnf("回收超时的延迟任务失败: %v", err)
+		} else if n > 0 {
+			zlog.Warnf("%d 个延迟任务超时未确认，已重新放回队列", n)
+		}
+		for ctx.Err() == nil {
+			msgs, err := taskQueue.Claim(execCtx, taskLease, 1)
+			if err != nil {
+				zlog.Warnf("获取延迟任务失败: %v", err)
+				break
+			}
+			if len(msgs) == 0 {
+				break
+			}
+			processTask(execCtx, msgs[0])
+		}
+	}
+}
+
+// processTask 执行一个任务：成功时确认，失败且未超过重试次数时按退避时间放回队列，否则放入死信列表
+func processTask(ctx context.Context, msg string) {
+	var t task
+	if err := json.Unmarshal([]byte(msg), &t); err != nil {
+		zlog.Errorf("延迟任务无法解析，放入死信列表: %v", err)
+		buryTask(ctx, msg, msg)
+		return
+	}
+	mu.Lock()
+	h := handlers[t.Type]
+	mu.Unlock()
+	if h == nil {
+		zlog.Errorf("延迟任务 %s 的类型 %s 没有处理函数，放入死信列表", t.ID, t.Type)
+		t.LastError = ErrUnknownTask.Error()
+		buryTask(ctx, msg, encodeTask(&t, msg))
+		return
+	}
+
+	taskCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	err := safeRun(taskCtx, func(ctx context.Context) error { return h.fn(ctx, t.Payload) })
+	cancel()
+	if err == nil {
+		if err := taskQueue.Ack(ctx, msg); err != nil {
+			zlog.Warnf("确认延迟任务 %s 失败，任务可能重复执行: %v", t.ID, err)
+		}
+		return
+	}
+
+	t.Attempts++
+	t.LastError = err.Error()
+	if t.Attempts >= h.retry.attempts() {
+		zlog.Errorf("延迟任务 %s（%s）执行失败 %d 次，放入死信列表: %v", t.ID, t.Type, t.Attempts, err)
+		buryTask(ctx, msg, encodeTask(&t, msg))
+		return
+	}
+	delay := h.retry.delay(t.Attempts)
+	zlog.Warnf("延迟任务 %s（%s）第 %d 次执行失败，%s 后重试: %v", t.ID, t.Type, t.Attempts, delay, err)
+	if err := taskQueue.Retry(ctx, msg, encodeTask(&t, msg), time.Now().Add(delay)); err != nil {
+		zlog.Errorf("延迟任务 %s 放回队列失败，租约到期后重新执行: %v", t.ID, err)
+	}
+}
+
+func buryTask(ctx context.Context, msg, dead string) {
+	if err := taskQueue.Bury(ctx, msg, dead); err != nil {
+		zlog.Errorf("延迟任务放入死信列表失败，租约到期后重新执行: %v", err)
+	}
+}
+
+// encodeTask 编码更新后的任务，失败时沿用原消息
+func encodeTask(t *task, fallback string) string {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fallback
+	}
+	return string(data)
+}
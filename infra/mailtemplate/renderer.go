@@ -0,0 +1,160 @@
+package mailtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	templateEmail "forge/template/email"
+)
+
+const (
+	defaultLanguage = "zh"
+	// subjectBlock 模板中定义邮件标题的块名
+	subjectBlock = "subject"
+)
+
+// ErrTemplateNotFound 指定模板在所有语言下都不存在
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Renderer 邮件模板渲染
+// 模板按 {语言}/{模板名}_{用途}.html -> {语言}/{模板名}.html 的顺序查找，
+// 找不到时回退到默认语言；配置了模板目录时优先使用目录中的模板，便于不重新编译替换品牌样式
+type Renderer struct {
+	sources     []fs.FS
+	defaultLang string
+
+	mu    sync.RWMutex
+	cache map[string]*template.Template
+}
+
+// NewRenderer 创建模板渲染器，dir 为空时只使用内置模板
+func NewRenderer(dir, defaultLang string) *Renderer {
+	var sources []fs.FS
+	if dir != "" {
+		sources = append(sources, os.DirFS(dir))
+	}
+	sources = append(sources, templateEmail.Templates)
+
+	if defaultLang = normalizeLanguage(defaultLang); defaultLang == "" {
+		defaultLang = defaultLanguage
+	}
+	return &Renderer{
+		sources:     sources,
+		defaultLang: defaultLang,
+		cache:       make(map[string]*template.Template),
+	}
+}
+
+// Render 渲染模板，返回邮件标题与 HTML 正文；模板未定义 subject 块时标题为空
+func (r *Renderer) Render(name, purpose, lang string, data any) (string, string, error) {
+	tmpl, err := r.lookup(name, purpose, lang)
+	if err != nil {
+		return "", "", err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("render email template %s failed: %w", tmpl.Name(), err)
+	}
+
+	var subject bytes.Buffer
+	if tmpl.Lookup(subjectBlock) != nil {
+		if err := tmpl.ExecuteTemplate(&subject, subjectBlock, data); err != nil {
+			return "", "", fmt.Errorf("render email subject %s failed: %w", tmpl.Name(), err)
+		}
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+// lookup 按语言与用途查找模板，解析结果按路径缓存
+func (r *Renderer) lookup(name, purpose, lang string) (*template.Template, error) {
+	// 用途来自请求参数，同样只允许作为文件名的一部分
+	if !isTemplateName(purpose) {
+		purpose = ""
+	}
+	langs := []string{r.defaultLang}
+	if lang = normalizeLanguage(lang); lang != "" && lang != r.defaultLang {
+		langs = []string{lang, r.defaultLang}
+	}
+
+	for _, l := range langs {
+		candidates := []string{l + "/" + name + ".html"}
+		if purpose != "" {
+			candidates = append([]string{l + "/" + name + "_" + purpose + ".html"}, candidates...)
+		}
+		for _, path := range candidates {
+			tmpl, err := r.load(path)
+			if err != nil {
+				return nil, err
+			}
+			if tmpl != nil {
+				return tmpl, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// load 从模板目录或内置模板中加载，文件不存在时返回 nil
+func (r *Renderer) load(path string) (*template.Template, error) {
+	r.mu.RLock()
+	tmpl, ok := r.cache[path]
+	r.mu.RUnlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	for _, source := range r.sources {
+		content, err := fs.ReadFile(source, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read email template %s failed: %w", path, err)
+		}
+		tmpl, err = template.New(path).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("parse email template %s failed: %w", path, err)
+		}
+		break
+	}
+
+	// 不存在的路径同样缓存，避免每次发送都访问磁盘
+	r.mu.Lock()
+	r.cache[path] = tmpl
+	r.mu.Unlock()
+	return tmpl, nil
+}
+
+// normalizeLanguage 从 Accept-Language 或 zh-CN 这类语言标签中取主语言
+func normalizeLanguage(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if i := strings.IndexAny(lang, ",;"); i >= 0 {
+		lang = lang[:i]
+	}
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ToLower(lang)
+	// 语言只作为目录名使用，拒绝路径字符
+	if !isTemplateName(lang) || strings.Contains(lang, "_") {
+		return ""
+	}
+	return lang
+}
+
+// isTemplateName 只允许小写字母与下划线
+func isTemplateName(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"forge/biz/adapter"
+)
+
+const sourceKeyword = "keyword"
+
+// keywordModerator 本地关键词匹配，忽略大小写与空白，避免用空格拆开关键词绕过
+type keywordModerator struct {
+	keywords []keyword
+}
+
+type keyword struct {
+	raw        string
+	normalized string
+}
+
+func newKeywordModerator(words []string) *keywordModerator {
+	m := &keywordModerator{}
+	for _, word := range words {
+		normalized := normalizeText(word)
+		if normalized == "" {
+			continue
+		}
+		m.keywords = append(m.keywords, keyword{raw: word, normalized: normalized})
+	}
+	return m
+}
+
+func (m *keywordModerator) Check(_ context.Context, text string) (*adapter.ModerationResult, error) {
+	normalized := normalizeText(text)
+	for _, kw := range m.keywords {
+		if strings.Contains(normalized, kw.normalized) {
+			return &adapter.ModerationResult{Blocked: true, Source: sourceKeyword, Category: kw.raw}, nil
+		}
+	}
+	return &adapter.ModerationResult{}, nil
+}
+
+// normalizeText 转小写并去掉空白
+func normalizeText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
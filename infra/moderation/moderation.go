@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	providerOpenAI = "openai"
+	// 审核服务出错且配置了 fail_closed 时的判定来源
+	sourceUnavailable = "unavailable"
+)
+
+// NewModerationService 按配置创建内容审核服务，未开启时返回 nil
+// 关键词在本地匹配，命中后不再调用第三方审核服务；审核服务出错时按 fail_closed 决定放行还是拒绝
+func NewModerationService(cfg configs.ModerationConfig) (adapter.ModerationService, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var checkers []adapter.ModerationService
+	keywords, err := loadKeywords(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) > 0 {
+		checkers = append(checkers, newKeywordModerator(keywords))
+	}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+	case providerOpenAI:
+		checkers = append(checkers, newOpenAIModerator(cfg.OpenAI, httpclient.New(cfg.Timeout())))
+	default:
+		return nil, fmt.Errorf("unsupported moderation provider: %s", cfg.Provider)
+	}
+
+	if len(checkers) == 0 {
+		return nil, fmt.Errorf("moderation enabled but neither keywords nor provider configured")
+	}
+	return &chainModerator{checkers: checkers, failClosed: cfg.FailClosed}, nil
+}
+
+// loadKeywords 合并配置中的关键词与关键词文件
+func loadKeywords(cfg configs.ModerationConfig) ([]string, error) {
+	keywords := append([]string(nil), cfg.Keywords...)
+	if cfg.KeywordFile == "" {
+		return keywords, nil
+	}
+
+	f, err := os.Open(cfg.KeywordFile)
+	if err != nil {
+		return nil, fmt.Errorf("open moderation keyword file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keywords = append(keywords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read moderation keyword file: %w", err)
+	}
+	return keywords, nil
+}
+
+// chainModerator 依次审核，任一审核方判定违规即返回
+type chainModerator struct {
+	checkers   []adapter.ModerationService
+	failClosed bool
+}
+
+func (c *chainModerator) Check(ctx context.Context, text string) (*adapter.ModerationResult, error) {
+	for _, checker := range c.checkers {
+		result, err := checker.Check(ctx, text)
+		if err != nil {
+			if c.failClosed {
+				return &adapter.ModerationResult{Blocked: true, Source: sourceUnavailable, Category: err.Error()}, nil
+			}
+			zlog.CtxWarnf(ctx, "moderation check failed, content allowed: %v", err)
+			continue
+		}
+		if result.Blocked {
+			return result, nil
+		}
+	}
+	return &adapter.ModerationResult{}, nil
+}
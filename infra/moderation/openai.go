@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+)
+
+const (
+	defaultOpenAIBaseURL         = "https://api.openai.com/v1"
+	defaultOpenAIModerationModel = "omni-moderation-latest"
+	openAIErrorBodyLimit         = 4096
+)
+
+// openAIModerator 调用 OpenAI 兼容的 /moderations 接口
+type openAIModerator struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOpenAIModerator(cfg configs.OpenAIModerationConfig, client *http.Client) *openAIModerator {
+	m := &openAIModerator{
+		apiKey:  cfg.ApiKey,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		model:   cfg.Model,
+		client:  client,
+	}
+	if m.baseURL == "" {
+		m.baseURL = defaultOpenAIBaseURL
+	}
+	if m.model == "" {
+		m.model = defaultOpenAIModerationModel
+	}
+	return m
+}
+
+type openAIModerationReq struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type openAIModerationResp struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+func (m *openAIModerator) Check(ctx context.Context, text string) (*adapter.ModerationResult, error) {
+	body, err := json.Marshal(openAIModerationReq{Model: m.model, Input: text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/moderations", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, openAIErrorBodyLimit))
+		return nil, fmt.Errorf("openai moderation returned %d: %s", resp.StatusCode, msg)
+	}
+
+	var result openAIModerationResp
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode openai moderation response: %w", err)
+	}
+	for _, r := range result.Results {
+		if !r.Flagged {
+			continue
+		}
+		var categories []string
+		for category, flagged := range r.Categories {
+			if flagged {
+				categories = append(categories, category)
+			}
+		}
+		sort.Strings(categories)
+		return &adapter.ModerationResult{Blocked: true, Source: providerOpenAI, Category: strings.Join(categories, ",")}, nil
+	}
+	return &adapter.ModerationResult{}, nil
+}
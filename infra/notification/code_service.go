@@ -5,14 +5,12 @@ import (
 	"context"
 	"fmt"
 	"html/template"
-	"io"
-	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
 	"forge/biz/adapter"
 	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/infra/mailtemplate"
 	"forge/pkg/log/zlog"
 	templateEmail "forge/template/email"
 
@@ -20,32 +18,57 @@ import (
 )
 
 type codeServiceImpl struct {
-	smtpConfig               configs.SMTPConfig
-	smsConfig                configs.SMSConfig
-	verificationCodeTemplate *template.Template
-	httpClient               *http.Client
+	smtpConfig              configs.SMTPConfig
+	smsProvider             smsProvider
+	templates               *mailtemplate.Renderer
+	contactReminderTemplate *template.Template
+	smtpTimeout             time.Duration
 }
 
 var cs *codeServiceImpl
 
 // InitCodeService 初始化验证码服务，需在程序启动时调用
-func InitCodeService(smtpConfig configs.SMTPConfig, smsConfig configs.SMSConfig) {
-	tmpl, err := template.New("verification_code").Parse(templateEmail.VerificationCodeTemplate)
-	if err != nil {
+func InitCodeService(smtpConfig configs.SMTPConfig, smsConfig configs.SMSConfig, timeoutConfig configs.TimeoutConfig) {
+	templates := mailtemplate.NewRenderer(smtpConfig.TemplateDir, smtpConfig.DefaultLanguage)
+	// 启动时渲染一次默认模板，模板有误时尽早暴露
+	if _, _, err := templates.Render(verificationCodeTemplate, "", "", verificationCodeData{}); err != nil {
 		zlog.Errorf("解析验证码邮件模板失败: %v", err)
 		panic(fmt.Sprintf("解析验证码邮件模板失败: %v", err))
 	}
+	if _, _, err := templates.Render(magicLinkTemplate, "", "", magicLinkData{}); err != nil {
+		zlog.Errorf("解析登录链接邮件模板失败: %v", err)
+		panic(fmt.Sprintf("解析登录链接邮件模板失败: %v", err))
+	}
+	if _, _, err := templates.Render(dataExportTemplate, "", "", dataExportData{}); err != nil {
+		zlog.Errorf("解析数据导出邮件模板失败: %v", err)
+		panic(fmt.Sprintf("解析数据导出邮件模板失败: %v", err))
+	}
+	reminderTmpl, err := template.New("contact_reminder").Parse(templateEmail.ContactReminderTemplate)
+	if err != nil {
+		zlog.Errorf("解析提醒邮件模板失败: %v", err)
+		panic(fmt.Sprintf("解析提醒邮件模板失败: %v", err))
+	}
+
+	httpClient := httpclient.New(10 * time.Second)
+	sms, err := newSMSProvider(smsConfig, httpClient)
+	if err != nil {
+		zlog.Errorf("初始化短信通道失败: %v", err)
+		panic(fmt.Sprintf("初始化短信通道失败: %v", err))
+	}
 
 	cs = &codeServiceImpl{
-		smtpConfig:               smtpConfig,
-		smsConfig:                smsConfig,
-		verificationCodeTemplate: tmpl,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		smtpConfig:              smtpConfig,
+		smsProvider:             sms,
+		templates:               templates,
+		contactReminderTemplate: reminderTmpl,
+		smtpTimeout:             timeoutConfig.SMTP(),
 	}
 
-	zlog.Infof("验证码服务初始化成功，已配置邮件与短信通道")
+	driver := smsConfig.Driver
+	if driver == "" {
+		driver = smsDriverHTTP
+	}
+	zlog.Infof("验证码服务初始化成功，已配置邮件与短信通道，短信服务商: %s", driver)
 }
 
 // GetCodeService 获取验证码服务实例
@@ -53,31 +76,51 @@ func GetCodeService() adapter.CodeService {
 	return cs
 }
 
+// verificationCodeTemplate 验证码邮件模板名
+const verificationCodeTemplate = "verification_code"
+
+// verificationCodeData 验证码邮件模板数据
+type verificationCodeData struct {
+	Code          string
+	Purpose       string
+	ExpireMinutes int
+	BrandName     string
+	LogoURL       string
+	SupportEmail  string
+}
+
 // SendEmailCode 发送邮件验证码
-func (c *codeServiceImpl) SendEmailCode(ctx context.Context, email, code string) error {
+func (c *codeServiceImpl) SendEmailCode(ctx context.Context, email string, msg *adapter.VerificationEmail) error {
 	if c == nil {
 		return fmt.Errorf("code service not initialized")
 	}
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
-	m.SetHeader("To", email)
-	m.SetHeader("Subject", "您的验证码")
-
-	data := map[string]string{
-		"Code": code,
+	data := verificationCodeData{
+		Code:          msg.Code,
+		Purpose:       msg.Purpose,
+		ExpireMinutes: int(msg.ExpiresIn.Minutes()),
+		BrandName:     c.smtpConfig.BrandName,
+		LogoURL:       c.smtpConfig.LogoURL,
+		SupportEmail:  c.smtpConfig.SupportEmail,
 	}
-	var emailBody bytes.Buffer
-	if err := c.verificationCodeTemplate.Execute(&emailBody, data); err != nil {
+	subject, body, err := c.templates.Render(verificationCodeTemplate, msg.Purpose, msg.Language, data)
+	if err != nil {
 		zlog.CtxErrorf(ctx, "渲染验证码邮件模板失败: %v", err)
 		return fmt.Errorf("渲染验证码邮件模板失败: %w", err)
 	}
+	if subject == "" {
+		subject = "您的验证码"
+	}
 
-	m.SetBody("text/html", emailBody.String())
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
 
 	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
 
-	if err := d.DialAndSend(m); err != nil {
+	if err := c.dialAndSend(ctx, d, m); err != nil {
 		zlog.CtxErrorf(ctx, "发送验证码邮件失败: %v", err)
 		return fmt.Errorf("发送验证码邮件失败: %w", err)
 	}
@@ -86,43 +129,169 @@ func (c *codeServiceImpl) SendEmailCode(ctx context.Context, email, code string)
 	return nil
 }
 
-// SendSMSCode 发送短信验证码
-func (c *codeServiceImpl) SendSMSCode(ctx context.Context, phone, code string) error {
+// SendContactReminder 发送绑定备用联系方式的提醒邮件
+func (c *codeServiceImpl) SendContactReminder(ctx context.Context, email string, reminder *adapter.ContactReminder) error {
 	if c == nil {
 		return fmt.Errorf("code service not initialized")
 	}
 
-	if c.smsConfig.Key == "" {
-		return fmt.Errorf("sms key not configured")
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", "为您的账号绑定手机号")
+	// 邮件客户端可据此展示一键退订
+	m.SetHeader("List-Unsubscribe", "<"+reminder.UnsubscribeURL+">")
+
+	var emailBody bytes.Buffer
+	if err := c.contactReminderTemplate.Execute(&emailBody, reminder); err != nil {
+		zlog.CtxErrorf(ctx, "渲染提醒邮件模板失败: %v", err)
+		return fmt.Errorf("渲染提醒邮件模板失败: %w", err)
 	}
 
-	endpoint := c.smsConfig.Endpoint
-	if endpoint == "" {
-		return fmt.Errorf("sms endpoint not configured")
+	m.SetBody("text/html", emailBody.String())
+
+	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
+
+	if err := c.dialAndSend(ctx, d, m); err != nil {
+		zlog.CtxErrorf(ctx, "发送提醒邮件失败: %v", err)
+		return fmt.Errorf("发送提醒邮件失败: %w", err)
 	}
 
-	smsURL := fmt.Sprintf(endpoint, c.smsConfig.Key, url.QueryEscape(code), url.QueryEscape(phone))
+	zlog.CtxInfof(ctx, "提醒邮件发送成功，邮箱: %s", email)
+	return nil
+}
+
+// magicLinkTemplate 免密登录邮件模板名
+const magicLinkTemplate = "magic_link"
+
+// magicLinkData 免密登录邮件模板数据
+type magicLinkData struct {
+	URL           string
+	ExpireMinutes int
+	BrandName     string
+	LogoURL       string
+	SupportEmail  string
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, smsURL, nil)
+// SendMagicLink 发送免密登录链接邮件
+func (c *codeServiceImpl) SendMagicLink(ctx context.Context, email string, msg *adapter.MagicLinkEmail) error {
+	if c == nil {
+		return fmt.Errorf("code service not initialized")
+	}
+
+	data := magicLinkData{
+		URL:           msg.URL,
+		ExpireMinutes: int(msg.ExpiresIn.Minutes()),
+		BrandName:     c.smtpConfig.BrandName,
+		LogoURL:       c.smtpConfig.LogoURL,
+		SupportEmail:  c.smtpConfig.SupportEmail,
+	}
+	subject, body, err := c.templates.Render(magicLinkTemplate, "", msg.Language, data)
 	if err != nil {
-		zlog.CtxErrorf(ctx, "创建短信服务请求失败: %v", err)
-		return fmt.Errorf("failed to create sms service request: %w", err)
+		zlog.CtxErrorf(ctx, "渲染登录链接邮件模板失败: %v", err)
+		return fmt.Errorf("渲染登录链接邮件模板失败: %w", err)
 	}
+	if subject == "" {
+		subject = "您的登录链接"
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
+
+	if err := c.dialAndSend(ctx, d, m); err != nil {
+		zlog.CtxErrorf(ctx, "发送登录链接邮件失败: %v", err)
+		return fmt.Errorf("发送登录链接邮件失败: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "登录链接邮件发送成功，邮箱: %s", email)
+	return nil
+}
+
+// dataExportTemplate 数据导出完成邮件模板名
+const dataExportTemplate = "data_export"
+
+// dataExportData 数据导出完成邮件模板数据
+type dataExportData struct {
+	URL           string
+	ExpireMinutes int
+	BrandName     string
+	LogoURL       string
+	SupportEmail  string
+}
 
-	resp, err := c.httpClient.Do(req)
+// SendDataExportReady 发送数据导出完成的通知邮件
+func (c *codeServiceImpl) SendDataExportReady(ctx context.Context, email string, msg *adapter.DataExportEmail) error {
+	if c == nil {
+		return fmt.Errorf("code service not initialized")
+	}
+
+	data := dataExportData{
+		URL:           msg.URL,
+		ExpireMinutes: int(msg.ExpiresIn.Minutes()),
+		BrandName:     c.smtpConfig.BrandName,
+		LogoURL:       c.smtpConfig.LogoURL,
+		SupportEmail:  c.smtpConfig.SupportEmail,
+	}
+	subject, body, err := c.templates.Render(dataExportTemplate, "", msg.Language, data)
 	if err != nil {
-		zlog.CtxErrorf(ctx, "请求短信服务失败: %v", err)
-		return fmt.Errorf("request sms service failed: %w", err)
+		zlog.CtxErrorf(ctx, "渲染数据导出邮件模板失败: %v", err)
+		return fmt.Errorf("渲染数据导出邮件模板失败: %w", err)
+	}
+	if subject == "" {
+		subject = "您的数据已导出完成"
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
+
+	if err := c.dialAndSend(ctx, d, m); err != nil {
+		zlog.CtxErrorf(ctx, "发送数据导出邮件失败: %v", err)
+		return fmt.Errorf("发送数据导出邮件失败: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "数据导出邮件发送成功，邮箱: %s", email)
+	return nil
+}
+
+// dialAndSend 带超时发送邮件
+// gomail 不支持 context，超时后直接返回，后台的发送协程会在连接出错或完成后退出
+func (c *codeServiceImpl) dialAndSend(ctx context.Context, d *gomail.Dialer, m *gomail.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, c.smtpTimeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.DialAndSend(m)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("smtp send timeout: %w", ctx.Err())
+	}
+}
+
+// SendSMSCode 发送短信验证码
+func (c *codeServiceImpl) SendSMSCode(ctx context.Context, phone, code string) error {
+	if c == nil {
+		return fmt.Errorf("code service not initialized")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		zlog.CtxErrorf(ctx, "短信服务返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-		return fmt.Errorf("sms service returned status %d", resp.StatusCode)
+	if err := c.smsProvider.SendCode(ctx, phone, code); err != nil {
+		return err
 	}
 
-	_, _ = io.Copy(io.Discard, resp.Body)
 	zlog.CtxInfof(ctx, "短信验证码发送成功，手机号: %s", phone)
 	return nil
 }
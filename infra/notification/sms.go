@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"forge/infra/configs"
+)
+
+// 短信服务商
+const (
+	smsDriverHTTP    = "http"
+	smsDriverAliyun  = "aliyun"
+	smsDriverTencent = "tencent"
+	smsDriverTwilio  = "twilio"
+
+	defaultSMSCountryCode = "86"
+	smsErrorBodyLimit     = 4096
+)
+
+// smsProvider 短信服务商，只负责发送验证码
+// 服务商明确的号码错误、频率限制映射为 adapter.ErrSMSInvalidPhone / adapter.ErrSMSRateLimited
+type smsProvider interface {
+	SendCode(ctx context.Context, phone, code string) error
+}
+
+// newSMSProvider 按配置的 driver 创建短信服务商
+// 配置了 international_driver 时，区号不是 country_code 的号码改由国际短信服务商发送
+func newSMSProvider(cfg configs.SMSConfig, client *http.Client) (smsProvider, error) {
+	domestic, err := newSMSDriver(cfg.Driver, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.InternationalDriver == "" || strings.EqualFold(cfg.InternationalDriver, cfg.Driver) {
+		return domestic, nil
+	}
+	international, err := newSMSDriver(cfg.InternationalDriver, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	return &routingSMSProvider{
+		domestic:      domestic,
+		international: international,
+		countryCode:   smsCountryCode(cfg),
+	}, nil
+}
+
+func newSMSDriver(driver string, cfg configs.SMSConfig, client *http.Client) (smsProvider, error) {
+	switch strings.ToLower(driver) {
+	case "", smsDriverHTTP:
+		return &httpSMSProvider{key: cfg.Key, endpoint: cfg.Endpoint, client: client}, nil
+	case smsDriverAliyun:
+		return newAliyunSMSProvider(cfg.Aliyun, client), nil
+	case smsDriverTencent:
+		return newTencentSMSProvider(cfg.Tencent, smsCountryCode(cfg), client), nil
+	case smsDriverTwilio:
+		return newTwilioSMSProvider(cfg.Twilio, smsCountryCode(cfg), client), nil
+	default:
+		return nil, fmt.Errorf("unsupported sms driver: %s", driver)
+	}
+}
+
+// routingSMSProvider 按手机号的国际区号选择服务商：
+// 未带区号或区号为 country_code 的号码走国内通道，其他号码走国际通道
+type routingSMSProvider struct {
+	domestic      smsProvider
+	international smsProvider
+	countryCode   string
+}
+
+func (p *routingSMSProvider) SendCode(ctx context.Context, phone, code string) error {
+	if isDomesticPhone(phone, p.countryCode) {
+		return p.domestic.SendCode(ctx, phone, code)
+	}
+	return p.international.SendCode(ctx, phone, code)
+}
+
+// isDomesticPhone 国内手机号不带区号保存，其他地区的号码保存为 E.164 格式
+func isDomesticPhone(phone, countryCode string) bool {
+	phone = strings.TrimSpace(phone)
+	return !strings.HasPrefix(phone, "+") || strings.HasPrefix(phone, "+"+countryCode)
+}
+
+func smsCountryCode(cfg configs.SMSConfig) string {
+	code := strings.TrimPrefix(strings.TrimSpace(cfg.CountryCode), "+")
+	if code == "" {
+		return defaultSMSCountryCode
+	}
+	return code
+}
+
+// toE164 将手机号转换为 +区号号码 的格式，已带 + 的号码原样返回
+func toE164(phone, countryCode string) string {
+	phone = strings.TrimSpace(phone)
+	if strings.HasPrefix(phone, "+") {
+		return phone
+	}
+	return "+" + countryCode + phone
+}
@@ -0,0 +1,155 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// ref: https://help.aliyun.com/zh/sms/developer-reference/api-dysmsapi-2017-05-25-sendsms
+const (
+	aliyunSMSEndpoint      = "https://dysmsapi.aliyuncs.com/"
+	aliyunSMSVersion       = "2017-05-25"
+	aliyunDefaultRegionID  = "cn-hangzhou"
+	aliyunSMSCodeOK        = "OK"
+	aliyunSMSInvalidMobile = "isv.MOBILE_NUMBER_ILLEGAL"
+)
+
+// aliyunRateLimitCodes 阿里云对同一号码的分钟级、小时级、天级流控
+var aliyunRateLimitCodes = map[string]bool{
+	"isv.BUSINESS_LIMIT_CONTROL": true,
+	"isv.DAY_LIMIT_CONTROL":      true,
+}
+
+// aliyunSMSProvider 阿里云短信，RPC 风格接口，签名算法 HMAC-SHA1
+type aliyunSMSProvider struct {
+	cfg    configs.AliyunSMSConfig
+	client *http.Client
+}
+
+func newAliyunSMSProvider(cfg configs.AliyunSMSConfig, client *http.Client) *aliyunSMSProvider {
+	if cfg.RegionID == "" {
+		cfg.RegionID = aliyunDefaultRegionID
+	}
+	return &aliyunSMSProvider{cfg: cfg, client: client}
+}
+
+type aliyunSMSResp struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestID string `json:"RequestId"`
+}
+
+func (p *aliyunSMSProvider) SendCode(ctx context.Context, phone, code string) error {
+	templateParam, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		return fmt.Errorf("marshal aliyun template param failed: %w", err)
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return fmt.Errorf("generate aliyun signature nonce failed: %w", err)
+	}
+
+	params := map[string]string{
+		"AccessKeyId":      p.cfg.AccessKeyID,
+		"Action":           "SendSms",
+		"Format":           "JSON",
+		"PhoneNumbers":     strings.TrimPrefix(phone, "+"),
+		"RegionId":         p.cfg.RegionID,
+		"SignName":         p.cfg.SignName,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   nonce,
+		"SignatureVersion": "1.0",
+		"TemplateCode":     p.cfg.TemplateCode,
+		"TemplateParam":    string(templateParam),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Version":          aliyunSMSVersion,
+	}
+	query := aliyunCanonicalQuery(params)
+	signature := aliyunSign(p.cfg.AccessKeySecret, query)
+	reqURL := aliyunSMSEndpoint + "?Signature=" + aliyunPercentEncode(signature) + "&" + query
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("create aliyun sms request failed: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request aliyun sms failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, smsErrorBodyLimit))
+	if err != nil {
+		return fmt.Errorf("read aliyun sms response failed: %w", err)
+	}
+	var result aliyunSMSResp
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("aliyun sms returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if result.Code == aliyunSMSCodeOK {
+		return nil
+	}
+
+	zlog.CtxWarnf(ctx, "阿里云短信发送失败: %s %s, requestID: %s", result.Code, result.Message, result.RequestID)
+	switch {
+	case result.Code == aliyunSMSInvalidMobile:
+		return adapter.ErrSMSInvalidPhone
+	case aliyunRateLimitCodes[result.Code]:
+		return adapter.ErrSMSRateLimited
+	}
+	return fmt.Errorf("aliyun sms failed: %s %s", result.Code, result.Message)
+}
+
+// aliyunCanonicalQuery 参数按名称排序后编码拼接
+func aliyunCanonicalQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, aliyunPercentEncode(k)+"="+aliyunPercentEncode(params[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func aliyunSign(secret, canonicalQuery string) string {
+	stringToSign := http.MethodGet + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(canonicalQuery)
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunPercentEncode 阿里云要求的 RFC3986 编码：空格为 %20，* 编码，~ 不编码
+func aliyunPercentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
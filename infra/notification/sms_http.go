@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"forge/pkg/log/zlog"
+)
+
+// httpSMSProvider 通过 GET 请求调用的短信网关，endpoint 依次填入 key、验证码、手机号
+type httpSMSProvider struct {
+	key      string
+	endpoint string
+	client   *http.Client
+}
+
+func (p *httpSMSProvider) SendCode(ctx context.Context, phone, code string) error {
+	if p.key == "" {
+		return fmt.Errorf("sms key not configured")
+	}
+	if p.endpoint == "" {
+		return fmt.Errorf("sms endpoint not configured")
+	}
+
+	smsURL := fmt.Sprintf(p.endpoint, p.key, url.QueryEscape(code), url.QueryEscape(phone))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, smsURL, nil)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "创建短信服务请求失败: %v", err)
+		return fmt.Errorf("failed to create sms service request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "请求短信服务失败: %v", err)
+		return fmt.Errorf("request sms service failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		zlog.CtxErrorf(ctx, "短信服务返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return fmt.Errorf("sms service returned status %d", resp.StatusCode)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// ref: https://cloud.tencent.com/document/product/382/55981
+const (
+	tencentSMSHost          = "sms.tencentcloudapi.com"
+	tencentSMSService       = "sms"
+	tencentSMSVersion       = "2021-01-11"
+	tencentDefaultSMSRegion = "ap-guangzhou"
+	tencentSMSCodeOK        = "Ok"
+	tencentContentType      = "application/json; charset=utf-8"
+)
+
+// tencentSMSProvider 腾讯云短信，API 3.0，签名算法 TC3-HMAC-SHA256
+type tencentSMSProvider struct {
+	cfg         configs.TencentSMSConfig
+	countryCode string
+	client      *http.Client
+}
+
+func newTencentSMSProvider(cfg configs.TencentSMSConfig, countryCode string, client *http.Client) *tencentSMSProvider {
+	if cfg.Region == "" {
+		cfg.Region = tencentDefaultSMSRegion
+	}
+	return &tencentSMSProvider{cfg: cfg, countryCode: countryCode, client: client}
+}
+
+type tencentSMSReq struct {
+	PhoneNumberSet   []string `json:"PhoneNumberSet"`
+	SmsSdkAppID      string   `json:"SmsSdkAppId"`
+	SignName         string   `json:"SignName"`
+	TemplateID       string   `json:"TemplateId"`
+	TemplateParamSet []string `json:"TemplateParamSet"`
+}
+
+type tencentSMSResp struct {
+	Response struct {
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+		SendStatusSet []struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"SendStatusSet"`
+		RequestID string `json:"RequestId"`
+	} `json:"Response"`
+}
+
+func (p *tencentSMSProvider) SendCode(ctx context.Context, phone, code string) error {
+	payload, err := json.Marshal(tencentSMSReq{
+		PhoneNumberSet:   []string{toE164(phone, p.countryCode)},
+		SmsSdkAppID:      p.cfg.SdkAppID,
+		SignName:         p.cfg.SignName,
+		TemplateID:       p.cfg.TemplateID,
+		TemplateParamSet: []string{code},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal tencent sms request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+tencentSMSHost, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create tencent sms request failed: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", tencentContentType)
+	req.Header.Set("Host", tencentSMSHost)
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", tencentSMSVersion)
+	req.Header.Set("X-TC-Region", p.cfg.Region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("Authorization", p.authorization(payload, now))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request tencent sms failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, smsErrorBodyLimit))
+	if err != nil {
+		return fmt.Errorf("read tencent sms response failed: %w", err)
+	}
+	var result tencentSMSResp
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("tencent sms returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// 请求级错误（鉴权、参数）在 Error 中，单个号码的发送结果在 SendStatusSet 中
+	errCode, errMsg := "", ""
+	if result.Response.Error != nil {
+		errCode, errMsg = result.Response.Error.Code, result.Response.Error.Message
+	} else if len(result.Response.SendStatusSet) == 0 {
+		return fmt.Errorf("tencent sms returned empty send status, requestID: %s", result.Response.RequestID)
+	} else if status := result.Response.SendStatusSet[0]; status.Code != tencentSMSCodeOK {
+		errCode, errMsg = status.Code, status.Message
+	}
+	if errCode == "" {
+		return nil
+	}
+
+	zlog.CtxWarnf(ctx, "腾讯云短信发送失败: %s %s, requestID: %s", errCode, errMsg, result.Response.RequestID)
+	switch {
+	case strings.HasPrefix(errCode, "InvalidParameterValue.IncorrectPhoneNumber"),
+		errCode == "FailedOperation.PhoneNumberInBlacklist":
+		return adapter.ErrSMSInvalidPhone
+	case strings.HasPrefix(errCode, "LimitExceeded.PhoneNumber"):
+		return adapter.ErrSMSRateLimited
+	}
+	return fmt.Errorf("tencent sms failed: %s %s", errCode, errMsg)
+}
+
+// authorization 计算 TC3-HMAC-SHA256 签名，签名头只包含 content-type 与 host
+func (p *tencentSMSProvider) authorization(payload []byte, now time.Time) string {
+	date := now.UTC().Format("2006-01-02")
+	signedHeaders := "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + tencentContentType + "\nhost:" + tencentSMSHost + "\n",
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	credentialScope := date + "/" + tencentSMSService + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		"TC3-HMAC-SHA256",
+		strconv.FormatInt(now.Unix(), 10),
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+p.cfg.SecretKey), date)
+	secretService := hmacSHA256(secretDate, tencentSMSService)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.cfg.SecretID, credentialScope, signedHeaders, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
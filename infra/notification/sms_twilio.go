@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// ref: https://www.twilio.com/docs/messaging/api/message-resource#create-a-message-resource
+const (
+	twilioMessagesURL  = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+	twilioDefaultBody  = "Your verification code is %s"
+	twilioRateLimitErr = 20429
+)
+
+// twilioInvalidPhoneCodes 号码无效、不是手机号、不支持该地区
+var twilioInvalidPhoneCodes = map[int]bool{
+	21211: true,
+	21408: true,
+	21610: true,
+	21614: true,
+}
+
+// twilioSMSProvider Twilio 短信，使用 Account SID 与 Auth Token 进行 Basic 认证
+type twilioSMSProvider struct {
+	cfg         configs.TwilioSMSConfig
+	countryCode string
+	client      *http.Client
+}
+
+func newTwilioSMSProvider(cfg configs.TwilioSMSConfig, countryCode string, client *http.Client) *twilioSMSProvider {
+	if cfg.Body == "" {
+		cfg.Body = twilioDefaultBody
+	}
+	return &twilioSMSProvider{cfg: cfg, countryCode: countryCode, client: client}
+}
+
+type twilioErrorResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (p *twilioSMSProvider) SendCode(ctx context.Context, phone, code string) error {
+	form := url.Values{}
+	form.Set("To", toE164(phone, p.countryCode))
+	if p.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", p.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", p.cfg.From)
+	}
+	if p.cfg.ContentSID != "" {
+		variables, err := json.Marshal(map[string]string{"1": code})
+		if err != nil {
+			return fmt.Errorf("marshal twilio content variables failed: %w", err)
+		}
+		form.Set("ContentSid", p.cfg.ContentSID)
+		form.Set("ContentVariables", string(variables))
+	} else {
+		form.Set("Body", fmt.Sprintf(p.cfg.Body, code))
+	}
+
+	reqURL := fmt.Sprintf(twilioMessagesURL, url.PathEscape(p.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create twilio request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request twilio failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, smsErrorBodyLimit))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var result twilioErrorResp
+	_ = json.Unmarshal(body, &result)
+	zlog.CtxWarnf(ctx, "Twilio 短信发送失败: status %d, code %d, %s", resp.StatusCode, result.Code, result.Message)
+	switch {
+	case twilioInvalidPhoneCodes[result.Code]:
+		return adapter.ErrSMSInvalidPhone
+	case resp.StatusCode == http.StatusTooManyRequests || result.Code == twilioRateLimitErr:
+		return adapter.ErrSMSRateLimited
+	}
+	return fmt.Errorf("twilio returned status %d: code %d %s", resp.StatusCode, result.Code, result.Message)
+}
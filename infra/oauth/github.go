@@ -0,0 +1,151 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	githubAuthorizeURL = "https://github.com/login/oauth/authorize"
+	githubTokenURL     = "https://github.com/login/oauth/access_token"
+	githubUserURL      = "https://api.github.com/user"
+	githubEmailsURL    = "https://api.github.com/user/emails"
+	githubReqTimeout   = 10 * time.Second
+
+	// 授权码无效、已使用或已过期
+	githubErrBadVerificationCode = "bad_verification_code"
+)
+
+// githubProvider GitHub OAuth App 登录
+type githubProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+}
+
+// NewGitHubProvider 创建 GitHub 登录
+func NewGitHubProvider(cfg configs.OAuthProviderConfig) adapter.OAuthProvider {
+	return &githubProvider{
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		client:       httpclient.New(githubReqTimeout),
+	}
+}
+
+func (g *githubProvider) Name() string {
+	return entity.OAuthProviderGitHub
+}
+
+func (g *githubProvider) AuthURL(state string) string {
+	query := url.Values{}
+	query.Set("client_id", g.clientID)
+	query.Set("redirect_uri", g.redirectURL)
+	query.Set("scope", "read:user user:email")
+	query.Set("state", state)
+	return githubAuthorizeURL + "?" + query.Encode()
+}
+
+// githubTokenResp 换取令牌失败时 HTTP 状态码仍为 200，通过 error 字段区分
+type githubTokenResp struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type githubUserResp struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmailResp struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (g *githubProvider) Exchange(ctx context.Context, code string) (*adapter.OAuthToken, error) {
+	form := url.Values{}
+	form.Set("client_id", g.clientID)
+	form.Set("client_secret", g.clientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", g.redirectURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, githubTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create github request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token githubTokenResp
+	if err := doJSON(g.client, req, &token); err != nil {
+		return nil, err
+	}
+	if token.Error != "" {
+		zlog.CtxWarnf(ctx, "github exchange code failed: %s %s", token.Error, token.ErrorDescription)
+		if token.Error == githubErrBadVerificationCode {
+			return nil, adapter.ErrOAuthCodeInvalid
+		}
+		return nil, fmt.Errorf("github exchange code failed: %s", token.Error)
+	}
+
+	return &adapter.OAuthToken{AccessToken: token.AccessToken}, nil
+}
+
+func (g *githubProvider) GetUserInfo(ctx context.Context, token *adapter.OAuthToken) (*adapter.OAuthUser, error) {
+	var user githubUserResp
+	if err := g.get(ctx, githubUserURL, token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+
+	// 用户公开资料中的邮箱不一定已验证，从邮箱列表中取已验证的主邮箱
+	var emails []githubEmailResp
+	if err := g.get(ctx, githubEmailsURL, token.AccessToken, &emails); err != nil {
+		// 邮箱只用于补全资料，获取失败不影响登录
+		zlog.CtxWarnf(ctx, "get github emails failed: %v", err)
+	}
+	var email string
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			email = e.Email
+			break
+		}
+	}
+
+	nickName := user.Name
+	if nickName == "" {
+		nickName = user.Login
+	}
+
+	return &adapter.OAuthUser{
+		Provider:    entity.OAuthProviderGitHub,
+		ProviderUID: strconv.FormatInt(user.ID, 10),
+		NickName:    nickName,
+		Avatar:      user.AvatarURL,
+		Email:       email,
+	}, nil
+}
+
+func (g *githubProvider) get(ctx context.Context, reqURL, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("create github request failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return doJSON(g.client, req, out)
+}
@@ -0,0 +1,135 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	googleAuthorizeURL = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL     = "https://oauth2.googleapis.com/token"
+	googleUserInfoURL  = "https://openidconnect.googleapis.com/v1/userinfo"
+	googleReqTimeout   = 10 * time.Second
+
+	// 授权码无效、已使用或已过期
+	googleErrInvalidGrant = "invalid_grant"
+)
+
+// googleProvider Google OpenID Connect 登录
+type googleProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+}
+
+// NewGoogleProvider 创建 Google 登录
+func NewGoogleProvider(cfg configs.OAuthProviderConfig) adapter.OAuthProvider {
+	return &googleProvider{
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		client:       httpclient.New(googleReqTimeout),
+	}
+}
+
+func (g *googleProvider) Name() string {
+	return entity.OAuthProviderGoogle
+}
+
+func (g *googleProvider) AuthURL(state string) string {
+	query := url.Values{}
+	query.Set("client_id", g.clientID)
+	query.Set("redirect_uri", g.redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	return googleAuthorizeURL + "?" + query.Encode()
+}
+
+type googleTokenResp struct {
+	AccessToken string `json:"access_token"`
+}
+
+// googleErrorResp 换取令牌失败时返回 4xx 与该结构
+type googleErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type googleUserInfoResp struct {
+	Sub           string `json:"sub"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func (g *googleProvider) Exchange(ctx context.Context, code string) (*adapter.OAuthToken, error) {
+	form := url.Values{}
+	form.Set("client_id", g.clientID)
+	form.Set("client_secret", g.clientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", g.redirectURL)
+	form.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create google request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token googleTokenResp
+	if err := doJSON(g.client, req, &token); err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			var errResp googleErrorResp
+			if json.Unmarshal(statusErr.Body, &errResp) == nil && errResp.Error == googleErrInvalidGrant {
+				zlog.CtxWarnf(ctx, "google exchange code failed: %s %s", errResp.Error, errResp.ErrorDescription)
+				return nil, adapter.ErrOAuthCodeInvalid
+			}
+		}
+		return nil, err
+	}
+
+	return &adapter.OAuthToken{AccessToken: token.AccessToken}, nil
+}
+
+func (g *googleProvider) GetUserInfo(ctx context.Context, token *adapter.OAuthToken) (*adapter.OAuthUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create google request failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	var info googleUserInfoResp
+	if err := doJSON(g.client, req, &info); err != nil {
+		return nil, err
+	}
+
+	// 未验证的邮箱不能用于关联本地账号
+	var email string
+	if info.EmailVerified {
+		email = info.Email
+	}
+
+	return &adapter.OAuthUser{
+		Provider:    entity.OAuthProviderGoogle,
+		ProviderUID: info.Sub,
+		NickName:    info.Name,
+		Avatar:      info.Picture,
+		Email:       email,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseSize 第三方接口响应体上限
+const maxResponseSize = 1 << 20
+
+// statusError 第三方接口返回非 2xx 状态码，Body 保留原始响应便于各平台解析错误码
+type statusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("oauth provider returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// doJSON 发送请求并将 JSON 响应解析到 out
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("read %s response failed: %w", req.URL.Host, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{StatusCode: resp.StatusCode, Body: body}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode %s response failed: %w", req.URL.Host, err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package oauth
+
+import (
+	"forge/biz/adapter"
+	"forge/infra/configs"
+)
+
+// NewProviders 按配置创建已开启的第三方登录平台
+func NewProviders(cfg configs.OAuthConfig) []adapter.OAuthProvider {
+	var providers []adapter.OAuthProvider
+	if cfg.WeChat.Enable {
+		providers = append(providers, NewWeChatProvider(cfg.WeChat))
+	}
+	if cfg.GitHub.Enable {
+		providers = append(providers, NewGitHubProvider(cfg.GitHub))
+	}
+	if cfg.Google.Enable {
+		providers = append(providers, NewGoogleProvider(cfg.Google))
+	}
+	return providers
+}
@@ -0,0 +1,145 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	wechatAuthorizeURL = "https://open.weixin.qq.com/connect/qrconnect"
+	wechatTokenURL     = "https://api.weixin.qq.com/sns/oauth2/access_token"
+	wechatUserInfoURL  = "https://api.weixin.qq.com/sns/userinfo"
+	wechatReqTimeout   = 10 * time.Second
+
+	// 授权码无效、已使用或已过期
+	wechatErrCodeInvalidCode = 40029
+	wechatErrCodeCodeUsed    = 40163
+)
+
+// wechatProvider 微信开放平台网站应用扫码登录
+type wechatProvider struct {
+	appID       string
+	appSecret   string
+	redirectURL string
+	client      *http.Client
+}
+
+// NewWeChatProvider 创建微信登录
+func NewWeChatProvider(cfg configs.OAuthProviderConfig) adapter.OAuthProvider {
+	return &wechatProvider{
+		appID:       cfg.ClientID,
+		appSecret:   cfg.ClientSecret,
+		redirectURL: cfg.RedirectURL,
+		client:      httpclient.New(wechatReqTimeout),
+	}
+}
+
+func (w *wechatProvider) Name() string {
+	return entity.OAuthProviderWeChat
+}
+
+func (w *wechatProvider) AuthURL(state string) string {
+	query := url.Values{}
+	query.Set("appid", w.appID)
+	query.Set("redirect_uri", w.redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", "snsapi_login")
+	query.Set("state", state)
+	return wechatAuthorizeURL + "?" + query.Encode() + "#wechat_redirect"
+}
+
+// wechatError 微信接口出错时返回 errcode/errmsg，HTTP 状态码仍为 200
+type wechatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+type wechatTokenResp struct {
+	wechatError
+	AccessToken string `json:"access_token"`
+	OpenID      string `json:"openid"`
+	UnionID     string `json:"unionid"`
+}
+
+type wechatUserInfoResp struct {
+	wechatError
+	OpenID     string `json:"openid"`
+	UnionID    string `json:"unionid"`
+	NickName   string `json:"nickname"`
+	HeadImgURL string `json:"headimgurl"`
+}
+
+func (w *wechatProvider) Exchange(ctx context.Context, code string) (*adapter.OAuthToken, error) {
+	query := url.Values{}
+	query.Set("appid", w.appID)
+	query.Set("secret", w.appSecret)
+	query.Set("code", code)
+	query.Set("grant_type", "authorization_code")
+
+	var token wechatTokenResp
+	if err := w.get(ctx, wechatTokenURL+"?"+query.Encode(), &token); err != nil {
+		return nil, err
+	}
+	if token.ErrCode != 0 {
+		zlog.CtxWarnf(ctx, "wechat exchange code failed: %d %s", token.ErrCode, token.ErrMsg)
+		if token.ErrCode == wechatErrCodeInvalidCode || token.ErrCode == wechatErrCodeCodeUsed {
+			return nil, adapter.ErrOAuthCodeInvalid
+		}
+		return nil, fmt.Errorf("wechat exchange code failed: %d %s", token.ErrCode, token.ErrMsg)
+	}
+
+	return &adapter.OAuthToken{
+		AccessToken: token.AccessToken,
+		OpenID:      token.OpenID,
+		UnionID:     token.UnionID,
+	}, nil
+}
+
+func (w *wechatProvider) GetUserInfo(ctx context.Context, token *adapter.OAuthToken) (*adapter.OAuthUser, error) {
+	query := url.Values{}
+	query.Set("access_token", token.AccessToken)
+	query.Set("openid", token.OpenID)
+
+	var info wechatUserInfoResp
+	if err := w.get(ctx, wechatUserInfoURL+"?"+query.Encode(), &info); err != nil {
+		return nil, err
+	}
+	if info.ErrCode != 0 {
+		return nil, fmt.Errorf("wechat get user info failed: %d %s", info.ErrCode, info.ErrMsg)
+	}
+
+	// 同一开放平台下的多个应用 unionid 相同，优先使用 unionid 关联用户
+	unionID := info.UnionID
+	if unionID == "" {
+		unionID = token.UnionID
+	}
+	providerUID := unionID
+	if providerUID == "" {
+		providerUID = token.OpenID
+	}
+
+	return &adapter.OAuthUser{
+		Provider:    entity.OAuthProviderWeChat,
+		ProviderUID: providerUID,
+		OpenID:      token.OpenID,
+		NickName:    info.NickName,
+		Avatar:      info.HeadImgURL,
+	}, nil
+}
+
+func (w *wechatProvider) get(ctx context.Context, reqURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("create wechat request failed: %w", err)
+	}
+	return doJSON(w.client, req, out)
+}
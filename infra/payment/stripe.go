@@ -0,0 +1,215 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/infra/configs"
+	"forge/infra/httpclient"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	stripeProvider         = "stripe"
+	stripeAPIBase          = "https://api.stripe.com/v1"
+	stripeReqTimeout       = 10 * time.Second
+	stripeSignatureTimeout = 5 * time.Minute // 回调时间戳允许的最大偏差，防止重放
+)
+
+type stripeServiceImpl struct {
+	secretKey     string
+	webhookSecret string
+	client        *http.Client
+}
+
+// NewStripeService 创建 Stripe 支付服务，直接调用 Stripe REST API
+func NewStripeService(cfg configs.BillingConfig) adapter.PaymentService {
+	return &stripeServiceImpl{
+		secretKey:     cfg.SecretKey,
+		webhookSecret: cfg.WebhookSecret,
+		client:        httpclient.New(stripeReqTimeout),
+	}
+}
+
+func (s *stripeServiceImpl) Provider() string {
+	return stripeProvider
+}
+
+// stripeCheckoutSession Stripe Checkout Session 中用到的字段
+type stripeCheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	ExpiresAt         int64             `json:"expires_at"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	PaymentStatus     string            `json:"payment_status"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object stripeCheckoutSession `json:"object"`
+	} `json:"data"`
+}
+
+type stripeError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// CreateCheckoutSession 创建一次性支付的 Checkout Session
+func (s *stripeServiceImpl) CreateCheckoutSession(ctx context.Context, req *adapter.CheckoutReq) (*adapter.CheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", req.SuccessURL)
+	form.Set("cancel_url", req.CancelURL)
+	form.Set("client_reference_id", req.OrderID)
+	form.Set("metadata[order_id]", req.OrderID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(req.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(req.Amount, 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.ProductName)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIBase+"/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("build stripe request failed: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Authorization", "Bearer "+s.secretKey)
+	// 同一订单重复请求时 Stripe 返回同一个会话
+	httpReq.Header.Set("Idempotency-Key", req.OrderID)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request stripe failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read stripe response failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var stripeErr stripeError
+		_ = json.Unmarshal(body, &stripeErr)
+		zlog.CtxErrorf(ctx, "stripe create checkout session failed, status: %d, type: %s, message: %s", resp.StatusCode, stripeErr.Error.Type, stripeErr.Error.Message)
+		return nil, fmt.Errorf("stripe create checkout session failed: status %d", resp.StatusCode)
+	}
+
+	var session stripeCheckoutSession
+	if err := json.Unmarshal(body, &session); err != nil {
+		return nil, fmt.Errorf("decode stripe response failed: %w", err)
+	}
+
+	return &adapter.CheckoutSession{
+		SessionID: session.ID,
+		URL:       session.URL,
+		ExpiresAt: time.Unix(session.ExpiresAt, 0),
+	}, nil
+}
+
+// ParseWebhookEvent 校验 Stripe-Signature 并解析事件
+func (s *stripeServiceImpl) ParseWebhookEvent(ctx context.Context, payload []byte, signature string) (*adapter.PaymentEvent, error) {
+	if err := s.verifySignature(payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decode stripe event failed: %w", err)
+	}
+
+	session := event.Data.Object
+	orderID := session.ClientReferenceID
+	if orderID == "" {
+		orderID = session.Metadata["order_id"]
+	}
+
+	paymentEvent := &adapter.PaymentEvent{
+		EventID:   event.ID,
+		OrderID:   orderID,
+		SessionID: session.ID,
+	}
+
+	switch event.Type {
+	case "checkout.session.completed":
+		// 异步支付方式完成会话时可能尚未到账，等待 async_payment_succeeded
+		if session.PaymentStatus == "paid" {
+			paymentEvent.Type = adapter.PaymentEventPaid
+		}
+	case "checkout.session.async_payment_succeeded":
+		paymentEvent.Type = adapter.PaymentEventPaid
+	case "checkout.session.async_payment_failed":
+		paymentEvent.Type = adapter.PaymentEventFailed
+	case "checkout.session.expired":
+		paymentEvent.Type = adapter.PaymentEventExpired
+	default:
+		zlog.CtxInfof(ctx, "ignore stripe event, id: %s, type: %s", event.ID, event.Type)
+	}
+
+	return paymentEvent, nil
+}
+
+// verifySignature 校验签名，格式：t=时间戳,v1=签名[,v1=签名]
+// 签名为 HMAC-SHA256(webhook_secret, "时间戳.请求体")
+func (s *stripeServiceImpl) verifySignature(payload []byte, header string, now time.Time) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return adapter.ErrInvalidPaymentSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return adapter.ErrInvalidPaymentSignature
+	}
+	if diff := now.Sub(time.Unix(ts, 0)); diff > stripeSignatureTimeout || diff < -stripeSignatureTimeout {
+		return adapter.ErrInvalidPaymentSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.webhookSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return adapter.ErrInvalidPaymentSignature
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type aiUsagePersistence struct {
+	db *gorm.DB
+}
+
+var aup *aiUsagePersistence
+
+func InitAiUsageStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移AI用量表
+	if err := database.AutoMigrate(&po.AiUsagePO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate ai usage table: %v", err))
+	}
+
+	aup = &aiUsagePersistence{
+		db: db,
+	}
+}
+
+func GetAiUsagePersistence() repo.AiUsageRepo {
+	return aup
+}
+
+// CreateUsageRecord 记录一次AI调用的用量
+func (a *aiUsagePersistence) CreateUsageRecord(ctx context.Context, record *entity.AiUsageRecord) error {
+	if err := database.Conn(ctx, a.db).Create(CastAiUsageDO2PO(record)).Error; err != nil {
+		return fmt.Errorf("create ai usage record failed: %w", err)
+	}
+	return nil
+}
+
+// SumUsage 统计用户在 since 之后的用量
+func (a *aiUsagePersistence) SumUsage(ctx context.Context, userID string, since time.Time) (entity.TokenUsage, error) {
+	var result struct {
+		PromptTokens     int64
+		CompletionTokens int64
+	}
+	err := database.Conn(ctx, a.db).Model(&po.AiUsagePO{}).
+		Select("COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&result).Error
+	if err != nil {
+		return entity.TokenUsage{}, fmt.Errorf("sum ai usage failed: %w", err)
+	}
+	return entity.TokenUsage{PromptTokens: result.PromptTokens, CompletionTokens: result.CompletionTokens}, nil
+}
+
+func (a *aiUsagePersistence) DeleteUserUsage(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, a.db).Where("user_id = ?", userID).Delete(&po.AiUsagePO{}).Error; err != nil {
+		return fmt.Errorf("delete ai usage records failed: %w", err)
+	}
+	return nil
+}
@@ -10,6 +10,8 @@ import (
 	"forge/infra/database"
 	"forge/infra/storage/po"
 	"gorm.io/gorm"
+	"strings"
+	"time"
 )
 
 type aiChatPersistence struct {
@@ -21,8 +23,8 @@ var cp *aiChatPersistence
 func InitAiChatStorage() {
 	db := database.ForgeDB()
 
-	if err := db.AutoMigrate(&po.ConversationPO{}); err != nil {
-		panic(fmt.Sprintf("自动建表失败 :%w", err))
+	if err := database.AutoMigrate(&po.ConversationPO{}); err != nil {
+		panic(fmt.Sprintf("自动建表失败 :%v", err))
 	}
 
 	cp = &aiChatPersistence{db: db}
@@ -38,7 +40,7 @@ func (a *aiChatPersistence) GetConversation(ctx context.Context, conversationID,
 	}
 
 	var conversationPO po.ConversationPO
-	if err := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&conversationPO).Error; err != nil {
+	if err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationID, userID).First(&conversationPO).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, aichatservice.CONVERSATION_NOT_EXIST
 		}
@@ -64,8 +66,11 @@ func (a *aiChatPersistence) GetMapAllConversation(ctx context.Context, mapID, us
 		return nil, aichatservice.MIND_MAP_NOT_EXIST
 	}
 
+	// 会话列表优先发往从库
 	var conversationPOs []po.ConversationPO
-	if err := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("map_id = ? AND user_id = ?", mapID, userID).Find(&conversationPOs).Error; err != nil {
+	query := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Where("map_id = ? AND user_id = ? AND is_deleted = 0", mapID, userID).
+		Order("is_pinned DESC").Order("sort_order ASC").Order("updated_at DESC")
+	if err := database.Read(ctx, query, func(db *gorm.DB) error { return db.Find(&conversationPOs).Error }); err != nil {
 		return nil, fmt.Errorf("获取导图会话时 数据库出错 %w", err)
 	}
 
@@ -95,7 +100,7 @@ func (a *aiChatPersistence) SaveConversation(ctx context.Context, conversation *
 	if err != nil {
 		return err
 	}
-	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Create(&conversationPO).Error
+	err = database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Create(&conversationPO).Error
 	if err != nil {
 		return fmt.Errorf("保存会话时，数据库出错 %w", err)
 	}
@@ -127,7 +132,7 @@ func (a *aiChatPersistence) UpdateConversationMessage(ctx context.Context, conve
 		Updates["messages"] = conversationPO.Messages
 	}
 
-	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
+	err = database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
 	if err != nil {
 		return fmt.Errorf("更新会话时 数据库出错 %w", err)
 	}
@@ -159,13 +164,138 @@ func (a *aiChatPersistence) UpdateConversationTitle(ctx context.Context, convers
 		Updates["title"] = conversationPO.Title
 	}
 
-	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
+	err = database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
 	if err != nil {
 		return fmt.Errorf("更新会话时 数据库出错 %w", err)
 	}
 	return nil
 }
 
+func (a *aiChatPersistence) UpdateConversationPreset(ctx context.Context, conversation *entity.Conversation) error {
+	if conversation.ConversationID == "" {
+		return aichatservice.CONVERSATION_ID_NOT_NULL
+	} else if conversation.UserID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	}
+
+	// 预设ID为空表示恢复默认，需要写入空值；会话是否存在由调用方先行确认
+	result := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversation.ConversationID, conversation.UserID).
+		Update("preset_id", conversation.PresetID)
+	if result.Error != nil {
+		return fmt.Errorf("更新会话时 数据库出错 %w", result.Error)
+	}
+	return nil
+}
+
+func (a *aiChatPersistence) SetConversationArchived(ctx context.Context, conversationID, userID string, archived bool) error {
+	if conversationID == "" {
+		return aichatservice.CONVERSATION_ID_NOT_NULL
+	} else if userID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	}
+
+	updates := map[string]interface{}{"is_archived": 0, "archived_at": nil}
+	if archived {
+		now := time.Now()
+		updates = map[string]interface{}{"is_archived": 1, "archived_at": &now}
+	}
+	// 会话是否存在由调用方先行确认
+	err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationID, userID).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("归档会话时 数据库出错 %w", err)
+	}
+	return nil
+}
+
+func (a *aiChatPersistence) SetConversationPinned(ctx context.Context, conversationID, userID string, pinned bool) error {
+	if conversationID == "" {
+		return aichatservice.CONVERSATION_ID_NOT_NULL
+	} else if userID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	}
+
+	var isPinned int8
+	if pinned {
+		isPinned = 1
+	}
+	// 只修改置顶标记，不更新 updated_at，避免影响未调整顺序的会话的排列；会话是否存在由调用方先行确认
+	err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationID, userID).
+		UpdateColumn("is_pinned", isPinned).Error
+	if err != nil {
+		return fmt.Errorf("置顶会话时 数据库出错 %w", err)
+	}
+	return nil
+}
+
+func (a *aiChatPersistence) UpdateConversationOrder(ctx context.Context, userID, mapID string, conversationIDs []string) error {
+	if userID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	} else if mapID == "" {
+		return aichatservice.MAP_ID_NOT_NULL
+	}
+
+	// 顺序从 1 开始，未调整过的会话保持 0，排在调整过的会话之前
+	return database.Conn(ctx, a.db).Transaction(func(tx *gorm.DB) error {
+		for i, conversationID := range conversationIDs {
+			err := tx.Model(&po.ConversationPO{}).
+				Where("conversation_id = ? AND user_id = ? AND map_id = ? AND is_deleted = 0", conversationID, userID, mapID).
+				UpdateColumn("sort_order", i+1).Error
+			if err != nil {
+				return fmt.Errorf("调整会话顺序时 数据库出错 %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// conversationMessageMatchSQL 聊天内容中是否有用户或ai的消息包含关键词，系统提示词与工具结果中带有导图数据，不参与搜索
+// JSON 中的字符串按二进制排序规则比较，统一转小写后匹配
+const conversationMessageMatchSQL = `EXISTS (SELECT 1 FROM JSON_TABLE(messages, '$[*]' COLUMNS(
+	role VARCHAR(16) PATH '$.role',
+	content LONGTEXT PATH '$.content')) AS m
+	WHERE m.role IN ('user', 'assistant') AND LOWER(m.content) LIKE ?)`
+
+func (a *aiChatPersistence) SearchConversations(ctx context.Context, query repo.ConversationSearchQuery) ([]*entity.Conversation, int64, error) {
+	if query.UserID == "" {
+		return nil, 0, aichatservice.USER_ID_NOT_NULL
+	}
+
+	pattern := "%" + escapeLike(strings.ToLower(query.Keyword)) + "%"
+	db := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("user_id = ? AND is_deleted = 0", query.UserID).
+		Where("(LOWER(title) LIKE ? OR "+conversationMessageMatchSQL+")", pattern, pattern)
+	if query.Archived != nil {
+		if *query.Archived {
+			db = db.Where("is_archived = 1")
+		} else {
+			db = db.Where("is_archived = 0")
+		}
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("搜索会话时 数据库出错 %w", err)
+	}
+
+	var conversationPOs []po.ConversationPO
+	err := db.Order("updated_at DESC").Order("id DESC").
+		Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).
+		Find(&conversationPOs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("搜索会话时 数据库出错 %w", err)
+	}
+
+	conversations, err := CastConversationPOs2DOs(conversationPOs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return conversations, total, nil
+}
+
 func (a *aiChatPersistence) DeleteConversation(ctx context.Context, conversationID, userID string) error {
 	if conversationID == "" {
 		return aichatservice.CONVERSATION_ID_NOT_NULL
@@ -173,19 +303,84 @@ func (a *aiChatPersistence) DeleteConversation(ctx context.Context, conversation
 		return aichatservice.USER_ID_NOT_NULL
 	}
 
-	result := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationID, userID).Delete(&po.ConversationPO{})
+	// 软删除：会话与其聊天记录保存在同一行，标记删除后在恢复期限内仍可恢复
+	now := time.Now()
+	result := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("conversation_id = ? AND user_id = ? AND is_deleted = 0", conversationID, userID).
+		Updates(map[string]interface{}{"is_deleted": 1, "deleted_at": &now})
+	if result.Error != nil {
+		return fmt.Errorf("删除会话时出错 %w", result.Error)
+	}
 	if result.RowsAffected == 0 {
 		return aichatservice.CONVERSATION_NOT_EXIST
 	}
-	if result.Error != nil {
-		return fmt.Errorf("删除会话时出错 %w", result.Error)
+	return nil
+}
+
+func (a *aiChatPersistence) RestoreConversation(ctx context.Context, conversationID, userID string, deletedAfter time.Time) error {
+	if conversationID == "" {
+		return aichatservice.CONVERSATION_ID_NOT_NULL
+	} else if userID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	}
+
+	var conversationPO po.ConversationPO
+	err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ? AND is_deleted = 1", conversationID, userID).First(&conversationPO).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return aichatservice.CONVERSATION_NOT_EXIST
+	} else if err != nil {
+		return fmt.Errorf("恢复会话时 数据库出错 %w", err)
+	}
+
+	if conversationPO.DeletedAt == nil || conversationPO.DeletedAt.Before(deletedAfter) {
+		return aichatservice.CONVERSATION_RESTORE_EXPIRED
+	}
+
+	err = database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("conversation_id = ? AND user_id = ? AND is_deleted = 1", conversationID, userID).
+		Updates(map[string]interface{}{"is_deleted": 0, "deleted_at": nil}).Error
+	if err != nil {
+		return fmt.Errorf("恢复会话时 数据库出错 %w", err)
+	}
+	return nil
+}
+
+func (a *aiChatPersistence) ListDeletedConversationIDs(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	var conversationIDs []string
+	err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).
+		Where("is_deleted = 1 AND deleted_at < ?", deletedBefore).
+		Pluck("conversation_id", &conversationIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询待清理会话时 数据库出错 %w", err)
+	}
+	return conversationIDs, nil
+}
+
+func (a *aiChatPersistence) PurgeConversations(ctx context.Context, conversationIDs []string) error {
+	if len(conversationIDs) == 0 {
+		return nil
+	}
+	//只删除仍处于软删除状态的会话
+	err := database.Conn(ctx, a.db).Where("conversation_id IN ? AND is_deleted = 1", conversationIDs).Delete(&po.ConversationPO{}).Error
+	if err != nil {
+		return fmt.Errorf("清理已删除会话时 数据库出错 %w", err)
+	}
+	return nil
+}
+
+func (a *aiChatPersistence) EraseUserConversations(ctx context.Context, userID string) error {
+	if userID == "" {
+		return aichatservice.USER_ID_NOT_NULL
+	}
+	if err := database.Conn(ctx, a.db).Where("user_id = ?", userID).Delete(&po.ConversationPO{}).Error; err != nil {
+		return fmt.Errorf("清理用户会话时 数据库出错 %w", err)
 	}
 	return nil
 }
 
 func checkMapIsExist(ctx context.Context, a *aiChatPersistence, checkMapID string) (bool, error) {
 	var id uint64
-	err := a.db.WithContext(ctx).Model(&po.MindMapPO{}).Select("id").Where("map_id = ?", checkMapID).Take(&id).Error
+	err := database.Conn(ctx, a.db).Model(&po.MindMapPO{}).Select("id").Where("map_id = ?", checkMapID).Take(&id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return false, nil
 	} else if err != nil {
@@ -197,7 +392,7 @@ func checkMapIsExist(ctx context.Context, a *aiChatPersistence, checkMapID strin
 
 func checkConversationIsExist(ctx context.Context, a *aiChatPersistence, checkConversationID string) (bool, error) {
 	var id uint64
-	err := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Select("id").Where("conversation_id = ?", checkConversationID).Take(&id).Error
+	err := database.Conn(ctx, a.db).Model(&po.ConversationPO{}).Select("id").Where("conversation_id = ? AND is_deleted = 0", checkConversationID).Take(&id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return false, nil
 	} else if err != nil {
@@ -206,3 +401,8 @@ func checkConversationIsExist(ctx context.Context, a *aiChatPersistence, checkCo
 		return true, nil
 	}
 }
+
+// escapeLike 转义 LIKE 中的通配符，关键词按字面匹配
+func escapeLike(keyword string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(keyword)
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type auditLogPersistence struct {
+	db *gorm.DB
+}
+
+var alp *auditLogPersistence
+
+func InitAuditLogStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移安全事件表
+	if err := database.AutoMigrate(&po.AuditLogPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate audit log table: %v", err))
+	}
+
+	alp = &auditLogPersistence{
+		db: db,
+	}
+}
+
+func GetAuditLogPersistence() repo.AuditLogRepo {
+	return alp
+}
+
+// CreateAuditLog 记录安全事件
+func (a *auditLogPersistence) CreateAuditLog(ctx context.Context, log *entity.AuditLog) error {
+	if err := database.Conn(ctx, a.db).Create(CastAuditLogDO2PO(log)).Error; err != nil {
+		return fmt.Errorf("create audit log failed: %w", err)
+	}
+	return nil
+}
+
+// ListAuditLogs 分页查询安全事件
+func (a *auditLogPersistence) ListAuditLogs(ctx context.Context, query repo.AuditLogQuery) ([]*entity.AuditLog, int64, error) {
+	var logPOs []*po.AuditLogPO
+	var total int64
+
+	db := database.Conn(ctx, a.db).Model(&po.AuditLogPO{})
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.Result != "" {
+		db = db.Where("result = ?", query.Result)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count audit logs failed: %w", err)
+	}
+
+	db = db.Order("id DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	}
+	if err := db.Find(&logPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list audit logs failed: %w", err)
+	}
+
+	logs := make([]*entity.AuditLog, 0, len(logPOs))
+	for _, logPO := range logPOs {
+		logs = append(logs, CastAuditLogPO2DO(logPO))
+	}
+	return logs, total, nil
+}
+
+func (a *auditLogPersistence) DeleteUserAuditLogs(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, a.db).Where("user_id = ?", userID).Delete(&po.AuditLogPO{}).Error; err != nil {
+		return fmt.Errorf("delete audit logs failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+
+	"gorm.io/gorm"
+)
+
+type backupPersistence struct {
+	db *gorm.DB
+}
+
+var bp *backupPersistence
+
+func InitBackupStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移备份记录表
+	if err := database.AutoMigrate(&po.BackupPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate backup table: %v", err))
+	}
+
+	bp = &backupPersistence{
+		db: db,
+	}
+}
+
+func GetBackupPersistence() repo.BackupRepo {
+	return bp
+}
+
+// CreateBackup 记录备份
+func (b *backupPersistence) CreateBackup(ctx context.Context, backup *entity.Backup) error {
+	backupPO := CastBackupDO2PO(backup)
+	if err := database.Conn(ctx, b.db).Create(backupPO).Error; err != nil {
+		return fmt.Errorf("create backup failed: %w", err)
+	}
+	return nil
+}
+
+// GetBackup 获取备份记录
+func (b *backupPersistence) GetBackup(ctx context.Context, backupID string) (*entity.Backup, error) {
+	var backupPO po.BackupPO
+	err := database.Conn(ctx, b.db).Where("backup_id = ?", backupID).First(&backupPO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get backup failed: %w", err)
+	}
+	return CastBackupPO2DO(&backupPO), nil
+}
+
+// ListBackups 获取用户的备份记录
+func (b *backupPersistence) ListBackups(ctx context.Context, userID string) ([]*entity.Backup, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("invalid backup query: user id is required")
+	}
+	var backupPOs []po.BackupPO
+	if err := database.Conn(ctx, b.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&backupPOs).Error; err != nil {
+		return nil, fmt.Errorf("list backups failed: %w", err)
+	}
+	return CastBackupPOs2DOs(backupPOs), nil
+}
+
+// ListBackupsBefore 获取过期的备份记录
+func (b *backupPersistence) ListBackupsBefore(ctx context.Context, before time.Time, limit int) ([]*entity.Backup, error) {
+	var backupPOs []po.BackupPO
+	if err := database.Conn(ctx, b.db).Where("created_at < ?", before).Order("created_at ASC").Limit(limit).Find(&backupPOs).Error; err != nil {
+		return nil, fmt.Errorf("list expired backups failed: %w", err)
+	}
+	return CastBackupPOs2DOs(backupPOs), nil
+}
+
+// DeleteBackups 删除备份记录
+func (b *backupPersistence) DeleteBackups(ctx context.Context, backupIDs []string) error {
+	if len(backupIDs) == 0 {
+		return nil
+	}
+	result := database.Conn(ctx, b.db).Where("backup_id IN ?", backupIDs).Delete(&po.BackupPO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete backups failed: %w", result.Error)
+	}
+	zlog.CtxInfof(ctx, "deleted %d backup records", result.RowsAffected)
+	return nil
+}
@@ -18,16 +18,30 @@ func CastUserDO2PO(user *entity.User) *po.UserPO {
 		return nil
 	}
 	return &po.UserPO{
-		UserID:        user.UserID,
-		UserName:      user.UserName,
-		Avatar:        user.Avatar,
-		Password:      user.Password,
-		Phone:         user.Phone,
-		Email:         user.Email,
-		Status:        user.Status,
-		PhoneVerified: user.PhoneVerified,
-		EmailVerified: user.EmailVerified,
-		LastLoginAt:   user.LastLoginAt,
+		UserID:         user.UserID,
+		UserName:       user.UserName,
+		Avatar:         user.Avatar,
+		Nickname:       user.Nickname,
+		Bio:            user.Bio,
+		Gender:         user.Gender,
+		Birthday:       user.Birthday,
+		Location:       user.Location,
+		Password:       user.Password,
+		Phone:          user.Phone,
+		Email:          user.Email,
+		Status:         user.Status,
+		SuspendReason:  user.SuspendReason,
+		SuspendedUntil: user.SuspendedUntil,
+		Role:           user.Role,
+		PhoneVerified:  user.PhoneVerified,
+		EmailVerified:  user.EmailVerified,
+		Plan:           user.Plan,
+		PlanExpiresAt:  user.PlanExpiresAt,
+		LastLoginAt:    user.LastLoginAt,
+		ReminderOptOut: user.ReminderOptOut,
+		ReminderSentAt: user.ReminderSentAt,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
 	}
 }
 
@@ -37,16 +51,31 @@ func CastUserPO2DO(userPO *po.UserPO) *entity.User {
 		return nil
 	}
 	user := &entity.User{
-		UserID:        userPO.UserID,
-		UserName:      userPO.UserName,
-		Avatar:        userPO.Avatar,
-		Password:      userPO.Password,
-		Phone:         userPO.Phone,
-		Email:         userPO.Email,
-		Status:        userPO.Status,
-		PhoneVerified: userPO.PhoneVerified,
-		EmailVerified: userPO.EmailVerified,
-		LastLoginAt:   userPO.LastLoginAt,
+		UserID:         userPO.UserID,
+		UserName:       userPO.UserName,
+		Avatar:         userPO.Avatar,
+		Nickname:       userPO.Nickname,
+		Bio:            userPO.Bio,
+		Gender:         userPO.Gender,
+		Birthday:       userPO.Birthday,
+		Location:       userPO.Location,
+		Password:       userPO.Password,
+		PasswordSet:    userPO.Password != "",
+		Phone:          userPO.Phone,
+		Email:          userPO.Email,
+		Status:         userPO.Status,
+		SuspendReason:  userPO.SuspendReason,
+		SuspendedUntil: userPO.SuspendedUntil,
+		Role:           userPO.Role,
+		PhoneVerified:  userPO.PhoneVerified,
+		EmailVerified:  userPO.EmailVerified,
+		Plan:           userPO.Plan,
+		PlanExpiresAt:  userPO.PlanExpiresAt,
+		LastLoginAt:    userPO.LastLoginAt,
+		ReminderOptOut: userPO.ReminderOptOut,
+		ReminderSentAt: userPO.ReminderSentAt,
+
+		DeletionScheduledAt: userPO.DeletionScheduledAt,
 	}
 
 	// 处理时间字段：如果 PO 中为 nil，Entity 中保持零值；否则解引用
@@ -72,13 +101,16 @@ func CastMindMapDO2PO(mindmap *entity.MindMap) (*po.MindMapPO, error) {
 		return nil, fmt.Errorf("failed to marshal mindmap data: %w", err)
 	}
 
+	content := mindmap.Data.SearchContent()
 	mindmapPO := &po.MindMapPO{
-		MapID:  mindmap.MapID,
-		UserID: mindmap.UserID,
-		Title:  mindmap.Title,
-		Desc:   mindmap.Desc,
-		Data:   string(dataBytes),
-		Layout: mindmap.Layout,
+		MapID:    mindmap.MapID,
+		UserID:   mindmap.UserID,
+		Title:    mindmap.Title,
+		Desc:     mindmap.Desc,
+		Data:     string(dataBytes),
+		Layout:   mindmap.Layout,
+		FolderID: mindmap.FolderID,
+		Content:  &content,
 	}
 
 	// 处理时间字段
@@ -105,12 +137,13 @@ func CastMindMapPO2DO(mindmapPO *po.MindMapPO) (*entity.MindMap, error) {
 	}
 
 	mindmap := &entity.MindMap{
-		MapID:  mindmapPO.MapID,
-		UserID: mindmapPO.UserID,
-		Title:  mindmapPO.Title,
-		Desc:   mindmapPO.Desc,
-		Data:   data,
-		Layout: mindmapPO.Layout,
+		MapID:    mindmapPO.MapID,
+		UserID:   mindmapPO.UserID,
+		Title:    mindmapPO.Title,
+		Desc:     mindmapPO.Desc,
+		Data:     data,
+		Layout:   mindmapPO.Layout,
+		FolderID: mindmapPO.FolderID,
 	}
 
 	// 处理时间字段
@@ -134,15 +167,24 @@ func CastConversationPO2DO(conversationPO *po.ConversationPO) (*entity.Conversat
 		return nil, fmt.Errorf("反序列化失败: %w", err)
 	}
 
-	return &entity.Conversation{
+	conversation := &entity.Conversation{
 		ConversationID: conversationPO.ConversationID,
 		UserID:         conversationPO.UserID,
 		MapID:          conversationPO.MapID,
 		Title:          conversationPO.Title,
+		PresetID:       conversationPO.PresetID,
 		Messages:       messages,
+		Archived:       conversationPO.IsArchived == 1,
+		ArchivedAt:     conversationPO.ArchivedAt,
+		Pinned:         conversationPO.IsPinned == 1,
+		SortOrder:      conversationPO.SortOrder,
 		CreatedAt:      conversationPO.CreatedAt,
 		UpdatedAt:      conversationPO.UpdatedAt,
-	}, nil
+		DeletedAt:      conversationPO.DeletedAt,
+	}
+	// 早期保存的消息没有编号
+	conversation.FillMessageIDs()
+	return conversation, nil
 
 }
 
@@ -178,10 +220,775 @@ func CastConversationDO2PO(conversation *entity.Conversation) (*po.ConversationP
 		UserID:         conversation.UserID,
 		MapID:          conversation.MapID,
 		Title:          conversation.Title,
+		PresetID:       conversation.PresetID,
 		Messages:       datatypes.JSON(jsonBytes),
+		ArchivedAt:     conversation.ArchivedAt,
+		SortOrder:      conversation.SortOrder,
 		CreatedAt:      conversation.CreatedAt,
 		UpdatedAt:      conversation.UpdatedAt,
+		DeletedAt:      conversation.DeletedAt,
+	}
+	if conversation.Archived {
+		conversationPO.IsArchived = 1
+	}
+	if conversation.Pinned {
+		conversationPO.IsPinned = 1
 	}
 	return conversationPO, nil
 
 }
+
+// CastFileDO2PO 文件实体转持久化对象
+func CastFileDO2PO(file *entity.File) *po.FilePO {
+	if file == nil {
+		return nil
+	}
+	filePO := &po.FilePO{
+		FileID:         file.FileID,
+		UserID:         file.UserID,
+		ResourcePath:   file.ResourcePath,
+		URL:            file.URL,
+		Filename:       file.Filename,
+		ContentType:    file.ContentType,
+		Size:           file.Size,
+		Hash:           file.Hash,
+		Purpose:        file.Purpose,
+		ConversationID: file.ConversationID,
+		Status:         file.Status,
+		ScanResult:     file.ScanResult,
+	}
+	if !file.CreatedAt.IsZero() {
+		filePO.CreatedAt = &file.CreatedAt
+	}
+	return filePO
+}
+
+// CastFilePO2DO 文件持久化对象转实体
+func CastFilePO2DO(filePO *po.FilePO) *entity.File {
+	if filePO == nil {
+		return nil
+	}
+	file := &entity.File{
+		FileID:         filePO.FileID,
+		UserID:         filePO.UserID,
+		ResourcePath:   filePO.ResourcePath,
+		URL:            filePO.URL,
+		Filename:       filePO.Filename,
+		ContentType:    filePO.ContentType,
+		Size:           filePO.Size,
+		Hash:           filePO.Hash,
+		Purpose:        filePO.Purpose,
+		ConversationID: filePO.ConversationID,
+		Status:         filePO.Status,
+		ScanResult:     filePO.ScanResult,
+	}
+	if filePO.CreatedAt != nil {
+		file.CreatedAt = *filePO.CreatedAt
+	}
+	return file
+}
+
+// CastInvoiceDO2PO 账单实体转持久化对象
+func CastInvoiceDO2PO(invoice *entity.Invoice) *po.InvoicePO {
+	if invoice == nil {
+		return nil
+	}
+	return &po.InvoicePO{
+		InvoiceID:         invoice.InvoiceID,
+		UserID:            invoice.UserID,
+		Product:           invoice.Product,
+		Plan:              invoice.Plan,
+		PeriodDays:        invoice.PeriodDays,
+		Amount:            invoice.Amount,
+		Currency:          invoice.Currency,
+		Status:            invoice.Status,
+		Provider:          invoice.Provider,
+		ProviderSessionID: invoice.ProviderSessionID,
+		CheckoutURL:       invoice.CheckoutURL,
+		PaidAt:            invoice.PaidAt,
+	}
+}
+
+// CastInvoicePO2DO 账单持久化对象转实体
+func CastInvoicePO2DO(invoicePO *po.InvoicePO) *entity.Invoice {
+	if invoicePO == nil {
+		return nil
+	}
+	invoice := &entity.Invoice{
+		InvoiceID:         invoicePO.InvoiceID,
+		UserID:            invoicePO.UserID,
+		Product:           invoicePO.Product,
+		Plan:              invoicePO.Plan,
+		PeriodDays:        invoicePO.PeriodDays,
+		Amount:            invoicePO.Amount,
+		Currency:          invoicePO.Currency,
+		Status:            invoicePO.Status,
+		Provider:          invoicePO.Provider,
+		ProviderSessionID: invoicePO.ProviderSessionID,
+		CheckoutURL:       invoicePO.CheckoutURL,
+		PaidAt:            invoicePO.PaidAt,
+	}
+	if invoicePO.CreatedAt != nil {
+		invoice.CreatedAt = *invoicePO.CreatedAt
+	}
+	if invoicePO.UpdatedAt != nil {
+		invoice.UpdatedAt = *invoicePO.UpdatedAt
+	}
+	return invoice
+}
+
+// CastBackupDO2PO 备份实体转持久化对象
+func CastBackupDO2PO(backup *entity.Backup) *po.BackupPO {
+	if backup == nil {
+		return nil
+	}
+	backupPO := &po.BackupPO{
+		BackupID:          backup.BackupID,
+		UserID:            backup.UserID,
+		ResourcePath:      backup.ResourcePath,
+		Size:              backup.Size,
+		SHA256:            backup.SHA256,
+		MindMapCount:      backup.MindMapCount,
+		ConversationCount: backup.ConversationCount,
+	}
+	if !backup.CreatedAt.IsZero() {
+		backupPO.CreatedAt = &backup.CreatedAt
+	}
+	return backupPO
+}
+
+// CastBackupPO2DO 备份持久化对象转实体
+func CastBackupPO2DO(backupPO *po.BackupPO) *entity.Backup {
+	if backupPO == nil {
+		return nil
+	}
+	backup := &entity.Backup{
+		BackupID:          backupPO.BackupID,
+		UserID:            backupPO.UserID,
+		ResourcePath:      backupPO.ResourcePath,
+		Size:              backupPO.Size,
+		SHA256:            backupPO.SHA256,
+		MindMapCount:      backupPO.MindMapCount,
+		ConversationCount: backupPO.ConversationCount,
+	}
+	if backupPO.CreatedAt != nil {
+		backup.CreatedAt = *backupPO.CreatedAt
+	}
+	return backup
+}
+
+// CastBackupPOs2DOs 备份持久化对象列表转实体列表
+func CastBackupPOs2DOs(backupPOs []po.BackupPO) []*entity.Backup {
+	backups := make([]*entity.Backup, 0, len(backupPOs))
+	for i := range backupPOs {
+		backups = append(backups, CastBackupPO2DO(&backupPOs[i]))
+	}
+	return backups
+}
+
+// CastUserIdentityDO2PO 第三方账号绑定实体转存储
+func CastUserIdentityDO2PO(identity *entity.UserIdentity) *po.UserIdentityPO {
+	if identity == nil {
+		return nil
+	}
+	return &po.UserIdentityPO{
+		UserID:      identity.UserID,
+		Provider:    identity.Provider,
+		ProviderUID: identity.ProviderUID,
+		OpenID:      identity.OpenID,
+		NickName:    identity.NickName,
+		Avatar:      identity.Avatar,
+	}
+}
+
+// CastUserIdentityPO2DO 第三方账号绑定存储转实体
+func CastUserIdentityPO2DO(identityPO *po.UserIdentityPO) *entity.UserIdentity {
+	if identityPO == nil {
+		return nil
+	}
+	identity := &entity.UserIdentity{
+		UserID:      identityPO.UserID,
+		Provider:    identityPO.Provider,
+		ProviderUID: identityPO.ProviderUID,
+		OpenID:      identityPO.OpenID,
+		NickName:    identityPO.NickName,
+		Avatar:      identityPO.Avatar,
+	}
+	if identityPO.CreatedAt != nil {
+		identity.CreatedAt = *identityPO.CreatedAt
+	}
+	return identity
+}
+
+func CastUserIdentityPOs2DOs(identityPOs []*po.UserIdentityPO) []*entity.UserIdentity {
+	identities := make([]*entity.UserIdentity, 0, len(identityPOs))
+	for _, identityPO := range identityPOs {
+		identities = append(identities, CastUserIdentityPO2DO(identityPO))
+	}
+	return identities
+}
+
+// CastUserSessionDO2PO 登录会话实体转存储
+func CastUserSessionDO2PO(session *entity.UserSession) *po.UserSessionPO {
+	if session == nil {
+		return nil
+	}
+	return &po.UserSessionPO{
+		SessionID:      session.SessionID,
+		UserID:         session.UserID,
+		Device:         session.Device,
+		IP:             session.IP,
+		UserAgent:      session.UserAgent,
+		RefreshTokenID: session.RefreshTokenID,
+		CreatedAt:      session.CreatedAt,
+		LastActiveAt:   session.LastActiveAt,
+		ExpiresAt:      session.ExpiresAt,
+		RevokedAt:      session.RevokedAt,
+	}
+}
+
+// CastUserSessionPO2DO 登录会话存储转实体
+func CastUserSessionPO2DO(sessionPO *po.UserSessionPO) *entity.UserSession {
+	if sessionPO == nil {
+		return nil
+	}
+	return &entity.UserSession{
+		SessionID:      sessionPO.SessionID,
+		UserID:         sessionPO.UserID,
+		Device:         sessionPO.Device,
+		IP:             sessionPO.IP,
+		UserAgent:      sessionPO.UserAgent,
+		RefreshTokenID: sessionPO.RefreshTokenID,
+		CreatedAt:      sessionPO.CreatedAt,
+		LastActiveAt:   sessionPO.LastActiveAt,
+		ExpiresAt:      sessionPO.ExpiresAt,
+		RevokedAt:      sessionPO.RevokedAt,
+	}
+}
+
+// CastPasswordHistoryDO2PO 历史密码实体转存储
+func CastPasswordHistoryDO2PO(history *entity.PasswordHistory) *po.PasswordHistoryPO {
+	if history == nil {
+		return nil
+	}
+	return &po.PasswordHistoryPO{
+		UserID:       history.UserID,
+		PasswordHash: history.PasswordHash,
+		CreatedAt:    history.CreatedAt,
+	}
+}
+
+// CastPasswordHistoryPO2DO 历史密码存储转实体
+func CastPasswordHistoryPO2DO(historyPO *po.PasswordHistoryPO) *entity.PasswordHistory {
+	if historyPO == nil {
+		return nil
+	}
+	return &entity.PasswordHistory{
+		UserID:       historyPO.UserID,
+		PasswordHash: historyPO.PasswordHash,
+		CreatedAt:    historyPO.CreatedAt,
+	}
+}
+
+// CastAuditLogDO2PO 安全事件实体转存储
+func CastAuditLogDO2PO(log *entity.AuditLog) *po.AuditLogPO {
+	if log == nil {
+		return nil
+	}
+	return &po.AuditLogPO{
+		LogID:     log.LogID,
+		UserID:    log.UserID,
+		ActorID:   log.ActorID,
+		Action:    log.Action,
+		Result:    log.Result,
+		Detail:    log.Detail,
+		IP:        log.IP,
+		UserAgent: log.UserAgent,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+// CastAuditLogPO2DO 安全事件存储转实体
+func CastAuditLogPO2DO(logPO *po.AuditLogPO) *entity.AuditLog {
+	if logPO == nil {
+		return nil
+	}
+	return &entity.AuditLog{
+		LogID:     logPO.LogID,
+		UserID:    logPO.UserID,
+		ActorID:   logPO.ActorID,
+		Action:    logPO.Action,
+		Result:    logPO.Result,
+		Detail:    logPO.Detail,
+		IP:        logPO.IP,
+		UserAgent: logPO.UserAgent,
+		CreatedAt: logPO.CreatedAt,
+	}
+}
+
+// CastInviteCodeDO2PO 邀请码实体转存储
+func CastInviteCodeDO2PO(code *entity.InviteCode) *po.InviteCodePO {
+	if code == nil {
+		return nil
+	}
+	return &po.InviteCodePO{
+		Code:      code.Code,
+		UserID:    code.UserID,
+		MaxUses:   code.MaxUses,
+		UsedCount: code.UsedCount,
+		ExpiresAt: code.ExpiresAt,
+		CreatedAt: code.CreatedAt,
+	}
+}
+
+// CastInviteCodePO2DO 邀请码存储转实体
+func CastInviteCodePO2DO(codePO *po.InviteCodePO) *entity.InviteCode {
+	if codePO == nil {
+		return nil
+	}
+	return &entity.InviteCode{
+		Code:      codePO.Code,
+		UserID:    codePO.UserID,
+		MaxUses:   codePO.MaxUses,
+		UsedCount: codePO.UsedCount,
+		ExpiresAt: codePO.ExpiresAt,
+		CreatedAt: codePO.CreatedAt,
+	}
+}
+
+// CastReferralDO2PO 邀请关系实体转存储
+func CastReferralDO2PO(referral *entity.Referral) *po.ReferralPO {
+	if referral == nil {
+		return nil
+	}
+	return &po.ReferralPO{
+		InviterID: referral.InviterID,
+		InviteeID: referral.InviteeID,
+		Code:      referral.Code,
+		CreatedAt: referral.CreatedAt,
+	}
+}
+
+// CastReferralPO2DO 邀请关系存储转实体
+func CastReferralPO2DO(referralPO *po.ReferralPO) *entity.Referral {
+	if referralPO == nil {
+		return nil
+	}
+	return &entity.Referral{
+		InviterID: referralPO.InviterID,
+		InviteeID: referralPO.InviteeID,
+		Code:      referralPO.Code,
+		CreatedAt: referralPO.CreatedAt,
+	}
+}
+
+// CastAiUsageDO2PO AI用量实体转存储
+func CastAiUsageDO2PO(record *entity.AiUsageRecord) *po.AiUsagePO {
+	if record == nil {
+		return nil
+	}
+	return &po.AiUsagePO{
+		RecordID:         record.RecordID,
+		UserID:           record.UserID,
+		Scene:            record.Scene,
+		Provider:         record.Provider,
+		Model:            record.Model,
+		PromptTokens:     record.Usage.PromptTokens,
+		CompletionTokens: record.Usage.CompletionTokens,
+		CreatedAt:        record.CreatedAt,
+	}
+}
+
+// CastPromptPresetDO2PO 对话预设实体转存储
+func CastPromptPresetDO2PO(preset *entity.PromptPreset) *po.PromptPresetPO {
+	if preset == nil {
+		return nil
+	}
+	return &po.PromptPresetPO{
+		PresetID:         preset.PresetID,
+		OwnerID:          preset.OwnerID,
+		Name:             preset.Name,
+		Description:      preset.Description,
+		SystemPrompt:     preset.SystemPrompt,
+		Temperature:      preset.Params.Temperature,
+		TopP:             preset.Params.TopP,
+		MaxTokens:        preset.Params.MaxTokens,
+		FrequencyPenalty: preset.Params.FrequencyPenalty,
+		CreatedAt:        preset.CreatedAt,
+		UpdatedAt:        preset.UpdatedAt,
+	}
+}
+
+// CastPromptPresetPO2DO 对话预设存储转实体
+func CastPromptPresetPO2DO(presetPO *po.PromptPresetPO) *entity.PromptPreset {
+	if presetPO == nil {
+		return nil
+	}
+	return &entity.PromptPreset{
+		PresetID:     presetPO.PresetID,
+		OwnerID:      presetPO.OwnerID,
+		Name:         presetPO.Name,
+		Description:  presetPO.Description,
+		SystemPrompt: presetPO.SystemPrompt,
+		Params: entity.ModelParams{
+			Temperature:      presetPO.Temperature,
+			TopP:             presetPO.TopP,
+			MaxTokens:        presetPO.MaxTokens,
+			FrequencyPenalty: presetPO.FrequencyPenalty,
+		},
+		CreatedAt: presetPO.CreatedAt,
+		UpdatedAt: presetPO.UpdatedAt,
+	}
+}
+
+// CastMessageFeedbackDO2PO 消息评价实体转存储
+func CastMessageFeedbackDO2PO(feedback *entity.MessageFeedback) *po.MessageFeedbackPO {
+	if feedback == nil {
+		return nil
+	}
+	return &po.MessageFeedbackPO{
+		ConversationID: feedback.ConversationID,
+		MessageID:      feedback.MessageID,
+		UserID:         feedback.UserID,
+		Rating:         feedback.Rating,
+		Comment:        feedback.Comment,
+		Prompt:         feedback.Prompt,
+		Reply:          feedback.Reply,
+		Provider:       feedback.Provider,
+		Model:          feedback.Model,
+		PresetID:       feedback.PresetID,
+		CreatedAt:      feedback.CreatedAt,
+		UpdatedAt:      feedback.UpdatedAt,
+	}
+}
+
+// CastMessageFeedbackPO2DO 消息评价存储转实体
+func CastMessageFeedbackPO2DO(feedbackPO *po.MessageFeedbackPO) *entity.MessageFeedback {
+	if feedbackPO == nil {
+		return nil
+	}
+	return &entity.MessageFeedback{
+		ConversationID: feedbackPO.ConversationID,
+		MessageID:      feedbackPO.MessageID,
+		UserID:         feedbackPO.UserID,
+		Rating:         feedbackPO.Rating,
+		Comment:        feedbackPO.Comment,
+		Prompt:         feedbackPO.Prompt,
+		Reply:          feedbackPO.Reply,
+		Provider:       feedbackPO.Provider,
+		Model:          feedbackPO.Model,
+		PresetID:       feedbackPO.PresetID,
+		CreatedAt:      feedbackPO.CreatedAt,
+		UpdatedAt:      feedbackPO.UpdatedAt,
+	}
+}
+
+// CastMindMapShareDO2PO 导图分享实体转存储
+func CastMindMapShareDO2PO(share *entity.MindMapShare) *po.MindMapSharePO {
+	if share == nil {
+		return nil
+	}
+	return &po.MindMapSharePO{
+		ShareID:      share.ShareID,
+		MapID:        share.MapID,
+		OwnerID:      share.OwnerID,
+		PasswordHash: share.PasswordHash,
+		ExpiresAt:    share.ExpiresAt,
+		CreatedAt:    share.CreatedAt,
+	}
+}
+
+// CastMindMapSharePO2DO 导图分享存储转实体
+func CastMindMapSharePO2DO(sharePO *po.MindMapSharePO) *entity.MindMapShare {
+	if sharePO == nil {
+		return nil
+	}
+	return &entity.MindMapShare{
+		ShareID:      sharePO.ShareID,
+		MapID:        sharePO.MapID,
+		OwnerID:      sharePO.OwnerID,
+		PasswordHash: sharePO.PasswordHash,
+		ExpiresAt:    sharePO.ExpiresAt,
+		CreatedAt:    sharePO.CreatedAt,
+	}
+}
+
+// CastMindMapMemberDO2PO 导图协作者实体转存储
+func CastMindMapMemberDO2PO(member *entity.MindMapMember) *po.MindMapMemberPO {
+	if member == nil {
+		return nil
+	}
+	return &po.MindMapMemberPO{
+		MapID:     member.MapID,
+		UserID:    member.UserID,
+		OwnerID:   member.OwnerID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}
+}
+
+// CastMindMapMemberPO2DO 导图协作者存储转实体
+func CastMindMapMemberPO2DO(memberPO *po.MindMapMemberPO) *entity.MindMapMember {
+	if memberPO == nil {
+		return nil
+	}
+	return &entity.MindMapMember{
+		MapID:     memberPO.MapID,
+		OwnerID:   memberPO.OwnerID,
+		UserID:    memberPO.UserID,
+		Role:      memberPO.Role,
+		CreatedAt: memberPO.CreatedAt,
+		UpdatedAt: memberPO.UpdatedAt,
+	}
+}
+
+// CastMindMapFolderDO2PO 导图文件夹实体转存储
+func CastMindMapFolderDO2PO(folder *entity.MindMapFolder) *po.MindMapFolderPO {
+	if folder == nil {
+		return nil
+	}
+	return &po.MindMapFolderPO{
+		FolderID:  folder.FolderID,
+		UserID:    folder.UserID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+}
+
+// CastMindMapFolderPO2DO 导图文件夹存储转实体
+func CastMindMapFolderPO2DO(folderPO *po.MindMapFolderPO) *entity.MindMapFolder {
+	if folderPO == nil {
+		return nil
+	}
+	return &entity.MindMapFolder{
+		FolderID:  folderPO.FolderID,
+		UserID:    folderPO.UserID,
+		ParentID:  folderPO.ParentID,
+		Name:      folderPO.Name,
+		CreatedAt: folderPO.CreatedAt,
+		UpdatedAt: folderPO.UpdatedAt,
+	}
+}
+
+// CastMindMapTagDO2PO 导图标签实体转存储
+func CastMindMapTagDO2PO(tag *entity.MindMapTag) *po.MindMapTagPO {
+	if tag == nil {
+		return nil
+	}
+	return &po.MindMapTagPO{
+		TagID:     tag.TagID,
+		UserID:    tag.UserID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+// CastMindMapTagPO2DO 导图标签存储转实体
+func CastMindMapTagPO2DO(tagPO *po.MindMapTagPO) *entity.MindMapTag {
+	if tagPO == nil {
+		return nil
+	}
+	return &entity.MindMapTag{
+		TagID:     tagPO.TagID,
+		UserID:    tagPO.UserID,
+		Name:      tagPO.Name,
+		CreatedAt: tagPO.CreatedAt,
+	}
+}
+
+// CastMindMapTemplateDO2PO 导图模板实体转存储
+func CastMindMapTemplateDO2PO(template *entity.MindMapTemplate) (*po.MindMapTemplatePO, error) {
+	if template == nil {
+		return nil, nil
+	}
+	dataBytes, err := json.Marshal(template.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mindmap template data: %w", err)
+	}
+	return &po.MindMapTemplatePO{
+		TemplateID: template.TemplateID,
+		Title:      template.Title,
+		Desc:       template.Desc,
+		Category:   template.Category,
+		Layout:     template.Layout,
+		Data:       string(dataBytes),
+		Sort:       template.Sort,
+		CreatedBy:  template.CreatedBy,
+		CreatedAt:  template.CreatedAt,
+		UpdatedAt:  template.UpdatedAt,
+	}, nil
+}
+
+// CastMindMapTemplatePO2DO 导图模板存储转实体
+func CastMindMapTemplatePO2DO(templatePO *po.MindMapTemplatePO) (*entity.MindMapTemplate, error) {
+	if templatePO == nil {
+		return nil, nil
+	}
+	var data entity.MindMapData
+	if err := json.Unmarshal([]byte(templatePO.Data), &data); err != nil {
+		return nil, fmt.Errorf("unmarshal mindmap template data failed: %w", err)
+	}
+	return &entity.MindMapTemplate{
+		TemplateID: templatePO.TemplateID,
+		Title:      templatePO.Title,
+		Desc:       templatePO.Desc,
+		Category:   templatePO.Category,
+		Layout:     templatePO.Layout,
+		Data:       data,
+		Sort:       templatePO.Sort,
+		CreatedBy:  templatePO.CreatedBy,
+		CreatedAt:  templatePO.CreatedAt,
+		UpdatedAt:  templatePO.UpdatedAt,
+	}, nil
+}
+
+// CastMindMapActivityDO2PO 导图变更记录实体转存储
+func CastMindMapActivityDO2PO(activity *entity.MindMapActivity) *po.MindMapActivityPO {
+	if activity == nil {
+		return nil
+	}
+	return &po.MindMapActivityPO{
+		ActivityID: activity.ActivityID,
+		MapID:      activity.MapID,
+		OwnerID:    activity.OwnerID,
+		ActorID:    activity.ActorID,
+		Type:       activity.Type,
+		Path:       encodeNodePath(activity.Path),
+		Text:       activity.Text,
+		OldText:    activity.OldText,
+		TargetID:   activity.TargetID,
+		CreatedAt:  activity.CreatedAt,
+	}
+}
+
+// CastMindMapActivityPO2DO 导图变更记录存储转实体
+func CastMindMapActivityPO2DO(activityPO *po.MindMapActivityPO) *entity.MindMapActivity {
+	if activityPO == nil {
+		return nil
+	}
+	return &entity.MindMapActivity{
+		ActivityID: activityPO.ActivityID,
+		MapID:      activityPO.MapID,
+		OwnerID:    activityPO.OwnerID,
+		ActorID:    activityPO.ActorID,
+		Type:       activityPO.Type,
+		Path:       decodeNodePath(activityPO.Path),
+		Text:       activityPO.Text,
+		OldText:    activityPO.OldText,
+		TargetID:   activityPO.TargetID,
+		CreatedAt:  activityPO.CreatedAt,
+	}
+}
+
+// CastMindMapCommentDO2PO 导图评论实体转存储
+func CastMindMapCommentDO2PO(comment *entity.MindMapComment) *po.MindMapCommentPO {
+	if comment == nil {
+		return nil
+	}
+	return &po.MindMapCommentPO{
+		CommentID:  comment.CommentID,
+		MapID:      comment.MapID,
+		NodeID:     comment.NodeID,
+		OwnerID:    comment.OwnerID,
+		UserID:     comment.UserID,
+		Content:    comment.Content,
+		Resolved:   comment.Resolved,
+		ResolvedBy: comment.ResolvedBy,
+		ResolvedAt: comment.ResolvedAt,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+	}
+}
+
+// CastMindMapCommentPO2DO 导图评论存储转实体
+func CastMindMapCommentPO2DO(commentPO *po.MindMapCommentPO) *entity.MindMapComment {
+	if commentPO == nil {
+		return nil
+	}
+	return &entity.MindMapComment{
+		CommentID:  commentPO.CommentID,
+		MapID:      commentPO.MapID,
+		OwnerID:    commentPO.OwnerID,
+		NodeID:     commentPO.NodeID,
+		UserID:     commentPO.UserID,
+		Content:    commentPO.Content,
+		Resolved:   commentPO.Resolved,
+		ResolvedBy: commentPO.ResolvedBy,
+		ResolvedAt: commentPO.ResolvedAt,
+		CreatedAt:  commentPO.CreatedAt,
+		UpdatedAt:  commentPO.UpdatedAt,
+	}
+}
+
+// CastUserQuotaDO2PO 用户单独配额实体转存储
+func CastUserQuotaDO2PO(override *entity.UserQuotaOverride) *po.UserQuotaPO {
+	if override == nil {
+		return nil
+	}
+	return &po.UserQuotaPO{
+		UserID:          override.UserID,
+		MaxMindMaps:     override.MaxMindMaps,
+		MaxNodesPerMap:  override.MaxNodesPerMap,
+		MaxStorageBytes: override.MaxStorageBytes,
+		UpdatedBy:       override.UpdatedBy,
+		UpdatedAt:       override.UpdatedAt,
+	}
+}
+
+// CastUserQuotaPO2DO 用户单独配额存储转实体
+func CastUserQuotaPO2DO(quotaPO *po.UserQuotaPO) *entity.UserQuotaOverride {
+	if quotaPO == nil {
+		return nil
+	}
+	return &entity.UserQuotaOverride{
+		UserID:          quotaPO.UserID,
+		MaxMindMaps:     quotaPO.MaxMindMaps,
+		MaxNodesPerMap:  quotaPO.MaxNodesPerMap,
+		MaxStorageBytes: quotaPO.MaxStorageBytes,
+		UpdatedBy:       quotaPO.UpdatedBy,
+		UpdatedAt:       quotaPO.UpdatedAt,
+	}
+}
+
+// CastOutboxEventDO2PO 发件箱事件实体转存储
+func CastOutboxEventDO2PO(event *entity.OutboxEvent) *po.OutboxEventPO {
+	if event == nil {
+		return nil
+	}
+	return &po.OutboxEventPO{
+		ID:            event.ID,
+		EventID:       event.EventID,
+		Topic:         event.Topic,
+		Key:           event.Key,
+		Payload:       event.Payload,
+		Attempts:      event.Attempts,
+		LastError:     event.LastError,
+		NextAttemptAt: event.NextAttemptAt,
+		PublishedAt:   event.PublishedAt,
+		FailedAt:      event.FailedAt,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// CastOutboxEventPO2DO 发件箱事件存储转实体
+func CastOutboxEventPO2DO(eventPO *po.OutboxEventPO) *entity.OutboxEvent {
+	if eventPO == nil {
+		return nil
+	}
+	return &entity.OutboxEvent{
+		ID:            eventPO.ID,
+		EventID:       eventPO.EventID,
+		Topic:         eventPO.Topic,
+		Key:           eventPO.Key,
+		Payload:       eventPO.Payload,
+		Attempts:      eventPO.Attempts,
+		LastError:     eventPO.LastError,
+		NextAttemptAt: eventPO.NextAttemptAt,
+		PublishedAt:   eventPO.PublishedAt,
+		FailedAt:      eventPO.FailedAt,
+		CreatedAt:     eventPO.CreatedAt,
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+
+	"gorm.io/gorm"
+)
+
+type filePersistence struct {
+	db *gorm.DB
+}
+
+var fp *filePersistence
+
+func InitFileStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移文件元数据表
+	if err := database.AutoMigrate(&po.FilePO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate file table: %v", err))
+	}
+
+	fp = &filePersistence{
+		db: db,
+	}
+}
+
+func GetFilePersistence() repo.FileRepo {
+	return fp
+}
+
+// CreateFile 记录文件元数据
+func (f *filePersistence) CreateFile(ctx context.Context, file *entity.File) error {
+	filePO := CastFileDO2PO(file)
+	if err := database.Conn(ctx, f.db).Create(filePO).Error; err != nil {
+		return fmt.Errorf("create file failed: %w", err)
+	}
+	return nil
+}
+
+// ListFiles 查询文件列表
+func (f *filePersistence) ListFiles(ctx context.Context, query repo.FileQuery) ([]*entity.File, error) {
+	var filePOs []po.FilePO
+
+	db := database.Conn(ctx, f.db)
+
+	var hasCond bool
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+		hasCond = true
+	}
+	if query.ConversationID != "" {
+		db = db.Where("conversation_id = ?", query.ConversationID)
+		hasCond = true
+	}
+	if len(query.ConversationIDs) > 0 {
+		db = db.Where("conversation_id IN ?", query.ConversationIDs)
+		hasCond = true
+	}
+	if query.Purpose != "" {
+		db = db.Where("purpose = ?", query.Purpose)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	// 防止误查全表
+	if !hasCond {
+		return nil, fmt.Errorf("invalid file query: no query field provided")
+	}
+
+	if err := db.Order("created_at DESC").Find(&filePOs).Error; err != nil {
+		return nil, fmt.Errorf("list files failed: %w", err)
+	}
+
+	files := make([]*entity.File, 0, len(filePOs))
+	for i := range filePOs {
+		files = append(files, CastFilePO2DO(&filePOs[i]))
+	}
+	return files, nil
+}
+
+// ListUserFiles 分页查询用户的文件
+func (f *filePersistence) ListUserFiles(ctx context.Context, userID, purpose string, page, pageSize int) ([]*entity.File, int64, error) {
+	db := database.Conn(ctx, f.db).Model(&po.FilePO{}).Where("user_id = ?", userID)
+	if purpose != "" {
+		db = db.Where("purpose = ?", purpose)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count user files failed: %w", err)
+	}
+
+	var filePOs []*po.FilePO
+	offset := (page - 1) * pageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&filePOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list user files failed: %w", err)
+	}
+
+	files := make([]*entity.File, 0, len(filePOs))
+	for _, filePO := range filePOs {
+		files = append(files, CastFilePO2DO(filePO))
+	}
+	return files, total, nil
+}
+
+// GetFile 查询用户的文件，不存在时返回 nil
+func (f *filePersistence) GetFile(ctx context.Context, userID, fileID string) (*entity.File, error) {
+	var filePO po.FilePO
+	err := database.Conn(ctx, f.db).Where("file_id = ? AND user_id = ?", fileID, userID).First(&filePO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get file failed: %w", err)
+	}
+	return CastFilePO2DO(&filePO), nil
+}
+
+// DeleteFiles 删除文件记录
+func (f *filePersistence) DeleteFiles(ctx context.Context, fileIDs []string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	result := database.Conn(ctx, f.db).Where("file_id IN ?", fileIDs).Delete(&po.FilePO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete files failed: %w", result.Error)
+	}
+	zlog.CtxInfof(ctx, "deleted %d file records", result.RowsAffected)
+	return nil
+}
+
+// SumFileSize 统计用户文件占用的总空间
+func (f *filePersistence) SumFileSize(ctx context.Context, userID string) (int64, error) {
+	var total int64
+	err := database.Conn(ctx, f.db).Model(&po.FilePO{}).
+		Where("user_id = ? AND status <> ?", userID, entity.FileStatusQuarantined).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("sum file size failed: %w", err)
+	}
+	return total, nil
+}
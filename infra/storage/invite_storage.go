@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type invitePersistence struct {
+	db *gorm.DB
+}
+
+var itp *invitePersistence
+
+func InitInviteStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移邀请码表和邀请关系表
+	if err := database.AutoMigrate(&po.InviteCodePO{}, &po.ReferralPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate invite tables: %v", err))
+	}
+
+	itp = &invitePersistence{
+		db: db,
+	}
+}
+
+func GetInvitePersistence() repo.InviteRepo {
+	return itp
+}
+
+// CreateInviteCode 保存邀请码
+func (i *invitePersistence) CreateInviteCode(ctx context.Context, code *entity.InviteCode) error {
+	if err := database.Conn(ctx, i.db).Create(CastInviteCodeDO2PO(code)).Error; err != nil {
+		return fmt.Errorf("create invite code failed: %w", err)
+	}
+	return nil
+}
+
+// GetInviteCode 查询邀请码
+func (i *invitePersistence) GetInviteCode(ctx context.Context, code string) (*entity.InviteCode, error) {
+	var codePO po.InviteCodePO
+	err := database.Conn(ctx, i.db).Where("code = ?", code).Take(&codePO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get invite code failed: %w", err)
+	}
+	return CastInviteCodePO2DO(&codePO), nil
+}
+
+// ListInviteCodes 获取用户生成的邀请码
+func (i *invitePersistence) ListInviteCodes(ctx context.Context, userID string) ([]*entity.InviteCode, error) {
+	var codePOs []*po.InviteCodePO
+	err := database.Conn(ctx, i.db).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Find(&codePOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list invite codes failed: %w", err)
+	}
+
+	codes := make([]*entity.InviteCode, 0, len(codePOs))
+	for _, codePO := range codePOs {
+		codes = append(codes, CastInviteCodePO2DO(codePO))
+	}
+	return codes, nil
+}
+
+// CountInviteCodes 统计用户已生成的邀请码个数
+func (i *invitePersistence) CountInviteCodes(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, i.db).Model(&po.InviteCodePO{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count invite codes failed: %w", err)
+	}
+	return count, nil
+}
+
+// UseInviteCode 条件更新使用次数，只有未过期且次数未用完的邀请码会被更新
+func (i *invitePersistence) UseInviteCode(ctx context.Context, code string, now time.Time) (bool, error) {
+	result := database.Conn(ctx, i.db).
+		Model(&po.InviteCodePO{}).
+		Where("code = ? AND used_count < max_uses AND (expires_at IS NULL OR expires_at > ?)", code, now).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return false, fmt.Errorf("use invite code failed: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseInviteCode 退还一次使用次数
+func (i *invitePersistence) ReleaseInviteCode(ctx context.Context, code string) error {
+	err := database.Conn(ctx, i.db).
+		Model(&po.InviteCodePO{}).
+		Where("code = ? AND used_count > 0", code).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+	if err != nil {
+		return fmt.Errorf("release invite code failed: %w", err)
+	}
+	return nil
+}
+
+// CreateReferral 记录邀请关系
+func (i *invitePersistence) CreateReferral(ctx context.Context, referral *entity.Referral) error {
+	if err := database.Conn(ctx, i.db).Create(CastReferralDO2PO(referral)).Error; err != nil {
+		return fmt.Errorf("create referral failed: %w", err)
+	}
+	return nil
+}
+
+// ListReferrals 获取用户邀请注册的记录
+func (i *invitePersistence) ListReferrals(ctx context.Context, inviterID string) ([]*entity.Referral, error) {
+	var referralPOs []*po.ReferralPO
+	err := database.Conn(ctx, i.db).
+		Where("inviter_id = ?", inviterID).
+		Order("id DESC").
+		Find(&referralPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list referrals failed: %w", err)
+	}
+
+	referrals := make([]*entity.Referral, 0, len(referralPOs))
+	for _, referralPO := range referralPOs {
+		referrals = append(referrals, CastReferralPO2DO(referralPO))
+	}
+	return referrals, nil
+}
+
+func (i *invitePersistence) DeleteUserInvites(ctx context.Context, userID string) error {
+	return database.Conn(ctx, i.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&po.InviteCodePO{}).Error; err != nil {
+			return fmt.Errorf("delete invite codes failed: %w", err)
+		}
+		if err := tx.Where("inviter_id = ? OR invitee_id = ?", userID, userID).Delete(&po.ReferralPO{}).Error; err != nil {
+			return fmt.Errorf("delete referrals failed: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type invoicePersistence struct {
+	db *gorm.DB
+}
+
+var ip *invoicePersistence
+
+func InitInvoiceStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移账单表
+	if err := database.AutoMigrate(&po.InvoicePO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate invoice table: %v", err))
+	}
+
+	ip = &invoicePersistence{
+		db: db,
+	}
+}
+
+func GetInvoicePersistence() repo.InvoiceRepo {
+	return ip
+}
+
+// CreateInvoice 创建账单
+func (i *invoicePersistence) CreateInvoice(ctx context.Context, invoice *entity.Invoice) error {
+	invoicePO := CastInvoiceDO2PO(invoice)
+	if err := database.Conn(ctx, i.db).Create(invoicePO).Error; err != nil {
+		return fmt.Errorf("create invoice failed: %w", err)
+	}
+	return nil
+}
+
+// GetInvoice 根据账单ID获取账单
+func (i *invoicePersistence) GetInvoice(ctx context.Context, invoiceID string) (*entity.Invoice, error) {
+	var invoicePO po.InvoicePO
+	err := database.Conn(ctx, i.db).Where("invoice_id = ?", invoiceID).First(&invoicePO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get invoice failed: %w", err)
+	}
+	return CastInvoicePO2DO(&invoicePO), nil
+}
+
+// ListInvoices 获取用户的账单列表
+func (i *invoicePersistence) ListInvoices(ctx context.Context, userID string) ([]*entity.Invoice, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+
+	var invoicePOs []po.InvoicePO
+	err := database.Conn(ctx, i.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&invoicePOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list invoices failed: %w", err)
+	}
+
+	invoices := make([]*entity.Invoice, 0, len(invoicePOs))
+	for idx := range invoicePOs {
+		invoices = append(invoices, CastInvoicePO2DO(&invoicePOs[idx]))
+	}
+	return invoices, nil
+}
+
+// UpdateInvoiceCheckout 记录支付渠道返回的会话信息
+func (i *invoicePersistence) UpdateInvoiceCheckout(ctx context.Context, invoiceID, sessionID, checkoutURL string) error {
+	err := database.Conn(ctx, i.db).Model(&po.InvoicePO{}).
+		Where("invoice_id = ?", invoiceID).
+		Updates(map[string]any{
+			"provider_session_id": sessionID,
+			"checkout_url":        checkoutURL,
+			"updated_at":          time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("update invoice checkout failed: %w", err)
+	}
+	return nil
+}
+
+// TransitInvoiceStatus 条件更新账单状态
+func (i *invoicePersistence) TransitInvoiceStatus(ctx context.Context, invoiceID, fromStatus, toStatus string, paidAt *time.Time) (bool, error) {
+	updates := map[string]any{
+		"status":     toStatus,
+		"updated_at": time.Now(),
+	}
+	if paidAt != nil {
+		updates["paid_at"] = *paidAt
+	}
+
+	result := database.Conn(ctx, i.db).Model(&po.InvoicePO{}).
+		Where("invoice_id = ? AND status = ?", invoiceID, fromStatus).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("update invoice status failed: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
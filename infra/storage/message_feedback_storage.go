@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type messageFeedbackPersistence struct {
+	db *gorm.DB
+}
+
+var mfp *messageFeedbackPersistence
+
+func InitMessageFeedbackStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移消息评价表
+	if err := database.AutoMigrate(&po.MessageFeedbackPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate message feedback table: %v", err))
+	}
+
+	mfp = &messageFeedbackPersistence{
+		db: db,
+	}
+}
+
+func GetMessageFeedbackPersistence() repo.MessageFeedbackRepo {
+	return mfp
+}
+
+// SaveFeedback 按会话与消息唯一，已有评价时覆盖评价、说明与快照，保留首次评价时间
+func (m *messageFeedbackPersistence) SaveFeedback(ctx context.Context, feedback *entity.MessageFeedback) error {
+	err := database.Conn(ctx, m.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_id"}, {Name: "message_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "comment", "prompt", "reply", "provider", "model", "preset_id", "updated_at"}),
+	}).Create(CastMessageFeedbackDO2PO(feedback)).Error
+	if err != nil {
+		return fmt.Errorf("save message feedback failed: %w", err)
+	}
+	return nil
+}
+
+func (m *messageFeedbackPersistence) DeleteFeedback(ctx context.Context, conversationID, messageID, userID string) error {
+	err := database.Conn(ctx, m.db).
+		Where("conversation_id = ? AND message_id = ? AND user_id = ?", conversationID, messageID, userID).
+		Delete(&po.MessageFeedbackPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete message feedback failed: %w", err)
+	}
+	return nil
+}
+
+func (m *messageFeedbackPersistence) ListConversationFeedback(ctx context.Context, conversationID, userID string) ([]*entity.MessageFeedback, error) {
+	var feedbackPOs []*po.MessageFeedbackPO
+	// 会话中只需要评价状态，不查询快照内容
+	err := database.Conn(ctx, m.db).
+		Select("conversation_id", "message_id", "user_id", "rating", "comment", "created_at", "updated_at").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Find(&feedbackPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list conversation feedback failed: %w", err)
+	}
+
+	feedbacks := make([]*entity.MessageFeedback, 0, len(feedbackPOs))
+	for _, feedbackPO := range feedbackPOs {
+		feedbacks = append(feedbacks, CastMessageFeedbackPO2DO(feedbackPO))
+	}
+	return feedbacks, nil
+}
+
+// ListFeedback 分页查询评价
+func (m *messageFeedbackPersistence) ListFeedback(ctx context.Context, query repo.MessageFeedbackQuery) ([]*entity.MessageFeedback, int64, error) {
+	var feedbackPOs []*po.MessageFeedbackPO
+	var total int64
+
+	db := database.Conn(ctx, m.db).Model(&po.MessageFeedbackPO{})
+	if query.Rating != "" {
+		db = db.Where("rating = ?", query.Rating)
+	}
+	if query.PresetID != "" {
+		db = db.Where("preset_id = ?", query.PresetID)
+	}
+	if query.Since != nil {
+		db = db.Where("updated_at >= ?", *query.Since)
+	}
+	if query.Until != nil {
+		db = db.Where("updated_at < ?", *query.Until)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count message feedback failed: %w", err)
+	}
+
+	db = db.Order("updated_at DESC, id DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	}
+	if err := db.Find(&feedbackPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list message feedback failed: %w", err)
+	}
+
+	feedbacks := make([]*entity.MessageFeedback, 0, len(feedbackPOs))
+	for _, feedbackPO := range feedbackPOs {
+		feedbacks = append(feedbacks, CastMessageFeedbackPO2DO(feedbackPO))
+	}
+	return feedbacks, total, nil
+}
+
+func (m *messageFeedbackPersistence) DeleteUserFeedback(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, m.db).Where("user_id = ?", userID).Delete(&po.MessageFeedbackPO{}).Error; err != nil {
+		return fmt.Errorf("delete user message feedback failed: %w", err)
+	}
+	return nil
+}
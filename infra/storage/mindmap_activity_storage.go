@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type mindMapActivityPersistence struct {
+	db *gorm.DB
+}
+
+var acp *mindMapActivityPersistence
+
+func InitMindMapActivityStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图变更记录表
+	if err := database.AutoMigrate(&po.MindMapActivityPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap activity table: %v", err))
+	}
+
+	acp = &mindMapActivityPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapActivityPersistence() repo.IMindMapActivityRepo {
+	return acp
+}
+
+func (m *mindMapActivityPersistence) CreateActivities(ctx context.Context, activities []*entity.MindMapActivity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	activityPOs := make([]*po.MindMapActivityPO, 0, len(activities))
+	for _, activity := range activities {
+		activityPOs = append(activityPOs, CastMindMapActivityDO2PO(activity))
+	}
+	if err := database.Conn(ctx, m.db).Create(&activityPOs).Error; err != nil {
+		return fmt.Errorf("create mindmap activities failed: %w", err)
+	}
+	return nil
+}
+
+// ListActivities 同一时间的记录按自增ID倒序，保证分页顺序稳定
+func (m *mindMapActivityPersistence) ListActivities(ctx context.Context, mapID string, since time.Time, page, pageSize int) ([]*entity.MindMapActivity, int64, error) {
+	db := database.Conn(ctx, m.db).Model(&po.MindMapActivityPO{}).Where("map_id = ?", mapID)
+	if !since.IsZero() {
+		db = db.Where("created_at > ?", since)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count mindmap activities failed: %w", err)
+	}
+
+	var activityPOs []*po.MindMapActivityPO
+	offset := (page - 1) * pageSize
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&activityPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list mindmap activities failed: %w", err)
+	}
+
+	activities := make([]*entity.MindMapActivity, 0, len(activityPOs))
+	for _, activityPO := range activityPOs {
+		activities = append(activities, CastMindMapActivityPO2DO(activityPO))
+	}
+	return activities, total, nil
+}
+
+func (m *mindMapActivityPersistence) DeleteUserActivities(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	err := database.Conn(ctx, m.db).Where("owner_id = ? OR actor_id = ?", userID, userID).Delete(&po.MindMapActivityPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete user mindmap activities failed: %w", err)
+	}
+	return nil
+}
+
+// encodeNodePath 节点路径保存为逗号分隔的下标，根节点为空字符串
+func encodeNodePath(path []int) string {
+	parts := make([]string, len(path))
+	for i, index := range path {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeNodePath 无法解析的路径按根节点处理
+func decodeNodePath(s string) []int {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	path := make([]int, 0, len(parts))
+	for _, part := range parts {
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		path = append(path, index)
+	}
+	return path
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type mindMapCommentPersistence struct {
+	db *gorm.DB
+}
+
+var cmp *mindMapCommentPersistence
+
+func InitMindMapCommentStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图评论表
+	if err := database.AutoMigrate(&po.MindMapCommentPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap comment table: %v", err))
+	}
+
+	cmp = &mindMapCommentPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapCommentPersistence() repo.IMindMapCommentRepo {
+	return cmp
+}
+
+func (m *mindMapCommentPersistence) CreateComment(ctx context.Context, comment *entity.MindMapComment) error {
+	if err := database.Conn(ctx, m.db).Create(CastMindMapCommentDO2PO(comment)).Error; err != nil {
+		return fmt.Errorf("create mindmap comment failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapCommentPersistence) GetComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error) {
+	var commentPO po.MindMapCommentPO
+	if err := database.Conn(ctx, m.db).Where("map_id = ? AND comment_id = ?", mapID, commentID).First(&commentPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap comment failed: %w", err)
+	}
+	return CastMindMapCommentPO2DO(&commentPO), nil
+}
+
+func (m *mindMapCommentPersistence) ListComments(ctx context.Context, query repo.MindMapCommentQuery) ([]*entity.MindMapComment, int64, error) {
+	db := database.Conn(ctx, m.db).Model(&po.MindMapCommentPO{}).Where("map_id = ?", query.MapID)
+	if query.NodeID != "" {
+		db = db.Where("node_id = ?", query.NodeID)
+	}
+	if query.Resolved != nil {
+		db = db.Where("resolved = ?", *query.Resolved)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count mindmap comments failed: %w", err)
+	}
+
+	var commentPOs []*po.MindMapCommentPO
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at ASC, id ASC").Offset(offset).Limit(query.PageSize).Find(&commentPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list mindmap comments failed: %w", err)
+	}
+
+	comments := make([]*entity.MindMapComment, 0, len(commentPOs))
+	for _, commentPO := range commentPOs {
+		comments = append(comments, CastMindMapCommentPO2DO(commentPO))
+	}
+	return comments, total, nil
+}
+
+func (m *mindMapCommentPersistence) CountComments(ctx context.Context, mapID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, m.db).Model(&po.MindMapCommentPO{}).Where("map_id = ?", mapID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count mindmap comments failed: %w", err)
+	}
+	return count, nil
+}
+
+func (m *mindMapCommentPersistence) UpdateCommentResolved(ctx context.Context, comment *entity.MindMapComment) error {
+	result := database.Conn(ctx, m.db).Model(&po.MindMapCommentPO{}).
+		Where("map_id = ? AND comment_id = ?", comment.MapID, comment.CommentID).
+		Updates(map[string]any{
+			"resolved":    comment.Resolved,
+			"resolved_by": comment.ResolvedBy,
+			"resolved_at": comment.ResolvedAt,
+			"updated_at":  comment.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("update mindmap comment failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapCommentNotFound
+	}
+	return nil
+}
+
+func (m *mindMapCommentPersistence) DeleteComment(ctx context.Context, mapID, commentID string) error {
+	result := database.Conn(ctx, m.db).Where("map_id = ? AND comment_id = ?", mapID, commentID).Delete(&po.MindMapCommentPO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap comment failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapCommentNotFound
+	}
+	return nil
+}
+
+func (m *mindMapCommentPersistence) DeleteUserComments(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	err := database.Conn(ctx, m.db).Where("owner_id = ? OR user_id = ?", userID, userID).Delete(&po.MindMapCommentPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete user mindmap comments failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type mindMapFolderPersistence struct {
+	db *gorm.DB
+}
+
+var fdp *mindMapFolderPersistence
+
+func InitMindMapFolderStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图文件夹表
+	if err := database.AutoMigrate(&po.MindMapFolderPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap folder table: %v", err))
+	}
+
+	fdp = &mindMapFolderPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapFolderPersistence() repo.IMindMapFolderRepo {
+	return fdp
+}
+
+func (m *mindMapFolderPersistence) CreateFolder(ctx context.Context, folder *entity.MindMapFolder) error {
+	if err := database.Conn(ctx, m.db).Create(CastMindMapFolderDO2PO(folder)).Error; err != nil {
+		return fmt.Errorf("create mindmap folder failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapFolderPersistence) GetFolder(ctx context.Context, userID, folderID string) (*entity.MindMapFolder, error) {
+	var folderPO po.MindMapFolderPO
+	if err := database.Conn(ctx, m.db).Where("folder_id = ? AND user_id = ?", folderID, userID).First(&folderPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap folder failed: %w", err)
+	}
+	return CastMindMapFolderPO2DO(&folderPO), nil
+}
+
+func (m *mindMapFolderPersistence) ListFolders(ctx context.Context, userID string) ([]*entity.MindMapFolder, error) {
+	var folderPOs []*po.MindMapFolderPO
+	if err := database.Conn(ctx, m.db).Where("user_id = ?", userID).Order("name ASC").Find(&folderPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap folders failed: %w", err)
+	}
+
+	folders := make([]*entity.MindMapFolder, 0, len(folderPOs))
+	for _, folderPO := range folderPOs {
+		folders = append(folders, CastMindMapFolderPO2DO(folderPO))
+	}
+	return folders, nil
+}
+
+func (m *mindMapFolderPersistence) CountFolders(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, m.db).Model(&po.MindMapFolderPO{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count mindmap folders failed: %w", err)
+	}
+	return count, nil
+}
+
+func (m *mindMapFolderPersistence) UpdateFolder(ctx context.Context, folder *entity.MindMapFolder) error {
+	result := database.Conn(ctx, m.db).Model(&po.MindMapFolderPO{}).
+		Where("folder_id = ? AND user_id = ?", folder.FolderID, folder.UserID).
+		Updates(map[string]any{"name": folder.Name, "parent_id": folder.ParentID, "updated_at": folder.UpdatedAt})
+	if result.Error != nil {
+		return fmt.Errorf("update mindmap folder failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapFolderNotFound
+	}
+	return nil
+}
+
+// DeleteFolder 子文件夹与导图（含已软删除的）移到上级文件夹后再删除，导图本身不受影响
+func (m *mindMapFolderPersistence) DeleteFolder(ctx context.Context, userID, folderID string) error {
+	return database.Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		var folderPO po.MindMapFolderPO
+		if err := tx.Where("folder_id = ? AND user_id = ?", folderID, userID).First(&folderPO).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return repo.ErrMindMapFolderNotFound
+			}
+			return fmt.Errorf("get mindmap folder failed: %w", err)
+		}
+
+		if err := tx.Model(&po.MindMapFolderPO{}).Where("user_id = ? AND parent_id = ?", userID, folderID).
+			UpdateColumn("parent_id", folderPO.ParentID).Error; err != nil {
+			return fmt.Errorf("move sub folders failed: %w", err)
+		}
+		if err := tx.Model(&po.MindMapPO{}).Where("user_id = ? AND folder_id = ?", userID, folderID).
+			UpdateColumn("folder_id", folderPO.ParentID).Error; err != nil {
+			return fmt.Errorf("move folder mindmaps failed: %w", err)
+		}
+		if err := tx.Delete(&folderPO).Error; err != nil {
+			return fmt.Errorf("delete mindmap folder failed: %w", err)
+		}
+		return nil
+	})
+}
+
+func (m *mindMapFolderPersistence) DeleteUserFolders(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	if err := database.Conn(ctx, m.db).Where("user_id = ?", userID).Delete(&po.MindMapFolderPO{}).Error; err != nil {
+		return fmt.Errorf("delete user mindmap folders failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mindMapMemberPersistence struct {
+	db *gorm.DB
+}
+
+var mbp *mindMapMemberPersistence
+
+func InitMindMapMemberStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图协作者表
+	if err := database.AutoMigrate(&po.MindMapMemberPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap member table: %v", err))
+	}
+
+	mbp = &mindMapMemberPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapMemberPersistence() repo.IMindMapMemberRepo {
+	return mbp
+}
+
+// SaveMember 已是协作者时只更新角色，保留加入时间
+func (m *mindMapMemberPersistence) SaveMember(ctx context.Context, member *entity.MindMapMember) error {
+	err := database.Conn(ctx, m.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "map_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(CastMindMapMemberDO2PO(member)).Error
+	if err != nil {
+		return fmt.Errorf("save mindmap member failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapMemberPersistence) GetMember(ctx context.Context, mapID, userID string) (*entity.MindMapMember, error) {
+	var memberPO po.MindMapMemberPO
+	if err := database.Conn(ctx, m.db).Where("map_id = ? AND user_id = ?", mapID, userID).First(&memberPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap member failed: %w", err)
+	}
+	return CastMindMapMemberPO2DO(&memberPO), nil
+}
+
+func (m *mindMapMemberPersistence) ListMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error) {
+	var memberPOs []*po.MindMapMemberPO
+	if err := database.Conn(ctx, m.db).Where("map_id = ?", mapID).Order("created_at ASC").Find(&memberPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap members failed: %w", err)
+	}
+
+	members := make([]*entity.MindMapMember, 0, len(memberPOs))
+	for _, memberPO := range memberPOs {
+		members = append(members, CastMindMapMemberPO2DO(memberPO))
+	}
+	return members, nil
+}
+
+func (m *mindMapMemberPersistence) CountMembers(ctx context.Context, mapID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, m.db).Model(&po.MindMapMemberPO{}).Where("map_id = ?", mapID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count mindmap members failed: %w", err)
+	}
+	return count, nil
+}
+
+func (m *mindMapMemberPersistence) RemoveMember(ctx context.Context, mapID, userID string) error {
+	result := database.Conn(ctx, m.db).Where("map_id = ? AND user_id = ?", mapID, userID).Delete(&po.MindMapMemberPO{})
+	if result.Error != nil {
+		return fmt.Errorf("remove mindmap member failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapMemberNotFound
+	}
+	return nil
+}
+
+func (m *mindMapMemberPersistence) DeleteUserMembers(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	err := database.Conn(ctx, m.db).Where("user_id = ? OR owner_id = ?", userID, userID).Delete(&po.MindMapMemberPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete user mindmap members failed: %w", err)
+	}
+	return nil
+}
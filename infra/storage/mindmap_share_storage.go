@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type mindMapSharePersistence struct {
+	db *gorm.DB
+}
+
+var msp *mindMapSharePersistence
+
+func InitMindMapShareStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图分享表
+	if err := database.AutoMigrate(&po.MindMapSharePO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap share table: %v", err))
+	}
+
+	msp = &mindMapSharePersistence{
+		db: db,
+	}
+}
+
+func GetMindMapSharePersistence() repo.IMindMapShareRepo {
+	return msp
+}
+
+func (m *mindMapSharePersistence) CreateShare(ctx context.Context, share *entity.MindMapShare) error {
+	if err := database.Conn(ctx, m.db).Create(CastMindMapShareDO2PO(share)).Error; err != nil {
+		return fmt.Errorf("create mindmap share failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapSharePersistence) GetShare(ctx context.Context, shareID string) (*entity.MindMapShare, error) {
+	var sharePO po.MindMapSharePO
+	if err := database.Conn(ctx, m.db).Where("share_id = ?", shareID).First(&sharePO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap share failed: %w", err)
+	}
+	return CastMindMapSharePO2DO(&sharePO), nil
+}
+
+func (m *mindMapSharePersistence) ListMapShares(ctx context.Context, mapID string, now time.Time) ([]*entity.MindMapShare, error) {
+	var sharePOs []*po.MindMapSharePO
+	err := database.Conn(ctx, m.db).
+		Where("map_id = ? AND expires_at > ?", mapID, now).
+		Order("created_at DESC").
+		Find(&sharePOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list mindmap shares failed: %w", err)
+	}
+
+	shares := make([]*entity.MindMapShare, 0, len(sharePOs))
+	for _, sharePO := range sharePOs {
+		shares = append(shares, CastMindMapSharePO2DO(sharePO))
+	}
+	return shares, nil
+}
+
+func (m *mindMapSharePersistence) CountMapShares(ctx context.Context, mapID string, now time.Time) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, m.db).
+		Model(&po.MindMapSharePO{}).
+		Where("map_id = ? AND expires_at > ?", mapID, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("count mindmap shares failed: %w", err)
+	}
+	return count, nil
+}
+
+func (m *mindMapSharePersistence) DeleteShare(ctx context.Context, shareID, mapID, ownerID string) error {
+	result := database.Conn(ctx, m.db).
+		Where("share_id = ? AND map_id = ? AND owner_id = ?", shareID, mapID, ownerID).
+		Delete(&po.MindMapSharePO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap share failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapShareNotFound
+	}
+	return nil
+}
+
+func (m *mindMapSharePersistence) DeleteUserShares(ctx context.Context, ownerID string) error {
+	if ownerID == "" {
+		return fmt.Errorf("OwnerID is required for erasure")
+	}
+	if err := database.Conn(ctx, m.db).Where("owner_id = ?", ownerID).Delete(&po.MindMapSharePO{}).Error; err != nil {
+		return fmt.Errorf("delete user mindmap shares failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mindMapStarPersistence struct {
+	db *gorm.DB
+}
+
+var stp *mindMapStarPersistence
+
+func InitMindMapStarStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图收藏表
+	if err := database.AutoMigrate(&po.MindMapStarPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap star table: %v", err))
+	}
+
+	stp = &mindMapStarPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapStarPersistence() repo.IMindMapStarRepo {
+	return stp
+}
+
+// StarMindMap 已收藏时保留原收藏时间
+func (m *mindMapStarPersistence) StarMindMap(ctx context.Context, star *entity.MindMapStar) error {
+	err := database.Conn(ctx, m.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&po.MindMapStarPO{
+		UserID:    star.UserID,
+		MapID:     star.MapID,
+		CreatedAt: star.CreatedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("star mindmap failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapStarPersistence) UnstarMindMap(ctx context.Context, userID, mapID string) error {
+	if err := database.Conn(ctx, m.db).Where("user_id = ? AND map_id = ?", userID, mapID).Delete(&po.MindMapStarPO{}).Error; err != nil {
+		return fmt.Errorf("unstar mindmap failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapStarPersistence) ListStarredMapIDs(ctx context.Context, userID string, mapIDs []string) (map[string]bool, error) {
+	starred := make(map[string]bool)
+	if len(mapIDs) == 0 {
+		return starred, nil
+	}
+	var ids []string
+	err := database.Conn(ctx, m.db).Model(&po.MindMapStarPO{}).
+		Where("user_id = ? AND map_id IN ?", userID, mapIDs).
+		Pluck("map_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("list starred mindmaps failed: %w", err)
+	}
+	for _, id := range ids {
+		starred[id] = true
+	}
+	return starred, nil
+}
+
+func (m *mindMapStarPersistence) DeleteUserStars(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	ownedMaps := m.db.Model(&po.MindMapPO{}).Select("map_id").Where("user_id = ?", userID)
+	err := database.Conn(ctx, m.db).Where("user_id = ? OR map_id IN (?)", userID, ownedMaps).Delete(&po.MindMapStarPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete user mindmap stars failed: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"forge/biz/entity"
 	"forge/biz/repo"
@@ -12,6 +13,7 @@ import (
 	"forge/pkg/log/zlog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type mindMapPersistence struct {
@@ -24,13 +26,47 @@ func InitMindMapStorage() {
 	db := database.ForgeDB()
 
 	// 自动迁移思维导图表
-	if err := db.AutoMigrate(&po.MindMapPO{}); err != nil {
+	if err := database.AutoMigrate(&po.MindMapPO{}); err != nil {
 		panic(fmt.Sprintf("failed to auto migrate mindmap table: %v", err))
 	}
+	// 标题与节点文本的全文索引，ngram 分词以支持中文；gorm 标签无法在不调整其他字段的情况下声明联合全文索引，这里单独创建
+	if database.AutoMigrateEnabled() && !db.Migrator().HasIndex(&po.MindMapPO{}, "ft_title_content") {
+		if err := db.Exec("CREATE FULLTEXT INDEX ft_title_content ON achobeta_forge_mindmap (title, content) WITH PARSER ngram").Error; err != nil {
+			panic(fmt.Sprintf("failed to create mindmap fulltext index: %v", err))
+		}
+	}
 
 	mmp = &mindMapPersistence{
 		db: db,
 	}
+	if err := mmp.fillSearchContent(); err != nil {
+		panic(fmt.Sprintf("failed to fill mindmap search content: %v", err))
+	}
+}
+
+// fillSearchContent 为新增 content 列之前创建的导图补全节点文本，已补全的导图不会重复处理
+func (m *mindMapPersistence) fillSearchContent() error {
+	for {
+		var mindmapPOs []po.MindMapPO
+		if err := m.db.Select("id", "map_id", "data").Where("content IS NULL").Limit(200).Find(&mindmapPOs).Error; err != nil {
+			return err
+		}
+		if len(mindmapPOs) == 0 {
+			return nil
+		}
+		for _, mindmapPO := range mindmapPOs {
+			// 数据无法解析的导图按没有节点文本处理，避免每次启动重复处理
+			var content string
+			var data entity.MindMapData
+			if err := json.Unmarshal([]byte(mindmapPO.Data), &data); err == nil {
+				content = data.SearchContent()
+			}
+			// 不更新 updated_at
+			if err := m.db.Model(&po.MindMapPO{}).Where("id = ?", mindmapPO.ID).UpdateColumn("content", content).Error; err != nil {
+				return err
+			}
+		}
+	}
 }
 
 func GetMindMapPersistence() repo.IMindMapRepo {
@@ -43,7 +79,7 @@ func (m *mindMapPersistence) CreateMindMap(ctx context.Context, mindmap *entity.
 	if err != nil {
 		return fmt.Errorf("convert mindmap to PO failed: %w", err)
 	}
-	if err := m.db.WithContext(ctx).Create(mindmapPO).Error; err != nil {
+	if err := database.Conn(ctx, m.db).Create(mindmapPO).Error; err != nil {
 		return fmt.Errorf("create mindmap failed: %w", err)
 	}
 	return nil
@@ -53,7 +89,7 @@ func (m *mindMapPersistence) CreateMindMap(ctx context.Context, mindmap *entity.
 func (m *mindMapPersistence) GetMindMap(ctx context.Context, query repo.MindMapQuery) (*entity.MindMap, error) {
 	var mindmapPO po.MindMapPO
 
-	db := m.db.WithContext(ctx).Where("is_deleted = 0")
+	db := database.Conn(ctx, m.db).Where("is_deleted = 0")
 
 	// 必须有UserID
 	if query.UserID == "" {
@@ -82,37 +118,55 @@ func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMa
 	var mindmapPOs []po.MindMapPO
 	var total int64
 
-	db := m.db.WithContext(ctx).Where("is_deleted = 0")
-
-	// 必须有UserID
-	if query.UserID == "" {
-		return nil, 0, fmt.Errorf("UserID is required")
-	}
-	db = db.Where("user_id = ?", query.UserID)
-
-	// 可选筛选条件
-	if query.Title != "" {
-		db = db.Where("title LIKE ?", "%"+query.Title+"%")
-	}
-	if query.Layout != "" {
-		db = db.Where("layout = ?", query.Layout)
+	db, err := m.filterMindMaps(ctx, query)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// 统计总数（先统计，再应用排序和分页）
-	if err := db.Model(&po.MindMapPO{}).Count(&total).Error; err != nil {
+	// 统计总数（先统计，再应用排序和分页），列表查询优先发往从库
+	err = database.Read(ctx, db, func(db *gorm.DB) error {
+		return db.Model(&po.MindMapPO{}).Count(&total).Error
+	})
+	if err != nil {
 		return nil, 0, fmt.Errorf("count mindmaps failed: %w", err)
 	}
 
-	// 先排序
-	db = db.Order("updated_at DESC")
+	// 先排序，排序字段相同时按导图ID，保证游标位置唯一；按收藏排序时先按是否收藏
+	column, value := mindMapSortColumn(query)
+	direction, compare := "DESC", "<"
+	if query.Ascending {
+		direction, compare = "ASC", ">"
+	}
+	order := fmt.Sprintf("%s %s, map_id %s", column, direction, direction)
+	starredSort := query.SortBy == repo.MindMapSortStarred
+	if starredSort {
+		// 带参数的排序表达式与普通排序列不能合并，整个排序写在同一个表达式中
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  starredMindMapExpr + " " + direction + ", " + order,
+			Vars: []interface{}{query.UserID},
+		}})
+	} else {
+		db = db.Order(order)
+	}
 
-	// 再分页
-	if query.Page > 0 && query.PageSize > 0 {
+	// 再分页，有游标时从游标之后开始
+	if query.After != nil {
+		keyset := fmt.Sprintf("(%s %s ? OR (%s = ? AND map_id %s ?))", column, compare, column, compare)
+		args := []interface{}{value, value, query.After.MapID}
+		if starredSort {
+			keyset = fmt.Sprintf("(%s %s ? OR (%s = ? AND %s))", starredMindMapExpr, compare, starredMindMapExpr, keyset)
+			args = append([]interface{}{query.UserID, query.After.Starred, query.UserID, query.After.Starred}, args...)
+		}
+		db = db.Where(keyset, args...)
+		if query.PageSize > 0 {
+			db = db.Limit(query.PageSize)
+		}
+	} else if query.Page > 0 && query.PageSize > 0 {
 		offset := (query.Page - 1) * query.PageSize
 		db = db.Offset(offset).Limit(query.PageSize)
 	}
 
-	if err := db.Find(&mindmapPOs).Error; err != nil {
+	if err := database.Read(ctx, db, func(db *gorm.DB) error { return db.Find(&mindmapPOs).Error }); err != nil {
 		return nil, 0, fmt.Errorf("list mindmaps failed: %w", err)
 	}
 
@@ -130,6 +184,150 @@ func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMa
 	return mindmaps, total, nil
 }
 
+// starredMindMapExpr 导图是否被指定用户收藏，按收藏排序时作为第一排序字段
+const starredMindMapExpr = "EXISTS (SELECT 1 FROM achobeta_forge_user_map_stars s WHERE s.user_id = ? AND s.map_id = achobeta_forge_mindmap.map_id)"
+
+// mindMapSortColumn 排序列及游标在该列上的值，未知的排序字段（包括按收藏排序）按更新时间
+func mindMapSortColumn(query repo.MindMapQuery) (string, interface{}) {
+	cursor := query.After
+	if cursor == nil {
+		cursor = &repo.MindMapCursor{}
+	}
+	switch query.SortBy {
+	case repo.MindMapSortCreatedAt:
+		return "created_at", cursor.CreatedAt
+	case repo.MindMapSortTitle:
+		return "title", cursor.Title
+	default:
+		return "updated_at", cursor.UpdatedAt
+	}
+}
+
+// SearchMindMaps 在可访问的导图中全文搜索，布尔模式下每个关键词都必须出现
+func (m *mindMapPersistence) SearchMindMaps(ctx context.Context, query repo.MindMapQuery) ([]*entity.MindMap, int64, error) {
+	var mindmapPOs []po.MindMapPO
+	var total int64
+
+	if len(query.Keywords) == 0 {
+		return nil, 0, fmt.Errorf("Keywords are required")
+	}
+	db, err := m.filterMindMaps(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	against := fulltextQuery(query.Keywords)
+	db = db.Where("MATCH(title, content) AGAINST(? IN BOOLEAN MODE)", against)
+
+	if err := db.Model(&po.MindMapPO{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count search mindmaps failed: %w", err)
+	}
+
+	// 按相关度排序，相同时最近更新的在前；带参数的排序表达式与普通排序列不能合并，需写在同一个表达式中
+	db = db.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:  "MATCH(title, content) AGAINST(? IN BOOLEAN MODE) DESC, updated_at DESC",
+		Vars: []interface{}{against},
+	}})
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	}
+	if err := db.Find(&mindmapPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("search mindmaps failed: %w", err)
+	}
+
+	mindmaps := make([]*entity.MindMap, 0, len(mindmapPOs))
+	for _, po := range mindmapPOs {
+		mindmap, err := CastMindMapPO2DO(&po)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to cast mindmap PO to DO for mapID %s: %v", po.MapID, err)
+			continue
+		}
+		mindmaps = append(mindmaps, mindmap)
+	}
+	return mindmaps, total, nil
+}
+
+// fulltextQuery 布尔模式的查询串，每个关键词加引号作为短语匹配，避免关键词中的字符被当作运算符
+func fulltextQuery(keywords []string) string {
+	terms := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		terms = append(terms, `+"`+strings.ReplaceAll(keyword, `"`, " ")+`"`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// CountMindMapTags 标签属于导图所有者，只统计符合条件的导图中自己的导图
+func (m *mindMapPersistence) CountMindMapTags(ctx context.Context, query repo.MindMapQuery) ([]*entity.MindMapTag, error) {
+	db, err := m.filterMindMaps(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	maps := db.Model(&po.MindMapPO{}).Select("map_id").Where("user_id = ?", query.UserID)
+
+	var rows []*mindMapTagCountRow
+	err = database.Conn(ctx, m.db).Model(&po.MindMapTagRelationPO{}).
+		Select("t.tag_id, t.name, t.created_at, COUNT(*) AS map_count").
+		Joins("JOIN achobeta_forge_mindmap_tag t ON t.tag_id = achobeta_forge_mindmap_map_tag.tag_id").
+		Where("achobeta_forge_mindmap_map_tag.user_id = ? AND achobeta_forge_mindmap_map_tag.map_id IN (?)", query.UserID, maps).
+		Group("t.tag_id, t.name, t.created_at").
+		Order("map_count DESC, t.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("count mindmap tags failed: %w", err)
+	}
+	return castMindMapTagCountRows(query.UserID, rows), nil
+}
+
+// filterMindMaps 按查询条件（不含排序和分页）筛选未删除的导图
+func (m *mindMapPersistence) filterMindMaps(ctx context.Context, query repo.MindMapQuery) (*gorm.DB, error) {
+	db := database.Conn(ctx, m.db).Where("is_deleted = 0")
+
+	// 必须有UserID，按范围查询自己的导图和/或作为协作者的导图
+	if query.UserID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+	memberMaps := m.db.Model(&po.MindMapMemberPO{}).Select("map_id").Where("user_id = ?", query.UserID)
+	switch query.Scope {
+	case repo.MindMapScopeShared:
+		db = db.Where("map_id IN (?)", memberMaps)
+	case repo.MindMapScopeAll:
+		db = db.Where("user_id = ? OR map_id IN (?)", query.UserID, memberMaps)
+	default:
+		db = db.Where("user_id = ?", query.UserID)
+	}
+
+	// 可选筛选条件
+	if query.Title != "" {
+		db = db.Where("title LIKE ?", "%"+query.Title+"%")
+	}
+	if query.Layout != "" {
+		db = db.Where("layout = ?", query.Layout)
+	}
+	if query.FolderID == repo.MindMapFolderRoot {
+		db = db.Where("user_id = ? AND folder_id = ''", query.UserID)
+	} else if query.FolderID != "" {
+		db = db.Where("user_id = ? AND folder_id = ?", query.UserID, query.FolderID)
+	}
+	if len(query.Tags) > 0 {
+		// 同时带有全部筛选标签的导图
+		tagged := m.db.Model(&po.MindMapTagRelationPO{}).
+			Select("achobeta_forge_mindmap_map_tag.map_id").
+			Joins("JOIN achobeta_forge_mindmap_tag t ON t.tag_id = achobeta_forge_mindmap_map_tag.tag_id").
+			Where("t.user_id = ? AND t.name IN ?", query.UserID, query.Tags).
+			Group("achobeta_forge_mindmap_map_tag.map_id").
+			Having("COUNT(DISTINCT achobeta_forge_mindmap_map_tag.tag_id) = ?", len(query.Tags))
+		db = db.Where("user_id = ? AND map_id IN (?)", query.UserID, tagged)
+	}
+	if query.Starred != nil {
+		starred := m.db.Model(&po.MindMapStarPO{}).Select("map_id").Where("user_id = ?", query.UserID)
+		if *query.Starred {
+			db = db.Where("map_id IN (?)", starred)
+		} else {
+			db = db.Where("map_id NOT IN (?)", starred)
+		}
+	}
+	return db, nil
+}
+
 // UpdateMindMap 更新思维导图
 func (m *mindMapPersistence) UpdateMindMap(ctx context.Context, updateInfo *repo.MindMapUpdateInfo) error {
 	if updateInfo.MapID == "" || updateInfo.UserID == "" {
@@ -147,19 +345,23 @@ func (m *mindMapPersistence) UpdateMindMap(ctx context.Context, updateInfo *repo
 	if updateInfo.Layout != nil {
 		updates["layout"] = *updateInfo.Layout
 	}
+	if updateInfo.FolderID != nil {
+		updates["folder_id"] = *updateInfo.FolderID
+	}
 	if updateInfo.Data != nil {
 		dataBytes, err := json.Marshal(updateInfo.Data)
 		if err != nil {
 			return fmt.Errorf("marshal data failed: %w", err)
 		}
 		updates["data"] = string(dataBytes)
+		updates["content"] = updateInfo.Data.SearchContent()
 	}
 
 	if len(updates) == 0 {
 		return nil // 没有需要更新的字段
 	}
 
-	result := m.db.WithContext(ctx).
+	result := database.Conn(ctx, m.db).
 		Model(&po.MindMapPO{}).
 		Where("map_id = ? AND user_id = ? AND is_deleted = 0", updateInfo.MapID, updateInfo.UserID).
 		Updates(updates)
@@ -181,7 +383,7 @@ func (m *mindMapPersistence) DeleteMindMap(ctx context.Context, mapID string, us
 		return fmt.Errorf("MapID and UserID are required for deletion")
 	}
 
-	result := m.db.WithContext(ctx).
+	result := database.Conn(ctx, m.db).
 		Model(&po.MindMapPO{}).
 		Where("map_id = ? AND user_id = ? AND is_deleted = 0", mapID, userID).
 		Update("is_deleted", 1)
@@ -196,3 +398,14 @@ func (m *mindMapPersistence) DeleteMindMap(ctx context.Context, mapID string, us
 
 	return nil
 }
+
+// EraseUserMindMaps 物理删除用户的所有导图
+func (m *mindMapPersistence) EraseUserMindMaps(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	if err := database.Conn(ctx, m.db).Where("user_id = ?", userID).Delete(&po.MindMapPO{}).Error; err != nil {
+		return fmt.Errorf("erase user mindmaps failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mindMapTagPersistence struct {
+	db *gorm.DB
+}
+
+var tgp *mindMapTagPersistence
+
+func InitMindMapTagStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图标签表与导图标签关系表
+	if err := database.AutoMigrate(&po.MindMapTagPO{}, &po.MindMapTagRelationPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap tag table: %v", err))
+	}
+
+	tgp = &mindMapTagPersistence{
+		db: db,
+	}
+}
+
+func GetMindMapTagPersistence() repo.IMindMapTagRepo {
+	return tgp
+}
+
+// EnsureTags 同名标签已存在时忽略，并发创建同一标签也只会保留一条
+func (m *mindMapTagPersistence) EnsureTags(ctx context.Context, tags []*entity.MindMapTag) ([]*entity.MindMapTag, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	tagPOs := make([]*po.MindMapTagPO, 0, len(tags))
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagPOs = append(tagPOs, CastMindMapTagDO2PO(tag))
+		names = append(names, tag.Name)
+	}
+	if err := database.Conn(ctx, m.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&tagPOs).Error; err != nil {
+		return nil, fmt.Errorf("create mindmap tags failed: %w", err)
+	}
+
+	var existing []*po.MindMapTagPO
+	if err := database.Conn(ctx, m.db).Where("user_id = ? AND name IN ?", tags[0].UserID, names).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("get mindmap tags failed: %w", err)
+	}
+	result := make([]*entity.MindMapTag, 0, len(existing))
+	for _, tagPO := range existing {
+		result = append(result, CastMindMapTagPO2DO(tagPO))
+	}
+	return result, nil
+}
+
+func (m *mindMapTagPersistence) GetTagByName(ctx context.Context, userID, name string) (*entity.MindMapTag, error) {
+	var tagPO po.MindMapTagPO
+	if err := database.Conn(ctx, m.db).Where("user_id = ? AND name = ?", userID, name).First(&tagPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap tag failed: %w", err)
+	}
+	return CastMindMapTagPO2DO(&tagPO), nil
+}
+
+// ListTags 导图数只统计未删除的导图
+func (m *mindMapTagPersistence) ListTags(ctx context.Context, userID string) ([]*entity.MindMapTag, error) {
+	var rows []*mindMapTagCountRow
+	err := database.Conn(ctx, m.db).Model(&po.MindMapTagPO{}).
+		Select("achobeta_forge_mindmap_tag.tag_id, achobeta_forge_mindmap_tag.name, achobeta_forge_mindmap_tag.created_at, COUNT(m.map_id) AS map_count").
+		Joins("LEFT JOIN achobeta_forge_mindmap_map_tag r ON r.tag_id = achobeta_forge_mindmap_tag.tag_id").
+		Joins("LEFT JOIN achobeta_forge_mindmap m ON m.map_id = r.map_id AND m.is_deleted = 0").
+		Where("achobeta_forge_mindmap_tag.user_id = ?", userID).
+		Group("achobeta_forge_mindmap_tag.tag_id, achobeta_forge_mindmap_tag.name, achobeta_forge_mindmap_tag.created_at").
+		Order("achobeta_forge_mindmap_tag.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list mindmap tags failed: %w", err)
+	}
+	return castMindMapTagCountRows(userID, rows), nil
+}
+
+func (m *mindMapTagPersistence) CountTags(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, m.db).Model(&po.MindMapTagPO{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count mindmap tags failed: %w", err)
+	}
+	return count, nil
+}
+
+func (m *mindMapTagPersistence) SetMapTags(ctx context.Context, userID, mapID string, tagIDs []string) error {
+	return database.Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("map_id = ?", mapID).Delete(&po.MindMapTagRelationPO{}).Error; err != nil {
+			return fmt.Errorf("clear mindmap tags failed: %w", err)
+		}
+		if len(tagIDs) > 0 {
+			relations := make([]*po.MindMapTagRelationPO, 0, len(tagIDs))
+			for _, tagID := range tagIDs {
+				relations = append(relations, &po.MindMapTagRelationPO{MapID: mapID, TagID: tagID, UserID: userID})
+			}
+			if err := tx.Create(&relations).Error; err != nil {
+				return fmt.Errorf("create mindmap tag relations failed: %w", err)
+			}
+		}
+		return deleteUnusedTags(tx, userID)
+	})
+}
+
+func (m *mindMapTagPersistence) RemoveMapTag(ctx context.Context, userID, mapID, tagID string) error {
+	return database.Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("map_id = ? AND tag_id = ?", mapID, tagID).Delete(&po.MindMapTagRelationPO{})
+		if result.Error != nil {
+			return fmt.Errorf("remove mindmap tag failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return repo.ErrMindMapTagNotFound
+		}
+		return deleteUnusedTags(tx, userID)
+	})
+}
+
+// deleteUnusedTags 删除用户不再被任何导图使用的标签
+func deleteUnusedTags(tx *gorm.DB, userID string) error {
+	used := tx.Model(&po.MindMapTagRelationPO{}).Select("tag_id").Where("user_id = ?", userID)
+	if err := tx.Where("user_id = ? AND tag_id NOT IN (?)", userID, used).Delete(&po.MindMapTagPO{}).Error; err != nil {
+		return fmt.Errorf("delete unused mindmap tags failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapTagPersistence) ListMapTagNames(ctx context.Context, mapIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(mapIDs))
+	if len(mapIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		MapID string
+		Name  string
+	}
+	err := database.Conn(ctx, m.db).Model(&po.MindMapTagRelationPO{}).
+		Select("achobeta_forge_mindmap_map_tag.map_id, t.name").
+		Joins("JOIN achobeta_forge_mindmap_tag t ON t.tag_id = achobeta_forge_mindmap_map_tag.tag_id").
+		Where("achobeta_forge_mindmap_map_tag.map_id IN ?", mapIDs).
+		Order("t.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list mindmap tag names failed: %w", err)
+	}
+	for _, row := range rows {
+		result[row.MapID] = append(result[row.MapID], row.Name)
+	}
+	return result, nil
+}
+
+func (m *mindMapTagPersistence) DeleteUserTags(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required for erasure")
+	}
+	return database.Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&po.MindMapTagRelationPO{}).Error; err != nil {
+			return fmt.Errorf("delete user mindmap tag relations failed: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&po.MindMapTagPO{}).Error; err != nil {
+			return fmt.Errorf("delete user mindmap tags failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// mindMapTagCountRow 标签及其导图数的查询结果
+type mindMapTagCountRow struct {
+	TagID     string
+	Name      string
+	CreatedAt time.Time
+	MapCount  int64
+}
+
+func castMindMapTagCountRows(userID string, rows []*mindMapTagCountRow) []*entity.MindMapTag {
+	tags := make([]*entity.MindMapTag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, &entity.MindMapTag{
+			TagID:     row.TagID,
+			UserID:    userID,
+			Name:      row.Name,
+			MapCount:  row.MapCount,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return tags
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+
+	"gorm.io/gorm"
+)
+
+type mindMapTemplatePersistence struct {
+	db *gorm.DB
+}
+
+var mtp *mindMapTemplatePersistence
+
+func InitMindMapTemplateStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移导图模板表
+	if err := database.AutoMigrate(&po.MindMapTemplatePO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate mindmap template table: %v", err))
+	}
+
+	mtp = &mindMapTemplatePersistence{
+		db: db,
+	}
+}
+
+func GetMindMapTemplatePersistence() repo.IMindMapTemplateRepo {
+	return mtp
+}
+
+func (m *mindMapTemplatePersistence) CreateTemplate(ctx context.Context, template *entity.MindMapTemplate) error {
+	templatePO, err := CastMindMapTemplateDO2PO(template)
+	if err != nil {
+		return err
+	}
+	if err := database.Conn(ctx, m.db).Create(templatePO).Error; err != nil {
+		return fmt.Errorf("create mindmap template failed: %w", err)
+	}
+	return nil
+}
+
+func (m *mindMapTemplatePersistence) UpdateTemplate(ctx context.Context, template *entity.MindMapTemplate) error {
+	templatePO, err := CastMindMapTemplateDO2PO(template)
+	if err != nil {
+		return err
+	}
+	// Sort 为 0 时同样需要写入，用 Select 指定列避免零值被忽略
+	result := database.Conn(ctx, m.db).Model(&po.MindMapTemplatePO{}).
+		Where("template_id = ?", template.TemplateID).
+		Select("title", "desc", "category", "layout", "data", "sort", "updated_at").
+		Updates(templatePO)
+	if result.Error != nil {
+		return fmt.Errorf("update mindmap template failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapTemplateNotFound
+	}
+	return nil
+}
+
+func (m *mindMapTemplatePersistence) GetTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error) {
+	var templatePO po.MindMapTemplatePO
+	if err := database.Conn(ctx, m.db).Where("template_id = ?", templateID).First(&templatePO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap template failed: %w", err)
+	}
+	return CastMindMapTemplatePO2DO(&templatePO)
+}
+
+func (m *mindMapTemplatePersistence) ListTemplates(ctx context.Context, category string) ([]*entity.MindMapTemplate, error) {
+	db := database.Conn(ctx, m.db)
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+	var templatePOs []*po.MindMapTemplatePO
+	if err := db.Order("category ASC, sort ASC, created_at ASC").Find(&templatePOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap templates failed: %w", err)
+	}
+
+	templates := make([]*entity.MindMapTemplate, 0, len(templatePOs))
+	for _, templatePO := range templatePOs {
+		template, err := CastMindMapTemplatePO2DO(templatePO)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to cast mindmap template PO to DO for templateID %s: %v", templatePO.TemplateID, err)
+			continue // 跳过转换失败的记录
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (m *mindMapTemplatePersistence) ListCategories(ctx context.Context) ([]string, error) {
+	var categories []string
+	err := database.Conn(ctx, m.db).Model(&po.MindMapTemplatePO{}).
+		Distinct("category").Order("category ASC").Pluck("category", &categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("list mindmap template categories failed: %w", err)
+	}
+	return categories, nil
+}
+
+func (m *mindMapTemplatePersistence) DeleteTemplate(ctx context.Context, templateID string) error {
+	result := database.Conn(ctx, m.db).Where("template_id = ?", templateID).Delete(&po.MindMapTemplatePO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap template failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapTemplateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type outboxPersistence struct {
+	db *gorm.DB
+}
+
+var obp *outboxPersistence
+
+func InitOutboxStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移事件发件箱表
+	if err := database.AutoMigrate(&po.OutboxEventPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate outbox event table: %v", err))
+	}
+
+	obp = &outboxPersistence{
+		db: db,
+	}
+}
+
+func GetOutboxPersistence() repo.OutboxRepo {
+	return obp
+}
+
+// AddEvent 写入待投递事件，ctx 中有事务时随事务提交
+func (o *outboxPersistence) AddEvent(ctx context.Context, event *entity.OutboxEvent) error {
+	eventPO := CastOutboxEventDO2PO(event)
+	if err := database.Conn(ctx, o.db).Create(eventPO).Error; err != nil {
+		return fmt.Errorf("add outbox event failed: %w", err)
+	}
+	event.ID = eventPO.ID
+	return nil
+}
+
+// ListPendingEvents 按写入顺序获取未投递且未放弃投递的事件
+func (o *outboxPersistence) ListPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var eventPOs []*po.OutboxEventPO
+	if err := database.Conn(ctx, o.db).
+		Where("published_at IS NULL AND failed_at IS NULL").
+		Order("id ASC").Limit(limit).Find(&eventPOs).Error; err != nil {
+		return nil, fmt.Errorf("list pending outbox events failed: %w", err)
+	}
+	events := make([]*entity.OutboxEvent, 0, len(eventPOs))
+	for _, eventPO := range eventPOs {
+		events = append(events, CastOutboxEventPO2DO(eventPO))
+	}
+	return events, nil
+}
+
+// MarkEventPublished 标记事件已投递
+func (o *outboxPersistence) MarkEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	if err := database.Conn(ctx, o.db).Model(&po.OutboxEventPO{}).Where("id = ?", id).
+		Update("published_at", publishedAt).Error; err != nil {
+		return fmt.Errorf("mark outbox event published failed: %w", err)
+	}
+	return nil
+}
+
+// MarkEventFailed 失败次数加一并记录错误与下次重试时间
+func (o *outboxPersistence) MarkEventFailed(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error {
+	if err := database.Conn(ctx, o.db).Model(&po.OutboxEventPO{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error; err != nil {
+		return fmt.Errorf("mark outbox event failed failed: %w", err)
+	}
+	return nil
+}
+
+// MarkEventDead 失败次数加一并记录错误，标记放弃投递
+func (o *outboxPersistence) MarkEventDead(ctx context.Context, id uint64, lastError string, failedAt time.Time) error {
+	if err := database.Conn(ctx, o.db).Model(&po.OutboxEventPO{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+		"failed_at":  failedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("mark outbox event dead failed: %w", err)
+	}
+	return nil
+}
+
+// PurgePublishedEvents 删除投递时间早于 before 的事件
+func (o *outboxPersistence) PurgePublishedEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := database.Conn(ctx, o.db).Where("published_at IS NOT NULL AND published_at < ?", before).
+		Limit(limit).Delete(&po.OutboxEventPO{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("purge published outbox events failed: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type passwordHistoryPersistence struct {
+	db *gorm.DB
+}
+
+var php *passwordHistoryPersistence
+
+func InitPasswordHistoryStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移历史密码表
+	if err := database.AutoMigrate(&po.PasswordHistoryPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate password history table: %v", err))
+	}
+
+	php = &passwordHistoryPersistence{
+		db: db,
+	}
+}
+
+func GetPasswordHistoryPersistence() repo.PasswordHistoryRepo {
+	return php
+}
+
+// AddPasswordHistory 记录一次密码设置
+func (p *passwordHistoryPersistence) AddPasswordHistory(ctx context.Context, history *entity.PasswordHistory) error {
+	if err := database.Conn(ctx, p.db).Create(CastPasswordHistoryDO2PO(history)).Error; err != nil {
+		return fmt.Errorf("add password history failed: %w", err)
+	}
+	return nil
+}
+
+// ListPasswordHistory 获取用户最近设置的密码
+func (p *passwordHistoryPersistence) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]*entity.PasswordHistory, error) {
+	var historyPOs []*po.PasswordHistoryPO
+	err := database.Conn(ctx, p.db).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&historyPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list password history failed: %w", err)
+	}
+
+	histories := make([]*entity.PasswordHistory, 0, len(historyPOs))
+	for _, historyPO := range historyPOs {
+		histories = append(histories, CastPasswordHistoryPO2DO(historyPO))
+	}
+	return histories, nil
+}
+
+// PrunePasswordHistory 删除超出保留条数的旧记录
+func (p *passwordHistoryPersistence) PrunePasswordHistory(ctx context.Context, userID string, keep int) error {
+	// 找到第 keep+1 新的记录，它及更早的记录都可以删除
+	var oldest po.PasswordHistoryPO
+	err := database.Conn(ctx, p.db).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Offset(keep).
+		Take(&oldest).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("find password history to prune failed: %w", err)
+	}
+
+	err = database.Conn(ctx, p.db).
+		Where("user_id = ? AND id <= ?", userID, oldest.ID).
+		Delete(&po.PasswordHistoryPO{}).Error
+	if err != nil {
+		return fmt.Errorf("prune password history failed: %w", err)
+	}
+	return nil
+}
+
+func (p *passwordHistoryPersistence) DeleteUserPasswordHistory(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, p.db).Where("user_id = ?", userID).Delete(&po.PasswordHistoryPO{}).Error; err != nil {
+		return fmt.Errorf("delete password history failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package po
+
+import (
+	"time"
+)
+
+// AiUsagePO AI token 用量持久化对象
+type AiUsagePO struct {
+	ID               uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RecordID         string    `gorm:"column:record_id;type:varchar(64);uniqueIndex" json:"record_id"`
+	UserID           string    `gorm:"column:user_id;type:varchar(64);index:idx_user_created" json:"user_id"`
+	Scene            string    `gorm:"column:scene;type:varchar(32)" json:"scene"`
+	Provider         string    `gorm:"column:provider;type:varchar(64)" json:"provider"`
+	Model            string    `gorm:"column:model;type:varchar(128)" json:"model"`
+	PromptTokens     int64     `gorm:"column:prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64     `gorm:"column:completion_tokens" json:"completion_tokens"`
+	CreatedAt        time.Time `gorm:"column:created_at;index:idx_user_created" json:"created_at"`
+}
+
+func (AiUsagePO) TableName() string {
+	return "achobeta_forge_ai_usage"
+}
@@ -12,9 +12,16 @@ type ConversationPO struct {
 	UserID         string         `gorm:"column:user_id;not null"`
 	MapID          string         `gorm:"column:map_id;not null"`
 	Title          string         `gorm:"column:title;not null"`
+	PresetID       string         `gorm:"column:preset_id;type:varchar(64)"` // 会话使用的对话预设，为空表示默认
 	Messages       datatypes.JSON `gorm:"column:messages;type:json"`
+	IsArchived     int8           `gorm:"column:is_archived;default:0"` // 已归档：1，归档的会话不出现在会话列表中
+	ArchivedAt     *time.Time     `gorm:"column:archived_at"`
+	IsPinned       int8           `gorm:"column:is_pinned;default:0"`  // 已置顶：1，置顶的会话排在列表最前面
+	SortOrder      int            `gorm:"column:sort_order;default:0"` // 用户手动调整的顺序，从小到大排列，0 表示未调整
 	CreatedAt      time.Time      `gorm:"column:created_at"`
 	UpdatedAt      time.Time      `gorm:"column:updated_at"`
+	IsDeleted      int8           `gorm:"column:is_deleted;default:0;index"` // 已删除：1
+	DeletedAt      *time.Time     `gorm:"column:deleted_at"`                 // 软删除时间，用于恢复期限判断与定时清理
 }
 
 func (ConversationPO) TableName() string {
@@ -0,0 +1,23 @@
+package po
+
+import (
+	"time"
+)
+
+// AuditLogPO 安全事件持久化对象
+type AuditLogPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	LogID     string    `gorm:"column:log_id;type:varchar(64);uniqueIndex" json:"log_id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(64);index:idx_user_created" json:"user_id"`
+	ActorID   string    `gorm:"column:actor_id;type:varchar(64);index" json:"actor_id"`
+	Action    string    `gorm:"column:action;type:varchar(32);index" json:"action"`
+	Result    string    `gorm:"column:result;type:varchar(16)" json:"result"`
+	Detail    string    `gorm:"column:detail;type:varchar(255)" json:"detail"`
+	IP        string    `gorm:"column:ip;type:varchar(64)" json:"ip"`
+	UserAgent string    `gorm:"column:user_agent;type:varchar(512)" json:"user_agent"`
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_user_created" json:"created_at"`
+}
+
+func (AuditLogPO) TableName() string {
+	return "achobeta_forge_audit_log"
+}
@@ -0,0 +1,30 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BackupPO 备份记录持久化对象
+type BackupPO struct {
+	ID                uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	BackupID          string     `gorm:"column:backup_id;type:varchar(64);uniqueIndex" json:"backup_id"`
+	UserID            string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	ResourcePath      string     `gorm:"column:resource_path;type:varchar(512)" json:"resource_path"`
+	Size              int64      `gorm:"column:size" json:"size"`
+	SHA256            string     `gorm:"column:sha256;type:char(64)" json:"sha256"`
+	MindMapCount      int64      `gorm:"column:mind_map_count" json:"mind_map_count"`
+	ConversationCount int64      `gorm:"column:conversation_count" json:"conversation_count"`
+	CreatedAt         *time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+func (BackupPO) TableName() string {
+	return "achobeta_forge_backup"
+}
+
+func (b *BackupPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	b.CreatedAt = &now
+	return nil
+}
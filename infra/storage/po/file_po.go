@@ -0,0 +1,35 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FilePO 文件元数据持久化对象
+type FilePO struct {
+	ID             uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	FileID         string     `gorm:"column:file_id;type:varchar(64);uniqueIndex" json:"file_id"`
+	UserID         string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	ResourcePath   string     `gorm:"column:resource_path;type:varchar(512)" json:"resource_path"`
+	URL            string     `gorm:"column:url;type:varchar(1024)" json:"url"`
+	Filename       string     `gorm:"column:filename;type:varchar(255)" json:"filename"`
+	ContentType    string     `gorm:"column:content_type;type:varchar(128)" json:"content_type"`
+	Size           int64      `gorm:"column:size" json:"size"`
+	Hash           string     `gorm:"column:hash;type:varchar(64)" json:"hash"`
+	Purpose        string     `gorm:"column:purpose;type:varchar(32)" json:"purpose"`
+	ConversationID string     `gorm:"column:conversation_id;type:varchar(64);index" json:"conversation_id"`
+	Status         string     `gorm:"column:status;type:varchar(16);default:active" json:"status"`
+	ScanResult     string     `gorm:"column:scan_result;type:varchar(255)" json:"scan_result"`
+	CreatedAt      *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (FilePO) TableName() string {
+	return "achobeta_forge_file"
+}
+
+func (f *FilePO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	f.CreatedAt = &now
+	return nil
+}
@@ -0,0 +1,33 @@
+package po
+
+import (
+	"time"
+)
+
+// InviteCodePO 邀请码持久化对象
+type InviteCodePO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Code      string     `gorm:"column:code;type:varchar(16);uniqueIndex" json:"code"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	MaxUses   int        `gorm:"column:max_uses" json:"max_uses"`
+	UsedCount int        `gorm:"column:used_count;default:0" json:"used_count"`
+	ExpiresAt *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	CreatedAt time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+func (InviteCodePO) TableName() string {
+	return "achobeta_forge_invite_code"
+}
+
+// ReferralPO 邀请关系持久化对象，每个用户最多被邀请一次
+type ReferralPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	InviterID string    `gorm:"column:inviter_id;type:varchar(64);index" json:"inviter_id"`
+	InviteeID string    `gorm:"column:invitee_id;type:varchar(64);uniqueIndex" json:"invitee_id"`
+	Code      string    `gorm:"column:code;type:varchar(16)" json:"code"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (ReferralPO) TableName() string {
+	return "achobeta_forge_referral"
+}
@@ -0,0 +1,43 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InvoicePO 账单持久化对象
+type InvoicePO struct {
+	ID                uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	InvoiceID         string     `gorm:"column:invoice_id;type:varchar(64);uniqueIndex" json:"invoice_id"`
+	UserID            string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	Product           string     `gorm:"column:product;type:varchar(64)" json:"product"`
+	Plan              string     `gorm:"column:plan;type:varchar(32)" json:"plan"`
+	PeriodDays        int        `gorm:"column:period_days" json:"period_days"`
+	Amount            int64      `gorm:"column:amount" json:"amount"`
+	Currency          string     `gorm:"column:currency;type:varchar(8)" json:"currency"`
+	Status            string     `gorm:"column:status;type:varchar(16);index" json:"status"`
+	Provider          string     `gorm:"column:provider;type:varchar(32)" json:"provider"`
+	ProviderSessionID string     `gorm:"column:provider_session_id;type:varchar(255)" json:"provider_session_id"`
+	CheckoutURL       string     `gorm:"column:checkout_url;type:varchar(1024)" json:"checkout_url"`
+	PaidAt            *time.Time `gorm:"column:paid_at" json:"paid_at"`
+	CreatedAt         *time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt         *time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (InvoicePO) TableName() string {
+	return "achobeta_forge_invoice"
+}
+
+func (i *InvoicePO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	i.CreatedAt = &now
+	i.UpdatedAt = &now
+	return nil
+}
+
+func (i *InvoicePO) BeforeUpdate(tx *gorm.DB) error {
+	now := time.Now()
+	i.UpdatedAt = &now
+	return nil
+}
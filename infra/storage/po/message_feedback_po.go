@@ -0,0 +1,26 @@
+package po
+
+import (
+	"time"
+)
+
+// MessageFeedbackPO 消息评价持久化对象
+type MessageFeedbackPO struct {
+	ID             uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ConversationID string    `gorm:"column:conversation_id;type:varchar(64);uniqueIndex:uk_conversation_message" json:"conversation_id"`
+	MessageID      string    `gorm:"column:message_id;type:varchar(32);uniqueIndex:uk_conversation_message" json:"message_id"`
+	UserID         string    `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	Rating         string    `gorm:"column:rating;type:varchar(8);index" json:"rating"`
+	Comment        string    `gorm:"column:comment;type:varchar(500)" json:"comment"`
+	Prompt         string    `gorm:"column:prompt;type:text" json:"prompt"`
+	Reply          string    `gorm:"column:reply;type:mediumtext" json:"reply"`
+	Provider       string    `gorm:"column:provider;type:varchar(64)" json:"provider"`
+	Model          string    `gorm:"column:model;type:varchar(128)" json:"model"`
+	PresetID       string    `gorm:"column:preset_id;type:varchar(64)" json:"preset_id"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;index" json:"updated_at"`
+}
+
+func (MessageFeedbackPO) TableName() string {
+	return "achobeta_forge_message_feedback"
+}
@@ -0,0 +1,24 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapActivityPO 导图变更记录持久化对象
+type MindMapActivityPO struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ActivityID string    `gorm:"column:activity_id;type:varchar(64);uniqueIndex" json:"activity_id"`
+	MapID      string    `gorm:"column:map_id;type:varchar(64);index:idx_map_created" json:"map_id"`
+	OwnerID    string    `gorm:"column:owner_id;type:varchar(64);index" json:"owner_id"`
+	ActorID    string    `gorm:"column:actor_id;type:varchar(64);index" json:"actor_id"`
+	Type       string    `gorm:"column:type;type:varchar(32)" json:"type"`
+	Path       string    `gorm:"column:path;type:varchar(1024)" json:"path"` // 节点路径，逗号分隔的下标
+	Text       string    `gorm:"column:text;type:text" json:"text"`
+	OldText    string    `gorm:"column:old_text;type:text" json:"old_text"`
+	TargetID   string    `gorm:"column:target_id;type:varchar(64)" json:"target_id"`
+	CreatedAt  time.Time `gorm:"column:created_at;index:idx_map_created" json:"created_at"`
+}
+
+func (MindMapActivityPO) TableName() string {
+	return "achobeta_forge_mindmap_activity"
+}
@@ -0,0 +1,25 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapCommentPO 导图评论持久化对象
+type MindMapCommentPO struct {
+	ID         uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	CommentID  string     `gorm:"column:comment_id;type:varchar(64);uniqueIndex" json:"comment_id"`
+	MapID      string     `gorm:"column:map_id;type:varchar(64);index:idx_map_node" json:"map_id"`
+	NodeID     string     `gorm:"column:node_id;type:varchar(64);index:idx_map_node" json:"node_id"`
+	OwnerID    string     `gorm:"column:owner_id;type:varchar(64);index" json:"owner_id"`
+	UserID     string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	Content    string     `gorm:"column:content;type:text" json:"content"`
+	Resolved   bool       `gorm:"column:resolved;default:false" json:"resolved"`
+	ResolvedBy string     `gorm:"column:resolved_by;type:varchar(64)" json:"resolved_by"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at" json:"resolved_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapCommentPO) TableName() string {
+	return "achobeta_forge_mindmap_comment"
+}
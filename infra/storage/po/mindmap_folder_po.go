@@ -0,0 +1,20 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapFolderPO 导图文件夹持久化对象
+type MindMapFolderPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	FolderID  string    `gorm:"column:folder_id;type:varchar(64);uniqueIndex" json:"folder_id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	ParentID  string    `gorm:"column:parent_id;type:varchar(64)" json:"parent_id"` // 空字符串表示顶层
+	Name      string    `gorm:"column:name;type:varchar(50)" json:"name"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapFolderPO) TableName() string {
+	return "achobeta_forge_mindmap_folder"
+}
@@ -0,0 +1,20 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapMemberPO 导图协作者持久化对象
+type MindMapMemberPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MapID     string    `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_map_user" json:"map_id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(64);uniqueIndex:uk_map_user;index" json:"user_id"`
+	OwnerID   string    `gorm:"column:owner_id;type:varchar(64);index" json:"owner_id"`
+	Role      string    `gorm:"column:role;type:varchar(16)" json:"role"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapMemberPO) TableName() string {
+	return "achobeta_forge_mindmap_members"
+}
@@ -18,6 +18,10 @@ type MindMapPO struct {
 	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt *time.Time `gorm:"column:updated_at" json:"updated_at"`
 	IsDeleted int8       `gorm:"column:is_deleted;default:0" json:"is_deleted"`
+	// 所在文件夹，空字符串表示不在文件夹中；默认值保证新增列时已有导图不在任何文件夹中
+	FolderID string `gorm:"column:folder_id;type:varchar(64);default:'';index" json:"folder_id"`
+	// 全部节点文本，每个节点一行，与标题一起建立全文索引；NULL 表示新增该列之前创建的导图，启动时补全
+	Content *string `gorm:"column:content;type:mediumtext" json:"-"`
 	// Version   int64   `gorm:"column:version" json:"version"` // TODO: 版本字段
 }
 
@@ -0,0 +1,20 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapSharePO 导图分享链接持久化对象
+type MindMapSharePO struct {
+	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ShareID      string    `gorm:"column:share_id;type:varchar(64);uniqueIndex" json:"share_id"`
+	MapID        string    `gorm:"column:map_id;type:varchar(64);index:idx_map_expires" json:"map_id"`
+	OwnerID      string    `gorm:"column:owner_id;type:varchar(64);index" json:"owner_id"`
+	PasswordHash string    `gorm:"column:password_hash;type:varchar(100)" json:"-"` // bcrypt 哈希，为空表示不需要密码
+	ExpiresAt    time.Time `gorm:"column:expires_at;index:idx_map_expires" json:"expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapSharePO) TableName() string {
+	return "achobeta_forge_mindmap_share"
+}
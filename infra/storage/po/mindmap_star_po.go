@@ -0,0 +1,17 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapStarPO 导图收藏持久化对象
+type MindMapStarPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(64);uniqueIndex:uk_user_map" json:"user_id"`
+	MapID     string    `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_user_map;index" json:"map_id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapStarPO) TableName() string {
+	return "achobeta_forge_user_map_stars"
+}
@@ -0,0 +1,30 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapTagPO 导图标签持久化对象
+type MindMapTagPO struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TagID     string    `gorm:"column:tag_id;type:varchar(64);uniqueIndex" json:"tag_id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(64);uniqueIndex:uk_user_name" json:"user_id"`
+	Name      string    `gorm:"column:name;type:varchar(20);uniqueIndex:uk_user_name" json:"name"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapTagPO) TableName() string {
+	return "achobeta_forge_mindmap_tag"
+}
+
+// MindMapTagRelationPO 导图与标签的关系
+type MindMapTagRelationPO struct {
+	ID     uint64 `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MapID  string `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_map_tag" json:"map_id"`
+	TagID  string `gorm:"column:tag_id;type:varchar(64);uniqueIndex:uk_map_tag;index" json:"tag_id"`
+	UserID string `gorm:"column:user_id;type:varchar(64);index" json:"user_id"` // 导图所有者，便于清理与注销
+}
+
+func (MindMapTagRelationPO) TableName() string {
+	return "achobeta_forge_mindmap_map_tag"
+}
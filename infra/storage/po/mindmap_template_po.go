@@ -0,0 +1,24 @@
+package po
+
+import (
+	"time"
+)
+
+// MindMapTemplatePO 导图模板持久化对象
+type MindMapTemplatePO struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TemplateID string    `gorm:"column:template_id;type:varchar(64);uniqueIndex" json:"template_id"`
+	Title      string    `gorm:"column:title;type:varchar(100)" json:"title"`
+	Desc       string    `gorm:"column:desc;type:varchar(500)" json:"desc"`
+	Category   string    `gorm:"column:category;type:varchar(20);index" json:"category"`
+	Layout     string    `gorm:"column:layout;type:varchar(50)" json:"layout"`
+	Data       string    `gorm:"column:data;type:json" json:"data"`
+	Sort       int       `gorm:"column:sort;default:0" json:"sort"`
+	CreatedBy  string    `gorm:"column:created_by;type:varchar(64)" json:"created_by"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapTemplatePO) TableName() string {
+	return "achobeta_forge_mindmap_template"
+}
@@ -0,0 +1,24 @@
+package po
+
+import (
+	"time"
+)
+
+// OutboxEventPO 事件发件箱持久化对象
+type OutboxEventPO struct {
+	ID            uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	EventID       string     `gorm:"column:event_id;type:varchar(64);uniqueIndex" json:"event_id"`
+	Topic         string     `gorm:"column:topic;type:varchar(64)" json:"topic"`
+	Key           string     `gorm:"column:event_key;type:varchar(64)" json:"event_key"`
+	Payload       string     `gorm:"column:payload;type:text" json:"payload"`
+	Attempts      int        `gorm:"column:attempts;default:0" json:"attempts"`
+	LastError     string     `gorm:"column:last_error;type:varchar(255)" json:"last_error"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at" json:"next_attempt_at"`
+	PublishedAt   *time.Time `gorm:"column:published_at;index" json:"published_at"`
+	FailedAt      *time.Time `gorm:"column:failed_at" json:"failed_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+func (OutboxEventPO) TableName() string {
+	return "achobeta_forge_outbox_event"
+}
@@ -0,0 +1,17 @@
+package po
+
+import (
+	"time"
+)
+
+// PasswordHistoryPO 历史密码持久化对象
+type PasswordHistoryPO struct {
+	ID           uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID       string    `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	PasswordHash string    `gorm:"column:password_hash;type:varchar(255)" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (PasswordHistoryPO) TableName() string {
+	return "achobeta_forge_password_history"
+}
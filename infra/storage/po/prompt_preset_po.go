@@ -0,0 +1,25 @@
+package po
+
+import (
+	"time"
+)
+
+// PromptPresetPO 对话预设持久化对象
+type PromptPresetPO struct {
+	ID               uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	PresetID         string    `gorm:"column:preset_id;type:varchar(64);uniqueIndex" json:"preset_id"`
+	OwnerID          string    `gorm:"column:owner_id;type:varchar(64);index" json:"owner_id"` // 为空表示全局预设
+	Name             string    `gorm:"column:name;type:varchar(32)" json:"name"`
+	Description      string    `gorm:"column:description;type:varchar(255)" json:"description"`
+	SystemPrompt     string    `gorm:"column:system_prompt;type:text" json:"system_prompt"`
+	Temperature      *float32  `gorm:"column:temperature" json:"temperature"`
+	TopP             *float32  `gorm:"column:top_p" json:"top_p"`
+	MaxTokens        *int      `gorm:"column:max_tokens" json:"max_tokens"`
+	FrequencyPenalty *float32  `gorm:"column:frequency_penalty" json:"frequency_penalty"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (PromptPresetPO) TableName() string {
+	return "achobeta_forge_prompt_preset"
+}
@@ -0,0 +1,30 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityPO 第三方账号绑定关系持久化对象
+// 同一平台账号只能绑定一个用户，同一用户在一个平台只能绑定一个账号
+type UserIdentityPO struct {
+	ID          uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID      string     `gorm:"column:user_id;type:varchar(64);uniqueIndex:idx_user_provider" json:"user_id"`
+	Provider    string     `gorm:"column:provider;type:varchar(32);uniqueIndex:idx_provider_uid;uniqueIndex:idx_user_provider" json:"provider"`
+	ProviderUID string     `gorm:"column:provider_uid;type:varchar(128);uniqueIndex:idx_provider_uid" json:"provider_uid"`
+	OpenID      string     `gorm:"column:open_id;type:varchar(128)" json:"open_id"`
+	NickName    string     `gorm:"column:nickname;type:varchar(128)" json:"nickname"`
+	Avatar      string     `gorm:"column:avatar;type:varchar(512)" json:"avatar"`
+	CreatedAt   *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (UserIdentityPO) TableName() string {
+	return "achobeta_forge_user_identity"
+}
+
+func (u *UserIdentityPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	u.CreatedAt = &now
+	return nil
+}
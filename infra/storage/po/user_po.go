@@ -13,13 +13,37 @@ type UserPO struct {
 	Password string `gorm:"column:password" json:"password"`
 
 	Avatar string `gorm:"column:avatar" json:"avatar"`
-	Phone  string `gorm:"column:phone" json:"phone"`
-	Email  string `gorm:"column:email" json:"email"`
+
+	// 个人资料
+	Nickname string     `gorm:"column:nickname;type:varchar(32)" json:"nickname"`
+	Bio      string     `gorm:"column:bio;type:varchar(255)" json:"bio"`
+	Gender   string     `gorm:"column:gender;type:varchar(16)" json:"gender"`
+	Birthday *time.Time `gorm:"column:birthday;type:date" json:"birthday"`
+	Location string     `gorm:"column:location;type:varchar(64)" json:"location"`
+	Phone    string     `gorm:"column:phone" json:"phone"`
+	Email    string     `gorm:"column:email" json:"email"`
 
 	// 状态信息
-	Status        int  `gorm:"column:status;default:1" json:"status"`
-	PhoneVerified bool `gorm:"column:phone_verified;default:false" json:"phone_verified"`
-	EmailVerified bool `gorm:"column:email_verified;default:false" json:"email_verified"`
+	Status        int        `gorm:"column:status;default:1" json:"status"`
+	Role          string     `gorm:"column:role;type:varchar(16);default:user" json:"role"`
+	PhoneVerified bool       `gorm:"column:phone_verified;default:false" json:"phone_verified"`
+	EmailVerified bool       `gorm:"column:email_verified;default:false" json:"email_verified"`
+	Plan          string     `gorm:"column:plan;type:varchar(32);default:free" json:"plan"`
+	PlanExpiresAt *time.Time `gorm:"column:plan_expires_at" json:"plan_expires_at"`
+
+	// 禁用
+	SuspendReason  string     `gorm:"column:suspend_reason;type:varchar(255)" json:"suspend_reason"`
+	SuspendedUntil *time.Time `gorm:"column:suspended_until" json:"suspended_until"`
+
+	// 通知偏好
+	ReminderOptOut bool       `gorm:"column:reminder_opt_out;default:false" json:"reminder_opt_out"`
+	ReminderSentAt *time.Time `gorm:"column:reminder_sent_at" json:"reminder_sent_at"`
+
+	// 注销
+	DeletionScheduledAt *time.Time `gorm:"column:deletion_scheduled_at;index" json:"deletion_scheduled_at"`
+
+	// 合并到的账号，账号被合并后同时标记为已删除
+	MergedInto string `gorm:"column:merged_into;type:varchar(64)" json:"merged_into"`
 
 	CreatedAt   *time.Time `gorm:"column:created_at" json:"create_at"`
 	UpdatedAt   *time.Time `gorm:"column:updated_at" json:"updated_at"`
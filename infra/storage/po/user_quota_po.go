@@ -0,0 +1,20 @@
+package po
+
+import (
+	"time"
+)
+
+// UserQuotaPO 用户单独配额持久化对象，字段为空表示沿用套餐配额
+type UserQuotaPO struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID          string    `gorm:"column:user_id;type:varchar(64);uniqueIndex" json:"user_id"`
+	MaxMindMaps     *int64    `gorm:"column:max_mind_maps" json:"max_mind_maps"`
+	MaxNodesPerMap  *int64    `gorm:"column:max_nodes_per_map" json:"max_nodes_per_map"`
+	MaxStorageBytes *int64    `gorm:"column:max_storage_bytes" json:"max_storage_bytes"`
+	UpdatedBy       string    `gorm:"column:updated_by;type:varchar(64)" json:"updated_by"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (UserQuotaPO) TableName() string {
+	return "achobeta_forge_user_quota"
+}
@@ -0,0 +1,24 @@
+package po
+
+import (
+	"time"
+)
+
+// UserSessionPO 登录会话持久化对象
+type UserSessionPO struct {
+	ID             uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SessionID      string     `gorm:"column:session_id;type:varchar(64);uniqueIndex" json:"session_id"`
+	UserID         string     `gorm:"column:user_id;type:varchar(64);index:idx_user_created" json:"user_id"`
+	Device         string     `gorm:"column:device;type:varchar(64)" json:"device"`
+	IP             string     `gorm:"column:ip;type:varchar(64)" json:"ip"`
+	UserAgent      string     `gorm:"column:user_agent;type:varchar(512)" json:"user_agent"`
+	RefreshTokenID string     `gorm:"column:refresh_token_id;type:varchar(64)" json:"refresh_token_id"`
+	CreatedAt      time.Time  `gorm:"column:created_at;index:idx_user_created" json:"created_at"`
+	LastActiveAt   time.Time  `gorm:"column:last_active_at" json:"last_active_at"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at" json:"expires_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+}
+
+func (UserSessionPO) TableName() string {
+	return "achobeta_forge_user_session"
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type promptPresetPersistence struct {
+	db *gorm.DB
+}
+
+var ppp *promptPresetPersistence
+
+func InitPromptPresetStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移对话预设表
+	if err := database.AutoMigrate(&po.PromptPresetPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate prompt preset table: %v", err))
+	}
+
+	ppp = &promptPresetPersistence{
+		db: db,
+	}
+}
+
+func GetPromptPresetPersistence() repo.PromptPresetRepo {
+	return ppp
+}
+
+func (p *promptPresetPersistence) CreatePreset(ctx context.Context, preset *entity.PromptPreset) error {
+	if err := database.Conn(ctx, p.db).Create(CastPromptPresetDO2PO(preset)).Error; err != nil {
+		return fmt.Errorf("create prompt preset failed: %w", err)
+	}
+	return nil
+}
+
+func (p *promptPresetPersistence) UpdatePreset(ctx context.Context, preset *entity.PromptPreset) error {
+	presetPO := CastPromptPresetDO2PO(preset)
+	// 模型参数为 nil 时同样需要写入，用 Select 指定列避免零值被忽略
+	err := database.Conn(ctx, p.db).Model(&po.PromptPresetPO{}).
+		Where("preset_id = ?", preset.PresetID).
+		Select("name", "description", "system_prompt", "temperature", "top_p", "max_tokens", "frequency_penalty", "updated_at").
+		Updates(presetPO).Error
+	if err != nil {
+		return fmt.Errorf("update prompt preset failed: %w", err)
+	}
+	return nil
+}
+
+func (p *promptPresetPersistence) GetPreset(ctx context.Context, presetID string) (*entity.PromptPreset, error) {
+	var presetPO po.PromptPresetPO
+	err := database.Conn(ctx, p.db).Where("preset_id = ?", presetID).First(&presetPO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get prompt preset failed: %w", err)
+	}
+	return CastPromptPresetPO2DO(&presetPO), nil
+}
+
+func (p *promptPresetPersistence) ListPresets(ctx context.Context, ownerID string) ([]*entity.PromptPreset, error) {
+	var presetPOs []po.PromptPresetPO
+	err := database.Conn(ctx, p.db).
+		Where("owner_id = '' OR owner_id = ?", ownerID).
+		Order("owner_id ASC, created_at ASC").
+		Find(&presetPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list prompt presets failed: %w", err)
+	}
+
+	presets := make([]*entity.PromptPreset, 0, len(presetPOs))
+	for i := range presetPOs {
+		presets = append(presets, CastPromptPresetPO2DO(&presetPOs[i]))
+	}
+	return presets, nil
+}
+
+func (p *promptPresetPersistence) CountUserPresets(ctx context.Context, ownerID string) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, p.db).Model(&po.PromptPresetPO{}).Where("owner_id = ?", ownerID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count prompt presets failed: %w", err)
+	}
+	return count, nil
+}
+
+func (p *promptPresetPersistence) DeletePreset(ctx context.Context, presetID string) error {
+	if err := database.Conn(ctx, p.db).Where("preset_id = ?", presetID).Delete(&po.PromptPresetPO{}).Error; err != nil {
+		return fmt.Errorf("delete prompt preset failed: %w", err)
+	}
+	return nil
+}
+
+func (p *promptPresetPersistence) DeleteUserPresets(ctx context.Context, ownerID string) error {
+	if ownerID == "" {
+		// 空的 ownerID 会匹配全局预设
+		return nil
+	}
+	if err := database.Conn(ctx, p.db).Where("owner_id = ?", ownerID).Delete(&po.PromptPresetPO{}).Error; err != nil {
+		return fmt.Errorf("delete user prompt presets failed: %w", err)
+	}
+	return nil
+}
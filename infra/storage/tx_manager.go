@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+
+	"forge/biz/repo"
+	"forge/infra/database"
+)
+
+// txManager 基于数据库事务的事务管理，仓储通过 database.Conn 使用 ctx 中的事务
+type txManager struct{}
+
+var txm = &txManager{}
+
+func GetTxManager() repo.TxManager {
+	return txm
+}
+
+// WithinTransaction 在一个数据库事务内执行 fn
+func (t *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.WithinTransaction(ctx, fn)
+}
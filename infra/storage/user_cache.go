@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/constant"
+	"forge/infra/cache"
+	"forge/infra/configs"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+)
+
+// cachedUserPersistence 用户仓储的缓存装饰器：按用户ID查询时先读 Redis，未命中再查数据库并回填
+// 修改、删除用户后清除缓存；批量降级套餐不逐个清除，套餐到期由 User.GetPlan 判断，不受缓存影响
+// 其他查询直接透传给数据库
+type cachedUserPersistence struct {
+	repo.UserRepo
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+var ucp *cachedUserPersistence
+
+// UserCacheStats 用户缓存命中统计，从启动开始累计
+type UserCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRate 命中率，没有请求时为 0
+func (s UserCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// InitUserCache 开启用户缓存，需在 InitUserStorage 之后、获取用户仓储之前调用
+func InitUserCache(conf configs.UserCacheConfig) {
+	if !conf.Enable {
+		return
+	}
+	ucp = &cachedUserPersistence{
+		UserRepo: up,
+		ttl:      conf.TTL(),
+	}
+}
+
+// GetUserCacheStats 获取用户缓存命中统计，未开启缓存时返回 false
+func GetUserCacheStats() (UserCacheStats, bool) {
+	if ucp == nil {
+		return UserCacheStats{}, false
+	}
+	return UserCacheStats{Hits: ucp.hits.Load(), Misses: ucp.misses.Load()}, true
+}
+
+// cachedUser 缓存中的用户，不含密码哈希，只记录是否设置了密码，避免能读取 Redis 即可拿到所有活跃用户的密码哈希
+type cachedUser struct {
+	*po.UserPO
+	PasswordSet bool `json:"password_set"`
+}
+
+// GetUser 只缓存按用户ID的查询，Redis 不可用时直接查询数据库
+// 事务中的查询与需要密码哈希的查询不读写缓存，事务内可能读到未提交的修改
+func (c *cachedUserPersistence) GetUser(ctx context.Context, query repo.UserQuery) (*entity.User, error) {
+	if query.UserID == "" || query.WithPassword || database.InTransaction(ctx) {
+		return c.UserRepo.GetUser(ctx, query)
+	}
+
+	key := fmt.Sprintf(constant.REDIS_USER_CACHE_KEY, query.UserID)
+	// 缓存存储对象而不是实体，实体的部分字段不参与序列化；用户不存在时不缓存
+	value, cached, err := cache.GetOrLoad(ctx, key, c.ttl, func(ctx context.Context) (*cachedUser, error) {
+		// 修改用户后会清除缓存，回填时读主库，避免把从库延迟的旧数据写入缓存
+		user, err := c.UserRepo.GetUser(database.WithPrimary(ctx), repo.UserQuery{UserID: query.UserID})
+		if err != nil || user == nil {
+			return nil, err
+		}
+		// 存储对象转换时不带创建/更新时间，这里补上
+		userPO := CastUserDO2PO(user)
+		userPO.CreatedAt, userPO.UpdatedAt = &user.CreatedAt, &user.UpdatedAt
+		userPO.Password = ""
+		return &cachedUser{UserPO: userPO, PasswordSet: user.HasPassword()}, nil
+	})
+	if cached {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	if err != nil || value == nil || value.UserPO == nil {
+		return nil, err
+	}
+	// 并发请求共享同一个存储对象，每次转换出新的实体返回
+	user := CastUserPO2DO(value.UserPO)
+	user.PasswordSet = value.PasswordSet
+	return user, nil
+}
+
+// UpdateUser 修改成功后清除缓存，下次查询重新从数据库加载
+func (c *cachedUserPersistence) UpdateUser(ctx context.Context, updateInfo *repo.UserUpdateInfo) error {
+	if err := c.UserRepo.UpdateUser(ctx, updateInfo); err != nil {
+		return err
+	}
+	c.invalidate(ctx, updateInfo.UserID)
+	return nil
+}
+
+// EraseUser 删除用户后清除缓存
+func (c *cachedUserPersistence) EraseUser(ctx context.Context, userID string) error {
+	if err := c.UserRepo.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+	c.invalidate(ctx, userID)
+	return nil
+}
+
+// MergeUser 合并后清除两个账号的缓存
+func (c *cachedUserPersistence) MergeUser(ctx context.Context, merge *repo.UserMerge) (*repo.UserMergeResult, error) {
+	result, err := c.UserRepo.MergeUser(ctx, merge)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, merge.FromUserID)
+	c.invalidate(ctx, merge.Target.UserID)
+	return result, nil
+}
+
+// invalidate 清除缓存，在事务中时等事务提交后再清除
+func (c *cachedUserPersistence) invalidate(ctx context.Context, userID string) {
+	key := fmt.Sprintf(constant.REDIS_USER_CACHE_KEY, userID)
+	database.AfterCommit(ctx, func() {
+		if err := cache.DelRedis(ctx, key); err != nil {
+			zlog.CtxErrorf(ctx, "invalidate user cache failed, userID: %s, err: %v", userID, err)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type userIdentityPersistence struct {
+	db *gorm.DB
+}
+
+var uip *userIdentityPersistence
+
+func InitUserIdentityStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移第三方账号绑定表
+	if err := database.AutoMigrate(&po.UserIdentityPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate user identity table: %v", err))
+	}
+
+	uip = &userIdentityPersistence{
+		db: db,
+	}
+}
+
+func GetUserIdentityPersistence() repo.UserIdentityRepo {
+	return uip
+}
+
+// CreateIdentity 绑定第三方账号
+func (u *userIdentityPersistence) CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	identityPO := CastUserIdentityDO2PO(identity)
+	if err := database.Conn(ctx, u.db).Create(identityPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return repo.ErrIdentityAlreadyBound
+		}
+		return fmt.Errorf("create user identity failed: %w", err)
+	}
+	return nil
+}
+
+// GetIdentity 获取绑定关系
+func (u *userIdentityPersistence) GetIdentity(ctx context.Context, provider, providerUID string) (*entity.UserIdentity, error) {
+	var identityPO po.UserIdentityPO
+	err := database.Conn(ctx, u.db).
+		Where("provider = ? AND provider_uid = ?", provider, providerUID).
+		First(&identityPO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get user identity failed: %w", err)
+	}
+	return CastUserIdentityPO2DO(&identityPO), nil
+}
+
+// ListIdentities 获取用户绑定的所有第三方账号
+func (u *userIdentityPersistence) ListIdentities(ctx context.Context, userID string) ([]*entity.UserIdentity, error) {
+	var identityPOs []*po.UserIdentityPO
+	err := database.Conn(ctx, u.db).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&identityPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list user identities failed: %w", err)
+	}
+	return CastUserIdentityPOs2DOs(identityPOs), nil
+}
+
+// DeleteIdentity 解绑第三方账号
+func (u *userIdentityPersistence) DeleteIdentity(ctx context.Context, userID, provider string) error {
+	err := database.Conn(ctx, u.db).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&po.UserIdentityPO{}).Error
+	if err != nil {
+		return fmt.Errorf("delete user identity failed: %w", err)
+	}
+	return nil
+}
+
+func (u *userIdentityPersistence) DeleteUserIdentities(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, u.db).Where("user_id = ?", userID).Delete(&po.UserIdentityPO{}).Error; err != nil {
+		return fmt.Errorf("delete user identities failed: %w", err)
+	}
+	return nil
+}
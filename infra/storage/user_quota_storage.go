@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type userQuotaPersistence struct {
+	db *gorm.DB
+}
+
+var uqp *userQuotaPersistence
+
+func InitUserQuotaStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移用户单独配额表
+	if err := database.AutoMigrate(&po.UserQuotaPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate user quota table: %v", err))
+	}
+
+	uqp = &userQuotaPersistence{
+		db: db,
+	}
+}
+
+func GetUserQuotaPersistence() repo.UserQuotaRepo {
+	return uqp
+}
+
+func (u *userQuotaPersistence) GetUserQuota(ctx context.Context, userID string) (*entity.UserQuotaOverride, error) {
+	var quotaPO po.UserQuotaPO
+	if err := database.Conn(ctx, u.db).Where("user_id = ?", userID).First(&quotaPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get user quota failed: %w", err)
+	}
+	return CastUserQuotaPO2DO(&quotaPO), nil
+}
+
+// SaveUserQuota 按用户ID覆盖，未设置的字段同样写入空值
+func (u *userQuotaPersistence) SaveUserQuota(ctx context.Context, override *entity.UserQuotaOverride) error {
+	err := database.Conn(ctx, u.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_mind_maps", "max_nodes_per_map", "max_storage_bytes", "updated_by", "updated_at"}),
+	}).Create(CastUserQuotaDO2PO(override)).Error
+	if err != nil {
+		return fmt.Errorf("save user quota failed: %w", err)
+	}
+	return nil
+}
+
+func (u *userQuotaPersistence) DeleteUserQuota(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("UserID is required")
+	}
+	if err := database.Conn(ctx, u.db).Where("user_id = ?", userID).Delete(&po.UserQuotaPO{}).Error; err != nil {
+		return fmt.Errorf("delete user quota failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/database"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+type userSessionPersistence struct {
+	db *gorm.DB
+}
+
+var usp *userSessionPersistence
+
+func InitUserSessionStorage() {
+	db := database.ForgeDB()
+
+	// 自动迁移登录会话表
+	if err := database.AutoMigrate(&po.UserSessionPO{}); err != nil {
+		panic(fmt.Sprintf("failed to auto migrate user session table: %v", err))
+	}
+
+	usp = &userSessionPersistence{
+		db: db,
+	}
+}
+
+func GetUserSessionPersistence() repo.UserSessionRepo {
+	return usp
+}
+
+// CreateSession 记录登录会话
+func (u *userSessionPersistence) CreateSession(ctx context.Context, session *entity.UserSession) error {
+	if err := database.Conn(ctx, u.db).Create(CastUserSessionDO2PO(session)).Error; err != nil {
+		return fmt.Errorf("create user session failed: %w", err)
+	}
+	return nil
+}
+
+// GetSession 获取登录会话
+func (u *userSessionPersistence) GetSession(ctx context.Context, sessionID string) (*entity.UserSession, error) {
+	var sessionPO po.UserSessionPO
+	err := database.Conn(ctx, u.db).Where("session_id = ?", sessionID).First(&sessionPO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get user session failed: %w", err)
+	}
+	return CastUserSessionPO2DO(&sessionPO), nil
+}
+
+// ListSessions 获取用户最近的登录会话
+func (u *userSessionPersistence) ListSessions(ctx context.Context, userID string, limit int) ([]*entity.UserSession, error) {
+	var sessionPOs []*po.UserSessionPO
+	err := database.Conn(ctx, u.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&sessionPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list user sessions failed: %w", err)
+	}
+
+	sessions := make([]*entity.UserSession, 0, len(sessionPOs))
+	for _, sessionPO := range sessionPOs {
+		sessions = append(sessions, CastUserSessionPO2DO(sessionPO))
+	}
+	return sessions, nil
+}
+
+// ListActiveSessions 获取用户未下线且未过期的会话
+func (u *userSessionPersistence) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]*entity.UserSession, error) {
+	var sessionPOs []*po.UserSessionPO
+	err := database.Conn(ctx, u.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Find(&sessionPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list active user sessions failed: %w", err)
+	}
+
+	sessions := make([]*entity.UserSession, 0, len(sessionPOs))
+	for _, sessionPO := range sessionPOs {
+		sessions = append(sessions, CastUserSessionPO2DO(sessionPO))
+	}
+	return sessions, nil
+}
+
+// RenewSession 续签后更新会话
+func (u *userSessionPersistence) RenewSession(ctx context.Context, sessionID, refreshTokenID string, lastActiveAt, expiresAt time.Time) error {
+	err := database.Conn(ctx, u.db).Model(&po.UserSessionPO{}).
+		Where("session_id = ?", sessionID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": refreshTokenID,
+			"last_active_at":   lastActiveAt,
+			"expires_at":       expiresAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("renew user session failed: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession 标记会话已下线，已下线的会话不重复更新
+func (u *userSessionPersistence) RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error {
+	err := database.Conn(ctx, u.db).Model(&po.UserSessionPO{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("revoke user session failed: %w", err)
+	}
+	return nil
+}
+
+func (u *userSessionPersistence) DeleteUserSessions(ctx context.Context, userID string) error {
+	if err := database.Conn(ctx, u.db).Where("user_id = ?", userID).Delete(&po.UserSessionPO{}).Error; err != nil {
+		return fmt.Errorf("delete user sessions failed: %w", err)
+	}
+	return nil
+}
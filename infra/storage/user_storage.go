@@ -7,6 +7,7 @@ import (
 	"forge/biz/repo"
 	"forge/infra/database"
 	"forge/infra/storage/po"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,7 +22,7 @@ func InitUserStorage() {
 	db := database.ForgeDB()
 
 	// 自动迁移用户表
-	if err := db.AutoMigrate(&po.UserPO{}); err != nil {
+	if err := database.AutoMigrate(&po.UserPO{}); err != nil {
 		panic(fmt.Sprintf("failed to auto migrate user table: %v", err))
 	}
 
@@ -31,13 +32,17 @@ func InitUserStorage() {
 }
 
 func GetUserPersistence() repo.UserRepo {
+	// 开启用户缓存时返回带缓存的仓储，所有调用方的修改都会清除缓存
+	if ucp != nil {
+		return ucp
+	}
 	return up
 }
 
 // CreateUser 创建用户
 func (u *userPersistence) CreateUser(ctx context.Context, user *entity.User) error {
 	userPO := CastUserDO2PO(user)
-	err := u.db.WithContext(ctx).Create(&userPO).Error
+	err := database.Conn(ctx, u.db).Create(&userPO).Error
 	if err != nil {
 		//todo 这里如何让上游更好地感知到错误类型，甚至前端感知到错误类型呢？
 		return err
@@ -53,6 +58,16 @@ func (u *userPersistence) UpdateUser(ctx context.Context, updateInfo *repo.UserU
 		return fmt.Errorf("invalid update info: userID is required") // 需要id定位用户
 	}
 
+	updates := userUpdates(updateInfo)
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return database.Conn(ctx, u.db).Model(&po.UserPO{}).Where("user_id = ?", updateInfo.UserID).Updates(updates).Error
+}
+
+// userUpdates 将更新信息转换为需要更新的列，只包含传入的字段
+func userUpdates(updateInfo *repo.UserUpdateInfo) map[string]any {
 	updates := map[string]any{}
 
 	// 基础信息
@@ -63,6 +78,27 @@ func (u *userPersistence) UpdateUser(ctx context.Context, updateInfo *repo.UserU
 		updates["avatar"] = *updateInfo.Avatar
 	}
 
+	// 个人资料
+	if updateInfo.Nickname != nil {
+		updates["nickname"] = *updateInfo.Nickname
+	}
+	if updateInfo.Bio != nil {
+		updates["bio"] = *updateInfo.Bio
+	}
+	if updateInfo.Gender != nil {
+		updates["gender"] = *updateInfo.Gender
+	}
+	if updateInfo.Birthday != nil {
+		if updateInfo.Birthday.IsZero() {
+			updates["birthday"] = nil
+		} else {
+			updates["birthday"] = *updateInfo.Birthday
+		}
+	}
+	if updateInfo.Location != nil {
+		updates["location"] = *updateInfo.Location
+	}
+
 	// 联系方式
 	if updateInfo.Phone != nil {
 		updates["phone"] = *updateInfo.Phone
@@ -80,6 +116,9 @@ func (u *userPersistence) UpdateUser(ctx context.Context, updateInfo *repo.UserU
 	if updateInfo.Status != nil {
 		updates["status"] = *updateInfo.Status
 	}
+	if updateInfo.Role != nil {
+		updates["role"] = *updateInfo.Role
+	}
 	if updateInfo.PhoneVerified != nil {
 		updates["phone_verified"] = *updateInfo.PhoneVerified
 	}
@@ -87,16 +126,143 @@ func (u *userPersistence) UpdateUser(ctx context.Context, updateInfo *repo.UserU
 		updates["email_verified"] = *updateInfo.EmailVerified
 	}
 
+	// 禁用
+	if updateInfo.SuspendReason != nil {
+		updates["suspend_reason"] = *updateInfo.SuspendReason
+	}
+	if updateInfo.SuspendedUntil != nil {
+		if updateInfo.SuspendedUntil.IsZero() {
+			updates["suspended_until"] = nil
+		} else {
+			updates["suspended_until"] = *updateInfo.SuspendedUntil
+		}
+	}
+
+	// 套餐
+	if updateInfo.Plan != nil {
+		updates["plan"] = *updateInfo.Plan
+	}
+	if updateInfo.PlanExpiresAt != nil {
+		if updateInfo.PlanExpiresAt.IsZero() {
+			updates["plan_expires_at"] = nil
+		} else {
+			updates["plan_expires_at"] = *updateInfo.PlanExpiresAt
+		}
+	}
+
 	// 时间信息
 	if updateInfo.LastLoginAt != nil {
 		updates["last_login_at"] = *updateInfo.LastLoginAt
 	}
 
-	if len(updates) == 0 {
+	// 通知偏好
+	if updateInfo.ReminderOptOut != nil {
+		updates["reminder_opt_out"] = *updateInfo.ReminderOptOut
+	}
+	if updateInfo.ReminderSentAt != nil {
+		updates["reminder_sent_at"] = *updateInfo.ReminderSentAt
+	}
+
+	// 注销
+	if updateInfo.DeletionScheduledAt != nil {
+		if updateInfo.DeletionScheduledAt.IsZero() {
+			updates["deletion_scheduled_at"] = nil
+		} else {
+			updates["deletion_scheduled_at"] = *updateInfo.DeletionScheduledAt
+		}
+	}
+
+	return updates
+}
+
+// MergeUser 合并账号，迁移导图与AI对话时不修改更新时间
+func (u *userPersistence) MergeUser(ctx context.Context, merge *repo.UserMerge) (*repo.UserMergeResult, error) {
+	if merge == nil || merge.FromUserID == "" || merge.Target == nil || merge.Target.UserID == "" {
+		return nil, fmt.Errorf("invalid user merge: userID is required")
+	}
+
+	result := &repo.UserMergeResult{}
+	err := database.Conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		// 先标记被合并账号，账号已被删除或已被并发合并时整个合并回滚
+		tombstone := tx.Model(&po.UserPO{}).
+			Where("user_id = ? AND is_deleted = 0", merge.FromUserID).
+			Updates(map[string]any{"is_deleted": 1, "merged_into": merge.Target.UserID})
+		if tombstone.Error != nil {
+			return fmt.Errorf("mark merged user failed: %w", tombstone.Error)
+		}
+		if tombstone.RowsAffected == 0 {
+			return fmt.Errorf("user %s not found or already merged", merge.FromUserID)
+		}
+
+		mindMaps := tx.Model(&po.MindMapPO{}).Where("user_id = ?", merge.FromUserID).UpdateColumn("user_id", merge.Target.UserID)
+		if mindMaps.Error != nil {
+			return fmt.Errorf("move mindmaps failed: %w", mindMaps.Error)
+		}
+		result.MindMaps = mindMaps.RowsAffected
+
+		// 协作者与分享链接按导图所有者查询导图，随导图一起迁移
+		if err := tx.Model(&po.MindMapMemberPO{}).Where("owner_id = ?", merge.FromUserID).UpdateColumn("owner_id", merge.Target.UserID).Error; err != nil {
+			return fmt.Errorf("move mindmap members failed: %w", err)
+		}
+		if err := tx.Model(&po.MindMapSharePO{}).Where("owner_id = ?", merge.FromUserID).UpdateColumn("owner_id", merge.Target.UserID).Error; err != nil {
+			return fmt.Errorf("move mindmap shares failed: %w", err)
+		}
+		if err := tx.Model(&po.MindMapFolderPO{}).Where("user_id = ?", merge.FromUserID).UpdateColumn("user_id", merge.Target.UserID).Error; err != nil {
+			return fmt.Errorf("move mindmap folders failed: %w", err)
+		}
+		if err := mergeMindMapTags(tx, merge.FromUserID, merge.Target.UserID); err != nil {
+			return err
+		}
+		if err := tx.Model(&po.MindMapActivityPO{}).Where("owner_id = ?", merge.FromUserID).UpdateColumn("owner_id", merge.Target.UserID).Error; err != nil {
+			return fmt.Errorf("move mindmap activities failed: %w", err)
+		}
+		if err := tx.Model(&po.MindMapActivityPO{}).Where("actor_id = ?", merge.FromUserID).UpdateColumn("actor_id", merge.Target.UserID).Error; err != nil {
+			return fmt.Errorf("move mindmap activities failed: %w", err)
+		}
+		if err := mergeMindMapStars(tx, merge.FromUserID, merge.Target.UserID); err != nil {
+			return err
+		}
+		for _, column := range []string{"owner_id", "user_id", "resolved_by"} {
+			if err := tx.Model(&po.MindMapCommentPO{}).Where(column+" = ?", merge.FromUserID).UpdateColumn(column, merge.Target.UserID).Error; err != nil {
+				return fmt.Errorf("move mindmap comments failed: %w", err)
+			}
+		}
+
+		conversations := tx.Model(&po.ConversationPO{}).Where("user_id = ?", merge.FromUserID).UpdateColumn("user_id", merge.Target.UserID)
+		if conversations.Error != nil {
+			return fmt.Errorf("move conversations failed: %w", conversations.Error)
+		}
+		result.Conversations = conversations.RowsAffected
+
+		if updates := userUpdates(merge.Target); len(updates) > 0 {
+			if err := tx.Model(&po.UserPO{}).Where("user_id = ?", merge.Target.UserID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("update merge target user failed: %w", err)
+			}
+		}
 		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	return u.db.WithContext(ctx).Model(&po.UserPO{}).Where("user_id = ?", updateInfo.UserID).Updates(updates).Error
+// GetUsersByIDs 一次 IN 查询批量获取用户
+func (u *userPersistence) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*entity.User, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var userPOs []*po.UserPO
+	if err := database.Conn(ctx, u.db).Where("user_id IN ? AND is_deleted = 0", userIDs).Find(&userPOs).Error; err != nil {
+		return nil, fmt.Errorf("get users by ids failed: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userPOs))
+	for _, userPO := range userPOs {
+		users = append(users, CastUserPO2DO(userPO))
+	}
+	return users, nil
 }
 
 // GetUser 用户查询接口，根据查询条件获取用户
@@ -104,11 +270,18 @@ func (u *userPersistence) GetUser(ctx context.Context, query repo.UserQuery) (*e
 	var userPO po.UserPO
 
 	// 根据查询条件构建查询
-	db := u.db.WithContext(ctx)
+	db := database.Conn(ctx, u.db)
 
-	// 唯一标识直接查
+	// 唯一标识直接查，展示用的查询优先发往从库；校验密码需要最新的哈希，读主库
 	if query.UserID != "" {
-		if err := db.Where("user_id = ? AND is_deleted = 0", query.UserID).First(&userPO).Error; err != nil {
+		readCtx := ctx
+		if query.WithPassword {
+			readCtx = database.WithPrimary(ctx)
+		}
+		err := database.Read(readCtx, db.Where("user_id = ? AND is_deleted = 0", query.UserID), func(db *gorm.DB) error {
+			return db.First(&userPO).Error
+		})
+		if err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return nil, nil
 			}
@@ -138,6 +311,7 @@ func (u *userPersistence) GetUser(ctx context.Context, query repo.UserQuery) (*e
 	// 未删除条件
 	db = db.Where("is_deleted = 0")
 
+	// 按账号查询用于登录校验与注册查重，手机号、邮箱没有唯一索引，从库延迟可能导致重复注册或用旧密码登录，只读主库
 	if err := db.First(&userPO).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -147,3 +321,153 @@ func (u *userPersistence) GetUser(ctx context.Context, query repo.UserQuery) (*e
 
 	return CastUserPO2DO(&userPO), nil
 }
+
+// DowngradeExpiredPlans 将付费套餐已到期的用户降级为免费版
+func (u *userPersistence) DowngradeExpiredPlans(ctx context.Context, now time.Time) (int64, error) {
+	result := database.Conn(ctx, u.db).Model(&po.UserPO{}).
+		Where("plan <> ? AND plan_expires_at IS NOT NULL AND plan_expires_at < ?", entity.PlanFree, now).
+		Updates(map[string]any{"plan": entity.PlanFree, "plan_expires_at": nil})
+	if result.Error != nil {
+		return 0, fmt.Errorf("downgrade expired plans failed: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListUserIDs 按用户ID顺序分批获取用户ID
+func (u *userPersistence) ListUserIDs(ctx context.Context, afterUserID string, limit int) ([]string, error) {
+	var userIDs []string
+	err := database.Conn(ctx, u.db).Model(&po.UserPO{}).
+		Where("user_id > ?", afterUserID).
+		Order("user_id ASC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list user ids failed: %w", err)
+	}
+	return userIDs, nil
+}
+
+// ListReminderCandidates 分批获取需要提醒绑定手机号的用户
+func (u *userPersistence) ListReminderCandidates(ctx context.Context, afterUserID string, sentBefore time.Time, limit int) ([]*entity.User, error) {
+	var userPOs []*po.UserPO
+	err := database.Conn(ctx, u.db).
+		Where("user_id > ? AND is_deleted = 0 AND status = ?", afterUserID, entity.UserStatusActive).
+		Where("email <> '' AND phone = '' AND reminder_opt_out = ? AND deletion_scheduled_at IS NULL", false).
+		Where("reminder_sent_at IS NULL OR reminder_sent_at < ?", sentBefore).
+		Order("user_id ASC").
+		Limit(limit).
+		Find(&userPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list reminder candidates failed: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userPOs))
+	for _, userPO := range userPOs {
+		users = append(users, CastUserPO2DO(userPO))
+	}
+	return users, nil
+}
+
+// ListUsers 分页获取用户列表
+func (u *userPersistence) ListUsers(ctx context.Context, query repo.UserListQuery) ([]*entity.User, int64, error) {
+	var userPOs []*po.UserPO
+	var total int64
+
+	db := database.Conn(ctx, u.db).Model(&po.UserPO{}).Where("is_deleted = 0")
+	if query.Keyword != "" {
+		like := "%" + query.Keyword + "%"
+		db = db.Where("username LIKE ? OR nickname LIKE ? OR phone LIKE ? OR email LIKE ?", like, like, like, like)
+	}
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+	if query.Role == entity.UserRoleUser {
+		// 旧数据没有角色，视为普通用户
+		db = db.Where("role = ? OR role = '' OR role IS NULL", query.Role)
+	} else if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count users failed: %w", err)
+	}
+
+	db = db.Order("id DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	}
+	if err := db.Find(&userPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list users failed: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userPOs))
+	for _, userPO := range userPOs {
+		users = append(users, CastUserPO2DO(userPO))
+	}
+	return users, total, nil
+}
+
+// ListDueDeletions 获取注销冷静期已到的用户
+func (u *userPersistence) ListDueDeletions(ctx context.Context, afterUserID string, before time.Time, limit int) ([]*entity.User, error) {
+	var userPOs []*po.UserPO
+	err := database.Conn(ctx, u.db).
+		Where("user_id > ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at < ?", afterUserID, before).
+		Order("user_id ASC").
+		Limit(limit).
+		Find(&userPOs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list due deletions failed: %w", err)
+	}
+
+	users := make([]*entity.User, 0, len(userPOs))
+	for _, userPO := range userPOs {
+		users = append(users, CastUserPO2DO(userPO))
+	}
+	return users, nil
+}
+
+// EraseUser 物理删除用户记录
+func (u *userPersistence) EraseUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	if err := database.Conn(ctx, u.db).Where("user_id = ?", userID).Delete(&po.UserPO{}).Error; err != nil {
+		return fmt.Errorf("erase user failed: %w", err)
+	}
+	return nil
+}
+
+// mergeMindMapTags 迁移导图标签，与目标用户同名的标签合并为目标用户的标签
+func mergeMindMapTags(tx *gorm.DB, fromUserID, toUserID string) error {
+	if err := tx.Exec("UPDATE achobeta_forge_mindmap_map_tag r "+
+		"JOIN achobeta_forge_mindmap_tag f ON f.tag_id = r.tag_id "+
+		"JOIN achobeta_forge_mindmap_tag t ON t.user_id = ? AND t.name = f.name "+
+		"SET r.tag_id = t.tag_id WHERE f.user_id = ?", toUserID, fromUserID).Error; err != nil {
+		return fmt.Errorf("merge mindmap tag relations failed: %w", err)
+	}
+	if err := tx.Exec("DELETE f FROM achobeta_forge_mindmap_tag f "+
+		"JOIN achobeta_forge_mindmap_tag t ON t.user_id = ? AND t.name = f.name "+
+		"WHERE f.user_id = ?", toUserID, fromUserID).Error; err != nil {
+		return fmt.Errorf("delete merged mindmap tags failed: %w", err)
+	}
+	if err := tx.Model(&po.MindMapTagPO{}).Where("user_id = ?", fromUserID).UpdateColumn("user_id", toUserID).Error; err != nil {
+		return fmt.Errorf("move mindmap tags failed: %w", err)
+	}
+	if err := tx.Model(&po.MindMapTagRelationPO{}).Where("user_id = ?", fromUserID).UpdateColumn("user_id", toUserID).Error; err != nil {
+		return fmt.Errorf("move mindmap tag relations failed: %w", err)
+	}
+	return nil
+}
+
+// mergeMindMapStars 迁移导图收藏，两个账号都收藏了的导图保留目标用户的收藏
+func mergeMindMapStars(tx *gorm.DB, fromUserID, toUserID string) error {
+	if err := tx.Exec("DELETE f FROM achobeta_forge_user_map_stars f "+
+		"JOIN achobeta_forge_user_map_stars t ON t.user_id = ? AND t.map_id = f.map_id "+
+		"WHERE f.user_id = ?", toUserID, fromUserID).Error; err != nil {
+		return fmt.Errorf("delete merged mindmap stars failed: %w", err)
+	}
+	if err := tx.Model(&po.MindMapStarPO{}).Where("user_id = ?", fromUserID).UpdateColumn("user_id", toUserID).Error; err != nil {
+		return fmt.Errorf("move mindmap stars failed: %w", err)
+	}
+	return nil
+}
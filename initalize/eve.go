@@ -1,24 +1,39 @@
 package initalize
 
 import (
+	"context"
+	"forge/infra/cache"
+	"forge/infra/configs"
+	"forge/infra/database"
+	"forge/infra/jobs"
 	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
+	"forge/pkg/tracing"
 	"runtime"
 )
 
+// Eve HTTP 服务退出后释放资源：先停止后台任务，再上报剩余的 span，最后关闭 Redis 与数据库
+// 总耗时不超过 app.shutdown_timeout_seconds
 func Eve() {
-	//zlog.Warnf("开始释放资源！")
-	//errRedis := global.Rdb.Close()
-	//if errRedis != nil {
-	//	zlog.Errorf("Redis关闭失败 ：%v", errRedis.Error())
-	//}
-	//
-	//sqlDB, _ := global.DB.DB()
-	//errDB := sqlDB.Close()
-	//if errDB != nil {
-	//	zlog.Errorf("数据库关闭失败 ：%v", errDB.Error())
-	//}
+	zlog.Warnf("开始释放资源！")
+	ctx, cancel := context.WithTimeout(context.Background(), configs.Config().GetAppConfig().ShutdownTimeout())
+	defer cancel()
+
+	// 后台任务会读写数据库，需在关闭连接之前退出
+	jobs.Stop(ctx)
+	loop.Close(ctx)
+	tracing.Shutdown(ctx)
+
+	errRedis := cache.Close()
+	if errRedis != nil {
+		zlog.Errorf("Redis关闭失败 ：%v", errRedis.Error())
+	}
+	errDB := database.Close()
+	if errDB != nil {
+		zlog.Errorf("数据库关闭失败 ：%v", errDB.Error())
+	}
 	runtime.GC()
-	//if errDB == nil && errRedis == nil {
-	zlog.Warnf("资源释放成功！")
-	//}
+	if errDB == nil && errRedis == nil {
+		zlog.Warnf("资源释放成功！")
+	}
 }
@@ -1,26 +1,51 @@
 package initalize
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
+	"forge/biz/adapter"
 	"forge/biz/aichatservice"
+	"forge/biz/auditservice"
+	"forge/biz/backupservice"
+	"forge/biz/billingservice"
+	"forge/biz/captchaservice"
 	"forge/biz/cosservice"
+	"forge/biz/erasureservice"
+	"forge/biz/exportservice"
+	"forge/biz/feedbackservice"
 	"forge/biz/mindmapservice"
+	"forge/biz/outboxservice"
+	"forge/biz/presetservice"
+	"forge/biz/quotaservice"
 	"forge/biz/userservice"
+	"forge/infra/alert"
 	"forge/infra/cache"
 	"forge/infra/configs"
 	"forge/infra/cos"
 	"forge/infra/coze"
 	"forge/infra/database"
 	"forge/infra/eino"
+	"forge/infra/eventbus"
+	"forge/infra/filescan"
+	"forge/infra/httpclient"
+	"forge/infra/jobs"
+	"forge/infra/moderation"
 	"forge/infra/notification"
+	"forge/infra/oauth"
+	"forge/infra/payment"
 	"forge/infra/storage"
 	"forge/interface/handler"
 	"forge/interface/router"
 	"forge/pkg/log"
-
-	// "forge/pkg/loop"
+	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
+	"forge/pkg/mindmapconv"
+	"forge/pkg/tracing"
 	"forge/util"
+	"os"
+	"strings"
+	"time"
 )
 
 func Init() {
@@ -31,15 +56,46 @@ func Init() {
 	configs.MustInit(path)
 	log.InitLog(path, configs.Config())
 	database.MustInitDatabase(configs.Config())
+	// 指定了 -migrate 时只执行迁移命令，不启动服务
+	if *migrateCommand != "" {
+		runMigrateCommand()
+	}
+	mustMigrate(configs.Config().GetDBConfig(), configs.Config().GetAppConfig().IsDev())
 	cache.MustInitCache(configs.Config())
-	// TODO: cozeloop配置好后启用
-	// loop.MustInitLoop()
-	coze.InitCozeService()
-	notification.InitCodeService(configs.Config().GetSMTPConfig(), configs.Config().GetSMSConfig())
+	loop.MustInitLoop(configs.Config().GetLoopConfig())
+	tracing.Init(configs.Config().GetTracingConfig())
+	// 外部调用共用的连接池，需在 coze、短信、COS、AI 等服务之前初始化
+	httpclient.InitHTTPClient(configs.Config().GetHTTPClientConfig())
+	coze.InitCozeService(configs.Config().GetCozeConfig())
+	notification.InitCodeService(configs.Config().GetSMTPConfig(), configs.Config().GetSMSConfig(), configs.Config().GetTimeoutConfig())
 
 	storage.InitUserStorage()
+	// 用户缓存包装用户仓储，需在获取用户仓储之前初始化
+	userCacheConfig := configs.Config().GetUserCacheConfig()
+	storage.InitUserCache(userCacheConfig)
+	storage.InitUserIdentityStorage()
+	storage.InitUserSessionStorage()
+	storage.InitPasswordHistoryStorage()
+	storage.InitAuditLogStorage()
+	storage.InitAiUsageStorage()
+	storage.InitUserQuotaStorage()
+	storage.InitPromptPresetStorage()
+	storage.InitMessageFeedbackStorage()
+	storage.InitInviteStorage()
 	storage.InitMindMapStorage()
+	storage.InitMindMapShareStorage()
+	storage.InitMindMapMemberStorage()
+	storage.InitMindMapFolderStorage()
+	storage.InitMindMapTagStorage()
+	storage.InitMindMapTemplateStorage()
+	storage.InitMindMapActivityStorage()
+	storage.InitMindMapCommentStorage()
+	storage.InitMindMapStarStorage()
 	storage.InitAiChatStorage()
+	storage.InitFileStorage()
+	storage.InitInvoiceStorage()
+	storage.InitBackupStorage()
+	storage.InitOutboxStorage()
 
 	// snowflake - 从配置文件读取节点ID
 	snowflakeConfig := configs.Config().GetSnowflakeConfig()
@@ -48,28 +104,190 @@ func Init() {
 		panic(fmt.Sprintf("init snowflake failed: %v", err))
 	}
 
+	// 密码规则 - 从配置文件读取，需在用户服务处理请求之前设置
+	if err := initPasswordPolicy(configs.Config().GetPasswordPolicyConfig()); err != nil {
+		panic(fmt.Sprintf("init password policy failed: %v", err))
+	}
+
 	// 从配置文件读取JWT配置并创建JWTUtil
+	// 签发与校验共用同一个 JWTUtil，保证 iss/aud 等声明一致
 	jwtConfig := configs.Config().GetJWTConfig()
-	jwtUtil := util.NewJWTUtil(jwtConfig.SecretKey, jwtConfig.ExpireHours)
+	// secret_key 为必填项，启动时已校验
+	secretKey := jwtConfig.SecretKey
+	if jwtConfig.Issuer == "" {
+		zlog.Warnf("JWT issuer is empty, tokens from other environments sharing the secret will be accepted")
+	}
+	jwtUtil := util.NewJWTUtil(secretKey, jwtConfig.ExpireHours, util.JWTClaimsOptions{
+		Issuer:     jwtConfig.Issuer,
+		Audience:   jwtConfig.Audience,
+		Leeway:     time.Duration(jwtConfig.LeewaySeconds) * time.Second,
+		RefreshTTL: time.Duration(jwtConfig.RefreshExpireHours) * time.Hour,
+	})
+
+	// 套餐配额服务，供导图、AI对话、COS服务统一校验
+	qs := quotaservice.NewQuotaServiceImpl(storage.GetUserPersistence(), storage.GetMindMapPersistence(), storage.GetFilePersistence(), storage.GetAiUsagePersistence(),
+		storage.GetUserQuotaPersistence(), configs.Config().GetPlanConfigs())
 
-	us := userservice.NewUserServiceImpl(storage.GetUserPersistence(), coze.GetCozeService(), jwtUtil, notification.GetCodeService())
+	// 退订链接签名密钥未配置时复用JWT密钥
+	reminderConfig := configs.Config().GetContactReminderConfig()
+	if reminderConfig.Secret == "" {
+		reminderConfig.Secret = secretKey
+	}
+	deletionConfig := configs.Config().GetAccountDeletionConfig()
+	// 账号安全审计，记录登录、重置密码、换绑等事件
+	as := auditservice.NewAuditServiceImpl(storage.GetAuditLogPersistence())
+	// 告警通知，用于接口 panic 与发件箱事件放弃投递；未开启时为 nil
+	alertNotifier, err := alert.NewAlertNotifier(configs.Config().GetAlertConfig())
+	if err != nil {
+		panic(fmt.Sprintf("init alert failed: %v", err))
+	}
+	// 事件发件箱，领域事件随业务事务写入，由后台任务投递到 Redis Stream；未开启时不记录事件
+	outboxConfig := configs.Config().GetOutboxConfig()
+	obs := outboxservice.NewOutboxServiceImpl(storage.GetOutboxPersistence(), eventbus.NewEventPublisher(outboxConfig), alertNotifier, outboxConfig)
+	// 第三方登录平台，只注册已开启的
+	oauthProviders := oauth.NewProviders(configs.Config().GetOAuthConfig())
+	us := userservice.NewUserServiceImpl(storage.GetUserPersistence(), coze.GetCozeService(), jwtUtil, notification.GetCodeService(), configs.Config().GetCozeConfig(), reminderConfig,
+		storage.GetUserIdentityPersistence(), oauthProviders, configs.Config().GetLoginLimitConfig(),
+		deletionConfig, storage.GetUserSessionPersistence(),
+		configs.Config().GetMagicLinkConfig(), storage.GetPasswordHistoryPersistence(), configs.Config().GetPasswordHistoryConfig(),
+		configs.Config().GetAccountChangeConfig(), as, storage.GetInvitePersistence(), configs.Config().GetInviteConfig(),
+		storage.GetTxManager(), obs)
 
 	// 依赖注入：创建COS服务实例
 	cosConfig := configs.Config().GetCOSConfig()
+	// 转发下载链接签名密钥未配置时复用JWT密钥
+	if cosConfig.Download.Secret == "" {
+		cosConfig.Download.Secret = secretKey
+	}
 	cosService := cos.NewCOSService(cosConfig)
 
-	mms := mindmapservice.NewMindMapServiceImpl(storage.GetMindMapPersistence())
-	cs := cosservice.NewCOSServiceImpl(cosService, cosConfig)
+	// 分享链接签名密钥未配置时复用JWT密钥
+	shareConfig := configs.Config().GetMindMapShareConfig()
+	if shareConfig.Secret == "" {
+		shareConfig.Secret = secretKey
+	}
+	// 导图导出，未配置字体时不支持导出 PNG
+	exportConfig := configs.Config().GetMindMapExportConfig()
+	exporter, err := mindmapconv.NewExporter(exportConfig.FontFile)
+	if err != nil {
+		panic(fmt.Sprintf("init mindmap exporter failed: %v", err))
+	}
+	mms := mindmapservice.NewMindMapServiceImpl(storage.GetMindMapPersistence(), qs, storage.GetMindMapSharePersistence(),
+		storage.GetMindMapMemberPersistence(), storage.GetMindMapFolderPersistence(), storage.GetMindMapTagPersistence(), storage.GetMindMapTemplatePersistence(), storage.GetMindMapActivityPersistence(),
+		storage.GetMindMapCommentPersistence(), storage.GetMindMapStarPersistence(), storage.GetUserPersistence(), shareConfig, cosService, exporter, exportConfig)
+	// 上传文件病毒扫描，未开启时为 nil
+	fileScanner, err := filescan.NewFileScanner(configs.Config().GetFileScanConfig())
+	if err != nil {
+		panic(fmt.Sprintf("init file scan failed: %v", err))
+	}
+	cs := cosservice.NewCOSServiceImpl(cosService, cosConfig, storage.GetFilePersistence(), qs, fileScanner)
 
-	// 依赖注入: 创建ai服务实例
+	// 依赖注入: 创建对话预设服务与ai服务实例
+	pps := presetservice.NewPromptPresetServiceImpl(storage.GetPromptPresetPersistence(), configs.Config().GetPromptPresetConfig())
+	// AI对话内容审核，未开启时为 nil
+	moderationService, err := moderation.NewModerationService(configs.Config().GetModerationConfig())
+	if err != nil {
+		panic(fmt.Sprintf("init moderation failed: %v", err))
+	}
 	aiConfig := configs.Config().GetAiChatConfig()
-	acs := aichatservice.NewAiChatService(storage.GetAiChatPersistence(), eino.NewAiChatClient(aiConfig.ApiKey, aiConfig.ModelName))
-	handler.MustInitHandler(us, mms, cs, acs)
+	acs := aichatservice.NewAiChatService(storage.GetAiChatPersistence(), eino.NewAiChatClient(aiConfig, configs.Config().GetTimeoutConfig().AI()), qs, mms, pps,
+		cs, moderationService, as, aiConfig)
+	mfs := feedbackservice.NewMessageFeedbackServiceImpl(storage.GetMessageFeedbackPersistence(), storage.GetAiChatPersistence())
+
+	// 依赖注入: 创建支付服务实例，未配置密钥时不开启支付
+	billingConfig := configs.Config().GetBillingConfig()
+	var paymentService adapter.PaymentService
+	if billingConfig.SecretKey != "" {
+		paymentService = payment.NewStripeService(billingConfig)
+	}
+	bs := billingservice.NewBillingServiceImpl(paymentService, storage.GetInvoicePersistence(), storage.GetUserPersistence(), qs, billingConfig)
+
+	// 依赖注入: 创建备份服务实例
+	backupConfig := configs.Config().GetBackupConfig()
+	bks := backupservice.NewBackupServiceImpl(cosService, storage.GetBackupPersistence(), storage.GetUserPersistence(), storage.GetMindMapPersistence(), storage.GetAiChatPersistence(), backupConfig)
+
+	// 依赖注入: 创建数据导出服务实例
+	dxs := exportservice.NewDataExportServiceImpl(cosService, notification.GetCodeService(), storage.GetMindMapPersistence(), storage.GetAiChatPersistence(),
+		configs.Config().GetDataExportConfig())
+
+	// 依赖注入: 创建注销账号清理服务实例
+	es := erasureservice.NewAccountErasureServiceImpl(cosService, storage.GetUserPersistence(), storage.GetUserIdentityPersistence(), storage.GetMindMapPersistence(),
+		storage.GetAiChatPersistence(), storage.GetFilePersistence(), storage.GetBackupPersistence(), storage.GetUserSessionPersistence(),
+		storage.GetPasswordHistoryPersistence(), storage.GetAuditLogPersistence(), dxs, storage.GetInvitePersistence(),
+		storage.GetAiUsagePersistence(), storage.GetPromptPresetPersistence(), storage.GetMessageFeedbackPersistence(),
+		storage.GetMindMapSharePersistence(), storage.GetMindMapMemberPersistence(), storage.GetMindMapFolderPersistence(), storage.GetMindMapTagPersistence(),
+		storage.GetMindMapActivityPersistence(), storage.GetMindMapCommentPersistence(), storage.GetMindMapStarPersistence(), storage.GetUserQuotaPersistence())
+
+	// 依赖注入: 创建图形验证码服务实例
+	cps := captchaservice.NewCaptchaServiceImpl(configs.Config().GetCaptchaConfig())
+
+	handler.MustInitHandler(us, mms, cs, acs, qs, bs, bks, cps, as, dxs, pps, mfs)
+
+	// 定时清理超过恢复期限的已删除会话
+	mustRegisterJob(conversationPurgeJob(acs, cs))
+	// 定时降级到期的付费套餐
+	mustRegisterJob(planExpiryJob(bs))
+	// 定时提醒只绑定了邮箱的用户绑定手机号
+	if reminderConfig.Enable {
+		mustRegisterJob(contactReminderJob(us, reminderConfig.IntervalHours))
+	}
+	// 定时彻底删除注销冷静期已到的账号
+	mustRegisterJob(accountDeletionJob(es, deletionConfig.Interval()))
+	// 定时输出用户缓存命中率
+	if userCacheConfig.Enable {
+		mustRegisterJob(userCacheStatsJob(userCacheConfig.StatsInterval()))
+	}
+	// 定时备份用户内容并清理过期备份
+	if backupConfig.Enable {
+		mustRegisterJob(backupJob(bks, backupConfig.IntervalHours))
+	}
+	// 定时投递发件箱中的事件并清理已投递的事件
+	if outboxConfig.Enable {
+		mustRegisterJob(outboxRelayJob(obs, outboxConfig.Interval(), outboxConfig.Batch()))
+		mustRegisterJob(outboxPurgeJob(obs))
+	}
+	// 启动定时任务与延迟任务的处理，服务退出时由 Eve 等待其结束
+	jobs.Start()
 
 	// 初始化JWT鉴权中间件
-	router.InitJWTAuth(us)
+	router.InitJWTAuth(jwtUtil, us, configs.Config().GetCookieAuthConfig())
+	// 初始化图形验证码校验
+	router.InitCaptcha(cps)
+	// 初始化 panic 告警
+	router.InitAlert(alertNotifier)
+	// 就绪检查依赖的组件
+	router.InitReadiness(map[string]func(context.Context) error{
+		"database": database.Ping,
+		"redis":    cache.Ping,
+	})
+	// 配置中的管理员用户授予管理员角色，之后可通过管理接口调整其他用户的角色
+	us.GrantAdminRole(context.Background(), configs.Config().GetAdminConfig().UserIDs)
 
 }
+
+// initPasswordPolicy 按配置设置密码规则，配置了常见密码文件时一并加载
+func initPasswordPolicy(conf configs.PasswordPolicyConfig) error {
+	commonPasswords := conf.CommonPasswords
+	if conf.CommonPasswordsFile != "" {
+		content, err := os.ReadFile(conf.CommonPasswordsFile)
+		if err != nil {
+			return fmt.Errorf("read common passwords file: %w", err)
+		}
+		commonPasswords = append(commonPasswords, strings.Split(string(content), "\n")...)
+	}
+
+	return util.InitPasswordPolicy(util.PasswordPolicy{
+		MinLength:       conf.MinLen(),
+		MaxLength:       conf.MaxLen(),
+		MinClasses:      conf.MinClassCount(),
+		RequireUpper:    conf.RequireUpper,
+		RequireLower:    conf.RequireLower,
+		RequireDigit:    conf.RequireDigit,
+		RequireSpecial:  conf.RequireSpecial,
+		CommonPasswords: commonPasswords,
+	})
+}
+
 func initPath() string {
 	return util.GetRootPath("")
 }
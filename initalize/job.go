@@ -0,0 +1,214 @@
+package initalize
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/types"
+	"forge/infra/jobs"
+	"forge/infra/storage"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	// 默认备份间隔
+	defaultBackupInterval = 24 * time.Hour
+	// 默认绑定联系方式提醒间隔
+	defaultContactReminderInterval = 24 * time.Hour
+)
+
+// mustRegisterJob 注册定时任务，执行时间表无效等配置错误时直接退出
+func mustRegisterJob(job jobs.Job) {
+	if err := jobs.Register(job); err != nil {
+		panic(fmt.Sprintf("register job failed: %v", err))
+	}
+}
+
+// every 固定间隔的执行时间表
+func every(interval time.Duration) string {
+	return "@every " + interval.String()
+}
+
+// conversationPurgeJob 定时彻底删除超过恢复期限的会话（含聊天记录与归档文件）
+// 先删除文件再删除会话：文件按会话ID查找，先删会话的话文件删除失败后再也找不到
+func conversationPurgeJob(aiChatService types.IAiChatService, cosService types.ICOSService) jobs.Job {
+	return jobs.Job{
+		Name:      "conversation_purge",
+		Schedule:  "@hourly",
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			conversationIDs, err := aiChatService.ListPurgeableConversations(ctx)
+			if err != nil {
+				return fmt.Errorf("查询待清理会话失败: %w", err)
+			}
+			if len(conversationIDs) == 0 {
+				return nil
+			}
+
+			// 文件未能全部删除的会话留待下次清理
+			cleaned, err := cosService.DeleteConversationFiles(ctx, conversationIDs)
+			if err != nil {
+				return fmt.Errorf("清理会话关联文件失败: %w", err)
+			}
+			if err := aiChatService.PurgeConversations(ctx, cleaned); err != nil {
+				return fmt.Errorf("清理已删除会话失败: %w", err)
+			}
+			if len(cleaned) > 0 {
+				zlog.Infof("已彻底删除 %d 个超过恢复期限的会话", len(cleaned))
+			}
+			if skipped := len(conversationIDs) - len(cleaned); skipped > 0 {
+				return fmt.Errorf("%d 个会话的关联文件删除失败，留待下次清理", skipped)
+			}
+			return nil
+		},
+	}
+}
+
+// planExpiryJob 定时将到期的付费套餐降级为免费版，降级可重复执行，失败时重试
+func planExpiryJob(billingService types.IBillingService) jobs.Job {
+	return jobs.Job{
+		Name:      "plan_expiry",
+		Schedule:  "*/10 * * * *",
+		Exclusive: true,
+		Retry:     jobs.RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second},
+		Run: func(ctx context.Context) error {
+			count, err := billingService.ExpirePlans(ctx)
+			if err != nil {
+				return fmt.Errorf("降级到期套餐失败: %w", err)
+			}
+			if count > 0 {
+				zlog.Infof("已将 %d 个到期的付费套餐降级为免费版", count)
+			}
+			return nil
+		},
+	}
+}
+
+// backupJob 定时备份所有用户的导图与会话，备份完成后清理超过保留期的备份
+func backupJob(backupService types.IBackupService, intervalHours int) jobs.Job {
+	interval := defaultBackupInterval
+	if intervalHours > 0 {
+		interval = time.Duration(intervalHours) * time.Hour
+	}
+	return jobs.Job{
+		Name:      "backup",
+		Schedule:  every(interval),
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			// 部分用户备份失败时仍清理过期备份
+			count, backupErr := backupService.RunBackup(ctx)
+			if backupErr != nil {
+				backupErr = fmt.Errorf("备份用户内容失败: %w", backupErr)
+			}
+			zlog.Infof("已完成 %d 个用户的内容备份", count)
+
+			purged, err := backupService.PurgeExpiredBackups(ctx)
+			if err != nil {
+				return fmt.Errorf("清理过期备份失败: %w", err)
+			}
+			if purged > 0 {
+				zlog.Infof("已清理 %d 个过期备份", purged)
+			}
+			return backupErr
+		},
+	}
+}
+
+// contactReminderJob 定时给只绑定了邮箱的用户发送绑定手机号的提醒
+func contactReminderJob(userService types.IUserService, intervalHours int) jobs.Job {
+	interval := defaultContactReminderInterval
+	if intervalHours > 0 {
+		interval = time.Duration(intervalHours) * time.Hour
+	}
+	return jobs.Job{
+		Name:      "contact_reminder",
+		Schedule:  every(interval),
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			count, err := userService.SendContactReminders(ctx)
+			if err != nil {
+				return fmt.Errorf("发送绑定联系方式提醒失败: %w", err)
+			}
+			if count > 0 {
+				zlog.Infof("已向 %d 个用户发送绑定联系方式提醒", count)
+			}
+			return nil
+		},
+	}
+}
+
+// accountDeletionJob 定时彻底删除注销冷静期已到的账号及其数据
+func accountDeletionJob(erasureService types.IAccountErasureService, interval time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:      "account_deletion",
+		Schedule:  every(interval),
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			count, err := erasureService.EraseDueAccounts(ctx)
+			if err != nil {
+				return fmt.Errorf("删除已注销账号失败: %w", err)
+			}
+			if count > 0 {
+				zlog.Infof("已彻底删除 %d 个注销冷静期已到的账号", count)
+			}
+			return nil
+		},
+	}
+}
+
+// outboxRelayJob 定时投递发件箱中的事件，有积压时连续投递直到取不满一批
+func outboxRelayJob(outboxService types.IOutboxService, interval time.Duration, batchSize int) jobs.Job {
+	return jobs.Job{
+		Name:      "outbox_relay",
+		Schedule:  every(interval),
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			for !jobs.Stopping() {
+				count, err := outboxService.Relay(ctx)
+				if err != nil {
+					return fmt.Errorf("投递发件箱事件失败: %w", err)
+				}
+				if count < batchSize {
+					return nil
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// outboxPurgeJob 定时删除超过保留期的已投递事件
+func outboxPurgeJob(outboxService types.IOutboxService) jobs.Job {
+	return jobs.Job{
+		Name:      "outbox_purge",
+		Schedule:  "@hourly",
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			count, err := outboxService.PurgePublishedEvents(ctx)
+			if err != nil {
+				return fmt.Errorf("清理已投递事件失败: %w", err)
+			}
+			if count > 0 {
+				zlog.Infof("已清理 %d 个已投递的事件", count)
+			}
+			return nil
+		},
+	}
+}
+
+// userCacheStatsJob 定时输出用户缓存的累计命中次数与命中率，统计的是本实例的缓存，各实例分别输出
+func userCacheStatsJob(interval time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:     "user_cache_stats",
+		Schedule: every(interval),
+		Run: func(ctx context.Context) error {
+			stats, ok := storage.GetUserCacheStats()
+			if !ok {
+				return nil
+			}
+			zlog.Infof("用户缓存命中 %d 次，未命中 %d 次，命中率 %.2f%%", stats.Hits, stats.Misses, stats.HitRate()*100)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package initalize
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"forge/infra/configs"
+	"forge/infra/database"
+	"forge/pkg/log/zlog"
+)
+
+// 数据库迁移命令，指定后只执行迁移，不启动服务：
+//
+//	forge -migrate up            执行所有未执行的迁移
+//	forge -migrate down -steps 1 回滚最近执行的 1 个迁移
+//	forge -migrate status        列出各迁移的执行状态
+var (
+	migrateCommand = flag.String("migrate", "", "数据库迁移命令：up、down、status，执行后退出，不启动服务")
+	migrateSteps   = flag.Int("steps", 1, "-migrate down 回滚的迁移个数")
+)
+
+// runMigrateCommand 执行 -migrate 指定的命令后退出
+func runMigrateCommand() {
+	err := migrate(context.Background(), *migrateCommand, *migrateSteps)
+	if closeErr := database.Close(); closeErr != nil {
+		zlog.Errorf("数据库关闭失败 ：%v", closeErr)
+	}
+	if err != nil {
+		zlog.Errorf("数据库迁移失败: %v", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func migrate(ctx context.Context, command string, steps int) error {
+	switch strings.ToLower(command) {
+	case "up":
+		done, err := database.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		zlog.Infof("已执行 %d 个迁移", len(done))
+	case "down":
+		if steps <= 0 {
+			return fmt.Errorf("-steps 必须大于 0")
+		}
+		done, err := database.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		zlog.Infof("已回滚 %d 个迁移", len(done))
+	case "status":
+		statuses, err := database.MigrationStatuses(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "未执行"
+			switch {
+			case s.Dirty:
+				state = "执行失败（dirty）"
+			case s.Applied:
+				state = "已执行 " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%s\t%s\n", s.Version, s.Name, state)
+		}
+	default:
+		return fmt.Errorf("不支持的迁移命令 %q，可选值为 up、down、status", command)
+	}
+	return nil
+}
+
+// mustMigrate 启动时检查表结构：开启 database.migrate 时执行未执行的迁移，
+// 否则存在未执行的迁移时拒绝启动，避免新代码在旧表结构上运行；开发环境由自动建表兜底，只输出警告
+func mustMigrate(conf configs.DBConfig, dev bool) {
+	ctx := context.Background()
+	if conf.Migrate {
+		done, err := database.MigrateUp(ctx)
+		if err != nil {
+			panic(fmt.Sprintf("数据库迁移失败: %v", err))
+		}
+		if len(done) > 0 {
+			zlog.Infof("已执行 %d 个数据库迁移", len(done))
+		}
+		return
+	}
+	pending, err := database.PendingMigrations(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("检查数据库迁移失败: %v", err))
+	}
+	if len(pending) == 0 {
+		return
+	}
+	names := make([]string, len(pending))
+	for i, m := range pending {
+		names[i] = fmt.Sprintf("%06d_%s", m.Version, m.Name)
+	}
+	if dev {
+		zlog.Warnf("存在未执行的数据库迁移 %s", strings.Join(names, "、"))
+		return
+	}
+	panic(fmt.Sprintf("存在未执行的数据库迁移 %s，请先执行 -migrate up 或开启 database.migrate", strings.Join(names, "、")))
+}
@@ -0,0 +1,99 @@
+package caster
+
+import (
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/interface/def"
+
+	"github.com/bytedance/gg/gslice"
+)
+
+// CastAdminListUsersReq2Params 用户列表查询 DTO -> Service 层参数
+func CastAdminListUsersReq2Params(req *def.AdminListUsersReq) *types.ListUsersParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListUsersParams{
+		Keyword:  req.Keyword,
+		Status:   req.Status,
+		Role:     req.Role,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastUserDOs2AdminDTOs 管理接口的用户列表，不返回密码等敏感字段
+func CastUserDOs2AdminDTOs(dos []*entity.User) []*def.AdminUser {
+	return gslice.Map(dos, func(do *entity.User) *def.AdminUser {
+		role := do.Role
+		if role == "" {
+			role = entity.UserRoleUser
+		}
+		return &def.AdminUser{
+			UserID:              do.UserID,
+			UserName:            do.UserName,
+			Nickname:            do.Nickname,
+			Avatar:              do.Avatar,
+			Phone:               do.Phone,
+			Email:               do.Email,
+			Role:                role,
+			Status:              do.Status,
+			SuspendReason:       do.SuspendReason,
+			SuspendedUntil:      do.SuspendedUntil,
+			Plan:                do.Plan,
+			CreatedAt:           do.CreatedAt,
+			LastLoginAt:         do.LastLoginAt,
+			DeletionScheduledAt: do.DeletionScheduledAt,
+		}
+	})
+}
+
+// CastAdminListAuditLogsReq2Params 安全记录查询 DTO -> Service 层参数
+func CastAdminListAuditLogsReq2Params(req *def.AdminListAuditLogsReq) *types.ListAuditLogsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListAuditLogsParams{
+		UserID:   req.UserID,
+		ActorID:  req.ActorID,
+		Action:   req.Action,
+		Result:   req.Result,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastAdminExportMessageFeedbackReq2Params 评价导出 DTO -> Service 层参数
+func CastAdminExportMessageFeedbackReq2Params(req *def.AdminExportMessageFeedbackReq) *types.ExportFeedbackParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ExportFeedbackParams{
+		Rating:    req.Rating,
+		PresetID:  req.PresetID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}
+}
+
+// CastMessageFeedbackDOs2AdminDTOs 导出的评价
+func CastMessageFeedbackDOs2AdminDTOs(dos []*entity.MessageFeedback) []*def.AdminMessageFeedback {
+	return gslice.Map(dos, func(do *entity.MessageFeedback) *def.AdminMessageFeedback {
+		return &def.AdminMessageFeedback{
+			ConversationID: do.ConversationID,
+			MessageID:      do.MessageID,
+			UserID:         do.UserID,
+			Rating:         do.Rating,
+			Comment:        do.Comment,
+			Prompt:         do.Prompt,
+			Reply:          do.Reply,
+			Provider:       do.Provider,
+			Model:          do.Model,
+			PresetID:       do.PresetID,
+			CreatedAt:      do.CreatedAt,
+			UpdatedAt:      do.UpdatedAt,
+		}
+	})
+}
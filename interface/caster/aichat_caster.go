@@ -4,6 +4,8 @@ import (
 	"forge/biz/entity"
 	"forge/biz/types"
 	"forge/interface/def"
+
+	"github.com/bytedance/gg/gslice"
 )
 
 func CastProcessUserMessageReq2Params(req *def.ProcessUserMessageRequest) *types.ProcessUserMessageParams {
@@ -15,6 +17,16 @@ func CastProcessUserMessageReq2Params(req *def.ProcessUserMessageRequest) *types
 		ConversationID: req.ConversationID,
 		Message:        req.Content,
 		MapData:        req.MapData,
+		Model:          req.Model,
+		Params: entity.ModelParams{
+			Temperature:      req.Temperature,
+			TopP:             req.TopP,
+			MaxTokens:        req.MaxTokens,
+			FrequencyPenalty: req.FrequencyPenalty,
+		},
+		Images: gslice.Map(req.Images, func(image def.MessageImageInput) types.ImageInput {
+			return types.ImageInput{Key: image.Key, Data: image.Data}
+		}),
 	}
 }
 
@@ -23,9 +35,10 @@ func CastSaveNewConversationReq2Params(req *def.SaveNewConversationRequest) *typ
 		return nil
 	}
 	return &types.SaveNewConversationParams{
-		Title:   req.Title,
-		MapID:   req.MapID,
-		MapData: req.MapData,
+		Title:    req.Title,
+		MapID:    req.MapID,
+		MapData:  req.MapData,
+		PresetID: req.PresetID,
 	}
 }
 
@@ -34,7 +47,8 @@ func CastGetConversationListReq2Params(req *def.GetConversationListRequest) *typ
 		return nil
 	}
 	return &types.GetConversationListParams{
-		MapID: req.MapID,
+		MapID:    req.MapID,
+		Archived: req.Archived,
 	}
 }
 
@@ -49,6 +63,9 @@ func CastConversationsDOs2Resp(conversations []*entity.Conversation) []def.Conve
 		conversationsData[i] = def.ConversationData{
 			ConversationID: conversation.ConversationID,
 			Title:          conversation.Title,
+			PresetID:       conversation.PresetID,
+			Archived:       conversation.Archived,
+			Pinned:         conversation.Pinned,
 			CreatedAt:      conversation.CreatedAt,
 			UpdatedAt:      conversation.UpdatedAt,
 		}
@@ -67,15 +84,37 @@ func CastDelConversationReq2Params(req *def.DelConversationRequest) *types.DelCo
 	}
 }
 
-func CastGetConversationReq2Params(req *def.GetConversationRequest) *types.GetConversationParams {
+func CastRestoreConversationReq2Params(req *def.RestoreConversationRequest) *types.RestoreConversationParams {
 	if req == nil {
 		return nil
 	}
-	return &types.GetConversationParams{
+	return &types.RestoreConversationParams{
 		ConversationID: req.ConversationID,
 	}
 }
 
+func CastGetConversationReq2Params(req *def.GetConversationRequest) *types.GetConversationMessagesParams {
+	if req == nil {
+		return nil
+	}
+	return &types.GetConversationMessagesParams{
+		ConversationID:  req.ConversationID,
+		BeforeMessageID: req.BeforeMessageID,
+		Limit:           req.Limit,
+	}
+}
+
+func CastForkConversationReq2Params(req *def.ForkConversationRequest) *types.ForkConversationParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ForkConversationParams{
+		ConversationID: req.ConversationID,
+		MessageID:      req.MessageID,
+		Title:          req.Title,
+	}
+}
+
 func CastUpdateConversationTitleReq2Params(req *def.UpdateConversationTitleRequest) *types.UpdateConversationTitleParams {
 	if req == nil {
 		return nil
@@ -86,6 +125,122 @@ func CastUpdateConversationTitleReq2Params(req *def.UpdateConversationTitleReque
 	}
 }
 
+func CastArchiveConversationReq2Params(req *def.ArchiveConversationRequest) *types.ArchiveConversationParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ArchiveConversationParams{
+		ConversationID: req.ConversationID,
+		Archived:       req.Archived,
+	}
+}
+
+func CastPinConversationReq2Params(req *def.PinConversationRequest) *types.PinConversationParams {
+	if req == nil {
+		return nil
+	}
+	return &types.PinConversationParams{
+		ConversationID: req.ConversationID,
+		Pinned:         req.Pinned,
+	}
+}
+
+func CastReorderConversationsReq2Params(req *def.ReorderConversationsRequest) *types.ReorderConversationsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ReorderConversationsParams{
+		MapID:           req.MapID,
+		ConversationIDs: req.ConversationIDs,
+	}
+}
+
+func CastSearchConversationsReq2Params(req *def.SearchConversationsRequest) *types.SearchConversationsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SearchConversationsParams{
+		Keyword:  req.Q,
+		Archived: req.Archived,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+func CastConversationSearchResult2Resp(result *types.ConversationSearchResult) *def.SearchConversationsResponse {
+	list := make([]def.ConversationSearchHit, len(result.List))
+	for i, hit := range result.List {
+		list[i] = def.ConversationSearchHit{
+			ConversationID: hit.Conversation.ConversationID,
+			MapID:          hit.Conversation.MapID,
+			Title:          hit.Conversation.Title,
+			Archived:       hit.Conversation.Archived,
+			MessageID:      hit.MessageID,
+			Snippet:        hit.Snippet,
+			UpdatedAt:      hit.Conversation.UpdatedAt,
+		}
+	}
+	return &def.SearchConversationsResponse{
+		List:     list,
+		Total:    result.Total,
+		Page:     result.Page,
+		PageSize: result.PageSize,
+		Success:  true,
+	}
+}
+
+func CastSetConversationPresetReq2Params(req *def.SetConversationPresetRequest) *types.SetConversationPresetParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SetConversationPresetParams{
+		ConversationID: req.ConversationID,
+		PresetID:       req.PresetID,
+	}
+}
+
+func CastPromptPresetReq2Params(req *def.PromptPresetRequest, global bool) *types.PromptPresetParams {
+	if req == nil {
+		return nil
+	}
+	return &types.PromptPresetParams{
+		Global:       global,
+		Name:         req.Name,
+		Description:  req.Description,
+		SystemPrompt: req.SystemPrompt,
+		Params: entity.ModelParams{
+			Temperature:      req.Temperature,
+			TopP:             req.TopP,
+			MaxTokens:        req.MaxTokens,
+			FrequencyPenalty: req.FrequencyPenalty,
+		},
+	}
+}
+
+func CastPromptPresetDO2Resp(preset *entity.PromptPreset) def.PromptPresetData {
+	return def.PromptPresetData{
+		PresetID:         preset.PresetID,
+		Name:             preset.Name,
+		Description:      preset.Description,
+		SystemPrompt:     preset.SystemPrompt,
+		Temperature:      preset.Params.Temperature,
+		TopP:             preset.Params.TopP,
+		MaxTokens:        preset.Params.MaxTokens,
+		FrequencyPenalty: preset.Params.FrequencyPenalty,
+		Global:           preset.IsGlobal(),
+		CreatedAt:        preset.CreatedAt,
+		UpdatedAt:        preset.UpdatedAt,
+	}
+}
+
+func CastPromptPresetDOs2Resp(presets []*entity.PromptPreset) []def.PromptPresetData {
+	list := make([]def.PromptPresetData, len(presets))
+	for i, preset := range presets {
+		list[i] = CastPromptPresetDO2Resp(preset)
+	}
+	return list
+}
+
 func CastGenerateMindMapReq2Params(req *def.GenerateMindMapRequest) *types.GenerateMindMapParams {
 	if req == nil {
 		return nil
@@ -95,3 +250,131 @@ func CastGenerateMindMapReq2Params(req *def.GenerateMindMapRequest) *types.Gener
 		File: req.File,
 	}
 }
+
+func CastFileDOs2AttachmentData(files []*entity.File) []def.AttachmentData {
+	if files == nil {
+		return nil
+	}
+
+	attachments := make([]def.AttachmentData, len(files))
+
+	for i, file := range files {
+		attachments[i] = def.AttachmentData{
+			FileID:      file.FileID,
+			Filename:    file.Filename,
+			URL:         file.URL,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			CreatedAt:   file.CreatedAt,
+		}
+	}
+
+	return attachments
+}
+
+func CastAITokenUsage2Resp(usage *types.AITokenUsage) *def.GetAiUsageResponse {
+	if usage == nil {
+		return nil
+	}
+	return &def.GetAiUsageResponse{
+		Today:            castTokenUsage(usage.Today),
+		ThisMonth:        castTokenUsage(usage.ThisMonth),
+		AITokensPerDay:   usage.AITokensPerDay,
+		AITokensPerMonth: usage.AITokensPerMonth,
+		Success:          true,
+	}
+}
+
+func castTokenUsage(usage entity.TokenUsage) def.TokenUsage {
+	return def.TokenUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.Total(),
+	}
+}
+
+func CastAgentResponse2Resp(aiMsg *types.AgentResponse) *def.ProcessUserMessageResponse {
+	if aiMsg == nil {
+		return nil
+	}
+	changes := make([]def.MindMapChange, len(aiMsg.Changes))
+	for i, change := range aiMsg.Changes {
+		changes[i] = def.MindMapChange{
+			Op:      change.Op,
+			Path:    change.Path,
+			Text:    change.Text,
+			OldText: change.OldText,
+		}
+	}
+	return &def.ProcessUserMessageResponse{
+		Content:    aiMsg.Content,
+		NewMapJson: aiMsg.NewMapJson,
+		Success:    true,
+		Provider:   aiMsg.Provider,
+		Model:      aiMsg.Model,
+		Changes:    changes,
+		EditError:  aiMsg.EditError,
+	}
+}
+
+func CastSummarizeMindMapReq2Params(req *def.SummarizeMindMapRequest) *types.SummarizeMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SummarizeMindMapParams{
+		MapID:          req.MapID,
+		ConversationID: req.ConversationID,
+		Model:          req.Model,
+	}
+}
+
+func CastMindMapSummary2Resp(result *types.MindMapSummaryResult) *def.SummarizeMindMapResponse {
+	if result == nil {
+		return nil
+	}
+	branches := make([]def.BranchSummary, len(result.Summary.Branches))
+	for i, branch := range result.Summary.Branches {
+		branches[i] = def.BranchSummary{
+			Title:     branch.Title,
+			KeyPoints: branch.KeyPoints,
+		}
+	}
+	return &def.SummarizeMindMapResponse{
+		ConversationID: result.ConversationID,
+		MessageID:      result.MessageID,
+		Summary:        result.Summary.Summary,
+		Branches:       branches,
+		Provider:       result.Provider,
+		Model:          result.Model,
+		Success:        true,
+	}
+}
+
+func CastSubmitMessageFeedbackReq2Params(req *def.SubmitMessageFeedbackRequest) *types.SubmitFeedbackParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SubmitFeedbackParams{
+		ConversationID: req.ConversationID,
+		MessageID:      req.MessageID,
+		Rating:         req.Rating,
+		Comment:        req.Comment,
+	}
+}
+
+// CastMessageFeedbackDOs2Resp 会话中的评价状态，只保留 messages 中的消息
+func CastMessageFeedbackDOs2Resp(feedbacks map[string]*entity.MessageFeedback, messages []*entity.Message) map[string]def.MessageFeedbackData {
+	resp := make(map[string]def.MessageFeedbackData)
+	for _, message := range messages {
+		feedback, ok := feedbacks[message.MessageID]
+		if !ok {
+			continue
+		}
+		resp[message.MessageID] = def.MessageFeedbackData{
+			Rating:    feedback.Rating,
+			Comment:   feedback.Comment,
+			UpdatedAt: feedback.UpdatedAt,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package caster
+
+import (
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/interface/def"
+)
+
+func CastBackups2Resp(backups []*entity.Backup) *def.ListBackupsResp {
+	infos := make([]def.BackupInfo, 0, len(backups))
+	for _, backup := range backups {
+		if backup == nil {
+			continue
+		}
+		infos = append(infos, def.BackupInfo{
+			BackupID:          backup.BackupID,
+			UserID:            backup.UserID,
+			Size:              backup.Size,
+			SHA256:            backup.SHA256,
+			MindMapCount:      backup.MindMapCount,
+			ConversationCount: backup.ConversationCount,
+			CreatedAt:         backup.CreatedAt,
+		})
+	}
+	return &def.ListBackupsResp{Backups: infos, Success: true}
+}
+
+func CastRestoreBackupResult2Resp(result *types.RestoreBackupResult) *def.RestoreBackupResp {
+	if result == nil {
+		return nil
+	}
+	return &def.RestoreBackupResp{
+		RestoredMindMaps:      result.RestoredMindMaps,
+		RestoredConversations: result.RestoredConversations,
+		SkippedMindMaps:       result.SkippedMindMaps,
+		Success:               true,
+	}
+}
@@ -0,0 +1,38 @@
+package caster
+
+import (
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/interface/def"
+)
+
+func CastCreateCheckoutReq2Params(req *def.CreateCheckoutReq) *types.CreateCheckoutParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateCheckoutParams{
+		Product: req.Product,
+	}
+}
+
+func CastInvoiceDOs2Resp(invoices []*entity.Invoice) []def.Invoice {
+	if invoices == nil {
+		return nil
+	}
+
+	list := make([]def.Invoice, len(invoices))
+	for i, invoice := range invoices {
+		list[i] = def.Invoice{
+			InvoiceID:  invoice.InvoiceID,
+			Product:    invoice.Product,
+			Plan:       invoice.Plan,
+			PeriodDays: invoice.PeriodDays,
+			Amount:     invoice.Amount,
+			Currency:   invoice.Currency,
+			Status:     invoice.Status,
+			PaidAt:     invoice.PaidAt,
+			CreatedAt:  invoice.CreatedAt,
+		}
+	}
+	return list
+}
@@ -1,6 +1,7 @@
 package caster
 
 import (
+	"forge/biz/entity"
 	"forge/biz/types"
 	"forge/interface/def"
 )
@@ -12,6 +13,7 @@ func CastGetOSSCredentialsReq2Params(req *def.GetOSSCredentialsReq) *types.GetOS
 	}
 
 	return &types.GetOSSCredentialsParams{
+		Purpose:         req.Purpose,
 		ResourcePath:    req.ResourcePath,
 		DurationSeconds: req.DurationSeconds,
 	}
@@ -31,7 +33,69 @@ func CastOSSCredentials2DTO(creds *types.OSSCredentials) *def.GetOSSCredentialsR
 		Region:          creds.Region,
 		BucketName:      creds.BucketName,
 		Provider:        creds.Provider,
+		Endpoint:        creds.Endpoint,
 		BaseURL:         creds.BaseURL,
 		AccountID:       creds.AccountID,
+		ResourcePath:    creds.ResourcePath,
 	}
 }
+
+// CastPresignDownloadReq2Params HTTP请求转服务层参数
+func CastPresignDownloadReq2Params(req *def.PresignDownloadReq) *types.PresignDownloadParams {
+	if req == nil {
+		return nil
+	}
+
+	return &types.PresignDownloadParams{
+		ResourcePath:  req.ResourcePath,
+		ExpireSeconds: req.ExpireSeconds,
+	}
+}
+
+// CastDownloadLink2DTO 服务层结果转HTTP响应
+func CastDownloadLink2DTO(link *types.DownloadLink) *def.PresignDownloadResp {
+	if link == nil {
+		return nil
+	}
+
+	return &def.PresignDownloadResp{
+		URL:       link.URL,
+		Mode:      link.Mode,
+		ExpiresAt: link.ExpiresAt.Unix(),
+	}
+}
+
+// CastListFilesReq2Params HTTP请求转服务层参数
+func CastListFilesReq2Params(req *def.ListFilesReq) *types.ListFilesParams {
+	if req == nil {
+		return nil
+	}
+
+	return &types.ListFilesParams{
+		Purpose:  req.Purpose,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastFiles2DTO 文件实体转HTTP响应
+func CastFiles2DTO(files []*entity.File) []def.FileInfo {
+	list := make([]def.FileInfo, 0, len(files))
+	for _, file := range files {
+		list = append(list, def.FileInfo{
+			FileID:         file.FileID,
+			ResourcePath:   file.ResourcePath,
+			URL:            file.URL,
+			Filename:       file.Filename,
+			ContentType:    file.ContentType,
+			Size:           file.Size,
+			Hash:           file.Hash,
+			Purpose:        file.Purpose,
+			ConversationID: file.ConversationID,
+			Status:         file.Status,
+			ScanResult:     file.ScanResult,
+			CreatedAt:      file.CreatedAt,
+		})
+	}
+	return list
+}
@@ -0,0 +1,19 @@
+package caster
+
+import (
+	"forge/biz/entity"
+	"forge/interface/def"
+)
+
+// CastDataExport2Resp 数据导出进度 -> 响应
+func CastDataExport2Resp(export *entity.DataExport) *def.ExportDataResp {
+	if export == nil {
+		return nil
+	}
+	return &def.ExportDataResp{
+		Status:    export.Status,
+		URL:       export.URL,
+		ExpiresAt: export.ExpiresAt,
+		Success:   true,
+	}
+}
@@ -1,6 +1,7 @@
 package caster
 
 import (
+	"strings"
 	"time"
 
 	"forge/biz/entity"
@@ -18,10 +19,37 @@ func CastCreateMindMapReq2Params(req *def.CreateMindMapReq) *types.CreateMindMap
 		return nil
 	}
 	return &types.CreateMindMapParams{
+		Title:    req.Title,
+		Desc:     req.Desc,
+		Layout:   req.Layout,
+		Data:     CastMindMapDataDTO2DO(req.Root),
+		FolderID: req.FolderID,
+	}
+}
+
+// CastCreateMindMapFromTextReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapFromTextReq2Params(req *def.CreateMindMapFromTextReq) *types.CreateMindMapFromTextParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapFromTextParams{
+		Title:  req.Title,
+		Desc:   req.Desc,
+		Layout: req.Layout,
+		Text:   req.Text,
+	}
+}
+
+// CastImportMindMapReq2Params DTO -> Service 层参数表单转换
+func CastImportMindMapReq2Params(req *def.ImportMindMapReq) *types.ImportMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ImportMindMapParams{
 		Title:  req.Title,
 		Desc:   req.Desc,
 		Layout: req.Layout,
-		Data:   CastMindMapDataDTO2DO(req.Root),
+		File:   req.File,
 	}
 }
 
@@ -54,11 +82,126 @@ func CastListMindMapsReq2Params(req *def.ListMindMapsReq) *types.ListMindMapsPar
 	return &types.ListMindMapsParams{
 		Title:    req.Title,
 		Layout:   req.Layout,
+		Scope:    req.Scope,
+		FolderID: req.FolderID,
+		Tags:     splitTags(req.Tags),
+		Starred:  req.Starred,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		SortBy:   req.SortBy,
+		Order:    req.Order,
+		Cursor:   req.Cursor,
+	}
+}
+
+// splitTags 拆分逗号分隔的标签，忽略空白项
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// CastSearchMindMapsReq2Params DTO -> Service 层参数表单转换
+func CastSearchMindMapsReq2Params(req *def.SearchMindMapsReq) *types.SearchMindMapsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SearchMindMapsParams{
+		Query:    req.Q,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastListMindMapActivitiesReq2Params DTO -> Service 层参数表单转换
+func CastListMindMapActivitiesReq2Params(req *def.ListMindMapActivitiesReq) *types.ListMindMapActivitiesParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListMindMapActivitiesParams{
+		Since:    req.Since,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastCreateMindMapCommentReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapCommentReq2Params(req *def.CreateMindMapCommentReq) *types.CreateMindMapCommentParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapCommentParams{
+		NodeID:  req.NodeID,
+		Content: req.Content,
+	}
+}
+
+// CastListMindMapCommentsReq2Params DTO -> Service 层参数表单转换
+func CastListMindMapCommentsReq2Params(req *def.ListMindMapCommentsReq) *types.ListMindMapCommentsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListMindMapCommentsParams{
+		NodeID:   req.NodeID,
+		Status:   req.Status,
 		Page:     req.Page,
 		PageSize: req.PageSize,
 	}
 }
 
+// CastMindMapTemplateReq2Params DTO -> Service 层参数表单转换
+func CastMindMapTemplateReq2Params(req *def.MindMapTemplateReq) *types.MindMapTemplateParams {
+	if req == nil {
+		return nil
+	}
+	return &types.MindMapTemplateParams{
+		Title:    req.Title,
+		Desc:     req.Desc,
+		Category: req.Category,
+		Layout:   req.Layout,
+		Data:     CastMindMapDataDTO2DO(req.Root),
+		Sort:     req.Sort,
+	}
+}
+
+// CastCreateMindMapFromTemplateReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapFromTemplateReq2Params(req *def.CreateMindMapFromTemplateReq) *types.CreateMindMapFromTemplateParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapFromTemplateParams{
+		Title:    req.Title,
+		FolderID: req.FolderID,
+	}
+}
+
+// CastShareMindMapReq2Params DTO -> Service 层参数表单转换
+func CastShareMindMapReq2Params(req *def.ShareMindMapReq) *types.ShareMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ShareMindMapParams{
+		Password:    req.Password,
+		ExpireHours: req.ExpireHours,
+	}
+}
+
+// CastAddMindMapMemberReq2Params DTO -> Service 层参数表单转换
+func CastAddMindMapMemberReq2Params(req *def.AddMindMapMemberReq) *types.AddMindMapMemberParams {
+	if req == nil {
+		return nil
+	}
+	return &types.AddMindMapMemberParams{
+		Account:     req.Account,
+		AccountType: req.AccountType,
+		Role:        req.Role,
+	}
+}
+
 // Entity -> DTO 转换
 
 // CastMindMapDO2DTO 实体转DTO
@@ -73,6 +216,9 @@ func CastMindMapDO2DTO(mindmap *entity.MindMap) *def.MindMapDTO {
 		Desc:      mindmap.Desc,
 		Layout:    mindmap.Layout,
 		Root:      CastMindMapDataDO2DTO(mindmap.Data),
+		FolderID:  mindmap.FolderID,
+		Tags:      mindmap.Tags,
+		Starred:   mindmap.Starred,
 		CreatedAt: formatTime(mindmap.CreatedAt),
 		UpdatedAt: formatTime(mindmap.UpdatedAt),
 	}
@@ -83,6 +229,128 @@ func CastMindMapDOs2DTOs(mindmaps []*entity.MindMap) []*def.MindMapDTO {
 	return gslice.Map(mindmaps, CastMindMapDO2DTO)
 }
 
+// CastMindMapShareLink2DTO 分享链接转DTO
+func CastMindMapShareLink2DTO(link *types.MindMapShareLink) *def.MindMapShareDTO {
+	if link == nil || link.Share == nil {
+		return nil
+	}
+	return &def.MindMapShareDTO{
+		ShareID:     link.Share.ShareID,
+		Token:       link.Token,
+		URL:         link.URL,
+		HasPassword: link.Share.HasPassword(),
+		ExpiresAt:   formatTime(link.Share.ExpiresAt),
+		CreatedAt:   formatTime(link.Share.CreatedAt),
+	}
+}
+
+// CastMindMapShareLinks2DTOs 分享链接列表转DTO列表
+func CastMindMapShareLinks2DTOs(links []*types.MindMapShareLink) []*def.MindMapShareDTO {
+	return gslice.Map(links, CastMindMapShareLink2DTO)
+}
+
+// CastMindMapMemberDO2DTO 协作者转DTO，users 为协作者的公开展示信息
+func CastMindMapMemberDO2DTO(member *entity.MindMapMember, users map[string]*entity.UserBrief) *def.MindMapMemberDTO {
+	if member == nil {
+		return nil
+	}
+	dto := &def.MindMapMemberDTO{
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: formatTime(member.CreatedAt),
+	}
+	if user, ok := users[member.UserID]; ok {
+		dto.Nickname = user.Nickname
+		if dto.Nickname == "" {
+			dto.Nickname = user.UserName
+		}
+		dto.Avatar = user.Avatar
+	}
+	return dto
+}
+
+// CastMindMapMembers2DTOs 所有者与协作者列表，所有者排在第一项
+func CastMindMapMembers2DTOs(mindmap *entity.MindMap, members []*entity.MindMapMember, users map[string]*entity.UserBrief) []*def.MindMapMemberDTO {
+	owner := &entity.MindMapMember{
+		MapID:     mindmap.MapID,
+		UserID:    mindmap.UserID,
+		Role:      entity.MindMapRoleOwner,
+		CreatedAt: mindmap.CreatedAt,
+	}
+	dtos := []*def.MindMapMemberDTO{CastMindMapMemberDO2DTO(owner, users)}
+	for _, member := range members {
+		dtos = append(dtos, CastMindMapMemberDO2DTO(member, users))
+	}
+	return dtos
+}
+
+// CastMindMapActivityDO2DTO 变更记录转DTO，users 为执行变更用户的昵称与头像
+func CastMindMapActivityDO2DTO(activity *entity.MindMapActivity, users map[string]*entity.UserBrief) *def.MindMapActivityDTO {
+	if activity == nil {
+		return nil
+	}
+	dto := &def.MindMapActivityDTO{
+		ActivityID:   activity.ActivityID,
+		Type:         activity.Type,
+		ActorID:      activity.ActorID,
+		Path:         activity.Path,
+		Text:         activity.Text,
+		OldText:      activity.OldText,
+		TargetUserID: activity.TargetID,
+		CreatedAt:    formatTime(activity.CreatedAt),
+	}
+	if user, ok := users[activity.ActorID]; ok {
+		dto.ActorNickname = user.Nickname
+		if dto.ActorNickname == "" {
+			dto.ActorNickname = user.UserName
+		}
+		dto.ActorAvatar = user.Avatar
+	}
+	return dto
+}
+
+// CastMindMapCommentDO2DTO 评论转DTO，users 为评论者的昵称与头像
+func CastMindMapCommentDO2DTO(comment *entity.MindMapComment, users map[string]*entity.UserBrief) *def.MindMapCommentDTO {
+	if comment == nil {
+		return nil
+	}
+	dto := &def.MindMapCommentDTO{
+		CommentID:  comment.CommentID,
+		NodeID:     comment.NodeID,
+		UserID:     comment.UserID,
+		Content:    comment.Content,
+		Resolved:   comment.Resolved,
+		ResolvedBy: comment.ResolvedBy,
+		CreatedAt:  formatTime(comment.CreatedAt),
+	}
+	if comment.ResolvedAt != nil {
+		dto.ResolvedAt = formatTime(*comment.ResolvedAt)
+	}
+	if user, ok := users[comment.UserID]; ok {
+		dto.Nickname = user.Nickname
+		if dto.Nickname == "" {
+			dto.Nickname = user.UserName
+		}
+		dto.Avatar = user.Avatar
+	}
+	return dto
+}
+
+// CastSharedMindMap2DTO 分享的导图转只读DTO
+func CastSharedMindMap2DTO(mindmap *entity.MindMap, share *entity.MindMapShare) *def.GetSharedMindMapResp {
+	if mindmap == nil || share == nil {
+		return nil
+	}
+	return &def.GetSharedMindMapResp{
+		Title:     mindmap.Title,
+		Desc:      mindmap.Desc,
+		Layout:    mindmap.Layout,
+		Root:      CastMindMapDataDO2DTO(mindmap.Data),
+		UpdatedAt: formatTime(mindmap.UpdatedAt),
+		ExpiresAt: formatTime(share.ExpiresAt),
+	}
+}
+
 // CastMindMapDataDO2DTO 思维导图数据实体转DTO
 func CastMindMapDataDO2DTO(data entity.MindMapData) def.MindMapData {
 	return def.MindMapData{
@@ -102,6 +370,7 @@ func CastMindMapDataDTO2DO(data def.MindMapData) entity.MindMapData {
 // CastNodeDataDO2DTO 节点数据实体转DTO
 func CastNodeDataDO2DTO(data entity.NodeData) def.NodeData {
 	return def.NodeData{
+		ID:   data.ID,
 		Text: data.Text,
 	}
 }
@@ -109,6 +378,7 @@ func CastNodeDataDO2DTO(data entity.NodeData) def.NodeData {
 // CastNodeDataDTO2DO 节点数据DTO转实体
 func CastNodeDataDTO2DO(data def.NodeData) entity.NodeData {
 	return entity.NodeData{
+		ID:   data.ID,
 		Text: data.Text,
 	}
 }
@@ -120,3 +390,130 @@ func formatTime(t time.Time) string {
 	}
 	return t.Format(time.RFC3339)
 }
+
+// CastExportMindMapReq2Params DTO -> Service 层参数表单转换
+func CastExportMindMapReq2Params(req *def.ExportMindMapReq) *types.ExportMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ExportMindMapParams{
+		Format: req.Format,
+		AsURL:  req.Delivery == "url",
+	}
+}
+
+// CastMindMapExport2DTO 导出结果转换
+func CastMindMapExport2DTO(export *types.MindMapExport) *def.ExportMindMapResp {
+	if export == nil {
+		return nil
+	}
+	rsp := &def.ExportMindMapResp{
+		FileName:    export.FileName,
+		ContentType: export.ContentType,
+		Data:        export.Data,
+		URL:         export.URL,
+	}
+	if export.ExpiresAt != nil {
+		rsp.ExpiresAt = formatTime(*export.ExpiresAt)
+	}
+	return rsp
+}
+
+// CastCreateMindMapFolderReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapFolderReq2Params(req *def.CreateMindMapFolderReq) *types.CreateMindMapFolderParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapFolderParams{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+}
+
+// CastUpdateMindMapFolderReq2Params DTO -> Service 层参数表单转换
+func CastUpdateMindMapFolderReq2Params(req *def.UpdateMindMapFolderReq) *types.UpdateMindMapFolderParams {
+	if req == nil {
+		return nil
+	}
+	return &types.UpdateMindMapFolderParams{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+}
+
+// CastMindMapFolderDO2DTO 文件夹实体转DTO
+func CastMindMapFolderDO2DTO(folder *entity.MindMapFolder) *def.MindMapFolderDTO {
+	if folder == nil {
+		return nil
+	}
+	return &def.MindMapFolderDTO{
+		FolderID:  folder.FolderID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: formatTime(folder.CreatedAt),
+		UpdatedAt: formatTime(folder.UpdatedAt),
+	}
+}
+
+// CastMindMapFolders2DTOs 文件夹列表转DTO列表
+func CastMindMapFolders2DTOs(folders []*entity.MindMapFolder) []*def.MindMapFolderDTO {
+	return gslice.Map(folders, CastMindMapFolderDO2DTO)
+}
+
+// CastMindMapTagDO2DTO 标签实体转DTO
+func CastMindMapTagDO2DTO(tag *entity.MindMapTag) *def.MindMapTagDTO {
+	if tag == nil {
+		return nil
+	}
+	return &def.MindMapTagDTO{
+		Name:     tag.Name,
+		MapCount: tag.MapCount,
+	}
+}
+
+// CastMindMapTags2DTOs 标签列表转DTO列表
+func CastMindMapTags2DTOs(tags []*entity.MindMapTag) []*def.MindMapTagDTO {
+	return gslice.Map(tags, CastMindMapTagDO2DTO)
+}
+
+// CastMindMapSearchHit2DTO 搜索结果转DTO
+func CastMindMapSearchHit2DTO(hit *types.MindMapSearchHit) *def.MindMapSearchHitDTO {
+	if hit == nil || hit.MindMap == nil {
+		return nil
+	}
+	return &def.MindMapSearchHitDTO{
+		MapID:          hit.MindMap.MapID,
+		UserID:         hit.MindMap.UserID,
+		Title:          hit.MindMap.Title,
+		TitleHighlight: hit.Title,
+		Snippets:       hit.Snippets,
+		UpdatedAt:      formatTime(hit.MindMap.UpdatedAt),
+	}
+}
+
+// CastMindMapSearchHits2DTOs 搜索结果列表转DTO列表
+func CastMindMapSearchHits2DTOs(hits []*types.MindMapSearchHit) []*def.MindMapSearchHitDTO {
+	return gslice.Map(hits, CastMindMapSearchHit2DTO)
+}
+
+// CastMindMapTemplateDO2DTO 模板实体转DTO
+func CastMindMapTemplateDO2DTO(template *entity.MindMapTemplate) *def.MindMapTemplateDTO {
+	if template == nil {
+		return nil
+	}
+	return &def.MindMapTemplateDTO{
+		TemplateID: template.TemplateID,
+		Title:      template.Title,
+		Desc:       template.Desc,
+		Category:   template.Category,
+		Layout:     template.Layout,
+		Root:       CastMindMapDataDO2DTO(template.Data),
+		Sort:       template.Sort,
+		UpdatedAt:  formatTime(template.UpdatedAt),
+	}
+}
+
+// CastMindMapTemplates2DTOs 模板列表转DTO列表
+func CastMindMapTemplates2DTOs(templates []*entity.MindMapTemplate) []*def.MindMapTemplateDTO {
+	return gslice.Map(templates, CastMindMapTemplateDO2DTO)
+}
@@ -0,0 +1,40 @@
+package caster
+
+import (
+	"forge/biz/types"
+	"forge/interface/def"
+)
+
+func CastPlanUsage2Resp(usage *types.PlanUsage) *def.GetPlanResp {
+	if usage == nil {
+		return nil
+	}
+	resp := &def.GetPlanResp{
+		Plan:          usage.Plan,
+		PlanExpiresAt: usage.PlanExpiresAt,
+		Limits: def.PlanLimits{
+			MaxMindMaps:         usage.Limits.MaxMindMaps,
+			MaxNodesPerMap:      usage.Limits.MaxNodesPerMap,
+			MaxStorageBytes:     usage.Limits.MaxStorageBytes,
+			AIRequestsPerDay:    usage.Limits.AIRequestsPerDay,
+			AIRequestsPerMinute: usage.Limits.AIRequestsPerMinute,
+			AIConcurrency:       usage.Limits.AIConcurrency,
+			AITokensPerDay:      usage.Limits.AITokensPerDay,
+			AITokensPerMonth:    usage.Limits.AITokensPerMonth,
+		},
+		MindMapCount:    usage.MindMapCount,
+		StorageBytes:    usage.StorageBytes,
+		AIRequestsToday: usage.AIRequestsToday,
+		Success:         true,
+	}
+	if usage.Override != nil {
+		resp.Override = &def.UserQuotaOverride{
+			MaxMindMaps:     usage.Override.MaxMindMaps,
+			MaxNodesPerMap:  usage.Override.MaxNodesPerMap,
+			MaxStorageBytes: usage.Override.MaxStorageBytes,
+			UpdatedBy:       usage.Override.UpdatedBy,
+			UpdatedAt:       usage.Override.UpdatedAt,
+		}
+	}
+	return resp
+}
@@ -1,9 +1,12 @@
 package caster
 
 import (
+	"time"
+
 	"forge/biz/entity"
 	"forge/biz/types"
 	"forge/interface/def"
+	"forge/util"
 
 	"github.com/bytedance/gg/gslice"
 )
@@ -74,17 +77,26 @@ func CastDogDOs2DTOs(dos []*entity.Dog) []*def.Dog {
 	return gslice.Map(dos, CastDogDO2DTO)
 }
 
+// CastAccount 手机号账号拼接国际区号，交给 Service 层校验并统一格式；其他类型的账号原样返回
+func CastAccount(account, accountType, countryCode string) string {
+	if accountType != types.AccountTypePhone {
+		return account
+	}
+	return util.WithCountryCode(account, countryCode)
+}
+
 // CastRegisterReq2Params： DTO -> Service 层参数表单转换
 func CastRegisterReq2Params(req *def.RegisterReq) *types.RegisterParams {
 	if req == nil {
 		return nil
 	}
 	return &types.RegisterParams{
-		Account:     req.Account,
+		Account:     CastAccount(req.Account, req.AccountType, req.CountryCode),
 		AccountType: req.AccountType,
 		Code:        req.Code,
 		Password:    req.Password,
 		UserName:    req.UserName,
+		InviteCode:  req.InviteCode,
 	}
 }
 
@@ -94,7 +106,7 @@ func CastResetPasswordReq2Params(req *def.ResetPasswordReq) *types.ResetPassword
 		return nil
 	}
 	return &types.ResetPasswordParams{
-		Account:         req.Account,
+		Account:         CastAccount(req.Account, req.AccountType, req.CountryCode),
 		AccountType:     req.AccountType,
 		Code:            req.Code,
 		NewPassword:     req.NewPassword,
@@ -116,10 +128,51 @@ func CastUpdateAccountReq2Params(req *def.UpdateAccountReq) *types.UpdateAccount
 		return nil
 	}
 	return &types.UpdateAccountParams{
-		Account:     req.Account,
+		Account:     CastAccount(req.Account, req.AccountType, req.CountryCode),
 		AccountType: req.AccountType,
 		Code:        req.Code,
 		Password:    req.Password,
+		OldCode:     req.OldCode,
+	}
+}
+
+// CastUpdateProfileReq2Params： DTO -> Service 层参数表单转换
+func CastUpdateProfileReq2Params(req *def.UpdateProfileReq) *types.UpdateProfileParams {
+	if req == nil {
+		return nil
+	}
+	return &types.UpdateProfileParams{
+		Nickname: req.Nickname,
+		Bio:      req.Bio,
+		Gender:   req.Gender,
+		Birthday: req.Birthday,
+		Location: req.Location,
+	}
+}
+
+// CastUserDO2Profile 取出用户的个人资料
+func CastUserDO2Profile(user *entity.User) def.UserProfile {
+	profile := def.UserProfile{
+		Nickname: user.Nickname,
+		Bio:      user.Bio,
+		Gender:   user.Gender,
+		Location: user.Location,
+	}
+	if user.Birthday != nil {
+		profile.Birthday = user.Birthday.Format("2006-01-02")
+	}
+	return profile
+}
+
+// CastDeleteAccountReq2Params： DTO -> Service 层参数表单转换
+func CastDeleteAccountReq2Params(req *def.DeleteAccountReq) *types.DeleteAccountParams {
+	if req == nil {
+		return nil
+	}
+	return &types.DeleteAccountParams{
+		Password:    req.Password,
+		AccountType: req.AccountType,
+		Code:        req.Code,
 	}
 }
 
@@ -129,7 +182,109 @@ func CastUnbindAccountReq2Params(req *def.UnbindAccountReq) *types.UnbindAccount
 		return nil
 	}
 	return &types.UnbindAccountParams{
-		Account:     req.Account,
+		Account:     CastAccount(req.Account, req.AccountType, req.CountryCode),
 		AccountType: req.AccountType,
 	}
 }
+
+// CastUserIdentityDOs2DTOs 第三方绑定信息，不返回第三方平台的用户ID
+func CastUserIdentityDOs2DTOs(dos []*entity.UserIdentity) []*def.OAuthBinding {
+	return gslice.Map(dos, func(do *entity.UserIdentity) *def.OAuthBinding {
+		return &def.OAuthBinding{
+			Provider: do.Provider,
+			NickName: do.NickName,
+			Avatar:   do.Avatar,
+			BoundAt:  do.CreatedAt,
+		}
+	})
+}
+
+// CastUserSessionDOs2DTOs 登录记录，currentSessionID 为当前请求所在的会话
+func CastUserSessionDOs2DTOs(dos []*entity.UserSession, currentSessionID string) []*def.Session {
+	now := time.Now()
+	return gslice.Map(dos, func(do *entity.UserSession) *def.Session {
+		return &def.Session{
+			SessionID:    do.SessionID,
+			Device:       do.Device,
+			IP:           do.IP,
+			UserAgent:    do.UserAgent,
+			LoginAt:      do.CreatedAt,
+			LastActiveAt: do.LastActiveAt,
+			Active:       do.Active(now),
+			Current:      currentSessionID != "" && do.SessionID == currentSessionID,
+		}
+	})
+}
+
+// CastMergeAccountReq2Params： DTO -> Service 层参数表单转换
+func CastMergeAccountReq2Params(req *def.MergeAccountReq) *types.MergeAccountParams {
+	if req == nil {
+		return nil
+	}
+	return &types.MergeAccountParams{
+		Password:         req.Password,
+		AccountType:      req.AccountType,
+		Code:             req.Code,
+		MergeAccount:     CastAccount(req.MergeAccount, req.MergeAccountType, req.MergeCountryCode),
+		MergeAccountType: req.MergeAccountType,
+		MergePassword:    req.MergePassword,
+		MergeCode:        req.MergeCode,
+	}
+}
+
+// CastInviteCodeDO2DTO 邀请码
+func CastInviteCodeDO2DTO(do *entity.InviteCode) *def.InviteCode {
+	if do == nil {
+		return nil
+	}
+	return &def.InviteCode{
+		Code:      do.Code,
+		MaxUses:   do.MaxUses,
+		UsedCount: do.UsedCount,
+		ExpiresAt: do.ExpiresAt,
+		Usable:    do.Usable(time.Now()),
+		CreatedAt: do.CreatedAt,
+	}
+}
+
+// CastInviteCodeDOs2DTOs 邀请码列表
+func CastInviteCodeDOs2DTOs(dos []*entity.InviteCode) []*def.InviteCode {
+	return gslice.Map(dos, CastInviteCodeDO2DTO)
+}
+
+// CastReferralDOs2DTOs 邀请记录，invitees 为被邀请用户的展示信息，key 为用户ID
+func CastReferralDOs2DTOs(dos []*entity.Referral, invitees map[string]*entity.UserBrief) []*def.Referral {
+	return gslice.Map(dos, func(do *entity.Referral) *def.Referral {
+		referral := &def.Referral{
+			InviteeID:    do.InviteeID,
+			Code:         do.Code,
+			RegisteredAt: do.CreatedAt,
+		}
+		if invitee, ok := invitees[do.InviteeID]; ok {
+			referral.InviteeName = invitee.Nickname
+			if referral.InviteeName == "" {
+				referral.InviteeName = invitee.UserName
+			}
+			referral.InviteeAvatar = invitee.Avatar
+		}
+		return referral
+	})
+}
+
+// CastAuditLogDOs2DTOs 账号安全记录
+func CastAuditLogDOs2DTOs(dos []*entity.AuditLog) []*def.AuditLog {
+	return gslice.Map(dos, func(do *entity.AuditLog) *def.AuditLog {
+		return &def.AuditLog{
+			LogID:     do.LogID,
+			UserID:    do.UserID,
+			ActorID:   do.ActorID,
+			Action:    do.Action,
+			Result:    do.Result,
+			Detail:    do.Detail,
+			IP:        do.IP,
+			Device:    util.ParseDevice(do.UserAgent),
+			UserAgent: do.UserAgent,
+			CreatedAt: do.CreatedAt,
+		}
+	})
+}
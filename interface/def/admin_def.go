@@ -0,0 +1,170 @@
+package def
+
+import "time"
+
+// ---------用户管理（管理接口）-----------
+type AdminListUsersReq struct {
+	Keyword  string `form:"keyword"` // 用户名/昵称/手机号/邮箱关键词
+	Status   *int   `form:"status"`  // 1 正常，0 禁用，不传表示不限
+	Role     string `form:"role"`    // user/admin，不传表示不限
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+type AdminUser struct {
+	UserID              string     `json:"user_id"`
+	UserName            string     `json:"user_name"`
+	Nickname            string     `json:"nickname,omitempty"`
+	Avatar              string     `json:"avatar,omitempty"`
+	Phone               string     `json:"phone,omitempty"`
+	Email               string     `json:"email,omitempty"`
+	Role                string     `json:"role"`
+	Status              int        `json:"status"`
+	SuspendReason       string     `json:"suspend_reason,omitempty"`
+	SuspendedUntil      *time.Time `json:"suspended_until,omitempty"` // 禁用到期时间，为空表示需手动恢复
+	Plan                string     `json:"plan"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastLoginAt         *time.Time `json:"last_login_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"` // 已申请注销时为计划删除时间
+}
+
+type AdminListUsersResp struct {
+	List     []*AdminUser `json:"list"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+	Success  bool         `json:"success"`
+}
+
+type AdminUpdateUserStatusReq struct {
+	UserID string `json:"user_id" binding:"required"`
+	Status *int   `json:"status" binding:"required"` // 1 启用，0 禁用
+}
+
+type AdminUpdateUserStatusResp struct {
+	Success bool `json:"success"`
+}
+
+type AdminSuspendUserReq struct {
+	UserID string     `json:"user_id" binding:"required"`
+	Reason string     `json:"reason"` // 禁用原因，最多255个字符
+	Until  *time.Time `json:"until"`  // 到期后自动恢复，RFC3339 格式，不传表示需手动恢复
+}
+
+type AdminSuspendUserResp struct {
+	Success bool `json:"success"`
+}
+
+type AdminReactivateUserReq struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type AdminReactivateUserResp struct {
+	Success bool `json:"success"`
+}
+
+type AdminUpdateUserRoleReq struct {
+	UserID string `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"required"` // user/admin
+}
+
+type AdminUpdateUserRoleResp struct {
+	Success bool `json:"success"`
+}
+
+type AdminResetPasswordReq struct {
+	UserID      string `json:"user_id" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type AdminResetPasswordResp struct {
+	Success bool `json:"success"`
+}
+
+type AdminListUserSessionsReq struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+type AdminListUserSessionsResp struct {
+	Sessions []*Session `json:"sessions"`
+	Success  bool       `json:"success"`
+}
+
+type AdminListAuditLogsReq struct {
+	UserID   string `form:"user_id"`  // 事件所属的用户，不传表示不限
+	ActorID  string `form:"actor_id"` // 操作人，不传表示不限
+	Action   string `form:"action"`   // 事件类型，不传表示不限
+	Result   string `form:"result"`   // success/failure，不传表示不限
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+type AdminListAuditLogsResp struct {
+	List     []*AuditLog `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Success  bool        `json:"success"`
+}
+
+// ---------AI回复评价（管理接口）-----------
+type AdminExportMessageFeedbackReq struct {
+	Rating    string `form:"rating"`     // up/down，不传表示不限
+	PresetID  string `form:"preset_id"`  // 评价时会话使用的预设，不传表示不限
+	StartDate string `form:"start_date"` // 最后评价日期不早于，格式 2006-01-02
+	EndDate   string `form:"end_date"`   // 最后评价日期不晚于（含当天）
+	Page      int    `form:"page,default=1"`
+	PageSize  int    `form:"page_size,default=100"`
+}
+
+type AdminMessageFeedback struct {
+	ConversationID string    `json:"conversation_id"`
+	MessageID      string    `json:"message_id"`
+	UserID         string    `json:"user_id"`
+	Rating         string    `json:"rating"`
+	Comment        string    `json:"comment"`
+	Prompt         string    `json:"prompt"` // 该回复之前的最后一条用户消息
+	Reply          string    `json:"reply"`
+	Provider       string    `json:"provider"`
+	Model          string    `json:"model"`
+	PresetID       string    `json:"preset_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type AdminExportMessageFeedbackResp struct {
+	List     []*AdminMessageFeedback `json:"list"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Success  bool                    `json:"success"`
+}
+
+// ---------后台任务（管理接口）-----------
+type AdminJob struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Exclusive      bool       `json:"exclusive"` // 多实例部署时每次计划执行只由一个实例执行
+	Running        bool       `json:"running"`
+	NextRunAt      *time.Time `json:"next_run_at"` // 本实例的下一次计划执行时间
+	LastRunAt      *time.Time `json:"last_run_at"` // 从未执行时为空
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error"`
+	LastAttempts   int        `json:"last_attempts"`
+	LastInstance   string     `json:"last_instance"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+}
+
+// AdminTaskQueue 延迟任务队列中各状态的任务数
+type AdminTaskQueue struct {
+	Pending    int64 `json:"pending"`
+	Processing int64 `json:"processing"`
+	Dead       int64 `json:"dead"`
+}
+
+type AdminListJobsResp struct {
+	Jobs    []*AdminJob     `json:"jobs"`
+	Tasks   *AdminTaskQueue `json:"tasks"` // 统计失败时为空
+	Success bool            `json:"success"`
+}
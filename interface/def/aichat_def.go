@@ -11,18 +11,50 @@ type ProcessUserMessageRequest struct {
 	ConversationID string `json:"conversation_id" binding:"required"`
 	Content        string `json:"content" binding:"required"`
 	MapData        string `json:"map_data"`
+	Model          string `json:"model"` // 可选，按提供方名称或模型名称选择
+	// 可选的模型参数，超出范围时收敛到边界，未指定的使用会话预设或配置的默认值
+	Temperature      *float32 `json:"temperature"`       // 0 ~ 2
+	TopP             *float32 `json:"top_p"`             // 0 ~ 1
+	MaxTokens        *int     `json:"max_tokens"`        // 单次回复的最大 token 数，不超过配置的上限
+	FrequencyPenalty *float32 `json:"frequency_penalty"` // -2 ~ 2
+	// 可选的图片，所选模型不支持图片时改用配置的支持图片的模型
+	Images []MessageImageInput `json:"images" binding:"omitempty,dive"`
+}
+
+// MessageImageInput 消息附带的图片，key 与 data 二选一
+type MessageImageInput struct {
+	Key  string `json:"key"`  // 已上传到对象存储的图片，须在当前用户目录 user/{user_id}/ 下
+	Data string `json:"data"` // base64 编码的图片内容，可带 data:image/png;base64, 前缀
 }
 
 type ProcessUserMessageResponse struct {
-	NewMapJson string `json:"new_map_json"`
-	Content    string `json:"content"`
-	Success    bool   `json:"success"`
+	NewMapJson string          `json:"new_map_json"`
+	Content    string          `json:"content"`
+	Success    bool            `json:"success"`
+	Provider   string          `json:"provider"`
+	Model      string          `json:"model"`
+	Changes    []MindMapChange `json:"changes"`              // ai对导图的修改，按执行顺序排列
+	EditError  string          `json:"edit_error,omitempty"` // ai提出的导图操作未能应用的原因
+}
+
+// MindMapChange 已应用的导图操作，path 为节点在导图中的位置（根节点下各层子节点的下标）
+type MindMapChange struct {
+	Op      string `json:"op"`
+	Path    []int  `json:"path"`
+	Text    string `json:"text,omitempty"`
+	OldText string `json:"old_text,omitempty"`
+}
+
+// ProcessUserMessageDelta 流式对话中模型输出的文本片段，对应 SSE 的 delta 事件
+type ProcessUserMessageDelta struct {
+	Content string `json:"content"`
 }
 
 type SaveNewConversationRequest struct {
-	Title   string `json:"title" binding:"required"`
-	MapID   string `json:"map_id" binding:"required"`
-	MapData string `json:"map_data"`
+	Title    string `json:"title" binding:"required"`
+	MapID    string `json:"map_id" binding:"required"`
+	MapData  string `json:"map_data"`
+	PresetID string `json:"preset_id"` // 可选，会话使用的对话预设
 }
 
 type SaveNewConversationResponse struct {
@@ -31,12 +63,16 @@ type SaveNewConversationResponse struct {
 }
 
 type GetConversationListRequest struct {
-	MapID string `json:"map_id" binding:"required"`
+	MapID    string `json:"map_id" form:"map_id" binding:"required"`
+	Archived bool   `json:"archived" form:"archived"` // 为 true 时只列出已归档的会话
 }
 
 type ConversationData struct {
 	ConversationID string    `json:"conversation_id"`
 	Title          string    `json:"title"`
+	PresetID       string    `json:"preset_id"`
+	Archived       bool      `json:"archived"`
+	Pinned         bool      `json:"pinned"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
@@ -54,14 +90,60 @@ type DelConversationResponse struct {
 	Success bool `json:"success"`
 }
 
-type GetConversationRequest struct {
+type RestoreConversationRequest struct {
 	ConversationID string `json:"conversation_id" binding:"required"`
 }
 
+type RestoreConversationResponse struct {
+	Success bool `json:"success"`
+}
+
+type GetConversationRequest struct {
+	ConversationID  string `json:"conversation_id" form:"conversation_id" binding:"required"`
+	BeforeMessageID string `json:"before_message_id" form:"before_message_id"`           // 分页游标，返回该消息之前的消息
+	Limit           int    `json:"limit" form:"limit" binding:"omitempty,min=1,max=200"` // 每页条数，不传时返回全部消息
+}
+
 type GetConversationResponse struct {
-	Title    string            `json:"title"`
-	Messages []*entity.Message `json:"messages"`
-	Success  bool              `json:"success"`
+	Title    string                         `json:"title"`
+	PresetID string                         `json:"preset_id"`
+	Messages []*entity.Message              `json:"messages"`
+	HasMore  bool                           `json:"has_more"` // 是否还有更早的消息，以本页第一条消息的 message_id 作为下一页的 before_message_id
+	Feedback map[string]MessageFeedbackData `json:"feedback"` // 本页消息中已评价的AI回复，按 message_id 索引
+	Success  bool                           `json:"success"`
+}
+
+type ForkConversationRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	MessageID      string `json:"message_id" binding:"required"` // 复制到该消息为止（含）
+	Title          string `json:"title"`                         // 可选，默认为原标题加“（分支）”
+}
+
+type ForkConversationResponse struct {
+	ConversationID string `json:"conversation_id"`
+	Success        bool   `json:"success"`
+}
+
+type SummarizeMindMapRequest struct {
+	MapID          string `json:"map_id" binding:"required"`
+	ConversationID string `json:"conversation_id"` // 可选，总结保存到该会话，为空时新建会话
+	Model          string `json:"model"`           // 可选，按提供方名称或模型名称选择
+}
+
+type SummarizeMindMapResponse struct {
+	ConversationID string          `json:"conversation_id"`
+	MessageID      string          `json:"message_id"`
+	Summary        string          `json:"summary"`
+	Branches       []BranchSummary `json:"branches"`
+	Provider       string          `json:"provider"`
+	Model          string          `json:"model"`
+	Success        bool            `json:"success"`
+}
+
+// BranchSummary 一级分支的要点
+type BranchSummary struct {
+	Title     string   `json:"title"`
+	KeyPoints []string `json:"key_points"`
 }
 
 type UpdateConversationTitleRequest struct {
@@ -73,12 +155,163 @@ type UpdateConversationTitleResponse struct {
 	Success bool `json:"success"`
 }
 
+type ArchiveConversationRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	Archived       bool   `json:"archived"` // false 表示取消归档
+}
+
+type ArchiveConversationResponse struct {
+	Success bool `json:"success"`
+}
+
+type PinConversationRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	Pinned         bool   `json:"pinned"` // false 表示取消置顶
+}
+
+type PinConversationResponse struct {
+	Success bool `json:"success"`
+}
+
+type ReorderConversationsRequest struct {
+	MapID           string   `json:"map_id" binding:"required"`
+	ConversationIDs []string `json:"conversation_ids" binding:"required"` // 调整后的顺序
+}
+
+type ReorderConversationsResponse struct {
+	Success bool `json:"success"`
+}
+
+type SearchConversationsRequest struct {
+	Q        string `form:"q" binding:"required"`
+	Archived *bool  `form:"archived"` // 不传表示不区分是否归档
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+type ConversationSearchHit struct {
+	ConversationID string    `json:"conversation_id"`
+	MapID          string    `json:"map_id"`
+	Title          string    `json:"title"`
+	Archived       bool      `json:"archived"`
+	MessageID      string    `json:"message_id,omitempty"` // 第一条匹配的消息，只有标题匹配时为空
+	Snippet        string    `json:"snippet,omitempty"`    // 匹配消息中关键词前后的内容
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type SearchConversationsResponse struct {
+	List     []ConversationSearchHit `json:"list"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Success  bool                    `json:"success"`
+}
+
+type SetConversationPresetRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	PresetID       string `json:"preset_id"` // 为空时恢复默认
+}
+
+type SetConversationPresetResponse struct {
+	Success bool `json:"success"`
+}
+
+type PromptPresetRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Description      string   `json:"description"`
+	SystemPrompt     string   `json:"system_prompt" binding:"required"`
+	Temperature      *float32 `json:"temperature"`       // 可选，0 ~ 2
+	TopP             *float32 `json:"top_p"`             // 可选，0 ~ 1
+	MaxTokens        *int     `json:"max_tokens"`        // 可选，单次回复的最大 token 数
+	FrequencyPenalty *float32 `json:"frequency_penalty"` // 可选，-2 ~ 2
+}
+
+type PromptPresetData struct {
+	PresetID         string    `json:"preset_id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	SystemPrompt     string    `json:"system_prompt"`
+	Temperature      *float32  `json:"temperature,omitempty"`
+	TopP             *float32  `json:"top_p,omitempty"`
+	MaxTokens        *int      `json:"max_tokens,omitempty"`
+	FrequencyPenalty *float32  `json:"frequency_penalty,omitempty"`
+	Global           bool      `json:"global"` // 是否为管理员创建的全局预设
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type PromptPresetResponse struct {
+	Preset  PromptPresetData `json:"preset"`
+	Success bool             `json:"success"`
+}
+
+type ListPromptPresetsResponse struct {
+	List    []PromptPresetData `json:"list"`
+	Success bool               `json:"success"`
+}
+
+type DeletePromptPresetResponse struct {
+	Success bool `json:"success"`
+}
+
 type GenerateMindMapRequest struct {
-	Text string `json:"text"` //预留文本字段
-	File *multipart.FileHeader
+	Text           string `json:"text"`            //预留文本字段
+	ConversationID string `json:"conversation_id"` //关联的会话，上传的文件会归档到该会话下
+	File           *multipart.FileHeader
 }
 
 type GenerateMindMapResponse struct {
 	Success bool   `json:"success"`
 	MapJson string `json:"map_json"`
+	Cached  bool   `json:"cached"`            //相同输入命中缓存，未重新生成
+	FileID  string `json:"file_id,omitempty"` //上传文件归档后的ID
+}
+
+type GetAttachmentsRequest struct {
+	ConversationID string `json:"conversation_id" form:"conversation_id" binding:"required"`
+}
+
+type AttachmentData struct {
+	FileID      string    `json:"file_id"`
+	Filename    string    `json:"filename"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type GetAttachmentsResponse struct {
+	List    []AttachmentData `json:"list"`
+	Success bool             `json:"success"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`     // 输入 token 数
+	CompletionTokens int64 `json:"completion_tokens"` // 输出 token 数
+	TotalTokens      int64 `json:"total_tokens"`      // 合计，配额按合计计算
+}
+
+type GetAiUsageResponse struct {
+	Today            TokenUsage `json:"today"`               // 今日用量
+	ThisMonth        TokenUsage `json:"this_month"`          // 本月用量
+	AITokensPerDay   int64      `json:"ai_tokens_per_day"`   // 每日上限，0 表示不限制
+	AITokensPerMonth int64      `json:"ai_tokens_per_month"` // 每月上限，0 表示不限制
+	Success          bool       `json:"success"`
+}
+
+type MessageFeedbackData struct {
+	Rating    string    `json:"rating"` // up/down
+	Comment   string    `json:"comment"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SubmitMessageFeedbackRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	MessageID      string `json:"message_id" binding:"required"`
+	Rating         string `json:"rating" binding:"omitempty,oneof=up down"` // up/down，为空表示撤销评价
+	Comment        string `json:"comment" binding:"max=500"`                // 可选，最多500个字符
+}
+
+type SubmitMessageFeedbackResponse struct {
+	Success bool `json:"success"`
 }
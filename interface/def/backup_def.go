@@ -0,0 +1,34 @@
+package def
+
+import "time"
+
+// ---------备份（管理接口）----------
+type BackupInfo struct {
+	BackupID          string    `json:"backup_id"`
+	UserID            string    `json:"user_id"`
+	Size              int64     `json:"size"`   // 压缩后大小（字节）
+	SHA256            string    `json:"sha256"` // 备份文件校验和
+	MindMapCount      int64     `json:"mind_map_count"`
+	ConversationCount int64     `json:"conversation_count"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ListBackupsReq struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+type ListBackupsResp struct {
+	Backups []BackupInfo `json:"backups"`
+	Success bool         `json:"success"`
+}
+
+type RestoreBackupReq struct {
+	BackupID string `json:"backup_id" binding:"required"`
+}
+
+type RestoreBackupResp struct {
+	RestoredMindMaps      int64 `json:"restored_mind_maps"`     // 重新创建的导图数（使用新的导图ID）
+	RestoredConversations int64 `json:"restored_conversations"` // 重新创建的会话数
+	SkippedMindMaps       int64 `json:"skipped_mind_maps"`      // 仍然存在而跳过的导图数
+	Success               bool  `json:"success"`
+}
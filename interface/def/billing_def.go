@@ -0,0 +1,41 @@
+package def
+
+import "time"
+
+// ---------支付与账单----------
+type CreateCheckoutReq struct {
+	Product string `json:"product" binding:"required"` // 商品标识
+}
+
+type CreateCheckoutResp struct {
+	InvoiceID   string `json:"invoice_id"`
+	CheckoutURL string `json:"checkout_url"` // 跳转到该地址完成支付
+	Success     bool   `json:"success"`
+}
+
+type Invoice struct {
+	InvoiceID  string     `json:"invoice_id"`
+	Product    string     `json:"product"`
+	Plan       string     `json:"plan"`
+	PeriodDays int        `json:"period_days"`
+	Amount     int64      `json:"amount"` // 最小货币单位（如分）
+	Currency   string     `json:"currency"`
+	Status     string     `json:"status"` // pending/paid/failed/expired
+	PaidAt     *time.Time `json:"paid_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ListInvoicesResp struct {
+	List    []Invoice `json:"list"`
+	Success bool      `json:"success"`
+}
+
+// 支付渠道回调，请求体需保持原样用于验签
+type PaymentWebhookReq struct {
+	Payload   []byte `json:"-"`
+	Signature string `json:"-"`
+}
+
+type PaymentWebhookResp struct {
+	Received bool `json:"received"`
+}
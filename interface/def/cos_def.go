@@ -1,9 +1,15 @@
 package def
 
+import (
+	"io"
+	"time"
+)
+
 // GetOSSCredentialsReq 获取OSS凭证请求
 type GetOSSCredentialsReq struct {
-	ResourcePath    string `json:"resource_path" binding:"required"` // 资源路径，如 user/123/avatar/profile.jpg
-	DurationSeconds int64  `json:"duration_seconds"`                 // 有效期（秒），可选，默认3600，范围900-7200（最短15分钟，最长2小时）
+	Purpose         string `json:"purpose"`          // 用途：avatar、attachment、export，可选，为空时取资源路径中的用途目录
+	ResourcePath    string `json:"resource_path"`    // 资源路径，如 user/123/avatar/profile.jpg，末尾可以为 *；为空时为该用途的整个目录
+	DurationSeconds int64  `json:"duration_seconds"` // 有效期（秒），可选，默认按用途配置，最短900，上限按用途（头像、导出15分钟，附件1小时）
 }
 
 // GetOSSCredentialsResp 获取OSS凭证响应
@@ -14,7 +20,75 @@ type GetOSSCredentialsResp struct {
 	Expiration      int64  `json:"expiration"`        // 过期时间（Unix时间戳）
 	Region          string `json:"region"`            // OSS地域
 	BucketName      string `json:"bucket_name"`       // 存储桶名称
-	Provider        string `json:"provider"`          // 提供商（cos）
+	Provider        string `json:"provider"`          // 提供商（cos、s3、minio、oss）
+	Endpoint        string `json:"endpoint"`          // 服务地址，s3、minio、oss 直传时使用
 	BaseURL         string `json:"base_url"`          // 访问基础URL
 	AccountID       string `json:"account_id"`        // 账户ID
+	ResourcePath    string `json:"resource_path"`     // 凭证允许访问的资源路径
+}
+
+// PresignDownloadReq 生成下载链接请求
+type PresignDownloadReq struct {
+	ResourcePath  string `form:"resource_path" binding:"required"` // 资源路径，只能是自己目录下的文件，如 user/123/attachment/1_a.pdf
+	ExpireSeconds int64  `form:"expire_seconds"`                   // 有效期（秒），可选，默认600，范围60到配置的上限（默认3600）
+}
+
+// PresignDownloadResp 生成下载链接响应
+type PresignDownloadResp struct {
+	URL       string `json:"url"`        // 下载链接
+	Mode      string `json:"mode"`       // presign 为 COS 签名链接，proxy 为经服务端转发的链接
+	ExpiresAt int64  `json:"expires_at"` // 过期时间（Unix时间戳）
+}
+
+// ProxyDownloadReq 转发下载请求
+type ProxyDownloadReq struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// ProxyDownloadResp 转发下载的文件，由路由层写入响应体后关闭 Body
+type ProxyDownloadResp struct {
+	FileName      string        `json:"-"`
+	ContentType   string        `json:"-"`
+	ContentLength int64         `json:"-"`
+	Body          io.ReadCloser `json:"-"`
+}
+
+// ListFilesReq 文件列表请求
+type ListFilesReq struct {
+	Purpose  string `form:"purpose"`   // 文件用途，可选：attachment、export、chat_image、avatar
+	Page     int    `form:"page"`      // 页码，从 1 开始
+	PageSize int    `form:"page_size"` // 每页数量，默认20，最多99
+}
+
+// FileInfo 上传的文件
+type FileInfo struct {
+	FileID         string    `json:"file_id"`
+	ResourcePath   string    `json:"resource_path"`
+	URL            string    `json:"url"`
+	Filename       string    `json:"filename"`
+	ContentType    string    `json:"content_type"`
+	Size           int64     `json:"size"`
+	Hash           string    `json:"hash"` // 内容的 SHA-256（十六进制）
+	Purpose        string    `json:"purpose"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	Status         string    `json:"status"`                // active，感染病毒时为 quarantined（未保存，只能删除记录）
+	ScanResult     string    `json:"scan_result,omitempty"` // 隔离文件命中的病毒特征
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ListFilesResp 文件列表响应
+type ListFilesResp struct {
+	List    []FileInfo `json:"list"`
+	Total   int64      `json:"total"`
+	Success bool       `json:"success"`
+}
+
+// DeleteFileReq 删除文件请求
+type DeleteFileReq struct {
+	FileID string `json:"-"` // 路径参数
+}
+
+// DeleteFileResp 删除文件响应
+type DeleteFileResp struct {
+	Success bool `json:"success"`
 }
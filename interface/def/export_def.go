@@ -0,0 +1,11 @@
+package def
+
+import "time"
+
+// ---------数据导出----------
+type ExportDataResp struct {
+	Status    string     `json:"status"`               // pending 导出中，ready 可下载
+	URL       string     `json:"url,omitempty"`        // 下载链接，导出完成后返回
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 下载链接过期时间
+	Success   bool       `json:"success"`
+}
@@ -1,19 +1,50 @@
 package def
 
+import (
+	"mime/multipart"
+	"time"
+)
+
 // 创建请求
 type CreateMindMapReq struct {
-	Title  string      `json:"title" binding:"required,max=100"`
-	Desc   string      `json:"desc" binding:"max=500"`
-	Layout string      `json:"layout" binding:"required"`
-	Root   MindMapData `json:"root" binding:"required"`
+	Title    string      `json:"title" binding:"required,max=100"`
+	Desc     string      `json:"desc" binding:"max=500"`
+	Layout   string      `json:"layout" binding:"required"`
+	Root     MindMapData `json:"root" binding:"required"`
+	FolderID string      `json:"folder_id"` // 可选，创建到自己的文件夹中
+}
+
+// 纯文本大纲创建请求
+type CreateMindMapFromTextReq struct {
+	Title  string `json:"title" binding:"max=100"` // 可选，为空时使用大纲根节点文本
+	Desc   string `json:"desc" binding:"max=500"`
+	Layout string `json:"layout" binding:"required"`
+	Text   string `json:"text" binding:"required"` // 缩进/项目符号形式的纯文本大纲
+}
+
+// 导入请求，multipart/form-data 上传 xmind/mm/opml/md 文件
+type ImportMindMapReq struct {
+	Title  string                `form:"title" binding:"max=100"` // 可选，为空时使用导图根节点文本
+	Desc   string                `form:"desc" binding:"max=500"`
+	Layout string                `form:"layout" binding:"required"`
+	File   *multipart.FileHeader `form:"file" binding:"required"`
 }
 
 // 列表查询请求
 type ListMindMapsReq struct {
 	Title    string `form:"title"`
 	Layout   string `form:"layout"`
+	Scope    string `form:"scope" binding:"omitempty,oneof=owned shared all"` // owned（默认）：自己的导图，shared：与我共享的导图，all：两者
+	FolderID string `form:"folder_id"`                                        // 只查询自己在该文件夹中的导图，root 表示不在任何文件夹中
+	Tags     string `form:"tags"`                                             // 逗号分隔的标签，只查询自己同时带有这些标签的导图，最多5个
+	Starred  *bool  `form:"is_starred"`                                       // true 只查询收藏的导图，false 只查询未收藏的导图
 	Page     int    `form:"page,default=1"`
 	PageSize int    `form:"page_size,default=20"`
+	// 排序与游标分页：cursor 为上一页返回的 next_cursor，指定时忽略 page，需与上一页使用相同的排序方式
+	// sort_by 为 starred 时收藏的导图在前，其余按更新时间
+	SortBy string `form:"sort_by" binding:"omitempty,oneof=updated_at created_at title starred"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"` // 为空时标题升序、时间降序
+	Cursor string `form:"cursor"`
 }
 
 // 更新请求
@@ -32,12 +63,16 @@ type MindMapDTO struct {
 	Desc      string      `json:"desc"`
 	Layout    string      `json:"layout"`
 	Root      MindMapData `json:"root"`
+	FolderID  string      `json:"folderId,omitempty"`
+	Tags      []string    `json:"tags,omitempty"`
+	Starred   bool        `json:"starred"` // 当前用户是否收藏
 	CreatedAt string      `json:"createdAt,omitempty"`
 	UpdatedAt string      `json:"updatedAt,omitempty"`
 }
 
 // 节点数据DTO
 type NodeData struct {
+	ID   string `json:"id,omitempty"` // 节点ID，为空时保存后由服务端生成，修改导图时需原样传回
 	Text string `json:"text"`
 	// 可扩展其他节点属性，如颜色、图标等
 }
@@ -58,10 +93,14 @@ type GetMindMapResp struct {
 }
 
 type ListMindMapsResp struct {
-	List     []*MindMapDTO `json:"list"`
-	Total    int64         `json:"total"`
-	Page     int           `json:"page"`
-	PageSize int           `json:"page_size"`
+	List     []*MindMapDTO    `json:"list"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Facets   []*MindMapTagDTO `json:"facets"` // 筛选条件下自己的导图中各标签的导图数
+	// 还有下一页时为下一页的游标
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
 
 type UpdateMindMapResp struct {
@@ -71,3 +110,312 @@ type UpdateMindMapResp struct {
 type DeleteMindMapResp struct {
 	Success bool `json:"success"`
 }
+
+// 创建分享链接请求
+type ShareMindMapReq struct {
+	Password    string `json:"password,omitempty"`                     // 访问密码，为空表示不需要密码
+	ExpireHours int    `json:"expire_hours,omitempty" binding:"min=0"` // 有效期（小时），为空时使用默认有效期
+}
+
+// 分享链接DTO
+type MindMapShareDTO struct {
+	ShareID     string `json:"shareId"`
+	Token       string `json:"token"`
+	URL         string `json:"url,omitempty"`
+	HasPassword bool   `json:"hasPassword"`
+	ExpiresAt   string `json:"expiresAt"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type ShareMindMapResp struct {
+	*MindMapShareDTO
+}
+
+type ListMindMapSharesResp struct {
+	List []*MindMapShareDTO `json:"list"`
+}
+
+type RevokeMindMapShareResp struct {
+	Success bool `json:"success"`
+}
+
+// 查看分享的导图请求，访问密码通过请求头 X-Share-Password 传递，不出现在链接与日志中
+type GetSharedMindMapReq struct {
+	Token    string `form:"token" binding:"required"`
+	Password string `form:"-" json:"-"`
+}
+
+// 分享的导图，只读，不包含导图ID与用户ID
+type GetSharedMindMapResp struct {
+	Title     string      `json:"title"`
+	Desc      string      `json:"desc"`
+	Layout    string      `json:"layout"`
+	Root      MindMapData `json:"root"`
+	UpdatedAt string      `json:"updatedAt,omitempty"`
+	ExpiresAt string      `json:"expiresAt"`
+}
+
+// 添加协作者请求，已是协作者时修改角色
+type AddMindMapMemberReq struct {
+	Account     string `json:"account" binding:"required"`
+	AccountType string `json:"account_type" binding:"required,oneof=phone email"` // 手机号或邮箱
+	Role        string `json:"role" binding:"required,oneof=viewer editor"`
+}
+
+// 协作者DTO
+type MindMapMemberDTO struct {
+	UserID    string `json:"userId"`
+	Nickname  string `json:"nickname,omitempty"` // 未设置昵称时为用户名
+	Avatar    string `json:"avatar,omitempty"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+type AddMindMapMemberResp struct {
+	*MindMapMemberDTO
+}
+
+// 协作者列表，第一项为所有者
+type ListMindMapMembersResp struct {
+	List []*MindMapMemberDTO `json:"list"`
+}
+
+type RemoveMindMapMemberResp struct {
+	Success bool `json:"success"`
+}
+
+// 导出请求
+type ExportMindMapReq struct {
+	Format   string `form:"format" binding:"required,oneof=xmind mm opml md svg png"`
+	Delivery string `form:"delivery" binding:"omitempty,oneof=download url"` // download（默认）：直接下载文件，url：返回临时下载链接
+}
+
+// 导出结果，直接下载时只使用文件名、类型与内容，不返回JSON
+type ExportMindMapResp struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"-"`
+	Data        []byte `json:"-"`
+	URL         string `json:"url,omitempty"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+}
+
+// 创建文件夹请求
+type CreateMindMapFolderReq struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	ParentID string `json:"parent_id"` // 可选，为空表示顶层
+}
+
+// 修改文件夹请求，未传的字段不修改
+type UpdateMindMapFolderReq struct {
+	Name     *string `json:"name" binding:"omitempty,max=50"`
+	ParentID *string `json:"parent_id"` // 空字符串表示移到顶层
+}
+
+// 文件夹DTO
+type MindMapFolderDTO struct {
+	FolderID  string `json:"folderId"`
+	ParentID  string `json:"parentId,omitempty"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type CreateMindMapFolderResp struct {
+	*MindMapFolderDTO
+}
+
+type UpdateMindMapFolderResp struct {
+	*MindMapFolderDTO
+}
+
+// 自己的所有文件夹，按 parentId 组装层级
+type ListMindMapFoldersResp struct {
+	List []*MindMapFolderDTO `json:"list"`
+}
+
+type DeleteMindMapFolderResp struct {
+	Success bool `json:"success"`
+}
+
+// 移动导图请求，folder_id 为空表示移出文件夹
+type MoveMindMapReq struct {
+	FolderID string `json:"folder_id"`
+}
+
+type MoveMindMapResp struct {
+	Success bool `json:"success"`
+}
+
+// 设置导图标签请求，替换导图的全部标签，空列表表示清空
+type SetMindMapTagsReq struct {
+	Tags []string `json:"tags" binding:"max=10,dive,max=20"`
+}
+
+type SetMindMapTagsResp struct {
+	Tags []string `json:"tags"`
+}
+
+type RemoveMindMapTagResp struct {
+	Success bool `json:"success"`
+}
+
+// 标签DTO
+type MindMapTagDTO struct {
+	Name     string `json:"name"`
+	MapCount int64  `json:"mapCount"`
+}
+
+// 自己的所有标签及各标签的导图数
+type ListMindMapTagsResp struct {
+	List []*MindMapTagDTO `json:"list"`
+}
+
+// 全文搜索请求
+type SearchMindMapsReq struct {
+	Q        string `form:"q" binding:"required,max=100"` // 空白分隔的关键词，标题或节点文本需包含全部关键词
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// 搜索结果，titleHighlight 与 snippets 中的关键词用 <em> 标出，其余文本已做 HTML 转义
+type MindMapSearchHitDTO struct {
+	MapID          string   `json:"mapId"`
+	UserID         string   `json:"userId"`
+	Title          string   `json:"title"`
+	TitleHighlight string   `json:"titleHighlight,omitempty"`
+	Snippets       []string `json:"snippets"`
+	UpdatedAt      string   `json:"updatedAt,omitempty"`
+}
+
+type SearchMindMapsResp struct {
+	List     []*MindMapSearchHitDTO `json:"list"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
+// 创建或修改模板请求（管理接口）
+type MindMapTemplateReq struct {
+	Title    string      `json:"title" binding:"required,max=100"`
+	Desc     string      `json:"desc" binding:"max=500"`
+	Category string      `json:"category" binding:"required,max=20"`
+	Layout   string      `json:"layout" binding:"required"`
+	Root     MindMapData `json:"root" binding:"required"`
+	Sort     int         `json:"sort"` // 同一分类中按从小到大排列
+}
+
+// 模板DTO
+type MindMapTemplateDTO struct {
+	TemplateID string      `json:"templateId"`
+	Title      string      `json:"title"`
+	Desc       string      `json:"desc"`
+	Category   string      `json:"category"`
+	Layout     string      `json:"layout"`
+	Root       MindMapData `json:"root"`
+	Sort       int         `json:"sort"`
+	UpdatedAt  string      `json:"updatedAt"`
+}
+
+type MindMapTemplateResp struct {
+	*MindMapTemplateDTO
+}
+
+type DeleteMindMapTemplateResp struct {
+	Success bool `json:"success"`
+}
+
+// 模板列表请求
+type ListMindMapTemplatesReq struct {
+	Category string `form:"category"` // 为空时返回全部模板
+}
+
+// 模板列表，categories 为全部分类，不受筛选影响
+type ListMindMapTemplatesResp struct {
+	Categories []string              `json:"categories"`
+	List       []*MindMapTemplateDTO `json:"list"`
+}
+
+// 基于模板创建导图请求
+type CreateMindMapFromTemplateReq struct {
+	Title    string `json:"title" binding:"max=100"` // 可选，为空时使用模板标题
+	FolderID string `json:"folder_id"`               // 可选，创建到自己的文件夹中
+}
+
+// 变更记录列表请求，since 为 RFC3339 时间，只返回该时间之后的记录
+type ListMindMapActivitiesReq struct {
+	Since    time.Time `form:"since"`
+	Page     int       `form:"page,default=1"`
+	PageSize int       `form:"page_size,default=20"`
+}
+
+// 变更记录DTO
+// type 为 node_added/node_edited/node_deleted/renamed/shared/member_added；
+// path 为节点路径（从根节点开始逐层的子节点下标），node_deleted 为删除前的位置；
+// member_added 的 text 为协作者角色，targetUserId 为协作者
+type MindMapActivityDTO struct {
+	ActivityID    string `json:"activityId"`
+	Type          string `json:"type"`
+	ActorID       string `json:"actorId"`
+	ActorNickname string `json:"actorNickname,omitempty"`
+	ActorAvatar   string `json:"actorAvatar,omitempty"`
+	Path          []int  `json:"path,omitempty"`
+	Text          string `json:"text,omitempty"`
+	OldText       string `json:"oldText,omitempty"`
+	TargetUserID  string `json:"targetUserId,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+// 变更记录列表，按时间倒序
+type ListMindMapActivitiesResp struct {
+	List     []*MindMapActivityDTO `json:"list"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// 创建评论请求
+type CreateMindMapCommentReq struct {
+	NodeID  string `json:"node_id" binding:"required,max=64"`
+	Content string `json:"content" binding:"required"`
+}
+
+// 评论列表请求
+type ListMindMapCommentsReq struct {
+	NodeID   string `form:"node_id"`                                        // 为空时返回整个导图的评论
+	Status   string `form:"status" binding:"omitempty,oneof=open resolved"` // open/resolved，为空时返回全部
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// 评论DTO，节点已被删除的评论仍会返回，由前端决定如何展示
+type MindMapCommentDTO struct {
+	CommentID  string `json:"commentId"`
+	NodeID     string `json:"nodeId"`
+	UserID     string `json:"userId"`
+	Nickname   string `json:"nickname,omitempty"` // 未设置昵称时为用户名
+	Avatar     string `json:"avatar,omitempty"`
+	Content    string `json:"content"`
+	Resolved   bool   `json:"resolved"`
+	ResolvedBy string `json:"resolvedBy,omitempty"`
+	ResolvedAt string `json:"resolvedAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type MindMapCommentResp struct {
+	*MindMapCommentDTO
+}
+
+type ListMindMapCommentsResp struct {
+	List     []*MindMapCommentDTO `json:"list"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+type DeleteMindMapCommentResp struct {
+	Success bool `json:"success"`
+}
+
+type StarMindMapResp struct {
+	Success bool `json:"success"`
+}
@@ -0,0 +1,64 @@
+package def
+
+import "time"
+
+// ---------套餐与配额----------
+type PlanLimits struct {
+	MaxMindMaps         int64 `json:"max_mind_maps"`          // 最多导图数量，0 表示不限制
+	MaxNodesPerMap      int64 `json:"max_nodes_per_map"`      // 单个导图最多节点数量，0 表示不限制
+	MaxStorageBytes     int64 `json:"max_storage_bytes"`      // 最大存储空间（字节），0 表示不限制
+	AIRequestsPerDay    int64 `json:"ai_requests_per_day"`    // 每日AI调用次数，0 表示不限制
+	AIRequestsPerMinute int64 `json:"ai_requests_per_minute"` // 每分钟AI调用次数，0 表示不限制
+	AIConcurrency       int64 `json:"ai_concurrency"`         // 同时进行中的AI生成任务数，0 表示不限制
+	AITokensPerDay      int64 `json:"ai_tokens_per_day"`      // 每日AI token 用量，0 表示不限制
+	AITokensPerMonth    int64 `json:"ai_tokens_per_month"`    // 每月AI token 用量，0 表示不限制
+}
+
+type GetPlanResp struct {
+	Plan            string     `json:"plan"`              // 当前套餐
+	PlanExpiresAt   *time.Time `json:"plan_expires_at"`   // 付费套餐到期时间，为空表示永久
+	Limits          PlanLimits `json:"limits"`            // 套餐配额
+	MindMapCount    int64      `json:"mind_map_count"`    // 已创建的导图数量
+	StorageBytes    int64      `json:"storage_bytes"`     // 已占用的存储空间（字节）
+	AIRequestsToday int64      `json:"ai_requests_today"` // 今日AI调用次数
+	Success         bool       `json:"success"`
+
+	// 管理员单独设置的配额，limits 已叠加该配额；未设置时不返回
+	Override *UserQuotaOverride `json:"override,omitempty"`
+}
+
+// UserQuotaOverride 管理员为用户单独设置的配额，字段为空表示沿用套餐配额，为 0 表示不限制
+type UserQuotaOverride struct {
+	MaxMindMaps     *int64    `json:"max_mind_maps"`
+	MaxNodesPerMap  *int64    `json:"max_nodes_per_map"`
+	MaxStorageBytes *int64    `json:"max_storage_bytes"`
+	UpdatedBy       string    `json:"updated_by"` // 设置配额的管理员
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// 管理接口：修改用户套餐
+type UpdateUserPlanReq struct {
+	UserID string `json:"user_id" binding:"required"`
+	Plan   string `json:"plan" binding:"required"` // free/pro
+}
+
+type UpdateUserPlanResp struct {
+	Success bool `json:"success"`
+}
+
+// 管理接口：查看用户的套餐、用量与单独设置的配额，返回 GetPlanResp
+type AdminGetUserQuotaReq struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+// 管理接口：为用户单独设置配额，字段为空表示沿用套餐配额，均为空时清除单独设置
+type UpdateUserQuotaReq struct {
+	UserID          string `json:"user_id" binding:"required"`
+	MaxMindMaps     *int64 `json:"max_mind_maps"`     // 0 表示不限制
+	MaxNodesPerMap  *int64 `json:"max_nodes_per_map"` // 0 表示不限制
+	MaxStorageBytes *int64 `json:"max_storage_bytes"` // 上传文件占用的最大存储空间（字节），0 表示不限制
+}
+
+type UpdateUserQuotaResp struct {
+	Success bool `json:"success"`
+}
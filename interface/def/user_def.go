@@ -1,5 +1,7 @@
 package def
 
+import "time"
+
 // 这个是DTO层，会暴露给前端 主要是接口定义
 
 type User struct {
@@ -20,29 +22,85 @@ type Dog struct {
 
 // ---------登录相关----------
 type LoginReq struct {
-	Account     string `json:"account"`      // 账号（手机号或邮箱）
-	AccountType string `json:"account_type"` // 账号类型：phone（手机号）或 email（邮箱）
-	Password    string `json:"password"`     // 密码
+	Account     string `json:"account" binding:"required"`                                 // 账号（手机号、邮箱或用户名）
+	AccountType string `json:"account_type" binding:"required,oneof=phone email username"` // 账号类型：phone（手机号）、email（邮箱）或 username（用户名）
+	CountryCode string `json:"country_code"`                                               // 手机号的国际区号，如 1、44，不填时按中国大陆号码处理；account 已带 + 时忽略
+	Password    string `json:"password" binding:"required"`                                // 密码
 }
 
 type LoginResp struct {
-	Token    string `json:"token,omitempty"`     // JWT token
-	UserID   string `json:"user_id,omitempty"`   // 用户ID
-	UserName string `json:"user_name,omitempty"` // 用户名
-	Avatar   string `json:"avatar,omitempty"`    // 头像
-	Phone    string `json:"phone,omitempty"`     // 手机号
-	Email    string `json:"email,omitempty"`     // 邮箱
-	Success  bool   `json:"success"`             // 登录是否成功
+	Token            string `json:"token,omitempty"`              // JWT 访问令牌
+	RefreshToken     string `json:"refresh_token,omitempty"`      // 刷新令牌，访问令牌过期后用于换取新令牌
+	ExpiresIn        int64  `json:"expires_in,omitempty"`         // 访问令牌有效期（秒）
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty"` // 刷新令牌有效期（秒）
+	UserID           string `json:"user_id,omitempty"`            // 用户ID
+	UserName         string `json:"user_name,omitempty"`          // 用户名
+	Avatar           string `json:"avatar,omitempty"`             // 头像
+	Phone            string `json:"phone,omitempty"`              // 手机号
+	Email            string `json:"email,omitempty"`              // 邮箱
+	Success          bool   `json:"success"`                      // 登录是否成功
+}
+
+// ---------图形验证码-----------
+// 需要时在登录、注册、发送验证码请求的 X-Captcha-Id、X-Captcha-Answer 请求头中携带
+type GetCaptchaResp struct {
+	CaptchaID string `json:"captcha_id,omitempty"`
+	Image     string `json:"image,omitempty"`      // PNG 图片的 data URL，可直接用作 img 的 src
+	ExpiresIn int64  `json:"expires_in,omitempty"` // 有效期（秒）
+	Success   bool   `json:"success"`
+}
+
+// 免密登录：发送登录链接到已注册的邮箱
+type SendMagicLinkReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type SendMagicLinkResp struct {
+	Success    bool  `json:"success"`
+	RetryAfter int64 `json:"retry_after,omitempty"` // 发送过于频繁时，距离下次可以发送的秒数
+}
+
+// 点击邮件中的登录链接，响应与账号密码登录一致（LoginResp）
+type MagicLinkLoginReq struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// 刷新令牌，cookie 鉴权模式下可不传，从 cookie 中读取
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResp struct {
+	Token            string `json:"token,omitempty"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresIn        int64  `json:"expires_in,omitempty"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty"`
+	Success          bool   `json:"success"`
+}
+
+// 退出登录：当前访问令牌加入黑名单，同时吊销刷新令牌
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token"`
+
+	TokenID        string    `json:"-"` // 当前访问令牌的 jti，由鉴权中间件提供
+	TokenExpiresAt time.Time `json:"-"` // 当前访问令牌的过期时间
+}
+
+type LogoutResp struct {
+	Success bool `json:"success"`
 }
 
 // ---------注册相关------------
 // 注册：用户名 + 手机号/邮箱 + 验证码 + 设置密码
+// 也可直接使用用户名注册（account_type 为 username），此时无需验证码，account 即为用户名
 type RegisterReq struct {
 	UserName    string `json:"user_name"`
-	Account     string `json:"account"`
-	AccountType string `json:"account_type"` // 手机号或邮箱
-	Code        string `json:"code"`
-	Password    string `json:"password"`
+	Account     string `json:"account" binding:"required"`
+	AccountType string `json:"account_type" binding:"required,oneof=phone email username"` // 手机号、邮箱或用户名
+	CountryCode string `json:"country_code"`                                               // 手机号的国际区号，不填时按中国大陆号码处理
+	Code        string `json:"code"`                                                       // 用户名注册时不需要
+	Password    string `json:"password" binding:"required"`
+	InviteCode  string `json:"invite_code"` // 邀请码，选填
 }
 
 type RegisterResp struct {
@@ -51,11 +109,12 @@ type RegisterResp struct {
 
 // ---------重置密码-----------
 type ResetPasswordReq struct {
-	Account         string `json:"account"`
-	AccountType     string `json:"account_type"` // 手机号或邮箱
-	Code            string `json:"code"`
-	NewPassword     string `json:"new_password"`
-	ConfirmPassword string `json:"confirm_password"`
+	Account         string `json:"account" binding:"required"`
+	AccountType     string `json:"account_type" binding:"required,oneof=phone email"` // 手机号或邮箱
+	CountryCode     string `json:"country_code"`                                      // 手机号的国际区号，不填时按中国大陆号码处理
+	Code            string `json:"code" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
 }
 
 type ResetPasswordResp struct {
@@ -83,13 +142,15 @@ type UpdateAvatarResp struct {
 
 // ---------发送验证码-----------
 type SendVerificationCodeReq struct {
-	Account     string `json:"account"`      // 账号（手机号或邮箱）  目前只支持邮箱 邮件收取验证码
-	AccountType string `json:"account_type"` // 账号类型：phone（手机号）或 email（邮箱）
-	Purpose     string `json:"purpose"`      // 使用场景：register（注册）、reset_password（重置密码）、change_account（换绑联系方式，手机号/邮箱）  // 控制验证
+	Account     string `json:"account" binding:"required"`                        // 账号（手机号或邮箱）  目前只支持邮箱 邮件收取验证码
+	AccountType string `json:"account_type" binding:"required,oneof=phone email"` // 账号类型：phone（手机号）或 email（邮箱）
+	CountryCode string `json:"country_code"`                                      // 手机号的国际区号，不填时按中国大陆号码处理
+	Purpose     string `json:"purpose"`                                           // 使用场景：register（注册）、reset_password（重置密码）、change_account（换绑联系方式，手机号/邮箱）、confirm_change_account（换绑前确认，发送到原手机号/邮箱）、delete_account（注销账号）、merge_account（合并账号）  // 控制验证
 }
 
 type SendVerificationCodeResp struct {
-	Success bool `json:"success"` // 发送是否成功
+	Success    bool  `json:"success"`               // 发送是否成功
+	RetryAfter int64 `json:"retry_after,omitempty"` // 发送过于频繁时，距离下次可以发送的秒数
 }
 
 // ---------个人主页-----------
@@ -99,14 +160,44 @@ type GetHomeResp struct {
 	Phone       string `json:"phone,omitempty"`  // 手机号
 	Email       string `json:"email,omitempty"`  // 邮箱
 	HasPassword bool   `json:"has_password"`     // 是否有密码
+
+	UserProfile
+
+	ReminderEmail bool `json:"reminder_email"` // 是否接收绑定联系方式提醒邮件
+}
+
+// UserProfile 个人资料，未设置的字段为空
+type UserProfile struct {
+	Nickname string `json:"nickname"` // 昵称
+	Bio      string `json:"bio"`      // 个人简介
+	Gender   string `json:"gender"`   // 性别：male、female、other，空表示未设置
+	Birthday string `json:"birthday"` // 生日，格式 2006-01-02
+	Location string `json:"location"` // 所在地
+}
+
+// ---------修改个人资料-----------
+// 未传的字段不修改，传空字符串表示清空
+type UpdateProfileReq struct {
+	Nickname *string `json:"nickname"`
+	Bio      *string `json:"bio"`
+	Gender   *string `json:"gender"`   // male、female、other
+	Birthday *string `json:"birthday"` // 格式 2006-01-02
+	Location *string `json:"location"`
+}
+
+type UpdateProfileResp struct {
+	Success bool        `json:"success"`
+	Profile UserProfile `json:"profile"` // 修改后的个人资料
 }
 
 // ---------更新联系方式（绑定/换绑）-----------
 type UpdateAccountReq struct {
-	Account     string `json:"account"`      // 新手机号/邮箱
-	AccountType string `json:"account_type"` // 账号类型：phone（手机号）或 email（邮箱）
-	Code        string `json:"code"`         // 验证码
-	Password    string `json:"password"`     // 密码（如果用户没有密码则必填，如果有密码则可选）
+	Account     string `json:"account" binding:"required"`                        // 新手机号/邮箱
+	AccountType string `json:"account_type" binding:"required,oneof=phone email"` // 账号类型：phone（手机号）或 email（邮箱）
+	CountryCode string `json:"country_code"`                                      // 手机号的国际区号，不填时按中国大陆号码处理
+	Code        string `json:"code" binding:"required"`                           // 验证码
+	Password    string `json:"password"`                                          // 密码（如果用户没有密码则必填，如果有密码则可选）
+	OldCode     string `json:"old_code"`                                          // 原手机号/邮箱收到的确认码（purpose 为 confirm_change_account），开启换绑确认时必填
 }
 
 type UpdateAccountResp struct {
@@ -116,12 +207,210 @@ type UpdateAccountResp struct {
 
 // ---------解绑联系方式-----------
 type UnbindAccountReq struct {
-	Account     string `json:"account"`      // 需要解绑的手机号/邮箱
-	AccountType string `json:"account_type"` // 账号类型：phone（手机号）或 email（邮箱）
+	Account     string `json:"account" binding:"required"`                        // 需要解绑的手机号/邮箱
+	AccountType string `json:"account_type" binding:"required,oneof=phone email"` // 账号类型：phone（手机号）或 email（邮箱）
+	CountryCode string `json:"country_code"`                                      // 手机号的国际区号，不填时按中国大陆号码处理
 }
 
 type UnbindAccountResp struct {
 	Success bool `json:"success"` // 解绑是否成功
 }
 
-//---------第三方--------- 暂时先不做
+// ---------注销账号-----------
+// 密码与验证码二选一；使用验证码时需先以 delete_account 场景向自己的手机号/邮箱发送验证码
+type DeleteAccountReq struct {
+	Password     string `json:"password"`
+	AccountType  string `json:"account_type"` // 接收验证码的联系方式类型：phone（手机号）或 email（邮箱）
+	Code         string `json:"code"`
+	RefreshToken string `json:"refresh_token"`
+
+	TokenID        string    `json:"-"` // 当前访问令牌的 jti，由鉴权中间件提供
+	TokenExpiresAt time.Time `json:"-"` // 当前访问令牌的过期时间
+}
+
+type DeleteAccountResp struct {
+	Success             bool      `json:"success"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"` // 计划彻底删除的时间，此前重新登录可撤销注销
+}
+
+// ---------通知偏好-----------
+type UpdateNotificationPreferenceReq struct {
+	ReminderEmail *bool `json:"reminder_email" binding:"required"` // 是否接收绑定联系方式提醒邮件
+}
+
+type UpdateNotificationPreferenceResp struct {
+	Success bool `json:"success"`
+}
+
+// 邮件中的退订链接，无需登录
+type UnsubscribeReminderReq struct {
+	UserID string `form:"uid" binding:"required"`
+	Token  string `form:"token" binding:"required"`
+}
+
+type UnsubscribeReminderResp struct {
+	Success bool `json:"success"`
+}
+
+//---------第三方---------
+
+// 第三方授权地址，provider 取自路径参数
+type OAuthAuthorizeReq struct {
+	Provider string `json:"-"`
+}
+
+type OAuthAuthorizeResp struct {
+	AuthURL string `json:"auth_url,omitempty"` // 第三方授权页面地址
+	Success bool   `json:"success"`
+}
+
+// 第三方登录，响应与账号密码登录一致（LoginResp）
+type OAuthLoginReq struct {
+	Provider string `json:"-"`
+	Code     string `json:"code" binding:"required"`  // 第三方回调携带的授权码
+	State    string `json:"state" binding:"required"` // 第三方回调原样带回的 state
+}
+
+// 绑定第三方账号
+type BindOAuthReq struct {
+	Provider string `json:"-"`
+	Code     string `json:"code" binding:"required"`
+	State    string `json:"state" binding:"required"`
+}
+
+type BindOAuthResp struct {
+	Success bool `json:"success"`
+}
+
+// 解绑第三方账号
+type UnbindOAuthReq struct {
+	Provider string `json:"-"`
+}
+
+type UnbindOAuthResp struct {
+	Success bool `json:"success"`
+}
+
+type OAuthBinding struct {
+	Provider string    `json:"provider"`            // 平台：wechat/github/google
+	NickName string    `json:"nick_name,omitempty"` // 第三方昵称
+	Avatar   string    `json:"avatar,omitempty"`    // 第三方头像
+	BoundAt  time.Time `json:"bound_at"`            // 绑定时间
+}
+
+type ListOAuthBindingsResp struct {
+	Bindings []*OAuthBinding `json:"bindings"`
+	Success  bool            `json:"success"`
+}
+
+// ---------登录会话-----------
+type ListSessionsReq struct {
+	CurrentSessionID string `json:"-"` // 当前访问令牌所属的会话，由鉴权中间件提供
+}
+
+type Session struct {
+	SessionID    string    `json:"session_id"`
+	Device       string    `json:"device,omitempty"` // 根据 User-Agent 识别的设备，如 Chrome / Windows
+	IP           string    `json:"ip,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	LoginAt      time.Time `json:"login_at"`       // 登录时间
+	LastActiveAt time.Time `json:"last_active_at"` // 最近一次签发或续签令牌的时间
+	Active       bool      `json:"active"`         // 是否仍然在线（未退出、未下线、未过期）
+	Current      bool      `json:"current"`        // 是否为当前请求所在的会话
+}
+
+type ListSessionsResp struct {
+	Sessions []*Session `json:"sessions"`
+	Success  bool       `json:"success"`
+}
+
+type RevokeSessionReq struct {
+	SessionID string `json:"-"` // 路径参数
+}
+
+type RevokeSessionResp struct {
+	Success bool `json:"success"`
+}
+
+// ---------合并账号-----------
+// 当前账号与待合并账号都需验证，密码与验证码二选一；使用验证码时需先以 merge_account 场景发送
+type MergeAccountReq struct {
+	// 当前账号
+	Password    string `json:"password"`
+	AccountType string `json:"account_type"` // 接收验证码的联系方式类型：phone（手机号）或 email（邮箱）
+	Code        string `json:"code"`
+
+	// 待合并的账号
+	MergeAccount     string `json:"merge_account" binding:"required"`                                 // 手机号、邮箱或用户名
+	MergeAccountType string `json:"merge_account_type" binding:"required,oneof=phone email username"` // 用户名只能使用密码验证
+	MergeCountryCode string `json:"merge_country_code"`                                               // 手机号的国际区号，不填时按中国大陆号码处理
+	MergePassword    string `json:"merge_password"`
+	MergeCode        string `json:"merge_code"`
+}
+
+type MergeAccountResp struct {
+	Success       bool   `json:"success"`
+	MergedUserID  string `json:"merged_user_id"` // 被合并的账号，合并后无法再登录
+	MindMaps      int64  `json:"mindmaps"`       // 迁移的导图数
+	Conversations int64  `json:"conversations"`  // 迁移的AI对话数
+}
+
+// ---------邀请码-----------
+type InviteCode struct {
+	Code      string     `json:"code"`
+	MaxUses   int        `json:"max_uses"`   // 可使用次数
+	UsedCount int        `json:"used_count"` // 已使用次数
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Usable    bool       `json:"usable"` // 是否仍可用于注册（未过期且有剩余次数）
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateInviteCodeResp struct {
+	InviteCode *InviteCode `json:"invite_code"`
+	Success    bool        `json:"success"`
+}
+
+type ListInviteCodesResp struct {
+	Codes   []*InviteCode `json:"codes"`
+	Success bool          `json:"success"`
+}
+
+type Referral struct {
+	InviteeID     string    `json:"invitee_id"`
+	InviteeName   string    `json:"invitee_name,omitempty"` // 昵称，未设置昵称时为用户名
+	InviteeAvatar string    `json:"invitee_avatar,omitempty"`
+	Code          string    `json:"code"`
+	RegisteredAt  time.Time `json:"registered_at"`
+}
+
+type ListReferralsResp struct {
+	Referrals []*Referral `json:"referrals"`
+	Success   bool        `json:"success"`
+}
+
+// ---------账号安全记录-----------
+type ListAuditLogsReq struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=20"`
+}
+
+type AuditLog struct {
+	LogID     string    `json:"log_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	ActorID   string    `json:"actor_id,omitempty"` // 操作人，管理员操作时为管理员ID
+	Action    string    `json:"action"`             // login、reset_password、update_account、unbind_account、update_avatar、admin_*
+	Result    string    `json:"result"`             // success/failure
+	Detail    string    `json:"detail,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	Device    string    `json:"device,omitempty"` // 根据 User-Agent 识别的设备，如 Chrome / Windows
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListAuditLogsResp struct {
+	List     []*AuditLog `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Success  bool        `json:"success"`
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// AdminListUsers 管理员分页查看用户
+func (h *Handler) AdminListUsers(ctx context.Context, req *def.AdminListUsersReq) (rsp *def.AdminListUsersResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_list_users", req, nil, err)
+	}()
+
+	params := caster.CastAdminListUsersReq2Params(req)
+	users, total, err := h.UserService.ListUsers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	list := caster.CastUserDOs2AdminDTOs(users)
+	for _, user := range list {
+		user.Avatar = h.COSService.SignDeliveryURL(ctx, user.Avatar)
+	}
+
+	return &def.AdminListUsersResp{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Success:  true,
+	}, nil
+}
+
+// AdminUpdateUserStatus 管理员禁用/启用用户
+func (h *Handler) AdminUpdateUserStatus(ctx context.Context, req *def.AdminUpdateUserStatusReq) (rsp *def.AdminUpdateUserStatusResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_update_user_status", req, rsp, err)
+	}()
+
+	if err = h.UserService.SetUserStatus(ctx, req.UserID, *req.Status); err != nil {
+		return nil, err
+	}
+
+	return &def.AdminUpdateUserStatusResp{Success: true}, nil
+}
+
+// AdminSuspendUser 管理员禁用用户，可填写原因与到期时间
+func (h *Handler) AdminSuspendUser(ctx context.Context, req *def.AdminSuspendUserReq) (rsp *def.AdminSuspendUserResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_suspend_user", req, rsp, err)
+	}()
+
+	if err = h.UserService.SuspendUser(ctx, req.UserID, strings.TrimSpace(req.Reason), req.Until); err != nil {
+		return nil, err
+	}
+
+	return &def.AdminSuspendUserResp{Success: true}, nil
+}
+
+// AdminReactivateUser 管理员恢复被禁用的用户
+func (h *Handler) AdminReactivateUser(ctx context.Context, req *def.AdminReactivateUserReq) (rsp *def.AdminReactivateUserResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_reactivate_user", req, rsp, err)
+	}()
+
+	if err = h.UserService.ReactivateUser(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return &def.AdminReactivateUserResp{Success: true}, nil
+}
+
+// AdminUpdateUserRole 管理员修改用户角色
+func (h *Handler) AdminUpdateUserRole(ctx context.Context, req *def.AdminUpdateUserRoleReq) (rsp *def.AdminUpdateUserRoleResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_update_user_role", req, rsp, err)
+	}()
+
+	if err = h.UserService.SetUserRole(ctx, req.UserID, req.Role); err != nil {
+		return nil, err
+	}
+
+	return &def.AdminUpdateUserRoleResp{Success: true}, nil
+}
+
+// AdminResetPassword 管理员为用户重置密码
+func (h *Handler) AdminResetPassword(ctx context.Context, req *def.AdminResetPasswordReq) (rsp *def.AdminResetPasswordResp, err error) {
+	defer func() {
+		// 请求中包含新密码，不记录请求体
+		zlog.CtxAllInOne(ctx, "handler.admin_reset_password", nil, rsp, err)
+	}()
+
+	if err = h.UserService.AdminResetPassword(ctx, req.UserID, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	return &def.AdminResetPasswordResp{Success: true}, nil
+}
+
+// AdminListUserSessions 管理员查看用户的登录记录
+func (h *Handler) AdminListUserSessions(ctx context.Context, req *def.AdminListUserSessionsReq) (rsp *def.AdminListUserSessionsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_list_user_sessions", req, nil, err)
+	}()
+
+	sessions, err := h.UserService.ListUserSessions(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.AdminListUserSessionsResp{
+		Sessions: caster.CastUserSessionDOs2DTOs(sessions, ""),
+		Success:  true,
+	}, nil
+}
+
+// AdminListAuditLogs 管理员按条件查看账号安全记录
+func (h *Handler) AdminListAuditLogs(ctx context.Context, req *def.AdminListAuditLogsReq) (rsp *def.AdminListAuditLogsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_list_audit_logs", req, nil, err)
+	}()
+
+	logs, total, err := h.AuditService.ListAuditLogs(ctx, caster.CastAdminListAuditLogsReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.AdminListAuditLogsResp{
+		List:     caster.CastAuditLogDOs2DTOs(logs),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Success:  true,
+	}, nil
+}
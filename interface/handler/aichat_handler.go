@@ -2,11 +2,21 @@ package handler
 
 import (
 	"context"
+	"forge/biz/types"
+	"forge/constant"
 	"forge/interface/caster"
 	"forge/interface/def"
+	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
+	"io"
 )
 
-func (h *Handler) SendMessage(ctx context.Context, req *def.ProcessUserMessageRequest) (*def.ProcessUserMessageResponse, error) {
+func (h *Handler) SendMessage(ctx context.Context, req *def.ProcessUserMessageRequest) (rsp *def.ProcessUserMessageResponse, err error) {
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.send_message", constant.LoopSpanType_Handle)
+	defer func() {
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+	}()
 
 	//转 biz层 参数
 	params := caster.CastProcessUserMessageReq2Params(req)
@@ -16,13 +26,25 @@ func (h *Handler) SendMessage(ctx context.Context, req *def.ProcessUserMessageRe
 		return nil, err
 	}
 
-	resp := &def.ProcessUserMessageResponse{
-		Content:    aiMsg.Content,
-		NewMapJson: aiMsg.NewMapJson,
-		Success:    true,
+	return caster.CastAgentResponse2Resp(&aiMsg), nil
+}
+
+// SendMessageStream 流式ai对话，模型输出的文本片段依次交给 onDelta，结束后返回完整回复
+func (h *Handler) SendMessageStream(ctx context.Context, req *def.ProcessUserMessageRequest, onDelta func(delta string) error) (rsp *def.ProcessUserMessageResponse, err error) {
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.send_message_stream", constant.LoopSpanType_Handle)
+	defer func() {
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+	}()
+
+	params := caster.CastProcessUserMessageReq2Params(req)
+
+	aiMsg, err := h.AiChatService.ProcessUserMessageStream(ctx, params, onDelta)
+	if err != nil {
+		return nil, err
 	}
 
-	return resp, nil
+	return caster.CastAgentResponse2Resp(&aiMsg), nil
 }
 
 func (h *Handler) SaveNewConversation(ctx context.Context, req *def.SaveNewConversationRequest) (*def.SaveNewConversationResponse, error) {
@@ -71,24 +93,78 @@ func (h *Handler) DelConversation(ctx context.Context, req *def.DelConversationR
 	return resp, nil
 }
 
+func (h *Handler) RestoreConversation(ctx context.Context, req *def.RestoreConversationRequest) (*def.RestoreConversationResponse, error) {
+	params := caster.CastRestoreConversationReq2Params(req)
+
+	err := h.AiChatService.RestoreConversation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.RestoreConversationResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
 func (h *Handler) GetConversation(ctx context.Context, req *def.GetConversationRequest) (*def.GetConversationResponse, error) {
 	params := caster.CastGetConversationReq2Params(req)
 
-	conversation, err := h.AiChatService.GetConversation(ctx, params)
+	page, err := h.AiChatService.GetConversationMessages(ctx, params)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// 评价状态查询失败不影响查看聊天记录
+	feedbacks, err := h.FeedbackService.ListConversationFeedback(ctx, req.ConversationID)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "list conversation feedback failed: %v", err)
+	}
+
 	resp := &def.GetConversationResponse{
 		Success:  true,
-		Title:    conversation.Title,
-		Messages: conversation.Messages,
+		Title:    page.Title,
+		PresetID: page.PresetID,
+		Messages: page.Messages,
+		HasMore:  page.HasMore,
+		Feedback: caster.CastMessageFeedbackDOs2Resp(feedbacks, page.Messages),
 	}
 
 	return resp, nil
 }
 
+func (h *Handler) ForkConversation(ctx context.Context, req *def.ForkConversationRequest) (*def.ForkConversationResponse, error) {
+	params := caster.CastForkConversationReq2Params(req)
+
+	conversationID, err := h.AiChatService.ForkConversation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.ForkConversationResponse{
+		ConversationID: conversationID,
+		Success:        true,
+	}
+	return resp, nil
+}
+
+func (h *Handler) SummarizeMindMap(ctx context.Context, req *def.SummarizeMindMapRequest) (rsp *def.SummarizeMindMapResponse, err error) {
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.summarize_mind_map", constant.LoopSpanType_Handle)
+	defer func() {
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+	}()
+
+	params := caster.CastSummarizeMindMapReq2Params(req)
+
+	result, err := h.AiChatService.SummarizeMindMap(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return caster.CastMindMapSummary2Resp(result), nil
+}
+
 func (h *Handler) UpdateConversationTitle(ctx context.Context, req *def.UpdateConversationTitleRequest) (*def.UpdateConversationTitleResponse, error) {
 	params := caster.CastUpdateConversationTitleReq2Params(req)
 
@@ -103,7 +179,88 @@ func (h *Handler) UpdateConversationTitle(ctx context.Context, req *def.UpdateCo
 	return resp, nil
 }
 
-func (h *Handler) GenerateMindMap(ctx context.Context, req *def.GenerateMindMapRequest) (*def.GenerateMindMapResponse, error) {
+func (h *Handler) ArchiveConversation(ctx context.Context, req *def.ArchiveConversationRequest) (*def.ArchiveConversationResponse, error) {
+	params := caster.CastArchiveConversationReq2Params(req)
+
+	err := h.AiChatService.ArchiveConversation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.ArchiveConversationResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
+func (h *Handler) PinConversation(ctx context.Context, req *def.PinConversationRequest) (*def.PinConversationResponse, error) {
+	params := caster.CastPinConversationReq2Params(req)
+
+	err := h.AiChatService.PinConversation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.PinConversationResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
+func (h *Handler) ReorderConversations(ctx context.Context, req *def.ReorderConversationsRequest) (*def.ReorderConversationsResponse, error) {
+	params := caster.CastReorderConversationsReq2Params(req)
+
+	err := h.AiChatService.ReorderConversations(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.ReorderConversationsResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
+func (h *Handler) SearchConversations(ctx context.Context, req *def.SearchConversationsRequest) (*def.SearchConversationsResponse, error) {
+	params := caster.CastSearchConversationsReq2Params(req)
+
+	result, err := h.AiChatService.SearchConversations(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastConversationSearchResult2Resp(result), nil
+}
+
+func (h *Handler) SetConversationPreset(ctx context.Context, req *def.SetConversationPresetRequest) (*def.SetConversationPresetResponse, error) {
+	params := caster.CastSetConversationPresetReq2Params(req)
+
+	err := h.AiChatService.SetConversationPreset(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.SetConversationPresetResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
+func (h *Handler) GenerateMindMap(ctx context.Context, req *def.GenerateMindMapRequest) (rsp *def.GenerateMindMapResponse, err error) {
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.generate_mind_map", constant.LoopSpanType_Handle)
+	defer func() {
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+	}()
+
+	// 指定了会话时先确认会话属于当前用户
+	if req.ConversationID != "" {
+		_, err := h.AiChatService.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	params := caster.CastGenerateMindMapReq2Params(req)
 
 	res, err := h.AiChatService.GenerateMindMap(ctx, params)
@@ -111,9 +268,80 @@ func (h *Handler) GenerateMindMap(ctx context.Context, req *def.GenerateMindMapR
 		return nil, err
 	}
 
+	// 导图生成成功后再将上传的文件归档到COS，无法解析的文件不归档；归档失败不影响返回结果
+	var fileID string
+	if req.File != nil {
+		id, err := h.uploadAttachment(ctx, req)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "failed to archive uploaded file: %v", err)
+		} else {
+			fileID = id
+		}
+	}
+
 	resp := &def.GenerateMindMapResponse{
 		Success: true,
-		MapJson: res,
+		MapJson: res.MapJson,
+		Cached:  res.Cached,
+		FileID:  fileID,
+	}
+	return resp, nil
+}
+
+// uploadAttachment 读取请求中的文件并通过COS服务归档，返回文件ID
+func (h *Handler) uploadAttachment(ctx context.Context, req *def.GenerateMindMapRequest) (string, error) {
+	f, err := req.File.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fileData, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := h.COSService.UploadAttachment(ctx, &types.UploadAttachmentParams{
+		ConversationID: req.ConversationID,
+		Filename:       req.File.Filename,
+		FileData:       fileData,
+	})
+	if err != nil {
+		return "", err
+	}
+	return file.FileID, nil
+}
+
+func (h *Handler) GetAttachments(ctx context.Context, req *def.GetAttachmentsRequest) (*def.GetAttachmentsResponse, error) {
+	// 会话不存在或不属于当前用户时直接返回会话相关错误
+	_, err := h.AiChatService.GetConversation(ctx, &types.GetConversationParams{ConversationID: req.ConversationID})
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := h.COSService.ListConversationFiles(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.GetAttachmentsResponse{
+		Success: true,
+		List:    caster.CastFileDOs2AttachmentData(files),
 	}
 	return resp, nil
 }
+
+// GetAiUsage 获取当前用户今日、本月的AI token 用量与套餐上限
+func (h *Handler) GetAiUsage(ctx context.Context) (rsp *def.GetAiUsageResponse, err error) {
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.get_ai_usage", constant.LoopSpanType_Handle)
+	defer func() {
+		loop.SetSpanAllInOne(ctx, sp, nil, rsp, err)
+	}()
+
+	usage, err := h.QuotaService.GetAITokenUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return caster.CastAITokenUsage2Resp(usage), nil
+}
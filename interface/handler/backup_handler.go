@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// ListBackups 管理员查看用户的备份记录
+func (h *Handler) ListBackups(ctx context.Context, req *def.ListBackupsReq) (rsp *def.ListBackupsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_backups", req, rsp, err)
+	}()
+
+	backups, err := h.BackupService.ListBackups(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastBackups2Resp(backups), nil
+}
+
+// RestoreBackup 管理员从备份恢复用户已删除的导图
+func (h *Handler) RestoreBackup(ctx context.Context, req *def.RestoreBackupReq) (rsp *def.RestoreBackupResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.restore_backup", req, rsp, err)
+	}()
+
+	result, err := h.BackupService.RestoreBackup(ctx, req.BackupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastRestoreBackupResult2Resp(result), nil
+}
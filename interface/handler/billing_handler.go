@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// CreateCheckout 购买套餐：创建账单并返回支付页面地址
+func (h *Handler) CreateCheckout(ctx context.Context, req *def.CreateCheckoutReq) (rsp *def.CreateCheckoutResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_checkout", req, rsp, err)
+	}()
+
+	invoice, err := h.BillingService.CreateCheckout(ctx, caster.CastCreateCheckoutReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.CreateCheckoutResp{
+		InvoiceID:   invoice.InvoiceID,
+		CheckoutURL: invoice.CheckoutURL,
+		Success:     true,
+	}
+	return rsp, nil
+}
+
+// ListInvoices 当前用户的账单列表
+func (h *Handler) ListInvoices(ctx context.Context) (rsp *def.ListInvoicesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_invoices", nil, rsp, err)
+	}()
+
+	invoices, err := h.BillingService.ListInvoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListInvoicesResp{
+		List:    caster.CastInvoiceDOs2Resp(invoices),
+		Success: true,
+	}
+	return rsp, nil
+}
+
+// PaymentWebhook 支付渠道回调
+func (h *Handler) PaymentWebhook(ctx context.Context, req *def.PaymentWebhookReq) (rsp *def.PaymentWebhookResp, err error) {
+	defer func() {
+		// 请求体可能较大且包含支付信息，不记录原文
+		zlog.CtxAllInOne(ctx, "handler.payment_webhook", len(req.Payload), rsp, err)
+	}()
+
+	if err = h.BillingService.HandlePaymentWebhook(ctx, req.Payload, req.Signature); err != nil {
+		return nil, err
+	}
+
+	return &def.PaymentWebhookResp{Received: true}, nil
+}
@@ -3,17 +3,19 @@ package handler
 import (
 	"context"
 
+	"forge/constant"
 	"forge/interface/caster"
 	"forge/interface/def"
 	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
 )
 
 func (h *Handler) GetOSSCredentials(ctx context.Context, req *def.GetOSSCredentialsReq) (rsp *def.GetOSSCredentialsResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.get_oss_credentials", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.get_oss_credentials", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.get_oss_credentials", req, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 	}()
 
 	// DTO -> Service 层参数转换
@@ -29,3 +31,67 @@ func (h *Handler) GetOSSCredentials(ctx context.Context, req *def.GetOSSCredenti
 	rsp = caster.CastOSSCredentials2DTO(creds)
 	return rsp, nil
 }
+
+// PresignDownload 为自己的文件生成有时效的下载链接
+func (h *Handler) PresignDownload(ctx context.Context, req *def.PresignDownloadReq) (rsp *def.PresignDownloadResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.presign_download", req, rsp, err)
+	}()
+
+	link, err := h.COSService.PresignDownload(ctx, caster.CastPresignDownloadReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastDownloadLink2DTO(link), nil
+}
+
+// ProxyDownload 通过转发下载令牌读取文件
+func (h *Handler) ProxyDownload(ctx context.Context, req *def.ProxyDownloadReq) (rsp *def.ProxyDownloadResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.proxy_download", nil, nil, err)
+	}()
+
+	file, err := h.COSService.OpenDownload(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ProxyDownloadResp{
+		FileName:      file.FileName,
+		ContentType:   file.ContentType,
+		ContentLength: file.ContentLength,
+		Body:          file.Body,
+	}, nil
+}
+
+// ListFiles 分页获取自己上传的文件
+func (h *Handler) ListFiles(ctx context.Context, req *def.ListFilesReq) (rsp *def.ListFilesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_files", req, nil, err)
+	}()
+
+	files, total, err := h.COSService.ListFiles(ctx, caster.CastListFilesReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListFilesResp{
+		List:    caster.CastFiles2DTO(files),
+		Total:   total,
+		Success: true,
+	}, nil
+}
+
+// DeleteFile 删除自己上传的文件
+func (h *Handler) DeleteFile(ctx context.Context, req *def.DeleteFileReq) (rsp *def.DeleteFileResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_file", req, rsp, err)
+	}()
+
+	if err = h.COSService.DeleteFile(ctx, req.FileID); err != nil {
+		return nil, err
+	}
+
+	return &def.DeleteFileResp{Success: true}, nil
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// ExportData 申请导出个人数据，返回导出进度，导出完成后返回下载链接
+func (h *Handler) ExportData(ctx context.Context) (rsp *def.ExportDataResp, err error) {
+	defer func() {
+		// 响应中的下载链接带签名，不记录响应体
+		zlog.CtxAllInOne(ctx, "handler.export_data", nil, nil, err)
+	}()
+
+	export, err := h.ExportService.RequestExport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastDataExport2Resp(export), nil
+}
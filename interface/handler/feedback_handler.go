@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// SubmitMessageFeedback 评价AI回复，rating 为空时撤销评价
+func (h *Handler) SubmitMessageFeedback(ctx context.Context, req *def.SubmitMessageFeedbackRequest) (*def.SubmitMessageFeedbackResponse, error) {
+	params := caster.CastSubmitMessageFeedbackReq2Params(req)
+
+	if err := h.FeedbackService.SubmitFeedback(ctx, params); err != nil {
+		return nil, err
+	}
+
+	resp := &def.SubmitMessageFeedbackResponse{
+		Success: true,
+	}
+	return resp, nil
+}
+
+// AdminExportMessageFeedback 管理员按条件导出评价及评价时的对话快照
+func (h *Handler) AdminExportMessageFeedback(ctx context.Context, req *def.AdminExportMessageFeedbackReq) (rsp *def.AdminExportMessageFeedbackResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_export_message_feedback", req, nil, err)
+	}()
+
+	feedbacks, total, err := h.FeedbackService.ExportFeedback(ctx, caster.CastAdminExportMessageFeedbackReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.AdminExportMessageFeedbackResp{
+		List:     caster.CastMessageFeedbackDOs2AdminDTOs(feedbacks),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Success:  true,
+	}, nil
+}
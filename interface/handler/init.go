@@ -8,6 +8,10 @@ import (
 
 type IHandler interface {
 	Login(ctx context.Context, req *def.LoginReq) (rsp *def.LoginResp, err error)
+	// RefreshToken: 刷新令牌换取新令牌
+	RefreshToken(ctx context.Context, req *def.RefreshTokenReq) (rsp *def.RefreshTokenResp, err error)
+	// Logout: 退出登录
+	Logout(ctx context.Context, req *def.LogoutReq) (rsp *def.LogoutResp, err error)
 	// Register: 注册 暂无第三方
 	Register(ctx context.Context, req *def.RegisterReq) (rsp *def.RegisterResp, err error)
 	// ResetPassword: 重置密码
@@ -22,54 +26,197 @@ type IHandler interface {
 	UpdateAccount(ctx context.Context, req *def.UpdateAccountReq) (rsp *def.UpdateAccountResp, err error)
 	// UnbindAccount: 解绑联系方式（手机号/邮箱）
 	UnbindAccount(ctx context.Context, req *def.UnbindAccountReq) (rsp *def.UnbindAccountResp, err error)
+	// UpdateProfile: 修改个人资料
+	UpdateProfile(ctx context.Context, req *def.UpdateProfileReq) (rsp *def.UpdateProfileResp, err error)
+	// DeleteAccount: 申请注销账号，冷静期后彻底删除
+	DeleteAccount(ctx context.Context, req *def.DeleteAccountReq) (rsp *def.DeleteAccountResp, err error)
 	// UpdateAvatar: 更新头像
 	UpdateAvatar(ctx context.Context, req *def.UpdateAvatarReq) (rsp *def.UpdateAvatarResp, err error)
+	// UpdateNotificationPreference: 修改通知偏好
+	UpdateNotificationPreference(ctx context.Context, req *def.UpdateNotificationPreferenceReq) (rsp *def.UpdateNotificationPreferenceResp, err error)
+	// UnsubscribeReminder: 邮件链接退订提醒
+	UnsubscribeReminder(ctx context.Context, req *def.UnsubscribeReminderReq) (rsp *def.UnsubscribeReminderResp, err error)
+	// GetCaptcha: 获取图形验证码
+	GetCaptcha(ctx context.Context) (rsp *def.GetCaptchaResp, err error)
+	// MagicLink: 邮件免密登录
+	SendMagicLink(ctx context.Context, req *def.SendMagicLinkReq) (rsp *def.SendMagicLinkResp, err error)
+	MagicLinkLogin(ctx context.Context, req *def.MagicLinkLoginReq) (rsp *def.LoginResp, err error)
+	// OAuth: 第三方登录与绑定
+	OAuthAuthorize(ctx context.Context, req *def.OAuthAuthorizeReq) (rsp *def.OAuthAuthorizeResp, err error)
+	OAuthBindAuthorize(ctx context.Context, req *def.OAuthAuthorizeReq) (rsp *def.OAuthAuthorizeResp, err error)
+	OAuthLogin(ctx context.Context, req *def.OAuthLoginReq) (rsp *def.LoginResp, err error)
+	BindOAuth(ctx context.Context, req *def.BindOAuthReq) (rsp *def.BindOAuthResp, err error)
+	UnbindOAuth(ctx context.Context, req *def.UnbindOAuthReq) (rsp *def.UnbindOAuthResp, err error)
+	ListOAuthBindings(ctx context.Context) (rsp *def.ListOAuthBindingsResp, err error)
+	// ListSessions: 登录记录
+	ListSessions(ctx context.Context, req *def.ListSessionsReq) (rsp *def.ListSessionsResp, err error)
+	// RevokeSession: 远程下线登录会话
+	RevokeSession(ctx context.Context, req *def.RevokeSessionReq) (rsp *def.RevokeSessionResp, err error)
+	// MergeAccount: 合并账号
+	MergeAccount(ctx context.Context, req *def.MergeAccountReq) (rsp *def.MergeAccountResp, err error)
+	// CreateInviteCode: 生成邀请码
+	CreateInviteCode(ctx context.Context) (rsp *def.CreateInviteCodeResp, err error)
+	// ListInviteCodes: 我的邀请码
+	ListInviteCodes(ctx context.Context) (rsp *def.ListInviteCodesResp, err error)
+	// ListReferrals: 我邀请注册的用户
+	ListReferrals(ctx context.Context) (rsp *def.ListReferralsResp, err error)
+	// ListAuditLogs: 账号安全记录
+	ListAuditLogs(ctx context.Context, req *def.ListAuditLogsReq) (rsp *def.ListAuditLogsResp, err error)
+	// ExportData: 导出个人数据
+	ExportData(ctx context.Context) (rsp *def.ExportDataResp, err error)
 
 	// MindMap: 思维导图相关接口
 	CreateMindMap(ctx context.Context, req *def.CreateMindMapReq) (rsp *def.CreateMindMapResp, err error)
+	CreateMindMapFromText(ctx context.Context, req *def.CreateMindMapFromTextReq) (rsp *def.CreateMindMapResp, err error)
+	ImportMindMap(ctx context.Context, req *def.ImportMindMapReq) (rsp *def.CreateMindMapResp, err error)
 	GetMindMap(ctx context.Context, mapID string) (rsp *def.GetMindMapResp, err error)
 	ListMindMaps(ctx context.Context, req *def.ListMindMapsReq) (rsp *def.ListMindMapsResp, err error)
 	UpdateMindMap(ctx context.Context, mapID string, req *def.UpdateMindMapReq) (rsp *def.UpdateMindMapResp, err error)
 	DeleteMindMap(ctx context.Context, mapID string) (rsp *def.DeleteMindMapResp, err error)
+	// MindMapShare: 只读分享链接，GetSharedMindMap 不需要登录
+	ShareMindMap(ctx context.Context, mapID string, req *def.ShareMindMapReq) (rsp *def.ShareMindMapResp, err error)
+	ListMindMapShares(ctx context.Context, mapID string) (rsp *def.ListMindMapSharesResp, err error)
+	RevokeMindMapShare(ctx context.Context, mapID, shareID string) (rsp *def.RevokeMindMapShareResp, err error)
+	GetSharedMindMap(ctx context.Context, req *def.GetSharedMindMapReq) (rsp *def.GetSharedMindMapResp, err error)
+	// MindMapMember: 协作者
+	AddMindMapMember(ctx context.Context, mapID string, req *def.AddMindMapMemberReq) (rsp *def.AddMindMapMemberResp, err error)
+	ListMindMapMembers(ctx context.Context, mapID string) (rsp *def.ListMindMapMembersResp, err error)
+	RemoveMindMapMember(ctx context.Context, mapID, userID string) (rsp *def.RemoveMindMapMemberResp, err error)
+	// MindMapActivity: 变更记录，所有者与协作者都可以查看
+	ListMindMapActivities(ctx context.Context, mapID string, req *def.ListMindMapActivitiesReq) (rsp *def.ListMindMapActivitiesResp, err error)
+	// MindMapComment: 节点评论，权限按协作者角色判断
+	CreateMindMapComment(ctx context.Context, mapID string, req *def.CreateMindMapCommentReq) (rsp *def.MindMapCommentResp, err error)
+	ListMindMapComments(ctx context.Context, mapID string, req *def.ListMindMapCommentsReq) (rsp *def.ListMindMapCommentsResp, err error)
+	ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) (rsp *def.MindMapCommentResp, err error)
+	DeleteMindMapComment(ctx context.Context, mapID, commentID string) (rsp *def.DeleteMindMapCommentResp, err error)
+	// MindMapStar: 收藏，可以收藏自己的导图与与我共享的导图
+	StarMindMap(ctx context.Context, mapID string) (rsp *def.StarMindMapResp, err error)
+	UnstarMindMap(ctx context.Context, mapID string) (rsp *def.StarMindMapResp, err error)
+	// MindMapFolder: 文件夹，只整理自己的导图
+	CreateMindMapFolder(ctx context.Context, req *def.CreateMindMapFolderReq) (rsp *def.CreateMindMapFolderResp, err error)
+	ListMindMapFolders(ctx context.Context) (rsp *def.ListMindMapFoldersResp, err error)
+	UpdateMindMapFolder(ctx context.Context, folderID string, req *def.UpdateMindMapFolderReq) (rsp *def.UpdateMindMapFolderResp, err error)
+	DeleteMindMapFolder(ctx context.Context, folderID string) (rsp *def.DeleteMindMapFolderResp, err error)
+	MoveMindMap(ctx context.Context, mapID string, req *def.MoveMindMapReq) (rsp *def.MoveMindMapResp, err error)
+	// MindMapTag: 标签，只标记自己的导图
+	SetMindMapTags(ctx context.Context, mapID string, req *def.SetMindMapTagsReq) (rsp *def.SetMindMapTagsResp, err error)
+	RemoveMindMapTag(ctx context.Context, mapID, tag string) (rsp *def.RemoveMindMapTagResp, err error)
+	ListMindMapTags(ctx context.Context) (rsp *def.ListMindMapTagsResp, err error)
+	// MindMapSearch: 在自己的导图与与我共享的导图中全文搜索
+	SearchMindMaps(ctx context.Context, req *def.SearchMindMapsReq) (rsp *def.SearchMindMapsResp, err error)
+	// MindMapTemplate: 模板，管理员维护，所有用户可以基于模板创建导图
+	CreateMindMapTemplate(ctx context.Context, req *def.MindMapTemplateReq) (rsp *def.MindMapTemplateResp, err error)
+	UpdateMindMapTemplate(ctx context.Context, templateID string, req *def.MindMapTemplateReq) (rsp *def.MindMapTemplateResp, err error)
+	DeleteMindMapTemplate(ctx context.Context, templateID string) (rsp *def.DeleteMindMapTemplateResp, err error)
+	ListMindMapTemplates(ctx context.Context, req *def.ListMindMapTemplatesReq) (rsp *def.ListMindMapTemplatesResp, err error)
+	CreateMindMapFromTemplate(ctx context.Context, templateID string, req *def.CreateMindMapFromTemplateReq) (rsp *def.CreateMindMapResp, err error)
+	// MindMapExport: 导出为 XMind/FreeMind/OPML/Markdown/SVG/PNG
+	ExportMindMap(ctx context.Context, mapID string, req *def.ExportMindMapReq) (rsp *def.ExportMindMapResp, err error)
+
+	// Plan: 套餐与配额
+	GetPlan(ctx context.Context) (rsp *def.GetPlanResp, err error)
+	UpdateUserPlan(ctx context.Context, req *def.UpdateUserPlanReq) (rsp *def.UpdateUserPlanResp, err error)
+	AdminGetUserQuota(ctx context.Context, req *def.AdminGetUserQuotaReq) (rsp *def.GetPlanResp, err error)
+	UpdateUserQuota(ctx context.Context, req *def.UpdateUserQuotaReq) (rsp *def.UpdateUserQuotaResp, err error)
+
+	// Admin: 用户管理（管理接口）
+	AdminListUsers(ctx context.Context, req *def.AdminListUsersReq) (rsp *def.AdminListUsersResp, err error)
+	AdminUpdateUserStatus(ctx context.Context, req *def.AdminUpdateUserStatusReq) (rsp *def.AdminUpdateUserStatusResp, err error)
+	AdminSuspendUser(ctx context.Context, req *def.AdminSuspendUserReq) (rsp *def.AdminSuspendUserResp, err error)
+	AdminReactivateUser(ctx context.Context, req *def.AdminReactivateUserReq) (rsp *def.AdminReactivateUserResp, err error)
+	AdminUpdateUserRole(ctx context.Context, req *def.AdminUpdateUserRoleReq) (rsp *def.AdminUpdateUserRoleResp, err error)
+	AdminResetPassword(ctx context.Context, req *def.AdminResetPasswordReq) (rsp *def.AdminResetPasswordResp, err error)
+	AdminListUserSessions(ctx context.Context, req *def.AdminListUserSessionsReq) (rsp *def.AdminListUserSessionsResp, err error)
+	AdminListAuditLogs(ctx context.Context, req *def.AdminListAuditLogsReq) (rsp *def.AdminListAuditLogsResp, err error)
+
+	// Backup: 用户内容备份（管理接口）
+	ListBackups(ctx context.Context, req *def.ListBackupsReq) (rsp *def.ListBackupsResp, err error)
+	RestoreBackup(ctx context.Context, req *def.RestoreBackupReq) (rsp *def.RestoreBackupResp, err error)
+
+	// Billing: 支付与账单
+	CreateCheckout(ctx context.Context, req *def.CreateCheckoutReq) (rsp *def.CreateCheckoutResp, err error)
+	ListInvoices(ctx context.Context) (rsp *def.ListInvoicesResp, err error)
+	PaymentWebhook(ctx context.Context, req *def.PaymentWebhookReq) (rsp *def.PaymentWebhookResp, err error)
 
 	// COS: OSS凭证相关接口
 	GetOSSCredentials(ctx context.Context, req *def.GetOSSCredentialsReq) (rsp *def.GetOSSCredentialsResp, err error)
+	PresignDownload(ctx context.Context, req *def.PresignDownloadReq) (rsp *def.PresignDownloadResp, err error)
+	ProxyDownload(ctx context.Context, req *def.ProxyDownloadReq) (rsp *def.ProxyDownloadResp, err error)
+	ListFiles(ctx context.Context, req *def.ListFilesReq) (rsp *def.ListFilesResp, err error)
+	DeleteFile(ctx context.Context, req *def.DeleteFileReq) (rsp *def.DeleteFileResp, err error)
 
 	//AiChat: ai对话相关
 	SendMessage(ctx context.Context, req *def.ProcessUserMessageRequest) (*def.ProcessUserMessageResponse, error)
+	SendMessageStream(ctx context.Context, req *def.ProcessUserMessageRequest, onDelta func(delta string) error) (*def.ProcessUserMessageResponse, error)
 	SaveNewConversation(ctx context.Context, req *def.SaveNewConversationRequest) (*def.SaveNewConversationResponse, error)
 	GetConversationList(ctx context.Context, req *def.GetConversationListRequest) (*def.GetConversationListResponse, error)
 	DelConversation(ctx context.Context, req *def.DelConversationRequest) (*def.DelConversationResponse, error)
+	RestoreConversation(ctx context.Context, req *def.RestoreConversationRequest) (*def.RestoreConversationResponse, error)
 	GetConversation(ctx context.Context, req *def.GetConversationRequest) (*def.GetConversationResponse, error)
+	ForkConversation(ctx context.Context, req *def.ForkConversationRequest) (*def.ForkConversationResponse, error)
 	UpdateConversationTitle(ctx context.Context, req *def.UpdateConversationTitleRequest) (*def.UpdateConversationTitleResponse, error)
+	SetConversationPreset(ctx context.Context, req *def.SetConversationPresetRequest) (*def.SetConversationPresetResponse, error)
+	ArchiveConversation(ctx context.Context, req *def.ArchiveConversationRequest) (*def.ArchiveConversationResponse, error)
+	SearchConversations(ctx context.Context, req *def.SearchConversationsRequest) (*def.SearchConversationsResponse, error)
+	PinConversation(ctx context.Context, req *def.PinConversationRequest) (*def.PinConversationResponse, error)
+	ReorderConversations(ctx context.Context, req *def.ReorderConversationsRequest) (*def.ReorderConversationsResponse, error)
 	GenerateMindMap(ctx context.Context, req *def.GenerateMindMapRequest) (*def.GenerateMindMapResponse, error)
+	SummarizeMindMap(ctx context.Context, req *def.SummarizeMindMapRequest) (*def.SummarizeMindMapResponse, error)
+	GetAttachments(ctx context.Context, req *def.GetAttachmentsRequest) (*def.GetAttachmentsResponse, error)
+	GetAiUsage(ctx context.Context) (*def.GetAiUsageResponse, error)
+
+	//PromptPreset: 对话预设，global 为 true 时操作全局预设（管理接口）
+	ListPromptPresets(ctx context.Context) (*def.ListPromptPresetsResponse, error)
+	CreatePromptPreset(ctx context.Context, req *def.PromptPresetRequest, global bool) (*def.PromptPresetResponse, error)
+	UpdatePromptPreset(ctx context.Context, presetID string, req *def.PromptPresetRequest, global bool) (*def.PromptPresetResponse, error)
+	DeletePromptPreset(ctx context.Context, presetID string, global bool) (*def.DeletePromptPresetResponse, error)
+
+	//MessageFeedback: 对AI回复的评价
+	SubmitMessageFeedback(ctx context.Context, req *def.SubmitMessageFeedbackRequest) (*def.SubmitMessageFeedbackResponse, error)
+	AdminExportMessageFeedback(ctx context.Context, req *def.AdminExportMessageFeedbackReq) (*def.AdminExportMessageFeedbackResp, error)
 }
 
 var handler IHandler
 
 type Handler struct {
-	UserService    types.IUserService
-	MindMapService types.IMindMapService
-	COSService     types.ICOSService
-	AiChatService  types.IAiChatService
+	UserService     types.IUserService
+	MindMapService  types.IMindMapService
+	COSService      types.ICOSService
+	AiChatService   types.IAiChatService
+	QuotaService    types.IQuotaService
+	BillingService  types.IBillingService
+	BackupService   types.IBackupService
+	CaptchaService  types.ICaptchaService
+	AuditService    types.IAuditService
+	ExportService   types.IDataExportService
+	PresetService   types.IPromptPresetService
+	FeedbackService types.IMessageFeedbackService
 }
 
 func GetHandler() IHandler {
 	return handler
 }
-func MustInitHandler(userService types.IUserService, mindMapService types.IMindMapService, cosService types.ICOSService, aiChatService types.IAiChatService) {
-	err := InitHandler(userService, mindMapService, cosService, aiChatService)
+func MustInitHandler(userService types.IUserService, mindMapService types.IMindMapService, cosService types.ICOSService, aiChatService types.IAiChatService, quotaService types.IQuotaService, billingService types.IBillingService, backupService types.IBackupService, captchaService types.ICaptchaService, auditService types.IAuditService, dataExportService types.IDataExportService, presetService types.IPromptPresetService, feedbackService types.IMessageFeedbackService) {
+	err := InitHandler(userService, mindMapService, cosService, aiChatService, quotaService, billingService, backupService, captchaService, auditService, dataExportService, presetService, feedbackService)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func InitHandler(userService types.IUserService, mindMapService types.IMindMapService, cosService types.ICOSService, aiChatService types.IAiChatService) error {
+func InitHandler(userService types.IUserService, mindMapService types.IMindMapService, cosService types.ICOSService, aiChatService types.IAiChatService, quotaService types.IQuotaService, billingService types.IBillingService, backupService types.IBackupService, captchaService types.ICaptchaService, auditService types.IAuditService, dataExportService types.IDataExportService, presetService types.IPromptPresetService, feedbackService types.IMessageFeedbackService) error {
 	handler = &Handler{
-		UserService:    userService,
-		MindMapService: mindMapService,
-		COSService:     cosService,
-		AiChatService:  aiChatService,
+		UserService:     userService,
+		MindMapService:  mindMapService,
+		COSService:      cosService,
+		AiChatService:   aiChatService,
+		QuotaService:    quotaService,
+		BillingService:  billingService,
+		BackupService:   backupService,
+		CaptchaService:  captchaService,
+		AuditService:    auditService,
+		ExportService:   dataExportService,
+		PresetService:   presetService,
+		FeedbackService: feedbackService,
 	}
 	return nil
 }
@@ -3,19 +3,19 @@ package handler
 import (
 	"context"
 
-	// "forge/constant"
+	"forge/constant"
 	"forge/interface/caster"
 	"forge/interface/def"
 	"forge/pkg/log/zlog"
-	// "forge/pkg/loop"
+	"forge/pkg/loop"
 )
 
 func (h *Handler) CreateMindMap(ctx context.Context, req *def.CreateMindMapReq) (rsp *def.CreateMindMapResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.create_mindmap", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.create_mindmap", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.create_mindmap", req, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 	}()
 
 	// DTO -> Service 层参数转换
@@ -34,12 +34,49 @@ func (h *Handler) CreateMindMap(ctx context.Context, req *def.CreateMindMapReq)
 	return rsp, nil
 }
 
+func (h *Handler) CreateMindMapFromText(ctx context.Context, req *def.CreateMindMapFromTextReq) (rsp *def.CreateMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_from_text", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastCreateMindMapFromTextReq2Params(req)
+
+	// 调用服务层解析大纲并创建思维导图
+	mindmap, err := h.MindMapService.CreateMindMapFromText(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.CreateMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindmap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ImportMindMap(ctx context.Context, req *def.ImportMindMapReq) (rsp *def.CreateMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.import_mindmap", map[string]interface{}{"title": req.Title, "filename": req.File.Filename, "size": req.File.Size}, rsp, err)
+	}()
+
+	mindmap, err := h.MindMapService.ImportMindMap(ctx, caster.CastImportMindMapReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.CreateMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindmap),
+	}
+	return rsp, nil
+}
+
 func (h *Handler) GetMindMap(ctx context.Context, mapID string) (rsp *def.GetMindMapResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.get_mindmap", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.get_mindmap", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.get_mindmap", mapID, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
 	}()
 
 	// 调用服务层获取思维导图
@@ -56,38 +93,47 @@ func (h *Handler) GetMindMap(ctx context.Context, mapID string) (rsp *def.GetMin
 }
 
 func (h *Handler) ListMindMaps(ctx context.Context, req *def.ListMindMapsReq) (rsp *def.ListMindMapsResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.list_mindmaps", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.list_mindmaps", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.list_mindmaps", req, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 	}()
 
 	// DTO -> Service 层参数转换
 	params := caster.CastListMindMapsReq2Params(req)
 
 	// 调用服务层获取思维导图列表
-	mindmaps, total, err := h.MindMapService.ListMindMaps(ctx, params)
+	list, err := h.MindMapService.ListMindMaps(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一筛选条件下的标签统计
+	facets, err := h.MindMapService.CountMindMapTags(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
 	// 组装响应
 	rsp = &def.ListMindMapsResp{
-		List:     caster.CastMindMapDOs2DTOs(mindmaps),
-		Total:    total,
-		Page:     req.Page,
-		PageSize: req.PageSize,
+		List:       caster.CastMindMapDOs2DTOs(list.MindMaps),
+		Total:      list.Total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Facets:     caster.CastMindMapTags2DTOs(facets),
+		NextCursor: list.NextCursor,
+		HasMore:    list.HasMore,
 	}
 	return rsp, nil
 }
 
 func (h *Handler) UpdateMindMap(ctx context.Context, mapID string, req *def.UpdateMindMapReq) (rsp *def.UpdateMindMapResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.update_mindmap", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.update_mindmap", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.update_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
 	}()
 
 	// DTO -> Service 层参数转换
@@ -107,11 +153,11 @@ func (h *Handler) UpdateMindMap(ctx context.Context, mapID string, req *def.Upda
 }
 
 func (h *Handler) DeleteMindMap(ctx context.Context, mapID string) (rsp *def.DeleteMindMapResp, err error) {
-	// 链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.delete_mindmap", constant.LoopSpanType_Handle)
+	// 链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.delete_mindmap", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.delete_mindmap", mapID, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
 	}()
 
 	// 调用服务层删除思维导图
@@ -126,3 +172,508 @@ func (h *Handler) DeleteMindMap(ctx context.Context, mapID string) (rsp *def.Del
 	}
 	return rsp, nil
 }
+
+func (h *Handler) ShareMindMap(ctx context.Context, mapID string, req *def.ShareMindMapReq) (rsp *def.ShareMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.share_mindmap", map[string]interface{}{"mapID": mapID, "expireHours": req.ExpireHours}, rsp, err)
+	}()
+
+	link, err := h.MindMapService.ShareMindMap(ctx, mapID, caster.CastShareMindMapReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ShareMindMapResp{
+		MindMapShareDTO: caster.CastMindMapShareLink2DTO(link),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapShares(ctx context.Context, mapID string) (rsp *def.ListMindMapSharesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_shares", mapID, rsp, err)
+	}()
+
+	links, err := h.MindMapService.ListMindMapShares(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListMindMapSharesResp{
+		List: caster.CastMindMapShareLinks2DTOs(links),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RevokeMindMapShare(ctx context.Context, mapID, shareID string) (rsp *def.RevokeMindMapShareResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.revoke_mindmap_share", map[string]interface{}{"mapID": mapID, "shareID": shareID}, rsp, err)
+	}()
+
+	if err = h.MindMapService.RevokeMindMapShare(ctx, mapID, shareID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.RevokeMindMapShareResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GetSharedMindMap(ctx context.Context, req *def.GetSharedMindMapReq) (rsp *def.GetSharedMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_shared_mindmap", nil, nil, err)
+	}()
+
+	mindmap, share, err := h.MindMapService.GetSharedMindMap(ctx, req.Token, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return caster.CastSharedMindMap2DTO(mindmap, share), nil
+}
+
+func (h *Handler) AddMindMapMember(ctx context.Context, mapID string, req *def.AddMindMapMemberReq) (rsp *def.AddMindMapMemberResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.add_mindmap_member", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	member, err := h.MindMapService.AddMindMapMember(ctx, mapID, caster.CastAddMindMapMemberReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+	users, err := h.getUserBriefs(ctx, []string{member.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.AddMindMapMemberResp{
+		MindMapMemberDTO: caster.CastMindMapMemberDO2DTO(member, users),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapMembers(ctx context.Context, mapID string) (rsp *def.ListMindMapMembersResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_members", mapID, rsp, err)
+	}()
+
+	mindmap, members, err := h.MindMapService.ListMindMapMembers(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(members)+1)
+	userIDs = append(userIDs, mindmap.UserID)
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	users, err := h.getUserBriefs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListMindMapMembersResp{
+		List: caster.CastMindMapMembers2DTOs(mindmap, members, users),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RemoveMindMapMember(ctx context.Context, mapID, userID string) (rsp *def.RemoveMindMapMemberResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.remove_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID}, rsp, err)
+	}()
+
+	if err = h.MindMapService.RemoveMindMapMember(ctx, mapID, userID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.RemoveMindMapMemberResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapActivities(ctx context.Context, mapID string, req *def.ListMindMapActivitiesReq) (rsp *def.ListMindMapActivitiesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_activities", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	params := caster.CastListMindMapActivitiesReq2Params(req)
+	activities, total, err := h.MindMapService.ListMindMapActivities(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+	actorIDs := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		actorIDs = append(actorIDs, activity.ActorID)
+	}
+	users, err := h.getUserBriefs(ctx, actorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*def.MindMapActivityDTO, 0, len(activities))
+	for _, activity := range activities {
+		list = append(list, caster.CastMindMapActivityDO2DTO(activity, users))
+	}
+	rsp = &def.ListMindMapActivitiesResp{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapComment(ctx context.Context, mapID string, req *def.CreateMindMapCommentReq) (rsp *def.MindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_comment", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	comment, err := h.MindMapService.CreateMindMapComment(ctx, mapID, caster.CastCreateMindMapCommentReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+	users, err := h.getUserBriefs(ctx, []string{comment.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.MindMapCommentResp{
+		MindMapCommentDTO: caster.CastMindMapCommentDO2DTO(comment, users),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapComments(ctx context.Context, mapID string, req *def.ListMindMapCommentsReq) (rsp *def.ListMindMapCommentsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_comments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	comments, total, err := h.MindMapService.ListMindMapComments(ctx, mapID, caster.CastListMindMapCommentsReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		userIDs = append(userIDs, comment.UserID)
+	}
+	users, err := h.getUserBriefs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*def.MindMapCommentDTO, 0, len(comments))
+	for _, comment := range comments {
+		list = append(list, caster.CastMindMapCommentDO2DTO(comment, users))
+	}
+	rsp = &def.ListMindMapCommentsResp{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) (rsp *def.MindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.resolve_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "resolved": resolved}, rsp, err)
+	}()
+
+	comment, err := h.MindMapService.ResolveMindMapComment(ctx, mapID, commentID, resolved)
+	if err != nil {
+		return nil, err
+	}
+	users, err := h.getUserBriefs(ctx, []string{comment.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.MindMapCommentResp{
+		MindMapCommentDTO: caster.CastMindMapCommentDO2DTO(comment, users),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapComment(ctx context.Context, mapID, commentID string) (rsp *def.DeleteMindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID}, rsp, err)
+	}()
+
+	if err = h.MindMapService.DeleteMindMapComment(ctx, mapID, commentID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.DeleteMindMapCommentResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) StarMindMap(ctx context.Context, mapID string) (rsp *def.StarMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.star_mindmap", mapID, rsp, err)
+	}()
+
+	if err = h.MindMapService.StarMindMap(ctx, mapID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.StarMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UnstarMindMap(ctx context.Context, mapID string) (rsp *def.StarMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.unstar_mindmap", mapID, rsp, err)
+	}()
+
+	if err = h.MindMapService.UnstarMindMap(ctx, mapID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.StarMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ExportMindMap(ctx context.Context, mapID string, req *def.ExportMindMapReq) (rsp *def.ExportMindMapResp, err error) {
+	defer func() {
+		// 文件内容不写入日志
+		zlog.CtxAllInOne(ctx, "handler.export_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, nil, err)
+	}()
+
+	export, err := h.MindMapService.ExportMindMap(ctx, mapID, caster.CastExportMindMapReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+	return caster.CastMindMapExport2DTO(export), nil
+}
+
+func (h *Handler) CreateMindMapFolder(ctx context.Context, req *def.CreateMindMapFolderReq) (rsp *def.CreateMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_folder", req, rsp, err)
+	}()
+
+	folder, err := h.MindMapService.CreateMindMapFolder(ctx, caster.CastCreateMindMapFolderReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.CreateMindMapFolderResp{
+		MindMapFolderDTO: caster.CastMindMapFolderDO2DTO(folder),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapFolders(ctx context.Context) (rsp *def.ListMindMapFoldersResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_folders", nil, rsp, err)
+	}()
+
+	folders, err := h.MindMapService.ListMindMapFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListMindMapFoldersResp{
+		List: caster.CastMindMapFolders2DTOs(folders),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UpdateMindMapFolder(ctx context.Context, folderID string, req *def.UpdateMindMapFolderReq) (rsp *def.UpdateMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_mindmap_folder", map[string]interface{}{"folderID": folderID, "req": req}, rsp, err)
+	}()
+
+	folder, err := h.MindMapService.UpdateMindMapFolder(ctx, folderID, caster.CastUpdateMindMapFolderReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.UpdateMindMapFolderResp{
+		MindMapFolderDTO: caster.CastMindMapFolderDO2DTO(folder),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapFolder(ctx context.Context, folderID string) (rsp *def.DeleteMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_folder", folderID, rsp, err)
+	}()
+
+	if err = h.MindMapService.DeleteMindMapFolder(ctx, folderID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.DeleteMindMapFolderResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) MoveMindMap(ctx context.Context, mapID string, req *def.MoveMindMapReq) (rsp *def.MoveMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.move_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	if err = h.MindMapService.MoveMindMap(ctx, mapID, req.FolderID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.MoveMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) SetMindMapTags(ctx context.Context, mapID string, req *def.SetMindMapTagsReq) (rsp *def.SetMindMapTagsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.set_mindmap_tags", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	tags, err := h.MindMapService.SetMindMapTags(ctx, mapID, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.SetMindMapTagsResp{
+		Tags: tags,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RemoveMindMapTag(ctx context.Context, mapID, tag string) (rsp *def.RemoveMindMapTagResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.remove_mindmap_tag", map[string]interface{}{"mapID": mapID, "tag": tag}, rsp, err)
+	}()
+
+	if err = h.MindMapService.RemoveMindMapTag(ctx, mapID, tag); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.RemoveMindMapTagResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapTags(ctx context.Context) (rsp *def.ListMindMapTagsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_tags", nil, rsp, err)
+	}()
+
+	tags, err := h.MindMapService.ListMindMapTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListMindMapTagsResp{
+		List: caster.CastMindMapTags2DTOs(tags),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) SearchMindMaps(ctx context.Context, req *def.SearchMindMapsReq) (rsp *def.SearchMindMapsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.search_mindmaps", req, rsp, err)
+	}()
+
+	hits, total, err := h.MindMapService.SearchMindMaps(ctx, caster.CastSearchMindMapsReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.SearchMindMapsResp{
+		List:     caster.CastMindMapSearchHits2DTOs(hits),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapTemplate(ctx context.Context, req *def.MindMapTemplateReq) (rsp *def.MindMapTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_template", req, rsp, err)
+	}()
+
+	template, err := h.MindMapService.CreateMindMapTemplate(ctx, caster.CastMindMapTemplateReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.MindMapTemplateResp{
+		MindMapTemplateDTO: caster.CastMindMapTemplateDO2DTO(template),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UpdateMindMapTemplate(ctx context.Context, templateID string, req *def.MindMapTemplateReq) (rsp *def.MindMapTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_mindmap_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+	}()
+
+	template, err := h.MindMapService.UpdateMindMapTemplate(ctx, templateID, caster.CastMindMapTemplateReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.MindMapTemplateResp{
+		MindMapTemplateDTO: caster.CastMindMapTemplateDO2DTO(template),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapTemplate(ctx context.Context, templateID string) (rsp *def.DeleteMindMapTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_template", templateID, rsp, err)
+	}()
+
+	if err = h.MindMapService.DeleteMindMapTemplate(ctx, templateID); err != nil {
+		return nil, err
+	}
+
+	rsp = &def.DeleteMindMapTemplateResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapTemplates(ctx context.Context, req *def.ListMindMapTemplatesReq) (rsp *def.ListMindMapTemplatesResp, err error) {
+	defer func() {
+		// 模板包含完整导图数据，不记录响应
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_templates", req, nil, err)
+	}()
+
+	templates, categories, err := h.MindMapService.ListMindMapTemplates(ctx, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.ListMindMapTemplatesResp{
+		Categories: categories,
+		List:       caster.CastMindMapTemplates2DTOs(templates),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapFromTemplate(ctx context.Context, templateID string, req *def.CreateMindMapFromTemplateReq) (rsp *def.CreateMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_from_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+	}()
+
+	mindmap, err := h.MindMapService.CreateMindMapFromTemplate(ctx, templateID, caster.CastCreateMindMapFromTemplateReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.CreateMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindmap),
+	}
+	return rsp, nil
+}
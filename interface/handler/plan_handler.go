@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+)
+
+// GetPlan 查看当前用户的套餐与用量
+func (h *Handler) GetPlan(ctx context.Context) (rsp *def.GetPlanResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_plan", nil, rsp, err)
+	}()
+
+	usage, err := h.QuotaService.GetPlanUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastPlanUsage2Resp(usage), nil
+}
+
+// UpdateUserPlan 管理员修改用户套餐
+func (h *Handler) UpdateUserPlan(ctx context.Context, req *def.UpdateUserPlanReq) (rsp *def.UpdateUserPlanResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_user_plan", req, rsp, err)
+	}()
+
+	if err = h.QuotaService.SetUserPlan(ctx, req.UserID, req.Plan); err != nil {
+		return nil, err
+	}
+
+	return &def.UpdateUserPlanResp{Success: true}, nil
+}
+
+// AdminGetUserQuota 管理员查看用户的套餐、用量与单独设置的配额
+func (h *Handler) AdminGetUserQuota(ctx context.Context, req *def.AdminGetUserQuotaReq) (rsp *def.GetPlanResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.admin_get_user_quota", req, rsp, err)
+	}()
+
+	usage, err := h.QuotaService.GetUserPlanUsage(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return caster.CastPlanUsage2Resp(usage), nil
+}
+
+// UpdateUserQuota 管理员为用户单独设置导图数量、节点数量与存储空间配额
+func (h *Handler) UpdateUserQuota(ctx context.Context, req *def.UpdateUserQuotaReq) (rsp *def.UpdateUserQuotaResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_user_quota", req, rsp, err)
+	}()
+
+	if err = h.QuotaService.SetUserQuota(ctx, req.UserID, req.MaxMindMaps, req.MaxNodesPerMap, req.MaxStorageBytes); err != nil {
+		return nil, err
+	}
+
+	return &def.UpdateUserQuotaResp{Success: true}, nil
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+
+	"forge/interface/caster"
+	"forge/interface/def"
+)
+
+// ListPromptPresets 当前用户可用的预设：全局预设与自己创建的预设
+func (h *Handler) ListPromptPresets(ctx context.Context) (*def.ListPromptPresetsResponse, error) {
+	presets, err := h.PresetService.ListPresets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.ListPromptPresetsResponse{
+		List:    caster.CastPromptPresetDOs2Resp(presets),
+		Success: true,
+	}
+	return resp, nil
+}
+
+// CreatePromptPreset 创建预设，global 为 true 时创建全局预设
+func (h *Handler) CreatePromptPreset(ctx context.Context, req *def.PromptPresetRequest, global bool) (*def.PromptPresetResponse, error) {
+	params := caster.CastPromptPresetReq2Params(req, global)
+
+	preset, err := h.PresetService.CreatePreset(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.PromptPresetResponse{
+		Preset:  caster.CastPromptPresetDO2Resp(preset),
+		Success: true,
+	}
+	return resp, nil
+}
+
+// UpdatePromptPreset 修改预设
+func (h *Handler) UpdatePromptPreset(ctx context.Context, presetID string, req *def.PromptPresetRequest, global bool) (*def.PromptPresetResponse, error) {
+	params := caster.CastPromptPresetReq2Params(req, global)
+
+	preset, err := h.PresetService.UpdatePreset(ctx, presetID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &def.PromptPresetResponse{
+		Preset:  caster.CastPromptPresetDO2Resp(preset),
+		Success: true,
+	}
+	return resp, nil
+}
+
+// DeletePromptPreset 删除预设
+func (h *Handler) DeletePromptPreset(ctx context.Context, presetID string, global bool) (*def.DeletePromptPresetResponse, error) {
+	if err := h.PresetService.DeletePreset(ctx, presetID, global); err != nil {
+		return nil, err
+	}
+
+	resp := &def.DeletePromptPresetResponse{
+		Success: true,
+	}
+	return resp, nil
+}
@@ -4,22 +4,23 @@ import (
 	"context"
 	"forge/infra/configs"
 
-	// "forge/constant"
 	"forge/biz/entity"
+	"forge/biz/types"
 	"forge/biz/userservice"
+	"forge/constant"
 	"forge/interface/caster"
 	"forge/interface/def"
 	"forge/pkg/log/zlog"
-	// "forge/pkg/loop"
+	"forge/pkg/loop"
 )
 
 func (h *Handler) Login(ctx context.Context, req *def.LoginReq) (rsp *def.LoginResp, err error) {
 
-	// 这里用作handler级别的链路追踪 - TODO: cozeloop配置好后启用
-	// ctx, sp := loop.GetNewSpan(ctx, "handler.login", constant.LoopSpanType_Handle)
+	// 这里用作handler级别的链路追踪
+	ctx, sp := loop.GetNewSpan(ctx, "handler.login", constant.LoopSpanType_Handle)
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.login", req, rsp, err)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 	}()
 
 	// 这里可能会做更复杂的service编排
@@ -29,24 +30,65 @@ func (h *Handler) Login(ctx context.Context, req *def.LoginReq) (rsp *def.LoginR
 	// 所以这里这么做区分
 	// 同时，发布事件应该也在handler层做，service层做就会腐化（引入与你无关的代码）
 	// 调用服务层登录
-	user, token, err := h.UserService.Login(ctx, req.Account, req.AccountType, req.Password)
+	user, tokens, err := h.UserService.Login(ctx, caster.CastAccount(req.Account, req.AccountType, req.CountryCode), req.AccountType, req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	// 组装响应
 	rsp = &def.LoginResp{
-		Token:    token,
-		UserID:   user.UserID,
-		UserName: user.UserName,
-		Avatar:   user.Avatar,
-		Phone:    user.Phone,
-		Email:    user.Email,
-		Success:  true, // 登录成功
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        tokens.ExpiresIn,
+		RefreshExpiresIn: tokens.RefreshExpiresIn,
+		UserID:           user.UserID,
+		UserName:         user.UserName,
+		Avatar:           h.COSService.SignDeliveryURL(ctx, user.Avatar),
+		Phone:            user.Phone,
+		Email:            user.Email,
+		Success:          true, // 登录成功
 	}
 	return rsp, nil
 }
 
+// RefreshToken 用刷新令牌换取新的令牌对
+func (h *Handler) RefreshToken(ctx context.Context, req *def.RefreshTokenReq) (rsp *def.RefreshTokenResp, err error) {
+	defer func() {
+		// 令牌属于敏感信息，不打印请求与响应
+		zlog.CtxAllInOne(ctx, "handler.refresh_token", nil, nil, err)
+	}()
+
+	tokens, err := h.UserService.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.RefreshTokenResp{
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        tokens.ExpiresIn,
+		RefreshExpiresIn: tokens.RefreshExpiresIn,
+		Success:          true,
+	}
+	return rsp, nil
+}
+
+// Logout 退出登录，当前访问令牌加入黑名单并吊销刷新令牌
+func (h *Handler) Logout(ctx context.Context, req *def.LogoutReq) (rsp *def.LogoutResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.logout", nil, rsp, err)
+	}()
+
+	if err = h.UserService.RevokeAccessToken(ctx, req.TokenID, req.TokenExpiresAt); err != nil {
+		return nil, err
+	}
+	if err = h.UserService.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
+		return nil, err
+	}
+
+	return &def.LogoutResp{Success: true}, nil
+}
+
 func (h *Handler) Register(ctx context.Context, req *def.RegisterReq) (rsp *def.RegisterResp, err error) {
 	//
 
@@ -98,7 +140,7 @@ func (h *Handler) GetVersion(ctx context.Context, req *def.GetVersionReq) (rsp *
 
 func (h *Handler) SendCode(ctx context.Context, req *def.SendVerificationCodeReq) (rsp *def.SendVerificationCodeResp, err error) {
 	// 调用服务层发送验证码
-	err = h.UserService.SendVerificationCode(ctx, req.Account, req.AccountType, req.Purpose)
+	err = h.UserService.SendVerificationCode(ctx, caster.CastAccount(req.Account, req.AccountType, req.CountryCode), req.AccountType, req.Purpose)
 	if err != nil {
 		return nil, err
 	}
@@ -122,15 +164,18 @@ func (h *Handler) GetHome(ctx context.Context) (rsp *def.GetHomeResp, err error)
 	}
 
 	// 判断是否有密码
-	hasPassword := user.Password != ""
+	hasPassword := user.HasPassword()
 
 	// 组装响应
 	rsp = &def.GetHomeResp{
 		UserName:    user.UserName,
-		Avatar:      user.Avatar,
+		Avatar:      h.COSService.SignDeliveryURL(ctx, user.Avatar),
 		Phone:       user.Phone,
 		Email:       user.Email,
 		HasPassword: hasPassword,
+		UserProfile: caster.CastUserDO2Profile(user),
+
+		ReminderEmail: !user.ReminderOptOut,
 	}
 	return rsp, nil
 }
@@ -173,6 +218,47 @@ func (h *Handler) UnbindAccount(ctx context.Context, req *def.UnbindAccountReq)
 	return rsp, nil
 }
 
+// UpdateProfile 修改个人资料
+func (h *Handler) UpdateProfile(ctx context.Context, req *def.UpdateProfileReq) (rsp *def.UpdateProfileResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_profile", req, rsp, err)
+	}()
+
+	user, err := h.UserService.UpdateProfile(ctx, caster.CastUpdateProfileReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.UpdateProfileResp{
+		Success: true,
+		Profile: caster.CastUserDO2Profile(user),
+	}, nil
+}
+
+// DeleteAccount 申请注销账号，成功后吊销当前令牌，冷静期内重新登录可撤销
+func (h *Handler) DeleteAccount(ctx context.Context, req *def.DeleteAccountReq) (rsp *def.DeleteAccountResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_account", nil, rsp, err)
+	}()
+
+	scheduledAt, err := h.UserService.RequestAccountDeletion(ctx, caster.CastDeleteAccountReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.UserService.RevokeAccessToken(ctx, req.TokenID, req.TokenExpiresAt); err != nil {
+		return nil, err
+	}
+	if err = h.UserService.RevokeRefreshToken(ctx, req.RefreshToken); err != nil {
+		return nil, err
+	}
+
+	return &def.DeleteAccountResp{
+		Success:             true,
+		DeletionScheduledAt: scheduledAt,
+	}, nil
+}
+
 func (h *Handler) UpdateAvatar(ctx context.Context, req *def.UpdateAvatarReq) (rsp *def.UpdateAvatarResp, err error) {
 	defer func() {
 		zlog.CtxAllInOne(ctx, "handler.update_avatar", req, rsp, err)
@@ -210,8 +296,336 @@ func (h *Handler) UpdateAvatar(ctx context.Context, req *def.UpdateAvatarReq) (r
 	}
 
 	rsp = &def.UpdateAvatarResp{
-		AvatarURL: avatarURL,
+		AvatarURL: h.COSService.SignDeliveryURL(ctx, avatarURL),
 		Success:   true,
 	}
 	return rsp, nil
 }
+
+// UpdateNotificationPreference 修改通知偏好
+func (h *Handler) UpdateNotificationPreference(ctx context.Context, req *def.UpdateNotificationPreferenceReq) (rsp *def.UpdateNotificationPreferenceResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_notification_preference", req, rsp, err)
+	}()
+
+	if err = h.UserService.UpdateNotificationPreference(ctx, *req.ReminderEmail); err != nil {
+		return nil, err
+	}
+
+	return &def.UpdateNotificationPreferenceResp{Success: true}, nil
+}
+
+// UnsubscribeReminder 通过邮件链接退订提醒
+func (h *Handler) UnsubscribeReminder(ctx context.Context, req *def.UnsubscribeReminderReq) (rsp *def.UnsubscribeReminderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.unsubscribe_reminder", req, rsp, err)
+	}()
+
+	if err = h.UserService.UnsubscribeReminder(ctx, req.UserID, req.Token); err != nil {
+		return nil, err
+	}
+
+	return &def.UnsubscribeReminderResp{Success: true}, nil
+}
+
+// OAuthAuthorize 第三方登录授权地址
+func (h *Handler) OAuthAuthorize(ctx context.Context, req *def.OAuthAuthorizeReq) (rsp *def.OAuthAuthorizeResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.oauth_authorize", req, rsp, err)
+	}()
+
+	authURL, err := h.UserService.OAuthAuthorizeURL(ctx, req.Provider, types.OAuthPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.OAuthAuthorizeResp{AuthURL: authURL, Success: true}, nil
+}
+
+// OAuthBindAuthorize 绑定第三方账号的授权地址
+func (h *Handler) OAuthBindAuthorize(ctx context.Context, req *def.OAuthAuthorizeReq) (rsp *def.OAuthAuthorizeResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.oauth_bind_authorize", req, rsp, err)
+	}()
+
+	authURL, err := h.UserService.OAuthAuthorizeURL(ctx, req.Provider, types.OAuthPurposeBind)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.OAuthAuthorizeResp{AuthURL: authURL, Success: true}, nil
+}
+
+// OAuthLogin 第三方登录
+func (h *Handler) OAuthLogin(ctx context.Context, req *def.OAuthLoginReq) (rsp *def.LoginResp, err error) {
+	defer func() {
+		// 授权码与令牌属于敏感信息，不打印请求与响应
+		zlog.CtxAllInOne(ctx, "handler.oauth_login", req.Provider, nil, err)
+	}()
+
+	user, tokens, err := h.UserService.LoginWithOAuth(ctx, req.Provider, req.Code, req.State)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.LoginResp{
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        tokens.ExpiresIn,
+		RefreshExpiresIn: tokens.RefreshExpiresIn,
+		UserID:           user.UserID,
+		UserName:         user.UserName,
+		Avatar:           h.COSService.SignDeliveryURL(ctx, user.Avatar),
+		Phone:            user.Phone,
+		Email:            user.Email,
+		Success:          true,
+	}
+	return rsp, nil
+}
+
+// GetCaptcha 获取图形验证码
+func (h *Handler) GetCaptcha(ctx context.Context) (rsp *def.GetCaptchaResp, err error) {
+	defer func() {
+		// 响应中包含图片，不打印
+		zlog.CtxAllInOne(ctx, "handler.get_captcha", nil, nil, err)
+	}()
+
+	challenge, err := h.CaptchaService.NewChallenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.GetCaptchaResp{
+		CaptchaID: challenge.CaptchaID,
+		Image:     challenge.Image,
+		ExpiresIn: challenge.ExpiresIn,
+		Success:   true,
+	}, nil
+}
+
+// SendMagicLink 发送免密登录链接
+func (h *Handler) SendMagicLink(ctx context.Context, req *def.SendMagicLinkReq) (rsp *def.SendMagicLinkResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.send_magic_link", req, rsp, err)
+	}()
+
+	if err = h.UserService.SendMagicLink(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	return &def.SendMagicLinkResp{Success: true}, nil
+}
+
+// MagicLinkLogin 使用登录链接登录
+func (h *Handler) MagicLinkLogin(ctx context.Context, req *def.MagicLinkLoginReq) (rsp *def.LoginResp, err error) {
+	defer func() {
+		// 链接令牌与登录令牌属于敏感信息，不打印请求与响应
+		zlog.CtxAllInOne(ctx, "handler.magic_link_login", nil, nil, err)
+	}()
+
+	user, tokens, err := h.UserService.LoginWithMagicLink(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp = &def.LoginResp{
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        tokens.ExpiresIn,
+		RefreshExpiresIn: tokens.RefreshExpiresIn,
+		UserID:           user.UserID,
+		UserName:         user.UserName,
+		Avatar:           h.COSService.SignDeliveryURL(ctx, user.Avatar),
+		Phone:            user.Phone,
+		Email:            user.Email,
+		Success:          true,
+	}
+	return rsp, nil
+}
+
+// BindOAuth 绑定第三方账号
+func (h *Handler) BindOAuth(ctx context.Context, req *def.BindOAuthReq) (rsp *def.BindOAuthResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.bind_oauth", req.Provider, rsp, err)
+	}()
+
+	if err = h.UserService.BindOAuth(ctx, req.Provider, req.Code, req.State); err != nil {
+		return nil, err
+	}
+
+	return &def.BindOAuthResp{Success: true}, nil
+}
+
+// UnbindOAuth 解绑第三方账号
+func (h *Handler) UnbindOAuth(ctx context.Context, req *def.UnbindOAuthReq) (rsp *def.UnbindOAuthResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.unbind_oauth", req, rsp, err)
+	}()
+
+	if err = h.UserService.UnbindOAuth(ctx, req.Provider); err != nil {
+		return nil, err
+	}
+
+	return &def.UnbindOAuthResp{Success: true}, nil
+}
+
+// ListOAuthBindings 已绑定的第三方账号
+func (h *Handler) ListOAuthBindings(ctx context.Context) (rsp *def.ListOAuthBindingsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_oauth_bindings", nil, rsp, err)
+	}()
+
+	identities, err := h.UserService.ListOAuthBindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListOAuthBindingsResp{
+		Bindings: caster.CastUserIdentityDOs2DTOs(identities),
+		Success:  true,
+	}, nil
+}
+
+// ListSessions 登录记录
+func (h *Handler) ListSessions(ctx context.Context, req *def.ListSessionsReq) (rsp *def.ListSessionsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_sessions", req, rsp, err)
+	}()
+
+	sessions, err := h.UserService.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListSessionsResp{
+		Sessions: caster.CastUserSessionDOs2DTOs(sessions, req.CurrentSessionID),
+		Success:  true,
+	}, nil
+}
+
+// RevokeSession 远程下线登录会话
+func (h *Handler) RevokeSession(ctx context.Context, req *def.RevokeSessionReq) (rsp *def.RevokeSessionResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.revoke_session", req, rsp, err)
+	}()
+
+	if err = h.UserService.RevokeSession(ctx, req.SessionID); err != nil {
+		return nil, err
+	}
+
+	return &def.RevokeSessionResp{Success: true}, nil
+}
+
+// MergeAccount 合并账号
+func (h *Handler) MergeAccount(ctx context.Context, req *def.MergeAccountReq) (rsp *def.MergeAccountResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.merge_account", nil, rsp, err)
+	}()
+
+	result, err := h.UserService.MergeAccount(ctx, caster.CastMergeAccountReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.MergeAccountResp{
+		Success:       true,
+		MergedUserID:  result.MergedUserID,
+		MindMaps:      result.MindMaps,
+		Conversations: result.Conversations,
+	}, nil
+}
+
+// CreateInviteCode 生成邀请码
+func (h *Handler) CreateInviteCode(ctx context.Context) (rsp *def.CreateInviteCodeResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_invite_code", nil, rsp, err)
+	}()
+
+	invite, err := h.UserService.CreateInviteCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.CreateInviteCodeResp{
+		InviteCode: caster.CastInviteCodeDO2DTO(invite),
+		Success:    true,
+	}, nil
+}
+
+// ListInviteCodes 我的邀请码
+func (h *Handler) ListInviteCodes(ctx context.Context) (rsp *def.ListInviteCodesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_invite_codes", nil, rsp, err)
+	}()
+
+	codes, err := h.UserService.ListInviteCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListInviteCodesResp{
+		Codes:   caster.CastInviteCodeDOs2DTOs(codes),
+		Success: true,
+	}, nil
+}
+
+// ListReferrals 我邀请注册的用户
+func (h *Handler) ListReferrals(ctx context.Context) (rsp *def.ListReferralsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_referrals", nil, rsp, err)
+	}()
+
+	referrals, err := h.UserService.ListReferrals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inviteeIDs := make([]string, 0, len(referrals))
+	for _, referral := range referrals {
+		inviteeIDs = append(inviteeIDs, referral.InviteeID)
+	}
+	invitees, err := h.getUserBriefs(ctx, inviteeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListReferralsResp{
+		Referrals: caster.CastReferralDOs2DTOs(referrals, invitees),
+		Success:   true,
+	}, nil
+}
+
+// ListAuditLogs 当前用户的账号安全记录
+func (h *Handler) ListAuditLogs(ctx context.Context, req *def.ListAuditLogsReq) (rsp *def.ListAuditLogsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_audit_logs", req, nil, err)
+	}()
+
+	logs, total, err := h.AuditService.ListMyAuditLogs(ctx, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &def.ListAuditLogsResp{
+		List:     caster.CastAuditLogDOs2DTOs(logs),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Success:  true,
+	}, nil
+}
+
+// getUserBriefs 批量获取用户展示信息，头像链接附加 CDN 鉴权签名
+// 用户信息来自缓存，签名写在副本上，不修改缓存中的地址
+func (h *Handler) getUserBriefs(ctx context.Context, userIDs []string) (map[string]*entity.UserBrief, error) {
+	users, err := h.UserService.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	briefs := make(map[string]*entity.UserBrief, len(users))
+	for userID, user := range users {
+		brief := *user
+		brief.Avatar = h.COSService.SignDeliveryURL(ctx, user.Avatar)
+		briefs[userID] = &brief
+	}
+	return briefs, nil
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"forge/biz/captchaservice"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 图形验证码通过请求头传递，不影响各接口原有的请求体
+const (
+	CaptchaIDHeader     = "X-Captcha-Id"
+	CaptchaAnswerHeader = "X-Captcha-Answer"
+)
+
+// Captcha 图形验证码中间件，route 为配置中的接口名
+// 同一来源请求过多时要求携带图形验证码，未携带或错误时直接返回，不再进入接口
+func Captcha(captchaService types.ICaptchaService, route string) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		if !captchaService.Required(ctx, route) {
+			gCtx.Next()
+			return
+		}
+
+		err := captchaService.Verify(ctx, gCtx.GetHeader(CaptchaIDHeader), gCtx.GetHeader(CaptchaAnswerHeader))
+		if err == nil {
+			gCtx.Next()
+			return
+		}
+
+		var msgCode response.MsgCode
+		switch {
+		case errors.Is(err, captchaservice.ErrCaptchaRequired):
+			msgCode = response.IMAGE_CAPTCHA_REQUIRED
+		case errors.Is(err, captchaservice.ErrCaptchaIncorrect):
+			msgCode = response.IMAGE_CAPTCHA_ERROR
+		default:
+			msgCode = response.INTERNAL_ERROR
+		}
+		zlog.CtxWarnf(ctx, "captcha check failed for %s: %v", route, err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    nil,
+		})
+		gCtx.Abort()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"forge/biz/entity"
 	"forge/constant"
 	"forge/pkg/log/zlog"
 
@@ -25,6 +26,10 @@ func AddTracer() gin.HandlerFunc {
 		// 增加Logid
 		ctx := gCtx.Request.Context()
 		ctx = zlog.WithLogKey(ctx, zap.String(constant.LOGID, logID))
+		// 来源IP，经过反向代理时依赖 gin 的可信代理配置解析 X-Forwarded-For
+		ctx = entity.WithClientIP(ctx, gCtx.ClientIP())
+		ctx = entity.WithLanguage(ctx, gCtx.GetHeader("Accept-Language"))
+		ctx = entity.WithUserAgent(ctx, gCtx.Request.UserAgent())
 		gCtx.Request = gCtx.Request.WithContext(ctx)
 		gCtx.Next()
 	}
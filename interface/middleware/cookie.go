@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTokenCookie   = "forge_token"
+	defaultRefreshCookie = "forge_refresh"
+	defaultCSRFCookie    = "forge_csrf"
+	defaultCookiePath    = "/"
+	// CSRFHeader 前端从 csrf cookie 读出值后放在该请求头中
+	CSRFHeader = "X-CSRF-Token"
+	// authViaCookieKey 本次请求是否通过 cookie 鉴权，CSRF 中间件据此判断是否需要校验
+	authViaCookieKey = "auth_via_cookie"
+)
+
+// CookieAuth cookie 鉴权模式下 token、刷新令牌与 csrf cookie 的读写
+// 未开启时各方法都是空操作，可以直接传 nil
+type CookieAuth struct {
+	cfg           configs.CookieAuthConfig
+	sameSite      http.SameSite
+	maxAge        time.Duration
+	refreshMaxAge time.Duration
+}
+
+// NewCookieAuth 创建 cookie 鉴权，maxAge、refreshMaxAge 分别与访问令牌、刷新令牌有效期保持一致；未开启时返回 nil
+func NewCookieAuth(cfg configs.CookieAuthConfig, maxAge, refreshMaxAge time.Duration) *CookieAuth {
+	if !cfg.Enable {
+		return nil
+	}
+	if cfg.TokenCookie == "" {
+		cfg.TokenCookie = defaultTokenCookie
+	}
+	if cfg.RefreshCookie == "" {
+		cfg.RefreshCookie = defaultRefreshCookie
+	}
+	if cfg.CSRFCookie == "" {
+		cfg.CSRFCookie = defaultCSRFCookie
+	}
+	if cfg.Path == "" {
+		cfg.Path = defaultCookiePath
+	}
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		// SameSite=None 必须配合 Secure，否则浏览器会丢弃 cookie
+		sameSite = http.SameSiteNoneMode
+		cfg.Secure = true
+	}
+
+	return &CookieAuth{cfg: cfg, sameSite: sameSite, maxAge: maxAge, refreshMaxAge: refreshMaxAge}
+}
+
+// Enabled 是否开启了 cookie 鉴权
+func (c *CookieAuth) Enabled() bool {
+	return c != nil
+}
+
+// SetLoginCookies 登录或刷新成功后写入 token、刷新令牌 cookie（HttpOnly）和 csrf cookie（前端可读）
+func (c *CookieAuth) SetLoginCookies(gCtx *gin.Context, token, refreshToken string) error {
+	if c == nil {
+		return nil
+	}
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		return err
+	}
+	maxAge := int(c.maxAge / time.Second)
+	refreshMaxAge := int(c.refreshMaxAge / time.Second)
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.TokenCookie, token, maxAge, true))
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.RefreshCookie, refreshToken, refreshMaxAge, true))
+	// csrf cookie 需要覆盖刷新令牌的有效期，访问令牌过期后刷新时仍可读到
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.CSRFCookie, csrfToken, refreshMaxAge, false))
+	return nil
+}
+
+// ClearCookies 退出登录时清除 token、刷新令牌与 csrf cookie
+func (c *CookieAuth) ClearCookies(gCtx *gin.Context) {
+	if c == nil {
+		return
+	}
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.TokenCookie, "", -1, true))
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.RefreshCookie, "", -1, true))
+	http.SetCookie(gCtx.Writer, c.newCookie(c.cfg.CSRFCookie, "", -1, false))
+}
+
+// tokenFromCookie 读取 cookie 中的 token
+func (c *CookieAuth) tokenFromCookie(gCtx *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	token, err := gCtx.Cookie(c.cfg.TokenCookie)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// RefreshTokenFromCookie 读取 cookie 中的刷新令牌，未开启或不存在时返回空
+func (c *CookieAuth) RefreshTokenFromCookie(gCtx *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	token, err := gCtx.Cookie(c.cfg.RefreshCookie)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+func (c *CookieAuth) newCookie(name, value string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     c.cfg.Path,
+		Domain:   c.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   c.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite,
+	}
+}
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CSRF 双重提交 cookie 校验，需挂在 JWTAuth 之后
+// 只校验通过 cookie 鉴权的写请求；使用 Authorization 头的请求浏览器不会自动携带，不存在 CSRF 问题
+func CSRF(cookieAuth *CookieAuth) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if !cookieAuth.Enabled() || !gCtx.GetBool(authViaCookieKey) {
+			gCtx.Next()
+			return
+		}
+		switch gCtx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			gCtx.Next()
+			return
+		}
+
+		ctx := gCtx.Request.Context()
+		headerToken := gCtx.GetHeader(CSRFHeader)
+		cookieToken, err := gCtx.Cookie(cookieAuth.cfg.CSRFCookie)
+		if err != nil || headerToken == "" ||
+			subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) != 1 {
+			zlog.CtxWarnf(ctx, "csrf token mismatch for %s %s", gCtx.Request.Method, gCtx.Request.URL.Path)
+			gCtx.JSON(http.StatusForbidden, response.JsonMsgResult{
+				Code:    response.CSRF_TOKEN_INVALID.Code,
+				Message: response.CSRF_TOKEN_INVALID.Msg,
+				Data:    nil,
+			})
+			gCtx.Abort()
+			return
+		}
+
+		gCtx.Next()
+	}
+}
@@ -14,40 +14,58 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// tokenClaimsKey 当前请求访问令牌的声明在 gin context 中的 key
+const tokenClaimsKey = "jwt_claims"
+
+// TokenClaims 获取 JWTAuth 校验通过的访问令牌声明，未经过 JWTAuth 时返回 nil
+func TokenClaims(gCtx *gin.Context) *util.Claims {
+	claims, ok := gCtx.Get(tokenClaimsKey)
+	if !ok {
+		return nil
+	}
+	c, _ := claims.(*util.Claims)
+	return c
+}
+
 // JWTAuth JWT鉴权中间件
 // 从请求头获取token，验证token，提取用户信息并注入到context中
-func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerFunc {
+// 开启 cookie 鉴权时，没有 Authorization 头的请求从 cookie 中读取 token
+func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService, cookieAuth *CookieAuth) gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		ctx := gCtx.Request.Context()
 
 		// 从请求头获取token
 		authHeader := gCtx.GetHeader("Authorization")
+		var tokenString string
 		if authHeader == "" {
-			zlog.CtxWarnf(ctx, "missing authorization header")
-			gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
-				Code:    response.USER_NOT_LOGIN.Code,
-				Message: response.USER_NOT_LOGIN.Msg,
-				Data:    nil,
-			})
-			gCtx.Abort()
-			return
-		}
-
-		// 解析Bearer token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			zlog.CtxWarnf(ctx, "invalid authorization header format")
-			gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
-				Code:    response.USER_NOT_LOGIN.Code,
-				Message: response.USER_NOT_LOGIN.Msg,
-				Data:    nil,
-			})
-			gCtx.Abort()
-			return
+			tokenString = cookieAuth.tokenFromCookie(gCtx)
+			if tokenString == "" {
+				zlog.CtxWarnf(ctx, "missing authorization header")
+				gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
+					Code:    response.USER_NOT_LOGIN.Code,
+					Message: response.USER_NOT_LOGIN.Msg,
+					Data:    nil,
+				})
+				gCtx.Abort()
+				return
+			}
+			gCtx.Set(authViaCookieKey, true)
+		} else {
+			// 解析Bearer token
+			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				zlog.CtxWarnf(ctx, "invalid authorization header format")
+				gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
+					Code:    response.USER_NOT_LOGIN.Code,
+					Message: response.USER_NOT_LOGIN.Msg,
+					Data:    nil,
+				})
+				gCtx.Abort()
+				return
+			}
+			tokenString = parts[1]
 		}
 
-		tokenString := parts[1]
-
 		// 验证token
 		claims, err := jwtUtil.ValidateToken(tokenString)
 		if err != nil {
@@ -67,6 +85,26 @@ func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerF
 			return
 		}
 
+		// 已退出登录的令牌在过期前也不能再使用
+		// Redis 不可用时无法确认令牌状态，与数据库查询失败一样拒绝请求
+		revoked, err := userService.IsAccessTokenRevoked(ctx, claims.ID, claims.SessionID)
+		if err != nil || revoked {
+			msgCode := response.USER_NOT_LOGIN
+			if err != nil {
+				zlog.CtxErrorf(ctx, "check token blacklist failed: %v", err)
+				msgCode = response.INTERNAL_ERROR
+			} else {
+				zlog.CtxWarnf(ctx, "token has been revoked: %s", claims.ID)
+			}
+			gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    nil,
+			})
+			gCtx.Abort()
+			return
+		}
+
 		// 从token中提取userID
 		userID := claims.UserID
 		if userID == "" {
@@ -86,8 +124,10 @@ func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerF
 			var msgCode response.MsgCode
 			if errors.Is(err, userservice.ErrUserNotFound) {
 				msgCode = response.USER_ACCOUNT_NOT_EXIST
-			} else if errors.Is(err, userservice.ErrPermissionDenied) {
-				msgCode = response.INSUFFICENT_PERMISSIONS
+			} else if errors.Is(err, userservice.ErrAccountSuspended) {
+				msgCode = response.ACCOUNT_SUSPENDED
+			} else if errors.Is(err, userservice.ErrAccountPendingDeletion) {
+				msgCode = response.ACCOUNT_PENDING_DELETION
 			} else {
 				msgCode = response.INTERNAL_ERROR
 			}
@@ -95,8 +135,8 @@ func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerF
 			zlog.CtxWarnf(ctx, "failed to get user by ID: %v", err)
 			// 用户不存在或权限不足，返回401 Unauthorized
 			statusCode := http.StatusUnauthorized
-			if msgCode == response.INSUFFICENT_PERMISSIONS {
-				// 权限不足也可返回403 Forbidden
+			if msgCode == response.ACCOUNT_SUSPENDED {
+				// 账号被禁用返回403 Forbidden
 				statusCode = http.StatusForbidden
 			}
 			gCtx.JSON(statusCode, response.JsonMsgResult{
@@ -108,6 +148,9 @@ func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerF
 			return
 		}
 
+		// 退出登录时需要当前令牌的 jti 与过期时间
+		gCtx.Set(tokenClaimsKey, claims)
+
 		// 将用户信息注入到context中
 		ctx = entity.WithUser(ctx, user)
 		// 更新gin context中的request context
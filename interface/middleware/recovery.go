@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+	"forge/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 发送告警的超时时间，告警在请求结束后异步发送
+	alertTimeout = 10 * time.Second
+	// 告警中附带的堆栈最大长度，群机器人对消息长度有限制
+	alertStackLimit = 3000
+)
+
+// 恢复的 panic 总次数，通过 debug/vars 查看
+var panicCount = expvar.NewInt("http_panics")
+
+// Recovery 捕获处理请求时的 panic：记录堆栈、计数并返回统一的内部错误
+// notifier 不为 nil 时异步发送告警，不影响响应
+// 客户端断开连接导致的写入失败不算作 panic，只中止请求
+func Recovery(notifier adapter.AlertNotifier) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			ctx := gCtx.Request.Context()
+			if brokenPipe(err) {
+				zlog.CtxWarnf(ctx, "connection closed by client, %s %s: %v", gCtx.Request.Method, gCtx.Request.URL.Path, err)
+				gCtx.Abort()
+				return
+			}
+
+			stack := string(debug.Stack())
+			panicCount.Add(1)
+			zlog.CtxErrorf(ctx, "panic recovered, %s %s: %v\n%s", gCtx.Request.Method, gCtx.Request.URL.Path, err, stack)
+			tracing.SpanFromContext(ctx).SetError(fmt.Sprint(err))
+
+			if !gCtx.Writer.Written() {
+				gCtx.AbortWithStatusJSON(http.StatusInternalServerError, response.JsonMsgResult{
+					Code:    response.INTERNAL_ERROR.Code,
+					Message: response.INTERNAL_ERROR.Msg,
+					Data:    nil,
+				})
+			} else {
+				gCtx.Abort()
+			}
+
+			if notifier != nil {
+				// 调用栈只能在 recover 所在的 defer 中获取
+				location := panicLocation()
+				go sendPanicAlert(ctx, notifier, gCtx.Request.Method, gCtx.Request.URL.Path,
+					gCtx.GetHeader("X-Request-ID"), location, err, stack)
+			}
+		}()
+		gCtx.Next()
+	}
+}
+
+// sendPanicAlert 告警 key 为 panic 发生的位置，同一处代码的 panic 按间隔合并
+func sendPanicAlert(ctx context.Context, notifier adapter.AlertNotifier, method, path, logID, location string, err any, stack string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertTimeout)
+	defer cancel()
+
+	if len(stack) > alertStackLimit {
+		stack = stack[:alertStackLimit] + "\n..."
+	}
+	key := location
+	if key == "" {
+		key = fmt.Sprint(err)
+	}
+	hostname, _ := os.Hostname()
+	content := fmt.Sprintf("接口: %s %s\n实例: %s\nlog_id: %s\ntrace_id: %s\n错误: %v\n位置: %s\n\n%s",
+		method, path, hostname, logID, tracing.SpanFromContext(ctx).TraceID(), err, key, stack)
+	if notifyErr := notifier.Notify(ctx, "panic:"+key, "[forge] 接口发生 panic", content); notifyErr != nil {
+		zlog.CtxErrorf(ctx, "send panic alert failed: %v", notifyErr)
+	}
+}
+
+// panicLocation 在 recover 时的调用栈中找到 panic 发生的位置，跳过 runtime 与本文件
+func panicLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasSuffix(frame.File, "/recovery.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// brokenPipe 客户端提前断开连接时写响应会失败，与 gin 默认 Recovery 的判断一致
+func brokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(e, http.ErrAbortHandler) || errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(e, &opErr) {
+		msg := strings.ToLower(opErr.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 角色鉴权中间件，需挂在JWTAuth之后
+// 当前用户拥有任一指定角色时放行，否则返回 403
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		user, ok := entity.GetUser(ctx)
+		if !ok {
+			zlog.CtxWarnf(ctx, "user not found in context")
+			gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
+				Code:    response.USER_NOT_LOGIN.Code,
+				Message: response.USER_NOT_LOGIN.Msg,
+				Data:    nil,
+			})
+			gCtx.Abort()
+			return
+		}
+
+		if !user.HasRole(roles...) {
+			zlog.CtxWarnf(ctx, "user %s with role %q lacks required role %s", user.UserID, user.Role, strings.Join(roles, "/"))
+			gCtx.JSON(http.StatusForbidden, response.JsonMsgResult{
+				Code:    response.INSUFFICENT_PERMISSIONS.Code,
+				Message: response.INSUFFICENT_PERMISSIONS.Msg,
+				Data:    nil,
+			})
+			gCtx.Abort()
+			return
+		}
+
+		gCtx.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"forge/biz/entity"
+	"forge/constant"
+	"forge/pkg/log/zlog"
+	"forge/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Tracing 为每个请求创建 server span，延续上游 traceparent 中的链路，trace id 写入日志字段
+// 需挂在 AddTracer 之前；未开启链路追踪时直接放行
+func Tracing() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if !tracing.Enabled() {
+			gCtx.Next()
+			return
+		}
+		ctx := tracing.Extract(gCtx.Request.Context(), gCtx.Request.Header)
+		// 按路由模板命名，避免路径参数导致 span 名称过多
+		route := gCtx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.Start(ctx, gCtx.Request.Method+" "+route, tracing.SpanKindServer,
+			tracing.String("http.request.method", gCtx.Request.Method),
+			tracing.String("http.route", route),
+			tracing.String("client.address", gCtx.ClientIP()),
+		)
+		defer span.End()
+		ctx = zlog.WithLogKey(ctx, zap.String(constant.TRACEID, span.TraceID()))
+		gCtx.Request = gCtx.Request.WithContext(ctx)
+
+		gCtx.Next()
+
+		status := gCtx.Writer.Status()
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if user, ok := entity.GetUser(gCtx.Request.Context()); ok {
+			span.SetAttributes(tracing.String("enduser.id", user.UserID))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetError(http.StatusText(status))
+		}
+	}
+}
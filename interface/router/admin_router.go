@@ -0,0 +1,195 @@
+package router
+
+import (
+	"net/http"
+
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListUsers
+//
+//	@Description:[GET] /api/biz/v1/admin/user/list?keyword=&status=&role=&page=&page_size=
+//	@return gin.HandlerFunc
+func AdminListUsers() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminListUsersReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminListUsersResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminListUsers(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminListUsersResp{Success: false})
+	}
+}
+
+// AdminUpdateUserStatus
+//
+//	@Description:[POST] /api/biz/v1/admin/user/status
+//	@return gin.HandlerFunc
+func AdminUpdateUserStatus() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminUpdateUserStatusReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminUpdateUserStatusResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminUpdateUserStatus(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminUpdateUserStatusResp{Success: false})
+	}
+}
+
+// AdminSuspendUser
+//
+//	@Description:[POST] /api/biz/v1/admin/user/suspend
+//	@return gin.HandlerFunc
+func AdminSuspendUser() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminSuspendUserReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminSuspendUserResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminSuspendUser(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminSuspendUserResp{Success: false})
+	}
+}
+
+// AdminReactivateUser
+//
+//	@Description:[POST] /api/biz/v1/admin/user/reactivate
+//	@return gin.HandlerFunc
+func AdminReactivateUser() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminReactivateUserReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminReactivateUserResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminReactivateUser(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminReactivateUserResp{Success: false})
+	}
+}
+
+// AdminUpdateUserRole
+//
+//	@Description:[POST] /api/biz/v1/admin/user/role
+//	@return gin.HandlerFunc
+func AdminUpdateUserRole() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminUpdateUserRoleReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminUpdateUserRoleResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminUpdateUserRole(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminUpdateUserRoleResp{Success: false})
+	}
+}
+
+// AdminResetPassword
+//
+//	@Description:[POST] /api/biz/v1/admin/user/reset_password
+//	@return gin.HandlerFunc
+func AdminResetPassword() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminResetPasswordReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminResetPasswordResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminResetPassword(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminResetPasswordResp{Success: false})
+	}
+}
+
+// AdminListUserSessions
+//
+//	@Description:[GET] /api/biz/v1/admin/user/sessions?user_id=
+//	@return gin.HandlerFunc
+func AdminListUserSessions() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminListUserSessionsReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminListUserSessionsResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminListUserSessions(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminListUserSessionsResp{Success: false})
+	}
+}
+
+// AdminListAuditLogs
+//
+//	@Description:[GET] /api/biz/v1/admin/audit/list?user_id=&actor_id=&action=&result=&page=&page_size=
+//	@return gin.HandlerFunc
+func AdminListAuditLogs() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminListAuditLogsReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminListAuditLogsResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminListAuditLogs(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.AdminListAuditLogsResp{Success: false})
+	}
+}
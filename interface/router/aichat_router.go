@@ -3,14 +3,25 @@ package router
 import (
 	"errors"
 	"forge/biz/aichatservice"
+	"forge/biz/cosservice"
+	"forge/biz/mindmapservice"
+	"forge/biz/presetservice"
+	"forge/constant"
 	"forge/interface/def"
 	"forge/interface/handler"
+	"forge/pkg/docparse"
 	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
 	"forge/pkg/response"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"net/http"
+	"strings"
 )
 
+// sseContentType 流式返回的 Content-Type
+const sseContentType = "text/event-stream"
+
 func aiChatServiceErrorToMsgCode(err error) response.MsgCode {
 	if err == nil {
 		return response.SUCCESS
@@ -37,8 +48,74 @@ func aiChatServiceErrorToMsgCode(err error) response.MsgCode {
 	if errors.Is(err, aichatservice.MIND_MAP_NOT_EXIST) {
 		return response.MIND_MAP_NOT_EXIST
 	}
+	if errors.Is(err, aichatservice.CONVERSATION_RESTORE_EXPIRED) {
+		return response.CONVERSATION_RESTORE_EXPIRED
+	}
+	if errors.Is(err, aichatservice.AI_MODEL_NOT_SUPPORTED) {
+		return response.AI_MODEL_NOT_SUPPORTED
+	}
+	if errors.Is(err, aichatservice.MESSAGE_NOT_EXIST) {
+		return response.MESSAGE_NOT_EXIST
+	}
+	if errors.Is(err, aichatservice.CONVERSATION_MAP_MISMATCH) {
+		return response.CONVERSATION_MAP_MISMATCH
+	}
+	if errors.Is(err, mindmapservice.ErrMindMapNotFound) {
+		return response.MIND_MAP_NOT_EXIST
+	}
+	if errors.Is(err, aichatservice.CONTENT_INPUT_BLOCKED) {
+		return response.CONTENT_INPUT_BLOCKED
+	}
+	if errors.Is(err, aichatservice.CONTENT_OUTPUT_BLOCKED) {
+		return response.CONTENT_OUTPUT_BLOCKED
+	}
+	if errors.Is(err, aichatservice.SEARCH_KEYWORD_INVALID) {
+		return response.SEARCH_KEYWORD_INVALID
+	}
+	if errors.Is(err, aichatservice.CONVERSATION_ORDER_INVALID) {
+		return response.CONVERSATION_ORDER_INVALID
+	}
+	if errors.Is(err, aichatservice.AI_VISION_NOT_SUPPORTED) {
+		return response.AI_VISION_NOT_SUPPORTED
+	}
+	if errors.Is(err, aichatservice.AI_IMAGE_INVALID) {
+		return response.MsgCode{Code: response.AI_IMAGE_INVALID.Code, Msg: err.Error()}
+	}
+	if errors.Is(err, cosservice.ErrInvalidImage) {
+		return response.AI_IMAGE_INVALID
+	}
+	if errors.Is(err, cosservice.ErrFileInfected) {
+		return response.COS_FILE_INFECTED
+	}
+	if errors.Is(err, cosservice.ErrFileScanFailed) {
+		return response.COS_FILE_SCAN_FAILED
+	}
+	if errors.Is(err, cosservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+	if errors.Is(err, aichatservice.AI_IMAGE_LIMIT_EXCEEDED) {
+		return response.AI_IMAGE_LIMIT_EXCEEDED
+	}
+	if errors.Is(err, presetservice.ErrPresetNotFound) {
+		return response.PROMPT_PRESET_NOT_FOUND
+	}
+	if errors.Is(err, docparse.ErrUnsupportedType) {
+		return response.DOCUMENT_UNSUPPORTED_TYPE
+	}
+	if errors.Is(err, docparse.ErrCorruptedFile) {
+		return response.DOCUMENT_CORRUPTED
+	}
+	if errors.Is(err, docparse.ErrFileTooLarge) {
+		return response.DOCUMENT_TOO_LARGE
+	}
+	if errors.Is(err, docparse.ErrTooManyPages) {
+		return response.DOCUMENT_TOO_MANY_PAGES
+	}
+	if errors.Is(err, docparse.ErrEmptyDocument) {
+		return response.DOCUMENT_EMPTY
+	}
 
-	return response.COMMON_FAIL
+	return mapQuotaServiceErrorToMsgCode(err)
 }
 
 // SendMessage 基础ai对话
@@ -47,16 +124,19 @@ func SendMessage() gin.HandlerFunc {
 		var req def.ProcessUserMessageRequest
 		ctx := gCtx.Request.Context()
 
-		if err := gCtx.ShouldBindJSON(&req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
-				Data:    def.ProcessUserMessageResponse{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.ProcessUserMessageResponse{Success: false}) {
+			return
+		}
+
+		// 请求 text/event-stream 时以 SSE 流式返回，与 send_message_stream 相同
+		if strings.Contains(gCtx.GetHeader("Accept"), sseContentType) {
+			sendMessageStream(gCtx, &req)
 			return
 		}
 
+		ctx, sp := loop.GetNewSpan(ctx, "send_message", constant.LoopSpanType_Root)
 		resp, err := handler.GetHandler().SendMessage(ctx, &req)
+		loop.SetSpanAllInOne(ctx, sp, req, resp, err)
 
 		zlog.CtxAllInOne(ctx, "send_message", map[string]interface{}{"req": req}, resp, err)
 
@@ -78,18 +158,70 @@ func SendMessage() gin.HandlerFunc {
 	}
 }
 
+// SendMessageStream 流式ai对话，以 SSE 返回：
+// delta 事件为模型输出的文本片段；done 事件为完整回复，与 send_message 的返回相同；error 事件为错误码
+// 完整回复在 done 之前已保存到会话
+func SendMessageStream() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.ProcessUserMessageRequest
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.ProcessUserMessageResponse{Success: false}) {
+			return
+		}
+		sendMessageStream(gCtx, &req)
+	}
+}
+
+func sendMessageStream(gCtx *gin.Context, req *def.ProcessUserMessageRequest) {
+	ctx := gCtx.Request.Context()
+
+	gCtx.Header("Content-Type", sseContentType)
+	gCtx.Header("Cache-Control", "no-cache")
+	gCtx.Header("Connection", "keep-alive")
+	// 关闭 nginx 的响应缓冲，否则片段会攒到一起才发给客户端
+	gCtx.Header("X-Accel-Buffering", "no")
+	gCtx.Status(http.StatusOK)
+
+	ctx, sp := loop.GetNewSpan(ctx, "send_message_stream", constant.LoopSpanType_Root)
+	resp, err := handler.GetHandler().SendMessageStream(ctx, req, func(delta string) error {
+		// 客户端断开后停止生成
+		if err := gCtx.Request.Context().Err(); err != nil {
+			return err
+		}
+		gCtx.SSEvent("delta", def.ProcessUserMessageDelta{Content: delta})
+		gCtx.Writer.Flush()
+		return nil
+	})
+	loop.SetSpanAllInOne(ctx, sp, req, resp, err)
+
+	zlog.CtxAllInOne(ctx, "send_message_stream", map[string]interface{}{"req": req}, resp, err)
+
+	if err != nil {
+		msgCode := aiChatServiceErrorToMsgCode(err)
+		if msgCode == response.COMMON_FAIL {
+			msgCode.Msg = err.Error()
+		}
+		gCtx.SSEvent("error", response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    def.ProcessUserMessageResponse{Success: false},
+		})
+	} else {
+		gCtx.SSEvent("done", response.JsonMsgResult{
+			Code:    response.SUCCESS_CODE,
+			Message: response.SUCCESS_MSG,
+			Data:    resp,
+		})
+	}
+	gCtx.Writer.Flush()
+}
+
 // SaveNewConversation 保存新的会话
 func SaveNewConversation() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		var req def.SaveNewConversationRequest
 		ctx := gCtx.Request.Context()
 
-		if err := gCtx.ShouldBindJSON(&req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
-				Data:    def.SaveNewConversationResponse{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.SaveNewConversationResponse{Success: false}) {
 			return
 		}
 
@@ -121,14 +253,7 @@ func GetConversationList() gin.HandlerFunc {
 		var req def.GetConversationListRequest
 		ctx := gCtx.Request.Context()
 
-		req.MapID = gCtx.Query("map_id")
-
-		if req.MapID == "" {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
-				Data:    def.GetConversationListResponse{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.Query, &req, def.GetConversationListResponse{Success: false}) {
 			return
 		}
 
@@ -159,12 +284,7 @@ func DelConversation() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		var req def.DelConversationRequest
 		ctx := gCtx.Request.Context()
-		if err := gCtx.ShouldBindJSON(&req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
-				Data:    def.DelConversationResponse{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.DelConversationResponse{Success: false}) {
 			return
 		}
 
@@ -189,25 +309,80 @@ func DelConversation() gin.HandlerFunc {
 	}
 }
 
+// RestoreConversation 恢复某个已删除的会话
+func RestoreConversation() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.RestoreConversationRequest
+		ctx := gCtx.Request.Context()
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.RestoreConversationResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().RestoreConversation(ctx, &req)
+		zlog.CtxAllInOne(ctx, "restore_conversation", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RestoreConversationResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
 // GetConversation 获取某个会话的详细信息
 func GetConversation() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		var req def.GetConversationRequest
 		ctx := gCtx.Request.Context()
 
-		req.ConversationID = gCtx.Query("conversation_id")
+		if !bindAndValidate(gCtx, binding.Query, &req, def.GetConversationResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().GetConversation(ctx, &req)
+		zlog.CtxAllInOne(ctx, "get_conversation", map[string]interface{}{"req": req}, resp, err)
 
-		if req.ConversationID == "" {
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
 			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
 				Data:    def.GetConversationResponse{Success: false},
 			})
 			return
+		} else {
+			r.SuccessWithETag(resp)
 		}
+	}
+}
 
-		resp, err := handler.GetHandler().GetConversation(ctx, &req)
-		zlog.CtxAllInOne(ctx, "get_conversation", map[string]interface{}{"req": req}, resp, err)
+// ForkConversation 从某条消息分支出新的会话
+func ForkConversation() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.ForkConversationRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.ForkConversationResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().ForkConversation(ctx, &req)
+
+		zlog.CtxAllInOne(ctx, "fork_conversation", map[string]interface{}{"req": req}, resp, err)
 
 		r := response.NewResponse(gCtx)
 		if err != nil {
@@ -218,7 +393,7 @@ func GetConversation() gin.HandlerFunc {
 			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
 				Code:    msgCode.Code,
 				Message: msgCode.Msg,
-				Data:    def.GetConversationResponse{Success: false},
+				Data:    def.ForkConversationResponse{Success: false},
 			})
 			return
 		} else {
@@ -227,18 +402,46 @@ func GetConversation() gin.HandlerFunc {
 	}
 }
 
-func UpdateConversationTitle() gin.HandlerFunc {
+// SummarizeMindMap 总结导图，返回整体总结与各分支要点
+func SummarizeMindMap() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
-		var req def.UpdateConversationTitleRequest
+		var req def.SummarizeMindMapRequest
 		ctx := gCtx.Request.Context()
 
-		if err := gCtx.ShouldBindJSON(&req); err != nil {
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.SummarizeMindMapResponse{Success: false}) {
+			return
+		}
+
+		ctx, sp := loop.GetNewSpan(ctx, "summarize_mind_map", constant.LoopSpanType_Root)
+		resp, err := handler.GetHandler().SummarizeMindMap(ctx, &req)
+		loop.SetSpanAllInOne(ctx, sp, req, resp, err)
+		zlog.CtxAllInOne(ctx, "summarize_mind_map", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
 			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.PARAM_NOT_COMPLETE.Code,
-				Message: response.PARAM_NOT_COMPLETE.Msg,
-				Data:    def.UpdateConversationTitleResponse{Success: false},
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SummarizeMindMapResponse{Success: false},
 			})
 			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+func UpdateConversationTitle() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.UpdateConversationTitleRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.UpdateConversationTitleResponse{Success: false}) {
+			return
 		}
 
 		resp, err := handler.GetHandler().UpdateConversationTitle(ctx, &req)
@@ -262,6 +465,160 @@ func UpdateConversationTitle() gin.HandlerFunc {
 	}
 }
 
+func ArchiveConversation() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.ArchiveConversationRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.ArchiveConversationResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().ArchiveConversation(ctx, &req)
+		zlog.CtxAllInOne(ctx, "archive_conversation", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ArchiveConversationResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+// SearchConversations 按关键词搜索会话标题与聊天内容
+//
+//	@Description:[GET] /api/biz/v1/aichat/search?q=&archived=&page=&page_size=
+//	@return gin.HandlerFunc
+func SearchConversations() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.SearchConversationsRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.Query, &req, def.SearchConversationsResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().SearchConversations(ctx, &req)
+		zlog.CtxAllInOne(ctx, "search_conversations", map[string]interface{}{"req": req}, nil, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SearchConversationsResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+func PinConversation() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.PinConversationRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.PinConversationResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().PinConversation(ctx, &req)
+		zlog.CtxAllInOne(ctx, "pin_conversation", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.PinConversationResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+func ReorderConversations() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.ReorderConversationsRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.ReorderConversationsResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().ReorderConversations(ctx, &req)
+		zlog.CtxAllInOne(ctx, "reorder_conversations", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ReorderConversationsResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+func SetConversationPreset() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.SetConversationPresetRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, &req, def.SetConversationPresetResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().SetConversationPreset(ctx, &req)
+		zlog.CtxAllInOne(ctx, "set_conversation_preset", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SetConversationPresetResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
 func GenerateMindMap() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		var req def.GenerateMindMapRequest
@@ -270,12 +627,7 @@ func GenerateMindMap() gin.HandlerFunc {
 		contentType := gCtx.ContentType()
 
 		if contentType == "application/json" {
-			if err := gCtx.ShouldBindJSON(&req); err != nil {
-				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-					Code:    response.PARAM_NOT_COMPLETE.Code,
-					Message: response.PARAM_NOT_COMPLETE.Msg,
-					Data:    def.GenerateMindMapResponse{Success: false},
-				})
+			if !bindAndValidate(gCtx, binding.JSON, &req, def.GenerateMindMapResponse{Success: false}) {
 				return
 			}
 		} else if contentType == "multipart/form-data" {
@@ -289,6 +641,7 @@ func GenerateMindMap() gin.HandlerFunc {
 				return
 			}
 			req.File = file
+			req.ConversationID = gCtx.PostForm("conversation_id")
 		} else {
 			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
 				Code:    response.INVALID_CONTENT_TYPE.Code,
@@ -298,7 +651,9 @@ func GenerateMindMap() gin.HandlerFunc {
 			return
 		}
 
+		ctx, sp := loop.GetNewSpan(ctx, "generate_mind_map", constant.LoopSpanType_Root)
 		resp, err := handler.GetHandler().GenerateMindMap(ctx, &req)
+		loop.SetSpanAllInOne(ctx, sp, req, resp, err)
 		zlog.CtxAllInOne(ctx, "generate_mind_map", map[string]interface{}{"req": req}, resp, err)
 
 		r := response.NewResponse(gCtx)
@@ -319,3 +674,58 @@ func GenerateMindMap() gin.HandlerFunc {
 
 	}
 }
+
+// GetAttachments 获取会话下归档的文件
+func GetAttachments() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.GetAttachmentsRequest
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.Query, &req, def.GetAttachmentsResponse{Success: false}) {
+			return
+		}
+
+		resp, err := handler.GetHandler().GetAttachments(ctx, &req)
+		zlog.CtxAllInOne(ctx, "get_attachments", map[string]interface{}{"req": req}, resp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetAttachmentsResponse{Success: false},
+			})
+			return
+		} else {
+			r.Success(resp)
+		}
+	}
+}
+
+// GetAiUsage 获取当前用户的AI token 用量与套餐上限
+func GetAiUsage() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		resp, err := handler.GetHandler().GetAiUsage(ctx)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetAiUsageResponse{Success: false},
+			})
+			return
+		}
+		r.Success(resp)
+	}
+}
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/backupservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/response"
+)
+
+// mapBackupServiceErrorToMsgCode 备份相关错误映射
+func mapBackupServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, backupservice.ErrInvalidParams) {
+		return response.PARAM_NOT_VALID
+	}
+
+	if errors.Is(err, backupservice.ErrBackupNotFound) {
+		return response.BACKUP_NOT_FOUND
+	}
+
+	if errors.Is(err, backupservice.ErrBackupCorrupted) {
+		return response.BACKUP_CORRUPTED
+	}
+
+	if errors.Is(err, backupservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	return mapQuotaServiceErrorToMsgCode(err)
+}
+
+func handleBackupResponse(gCtx *gin.Context, rsp interface{}, err error, emptyResp interface{}) {
+	r := response.NewResponse(gCtx)
+	if err != nil {
+		msgCode := mapBackupServiceErrorToMsgCode(err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    emptyResp,
+		})
+		return
+	}
+	r.Success(rsp)
+}
+
+// ListBackups
+//
+//	@Description:[GET] /api/biz/v1/admin/backup/list?user_id=
+//	@return gin.HandlerFunc
+func ListBackups() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListBackupsReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListBackupsResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListBackups(ctx, req)
+		handleBackupResponse(gCtx, rsp, err, def.ListBackupsResp{Success: false})
+	}
+}
+
+// RestoreBackup
+//
+//	@Description:[POST] /api/biz/v1/admin/backup/restore
+//	@return gin.HandlerFunc
+func RestoreBackup() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.RestoreBackupReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.RestoreBackupResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().RestoreBackup(ctx, req)
+		handleBackupResponse(gCtx, rsp, err, def.RestoreBackupResp{Success: false})
+	}
+}
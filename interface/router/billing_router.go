@@ -0,0 +1,146 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/billingservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+)
+
+// 支付回调请求体上限，防止恶意大包
+const maxWebhookBodySize = 1 << 20
+
+// mapBillingServiceErrorToMsgCode 支付相关错误映射
+func mapBillingServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, billingservice.ErrBillingDisabled) {
+		return response.BILLING_DISABLED
+	}
+
+	if errors.Is(err, billingservice.ErrInvalidProduct) || errors.Is(err, billingservice.ErrUnsupportedPlan) {
+		return response.BILLING_INVALID_PRODUCT
+	}
+
+	if errors.Is(err, billingservice.ErrPaymentProvider) {
+		return response.BILLING_PROVIDER_ERROR
+	}
+
+	if errors.Is(err, billingservice.ErrInvalidSignature) || errors.Is(err, billingservice.ErrInvalidWebhookReq) {
+		return response.BILLING_INVALID_WEBHOOK
+	}
+
+	if errors.Is(err, billingservice.ErrInvoiceNotFound) {
+		return response.BILLING_INVOICE_NOT_FOUND
+	}
+
+	if errors.Is(err, billingservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+
+	if errors.Is(err, billingservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	return mapQuotaServiceErrorToMsgCode(err)
+}
+
+func handleBillingResponse(gCtx *gin.Context, rsp interface{}, err error, emptyResp interface{}) {
+	r := response.NewResponse(gCtx)
+	if err != nil {
+		msgCode := mapBillingServiceErrorToMsgCode(err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    emptyResp,
+		})
+		return
+	}
+	r.Success(rsp)
+}
+
+// CreateCheckout
+//
+//	@Description:[POST] /api/biz/v1/user/billing/checkout
+//	@return gin.HandlerFunc
+func CreateCheckout() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.CreateCheckoutReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.CreateCheckoutResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateCheckout(ctx, req)
+		handleBillingResponse(gCtx, rsp, err, def.CreateCheckoutResp{Success: false})
+	}
+}
+
+// ListInvoices
+//
+//	@Description:[GET] /api/biz/v1/user/billing/invoices
+//	@return gin.HandlerFunc
+func ListInvoices() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListInvoices(ctx)
+		handleBillingResponse(gCtx, rsp, err, def.ListInvoicesResp{Success: false})
+	}
+}
+
+// PaymentWebhook
+//
+//	@Description:[POST] /api/biz/v1/user/billing/webhook
+//	支付渠道依赖HTTP状态码判断是否重试，因此这里失败时不返回200
+//	@return gin.HandlerFunc
+func PaymentWebhook() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		payload, err := io.ReadAll(io.LimitReader(gCtx.Request.Body, maxWebhookBodySize))
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to read webhook body: %v", err)
+			gCtx.JSON(http.StatusBadRequest, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+			})
+			return
+		}
+
+		req := &def.PaymentWebhookReq{
+			Payload:   payload,
+			Signature: gCtx.GetHeader("Stripe-Signature"),
+		}
+
+		rsp, err := handler.GetHandler().PaymentWebhook(ctx, req)
+		if err != nil {
+			msgCode := mapBillingServiceErrorToMsgCode(err)
+			statusCode := http.StatusInternalServerError
+			if msgCode == response.BILLING_INVALID_WEBHOOK || msgCode == response.BILLING_DISABLED {
+				statusCode = http.StatusBadRequest
+			}
+			gCtx.JSON(statusCode, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+			})
+			return
+		}
+		gCtx.JSON(http.StatusOK, rsp)
+	}
+}
@@ -3,13 +3,16 @@ package router
 import (
 	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
 
 	"forge/biz/cosservice"
+	"forge/constant"
 	"forge/interface/def"
 	"forge/interface/handler"
 	"forge/pkg/log/zlog"
+	"forge/pkg/loop"
 	"forge/pkg/response"
 )
 
@@ -36,12 +39,36 @@ func mapCOSServiceErrorToMsgCode(err error) response.MsgCode {
 		return response.COS_INVALID_DURATION
 	}
 
+	if errors.Is(err, cosservice.ErrFileNotFound) {
+		return response.COS_FILE_NOT_FOUND
+	}
+
+	if errors.Is(err, cosservice.ErrDownloadLinkInvalid) {
+		return response.COS_DOWNLOAD_LINK_INVALID
+	}
+
+	if errors.Is(err, cosservice.ErrCredentialsNotSupported) {
+		return response.COS_STS_NOT_SUPPORTED
+	}
+
+	if errors.Is(err, cosservice.ErrInvalidPurpose) {
+		return response.COS_INVALID_PURPOSE
+	}
+
+	if errors.Is(err, cosservice.ErrFileInfected) {
+		return response.COS_FILE_INFECTED
+	}
+
+	if errors.Is(err, cosservice.ErrFileScanFailed) {
+		return response.COS_FILE_SCAN_FAILED
+	}
+
 	if errors.Is(err, cosservice.ErrInternalError) {
 		return response.COS_GET_CREDENTIALS_FAILED
 	}
 
-	// 默认返回通用错误
-	return response.COMMON_FAIL
+	// 套餐配额错误，其余返回通用错误
+	return mapQuotaServiceErrorToMsgCode(err)
 }
 
 // GetOSSCredentials
@@ -63,10 +90,9 @@ func GetOSSCredentials() gin.HandlerFunc {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "get_oss_credentials", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "get_oss_credentials", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().GetOSSCredentials(ctx, req)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 		zlog.CtxAllInOne(ctx, "get_oss_credentials", req, rsp, err)
 
 		r := response.NewResponse(gCtx)
@@ -83,3 +109,132 @@ func GetOSSCredentials() gin.HandlerFunc {
 		}
 	}
 }
+
+// PresignDownload
+//
+//	@Description:[GET] /api/biz/v1/cos/presign_download?resource_path=&expire_seconds=
+//	@return gin.HandlerFunc
+func PresignDownload() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.PresignDownloadReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.PresignDownloadResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().PresignDownload(ctx, req)
+		if err != nil {
+			msgCode := mapCOSServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.PresignDownloadResp{},
+			})
+			return
+		}
+		response.NewResponse(gCtx).Success(rsp)
+	}
+}
+
+// ProxyDownload
+//
+//	@Description:[GET] /api/biz/v1/cos/download?token=
+//	@return gin.HandlerFunc
+func ProxyDownload() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ProxyDownloadReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    nil,
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ProxyDownload(ctx, req)
+		if err != nil {
+			msgCode := mapCOSServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    nil,
+			})
+			return
+		}
+		defer rsp.Body.Close()
+
+		contentType := rsp.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		// 边读边写，大文件不占用服务端内存；文件名可能包含中文，按 RFC 5987 编码
+		gCtx.DataFromReader(http.StatusOK, rsp.ContentLength, contentType, rsp.Body, map[string]string{
+			"Content-Disposition": "attachment; filename*=UTF-8''" + url.PathEscape(rsp.FileName),
+			"Cache-Control":       "private, no-store",
+		})
+	}
+}
+
+// ListFiles
+//
+//	@Description:[GET] /api/biz/v1/cos/files?purpose=&page=&page_size=
+//	@return gin.HandlerFunc
+func ListFiles() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListFilesReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListFilesResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListFiles(ctx, req)
+		if err != nil {
+			msgCode := mapCOSServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListFilesResp{Success: false},
+			})
+			return
+		}
+		response.NewResponse(gCtx).Success(rsp)
+	}
+}
+
+// DeleteFile
+//
+//	@Description:[DELETE] /api/biz/v1/cos/files/:id
+//	@return gin.HandlerFunc
+func DeleteFile() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.DeleteFileReq{FileID: gCtx.Param("id")}
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().DeleteFile(ctx, req)
+		if err != nil {
+			msgCode := mapCOSServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteFileResp{Success: false},
+			})
+			return
+		}
+		response.NewResponse(gCtx).Success(rsp)
+	}
+}
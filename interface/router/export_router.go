@@ -0,0 +1,61 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/exportservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/response"
+)
+
+// mapExportServiceErrorToMsgCode 数据导出相关错误映射
+func mapExportServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, exportservice.ErrExportDisabled) {
+		return response.DATA_EXPORT_DISABLED
+	}
+
+	if errors.Is(err, exportservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+
+	if errors.Is(err, exportservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	return response.COMMON_FAIL
+}
+
+func handleExportResponse(gCtx *gin.Context, rsp interface{}, err error, emptyResp interface{}) {
+	r := response.NewResponse(gCtx)
+	if err != nil {
+		msgCode := mapExportServiceErrorToMsgCode(err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    emptyResp,
+		})
+		return
+	}
+	r.Success(rsp)
+}
+
+// ExportData
+//
+//	@Description:[GET] /api/biz/v1/user/export
+//	@return gin.HandlerFunc
+func ExportData() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ExportData(ctx)
+		handleExportResponse(gCtx, rsp, err, def.ExportDataResp{Success: false})
+	}
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/entity"
+	"forge/biz/feedbackservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+)
+
+// mapFeedbackServiceErrorToMsgCode 消息评价相关错误映射
+func mapFeedbackServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, feedbackservice.ErrMessageNotFound) {
+		return response.MESSAGE_NOT_EXIST
+	}
+
+	if errors.Is(err, feedbackservice.ErrMessageNotRateable) {
+		return response.MESSAGE_NOT_RATEABLE
+	}
+
+	if errors.Is(err, feedbackservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+
+	if errors.Is(err, feedbackservice.ErrInvalidExportPeriod) {
+		return response.MsgCode{Code: response.INVALID_PARAMS.Code, Msg: err.Error()}
+	}
+
+	if errors.Is(err, feedbackservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	// 字段校验失败时返回具体原因
+	if errors.Is(err, entity.ErrInvalidFeedbackRating) || errors.Is(err, entity.ErrInvalidFeedbackComment) {
+		return response.MsgCode{Code: response.MESSAGE_FEEDBACK_INVALID.Code, Msg: err.Error()}
+	}
+
+	// 会话不存在等错误
+	msgCode := aiChatServiceErrorToMsgCode(err)
+	if msgCode == response.COMMON_FAIL {
+		msgCode.Msg = err.Error()
+	}
+	return msgCode
+}
+
+func handleFeedbackResponse(gCtx *gin.Context, rsp interface{}, err error, emptyResp interface{}) {
+	r := response.NewResponse(gCtx)
+	if err != nil {
+		msgCode := mapFeedbackServiceErrorToMsgCode(err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    emptyResp,
+		})
+		return
+	}
+	r.Success(rsp)
+}
+
+// SubmitMessageFeedback
+//
+//	@Description:[POST] /api/biz/v1/aichat/message_feedback
+//	@return gin.HandlerFunc
+func SubmitMessageFeedback() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.SubmitMessageFeedbackRequest{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_COMPLETE.Code,
+				Message: response.PARAM_NOT_COMPLETE.Msg,
+				Data:    def.SubmitMessageFeedbackResponse{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().SubmitMessageFeedback(ctx, req)
+		zlog.CtxAllInOne(ctx, "submit_message_feedback", map[string]interface{}{"req": req}, rsp, err)
+
+		handleFeedbackResponse(gCtx, rsp, err, def.SubmitMessageFeedbackResponse{Success: false})
+	}
+}
+
+// AdminExportMessageFeedback
+//
+//	@Description:[GET] /api/biz/v1/admin/message_feedback/export
+//	@return gin.HandlerFunc
+func AdminExportMessageFeedback() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminExportMessageFeedbackReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.AdminExportMessageFeedbackResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminExportMessageFeedback(ctx, req)
+		handleFeedbackResponse(gCtx, rsp, err, def.AdminExportMessageFeedbackResp{Success: false})
+	}
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout 单次就绪检查的总超时时间
+const readinessTimeout = 3 * time.Second
+
+// readinessChecks 就绪检查依赖的组件，名称到检查函数
+var readinessChecks map[string]func(context.Context) error
+
+// InitReadiness 初始化就绪检查依赖的组件，需在 RunServer 之前调用
+func InitReadiness(checks map[string]func(context.Context) error) {
+	readinessChecks = checks
+}
+
+// Readyz
+//
+//	@Description:[GET] /readyz
+//	所有组件可用时返回 200，任一组件不可用时返回 503，Data 中为各组件的状态（ok/unavailable）
+//	具体错误只写日志，不在响应中暴露内部地址
+//	@return gin.HandlerFunc
+func Readyz() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx, cancel := context.WithTimeout(gCtx.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		names := make([]string, 0, len(readinessChecks))
+		for name := range readinessChecks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		ready := true
+		status := make(map[string]string, len(names))
+		for _, name := range names {
+			if err := readinessChecks[name](ctx); err != nil {
+				zlog.Warnf("readiness check %s failed: %v", name, err)
+				status[name] = "unavailable"
+				ready = false
+				continue
+			}
+			status[name] = "ok"
+		}
+
+		if !ready {
+			gCtx.JSON(http.StatusServiceUnavailable, response.JsonMsgResult{
+				Code:    response.COMMON_FAIL.Code,
+				Message: response.COMMON_FAIL.Msg,
+				Data:    status,
+			})
+			return
+		}
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    response.SUCCESS.Code,
+			Message: response.SUCCESS.Msg,
+			Data:    status,
+		})
+	}
+}
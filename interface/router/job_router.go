@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"forge/infra/jobs"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListJobs
+//
+//	@Description:[GET] /api/biz/v1/admin/job/list
+//	定时任务的执行状态与延迟任务队列的积压情况，直接读取调度器，不经过 handler
+//	@return gin.HandlerFunc
+func AdminListJobs() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		statuses, err := jobs.ListStatus(ctx)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "list job status failed: %v", err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.COMMON_FAIL.Code,
+				Message: response.COMMON_FAIL.Msg,
+				Data:    def.AdminListJobsResp{Success: false},
+			})
+			return
+		}
+		rsp := def.AdminListJobsResp{Jobs: make([]*def.AdminJob, 0, len(statuses)), Success: true}
+		for _, s := range statuses {
+			rsp.Jobs = append(rsp.Jobs, &def.AdminJob{
+				Name:           s.Name,
+				Schedule:       s.Schedule,
+				Exclusive:      s.Exclusive,
+				Running:        s.Running,
+				NextRunAt:      timeOrNil(s.NextRunAt),
+				LastRunAt:      timeOrNil(s.LastRunAt),
+				LastDurationMs: s.LastDuration.Milliseconds(),
+				LastError:      s.LastError,
+				LastAttempts:   s.LastAttempts,
+				LastInstance:   s.LastInstance,
+				Runs:           s.Runs,
+				Failures:       s.Failures,
+			})
+		}
+
+		// 队列统计失败不影响查看定时任务
+		if stats, err := jobs.TaskQueueStats(ctx); err != nil {
+			zlog.CtxWarnf(ctx, "get task queue stats failed: %v", err)
+		} else {
+			rsp.Tasks = &def.AdminTaskQueue{Pending: stats.Pending, Processing: stats.Processing, Dead: stats.Dead}
+		}
+
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    response.SUCCESS.Code,
+			Message: response.SUCCESS.Msg,
+			Data:    rsp,
+		})
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -3,15 +3,19 @@ package router
 import (
 	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
+	"forge/biz/entity"
 	"forge/biz/mindmapservice"
-	// "forge/constant"
+	"forge/constant"
 	"forge/interface/def"
 	"forge/interface/handler"
 	"forge/pkg/log/zlog"
-	// "forge/pkg/loop"
+	"forge/pkg/loop"
+	"forge/pkg/mindmapconv"
 	"forge/pkg/response"
 )
 
@@ -38,12 +42,160 @@ func mapMindMapServiceErrorToMsgCode(err error) response.MsgCode {
 		return response.MINDMAP_PERMISSION_DENIED
 	}
 
+	if errors.Is(err, mindmapservice.ErrEmptyOutline) {
+		return response.MINDMAP_OUTLINE_EMPTY
+	}
+
+	if errors.Is(err, mindmapservice.ErrOutlineTooLarge) {
+		return response.MINDMAP_OUTLINE_TOO_LARGE
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareNotFound) {
+		return response.MINDMAP_SHARE_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareExpired) {
+		return response.MINDMAP_SHARE_EXPIRED
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareLimitExceeded) {
+		return response.MINDMAP_SHARE_LIMIT_EXCEEDED
+	}
+
+	if errors.Is(err, mindmapservice.ErrInvalidShareExpire) || errors.Is(err, entity.ErrInvalidSharePassword) {
+		return response.MINDMAP_SHARE_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrSharePasswordNeeded) {
+		return response.MINDMAP_SHARE_PASSWORD_REQUIRED
+	}
+
+	if errors.Is(err, mindmapservice.ErrSharePasswordWrong) {
+		return response.MINDMAP_SHARE_PASSWORD_WRONG
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareTooManyAttempts) {
+		return response.MINDMAP_SHARE_TOO_MANY_ATTEMPTS
+	}
+
+	if errors.Is(err, mindmapservice.ErrMemberNotFound) {
+		return response.MINDMAP_MEMBER_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrMemberUserNotFound) {
+		return response.MINDMAP_MEMBER_USER_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrMemberLimitExceeded) {
+		return response.MINDMAP_MEMBER_LIMIT_EXCEEDED
+	}
+
+	if errors.Is(err, entity.ErrInvalidMemberRole) {
+		return response.MINDMAP_MEMBER_ROLE_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrCannotAddSelf) {
+		return response.MINDMAP_MEMBER_SELF
+	}
+
+	if errors.Is(err, mindmapconv.ErrUnsupportedFormat) {
+		return response.MINDMAP_EXPORT_UNSUPPORTED
+	}
+
+	if errors.Is(err, mindmapconv.ErrPNGUnavailable) {
+		return response.MINDMAP_EXPORT_PNG_UNAVAILABLE
+	}
+
+	if errors.Is(err, mindmapconv.ErrImageTooLarge) {
+		return response.MINDMAP_EXPORT_TOO_LARGE
+	}
+
+	if errors.Is(err, mindmapconv.ErrUnsupportedImport) {
+		return response.MINDMAP_IMPORT_UNSUPPORTED
+	}
+
+	if errors.Is(err, mindmapconv.ErrInvalidFile) || errors.Is(err, mindmapconv.ErrEmptyMap) {
+		return response.MINDMAP_IMPORT_INVALID_FILE
+	}
+
+	if errors.Is(err, mindmapservice.ErrImportFileTooLarge) || errors.Is(err, mindmapconv.ErrTooManyNodes) {
+		return response.MINDMAP_IMPORT_TOO_LARGE
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderNotFound) {
+		return response.MINDMAP_FOLDER_NOT_FOUND
+	}
+
+	if errors.Is(err, entity.ErrInvalidFolderName) {
+		return response.MINDMAP_FOLDER_NAME_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderLimitExceeded) {
+		return response.MINDMAP_FOLDER_LIMIT_EXCEEDED
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderTooDeep) {
+		return response.MINDMAP_FOLDER_TOO_DEEP
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderCycle) {
+		return response.MINDMAP_FOLDER_CYCLE
+	}
+
+	if errors.Is(err, mindmapservice.ErrTagNotFound) {
+		return response.MINDMAP_TAG_NOT_FOUND
+	}
+
+	if errors.Is(err, entity.ErrInvalidTagName) {
+		return response.MINDMAP_TAG_NAME_INVALID
+	}
+
+	if errors.Is(err, entity.ErrTooManyTags) {
+		return response.MINDMAP_TAG_TOO_MANY
+	}
+
+	if errors.Is(err, mindmapservice.ErrTagLimitExceeded) {
+		return response.MINDMAP_TAG_LIMIT_EXCEEDED
+	}
+
+	if errors.Is(err, entity.ErrInvalidSearchQuery) {
+		return response.MINDMAP_SEARCH_QUERY_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrInvalidListCursor) {
+		return response.MINDMAP_LIST_CURSOR_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrTemplateNotFound) {
+		return response.MINDMAP_TEMPLATE_NOT_FOUND
+	}
+
+	if errors.Is(err, entity.ErrInvalidTemplateCategory) {
+		return response.MINDMAP_TEMPLATE_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrCommentNotFound) {
+		return response.MINDMAP_COMMENT_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrCommentNodeNotFound) {
+		return response.MINDMAP_COMMENT_NODE_NOT_FOUND
+	}
+
+	if errors.Is(err, entity.ErrInvalidCommentContent) {
+		return response.MINDMAP_COMMENT_INVALID
+	}
+
+	if errors.Is(err, mindmapservice.ErrCommentLimitExceeded) {
+		return response.MINDMAP_COMMENT_LIMIT_EXCEEDED
+	}
+
 	if errors.Is(err, mindmapservice.ErrInternalError) {
 		return response.INTERNAL_ERROR
 	}
 
-	// 默认返回通用错误
-	return response.COMMON_FAIL
+	// 套餐配额错误，其余返回通用错误
+	return mapQuotaServiceErrorToMsgCode(err)
 }
 
 // CreateMindMap
@@ -56,20 +208,46 @@ func CreateMindMap() gin.HandlerFunc {
 		ctx := gCtx.Request.Context()
 
 		// 绑定JSON请求体
-		if err := gCtx.ShouldBindJSON(req); err != nil {
+		if !bindAndValidate(gCtx, binding.JSON, req, def.CreateMindMapResp{}) {
+			return
+		}
+
+		ctx, sp := loop.GetNewSpan(ctx, "create_mindmap", constant.LoopSpanType_Root)
+		rsp, err := handler.GetHandler().CreateMindMap(ctx, req)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		zlog.CtxAllInOne(ctx, "create_mindmap", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
 			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
 				Data:    def.CreateMindMapResp{},
 			})
 			return
+		} else {
+			r.Success(rsp)
 		}
+	}
+}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "create_mindmap", constant.LoopSpanType_Root)
-		rsp, err := handler.GetHandler().CreateMindMap(ctx, req)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
-		zlog.CtxAllInOne(ctx, "create_mindmap", req, rsp, err)
+// CreateMindMapFromText
+//
+//	@Description:[POST] /api/biz/v1/mindmap/from_text
+//	@return gin.HandlerFunc
+func CreateMindMapFromText() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.CreateMindMapFromTextReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.CreateMindMapResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapFromText(ctx, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_from_text", req, rsp, err)
 
 		r := response.NewResponse(gCtx)
 		if err != nil {
@@ -86,6 +264,48 @@ func CreateMindMap() gin.HandlerFunc {
 	}
 }
 
+// ImportMindMap
+//
+//	@Description:[POST] /api/biz/v1/mindmap/import
+//	@return gin.HandlerFunc
+func ImportMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ImportMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 请求体超出文件上限（另留 1MB 给其他表单字段）时直接拒绝，不读入内存
+		gCtx.Request.Body = http.MaxBytesReader(gCtx.Writer, gCtx.Request.Body, mindmapservice.MaxImportFileSize+1<<20)
+		if err := gCtx.ShouldBind(req); err != nil {
+			msgCode := response.INVALID_PARAMS
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				msgCode = response.MINDMAP_IMPORT_TOO_LARGE
+			}
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ImportMindMap(ctx, req)
+		zlog.CtxAllInOne(ctx, "import_mindmap", map[string]interface{}{"title": req.Title, "filename": req.File.Filename}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
 // GetMindMap
 //
 //	@Description:[GET] /api/biz/v1/mindmap/:id
@@ -105,10 +325,9 @@ func GetMindMap() gin.HandlerFunc {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "get_mindmap", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "get_mindmap", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().GetMindMap(ctx, mapID)
-		// loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
 		zlog.CtxAllInOne(ctx, "get_mindmap", mapID, rsp, err)
 
 		r := response.NewResponse(gCtx)
@@ -121,7 +340,7 @@ func GetMindMap() gin.HandlerFunc {
 			})
 			return
 		} else {
-			r.Success(rsp)
+			r.SuccessWithETag(rsp)
 		}
 	}
 }
@@ -136,19 +355,13 @@ func ListMindMaps() gin.HandlerFunc {
 		ctx := gCtx.Request.Context()
 
 		// 绑定查询参数
-		if err := gCtx.ShouldBindQuery(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.ListMindMapsResp{},
-			})
+		if !bindAndValidate(gCtx, binding.Query, req, def.ListMindMapsResp{}) {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "list_mindmaps", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "list_mindmaps", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().ListMindMaps(ctx, req)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 		zlog.CtxAllInOne(ctx, "list_mindmaps", req, rsp, err)
 
 		r := response.NewResponse(gCtx)
@@ -187,19 +400,13 @@ func UpdateMindMap() gin.HandlerFunc {
 		}
 
 		// 绑定JSON请求体
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.UpdateMindMapResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UpdateMindMapResp{Success: false}) {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "update_mindmap", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "update_mindmap", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().UpdateMindMap(ctx, mapID, req)
-		// loop.SetSpanAllInOne(ctx, sp, map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
 		zlog.CtxAllInOne(ctx, "update_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
 
 		r := response.NewResponse(gCtx)
@@ -236,10 +443,9 @@ func DeleteMindMap() gin.HandlerFunc {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "delete_mindmap", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "delete_mindmap", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().DeleteMindMap(ctx, mapID)
-		// loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, mapID, rsp, err)
 		zlog.CtxAllInOne(ctx, "delete_mindmap", mapID, rsp, err)
 
 		r := response.NewResponse(gCtx)
@@ -256,3 +462,873 @@ func DeleteMindMap() gin.HandlerFunc {
 		}
 	}
 }
+
+// ShareMindMap
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/share
+//	@return gin.HandlerFunc
+func ShareMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ShareMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验，请求体可以为空
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ShareMindMapResp{},
+			})
+			return
+		}
+		if gCtx.Request.ContentLength != 0 {
+			if !bindAndValidate(gCtx, binding.JSON, req, def.ShareMindMapResp{}) {
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().ShareMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "share_mindmap", map[string]interface{}{"mapID": mapID, "expireHours": req.ExpireHours}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ShareMindMapResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapShares
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/shares
+//	@return gin.HandlerFunc
+func ListMindMapShares() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapShares(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "list_mindmap_shares", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapSharesResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RevokeMindMapShare
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/share/:share_id
+//	@return gin.HandlerFunc
+func RevokeMindMapShare() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		shareID := gCtx.Param("share_id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().RevokeMindMapShare(ctx, mapID, shareID)
+		zlog.CtxAllInOne(ctx, "revoke_mindmap_share", map[string]interface{}{"mapID": mapID, "shareID": shareID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RevokeMindMapShareResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// GetSharedMindMap
+//
+//	@Description:[GET] /api/biz/v1/share/mindmap?token=
+//	@return gin.HandlerFunc
+func GetSharedMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.GetSharedMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.GetSharedMindMapResp{}) {
+			return
+		}
+		req.Password = gCtx.GetHeader("X-Share-Password")
+
+		rsp, err := handler.GetHandler().GetSharedMindMap(ctx, req)
+		// 令牌与密码不写入日志
+		zlog.CtxAllInOne(ctx, "get_shared_mindmap", nil, nil, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetSharedMindMapResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// AddMindMapMember
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/members
+//	@return gin.HandlerFunc
+func AddMindMapMember() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.AddMindMapMemberReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.AddMindMapMemberResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().AddMindMapMember(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "add_mindmap_member", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.AddMindMapMemberResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapMembers
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/members
+//	@return gin.HandlerFunc
+func ListMindMapMembers() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapMembers(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "list_mindmap_members", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapMembersResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapActivities
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/activity
+//	@return gin.HandlerFunc
+func ListMindMapActivities() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ListMindMapActivitiesReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.ListMindMapActivitiesResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapActivities(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_activities", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapActivitiesResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// CreateMindMapComment
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/comments
+//	@return gin.HandlerFunc
+func CreateMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.CreateMindMapCommentReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.MindMapCommentResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapComment(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_comment", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MindMapCommentResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapComments
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/comments
+//	@return gin.HandlerFunc
+func ListMindMapComments() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ListMindMapCommentsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.ListMindMapCommentsResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapComments(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_comments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapCommentsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ResolveMindMapComment resolved 为 false 时重新打开评论
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+//	@return gin.HandlerFunc
+func ResolveMindMapComment(resolved bool) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		commentID := gCtx.Param("comment_id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ResolveMindMapComment(ctx, mapID, commentID, resolved)
+		zlog.CtxAllInOne(ctx, "resolve_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "resolved": resolved}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MindMapCommentResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// DeleteMindMapComment
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id
+//	@return gin.HandlerFunc
+func DeleteMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		commentID := gCtx.Param("comment_id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().DeleteMindMapComment(ctx, mapID, commentID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapCommentResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// StarMindMap
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/star
+//	@return gin.HandlerFunc
+func StarMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().StarMindMap(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "star_mindmap", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.StarMindMapResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// UnstarMindMap
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/star
+//	@return gin.HandlerFunc
+func UnstarMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().UnstarMindMap(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "unstar_mindmap", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.StarMindMapResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RemoveMindMapMember
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/members/:user_id
+//	@return gin.HandlerFunc
+func RemoveMindMapMember() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		userID := gCtx.Param("user_id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().RemoveMindMapMember(ctx, mapID, userID)
+		zlog.CtxAllInOne(ctx, "remove_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RemoveMindMapMemberResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ExportMindMap
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/export?format=&delivery=
+//	@return gin.HandlerFunc
+func ExportMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ExportMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.ExportMindMapResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().ExportMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "export_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, nil, err)
+
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+		if rsp.URL != "" {
+			response.NewResponse(gCtx).Success(rsp)
+			return
+		}
+
+		// 直接下载，文件名可能包含中文，按 RFC 5987 编码
+		gCtx.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(rsp.FileName))
+		gCtx.Data(http.StatusOK, rsp.ContentType, rsp.Data)
+	}
+}
+
+// MoveMindMap
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/folder
+//	@return gin.HandlerFunc
+func MoveMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.MoveMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.MoveMindMapResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().MoveMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "move_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MoveMindMapResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// CreateMindMapFolder
+//
+//	@Description:[POST] /api/biz/v1/mindmap/folders
+//	@return gin.HandlerFunc
+func CreateMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.CreateMindMapFolderReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.CreateMindMapFolderResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapFolder(ctx, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_folder", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapFolderResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapFolders
+//
+//	@Description:[GET] /api/biz/v1/mindmap/folders
+//	@return gin.HandlerFunc
+func ListMindMapFolders() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapFolders(ctx)
+		zlog.CtxAllInOne(ctx, "list_mindmap_folders", nil, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapFoldersResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// UpdateMindMapFolder
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/folders/:folder_id
+//	@return gin.HandlerFunc
+func UpdateMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		folderID := gCtx.Param("folder_id")
+		req := &def.UpdateMindMapFolderReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UpdateMindMapFolderResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateMindMapFolder(ctx, folderID, req)
+		zlog.CtxAllInOne(ctx, "update_mindmap_folder", map[string]interface{}{"folderID": folderID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UpdateMindMapFolderResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// DeleteMindMapFolder
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/folders/:folder_id
+//	@return gin.HandlerFunc
+func DeleteMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		folderID := gCtx.Param("folder_id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().DeleteMindMapFolder(ctx, folderID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_folder", folderID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapFolderResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// SetMindMapTags
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/tags
+//	@return gin.HandlerFunc
+func SetMindMapTags() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.SetMindMapTagsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.SetMindMapTagsResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().SetMindMapTags(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "set_mindmap_tags", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SetMindMapTagsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RemoveMindMapTag
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/tags/:tag
+//	@return gin.HandlerFunc
+func RemoveMindMapTag() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		tag := gCtx.Param("tag")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().RemoveMindMapTag(ctx, mapID, tag)
+		zlog.CtxAllInOne(ctx, "remove_mindmap_tag", map[string]interface{}{"mapID": mapID, "tag": tag}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RemoveMindMapTagResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapTags
+//
+//	@Description:[GET] /api/biz/v1/mindmap/tags
+//	@return gin.HandlerFunc
+func ListMindMapTags() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapTags(ctx)
+		zlog.CtxAllInOne(ctx, "list_mindmap_tags", nil, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapTagsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// SearchMindMaps
+//
+//	@Description:[GET] /api/biz/v1/mindmap/search?q=
+//	@return gin.HandlerFunc
+func SearchMindMaps() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.SearchMindMapsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.SearchMindMapsResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().SearchMindMaps(ctx, req)
+		zlog.CtxAllInOne(ctx, "search_mindmaps", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SearchMindMapsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// CreateMindMapTemplate
+//
+//	@Description:[POST] /api/biz/v1/admin/mindmap_template
+//	@return gin.HandlerFunc
+func CreateMindMapTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.MindMapTemplateReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.MindMapTemplateResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapTemplate(ctx, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_template", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MindMapTemplateResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// UpdateMindMapTemplate
+//
+//	@Description:[PUT] /api/biz/v1/admin/mindmap_template/:id
+//	@return gin.HandlerFunc
+func UpdateMindMapTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("id")
+		req := &def.MindMapTemplateReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if !bindAndValidate(gCtx, binding.JSON, req, def.MindMapTemplateResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateMindMapTemplate(ctx, templateID, req)
+		zlog.CtxAllInOne(ctx, "update_mindmap_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MindMapTemplateResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// DeleteMindMapTemplate
+//
+//	@Description:[DELETE] /api/biz/v1/admin/mindmap_template/:id
+//	@return gin.HandlerFunc
+func DeleteMindMapTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().DeleteMindMapTemplate(ctx, templateID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_template", templateID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapTemplateResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapTemplates
+//
+//	@Description:[GET] /api/biz/v1/mindmap/templates?category=
+//	@return gin.HandlerFunc
+func ListMindMapTemplates() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListMindMapTemplatesReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if !bindAndValidate(gCtx, binding.Query, req, def.ListMindMapTemplatesResp{}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapTemplates(ctx, req)
+		// 模板包含完整导图数据，不记录响应
+		zlog.CtxAllInOne(ctx, "list_mindmap_templates", req, nil, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapTemplatesResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// CreateMindMapFromTemplate
+//
+//	@Description:[POST] /api/biz/v1/mindmap/from_template/:template_id
+//	@return gin.HandlerFunc
+func CreateMindMapFromTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("template_id")
+		req := &def.CreateMindMapFromTemplateReq{}
+		ctx := gCtx.Request.Context()
+
+		// 请求体可以为空，此时使用模板标题且不放入文件夹
+		if gCtx.Request.ContentLength != 0 {
+			if !bindAndValidate(gCtx, binding.JSON, req, def.CreateMindMapResp{}) {
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapFromTemplate(ctx, templateID, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_from_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
@@ -0,0 +1,310 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"forge/infra/configs"
+	"forge/interface/def"
+	"forge/pkg/log/zlog"
+	"forge/pkg/openapi"
+	"forge/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiDoc 接口文档条目，Path 相对 /api/biz/v1，与注册路由时的写法一致
+type apiDoc struct {
+	Method  string
+	Path    string
+	Summary string
+	// Req 请求参数：GET 按 form 标签作为查询参数；其他方法有文件字段时为 multipart 表单，否则按 json 标签作为请求体
+	Req any
+	// Resp 统一响应结构中 Data 的类型
+	Resp any
+	// Public 不需要登录
+	Public bool
+	// Produces 响应不是统一 JSON 结构时的 Content-Type，如文件下载、SSE
+	Produces string
+	// Hidden 不面向前端的接口（支付回调、运行指标），不出现在文档中
+	Hidden bool
+}
+
+// avatarUploadForm 上传头像的表单，接口直接读取表单文件，仅用于生成文档
+type avatarUploadForm struct {
+	Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+}
+
+// 文档中各路由组的说明，按路径的第一段分组
+var apiTags = map[string]string{
+	"user":    "用户、登录与账号",
+	"mindmap": "思维导图",
+	"share":   "分享链接",
+	"cos":     "文件",
+	"aichat":  "AI对话",
+	"admin":   "管理接口，需要管理员角色",
+}
+
+// apiDocs 新增或修改接口时同步维护，请求/响应的字段由 def 中的类型生成
+var apiDocs = []apiDoc{
+	{Method: GET, Path: "/user/captcha", Summary: "获取图形验证码", Resp: def.GetCaptchaResp{}, Public: true},
+	{Method: POST, Path: "/user/login", Summary: "账号密码登录，同一来源请求过多时需要先完成图形验证码", Req: def.LoginReq{}, Resp: def.LoginResp{}, Public: true},
+	{Method: POST, Path: "/user/refresh_token", Summary: "刷新令牌，访问令牌过期后调用", Req: def.RefreshTokenReq{}, Resp: def.RefreshTokenResp{}, Public: true},
+	{Method: POST, Path: "/user/register", Summary: "注册", Req: def.RegisterReq{}, Resp: def.RegisterResp{}, Public: true},
+	{Method: POST, Path: "/user/send_code", Summary: "发送验证码", Req: def.SendVerificationCodeReq{}, Resp: def.SendVerificationCodeResp{}, Public: true},
+	{Method: POST, Path: "/user/reset_password", Summary: "重置密码", Req: def.ResetPasswordReq{}, Resp: def.ResetPasswordResp{}, Public: true},
+	{Method: GET, Path: "/user/version", Summary: "回显版本", Req: def.GetVersionReq{}, Resp: def.GetVersionResp{}, Public: true},
+	{Method: POST, Path: "/user/billing/webhook", Summary: "支付渠道回调（通过签名校验，不需要JWT）", Req: def.PaymentWebhookReq{}, Public: true, Hidden: true},
+	{Method: GET, Path: "/user/unsubscribe", Summary: "提醒邮件中的退订链接（通过签名校验，不需要JWT）", Req: def.UnsubscribeReminderReq{}, Resp: def.UnsubscribeReminderResp{}, Public: true},
+	{Method: POST, Path: "/user/login/magic_link", Summary: "免密登录，向已注册的邮箱发送一次性登录链接", Req: def.SendMagicLinkReq{}, Resp: def.SendMagicLinkResp{}, Public: true},
+	{Method: GET, Path: "/user/login/magic_link/verify", Summary: "邮件中的登录链接，链接只能使用一次", Req: def.MagicLinkLoginReq{}, Resp: def.LoginResp{}, Public: true},
+	{Method: GET, Path: "/user/oauth/:provider/authorize", Summary: "第三方登录授权地址（provider: wechat/github/google）", Req: def.OAuthAuthorizeReq{}, Resp: def.OAuthAuthorizeResp{}, Public: true},
+	{Method: POST, Path: "/user/oauth/:provider/login", Summary: "第三方登录，未绑定的第三方账号自动注册", Req: def.OAuthLoginReq{}, Resp: def.LoginResp{}, Public: true},
+	{Method: POST, Path: "/user/logout", Summary: "退出登录，cookie 鉴权模式下清除 cookie", Req: def.LogoutReq{}, Resp: def.LogoutResp{}},
+	{Method: GET, Path: "/user/home", Summary: "个人主页", Resp: def.GetHomeResp{}},
+	{Method: POST, Path: "/user/send_code_for_change", Summary: "发送验证码（换绑、注销等需要登录的场景）", Req: def.SendVerificationCodeReq{}, Resp: def.SendVerificationCodeResp{}},
+	{Method: POST, Path: "/user/account", Summary: "更新联系方式（绑定/换绑手机号、邮箱）", Req: def.UpdateAccountReq{}, Resp: def.UpdateAccountResp{}},
+	{Method: DELETE, Path: "/user/contact", Summary: "解绑联系方式（手机号/邮箱）", Req: def.UnbindAccountReq{}, Resp: def.UnbindAccountResp{}},
+	{Method: DELETE, Path: "/user/account", Summary: "注销账号，冷静期内重新登录可撤销", Req: def.DeleteAccountReq{}, Resp: def.DeleteAccountResp{}},
+	{Method: POST, Path: "/user/account/merge", Summary: "合并账号，将另外注册的账号的导图、AI对话合并到当前账号", Req: def.MergeAccountReq{}, Resp: def.MergeAccountResp{}},
+	{Method: PUT, Path: "/user/profile", Summary: "修改个人资料", Req: def.UpdateProfileReq{}, Resp: def.UpdateProfileResp{}},
+	{Method: POST, Path: "/user/avatar", Summary: "上传头像，表单字段 avatar", Req: avatarUploadForm{}, Resp: def.UpdateAvatarResp{}},
+	{Method: GET, Path: "/user/plan", Summary: "查看套餐与用量", Resp: def.GetPlanResp{}},
+	{Method: POST, Path: "/user/notification_preference", Summary: "修改通知偏好", Req: def.UpdateNotificationPreferenceReq{}, Resp: def.UpdateNotificationPreferenceResp{}},
+	{Method: GET, Path: "/user/sessions", Summary: "登录记录", Req: def.ListSessionsReq{}, Resp: def.ListSessionsResp{}},
+	{Method: DELETE, Path: "/user/sessions/:id", Summary: "远程下线登录会话", Req: def.RevokeSessionReq{}, Resp: def.RevokeSessionResp{}},
+	{Method: POST, Path: "/user/invite/codes", Summary: "生成邀请码", Resp: def.CreateInviteCodeResp{}},
+	{Method: GET, Path: "/user/invite/codes", Summary: "我的邀请码", Resp: def.ListInviteCodesResp{}},
+	{Method: GET, Path: "/user/invite/referrals", Summary: "我邀请注册的用户", Resp: def.ListReferralsResp{}},
+	{Method: GET, Path: "/user/security/audit", Summary: "账号安全记录（登录、重置密码、换绑联系方式、修改头像、管理员操作）", Req: def.ListAuditLogsReq{}, Resp: def.ListAuditLogsResp{}},
+	{Method: GET, Path: "/user/export", Summary: "导出个人数据（个人资料、导图、AI对话），后台打包完成后邮件通知下载", Resp: def.ExportDataResp{}},
+	{Method: GET, Path: "/user/oauth/bindings", Summary: "已绑定的第三方账号", Resp: def.ListOAuthBindingsResp{}},
+	{Method: GET, Path: "/user/oauth/:provider/bind_authorize", Summary: "绑定第三方账号的授权地址", Req: def.OAuthAuthorizeReq{}, Resp: def.OAuthAuthorizeResp{}},
+	{Method: POST, Path: "/user/oauth/:provider/bind", Summary: "绑定第三方账号", Req: def.BindOAuthReq{}, Resp: def.BindOAuthResp{}},
+	{Method: DELETE, Path: "/user/oauth/:provider", Summary: "解绑第三方账号", Req: def.UnbindOAuthReq{}, Resp: def.UnbindOAuthResp{}},
+	{Method: POST, Path: "/user/billing/checkout", Summary: "购买套餐，返回支付页面地址", Req: def.CreateCheckoutReq{}, Resp: def.CreateCheckoutResp{}},
+	{Method: GET, Path: "/user/billing/invoices", Summary: "账单列表", Resp: def.ListInvoicesResp{}},
+	{Method: POST, Path: "/mindmap", Summary: "创建思维导图", Req: def.CreateMindMapReq{}, Resp: def.CreateMindMapResp{}},
+	{Method: POST, Path: "/mindmap/from_text", Summary: "根据纯文本大纲创建思维导图（不调用AI）", Req: def.CreateMindMapFromTextReq{}, Resp: def.CreateMindMapResp{}},
+	{Method: POST, Path: "/mindmap/import", Summary: "导入 xmind/mm/opml/md 文件创建思维导图，表单字段 file、layout，可选 title、desc", Req: def.ImportMindMapReq{}, Resp: def.CreateMindMapResp{}},
+	{Method: GET, Path: "/mindmap/:id", Summary: "获取思维导图详情", Resp: def.GetMindMapResp{}},
+	{Method: GET, Path: "/mindmap/list", Summary: "获取思维导图列表，scope=shared 时为与我共享的导图，scope=all 时为两者", Req: def.ListMindMapsReq{}, Resp: def.ListMindMapsResp{}},
+	{Method: PUT, Path: "/mindmap/:id", Summary: "更新思维导图", Req: def.UpdateMindMapReq{}, Resp: def.UpdateMindMapResp{}},
+	{Method: DELETE, Path: "/mindmap/:id", Summary: "删除思维导图", Resp: def.DeleteMindMapResp{}},
+	{Method: POST, Path: "/mindmap/:id/share", Summary: "创建只读分享链接，可设置访问密码与有效期", Req: def.ShareMindMapReq{}, Resp: def.ShareMindMapResp{}},
+	{Method: GET, Path: "/mindmap/:id/shares", Summary: "导图仍有效的分享链接", Resp: def.ListMindMapSharesResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/share/:share_id", Summary: "撤销分享链接", Resp: def.RevokeMindMapShareResp{}},
+	{Method: POST, Path: "/mindmap/:id/members", Summary: "按手机号或邮箱添加协作者（viewer/editor），已是协作者时修改角色", Req: def.AddMindMapMemberReq{}, Resp: def.AddMindMapMemberResp{}},
+	{Method: GET, Path: "/mindmap/:id/members", Summary: "导图的所有者与协作者", Resp: def.ListMindMapMembersResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/members/:user_id", Summary: "移除协作者，协作者移除自己即退出协作", Resp: def.RemoveMindMapMemberResp{}},
+	{Method: GET, Path: "/mindmap/:id/activity", Summary: "导图的变更记录（节点增删改、改名、分享、添加协作者），可只查询某时间之后的记录", Req: def.ListMindMapActivitiesReq{}, Resp: def.ListMindMapActivitiesResp{}},
+	{Method: POST, Path: "/mindmap/:id/comments", Summary: "在节点上评论，所有者与协作者都可以评论", Req: def.CreateMindMapCommentReq{}, Resp: def.MindMapCommentResp{}},
+	{Method: GET, Path: "/mindmap/:id/comments", Summary: "导图的评论，可按节点与是否解决筛选", Req: def.ListMindMapCommentsReq{}, Resp: def.ListMindMapCommentsResp{}},
+	{Method: PUT, Path: "/mindmap/:id/comments/:comment_id/resolve", Summary: "标记评论为已解决，所有者、编辑者与评论者可以操作", Resp: def.MindMapCommentResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/comments/:comment_id/resolve", Summary: "重新打开已解决的评论", Resp: def.MindMapCommentResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/comments/:comment_id", Summary: "删除评论，所有者与评论者可以删除", Resp: def.DeleteMindMapCommentResp{}},
+	{Method: PUT, Path: "/mindmap/:id/star", Summary: "收藏导图，自己的导图与与我共享的导图都可以收藏", Resp: def.StarMindMapResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/star", Summary: "取消收藏", Resp: def.StarMindMapResp{}},
+	{Method: PUT, Path: "/mindmap/:id/folder", Summary: "将自己的导图移到文件夹中，folder_id 为空时移出文件夹", Req: def.MoveMindMapReq{}, Resp: def.MoveMindMapResp{}},
+	{Method: POST, Path: "/mindmap/folders", Summary: "创建文件夹，可以指定上级文件夹", Req: def.CreateMindMapFolderReq{}, Resp: def.CreateMindMapFolderResp{}},
+	{Method: GET, Path: "/mindmap/folders", Summary: "自己的所有文件夹", Resp: def.ListMindMapFoldersResp{}},
+	{Method: PUT, Path: "/mindmap/folders/:folder_id", Summary: "重命名或移动文件夹", Req: def.UpdateMindMapFolderReq{}, Resp: def.UpdateMindMapFolderResp{}},
+	{Method: DELETE, Path: "/mindmap/folders/:folder_id", Summary: "删除文件夹，其中的子文件夹与导图移到上级文件夹", Resp: def.DeleteMindMapFolderResp{}},
+	{Method: PUT, Path: "/mindmap/:id/tags", Summary: "替换自己导图的全部标签", Req: def.SetMindMapTagsReq{}, Resp: def.SetMindMapTagsResp{}},
+	{Method: DELETE, Path: "/mindmap/:id/tags/:tag", Summary: "移除自己导图的一个标签", Resp: def.RemoveMindMapTagResp{}},
+	{Method: GET, Path: "/mindmap/tags", Summary: "自己的所有标签及各标签的导图数", Resp: def.ListMindMapTagsResp{}},
+	{Method: GET, Path: "/mindmap/search", Summary: "在自己的导图与与我共享的导图中搜索标题与节点文本，返回标出关键词的节点摘要", Req: def.SearchMindMapsReq{}, Resp: def.SearchMindMapsResp{}},
+	{Method: GET, Path: "/mindmap/templates", Summary: "模板列表及全部分类，可按分类筛选", Req: def.ListMindMapTemplatesReq{}, Resp: def.ListMindMapTemplatesResp{}},
+	{Method: POST, Path: "/mindmap/from_template/:template_id", Summary: "基于模板创建自己的导图，可指定标题与文件夹", Req: def.CreateMindMapFromTemplateReq{}, Resp: def.CreateMindMapResp{}},
+	{Method: GET, Path: "/mindmap/:id/export", Summary: "导出为 xmind/mm/opml/md/svg/png，默认直接下载文件，delivery=url 时返回临时下载链接", Req: def.ExportMindMapReq{}, Resp: def.ExportMindMapResp{}},
+	{Method: GET, Path: "/share/mindmap", Summary: "通过分享链接查看导图（通过签名校验，不需要JWT），访问密码放在请求头 X-Share-Password", Req: def.GetSharedMindMapReq{}, Resp: def.GetSharedMindMapResp{}, Public: true},
+	{Method: POST, Path: "/cos/sts/credentials", Summary: "获取OSS临时凭证", Req: def.GetOSSCredentialsReq{}, Resp: def.GetOSSCredentialsResp{}},
+	{Method: GET, Path: "/cos/presign_download", Summary: "为自己的文件生成有时效的下载链接，proxy 模式下返回经服务端转发的链接", Req: def.PresignDownloadReq{}, Resp: def.PresignDownloadResp{}},
+	{Method: GET, Path: "/cos/files", Summary: "分页获取自己上传的文件", Req: def.ListFilesReq{}, Resp: def.ListFilesResp{}},
+	{Method: DELETE, Path: "/cos/files/:id", Summary: "删除自己上传的文件，释放占用的存储空间", Req: def.DeleteFileReq{}, Resp: def.DeleteFileResp{}},
+	{Method: GET, Path: "/cos/download", Summary: "通过下载链接中的令牌转发下载文件（通过签名校验，不需要JWT）", Req: def.ProxyDownloadReq{}, Public: true, Produces: "application/octet-stream"},
+	{Method: POST, Path: "/aichat/send_message", Summary: "AI对话", Req: def.ProcessUserMessageRequest{}, Resp: def.ProcessUserMessageResponse{}},
+	{Method: POST, Path: "/aichat/send_message_stream", Summary: "流式AI对话（SSE）", Req: def.ProcessUserMessageRequest{}, Produces: "text/event-stream"},
+	{Method: POST, Path: "/aichat/save_conversation", Summary: "新增会话", Req: def.SaveNewConversationRequest{}, Resp: def.SaveNewConversationResponse{}},
+	{Method: GET, Path: "/aichat/get_conversation_list", Summary: "获取导图的所有会话", Req: def.GetConversationListRequest{}, Resp: def.GetConversationListResponse{}},
+	{Method: POST, Path: "/aichat/del_conversation", Summary: "删除会话", Req: def.DelConversationRequest{}, Resp: def.DelConversationResponse{}},
+	{Method: POST, Path: "/aichat/restore_conversation", Summary: "恢复已删除的会话（删除后7天内）", Req: def.RestoreConversationRequest{}, Resp: def.RestoreConversationResponse{}},
+	{Method: GET, Path: "/aichat/get_conversation", Summary: "获取某个会话的详细信息", Req: def.GetConversationRequest{}, Resp: def.GetConversationResponse{}},
+	{Method: POST, Path: "/aichat/fork_conversation", Summary: "从某条消息分支出新的会话（同一导图下，原会话不变）", Req: def.ForkConversationRequest{}, Resp: def.ForkConversationResponse{}},
+	{Method: POST, Path: "/aichat/update_conversation_title", Summary: "更新某个会话的标题", Req: def.UpdateConversationTitleRequest{}, Resp: def.UpdateConversationTitleResponse{}},
+	{Method: POST, Path: "/aichat/archive_conversation", Summary: "归档或取消归档某个会话", Req: def.ArchiveConversationRequest{}, Resp: def.ArchiveConversationResponse{}},
+	{Method: POST, Path: "/aichat/pin_conversation", Summary: "置顶或取消置顶某个会话，置顶的会话在会话列表最前面", Req: def.PinConversationRequest{}, Resp: def.PinConversationResponse{}},
+	{Method: POST, Path: "/aichat/reorder_conversations", Summary: "按给定顺序调整导图下会话的排列顺序", Req: def.ReorderConversationsRequest{}, Resp: def.ReorderConversationsResponse{}},
+	{Method: POST, Path: "/aichat/message_feedback", Summary: "评价AI回复（赞/踩），rating 为空时撤销评价", Req: def.SubmitMessageFeedbackRequest{}, Resp: def.SubmitMessageFeedbackResponse{}},
+	{Method: GET, Path: "/aichat/search", Summary: "按关键词搜索会话标题与聊天内容", Req: def.SearchConversationsRequest{}, Resp: def.SearchConversationsResponse{}},
+	{Method: POST, Path: "/aichat/set_conversation_preset", Summary: "设置会话使用的对话预设，preset_id 为空时恢复默认", Req: def.SetConversationPresetRequest{}, Resp: def.SetConversationPresetResponse{}},
+	{Method: GET, Path: "/aichat/presets", Summary: "当前用户可用的对话预设（全局预设与自己创建的预设）", Resp: def.ListPromptPresetsResponse{}},
+	{Method: POST, Path: "/aichat/preset", Summary: "创建自己的对话预设，需在配置中开启 prompt_preset.allow_user_presets", Req: def.PromptPresetRequest{}, Resp: def.PromptPresetResponse{}},
+	{Method: PUT, Path: "/aichat/preset/:id", Summary: "修改自己的对话预设", Req: def.PromptPresetRequest{}, Resp: def.PromptPresetResponse{}},
+	{Method: DELETE, Path: "/aichat/preset/:id", Summary: "删除自己的对话预设", Resp: def.DeletePromptPresetResponse{}},
+	{Method: POST, Path: "/aichat/generate_mind_map", Summary: "根据文本或上传的文件（multipart 表单字段 file、conversation_id）生成导图", Req: def.GenerateMindMapRequest{}, Resp: def.GenerateMindMapResponse{}},
+	{Method: POST, Path: "/aichat/summarize_mindmap", Summary: "总结导图，总结保存到关联的会话（未指定时新建会话）", Req: def.SummarizeMindMapRequest{}, Resp: def.SummarizeMindMapResponse{}},
+	{Method: GET, Path: "/aichat/get_attachments", Summary: "获取会话下归档的文件（可重新下载）", Req: def.GetAttachmentsRequest{}, Resp: def.GetAttachmentsResponse{}},
+	{Method: GET, Path: "/aichat/usage", Summary: "当前用户今日、本月的AI token 用量与套餐上限", Resp: def.GetAiUsageResponse{}},
+	{Method: GET, Path: "/admin/user/list", Summary: "用户列表", Req: def.AdminListUsersReq{}, Resp: def.AdminListUsersResp{}},
+	{Method: POST, Path: "/admin/user/status", Summary: "禁用/启用用户", Req: def.AdminUpdateUserStatusReq{}, Resp: def.AdminUpdateUserStatusResp{}},
+	{Method: POST, Path: "/admin/user/suspend", Summary: "禁用用户，可填写原因与到期时间，禁用后下线其所有会话", Req: def.AdminSuspendUserReq{}, Resp: def.AdminSuspendUserResp{}},
+	{Method: POST, Path: "/admin/user/reactivate", Summary: "恢复被禁用的用户", Req: def.AdminReactivateUserReq{}, Resp: def.AdminReactivateUserResp{}},
+	{Method: POST, Path: "/admin/user/role", Summary: "修改用户角色", Req: def.AdminUpdateUserRoleReq{}, Resp: def.AdminUpdateUserRoleResp{}},
+	{Method: POST, Path: "/admin/user/reset_password", Summary: "为用户重置密码", Req: def.AdminResetPasswordReq{}, Resp: def.AdminResetPasswordResp{}},
+	{Method: GET, Path: "/admin/user/sessions", Summary: "查看用户的登录记录", Req: def.AdminListUserSessionsReq{}, Resp: def.AdminListUserSessionsResp{}},
+	{Method: GET, Path: "/admin/audit/list", Summary: "查询账号安全记录", Req: def.AdminListAuditLogsReq{}, Resp: def.AdminListAuditLogsResp{}},
+	{Method: POST, Path: "/admin/user/plan", Summary: "修改用户套餐", Req: def.UpdateUserPlanReq{}, Resp: def.UpdateUserPlanResp{}},
+	{Method: GET, Path: "/admin/user/quota", Summary: "查看用户的套餐、用量与单独设置的配额", Req: def.AdminGetUserQuotaReq{}, Resp: def.GetPlanResp{}},
+	{Method: POST, Path: "/admin/user/quota", Summary: "为用户单独设置导图数量与节点数量配额", Req: def.UpdateUserQuotaReq{}, Resp: def.UpdateUserQuotaResp{}},
+	{Method: GET, Path: "/admin/backup/list", Summary: "查看用户的备份记录", Req: def.ListBackupsReq{}, Resp: def.ListBackupsResp{}},
+	{Method: POST, Path: "/admin/backup/restore", Summary: "从备份恢复用户已删除的导图及其会话", Req: def.RestoreBackupReq{}, Resp: def.RestoreBackupResp{}},
+	{Method: POST, Path: "/admin/prompt_preset", Summary: "创建全局对话预设，所有用户可用", Req: def.PromptPresetRequest{}, Resp: def.PromptPresetResponse{}},
+	{Method: PUT, Path: "/admin/prompt_preset/:id", Summary: "修改全局对话预设", Req: def.PromptPresetRequest{}, Resp: def.PromptPresetResponse{}},
+	{Method: DELETE, Path: "/admin/prompt_preset/:id", Summary: "删除全局对话预设", Resp: def.DeletePromptPresetResponse{}},
+	{Method: POST, Path: "/admin/mindmap_template", Summary: "创建导图模板", Req: def.MindMapTemplateReq{}, Resp: def.MindMapTemplateResp{}},
+	{Method: PUT, Path: "/admin/mindmap_template/:id", Summary: "修改导图模板，已基于模板创建的导图不受影响", Req: def.MindMapTemplateReq{}, Resp: def.MindMapTemplateResp{}},
+	{Method: DELETE, Path: "/admin/mindmap_template/:id", Summary: "删除导图模板", Resp: def.DeleteMindMapTemplateResp{}},
+	{Method: GET, Path: "/admin/message_feedback/export", Summary: "导出AI回复的评价及评价时的对话快照", Req: def.AdminExportMessageFeedbackReq{}, Resp: def.AdminExportMessageFeedbackResp{}},
+	{Method: GET, Path: "/admin/job/list", Summary: "定时任务的执行状态与延迟任务队列的积压情况", Resp: def.AdminListJobsResp{}},
+	{Method: GET, Path: "/admin/debug/vars", Summary: "运行指标：AI提供方按结果分类的调用次数、重试/回退/熔断次数与各提供方的熔断状态", Hidden: true},
+}
+
+// loadOpenAPI 按已注册的路由生成接口文档，需在注册完其他路由之后调用
+// 没有文档条目的路由只列出路径，启动时输出警告提醒补充 apiDocs
+func loadOpenAPI(r *gin.Engine, cfg configs.OpenAPIConfig, env string) {
+	if !cfg.Enabled {
+		return
+	}
+	doc, err := json.Marshal(buildOpenAPI(r.BasePath(), r.Routes()))
+	if err != nil {
+		zlog.Errorf("marshal openapi document failed: %v", err)
+		return
+	}
+	// [GET] /api/biz/v1/openapi.json
+	r.Handle(GET, "openapi.json", func(gCtx *gin.Context) {
+		gCtx.Data(http.StatusOK, "application/json; charset=utf-8", doc)
+	})
+
+	if !cfg.SwaggerUIEnabled(env) {
+		return
+	}
+	page := []byte(fmt.Sprintf(swaggerUIPage, html.EscapeString(cfg.Assets())))
+	// [GET] /api/biz/v1/swagger
+	r.Handle(GET, "swagger", func(gCtx *gin.Context) {
+		gCtx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	})
+}
+
+func buildOpenAPI(basePath string, routes gin.RoutesInfo) *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "forge API",
+		Description: "响应统一为 {Code, Message, Data}，Code 为 200 时成功，其余为错误码；参数校验失败时 Errors 中列出各字段的错误",
+		Version:     configs.Config().GetAppConfig().Version,
+	}, openapi.Server{URL: basePath})
+	b.AddSecurityScheme("bearerAuth", &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "登录返回的访问令牌；开启 cookie 鉴权时由 cookie 携带，无需设置",
+	})
+	for name, desc := range apiTags {
+		b.AddTag(name, desc)
+	}
+
+	docs := make(map[string]apiDoc, len(apiDocs))
+	for _, d := range apiDocs {
+		docs[d.Method+" "+d.Path] = d
+	}
+	for _, route := range routes {
+		// /readyz 等探针接口不在业务前缀下，不列入接口文档
+		path, ok := strings.CutPrefix(route.Path, basePath)
+		if !ok {
+			continue
+		}
+		d, ok := docs[route.Method+" "+path]
+		if !ok {
+			zlog.Warnf("route %s %s has no api doc", route.Method, route.Path)
+			d = apiDoc{Method: route.Method, Path: path}
+		}
+		if d.Hidden {
+			continue
+		}
+		b.Add(route.Method, path, openAPIOperation(b, d))
+	}
+	return b.Document()
+}
+
+func openAPIOperation(b *openapi.Builder, d apiDoc) openapi.Operation {
+	tag, _, _ := strings.Cut(strings.TrimPrefix(d.Path, "/"), "/")
+	op := openapi.Operation{
+		Tags:      []string{tag},
+		Summary:   d.Summary,
+		Responses: make(map[string]openapi.Response),
+	}
+	if !d.Public {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	if d.Req != nil {
+		switch {
+		case d.Method == GET:
+			op.Parameters = b.QueryParameters(d.Req)
+		case openapi.HasFile(d.Req):
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				"multipart/form-data": {Schema: b.FormSchema(d.Req)},
+			}}
+		case b.HasFields(d.Req, "json"):
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				"application/json": {Schema: b.SchemaOf(d.Req)},
+			}}
+		default:
+			op.Parameters = b.QueryParameters(d.Req)
+		}
+	}
+
+	if d.Produces != "" {
+		op.Responses["200"] = openapi.Response{Description: "成功", Content: map[string]openapi.MediaType{
+			d.Produces: {Schema: &openapi.Schema{Type: "string"}},
+		}}
+		return op
+	}
+	// 统一响应结构，与 response.JsonMsgResult 一致
+	op.Responses["200"] = openapi.Response{Description: "统一响应结构", Content: map[string]openapi.MediaType{
+		"application/json": {Schema: &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"Code":    {Type: "integer", Description: "200 为成功，其余为错误码"},
+				"Message": {Type: "string"},
+				"Data":    b.SchemaOf(d.Resp),
+				"Errors":  b.SchemaOf([]response.FieldError{}),
+			},
+			Required: []string{"Code", "Message", "Data"},
+		}},
+	}}
+	return op
+}
+
+// swaggerUIPage 加载 swagger-ui-dist 展示同目录下的 openapi.json，%[1]s 为静态资源地址
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>forge API</title>
+<link rel="stylesheet" href="%[1]s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%[1]s/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function () {
+	window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui", persistAuthorization: true});
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,149 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/entity"
+	"forge/biz/presetservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+)
+
+// mapPresetServiceErrorToMsgCode 对话预设相关错误映射
+func mapPresetServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, presetservice.ErrPresetNotFound) {
+		return response.PROMPT_PRESET_NOT_FOUND
+	}
+
+	if errors.Is(err, presetservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+
+	if errors.Is(err, presetservice.ErrUserPresetDisabled) {
+		return response.USER_PRESET_DISABLED
+	}
+
+	if errors.Is(err, presetservice.ErrPresetLimitExceeded) {
+		return response.PROMPT_PRESET_LIMIT_EXCEEDED
+	}
+
+	if errors.Is(err, presetservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	// 字段校验失败时返回具体原因
+	if errors.Is(err, entity.ErrInvalidPresetName) || errors.Is(err, entity.ErrInvalidPresetPrompt) ||
+		errors.Is(err, entity.ErrInvalidPresetDesc) || errors.Is(err, entity.ErrInvalidModelParams) {
+		return response.MsgCode{Code: response.PROMPT_PRESET_INVALID.Code, Msg: err.Error()}
+	}
+
+	return mapQuotaServiceErrorToMsgCode(err)
+}
+
+func handlePresetResponse(gCtx *gin.Context, rsp interface{}, err error, emptyResp interface{}) {
+	r := response.NewResponse(gCtx)
+	if err != nil {
+		msgCode := mapPresetServiceErrorToMsgCode(err)
+		gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			Code:    msgCode.Code,
+			Message: msgCode.Msg,
+			Data:    emptyResp,
+		})
+		return
+	}
+	r.Success(rsp)
+}
+
+// ListPromptPresets
+//
+//	@Description:[GET] /api/biz/v1/aichat/presets
+//	@return gin.HandlerFunc
+func ListPromptPresets() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListPromptPresets(ctx)
+		zlog.CtxAllInOne(ctx, "list_prompt_presets", nil, nil, err)
+
+		handlePresetResponse(gCtx, rsp, err, def.ListPromptPresetsResponse{Success: false})
+	}
+}
+
+// CreatePromptPreset global 为 true 时创建全局预设（管理接口）
+//
+//	@Description:[POST] /api/biz/v1/aichat/preset
+//	@Description:[POST] /api/biz/v1/admin/prompt_preset
+//	@return gin.HandlerFunc
+func CreatePromptPreset(global bool) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.PromptPresetRequest{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_COMPLETE.Code,
+				Message: response.PARAM_NOT_COMPLETE.Msg,
+				Data:    def.PromptPresetResponse{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreatePromptPreset(ctx, req, global)
+		zlog.CtxAllInOne(ctx, "create_prompt_preset", map[string]interface{}{"req": req, "global": global}, rsp, err)
+
+		handlePresetResponse(gCtx, rsp, err, def.PromptPresetResponse{Success: false})
+	}
+}
+
+// UpdatePromptPreset global 为 true 时修改全局预设（管理接口）
+//
+//	@Description:[PUT] /api/biz/v1/aichat/preset/:id
+//	@Description:[PUT] /api/biz/v1/admin/prompt_preset/:id
+//	@return gin.HandlerFunc
+func UpdatePromptPreset(global bool) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		presetID := gCtx.Param("id")
+		req := &def.PromptPresetRequest{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_COMPLETE.Code,
+				Message: response.PARAM_NOT_COMPLETE.Msg,
+				Data:    def.PromptPresetResponse{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdatePromptPreset(ctx, presetID, req, global)
+		zlog.CtxAllInOne(ctx, "update_prompt_preset", map[string]interface{}{"presetID": presetID, "req": req, "global": global}, rsp, err)
+
+		handlePresetResponse(gCtx, rsp, err, def.PromptPresetResponse{Success: false})
+	}
+}
+
+// DeletePromptPreset global 为 true 时删除全局预设（管理接口）
+//
+//	@Description:[DELETE] /api/biz/v1/aichat/preset/:id
+//	@Description:[DELETE] /api/biz/v1/admin/prompt_preset/:id
+//	@return gin.HandlerFunc
+func DeletePromptPreset(global bool) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		presetID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().DeletePromptPreset(ctx, presetID, global)
+		zlog.CtxAllInOne(ctx, "delete_prompt_preset", map[string]interface{}{"presetID": presetID, "global": global}, rsp, err)
+
+		handlePresetResponse(gCtx, rsp, err, def.DeletePromptPresetResponse{Success: false})
+	}
+}
@@ -0,0 +1,153 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"forge/biz/quotaservice"
+	"forge/interface/def"
+	"forge/interface/handler"
+	"forge/pkg/response"
+)
+
+// mapQuotaServiceErrorToMsgCode 套餐配额错误映射，各业务的错误映射在兜底时调用
+func mapQuotaServiceErrorToMsgCode(err error) response.MsgCode {
+	if err == nil {
+		return response.SUCCESS
+	}
+
+	if errors.Is(err, quotaservice.ErrInvalidPlan) {
+		return response.PLAN_INVALID
+	}
+
+	if errors.Is(err, quotaservice.ErrMindMapQuotaExceeded) {
+		return response.PLAN_MINDMAP_QUOTA_EXCEEDED
+	}
+
+	if errors.Is(err, quotaservice.ErrMindMapNodesExceeded) {
+		return response.PLAN_MINDMAP_NODES_EXCEEDED
+	}
+
+	if errors.Is(err, quotaservice.ErrInvalidQuota) {
+		return response.PLAN_QUOTA_INVALID
+	}
+
+	if errors.Is(err, quotaservice.ErrStorageQuotaExceeded) {
+		return response.PLAN_STORAGE_QUOTA_EXCEEDED
+	}
+
+	if errors.Is(err, quotaservice.ErrAIQuotaExceeded) {
+		return response.PLAN_AI_QUOTA_EXCEEDED
+	}
+
+	if errors.Is(err, quotaservice.ErrAIRateLimited) {
+		return response.PLAN_AI_RATE_LIMITED
+	}
+
+	if errors.Is(err, quotaservice.ErrAIConcurrencyLimited) {
+		return response.PLAN_AI_CONCURRENCY_LIMITED
+	}
+
+	if errors.Is(err, quotaservice.ErrAITokenQuotaExceeded) {
+		return response.PLAN_AI_TOKEN_EXCEEDED
+	}
+
+	if errors.Is(err, quotaservice.ErrUserNotFound) {
+		return response.USER_ACCOUNT_NOT_EXIST
+	}
+
+	if errors.Is(err, quotaservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+
+	if errors.Is(err, quotaservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	// 默认返回通用错误
+	return response.COMMON_FAIL
+}
+
+// GetPlan
+//
+//	@Description:[GET] /api/biz/v1/user/plan
+//	@return gin.HandlerFunc
+func GetPlan() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().GetPlan(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.GetPlanResp{})
+	}
+}
+
+// UpdateUserPlan
+//
+//	@Description:[POST] /api/biz/v1/admin/user/plan
+//	@return gin.HandlerFunc
+func UpdateUserPlan() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UpdateUserPlanReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UpdateUserPlanResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateUserPlan(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UpdateUserPlanResp{Success: false})
+	}
+}
+
+// AdminGetUserQuota
+//
+//	@Description:[GET] /api/biz/v1/admin/user/quota?user_id=
+//	@return gin.HandlerFunc
+func AdminGetUserQuota() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.AdminGetUserQuotaReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.GetPlanResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().AdminGetUserQuota(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.GetPlanResp{Success: false})
+	}
+}
+
+// UpdateUserQuota
+//
+//	@Description:[POST] /api/biz/v1/admin/user/quota
+//	@return gin.HandlerFunc
+func UpdateUserQuota() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UpdateUserQuotaReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UpdateUserQuotaResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateUserQuota(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UpdateUserQuotaResp{Success: false})
+	}
+}
@@ -1,12 +1,20 @@
 package router
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"forge/biz/adapter"
+	"forge/biz/entity"
 	"forge/biz/types"
 	"forge/infra/configs"
 	"forge/interface/middleware"
 	"forge/pkg/log/zlog"
 	"forge/util"
+	"net/http"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cast"
@@ -14,21 +22,33 @@ import (
 
 var (
 	jwtAuthMiddleware gin.HandlerFunc
+	csrfMiddleware    gin.HandlerFunc
+	// cookieAuth 未开启 cookie 鉴权时为 nil
+	cookieAuth *middleware.CookieAuth
+	// captchaService 未登录接口的图形验证码校验
+	captchaService types.ICaptchaService
+	// alertNotifier 未配置告警时为 nil
+	alertNotifier adapter.AlertNotifier
 )
 
-// InitJWTAuth 初始化JWT鉴权中间件
-func InitJWTAuth(userService types.IUserService) {
-	jwtConfig := configs.Config().GetJWTConfig()
-
-	// 如果secret_key为空，使用默认值（仅开发环境）
-	secretKey := jwtConfig.SecretKey
-	if secretKey == "" {
-		secretKey = "default-secret-key-change-in-production"
-		zlog.Warnf("JWT secret_key is empty, using default key. Please set it in config.yaml")
+// InitJWTAuth 初始化JWT鉴权中间件，开启 cookie 鉴权时同时初始化 CSRF 校验
+func InitJWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService, cookieConfig configs.CookieAuthConfig) {
+	cookieAuth = middleware.NewCookieAuth(cookieConfig, jwtUtil.TokenTTL(), jwtUtil.RefreshTokenTTL())
+	if cookieAuth.Enabled() && !cookieConfig.Secure {
+		zlog.Warnf("cookie_auth.secure is false, auth cookie will be sent over plain http")
 	}
+	jwtAuthMiddleware = middleware.JWTAuth(jwtUtil, userService, cookieAuth)
+	csrfMiddleware = middleware.CSRF(cookieAuth)
+}
+
+// InitCaptcha 初始化图形验证码校验，需在 RunServer 之前调用
+func InitCaptcha(service types.ICaptchaService) {
+	captchaService = service
+}
 
-	jwtUtil := util.NewJWTUtil(secretKey, jwtConfig.ExpireHours)
-	jwtAuthMiddleware = middleware.JWTAuth(jwtUtil, userService)
+// InitAlert 初始化 panic 告警，需在 RunServer 之前调用
+func InitAlert(notifier adapter.AlertNotifier) {
+	alertNotifier = notifier
 }
 
 func RunServer() {
@@ -38,37 +58,95 @@ func RunServer() {
 
 func register() (router *gin.Engine) {
 	gin.SetMode(gin.DebugMode)
-	r := gin.Default()
-	r.RouterGroup = *r.Group("/api/biz/v1", middleware.AddTracer())
+	registerValidatorTagName()
+	// 使用自定义 Recovery 替换 gin 默认的，panic 时返回统一错误并发送告警
+	r := gin.New()
+	// 只信任配置的代理转发的 X-Forwarded-For，否则客户端可以伪造 IP 绕过按 IP 的限流与登录失败锁定
+	if err := r.SetTrustedProxies(configs.Config().GetServerConfig().TrustedProxies); err != nil {
+		panic(fmt.Sprintf("set trusted proxies failed: %v", err))
+	}
+	r.Use(gin.Logger(), middleware.Recovery(alertNotifier))
+	// 就绪检查供负载均衡、k8s 探针调用，不带业务前缀，不限流、不记录链路
+	// [GET] /readyz
+	r.Handle(GET, "/readyz", Readyz())
+	r.RouterGroup = *r.Group("/api/biz/v1", middleware.Tracing(), middleware.AddTracer())
+	// 各路由组按 rate_limit 配置限流，需要JWT的路由组挂在鉴权之后，按用户ID计数
+	// 各路由组按 body_limit 配置限制请求体大小，挂在最前面，超大请求在鉴权之前拒绝
+	bodyLimitConfig := configs.Config().GetBodyLimitConfig()
 
 	// 用户服务：不需要JWT的路由（登录、注册、发送验证码、重置密码）
-	userGroup := r.Group("user")
+	userGroup := r.Group("user", middleware.BodyLimit(bodyLimitConfig, "user"), middleware.RateLimit("user"))
 	loadUserService(userGroup)
 
 	// 用户服务：需要JWT鉴权的路由（更新头像, 查看个人主页，更新联系方式）
-	userAuthGroup := r.Group("user", jwtAuthMiddleware)
+	// 通过 cookie 鉴权的写请求还需要通过 CSRF 校验
+	userAuthGroup := r.Group("user", middleware.BodyLimit(bodyLimitConfig, "user_auth"), jwtAuthMiddleware, middleware.RateLimit("user_auth"), csrfMiddleware)
 	loadUserAuthService(userAuthGroup)
 
 	// mindmap路由组需要JWT鉴权
-	mindMapGroup := r.Group("mindmap", jwtAuthMiddleware)
+	mindMapGroup := r.Group("mindmap", middleware.BodyLimit(bodyLimitConfig, "mindmap"), jwtAuthMiddleware, middleware.RateLimit("mindmap"), csrfMiddleware)
 	loadMindMapService(mindMapGroup)
 
+	// 分享链接查看路由组不需要JWT
+	shareGroup := r.Group("share", middleware.BodyLimit(bodyLimitConfig, "share"), middleware.RateLimit("share"))
+	loadShareService(shareGroup)
+
 	// cos路由组需要JWT鉴权
-	cosGroup := r.Group("cos", jwtAuthMiddleware)
+	cosGroup := r.Group("cos", middleware.BodyLimit(bodyLimitConfig, "cos"), jwtAuthMiddleware, middleware.RateLimit("cos"), csrfMiddleware)
 	loadCOSService(cosGroup)
 
-	aiChat := r.Group("aichat", jwtAuthMiddleware)
+	// 转发下载通过令牌签名校验，不需要JWT
+	cosPublicGroup := r.Group("cos", middleware.BodyLimit(bodyLimitConfig, "cos_public"), middleware.RateLimit("cos_public"))
+	loadCOSPublicService(cosPublicGroup)
+
+	aiChat := r.Group("aichat", middleware.BodyLimit(bodyLimitConfig, "aichat"), jwtAuthMiddleware, middleware.RateLimit("aichat"), csrfMiddleware)
 	loadAiChat(aiChat)
 
+	// 管理接口需要JWT鉴权且为管理员角色
+	adminGroup := r.Group("admin", middleware.BodyLimit(bodyLimitConfig, "admin"), jwtAuthMiddleware, middleware.RateLimit("admin"), csrfMiddleware, middleware.RequireRole(entity.UserRoleAdmin))
+	loadAdminService(adminGroup)
+
+	// 接口文档按已注册的路由生成，放在最后
+	loadOpenAPI(r, configs.Config().GetOpenAPIConfig(), configs.Config().GetAppConfig().Env)
+
 	return r
 }
 
+// run 启动 HTTP 服务，收到 SIGINT/SIGTERM 后停止接收新请求，等待处理中的请求完成后返回
+// 等待超过 app.shutdown_timeout_seconds 时强制关闭剩余连接
 func run(router *gin.Engine) {
-	prot := cast.ToString(configs.Config().GetAppConfig().Port)
-	host := configs.Config().GetAppConfig().Host
+	appConfig := configs.Config().GetAppConfig()
+	prot := cast.ToString(appConfig.Port)
+	host := appConfig.Host
 
+	server := &http.Server{
+		Addr:    fmt.Sprintf("%s:%s", host, prot),
+		Handler: router,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
 	zlog.Infof("server run success")
-	router.Run(fmt.Sprintf("%s:%s", host, prot))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			zlog.Errorf("server run failed: %v", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	zlog.Infof("shutdown signal received, draining requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout())
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		zlog.Errorf("server shutdown timeout, force close: %v", err)
+		_ = server.Close()
+	}
 	zlog.Infof("close run success")
 }
 
@@ -80,15 +158,23 @@ const (
 )
 
 func loadUserService(r *gin.RouterGroup) {
-	r.Handle(POST, "login", Login())
+	// 登录、注册、发送验证码在同一来源请求过多时需要先完成图形验证码
+	// [GET] /api/biz/v1/user/captcha
+	r.Handle(GET, "captcha", GetCaptcha())
+
+	r.Handle(POST, "login", middleware.Captcha(captchaService, types.CaptchaRouteLogin), Login())
+
+	// 刷新令牌接口（访问令牌过期后调用，不需要JWT）
+	// [POST] /api/biz/v1/user/refresh_token
+	r.Handle(POST, "refresh_token", RefreshToken())
 
 	// 注册接口 user/api/biz/v1/register
 	// [POST] /api/biz/v1/user/register
-	r.Handle(POST, "register", Register())
+	r.Handle(POST, "register", middleware.Captcha(captchaService, types.CaptchaRouteRegister), Register())
 
 	// 发送验证码接口
 	// [POST] /api/biz/v1/user/send_code
-	r.Handle(POST, "send_code", SendCode())
+	r.Handle(POST, "send_code", middleware.Captcha(captchaService, types.CaptchaRouteSendCode), SendCode())
 
 	// 重置密码接口
 	// [POST] /api/biz/v1/user/reset_password
@@ -97,9 +183,37 @@ func loadUserService(r *gin.RouterGroup) {
 	//回显版本
 	// [GET] /api/biz/v1/user/version
 	r.Handle(GET, "version", GetVersion())
+
+	// 支付渠道回调（通过签名校验，不需要JWT）
+	// [POST] /api/biz/v1/user/billing/webhook
+	r.Handle(POST, "billing/webhook", PaymentWebhook())
+
+	// 提醒邮件中的退订链接（通过签名校验，不需要JWT）
+	// [GET] /api/biz/v1/user/unsubscribe?uid=&token=
+	r.Handle(GET, "unsubscribe", UnsubscribeReminder())
+
+	// 免密登录，向已注册的邮箱发送一次性登录链接
+	// [POST] /api/biz/v1/user/login/magic_link
+	r.Handle(POST, "login/magic_link", SendMagicLink())
+
+	// 邮件中的登录链接，链接只能使用一次
+	// [GET] /api/biz/v1/user/login/magic_link/verify?token=
+	r.Handle(GET, "login/magic_link/verify", MagicLinkLogin())
+
+	// 第三方登录授权地址（provider: wechat/github/google）
+	// [GET] /api/biz/v1/user/oauth/:provider/authorize
+	r.Handle(GET, "oauth/:provider/authorize", OAuthAuthorize())
+
+	// 第三方登录，未绑定的第三方账号自动注册
+	// [POST] /api/biz/v1/user/oauth/:provider/login
+	r.Handle(POST, "oauth/:provider/login", OAuthLogin())
 }
 
 func loadUserAuthService(r *gin.RouterGroup) {
+	// 退出登录，cookie 鉴权模式下清除 cookie
+	// [POST] /api/biz/v1/user/logout
+	r.Handle(POST, "logout", Logout())
+
 	// 个人主页接口
 	// [GET] /api/biz/v1/user/home
 	r.Handle(GET, "home", GetHome())
@@ -116,9 +230,81 @@ func loadUserAuthService(r *gin.RouterGroup) {
 	// [DELETE] /api/biz/v1/user/contact
 	r.Handle(DELETE, "contact", UnbindAccount())
 
+	// 注销账号接口（冷静期内重新登录可撤销）
+	// [DELETE] /api/biz/v1/user/account
+	r.Handle(DELETE, "account", DeleteAccount())
+
+	// 合并账号接口（将另外注册的账号的导图、AI对话合并到当前账号）
+	// [POST] /api/biz/v1/user/account/merge
+	r.Handle(POST, "account/merge", MergeAccount())
+
+	// 修改个人资料接口
+	// [PUT] /api/biz/v1/user/profile
+	r.Handle(PUT, "profile", UpdateProfile())
+
 	// 更新头像接口（改为POST，因为要上传文件）
 	// [POST] /api/biz/v1/user/avatar
 	r.Handle(POST, "avatar", UpdateAvatar())
+
+	// 查看套餐与用量
+	// [GET] /api/biz/v1/user/plan
+	r.Handle(GET, "plan", GetPlan())
+
+	// 修改通知偏好
+	// [POST] /api/biz/v1/user/notification_preference
+	r.Handle(POST, "notification_preference", UpdateNotificationPreference())
+
+	// 登录记录
+	// [GET] /api/biz/v1/user/sessions
+	r.Handle(GET, "sessions", ListSessions())
+
+	// 远程下线登录会话
+	// [DELETE] /api/biz/v1/user/sessions/:id
+	r.Handle(DELETE, "sessions/:id", RevokeSession())
+
+	// 生成邀请码
+	// [POST] /api/biz/v1/user/invite/codes
+	r.Handle(POST, "invite/codes", CreateInviteCode())
+
+	// 我的邀请码
+	// [GET] /api/biz/v1/user/invite/codes
+	r.Handle(GET, "invite/codes", ListInviteCodes())
+
+	// 我邀请注册的用户
+	// [GET] /api/biz/v1/user/invite/referrals
+	r.Handle(GET, "invite/referrals", ListReferrals())
+
+	// 账号安全记录（登录、重置密码、换绑联系方式、修改头像、管理员操作）
+	// [GET] /api/biz/v1/user/security/audit?page=&page_size=
+	r.Handle(GET, "security/audit", ListAuditLogs())
+
+	// 导出个人数据（个人资料、导图、AI对话），后台打包完成后邮件通知下载
+	// [GET] /api/biz/v1/user/export
+	r.Handle(GET, "export", ExportData())
+
+	// 已绑定的第三方账号
+	// [GET] /api/biz/v1/user/oauth/bindings
+	r.Handle(GET, "oauth/bindings", ListOAuthBindings())
+
+	// 绑定第三方账号的授权地址
+	// [GET] /api/biz/v1/user/oauth/:provider/bind_authorize
+	r.Handle(GET, "oauth/:provider/bind_authorize", OAuthBindAuthorize())
+
+	// 绑定第三方账号
+	// [POST] /api/biz/v1/user/oauth/:provider/bind
+	r.Handle(POST, "oauth/:provider/bind", BindOAuth())
+
+	// 解绑第三方账号
+	// [DELETE] /api/biz/v1/user/oauth/:provider
+	r.Handle(DELETE, "oauth/:provider", UnbindOAuth())
+
+	// 购买套餐，返回支付页面地址
+	// [POST] /api/biz/v1/user/billing/checkout
+	r.Handle(POST, "billing/checkout", CreateCheckout())
+
+	// 账单列表
+	// [GET] /api/biz/v1/user/billing/invoices
+	r.Handle(GET, "billing/invoices", ListInvoices())
 }
 
 func loadMindMapService(r *gin.RouterGroup) {
@@ -126,12 +312,21 @@ func loadMindMapService(r *gin.RouterGroup) {
 	// [POST] /api/biz/v1/mindmap
 	r.Handle(POST, "", CreateMindMap())
 
+	// 根据纯文本大纲创建思维导图（不调用AI）
+	// [POST] /api/biz/v1/mindmap/from_text
+	r.Handle(POST, "from_text", CreateMindMapFromText())
+
+	// 导入 xmind/mm/opml/md 文件创建思维导图，表单字段 file、layout，可选 title、desc
+	// [POST] /api/biz/v1/mindmap/import
+	r.Handle(POST, "import", ImportMindMap())
+
 	// 获取思维导图详情
 	// [GET] /api/biz/v1/mindmap/:id
 	r.Handle(GET, ":id", GetMindMap())
 
-	// 获取思维导图列表
-	// [GET] /api/biz/v1/mindmap/list
+	// 获取思维导图列表，scope=shared 时为与我共享的导图，scope=all 时为两者
+	// 支持按文件夹、标签筛选，按更新时间、创建时间或标题排序，偏移分页或游标分页
+	// [GET] /api/biz/v1/mindmap/list?scope=&folder_id=&tags=&sort_by=&order=&page=&page_size=&cursor=
 	r.Handle(GET, "list", ListMindMaps())
 
 	// 更新思维导图
@@ -141,12 +336,140 @@ func loadMindMapService(r *gin.RouterGroup) {
 	// 删除思维导图
 	// [DELETE] /api/biz/v1/mindmap/:id
 	r.Handle(DELETE, ":id", DeleteMindMap())
+
+	// 创建只读分享链接，可设置访问密码与有效期
+	// [POST] /api/biz/v1/mindmap/:id/share
+	r.Handle(POST, ":id/share", ShareMindMap())
+
+	// 导图仍有效的分享链接
+	// [GET] /api/biz/v1/mindmap/:id/shares
+	r.Handle(GET, ":id/shares", ListMindMapShares())
+
+	// 撤销分享链接
+	// [DELETE] /api/biz/v1/mindmap/:id/share/:share_id
+	r.Handle(DELETE, ":id/share/:share_id", RevokeMindMapShare())
+
+	// 按手机号或邮箱添加协作者（viewer/editor），已是协作者时修改角色
+	// [POST] /api/biz/v1/mindmap/:id/members
+	r.Handle(POST, ":id/members", AddMindMapMember())
+
+	// 导图的所有者与协作者
+	// [GET] /api/biz/v1/mindmap/:id/members
+	r.Handle(GET, ":id/members", ListMindMapMembers())
+
+	// 移除协作者，协作者移除自己即退出协作
+	// [DELETE] /api/biz/v1/mindmap/:id/members/:user_id
+	r.Handle(DELETE, ":id/members/:user_id", RemoveMindMapMember())
+
+	// 导图的变更记录（节点增删改、改名、分享、添加协作者），可只查询某时间之后的记录
+	// [GET] /api/biz/v1/mindmap/:id/activity
+	r.Handle(GET, ":id/activity", ListMindMapActivities())
+
+	// 在节点上评论，所有者与协作者都可以评论
+	// [POST] /api/biz/v1/mindmap/:id/comments
+	r.Handle(POST, ":id/comments", CreateMindMapComment())
+
+	// 导图的评论，可按节点与是否解决筛选
+	// [GET] /api/biz/v1/mindmap/:id/comments
+	r.Handle(GET, ":id/comments", ListMindMapComments())
+
+	// 标记评论为已解决，所有者、编辑者与评论者可以操作
+	// [PUT] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+	r.Handle(PUT, ":id/comments/:comment_id/resolve", ResolveMindMapComment(true))
+
+	// 重新打开已解决的评论
+	// [DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+	r.Handle(DELETE, ":id/comments/:comment_id/resolve", ResolveMindMapComment(false))
+
+	// 删除评论，所有者与评论者可以删除
+	// [DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id
+	r.Handle(DELETE, ":id/comments/:comment_id", DeleteMindMapComment())
+
+	// 收藏导图，自己的导图与与我共享的导图都可以收藏
+	// [PUT] /api/biz/v1/mindmap/:id/star
+	r.Handle(PUT, ":id/star", StarMindMap())
+
+	// 取消收藏
+	// [DELETE] /api/biz/v1/mindmap/:id/star
+	r.Handle(DELETE, ":id/star", UnstarMindMap())
+
+	// 将自己的导图移到文件夹中，folder_id 为空时移出文件夹
+	// [PUT] /api/biz/v1/mindmap/:id/folder
+	r.Handle(PUT, ":id/folder", MoveMindMap())
+
+	// 创建文件夹，可以指定上级文件夹
+	// [POST] /api/biz/v1/mindmap/folders
+	r.Handle(POST, "folders", CreateMindMapFolder())
+
+	// 自己的所有文件夹
+	// [GET] /api/biz/v1/mindmap/folders
+	r.Handle(GET, "folders", ListMindMapFolders())
+
+	// 重命名或移动文件夹
+	// [PUT] /api/biz/v1/mindmap/folders/:folder_id
+	r.Handle(PUT, "folders/:folder_id", UpdateMindMapFolder())
+
+	// 删除文件夹，其中的子文件夹与导图移到上级文件夹
+	// [DELETE] /api/biz/v1/mindmap/folders/:folder_id
+	r.Handle(DELETE, "folders/:folder_id", DeleteMindMapFolder())
+
+	// 替换自己导图的全部标签
+	// [PUT] /api/biz/v1/mindmap/:id/tags
+	r.Handle(PUT, ":id/tags", SetMindMapTags())
+
+	// 移除自己导图的一个标签
+	// [DELETE] /api/biz/v1/mindmap/:id/tags/:tag
+	r.Handle(DELETE, ":id/tags/:tag", RemoveMindMapTag())
+
+	// 自己的所有标签及各标签的导图数
+	// [GET] /api/biz/v1/mindmap/tags
+	r.Handle(GET, "tags", ListMindMapTags())
+
+	// 在自己的导图与与我共享的导图中搜索标题与节点文本，返回标出关键词的节点摘要
+	// [GET] /api/biz/v1/mindmap/search?q=&page=&page_size=
+	r.Handle(GET, "search", SearchMindMaps())
+
+	// 模板列表及全部分类，可按分类筛选
+	// [GET] /api/biz/v1/mindmap/templates?category=
+	r.Handle(GET, "templates", ListMindMapTemplates())
+
+	// 基于模板创建自己的导图，可指定标题与文件夹
+	// [POST] /api/biz/v1/mindmap/from_template/:template_id
+	r.Handle(POST, "from_template/:template_id", CreateMindMapFromTemplate())
+
+	// 导出为 xmind/mm/opml/md/svg/png，默认直接下载文件，delivery=url 时返回临时下载链接
+	// [GET] /api/biz/v1/mindmap/:id/export?format=&delivery=
+	r.Handle(GET, ":id/export", ExportMindMap())
+}
+
+func loadShareService(r *gin.RouterGroup) {
+	// 通过分享链接查看导图（通过签名校验，不需要JWT），访问密码放在请求头 X-Share-Password
+	// [GET] /api/biz/v1/share/mindmap?token=
+	r.Handle(GET, "mindmap", GetSharedMindMap())
 }
 
 func loadCOSService(r *gin.RouterGroup) {
 	// 获取OSS临时凭证
 	// [POST] /api/biz/v1/cos/sts/credentials
 	r.Handle(POST, "sts/credentials", GetOSSCredentials())
+
+	// 为自己的文件生成有时效的下载链接，proxy 模式下返回经服务端转发的链接
+	// [GET] /api/biz/v1/cos/presign_download?resource_path=&expire_seconds=
+	r.Handle(GET, "presign_download", PresignDownload())
+
+	// 分页获取自己上传的文件
+	// [GET] /api/biz/v1/cos/files?purpose=&page=&page_size=
+	r.Handle(GET, "files", ListFiles())
+
+	// 删除自己上传的文件，释放占用的存储空间
+	// [DELETE] /api/biz/v1/cos/files/:id
+	r.Handle(DELETE, "files/:id", DeleteFile())
+}
+
+func loadCOSPublicService(r *gin.RouterGroup) {
+	// 通过下载链接中的令牌转发下载文件（通过签名校验，不需要JWT）
+	// [GET] /api/biz/v1/cos/download?token=
+	r.Handle(GET, "download", ProxyDownload())
 }
 
 func loadAiChat(r *gin.RouterGroup) {
@@ -154,28 +477,186 @@ func loadAiChat(r *gin.RouterGroup) {
 	// [POST] /api/biz/v1/aichat/send_message
 	r.Handle(POST, "send_message", SendMessage())
 
+	// 流式ai对话（SSE），也可以在 send_message 请求头中设置 Accept: text/event-stream
+	// [POST] /api/biz/v1/aichat/send_message_stream
+	r.Handle(POST, "send_message_stream", SendMessageStream())
+
 	//新增会话
 	// [POST] /api/biz/v1/aichat/save_conversation
 	r.Handle(POST, "save_conversation", SaveNewConversation())
 
 	//获取该导图的所有会话
-	// [GET] /api/biz/v1/aichat/get_conversation_list?map_id=
+	// [GET] /api/biz/v1/aichat/get_conversation_list?map_id=&archived=
+	// archived=true 时只列出已归档的会话，默认只列出未归档的会话
+	// 置顶的会话在前，其余按手动调整的顺序，未调整过的（新建的）会话按最近更新排在前面
 	r.Handle(GET, "get_conversation_list", GetConversationList())
 
 	//删除会话
 	// [POST] /api/biz/v1/aichat/del_conversation
 	r.Handle(POST, "del_conversation", DelConversation())
 
+	//恢复已删除的会话（删除后7天内）
+	// [POST] /api/biz/v1/aichat/restore_conversation
+	r.Handle(POST, "restore_conversation", RestoreConversation())
+
 	//获取某个会话的详细信息
-	// [GET] /api/biz/v1/aichat/get_conversation?conversation_id=
+	// [GET] /api/biz/v1/aichat/get_conversation?conversation_id=&before_message_id=&limit=
+	// 传 limit 时按游标分页，不传时返回全部消息
 	r.Handle(GET, "get_conversation", GetConversation())
 
+	//从某条消息分支出新的会话（同一导图下，原会话不变）
+	// [POST] /api/biz/v1/aichat/fork_conversation
+	r.Handle(POST, "fork_conversation", ForkConversation())
+
 	//更新某个会话的标题
 	// [POST] /api/biz/v1/aichat/update_conversation_title
 	r.Handle(POST, "update_conversation_title", UpdateConversationTitle())
 
+	//归档或取消归档某个会话
+	// [POST] /api/biz/v1/aichat/archive_conversation
+	r.Handle(POST, "archive_conversation", ArchiveConversation())
+
+	//置顶或取消置顶某个会话，置顶的会话在会话列表最前面
+	// [POST] /api/biz/v1/aichat/pin_conversation
+	r.Handle(POST, "pin_conversation", PinConversation())
+
+	//按给定顺序调整导图下会话的排列顺序
+	// [POST] /api/biz/v1/aichat/reorder_conversations
+	r.Handle(POST, "reorder_conversations", ReorderConversations())
+
+	//评价AI回复（赞/踩），rating 为空时撤销评价
+	// [POST] /api/biz/v1/aichat/message_feedback
+	r.Handle(POST, "message_feedback", SubmitMessageFeedback())
+
+	//按关键词搜索会话标题与聊天内容
+	// [GET] /api/biz/v1/aichat/search?q=&archived=&page=&page_size=
+	r.Handle(GET, "search", SearchConversations())
+
+	//设置会话使用的对话预设，preset_id 为空时恢复默认
+	// [POST] /api/biz/v1/aichat/set_conversation_preset
+	r.Handle(POST, "set_conversation_preset", SetConversationPreset())
+
+	//当前用户可用的对话预设（全局预设与自己创建的预设）
+	// [GET] /api/biz/v1/aichat/presets
+	r.Handle(GET, "presets", ListPromptPresets())
+
+	//创建自己的对话预设，需在配置中开启 prompt_preset.allow_user_presets
+	// [POST] /api/biz/v1/aichat/preset
+	r.Handle(POST, "preset", CreatePromptPreset(false))
+
+	//修改自己的对话预设
+	// [PUT] /api/biz/v1/aichat/preset/:id
+	r.Handle(PUT, "preset/:id", UpdatePromptPreset(false))
+
+	//删除自己的对话预设
+	// [DELETE] /api/biz/v1/aichat/preset/:id
+	r.Handle(DELETE, "preset/:id", DeletePromptPreset(false))
+
 	//生成导图
 	// [POST] /api/biz/v1/aichat/generate_mind_map
-	// 表单名称 file
+	// 表单名称 file，支持 pdf/docx/pptx/md/txt 以及 srt/vtt 字幕文件，大小与页数上限见 ai_client.document 配置
+	// 可选表单字段 conversation_id，上传的文件会归档到该会话下
 	r.Handle(POST, "generate_mind_map", GenerateMindMap())
+
+	//总结导图，总结保存到关联的会话（未指定时新建会话）
+	// [POST] /api/biz/v1/aichat/summarize_mindmap
+	r.Handle(POST, "summarize_mindmap", SummarizeMindMap())
+
+	//获取会话下归档的文件（可重新下载）
+	// [GET] /api/biz/v1/aichat/get_attachments?conversation_id=
+	r.Handle(GET, "get_attachments", GetAttachments())
+
+	//当前用户今日、本月的AI token 用量与套餐上限
+	// [GET] /api/biz/v1/aichat/usage
+	r.Handle(GET, "usage", GetAiUsage())
+}
+
+func loadAdminService(r *gin.RouterGroup) {
+	// 用户列表
+	// [GET] /api/biz/v1/admin/user/list?keyword=&status=&role=&page=&page_size=
+	r.Handle(GET, "user/list", AdminListUsers())
+
+	// 禁用/启用用户
+	// [POST] /api/biz/v1/admin/user/status
+	r.Handle(POST, "user/status", AdminUpdateUserStatus())
+
+	// 禁用用户，可填写原因与到期时间，禁用后下线其所有会话
+	// [POST] /api/biz/v1/admin/user/suspend
+	r.Handle(POST, "user/suspend", AdminSuspendUser())
+
+	// 恢复被禁用的用户
+	// [POST] /api/biz/v1/admin/user/reactivate
+	r.Handle(POST, "user/reactivate", AdminReactivateUser())
+
+	// 修改用户角色
+	// [POST] /api/biz/v1/admin/user/role
+	r.Handle(POST, "user/role", AdminUpdateUserRole())
+
+	// 为用户重置密码
+	// [POST] /api/biz/v1/admin/user/reset_password
+	r.Handle(POST, "user/reset_password", AdminResetPassword())
+
+	// 查看用户的登录记录
+	// [GET] /api/biz/v1/admin/user/sessions?user_id=
+	r.Handle(GET, "user/sessions", AdminListUserSessions())
+
+	// 查询账号安全记录
+	// [GET] /api/biz/v1/admin/audit/list?user_id=&actor_id=&action=&result=&page=&page_size=
+	r.Handle(GET, "audit/list", AdminListAuditLogs())
+
+	// 修改用户套餐
+	// [POST] /api/biz/v1/admin/user/plan
+	r.Handle(POST, "user/plan", UpdateUserPlan())
+
+	// 查看用户的套餐、用量与单独设置的配额
+	// [GET] /api/biz/v1/admin/user/quota?user_id=
+	r.Handle(GET, "user/quota", AdminGetUserQuota())
+
+	// 为用户单独设置导图数量与节点数量配额
+	// [POST] /api/biz/v1/admin/user/quota
+	r.Handle(POST, "user/quota", UpdateUserQuota())
+
+	// 查看用户的备份记录
+	// [GET] /api/biz/v1/admin/backup/list?user_id=
+	r.Handle(GET, "backup/list", ListBackups())
+
+	// 从备份恢复用户已删除的导图及其会话
+	// [POST] /api/biz/v1/admin/backup/restore
+	r.Handle(POST, "backup/restore", RestoreBackup())
+
+	// 创建全局对话预设，所有用户可用
+	// [POST] /api/biz/v1/admin/prompt_preset
+	r.Handle(POST, "prompt_preset", CreatePromptPreset(true))
+
+	// 修改全局对话预设
+	// [PUT] /api/biz/v1/admin/prompt_preset/:id
+	r.Handle(PUT, "prompt_preset/:id", UpdatePromptPreset(true))
+
+	// 删除全局对话预设
+	// [DELETE] /api/biz/v1/admin/prompt_preset/:id
+	r.Handle(DELETE, "prompt_preset/:id", DeletePromptPreset(true))
+
+	// 创建导图模板
+	// [POST] /api/biz/v1/admin/mindmap_template
+	r.Handle(POST, "mindmap_template", CreateMindMapTemplate())
+
+	// 修改导图模板，已基于模板创建的导图不受影响
+	// [PUT] /api/biz/v1/admin/mindmap_template/:id
+	r.Handle(PUT, "mindmap_template/:id", UpdateMindMapTemplate())
+
+	// 删除导图模板
+	// [DELETE] /api/biz/v1/admin/mindmap_template/:id
+	r.Handle(DELETE, "mindmap_template/:id", DeleteMindMapTemplate())
+
+	// 导出AI回复的评价及评价时的对话快照
+	// [GET] /api/biz/v1/admin/message_feedback/export?rating=&preset_id=&start_date=&end_date=&page=&page_size=
+	r.Handle(GET, "message_feedback/export", AdminExportMessageFeedback())
+
+	// 定时任务的执行状态与延迟任务队列的积压情况
+	// [GET] /api/biz/v1/admin/job/list
+	r.Handle(GET, "job/list", AdminListJobs())
+
+	// 运行指标：AI提供方按结果分类的调用次数、重试/回退/熔断次数与各提供方的熔断状态
+	// [GET] /api/biz/v1/admin/debug/vars
+	r.Handle(GET, "debug/vars", gin.WrapH(expvar.Handler()))
 }
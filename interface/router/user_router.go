@@ -5,20 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
+	"forge/biz/auditservice"
+	"forge/biz/captchaservice"
 	"forge/biz/cosservice"
 	"forge/biz/userservice"
 
-	// "forge/constant"
+	"forge/constant"
 	"forge/interface/def"
 	"forge/interface/handler"
+	"forge/interface/middleware"
 	"forge/pkg/log/zlog"
 
-	// "forge/pkg/loop"
+	"forge/pkg/loop"
 	"forge/pkg/response"
 	"forge/util"
 )
@@ -88,6 +94,81 @@ func mapServiceErrorToMsgCode(err error) response.MsgCode {
 		return response.ACCOUNT_LAST_CONTACT
 	}
 
+	if errors.Is(err, userservice.ErrAccountPendingDeletion) {
+		return response.ACCOUNT_PENDING_DELETION
+	}
+
+	if errors.Is(err, userservice.ErrAccountSuspended) {
+		return response.ACCOUNT_SUSPENDED
+	}
+
+	if errors.Is(err, userservice.ErrCannotManageSelf) {
+		return response.CANNOT_MANAGE_SELF
+	}
+
+	if errors.Is(err, userservice.ErrInvalidRole) {
+		return response.PARAM_NOT_VALID
+	}
+
+	if errors.Is(err, userservice.ErrSessionNotFound) {
+		return response.SESSION_NOT_FOUND
+	}
+
+	if errors.Is(err, userservice.ErrInvalidMagicLink) {
+		return response.MAGIC_LINK_INVALID
+	}
+
+	if errors.Is(err, userservice.ErrInvalidRefreshToken) {
+		return response.REFRESH_TOKEN_INVALID
+	}
+
+	if errors.Is(err, userservice.ErrTooManyLoginAttempts) {
+		return response.LOGIN_TOO_MANY_ATTEMPTS
+	}
+
+	if errors.Is(err, userservice.ErrInvalidUnsubscribeToken) {
+		return response.UNSUBSCRIBE_TOKEN_INVALID
+	}
+
+	// 第三方登录错误
+	if errors.Is(err, userservice.ErrUnsupportedOAuthProvider) {
+		return response.OAUTH_PROVIDER_UNSUPPORTED
+	}
+	if errors.Is(err, userservice.ErrInvalidOAuthState) {
+		return response.OAUTH_STATE_INVALID
+	}
+	if errors.Is(err, userservice.ErrOAuthCodeInvalid) {
+		return response.OAUTH_CODE_INVALID
+	}
+	if errors.Is(err, userservice.ErrOAuthProvider) {
+		return response.OAUTH_PROVIDER_ERROR
+	}
+	if errors.Is(err, userservice.ErrOAuthAlreadyBound) {
+		return response.OAUTH_ALREADY_BOUND
+	}
+
+	if errors.Is(err, userservice.ErrPasswordReused) {
+		return response.PASSWORD_RECENTLY_USED
+	}
+	if errors.Is(err, userservice.ErrOldContactConfirmRequired) {
+		return response.OLD_CONTACT_CODE_REQUIRED
+	}
+
+	if errors.Is(err, userservice.ErrMergeSameAccount) {
+		return response.MERGE_SAME_ACCOUNT
+	}
+
+	// 邀请码错误
+	if errors.Is(err, userservice.ErrInviteCodeRequired) {
+		return response.INVITE_CODE_REQUIRED
+	}
+	if errors.Is(err, userservice.ErrInviteCodeInvalid) {
+		return response.INVITE_CODE_INVALID
+	}
+	if errors.Is(err, userservice.ErrInviteQuotaExceeded) {
+		return response.INVITE_QUOTA_EXCEEDED
+	}
+
 	if errors.Is(err, userservice.ErrPasswordMismatch) {
 		return response.USER_PASSWORD_DIFFERENT
 	}
@@ -112,6 +193,12 @@ func mapServiceErrorToMsgCode(err error) response.MsgCode {
 	if errors.Is(err, userservice.ErrVerificationCodeIncorrect) {
 		return response.CAPTCHA_ERROR
 	}
+	if errors.Is(err, userservice.ErrVerificationCodeExpired) {
+		return response.CAPTCHA_EXPIRED
+	}
+	if errors.Is(err, userservice.ErrVerificationCodeTooFrequent) {
+		return response.CAPTCHA_TOO_FREQUENT
+	}
 
 	// 密码强度校验错误
 	if errors.Is(err, util.ErrPasswordTooShort) {
@@ -123,6 +210,37 @@ func mapServiceErrorToMsgCode(err error) response.MsgCode {
 	if errors.Is(err, util.ErrPasswordTooLong) {
 		return response.PARAM_NOT_VALID
 	}
+	if errors.Is(err, util.ErrPasswordTooCommon) {
+		return response.PARAM_NOT_VALID
+	}
+	if errors.Is(err, util.ErrUserNameInvalid) {
+		return response.USER_NAME_INVALID
+	}
+	if errors.Is(err, util.ErrPhoneInvalid) {
+		return response.PHONE_INVALID
+	}
+
+	// 图形验证码错误
+	if errors.Is(err, captchaservice.ErrCaptchaRequired) {
+		return response.IMAGE_CAPTCHA_REQUIRED
+	}
+	if errors.Is(err, captchaservice.ErrCaptchaIncorrect) {
+		return response.IMAGE_CAPTCHA_ERROR
+	}
+	if errors.Is(err, captchaservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
+
+	// 安全记录相关错误
+	if errors.Is(err, auditservice.ErrInvalidParams) {
+		return response.PARAM_NOT_VALID
+	}
+	if errors.Is(err, auditservice.ErrPermissionDenied) {
+		return response.INSUFFICENT_PERMISSIONS
+	}
+	if errors.Is(err, auditservice.ErrInternalError) {
+		return response.INTERNAL_ERROR
+	}
 
 	// COS相关错误
 	if errors.Is(err, cosservice.ErrInvalidParams) {
@@ -135,8 +253,8 @@ func mapServiceErrorToMsgCode(err error) response.MsgCode {
 		return response.INTERNAL_FILE_UPLOAD_ERROR
 	}
 
-	// 默认返回通用错误
-	return response.COMMON_FAIL
+	// 套餐配额错误，其余返回通用错误
+	return mapQuotaServiceErrorToMsgCode(err)
 }
 
 // Login
@@ -149,26 +267,101 @@ func Login() gin.HandlerFunc {
 		ctx := gCtx.Request.Context()
 
 		// 绑定JSON请求体
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.LoginResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.LoginResp{Success: false}) {
 			return
 		}
 
-		// TODO: cozeloop配置好后启用
-		// ctx, sp := loop.GetNewSpan(ctx, "login", constant.LoopSpanType_Root)
+		ctx, sp := loop.GetNewSpan(ctx, "login", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().Login(ctx, req)
-		// loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
+		loop.SetSpanAllInOne(ctx, sp, req, rsp, err)
 		zlog.CtxAllInOne(ctx, "login", req, rsp, err)
 
+		// cookie 鉴权模式下 token 写入 HttpOnly cookie，不再返回给前端
+		if err == nil && cookieAuth.Enabled() {
+			if cookieErr := cookieAuth.SetLoginCookies(gCtx, rsp.Token, rsp.RefreshToken); cookieErr != nil {
+				zlog.CtxErrorf(ctx, "set login cookies failed: %v", cookieErr)
+				handleHandlerResponse(gCtx, nil, userservice.ErrInternalError, def.LoginResp{Success: false})
+				return
+			}
+			rsp.Token, rsp.RefreshToken = "", ""
+		}
+
 		// 统一处理响应和错误
 		handleHandlerResponse(gCtx, rsp, err, def.LoginResp{Success: false})
 	}
 }
 
+// RefreshToken
+//
+//	@Description:[POST] /api/biz/v1/user/refresh_token
+//	@return gin.HandlerFunc
+func RefreshToken() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.RefreshTokenReq{}
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.RefreshTokenResp{Success: false},
+			})
+			return
+		}
+		if req.RefreshToken == "" {
+			req.RefreshToken = cookieAuth.RefreshTokenFromCookie(gCtx)
+		}
+
+		rsp, err := handler.GetHandler().RefreshToken(ctx, req)
+
+		// cookie 鉴权模式下新令牌写回 cookie，不返回给前端
+		if err == nil && cookieAuth.Enabled() {
+			if cookieErr := cookieAuth.SetLoginCookies(gCtx, rsp.Token, rsp.RefreshToken); cookieErr != nil {
+				zlog.CtxErrorf(ctx, "set refresh cookies failed: %v", cookieErr)
+				handleHandlerResponse(gCtx, nil, userservice.ErrInternalError, def.RefreshTokenResp{Success: false})
+				return
+			}
+			rsp.Token, rsp.RefreshToken = "", ""
+		}
+
+		handleHandlerResponse(gCtx, rsp, err, def.RefreshTokenResp{Success: false})
+	}
+}
+
+// Logout
+//
+//	@Description:[POST] /api/biz/v1/user/logout
+//	@return gin.HandlerFunc
+func Logout() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.LogoutReq{}
+		ctx := gCtx.Request.Context()
+
+		// 请求体可以为空（cookie 鉴权模式或不使用刷新令牌的客户端）
+		if err := gCtx.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.LogoutResp{Success: false},
+			})
+			return
+		}
+		if req.RefreshToken == "" {
+			req.RefreshToken = cookieAuth.RefreshTokenFromCookie(gCtx)
+		}
+		if claims := middleware.TokenClaims(gCtx); claims != nil {
+			req.TokenID = claims.ID
+			if claims.ExpiresAt != nil {
+				req.TokenExpiresAt = claims.ExpiresAt.Time
+			}
+		}
+
+		rsp, err := handler.GetHandler().Logout(ctx, req)
+		cookieAuth.ClearCookies(gCtx)
+		handleHandlerResponse(gCtx, rsp, err, def.LogoutResp{Success: false})
+	}
+}
+
 // Register
 //
 //	@Description:[POST] /api/biz/v1/user/register
@@ -178,12 +371,7 @@ func Register() gin.HandlerFunc {
 		req := &def.RegisterReq{}
 		// 统一从 gin 上下文取出 request 的 context，供后续业务调用使用
 		ctx := gCtx.Request.Context()
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.RegisterResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.RegisterResp{Success: false}) {
 			return
 		}
 
@@ -201,16 +389,20 @@ func SendCode() gin.HandlerFunc {
 		req := &def.SendVerificationCodeReq{}
 		// 统一从 gin 上下文取出 request 的 context
 		ctx := gCtx.Request.Context()
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.SendVerificationCodeResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.SendVerificationCodeResp{Success: false}) {
 			return
 		}
 
 		rsp, err := handler.GetHandler().SendCode(ctx, req)
+
+		// 发送过于频繁时告诉前端还需等待多久，用于展示倒计时
+		var limited *userservice.CodeRateLimitedError
+		if errors.As(err, &limited) {
+			retryAfter := int64(math.Ceil(limited.RetryAfter.Seconds()))
+			gCtx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			handleHandlerResponse(gCtx, nil, err, def.SendVerificationCodeResp{Success: false, RetryAfter: retryAfter})
+			return
+		}
 		handleHandlerResponse(gCtx, rsp, err, def.SendVerificationCodeResp{Success: false})
 	}
 }
@@ -224,12 +416,7 @@ func ResetPassword() gin.HandlerFunc {
 		req := &def.ResetPasswordReq{}
 		// 统一从 gin 上下文取出 request 的 context
 		ctx := gCtx.Request.Context()
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.ResetPasswordResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.ResetPasswordResp{Success: false}) {
 			return
 		}
 
@@ -247,12 +434,7 @@ func GetVersion() gin.HandlerFunc {
 		req := &def.GetVersionReq{}
 		// 统一从 gin 上下文取出 request 的 context
 		ctx := gCtx.Request.Context()
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.GetVersionResp{Version: "V0.0.1有bug"},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.GetVersionResp{Version: "V0.0.1有bug"}) {
 			return
 		}
 
@@ -270,7 +452,12 @@ func GetHome() gin.HandlerFunc {
 		ctx := gCtx.Request.Context()
 
 		rsp, err := handler.GetHandler().GetHome(ctx)
-		handleHandlerResponse(gCtx, rsp, err, def.GetHomeResp{})
+		if err != nil {
+			handleHandlerResponse(gCtx, rsp, err, def.GetHomeResp{})
+			return
+		}
+		// 前端轮询个人主页，内容未变化时返回 304
+		response.NewResponse(gCtx).SuccessWithETag(rsp)
 	}
 }
 
@@ -283,12 +470,7 @@ func UpdateAccount() gin.HandlerFunc {
 		req := &def.UpdateAccountReq{}
 		ctx := gCtx.Request.Context()
 
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.UpdateAccountResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UpdateAccountResp{Success: false}) {
 			return
 		}
 
@@ -306,12 +488,7 @@ func UnbindAccount() gin.HandlerFunc {
 		req := &def.UnbindAccountReq{}
 		ctx := gCtx.Request.Context()
 
-		if err := gCtx.ShouldBindJSON(req); err != nil {
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
-				Code:    response.INVALID_PARAMS.Code,
-				Message: response.INVALID_PARAMS.Msg,
-				Data:    def.UnbindAccountResp{Success: false},
-			})
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UnbindAccountResp{Success: false}) {
 			return
 		}
 
@@ -320,6 +497,54 @@ func UnbindAccount() gin.HandlerFunc {
 	}
 }
 
+// UpdateProfile
+//
+//	@Description:[PUT] /api/biz/v1/user/profile
+//	@return gin.HandlerFunc
+func UpdateProfile() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UpdateProfileReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UpdateProfileResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateProfile(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UpdateProfileResp{Success: false})
+	}
+}
+
+// DeleteAccount
+//
+//	@Description:[DELETE] /api/biz/v1/user/account
+//	@return gin.HandlerFunc
+func DeleteAccount() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.DeleteAccountReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.DeleteAccountResp{Success: false}) {
+			return
+		}
+		if req.RefreshToken == "" {
+			req.RefreshToken = cookieAuth.RefreshTokenFromCookie(gCtx)
+		}
+		if claims := middleware.TokenClaims(gCtx); claims != nil {
+			req.TokenID = claims.ID
+			if claims.ExpiresAt != nil {
+				req.TokenExpiresAt = claims.ExpiresAt.Time
+			}
+		}
+
+		rsp, err := handler.GetHandler().DeleteAccount(ctx, req)
+		if err == nil {
+			cookieAuth.ClearCookies(gCtx)
+		}
+		handleHandlerResponse(gCtx, rsp, err, def.DeleteAccountResp{Success: false})
+	}
+}
+
 // UpdateAvatar
 //
 //	@Description:[POST] /api/biz/v1/user/avatar
@@ -328,7 +553,7 @@ func UpdateAvatar() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		ctx := gCtx.Request.Context()
 
-		// 设置文件大小限制（8MB）
+		// 表单最多 8MB 保存在内存中，超出部分写入临时文件；请求体总大小由 BodyLimit 中间件限制
 		gCtx.Request.ParseMultipartForm(8 << 20)
 
 		// 接收文件
@@ -386,3 +611,322 @@ func UpdateAvatar() gin.HandlerFunc {
 		r.Success(rsp)
 	}
 }
+
+// UpdateNotificationPreference
+//
+//	@Description:[POST] /api/biz/v1/user/notification_preference
+//	@return gin.HandlerFunc
+func UpdateNotificationPreference() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UpdateNotificationPreferenceReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.UpdateNotificationPreferenceResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateNotificationPreference(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UpdateNotificationPreferenceResp{Success: false})
+	}
+}
+
+// UnsubscribeReminder
+//
+//	@Description:[GET] /api/biz/v1/user/unsubscribe?uid=&token=
+//	@return gin.HandlerFunc
+func UnsubscribeReminder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UnsubscribeReminderReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.Query, req, def.UnsubscribeReminderResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().UnsubscribeReminder(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UnsubscribeReminderResp{Success: false})
+	}
+}
+
+// OAuthAuthorize
+//
+//	@Description:[GET] /api/biz/v1/user/oauth/:provider/authorize
+//	@return gin.HandlerFunc
+func OAuthAuthorize() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.OAuthAuthorizeReq{Provider: gCtx.Param("provider")}
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().OAuthAuthorize(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.OAuthAuthorizeResp{Success: false})
+	}
+}
+
+// OAuthBindAuthorize
+//
+//	@Description:[GET] /api/biz/v1/user/oauth/:provider/bind_authorize
+//	@return gin.HandlerFunc
+func OAuthBindAuthorize() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.OAuthAuthorizeReq{Provider: gCtx.Param("provider")}
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().OAuthBindAuthorize(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.OAuthAuthorizeResp{Success: false})
+	}
+}
+
+// OAuthLogin
+//
+//	@Description:[POST] /api/biz/v1/user/oauth/:provider/login
+//	@return gin.HandlerFunc
+func OAuthLogin() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.OAuthLoginReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.LoginResp{Success: false}) {
+			return
+		}
+		req.Provider = gCtx.Param("provider")
+
+		ctx, sp := loop.GetNewSpan(ctx, "oauth_login", constant.LoopSpanType_Root)
+		rsp, err := handler.GetHandler().OAuthLogin(ctx, req)
+		loop.SetSpanAllInOne(ctx, sp, req.Provider, nil, err)
+
+		// 与账号密码登录一致，cookie 鉴权模式下 token 写入 HttpOnly cookie
+		if err == nil && cookieAuth.Enabled() {
+			if cookieErr := cookieAuth.SetLoginCookies(gCtx, rsp.Token, rsp.RefreshToken); cookieErr != nil {
+				zlog.CtxErrorf(ctx, "set login cookies failed: %v", cookieErr)
+				handleHandlerResponse(gCtx, nil, userservice.ErrInternalError, def.LoginResp{Success: false})
+				return
+			}
+			rsp.Token, rsp.RefreshToken = "", ""
+		}
+
+		handleHandlerResponse(gCtx, rsp, err, def.LoginResp{Success: false})
+	}
+}
+
+// GetCaptcha
+//
+//	@Description:[GET] /api/biz/v1/user/captcha
+//	@return gin.HandlerFunc
+func GetCaptcha() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().GetCaptcha(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.GetCaptchaResp{Success: false})
+	}
+}
+
+// SendMagicLink
+//
+//	@Description:[POST] /api/biz/v1/user/login/magic_link
+//	@return gin.HandlerFunc
+func SendMagicLink() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.SendMagicLinkReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.SendMagicLinkResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().SendMagicLink(ctx, req)
+
+		// 与发送验证码共用频率限制，同样返回需要等待的时间
+		var limited *userservice.CodeRateLimitedError
+		if errors.As(err, &limited) {
+			retryAfter := int64(math.Ceil(limited.RetryAfter.Seconds()))
+			gCtx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			handleHandlerResponse(gCtx, nil, err, def.SendMagicLinkResp{Success: false, RetryAfter: retryAfter})
+			return
+		}
+		handleHandlerResponse(gCtx, rsp, err, def.SendMagicLinkResp{Success: false})
+	}
+}
+
+// MagicLinkLogin
+//
+//	@Description:[GET] /api/biz/v1/user/login/magic_link/verify?token=
+//	@return gin.HandlerFunc
+func MagicLinkLogin() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.MagicLinkLoginReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.Query, req, def.LoginResp{Success: false}) {
+			return
+		}
+
+		ctx, sp := loop.GetNewSpan(ctx, "magic_link_login", constant.LoopSpanType_Root)
+		rsp, err := handler.GetHandler().MagicLinkLogin(ctx, req)
+		loop.SetSpanAllInOne(ctx, sp, nil, nil, err)
+
+		// 与账号密码登录一致，cookie 鉴权模式下 token 写入 HttpOnly cookie
+		if err == nil && cookieAuth.Enabled() {
+			if cookieErr := cookieAuth.SetLoginCookies(gCtx, rsp.Token, rsp.RefreshToken); cookieErr != nil {
+				zlog.CtxErrorf(ctx, "set login cookies failed: %v", cookieErr)
+				handleHandlerResponse(gCtx, nil, userservice.ErrInternalError, def.LoginResp{Success: false})
+				return
+			}
+			rsp.Token, rsp.RefreshToken = "", ""
+		}
+
+		handleHandlerResponse(gCtx, rsp, err, def.LoginResp{Success: false})
+	}
+}
+
+// BindOAuth
+//
+//	@Description:[POST] /api/biz/v1/user/oauth/:provider/bind
+//	@return gin.HandlerFunc
+func BindOAuth() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.BindOAuthReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.BindOAuthResp{Success: false}) {
+			return
+		}
+		req.Provider = gCtx.Param("provider")
+
+		rsp, err := handler.GetHandler().BindOAuth(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.BindOAuthResp{Success: false})
+	}
+}
+
+// UnbindOAuth
+//
+//	@Description:[DELETE] /api/biz/v1/user/oauth/:provider
+//	@return gin.HandlerFunc
+func UnbindOAuth() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.UnbindOAuthReq{Provider: gCtx.Param("provider")}
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().UnbindOAuth(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.UnbindOAuthResp{Success: false})
+	}
+}
+
+// ListOAuthBindings
+//
+//	@Description:[GET] /api/biz/v1/user/oauth/bindings
+//	@return gin.HandlerFunc
+func ListOAuthBindings() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListOAuthBindings(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.ListOAuthBindingsResp{Success: false})
+	}
+}
+
+// ListSessions
+//
+//	@Description:[GET] /api/biz/v1/user/sessions
+//	@return gin.HandlerFunc
+func ListSessions() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListSessionsReq{}
+		ctx := gCtx.Request.Context()
+		if claims := middleware.TokenClaims(gCtx); claims != nil {
+			req.CurrentSessionID = claims.SessionID
+		}
+
+		rsp, err := handler.GetHandler().ListSessions(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.ListSessionsResp{Success: false})
+	}
+}
+
+// RevokeSession
+//
+//	@Description:[DELETE] /api/biz/v1/user/sessions/:id
+//	@return gin.HandlerFunc
+func RevokeSession() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.RevokeSessionReq{SessionID: gCtx.Param("id")}
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().RevokeSession(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.RevokeSessionResp{Success: false})
+	}
+}
+
+// MergeAccount
+//
+//	@Description:[POST] /api/biz/v1/user/account/merge
+//	@return gin.HandlerFunc
+func MergeAccount() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.MergeAccountReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.JSON, req, def.MergeAccountResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().MergeAccount(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.MergeAccountResp{Success: false})
+	}
+}
+
+// CreateInviteCode
+//
+//	@Description:[POST] /api/biz/v1/user/invite/codes
+//	@return gin.HandlerFunc
+func CreateInviteCode() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().CreateInviteCode(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.CreateInviteCodeResp{Success: false})
+	}
+}
+
+// ListInviteCodes
+//
+//	@Description:[GET] /api/biz/v1/user/invite/codes
+//	@return gin.HandlerFunc
+func ListInviteCodes() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListInviteCodes(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.ListInviteCodesResp{Success: false})
+	}
+}
+
+// ListReferrals
+//
+//	@Description:[GET] /api/biz/v1/user/invite/referrals
+//	@return gin.HandlerFunc
+func ListReferrals() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListReferrals(ctx)
+		handleHandlerResponse(gCtx, rsp, err, def.ListReferralsResp{Success: false})
+	}
+}
+
+// ListAuditLogs
+//
+//	@Description:[GET] /api/biz/v1/user/security/audit?page=&page_size=
+//	@return gin.HandlerFunc
+func ListAuditLogs() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListAuditLogsReq{}
+		ctx := gCtx.Request.Context()
+
+		if !bindAndValidate(gCtx, binding.Query, req, def.ListAuditLogsResp{Success: false}) {
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListAuditLogs(ctx, req)
+		handleHandlerResponse(gCtx, rsp, err, def.ListAuditLogsResp{Success: false})
+	}
+}
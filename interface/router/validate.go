@@ -0,0 +1,116 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// registerValidatorTagName 校验错误中的字段名使用请求中的名称（json 或 form 标签），而不是 Go 结构体字段名
+func registerValidatorTagName() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// bindAndValidate 按 b 绑定请求参数（binding.JSON、binding.Query 等）并按 def 中的 binding 标签校验
+// 请求体无法解析时返回 INVALID_PARAMS，校验不通过时返回 PARAM_NOT_VALID 并在 Errors 中列出各字段的错误
+// 失败时已写入响应，返回 false，调用方直接 return
+func bindAndValidate(gCtx *gin.Context, b binding.Binding, req any, emptyResp any) bool {
+	err := gCtx.ShouldBindWith(req, b)
+	if err == nil {
+		return true
+	}
+
+	result := response.JsonMsgResult{
+		Code:    response.INVALID_PARAMS.Code,
+		Message: response.INVALID_PARAMS.Msg,
+		Data:    emptyResp,
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		result.Code = response.PARAM_NOT_VALID.Code
+		result.Message = response.PARAM_NOT_VALID.Msg
+		result.Errors = make([]response.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			result.Errors = append(result.Errors, response.FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: fieldErrorMessage(fe),
+			})
+		}
+	}
+	zlog.CtxWarnf(gCtx.Request.Context(), "bind request failed, %s %s: %v", gCtx.Request.Method, gCtx.FullPath(), err)
+	gCtx.JSON(http.StatusOK, result)
+	return false
+}
+
+// fieldPath 去掉结构体名，嵌套字段保留路径，如 images[0].url
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+// fieldErrorMessage 常用校验规则的中文提示，其余规则统一提示格式不正确
+func fieldErrorMessage(fe validator.FieldError) string {
+	// 字符串、切片的 min/max/len 限制的是长度，数字限制的是取值
+	sized := false
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		sized = true
+	}
+	switch fe.Tag() {
+	case "required", "required_if", "required_with", "required_without":
+		return "不能为空"
+	case "email":
+		return "邮箱格式不正确"
+	case "url", "http_url":
+		return "链接格式不正确"
+	case "oneof":
+		return fmt.Sprintf("取值必须为 %s 之一", strings.Join(strings.Fields(fe.Param()), "、"))
+	case "min", "gte":
+		if sized {
+			return fmt.Sprintf("长度不能少于 %s", fe.Param())
+		}
+		return fmt.Sprintf("不能小于 %s", fe.Param())
+	case "max", "lte":
+		if sized {
+			return fmt.Sprintf("长度不能超过 %s", fe.Param())
+		}
+		return fmt.Sprintf("不能大于 %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("长度必须为 %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("必须大于 %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("必须小于 %s", fe.Param())
+	case "datetime":
+		return fmt.Sprintf("时间格式应为 %s", fe.Param())
+	}
+	return "格式不正确"
+}
@@ -0,0 +1,196 @@
+package docparse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"forge/pkg/log/zlog"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedType = errors.New("不支持的文件格式，仅支持 PDF、DOCX、PPTX、Markdown、TXT 与 SRT/VTT 字幕")
+	ErrCorruptedFile   = errors.New("文件已损坏或内容与扩展名不符")
+	ErrFileTooLarge    = errors.New("文件过大")
+	ErrTooManyPages    = errors.New("文件页数过多")
+	ErrEmptyDocument   = errors.New("未能从文件中提取到文字")
+)
+
+// Type 文档类型
+type Type string
+
+const (
+	TypePDF      Type = "pdf"
+	TypeDOCX     Type = "docx"
+	TypePPTX     Type = "pptx"
+	TypeMarkdown Type = "markdown"
+	TypeText     Type = "text"
+	TypeSubtitle Type = "subtitle"
+)
+
+// sniffLength 嗅探文件类型时读取的文件头长度
+const sniffLength = 512
+
+var (
+	pdfSignature = []byte("%PDF-")
+	zipSignature = []byte("PK\x03\x04")
+)
+
+// extTypes 扩展名对应的文档类型；旧版 .doc/.ppt 为二进制格式，无法解析
+var extTypes = map[string]Type{
+	".pdf":      TypePDF,
+	".docx":     TypeDOCX,
+	".pptx":     TypePPTX,
+	".md":       TypeMarkdown,
+	".markdown": TypeMarkdown,
+	".txt":      TypeText,
+	".srt":      TypeSubtitle,
+	".vtt":      TypeSubtitle,
+}
+
+// File 解析器读取的文件，multipart.File 满足该接口
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// Parser 按文档类型提取正文
+type Parser interface {
+	Parse(f File, size int64, limits Limits) (string, error)
+}
+
+var parsers = map[Type]Parser{
+	TypePDF:      pdfParser{},
+	TypeDOCX:     docxParser{},
+	TypePPTX:     pptxParser{},
+	TypeMarkdown: markdownParser{},
+	TypeText:     textParser{},
+	TypeSubtitle: subtitleParser{},
+}
+
+// Limits 解析限制，0 表示不限制
+type Limits struct {
+	MaxFileSize   int64 // 文件大小上限（字节）
+	MaxPages      int   // PDF 页数、PPTX 幻灯片数上限
+	MaxTextLength int   // 提取出的正文最大字符数，超出部分截断
+}
+
+// Extract 识别上传文件的类型并提取正文
+// 类型以扩展名为准，并用文件头校验内容；没有可识别的扩展名时按文件头嗅探
+func Extract(ctx context.Context, fh *multipart.FileHeader, limits Limits) (text string, err error) {
+	if limits.MaxFileSize > 0 && fh.Size > limits.MaxFileSize {
+		zlog.CtxWarnf(ctx, "file %s too large: %d bytes", fh.Filename, fh.Size)
+		return "", ErrFileTooLarge
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, head)
+	if n == 0 {
+		if err == io.EOF {
+			return "", ErrEmptyDocument
+		}
+		return "", err
+	}
+	docType, err := detectType(fh.Filename, head[:n])
+	if err != nil {
+		zlog.CtxWarnf(ctx, "detect type of file %s failed: %v", fh.Filename, err)
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	text, err = parse(parsers[docType], f, fh.Size, limits)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "parse %s file %s failed: %v", docType, fh.Filename, err)
+		return "", err
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmptyDocument
+	}
+	if limits.MaxTextLength > 0 && utf8.RuneCountInString(text) > limits.MaxTextLength {
+		zlog.CtxInfof(ctx, "text of file %s truncated to %d characters", fh.Filename, limits.MaxTextLength)
+		text = string([]rune(text)[:limits.MaxTextLength])
+	}
+	return text, nil
+}
+
+// parse 调用解析器，第三方库的错误与 panic 都视为文件损坏
+func parse(parser Parser, f File, size int64, limits Limits) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrCorruptedFile, r)
+		}
+	}()
+
+	text, err = parser.Parse(f, size, limits)
+	if err != nil && !errors.Is(err, ErrTooManyPages) && !errors.Is(err, ErrCorruptedFile) {
+		err = fmt.Errorf("%w: %v", ErrCorruptedFile, err)
+	}
+	return text, err
+}
+
+// detectType 根据扩展名与文件头判断文档类型
+func detectType(filename string, head []byte) (Type, error) {
+	docType, ok := extTypes[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return sniffType(filename, head)
+	}
+
+	var valid bool
+	switch docType {
+	case TypePDF:
+		valid = bytes.Contains(head, pdfSignature)
+	case TypeDOCX, TypePPTX:
+		valid = bytes.HasPrefix(head, zipSignature)
+	default:
+		valid = isUTF8Text(head)
+	}
+	if !valid {
+		return "", ErrCorruptedFile
+	}
+	return docType, nil
+}
+
+// sniffType 没有可识别的扩展名时按文件头判断，只能区分 PDF 与纯文本
+func sniffType(filename string, head []byte) (Type, error) {
+	// 有扩展名但不在支持列表中（如 .doc、.xlsx）时直接拒绝
+	if filepath.Ext(filename) != "" {
+		return "", ErrUnsupportedType
+	}
+	if bytes.HasPrefix(head, pdfSignature) {
+		return TypePDF, nil
+	}
+	if isUTF8Text(head) {
+		return TypeText, nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// isUTF8Text 判断文件头是否为 UTF-8 文本
+func isUTF8Text(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	// 文件头可能截断在多字节字符中间
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	return utf8.Valid(head)
+}
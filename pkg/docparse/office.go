@@ -0,0 +1,108 @@
+package docparse
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/presentation"
+	"github.com/unidoc/unipdf/v4/extractor"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// todo
+// 获取许可
+// license.SetMeteredKey
+// "github.com/unidoc/unioffice/v2/common/license"
+
+var errEncryptedPDF = errors.New("encrypted pdf")
+
+type pdfParser struct{}
+
+func (pdfParser) Parse(f File, _ int64, limits Limits) (string, error) {
+	pdfReader, err := model.NewPdfReader(f)
+	if err != nil {
+		return "", err
+	}
+
+	// 只支持无打开密码的加密文件
+	encrypted, err := pdfReader.IsEncrypted()
+	if err != nil {
+		return "", err
+	}
+	if encrypted {
+		ok, err := pdfReader.Decrypt(nil)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", errEncryptedPDF
+		}
+	}
+
+	numPages, err := pdfReader.GetNumPages()
+	if err != nil {
+		return "", err
+	}
+	if limits.MaxPages > 0 && numPages > limits.MaxPages {
+		return "", ErrTooManyPages
+	}
+
+	var textBuilder strings.Builder
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		page, err := pdfReader.GetPage(pageNum) //文本操作对象
+		if err != nil {
+			return "", err
+		}
+
+		ex, err := extractor.New(page)
+		if err != nil {
+			return "", err
+		}
+
+		text, err := ex.ExtractText() //文本
+		if err != nil {
+			return "", err
+		}
+		//拼接
+		textBuilder.WriteString(text)
+		textBuilder.WriteString("\n")
+	}
+	return textBuilder.String(), nil
+}
+
+type docxParser struct{}
+
+func (docxParser) Parse(f File, size int64, _ Limits) (string, error) {
+	doc, err := document.Read(f, size) //word文件对象
+	if err != nil {
+		return "", err
+	}
+
+	var allText strings.Builder
+	for _, item := range doc.ExtractText().Items {
+		allText.WriteString(item.Text)
+	}
+	return allText.String(), nil
+}
+
+type pptxParser struct{}
+
+func (pptxParser) Parse(f File, size int64, limits Limits) (string, error) {
+	ppt, err := presentation.Read(f, size)
+	if err != nil {
+		return "", err
+	}
+	if limits.MaxPages > 0 && len(ppt.Slides()) > limits.MaxPages {
+		return "", ErrTooManyPages
+	}
+
+	var allText strings.Builder
+	for _, slide := range ppt.ExtractText().Slides { //每个  slide  代表一张幻灯片
+		for _, item := range slide.Items { //当前这页ppt中的文本项的列表
+			allText.WriteString(item.Text)
+			allText.WriteString("\n")
+		}
+	}
+	return allText.String(), nil
+}
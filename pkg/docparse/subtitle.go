@@ -0,0 +1,99 @@
+package docparse
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+var (
+	// 时间轴行：00:00:01,000 --> 00:00:04,000 或 00:01.000 --> 00:04.000 position:10%
+	subtitleTimingRe = regexp.MustCompile(`^\s*(\d{1,2}:)?\d{1,2}:\d{2}[.,]\d{1,3}\s*-->`)
+	// 行内标签：<i> </b> <c.yellow> <00:00:01.000> 以及 {\an8} 这类 ASS 样式
+	subtitleTagRe = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	// 纯数字行，下一行是时间轴时才是序号
+	subtitleIndexRe = regexp.MustCompile(`^\d+$`)
+	// WEBVTT 头与 NOTE/STYLE/REGION 块首行的关键字
+	vttBlockKeywords = []string{"WEBVTT", "NOTE", "STYLE", "REGION"}
+)
+
+// subtitleParser 提取 SRT/VTT 字幕的台词，去掉序号、时间轴与样式标签
+type subtitleParser struct{}
+
+func (subtitleParser) Parse(f File, _ int64, _ Limits) (string, error) {
+	var (
+		textBuilder strings.Builder
+		lastLine    string
+		pending     string // 纯数字行，确认不是序号后再作为台词输出
+		blockStart  = true
+		skipBlock   bool // WEBVTT 头、NOTE/STYLE/REGION 块整体跳过，直到空行
+	)
+
+	emit := func(line string) {
+		line = strings.TrimSpace(subtitleTagRe.ReplaceAllString(line, ""))
+		// 自动字幕经常在相邻字幕块中重复同一行
+		if line == "" || line == lastLine {
+			return
+		}
+		lastLine = line
+
+		textBuilder.WriteString(line)
+		textBuilder.WriteString("\n")
+	}
+	flush := func() {
+		if pending != "" {
+			emit(pending)
+			pending = ""
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		if line == "" {
+			flush()
+			skipBlock = false
+			blockStart = true
+			continue
+		}
+		if skipBlock {
+			continue
+		}
+		if blockStart && isVTTBlockHeader(line) {
+			skipBlock = true
+			continue
+		}
+		blockStart = false
+
+		// 时间轴行不属于正文，其前的纯数字行是序号
+		if subtitleTimingRe.MatchString(line) {
+			pending = ""
+			continue
+		}
+		flush()
+		if subtitleIndexRe.MatchString(line) {
+			pending = line
+			continue
+		}
+		emit(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	flush()
+
+	return textBuilder.String(), nil
+}
+
+// isVTTBlockHeader 块首行为关键字本身，或关键字后跟空白时整块跳过；NOTEBOOK 这类台词不受影响
+func isVTTBlockHeader(line string) bool {
+	for _, keyword := range vttBlockKeywords {
+		rest, ok := strings.CutPrefix(line, keyword)
+		if ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return true
+		}
+	}
+	return false
+}
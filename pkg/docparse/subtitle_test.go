@@ -0,0 +1,46 @@
+package docparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubtitleParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "SRT 序号与时间轴",
+			input: "1\n00:00:01,000 --> 00:00:02,000\n<i>你好</i>\n\n2\n00:00:02,000 --> 00:00:03,000\n你好\n世界\n",
+			want:  "你好\n世界\n",
+		},
+		{
+			name:  "台词是纯数字",
+			input: "1\n00:00:01,000 --> 00:00:02,000\n倒数\n\n2\n00:00:02,000 --> 00:00:03,000\n3\n\n3\n00:00:03,000 --> 00:00:04,000\n2\n1\n",
+			want:  "倒数\n3\n2\n1\n",
+		},
+		{
+			name:  "VTT 头与注释块",
+			input: "WEBVTT - 标题\n\nNOTE 这是注释\n第二行注释\n\nSTYLE\n::cue { color: red }\n\n00:01.000 --> 00:02.000 position:10%\n台词\n",
+			want:  "台词\n",
+		},
+		{
+			name:  "台词以关键字开头",
+			input: "WEBVTT\n\n00:01.000 --> 00:02.000\nNOTEBOOK 在桌上\nSTYLES 很多\n\n00:02.000 --> 00:03.000\nNOTE 不在块首行\n",
+			want:  "NOTEBOOK 在桌上\nSTYLES 很多\nNOTE 不在块首行\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := subtitleParser{}.Parse(strings.NewReader(tt.input), 0, Limits{})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package docparse
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// 文件开头的 YAML front matter
+	markdownFrontMatterRe = regexp.MustCompile(`(?s)\A---\r?\n.*?\r?\n---\r?\n`)
+	// 图片 ![alt](url) 保留替代文字
+	markdownImageRe = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	// 链接 [text](url) 保留链接文字
+	markdownLinkRe = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// HTML 注释与标签
+	markdownHTMLRe = regexp.MustCompile(`(?s)<!--.*?-->|</?[a-zA-Z][^>]*>`)
+)
+
+// textParser 纯文本按原样读取
+type textParser struct{}
+
+func (textParser) Parse(f File, _ int64, _ Limits) (string, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(string(data), "\ufeff"), nil
+}
+
+// markdownParser 保留标题与列表等层级标记，便于模型据此划分导图层级；去掉链接地址、图片与 HTML
+type markdownParser struct{}
+
+func (markdownParser) Parse(f File, size int64, limits Limits) (string, error) {
+	text, err := textParser{}.Parse(f, size, limits)
+	if err != nil {
+		return "", err
+	}
+	text = markdownFrontMatterRe.ReplaceAllString(text, "")
+	text = markdownImageRe.ReplaceAllString(text, "$1")
+	text = markdownLinkRe.ReplaceAllString(text, "$1")
+	text = markdownHTMLRe.ReplaceAllString(text, "")
+	return text, nil
+}
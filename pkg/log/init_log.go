@@ -3,9 +3,28 @@ package log
 import (
 	"forge/infra/configs"
 	"forge/pkg/log/zlog"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// level 日志的最低级别，配置热更新时修改，不需要重建 logger
+	level          = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	registerReload sync.Once
 )
 
 func InitLog(path string, config configs.IConfig) {
+	level.SetLevel(zapcore.Level(config.GetLoggerConfig().Level))
 	logger := GetZap(path, config)
 	zlog.InitLogger(logger)
+	registerReload.Do(func() {
+		configs.OnReload(func(config configs.IConfig) {
+			if l := zapcore.Level(config.GetLoggerConfig().Level); l != level.Level() {
+				zlog.Warnf("日志级别修改为 %s", l)
+				level.SetLevel(l)
+			}
+		})
+	})
 }
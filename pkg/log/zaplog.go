@@ -23,7 +23,7 @@ func GetZap(path string, config configs.IConfig) *zap.Logger {
 		fileInfoCore := newZapConfig().
 			setEncoder(false, zapcore.NewConsoleEncoder).
 			setFileWriteSyncer(path + config.GetAppConfig().LogfilePath + "info.log").
-			setLevelEnabler(zapcore.DebugLevel).
+			setLevelEnabler(level).
 			getCore()
 		//本开发模式旨在将error及以上的log记录在文件中，方便查看
 		fileErrorCore := newZapConfig().
@@ -37,7 +37,7 @@ func GetZap(path string, config configs.IConfig) *zap.Logger {
 		consoleInfoCore := newZapConfig().
 			setEncoder(true, zapcore.NewConsoleEncoder).
 			setStdOutWriteSyncer().
-			setLevelEnabler(zapcore.DebugLevel).
+			setLevelEnabler(level).
 			getCore()
 		cores = append(cores, consoleInfoCore)
 	default:
@@ -45,7 +45,7 @@ func GetZap(path string, config configs.IConfig) *zap.Logger {
 		consoleInfoCore := newZapConfig().
 			setEncoder(true, zapcore.NewConsoleEncoder).
 			setStdOutWriteSyncer().
-			setLevelEnabler(zapcore.DebugLevel).
+			setLevelEnabler(level).
 			getCore()
 		cores = append(cores, consoleInfoCore)
 
@@ -117,10 +117,10 @@ func (z *zapConfig) setStdOutWriteSyncer() *zapConfig {
 	z.writeSyncerSlice = append(z.writeSyncerSlice, zapcore.AddSync(os.Stdout))
 	return z
 }
-func (z *zapConfig) setLevelEnabler(enabler zapcore.Level) *zapConfig {
-	z.levelEnabler = zap.LevelEnablerFunc(func(lev zapcore.Level) bool { //error级别
-		return lev >= enabler
-	})
+
+// 固定级别传 zapcore.Level，跟随 log.level 配置的传 level
+func (z *zapConfig) setLevelEnabler(enabler zapcore.LevelEnabler) *zapConfig {
+	z.levelEnabler = enabler
 	return z
 }
 
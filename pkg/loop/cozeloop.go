@@ -4,25 +4,53 @@ import (
 	"context"
 	"forge/biz/entity"
 	"forge/constant"
+	"forge/infra/configs"
 	"forge/pkg/log/zlog"
 
 	cozeloop "github.com/coze-dev/cozeloop-go"
 )
 
-var client cozeloop.Client
+var (
+	client cozeloop.Client
+	// enabled 未开启时所有打点函数都是空操作，不会调用 client
+	enabled bool
+)
+
+// MustInitLoop 根据配置初始化 cozeloop 客户端，未开启时直接返回
+// ref: https://github.com/coze-dev/cozeloop-go
+func MustInitLoop(cfg configs.LoopConfig) {
+	if !cfg.Enable {
+		zlog.Infof("cozeloop disabled, span tracing is noop")
+		return
+	}
 
-func MustInitLoop() {
-	// todo需要初始化
-	// ref: https://github.com/coze-dev/cozeloop-go
-	//_client, err := cozeloop.NewClient()
-	//if err != nil {
-	//	panic(err)
-	//}
-	//client = _client
+	opts := []cozeloop.Option{
+		cozeloop.WithAPIToken(cfg.APIToken),
+		cozeloop.WithWorkspaceID(cfg.WorkspaceID),
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, cozeloop.WithAPIBaseURL(cfg.BaseURL))
+	}
+	_client, err := cozeloop.NewClient(opts...)
+	if err != nil {
+		panic(err)
+	}
+	client = _client
+	enabled = true
+}
 
+// Close 退出前上报剩余的 span
+func Close(ctx context.Context) {
+	if !enabled {
+		return
+	}
+	client.Close(ctx)
 }
 
 func GetNewSpan(ctx context.Context, spanName string, spanType constant.LoopSpanType, opts ...cozeloop.StartSpanOption) (sCtx context.Context, sp cozeloop.Span) {
+	if !enabled {
+		return ctx, cozeloop.DefaultNoopSpan
+	}
 	// 集成业务信息，可扩展
 	defer func() {
 		user, ok := entity.GetUser(ctx)
@@ -43,7 +71,7 @@ func SetSpanAllInOne(ctx context.Context, sp cozeloop.Span, input, output any, e
 	sp.SetOutput(ctx, output)
 	sp.SetError(ctx, err)
 	sp.Finish(ctx)
-	client.Flush(ctx) // todo这样会有性能问题，但是我们量级太小了无所谓
+	flush(ctx)
 }
 func SetSpanInput(ctx context.Context, sp cozeloop.Span, input any) {
 	sp.SetInput(ctx, input)
@@ -53,9 +81,16 @@ func SetSpanOutput(ctx context.Context, sp cozeloop.Span, output any) {
 }
 func SetSpanFinish(ctx context.Context, sp cozeloop.Span) {
 	sp.Finish(ctx)
-	client.Flush(ctx) // todo这样会有性能问题，但是我们量级太小了无所谓
+	flush(ctx)
 }
 func SetSpanInputWithTags(ctx context.Context, sp cozeloop.Span, input any, tags map[string]interface{}) {
 	sp.SetInput(ctx, input)
 	sp.SetTags(ctx, tags)
 }
+
+func flush(ctx context.Context) {
+	if !enabled {
+		return
+	}
+	client.Flush(ctx) // todo这样会有性能问题，但是我们量级太小了无所谓
+}
@@ -0,0 +1,200 @@
+package mindmapconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxXMindEntrySize .xmind 中内容文件解压后的大小上限，防止压缩炸弹
+const maxXMindEntrySize = 16 << 20
+
+// FormatOf 按文件扩展名判断导入格式，无法识别时返回空字符串
+func FormatOf(fileName string) string {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".xmind":
+		return FormatXMind
+	case ".mm":
+		return FormatFreeMind
+	case ".opml":
+		return FormatOPML
+	case ".md", ".markdown", ".txt":
+		return FormatMarkdown
+	}
+	return ""
+}
+
+// Import 解析 xmind、mm、opml 文件，节点总数超过 maxNodes 时返回 ErrTooManyNodes
+// Markdown 大纲由调用方按纯文本大纲解析
+func Import(format string, data []byte, maxNodes int) (Node, error) {
+	var (
+		root Node
+		err  error
+	)
+	switch format {
+	case FormatXMind:
+		root, err = fromXMind(data)
+	case FormatFreeMind:
+		root, err = fromFreeMind(data)
+	case FormatOPML:
+		root, err = fromOPML(data)
+	default:
+		return Node{}, ErrUnsupportedImport
+	}
+	if err != nil {
+		return Node{}, err
+	}
+	if root.Text == "" && len(root.Children) == 0 {
+		return Node{}, ErrEmptyMap
+	}
+	if countNodes(root) > maxNodes {
+		return Node{}, ErrTooManyNodes
+	}
+	return root, nil
+}
+
+func countNodes(n Node) int {
+	count := 1
+	for _, child := range n.Children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// fromXMind 优先读取新版的 content.json，没有时读取 XMind 8 的 content.xml，只导入第一个画布
+func fromXMind(data []byte) (Node, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Node{}, ErrInvalidFile
+	}
+	if content, ok, err := readZipEntry(zr, "content.json"); ok {
+		if err != nil {
+			return Node{}, err
+		}
+		var sheets []xmindSheet
+		if err := json.Unmarshal(content, &sheets); err != nil || len(sheets) == 0 {
+			return Node{}, ErrInvalidFile
+		}
+		return fromXMindTopic(sheets[0].RootTopic), nil
+	}
+	if content, ok, err := readZipEntry(zr, "content.xml"); ok {
+		if err != nil {
+			return Node{}, err
+		}
+		var doc xmindLegacyContent
+		if err := xml.Unmarshal(content, &doc); err != nil || len(doc.Sheets) == 0 {
+			return Node{}, ErrInvalidFile
+		}
+		return fromXMindLegacyTopic(doc.Sheets[0].Topic), nil
+	}
+	return Node{}, ErrInvalidFile
+}
+
+func readZipEntry(zr *zip.Reader, name string) ([]byte, bool, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, true, ErrInvalidFile
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, maxXMindEntrySize+1))
+		if err != nil {
+			return nil, true, ErrInvalidFile
+		}
+		if len(content) > maxXMindEntrySize {
+			return nil, true, ErrTooManyNodes
+		}
+		return content, true, nil
+	}
+	return nil, false, nil
+}
+
+func fromXMindTopic(t xmindTopic) Node {
+	node := Node{Text: strings.TrimSpace(t.Title)}
+	if t.Children != nil {
+		for _, child := range t.Children.Attached {
+			node.Children = append(node.Children, fromXMindTopic(child))
+		}
+	}
+	return node
+}
+
+// xmindLegacyContent XMind 8 的 content.xml
+type xmindLegacyContent struct {
+	Sheets []struct {
+		Topic xmindLegacyTopic `xml:"topic"`
+	} `xml:"sheet"`
+}
+
+type xmindLegacyTopic struct {
+	Title  string `xml:"title"`
+	Topics []struct {
+		Type   string             `xml:"type,attr"`
+		Topics []xmindLegacyTopic `xml:"topic"`
+	} `xml:"children>topics"`
+}
+
+func fromXMindLegacyTopic(t xmindLegacyTopic) Node {
+	node := Node{Text: strings.TrimSpace(t.Title)}
+	for _, group := range t.Topics {
+		// 只导入主干上的子主题，不导入自由主题与概要
+		if group.Type != "attached" {
+			continue
+		}
+		for _, child := range group.Topics {
+			node.Children = append(node.Children, fromXMindLegacyTopic(child))
+		}
+	}
+	return node
+}
+
+func fromFreeMind(data []byte) (Node, error) {
+	var doc freeMindMap
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return Node{}, ErrInvalidFile
+	}
+	return fromFreeMindNode(doc.Root), nil
+}
+
+func fromFreeMindNode(n freeMindNode) Node {
+	node := Node{Text: strings.TrimSpace(n.Text)}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, fromFreeMindNode(child))
+	}
+	return node
+}
+
+// fromOPML 只有一个顶层 outline 时它就是根节点，否则以文档标题作为根节点
+func fromOPML(data []byte) (Node, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return Node{}, ErrInvalidFile
+	}
+	if len(doc.Body.Outlines) == 1 {
+		return fromOPMLOutline(doc.Body.Outlines[0]), nil
+	}
+	root := Node{Text: strings.TrimSpace(doc.Head.Title)}
+	for _, outline := range doc.Body.Outlines {
+		root.Children = append(root.Children, fromOPMLOutline(outline))
+	}
+	return root, nil
+}
+
+func fromOPMLOutline(o opmlOutline) Node {
+	text := o.Text
+	if text == "" {
+		text = o.Title
+	}
+	node := Node{Text: strings.TrimSpace(text)}
+	for _, child := range o.Children {
+		node.Children = append(node.Children, fromOPMLOutline(child))
+	}
+	return node
+}
@@ -0,0 +1,133 @@
+package mindmapconv
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// 图片渲染的尺寸参数，单位为像素
+const (
+	fontSize      = 16
+	lineHeight    = 22
+	nodePadX      = 12
+	nodePadY      = 6
+	levelGap      = 40 // 父子节点之间的水平间距
+	siblingGap    = 12 // 兄弟子树之间的垂直间距
+	canvasMargin  = 24
+	maxLabelRunes = 40    // 超出的节点文字截断显示
+	maxCanvasSide = 16000 // 画布任一边超出时拒绝渲染
+)
+
+// box 布局后的节点
+type box struct {
+	label    string
+	x, y     int // 左上角
+	w, h     int
+	depth    int
+	children []*box
+}
+
+// layout 从左到右的树形布局：子节点在父节点右侧纵向排列，父节点在子树范围内垂直居中
+// measure 返回文字的像素宽度，返回根节点与画布尺寸
+func layout(root Node, measure func(string) int) (*box, int, int, error) {
+	b := buildBox(root, measure, 0)
+	height := placeBox(b, canvasMargin, canvasMargin)
+	width := rightmost(b) + canvasMargin
+	height += 2 * canvasMargin
+	if width > maxCanvasSide || height > maxCanvasSide {
+		return nil, 0, 0, ErrImageTooLarge
+	}
+	return b, width, height, nil
+}
+
+func buildBox(n Node, measure func(string) int, depth int) *box {
+	label := nodeLabel(n.Text)
+	b := &box{
+		label: label,
+		w:     measure(label) + 2*nodePadX,
+		h:     lineHeight + 2*nodePadY,
+		depth: depth,
+	}
+	for _, child := range n.Children {
+		b.children = append(b.children, buildBox(child, measure, depth+1))
+	}
+	return b
+}
+
+// placeBox 以 (x, top) 为子树左上角放置节点，返回子树占用的高度
+func placeBox(b *box, x, top int) int {
+	b.x = x
+	if len(b.children) == 0 {
+		b.y = top
+		return b.h
+	}
+	childX := x + b.w + levelGap
+	span := 0
+	for i, child := range b.children {
+		if i > 0 {
+			span += siblingGap
+		}
+		span += placeBox(child, childX, top+span)
+	}
+	if span < b.h {
+		// 子树比节点本身还矮时整体下移，使子节点与父节点居中对齐
+		offset := (b.h - span) / 2
+		for _, child := range b.children {
+			shiftBox(child, offset)
+		}
+		span = b.h
+	}
+	b.y = top + (span-b.h)/2
+	return span
+}
+
+func shiftBox(b *box, dy int) {
+	b.y += dy
+	for _, child := range b.children {
+		shiftBox(child, dy)
+	}
+}
+
+func rightmost(b *box) int {
+	right := b.x + b.w
+	for _, child := range b.children {
+		right = max(right, rightmost(child))
+	}
+	return right
+}
+
+// nodeLabel 节点文字合并为单行并截断过长的部分
+func nodeLabel(text string) string {
+	label := strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(label) > maxLabelRunes {
+		label = string([]rune(label)[:maxLabelRunes]) + "…"
+	}
+	return label
+}
+
+// estimateWidth 没有字体度量时估算文字宽度：ASCII 按半个字宽，其余按一个字宽
+func estimateWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			width += fontSize * 6 / 10
+		} else {
+			width += fontSize
+		}
+	}
+	return width
+}
+
+// 节点按层级使用的颜色：根节点、一级分支、其余节点
+var levelColors = [...]struct{ fill, stroke, text uint32 }{
+	{0x3b82f6, 0x2563eb, 0xffffff},
+	{0xdbeafe, 0x60a5fa, 0x1e3a8a},
+	{0xffffff, 0x93c5fd, 0x1f2937},
+}
+
+func colorsOf(depth int) (fill, stroke, text uint32) {
+	c := levelColors[min(depth, len(levelColors)-1)]
+	return c.fill, c.stroke, c.text
+}
+
+const edgeColor = 0x93c5fd
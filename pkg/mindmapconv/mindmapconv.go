@@ -0,0 +1,114 @@
+package mindmapconv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// 错误定义
+var (
+	ErrUnsupportedFormat = errors.New("不支持的导出格式，仅支持 xmind、mm、opml、md、svg 与 png")
+	ErrPNGUnavailable    = errors.New("未配置渲染字体，暂不支持导出 PNG")
+	ErrImageTooLarge     = errors.New("导图过大，无法渲染为图片")
+	ErrUnsupportedImport = errors.New("不支持的导入格式，仅支持 xmind、mm、opml 与 md")
+	ErrInvalidFile       = errors.New("文件内容无法解析，请确认文件格式与扩展名一致")
+	ErrEmptyMap          = errors.New("文件中没有导图内容")
+	ErrTooManyNodes      = errors.New("导图节点数量超出上限")
+)
+
+// 导出格式
+const (
+	FormatXMind    = "xmind" // XMind（2020 及以上版本的 content.json）
+	FormatFreeMind = "mm"    // FreeMind / Freeplane
+	FormatOPML     = "opml"
+	FormatMarkdown = "md" // 标题加缩进列表的大纲
+	FormatSVG      = "svg"
+	FormatPNG      = "png"
+)
+
+// Node 导图节点，与业务实体无关，导入导出都使用该结构
+type Node struct {
+	Text     string
+	Children []Node
+}
+
+// File 导出的文件内容
+type File struct {
+	Data        []byte
+	ContentType string
+	Ext         string // 含点号的扩展名，如 .xmind
+}
+
+// Exporter 导图导出器，渲染 PNG 需要能显示中文的字体
+type Exporter struct {
+	face font.Face // 未配置字体时为 nil，不能导出 PNG
+}
+
+// NewExporter 创建导出器，fontFile 为 TTF/OTF 字体文件路径，为空时不能导出 PNG
+func NewExporter(fontFile string) (*Exporter, error) {
+	if fontFile == "" {
+		return &Exporter{}, nil
+	}
+	data, err := os.ReadFile(fontFile)
+	if err != nil {
+		return nil, fmt.Errorf("read font file failed: %w", err)
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font file failed: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: fontSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("create font face failed: %w", err)
+	}
+	return &Exporter{face: face}, nil
+}
+
+// Supported 格式是否可以导出
+func (e *Exporter) Supported(format string) bool {
+	switch format {
+	case FormatXMind, FormatFreeMind, FormatOPML, FormatMarkdown, FormatSVG:
+		return true
+	case FormatPNG:
+		return e.face != nil
+	}
+	return false
+}
+
+// Export 将以 root 为根节点的导图转换为指定格式
+func (e *Exporter) Export(format string, root Node) (*File, error) {
+	switch format {
+	case FormatXMind:
+		data, err := toXMind(root)
+		return newFile(data, err, "application/vnd.xmind.workbook", ".xmind")
+	case FormatFreeMind:
+		data, err := toFreeMind(root)
+		return newFile(data, err, "application/x-freemind", ".mm")
+	case FormatOPML:
+		data, err := toOPML(root)
+		return newFile(data, err, "text/x-opml; charset=utf-8", ".opml")
+	case FormatMarkdown:
+		return newFile(toMarkdown(root), nil, "text/markdown; charset=utf-8", ".md")
+	case FormatSVG:
+		data, err := toSVG(root)
+		return newFile(data, err, "image/svg+xml", ".svg")
+	case FormatPNG:
+		if e.face == nil {
+			return nil, ErrPNGUnavailable
+		}
+		data, err := toPNG(root, e.face)
+		return newFile(data, err, "image/png", ".png")
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func newFile(data []byte, err error, contentType, ext string) (*File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &File{Data: data, ContentType: contentType, Ext: ext}, nil
+}
@@ -0,0 +1,98 @@
+package mindmapconv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// freeMindNode FreeMind 的 <node TEXT=""> 元素
+type freeMindNode struct {
+	XMLName  xml.Name       `xml:"node"`
+	Text     string         `xml:"TEXT,attr"`
+	Children []freeMindNode `xml:"node"`
+}
+
+type freeMindMap struct {
+	XMLName xml.Name     `xml:"map"`
+	Version string       `xml:"version,attr"`
+	Root    freeMindNode `xml:"node"`
+}
+
+func toFreeMindNode(n Node) freeMindNode {
+	node := freeMindNode{Text: n.Text}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, toFreeMindNode(child))
+	}
+	return node
+}
+
+func toFreeMind(root Node) ([]byte, error) {
+	return xml.MarshalIndent(freeMindMap{Version: "1.0.1", Root: toFreeMindNode(root)}, "", "  ")
+}
+
+// opmlOutline OPML 的 <outline text=""> 元素
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"` // 部分工具只写 title，导入时作为备用
+	Children []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+func toOPMLOutline(n Node) opmlOutline {
+	outline := opmlOutline{Text: n.Text}
+	for _, child := range n.Children {
+		outline.Children = append(outline.Children, toOPMLOutline(child))
+	}
+	return outline
+}
+
+// toOPML 根节点作为文档标题，同时作为唯一的顶层 outline，便于其他工具还原根节点
+func toOPML(root Node) ([]byte, error) {
+	var doc opmlDocument
+	doc.Version = "2.0"
+	doc.Head.Title = root.Text
+	doc.Body.Outlines = []opmlOutline{toOPMLOutline(root)}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// toMarkdown 根节点为一级标题，其余节点为按层级缩进的列表
+func toMarkdown(root Node) []byte {
+	var b bytes.Buffer
+	b.WriteString("# ")
+	b.WriteString(markdownLine(root.Text))
+	b.WriteString("\n\n")
+	for _, child := range root.Children {
+		writeMarkdownItem(&b, child, 0)
+	}
+	return b.Bytes()
+}
+
+func writeMarkdownItem(b *bytes.Buffer, n Node, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("- ")
+	b.WriteString(markdownLine(n.Text))
+	b.WriteString("\n")
+	for _, child := range n.Children {
+		writeMarkdownItem(b, child, depth+1)
+	}
+}
+
+// markdownLine 节点文本中的换行会破坏列表结构，替换为空格
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,78 @@
+package mindmapconv
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// toPNG 使用配置的字体渲染为位图
+func toPNG(root Node, face font.Face) ([]byte, error) {
+	measure := func(text string) int {
+		return font.MeasureString(face, text).Ceil()
+	}
+	b, width, height, err := layout(root, measure)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	drawPNGEdges(img, b)
+	drawPNGNodes(img, b, face)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawPNGEdges(img *image.RGBA, b *box) {
+	edge := rgb(edgeColor)
+	for _, child := range b.children {
+		x1, y1 := b.x+b.w, b.y+b.h/2
+		x2, y2 := child.x, child.y+child.h/2
+		mid := x1 + levelGap/2
+		fillRect(img, image.Rect(x1, y1-1, mid+1, y1+1), edge)
+		fillRect(img, image.Rect(mid-1, min(y1, y2)-1, mid+1, max(y1, y2)+1), edge)
+		fillRect(img, image.Rect(mid-1, y2-1, x2, y2+1), edge)
+		drawPNGEdges(img, child)
+	}
+}
+
+func drawPNGNodes(img *image.RGBA, b *box, face font.Face) {
+	fill, stroke, text := colorsOf(b.depth)
+	rect := image.Rect(b.x, b.y, b.x+b.w, b.y+b.h)
+	fillRect(img, rect, rgb(stroke))
+	fillRect(img, rect.Inset(1), rgb(fill))
+
+	// 文字在节点内水平、垂直居中
+	metrics := face.Metrics()
+	textWidth := font.MeasureString(face, b.label)
+	baseline := fixed.I(b.y+b.h/2) + (metrics.Ascent-metrics.Descent)/2
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(rgb(text)),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I(b.x+b.w/2) - textWidth/2, Y: baseline},
+	}
+	d.DrawString(b.label)
+
+	for _, child := range b.children {
+		drawPNGNodes(img, child, face)
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
@@ -0,0 +1,49 @@
+package mindmapconv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// toSVG 渲染为 SVG 矢量图，文字宽度为估算值，由查看器使用系统字体显示
+func toSVG(root Node) ([]byte, error) {
+	b, width, height, err := layout(root, estimateWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n",
+		width, height, width, height, fontSize)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	writeSVGEdges(&buf, b)
+	writeSVGNodes(&buf, b)
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// writeSVGEdges 父节点右侧中点到子节点左侧中点的折线
+func writeSVGEdges(buf *bytes.Buffer, b *box) {
+	for _, child := range b.children {
+		x1, y1 := b.x+b.w, b.y+b.h/2
+		x2, y2 := child.x, child.y+child.h/2
+		mid := x1 + levelGap/2
+		fmt.Fprintf(buf, `<polyline points="%d,%d %d,%d %d,%d %d,%d" fill="none" stroke="#%06x" stroke-width="2"/>`+"\n",
+			x1, y1, mid, y1, mid, y2, x2, y2, edgeColor)
+		writeSVGEdges(buf, child)
+	}
+}
+
+func writeSVGNodes(buf *bytes.Buffer, b *box) {
+	fill, stroke, text := colorsOf(b.depth)
+	fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#%06x" stroke="#%06x" stroke-width="1.5"/>`+"\n",
+		b.x, b.y, b.w, b.h, fill, stroke)
+	fmt.Fprintf(buf, `<text x="%d" y="%d" fill="#%06x" text-anchor="middle" dominant-baseline="central">`,
+		b.x+b.w/2, b.y+b.h/2, text)
+	_ = xml.EscapeText(buf, []byte(b.label))
+	buf.WriteString("</text>\n")
+	for _, child := range b.children {
+		writeSVGNodes(buf, child)
+	}
+}
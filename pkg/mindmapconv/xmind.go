@@ -0,0 +1,84 @@
+package mindmapconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// xmindTopic XMind 2020 及以上版本 content.json 中的主题
+type xmindTopic struct {
+	ID             string         `json:"id"`
+	Class          string         `json:"class"`
+	Title          string         `json:"title"`
+	StructureClass string         `json:"structureClass,omitempty"`
+	Children       *xmindChildren `json:"children,omitempty"`
+}
+
+type xmindChildren struct {
+	Attached []xmindTopic `json:"attached"`
+}
+
+type xmindSheet struct {
+	ID        string     `json:"id"`
+	Class     string     `json:"class"`
+	Title     string     `json:"title"`
+	RootTopic xmindTopic `json:"rootTopic"`
+}
+
+// toXMind 生成只包含 content.json、metadata.json 与 manifest.json 的 .xmind 压缩包
+func toXMind(root Node) ([]byte, error) {
+	seq := 0
+	rootTopic := toXMindTopic(root, &seq)
+	rootTopic.StructureClass = "org.xmind.ui.map.unbalanced"
+	content, err := json.Marshal([]xmindSheet{{
+		ID:        "sheet",
+		Class:     "sheet",
+		Title:     root.Text,
+		RootTopic: rootTopic,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"content.json", content},
+		{"metadata.json", []byte(`{"creator":{"name":"forge"}}`)},
+		{"manifest.json", []byte(`{"file-entries":{"content.json":{},"metadata.json":{}}}`)},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toXMindTopic(n Node, seq *int) xmindTopic {
+	*seq++
+	topic := xmindTopic{
+		ID:    "topic-" + strconv.Itoa(*seq),
+		Class: "topic",
+		Title: n.Text,
+	}
+	if len(n.Children) > 0 {
+		topic.Children = &xmindChildren{}
+		for _, child := range n.Children {
+			topic.Children.Attached = append(topic.Children.Attached, toXMindTopic(child, seq))
+		}
+	}
+	return topic
+}
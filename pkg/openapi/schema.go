@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// 按标签解析出的结构体字段
+type field struct {
+	name     string
+	schema   *Schema
+	required bool
+}
+
+// SchemaOf 按 json 标签生成 v 的类型的 schema，具名结构体注册到 components 中并返回引用
+// v 为 nil 时返回空 schema，表示任意值
+func (b *Builder) SchemaOf(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return b.schema(reflect.TypeOf(v), "json")
+}
+
+// FormSchema 按 form 标签生成 multipart 表单的 schema，文件字段为 binary
+func (b *Builder) FormSchema(v any) *Schema {
+	return b.objectSchema(b.fields(reflect.TypeOf(v), "form"))
+}
+
+// QueryParameters 按 form 标签生成查询参数，默认值取自 form 标签中的 default
+func (b *Builder) QueryParameters(v any) []Parameter {
+	var params []Parameter
+	for _, f := range b.fields(reflect.TypeOf(v), "form") {
+		params = append(params, Parameter{Name: f.name, In: "query", Required: f.required, Schema: f.schema})
+	}
+	return params
+}
+
+// HasFields 按 tag 标签是否有需要传的字段，用于判断请求参数放在请求体还是查询参数中
+func (b *Builder) HasFields(v any, tag string) bool {
+	return len(b.fields(reflect.TypeOf(v), tag)) > 0
+}
+
+// HasFile 是否有上传文件的字段
+func HasFile(v any) bool {
+	t := deref(reflect.TypeOf(v))
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		ft := deref(t.Field(i).Type)
+		if ft.Kind() == reflect.Slice {
+			ft = deref(ft.Elem())
+		}
+		if ft == fileHeaderType {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Builder) schema(t reflect.Type, tag string) *Schema {
+	t = deref(t)
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// []byte 按 encoding/json 的规则编码为 base64 字符串
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem(), tag)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem(), tag)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.objectSchema(b.fields(t, tag))
+		}
+		name := b.nameOf(t)
+		if _, ok := b.doc.Components.Schemas[name]; !ok {
+			// 先占位再生成字段，结构体引用自身（如导图节点的子节点）时不会无限递归
+			s := &Schema{}
+			b.doc.Components.Schemas[name] = s
+			*s = *b.objectSchema(b.fields(t, tag))
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	// interface{} 等无法确定类型的字段
+	return &Schema{}
+}
+
+func (b *Builder) objectSchema(fields []field) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema, len(fields))}
+	for _, f := range fields {
+		s.Properties[f.name] = f.schema
+		if f.required {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+// fields 按 tag 标签列出结构体的字段，嵌入的结构体字段展开
+// json 模式与 encoding/json 一致，未写标签的字段使用字段名；form 模式只列出写了 form 标签的字段
+func (b *Builder) fields(t reflect.Type, tag string) []field {
+	t = deref(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && name == "" && deref(sf.Type).Kind() == reflect.Struct {
+			fields = append(fields, b.fields(sf.Type, tag)...)
+			continue
+		}
+		if name == "" {
+			if tag != "json" {
+				continue
+			}
+			name = sf.Name
+		}
+		// 文件只能通过 multipart 表单上传，不出现在 JSON 中
+		if tag == "json" && deref(sf.Type) == fileHeaderType {
+			continue
+		}
+
+		s := b.schema(sf.Type, tag)
+		required := applyBinding(s, sf.Tag.Get("binding"))
+		for _, opt := range strings.Split(opts, ",") {
+			if def, ok := strings.CutPrefix(opt, "default="); ok {
+				s.Default = typedValue(s, def)
+			}
+		}
+		fields = append(fields, field{name: name, schema: s, required: required})
+	}
+	return fields
+}
+
+// applyBinding 将 binding 标签中的常用规则转为 schema 的约束，返回是否必填
+// dive 之后的规则作用于数组元素；引用其他 schema 时 OpenAPI 3.0 不支持附加约束，只判断是否必填
+func applyBinding(s *Schema, rules string) bool {
+	if rules == "" {
+		return false
+	}
+	required := false
+	ruleList := strings.Split(rules, ",")
+	for i, rule := range ruleList {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			if s.Items != nil && s.Items.Ref == "" {
+				applyBinding(s.Items, strings.Join(ruleList[i+1:], ","))
+			}
+			break
+		}
+		if name == "required" {
+			required = true
+			continue
+		}
+		if s.Ref != "" {
+			continue
+		}
+		switch name {
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, typedValue(s, v))
+			}
+		case "email":
+			s.Format = "email"
+		case "url", "http_url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "min", "gte", "gt", "max", "lte", "lt", "len":
+			applyLimit(s, name, param)
+		}
+	}
+	return required
+}
+
+// applyLimit 字符串、数组限制长度，数字限制取值
+func applyLimit(s *Schema, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	lower := rule == "min" || rule == "gte" || rule == "gt" || rule == "len"
+	upper := rule == "max" || rule == "lte" || rule == "lt" || rule == "len"
+	switch s.Type {
+	case "string", "array":
+		size := int(n)
+		if s.Type == "string" {
+			if lower {
+				s.MinLength = &size
+			}
+			if upper {
+				s.MaxLength = &size
+			}
+			return
+		}
+		if lower {
+			s.MinItems = &size
+		}
+		if upper {
+			s.MaxItems = &size
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+			s.ExclusiveMinimum = rule == "gt"
+		}
+		if upper {
+			s.Maximum = &n
+			s.ExclusiveMaximum = rule == "lt"
+		}
+	}
+}
+
+// typedValue 将标签中的字符串按 schema 类型转换，用于 enum 与 default
+func typedValue(s *Schema, v string) any {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// nameOf 结构体在 components 中的名称，不同包的同名类型带上包名区分
+func (b *Builder) nameOf(t reflect.Type) string {
+	key := t.PkgPath() + "." + t.Name()
+	if name, ok := b.names[key]; ok {
+		return name
+	}
+	name := sanitizeName(t.Name())
+	for _, used := range b.names {
+		if used == name {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = sanitizeName(pkg + "." + t.Name())
+			break
+		}
+	}
+	b.names[key] = name
+	return name
+}
+
+// sanitizeName components 中的名称只能包含字母、数字与 .-_，泛型类型名中的其他字符替换为 _
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -21,6 +21,9 @@ var (
 	/* 请求错误 <0 */
 	TOKEN_IS_EXPIRED = MsgCode{Code: -2, Msg: "token已过期"}
 
+	/* 限流 */
+	TOO_MANY_REQUESTS = MsgCode{Code: 429, Msg: "请求过于频繁，请稍后再试"}
+
 	/* 内部错误 600 ~ 999 */
 	INTERNAL_ERROR             = MsgCode{Code: 601, Msg: "内部错误, check log"}
 	INTERNAL_FILE_UPLOAD_ERROR = MsgCode{Code: 602, Msg: "文件上传失败"}
@@ -32,6 +35,7 @@ var (
 	INVALID_PARAMS     = MsgCode{Code: 1005, Msg: "请求体无效"}
 
 	PARAM_FILE_SIZE_TOO_BIG = MsgCode{Code: 1010, Msg: "文件过大"}
+	REQUEST_BODY_TOO_LARGE  = MsgCode{Code: 1011, Msg: "请求体过大"}
 
 	/* 用户错误 2000 ~ 2999 */
 	USER_NOT_LOGIN             = MsgCode{Code: 2001, Msg: "用户未登录"}
@@ -41,32 +45,148 @@ var (
 	USER_ACCOUNT_ALREADY_EXIST = MsgCode{Code: 2008, Msg: "账号已存在"}
 	ACCOUNT_ALREADY_IN_USE     = MsgCode{Code: 2009, Msg: "该账号已被使用"}
 	// EMAIL_ALREADY_IN_USE 已废弃，请使用 ACCOUNT_ALREADY_IN_USE（保持向后兼容）
-	EMAIL_ALREADY_IN_USE    = ACCOUNT_ALREADY_IN_USE
-	PASSWORD_REQUIRED       = MsgCode{Code: 2010, Msg: "密码必填"}
-	ACCOUNT_LAST_CONTACT    = MsgCode{Code: 2011, Msg: "无法解绑唯一联系方式"}
-	CAPTCHA_ERROR           = MsgCode{Code: 2100, Msg: "验证码错误"}
-	INSUFFICENT_PERMISSIONS = MsgCode{Code: 2200, Msg: "权限不足"}
+	EMAIL_ALREADY_IN_USE       = ACCOUNT_ALREADY_IN_USE
+	PASSWORD_REQUIRED          = MsgCode{Code: 2010, Msg: "密码必填"}
+	ACCOUNT_LAST_CONTACT       = MsgCode{Code: 2011, Msg: "无法解绑唯一联系方式"}
+	ACCOUNT_PENDING_DELETION   = MsgCode{Code: 2012, Msg: "账号已申请注销，重新登录可撤销注销"}
+	USER_NAME_INVALID          = MsgCode{Code: 2013, Msg: "用户名需以字母开头，由4~20位字母、数字或下划线组成"}
+	PASSWORD_RECENTLY_USED     = MsgCode{Code: 2014, Msg: "不能使用最近用过的密码"}
+	PHONE_INVALID              = MsgCode{Code: 2015, Msg: "手机号格式不正确，国际号码请填写国际区号"}
+	OLD_CONTACT_CODE_REQUIRED  = MsgCode{Code: 2016, Msg: "换绑前需要输入原手机号/邮箱收到的确认码"}
+	ACCOUNT_SUSPENDED          = MsgCode{Code: 2017, Msg: "账号已被禁用"}
+	INVITE_CODE_REQUIRED       = MsgCode{Code: 2018, Msg: "注册需要填写邀请码"}
+	INVITE_CODE_INVALID        = MsgCode{Code: 2019, Msg: "邀请码无效、已过期或已被使用"}
+	INVITE_QUOTA_EXCEEDED      = MsgCode{Code: 2020, Msg: "邀请码数量已达上限"}
+	MERGE_SAME_ACCOUNT         = MsgCode{Code: 2021, Msg: "不能合并当前登录的账号"}
+	CAPTCHA_ERROR              = MsgCode{Code: 2100, Msg: "验证码错误"}
+	CAPTCHA_TOO_FREQUENT       = MsgCode{Code: 2101, Msg: "验证码发送过于频繁，请稍后再试"}
+	CAPTCHA_EXPIRED            = MsgCode{Code: 2102, Msg: "验证码已失效，请重新获取"}
+	IMAGE_CAPTCHA_REQUIRED     = MsgCode{Code: 2103, Msg: "请先完成图形验证码"}
+	IMAGE_CAPTCHA_ERROR        = MsgCode{Code: 2104, Msg: "图形验证码错误或已失效，请重新获取"}
+	INSUFFICENT_PERMISSIONS    = MsgCode{Code: 2200, Msg: "权限不足"}
+	CSRF_TOKEN_INVALID         = MsgCode{Code: 2201, Msg: "CSRF校验失败"}
+	REFRESH_TOKEN_INVALID      = MsgCode{Code: 2202, Msg: "刷新令牌无效或已过期，请重新登录"}
+	LOGIN_TOO_MANY_ATTEMPTS    = MsgCode{Code: 2203, Msg: "登录失败次数过多，请稍后再试"}
+	SESSION_NOT_FOUND          = MsgCode{Code: 2204, Msg: "登录会话不存在"}
+	CANNOT_MANAGE_SELF         = MsgCode{Code: 2205, Msg: "不能对自己执行该操作"}
+	MAGIC_LINK_INVALID         = MsgCode{Code: 2206, Msg: "登录链接无效或已过期，请重新获取"}
+	UNSUBSCRIBE_TOKEN_INVALID  = MsgCode{Code: 2301, Msg: "退订链接无效"}
+	OAUTH_PROVIDER_UNSUPPORTED = MsgCode{Code: 2401, Msg: "不支持的第三方登录方式"}
+	OAUTH_STATE_INVALID        = MsgCode{Code: 2402, Msg: "授权已过期，请重新发起"}
+	OAUTH_CODE_INVALID         = MsgCode{Code: 2403, Msg: "授权码无效或已使用"}
+	OAUTH_PROVIDER_ERROR       = MsgCode{Code: 2404, Msg: "第三方平台服务异常，请稍后重试"}
+	OAUTH_ALREADY_BOUND        = MsgCode{Code: 2405, Msg: "已绑定该平台的其他账号，请先解绑"}
 
 	/* 思维导图错误 3000 ~ 3999 */
-	MINDMAP_NOT_FOUND         = MsgCode{Code: 3001, Msg: "思维导图不存在"}
-	MINDMAP_ALREADY_EXISTS    = MsgCode{Code: 3002, Msg: "思维导图已存在"}
-	MINDMAP_PERMISSION_DENIED = MsgCode{Code: 3003, Msg: "思维导图权限不足"}
+	MINDMAP_NOT_FOUND               = MsgCode{Code: 3001, Msg: "思维导图不存在"}
+	MINDMAP_ALREADY_EXISTS          = MsgCode{Code: 3002, Msg: "思维导图已存在"}
+	MINDMAP_PERMISSION_DENIED       = MsgCode{Code: 3003, Msg: "思维导图权限不足"}
+	MINDMAP_OUTLINE_EMPTY           = MsgCode{Code: 3004, Msg: "大纲内容为空"}
+	MINDMAP_OUTLINE_TOO_LARGE       = MsgCode{Code: 3005, Msg: "大纲内容过大"}
+	MINDMAP_SHARE_NOT_FOUND         = MsgCode{Code: 3006, Msg: "分享链接无效或已失效"}
+	MINDMAP_SHARE_EXPIRED           = MsgCode{Code: 3007, Msg: "分享链接已过期"}
+	MINDMAP_SHARE_LIMIT_EXCEEDED    = MsgCode{Code: 3008, Msg: "该导图的分享链接数量已达上限"}
+	MINDMAP_SHARE_INVALID           = MsgCode{Code: 3009, Msg: "访问密码长度为4-32个字符，有效期不能超过上限"}
+	MINDMAP_SHARE_PASSWORD_REQUIRED = MsgCode{Code: 3010, Msg: "需要输入访问密码"}
+	MINDMAP_SHARE_PASSWORD_WRONG    = MsgCode{Code: 3011, Msg: "访问密码错误"}
+	MINDMAP_SHARE_TOO_MANY_ATTEMPTS = MsgCode{Code: 3012, Msg: "访问密码错误次数过多，请稍后再试"}
+	MINDMAP_MEMBER_NOT_FOUND        = MsgCode{Code: 3013, Msg: "协作者不存在"}
+	MINDMAP_MEMBER_USER_NOT_FOUND   = MsgCode{Code: 3014, Msg: "该账号未注册"}
+	MINDMAP_MEMBER_LIMIT_EXCEEDED   = MsgCode{Code: 3015, Msg: "该导图的协作者数量已达上限"}
+	MINDMAP_MEMBER_ROLE_INVALID     = MsgCode{Code: 3016, Msg: "协作者角色只能是 viewer 或 editor"}
+	MINDMAP_MEMBER_SELF             = MsgCode{Code: 3017, Msg: "不能将自己添加为协作者"}
+	MINDMAP_EXPORT_UNSUPPORTED      = MsgCode{Code: 3018, Msg: "不支持的导出格式"}
+	MINDMAP_EXPORT_PNG_UNAVAILABLE  = MsgCode{Code: 3019, Msg: "暂不支持导出PNG图片"}
+	MINDMAP_EXPORT_TOO_LARGE        = MsgCode{Code: 3020, Msg: "导图过大，无法渲染为图片"}
+	MINDMAP_IMPORT_UNSUPPORTED      = MsgCode{Code: 3021, Msg: "不支持的导入格式"}
+	MINDMAP_IMPORT_INVALID_FILE     = MsgCode{Code: 3022, Msg: "导入文件无法解析"}
+	MINDMAP_IMPORT_TOO_LARGE        = MsgCode{Code: 3023, Msg: "导入文件过大或节点过多"}
+	MINDMAP_FOLDER_NOT_FOUND        = MsgCode{Code: 3024, Msg: "文件夹不存在"}
+	MINDMAP_FOLDER_NAME_INVALID     = MsgCode{Code: 3025, Msg: "文件夹名称不能为空且不超过50个字符"}
+	MINDMAP_FOLDER_LIMIT_EXCEEDED   = MsgCode{Code: 3026, Msg: "文件夹数量已达上限"}
+	MINDMAP_FOLDER_TOO_DEEP         = MsgCode{Code: 3027, Msg: "文件夹嵌套层数超出上限"}
+	MINDMAP_FOLDER_CYCLE            = MsgCode{Code: 3028, Msg: "不能将文件夹移动到自身或其子文件夹中"}
+	MINDMAP_TAG_NOT_FOUND           = MsgCode{Code: 3029, Msg: "标签不存在"}
+	MINDMAP_TAG_NAME_INVALID        = MsgCode{Code: 3030, Msg: "标签名称不能为空且不超过20个字符"}
+	MINDMAP_TAG_TOO_MANY            = MsgCode{Code: 3031, Msg: "每个导图最多10个标签"}
+	MINDMAP_TAG_LIMIT_EXCEEDED      = MsgCode{Code: 3032, Msg: "标签数量已达上限"}
+	MINDMAP_SEARCH_QUERY_INVALID    = MsgCode{Code: 3033, Msg: "搜索词至少包含一个不少于2个字符的关键词"}
+	MINDMAP_TEMPLATE_NOT_FOUND      = MsgCode{Code: 3034, Msg: "模板不存在"}
+	MINDMAP_TEMPLATE_INVALID        = MsgCode{Code: 3035, Msg: "模板分类不能为空且不超过20个字符"}
+	MINDMAP_LIST_CURSOR_INVALID     = MsgCode{Code: 3036, Msg: "分页游标无效，请从第一页重新加载"}
+	MINDMAP_COMMENT_NOT_FOUND       = MsgCode{Code: 3037, Msg: "评论不存在"}
+	MINDMAP_COMMENT_NODE_NOT_FOUND  = MsgCode{Code: 3038, Msg: "评论的节点不存在，请刷新导图后重试"}
+	MINDMAP_COMMENT_INVALID         = MsgCode{Code: 3039, Msg: "评论内容不能为空且不超过1000个字符"}
+	MINDMAP_COMMENT_LIMIT_EXCEEDED  = MsgCode{Code: 3040, Msg: "该导图的评论数量已达上限"}
 
 	/* COS错误 4000 ~ 4999 */
 	COS_INVALID_RESOURCE_PATH  = MsgCode{Code: 4001, Msg: "无效的资源路径"}
 	COS_INVALID_DURATION       = MsgCode{Code: 4002, Msg: "无效的有效期"}
 	COS_GET_CREDENTIALS_FAILED = MsgCode{Code: 4003, Msg: "获取COS凭证失败"}
 	COS_PERMISSION_DENIED      = MsgCode{Code: 4004, Msg: "COS权限不足"}
+	COS_FILE_NOT_FOUND         = MsgCode{Code: 4005, Msg: "文件不存在"}
+	COS_DOWNLOAD_LINK_INVALID  = MsgCode{Code: 4006, Msg: "下载链接无效或已过期"}
+	COS_STS_NOT_SUPPORTED      = MsgCode{Code: 4007, Msg: "当前存储服务不支持临时凭证，请通过服务端上传"}
+	COS_INVALID_PURPOSE        = MsgCode{Code: 4008, Msg: "不支持的文件用途"}
+	COS_FILE_INFECTED          = MsgCode{Code: 4009, Msg: "文件包含病毒，已隔离"}
+	COS_FILE_SCAN_FAILED       = MsgCode{Code: 4010, Msg: "文件安全扫描失败，请稍后重试"}
 
 	/* ai对话错误 5000~5999 */
 
 	INVALID_CONTENT_TYPE = MsgCode{Code: 5000, Msg: "只接受 application/json 或 multipart/form-data"}
 
-	CONVERSATION_ID_NOT_NULL    = MsgCode{Code: 5200, Msg: "会话ID不能为空"}
-	USER_ID_NOT_NULL            = MsgCode{Code: 5201, Msg: "用户ID不能为空"}
-	MAP_ID_NOT_NULL             = MsgCode{Code: 5202, Msg: "导图ID不能为空"}
-	CONVERSATION_TITLE_NOT_NULL = MsgCode{Code: 5203, Msg: "会话标题不能为空"}
-	CONVERSATION_NOT_EXIST      = MsgCode{Code: 5204, Msg: "该会话不存在"}
-	AI_CHAT_PERMISSION_DENIED   = MsgCode{Code: 5205, Msg: "会话权限不足"}
-	MIND_MAP_NOT_EXIST          = MsgCode{Code: 5206, Msg: "该导图不存在"}
+	CONVERSATION_ID_NOT_NULL     = MsgCode{Code: 5200, Msg: "会话ID不能为空"}
+	USER_ID_NOT_NULL             = MsgCode{Code: 5201, Msg: "用户ID不能为空"}
+	MAP_ID_NOT_NULL              = MsgCode{Code: 5202, Msg: "导图ID不能为空"}
+	CONVERSATION_TITLE_NOT_NULL  = MsgCode{Code: 5203, Msg: "会话标题不能为空"}
+	CONVERSATION_NOT_EXIST       = MsgCode{Code: 5204, Msg: "该会话不存在"}
+	AI_CHAT_PERMISSION_DENIED    = MsgCode{Code: 5205, Msg: "会话权限不足"}
+	MIND_MAP_NOT_EXIST           = MsgCode{Code: 5206, Msg: "该导图不存在"}
+	CONVERSATION_RESTORE_EXPIRED = MsgCode{Code: 5207, Msg: "会话已超过可恢复期限"}
+	AI_MODEL_NOT_SUPPORTED       = MsgCode{Code: 5208, Msg: "不支持该模型"}
+	MESSAGE_NOT_EXIST            = MsgCode{Code: 5209, Msg: "该消息不存在"}
+	DOCUMENT_UNSUPPORTED_TYPE    = MsgCode{Code: 5210, Msg: "不支持的文件格式，仅支持 PDF、DOCX、PPTX、Markdown、TXT 与 SRT/VTT 字幕"}
+	DOCUMENT_CORRUPTED           = MsgCode{Code: 5211, Msg: "文件已损坏或内容与扩展名不符"}
+	DOCUMENT_TOO_LARGE           = MsgCode{Code: 5212, Msg: "文件过大"}
+	DOCUMENT_TOO_MANY_PAGES      = MsgCode{Code: 5213, Msg: "文件页数过多"}
+	DOCUMENT_EMPTY               = MsgCode{Code: 5214, Msg: "未能从文件中提取到文字"}
+	CONVERSATION_MAP_MISMATCH    = MsgCode{Code: 5215, Msg: "该会话不属于该导图"}
+	PROMPT_PRESET_NOT_FOUND      = MsgCode{Code: 5216, Msg: "该预设不存在"}
+	PROMPT_PRESET_INVALID        = MsgCode{Code: 5217, Msg: "预设内容不合法"}
+	PROMPT_PRESET_LIMIT_EXCEEDED = MsgCode{Code: 5218, Msg: "自定义预设数量已达上限"}
+	USER_PRESET_DISABLED         = MsgCode{Code: 5219, Msg: "未开放自定义预设"}
+	CONTENT_INPUT_BLOCKED        = MsgCode{Code: 5220, Msg: "消息包含违规内容，请修改后重试"}
+	CONTENT_OUTPUT_BLOCKED       = MsgCode{Code: 5221, Msg: "AI回复未通过内容审核，请换个问题重试"}
+	SEARCH_KEYWORD_INVALID       = MsgCode{Code: 5222, Msg: "搜索关键词不能为空且不超过100个字符"}
+	CONVERSATION_ORDER_INVALID   = MsgCode{Code: 5223, Msg: "会话顺序中有重复或不属于该导图的会话"}
+	MESSAGE_FEEDBACK_INVALID     = MsgCode{Code: 5224, Msg: "评价内容不合法"}
+	MESSAGE_NOT_RATEABLE         = MsgCode{Code: 5225, Msg: "只能评价AI的回复"}
+	AI_VISION_NOT_SUPPORTED      = MsgCode{Code: 5226, Msg: "当前模型不支持图片输入，且未配置支持图片的模型"}
+	AI_IMAGE_INVALID             = MsgCode{Code: 5227, Msg: "图片不存在或格式不支持"}
+	AI_IMAGE_LIMIT_EXCEEDED      = MsgCode{Code: 5228, Msg: "图片数量或大小超出限制"}
+
+	/* 套餐配额错误 6000~6999 */
+	PLAN_INVALID                = MsgCode{Code: 6001, Msg: "无效的套餐"}
+	PLAN_MINDMAP_QUOTA_EXCEEDED = MsgCode{Code: 6002, Msg: "导图数量已达套餐上限"}
+	PLAN_STORAGE_QUOTA_EXCEEDED = MsgCode{Code: 6003, Msg: "存储空间已达套餐上限"}
+	PLAN_AI_QUOTA_EXCEEDED      = MsgCode{Code: 6004, Msg: "今日AI调用次数已达套餐上限"}
+	PLAN_AI_RATE_LIMITED        = MsgCode{Code: 6005, Msg: "AI调用过于频繁，请稍后再试"}
+	PLAN_AI_CONCURRENCY_LIMITED = MsgCode{Code: 6006, Msg: "进行中的AI生成任务过多，请等待完成后再试"}
+	PLAN_AI_TOKEN_EXCEEDED      = MsgCode{Code: 6007, Msg: "AI token 用量已达套餐上限"}
+	PLAN_MINDMAP_NODES_EXCEEDED = MsgCode{Code: 6008, Msg: "导图节点数量已达套餐上限"}
+	PLAN_QUOTA_INVALID          = MsgCode{Code: 6009, Msg: "配额不能为负数"}
+
+	/* 支付错误 6100~6199 */
+	BILLING_DISABLED          = MsgCode{Code: 6101, Msg: "支付功能未开启"}
+	BILLING_INVALID_PRODUCT   = MsgCode{Code: 6102, Msg: "无效的商品"}
+	BILLING_PROVIDER_ERROR    = MsgCode{Code: 6103, Msg: "支付渠道调用失败"}
+	BILLING_INVALID_WEBHOOK   = MsgCode{Code: 6104, Msg: "无效的支付回调"}
+	BILLING_INVOICE_NOT_FOUND = MsgCode{Code: 6105, Msg: "账单不存在"}
+
+	/* 备份错误 6200~6299 */
+	BACKUP_NOT_FOUND = MsgCode{Code: 6201, Msg: "备份不存在"}
+	BACKUP_CORRUPTED = MsgCode{Code: 6202, Msg: "备份文件校验失败"}
+
+	/* 数据导出错误 6300~6399 */
+	DATA_EXPORT_DISABLED = MsgCode{Code: 6301, Msg: "数据导出功能未开启"}
 )
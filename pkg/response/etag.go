@@ -0,0 +1,59 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// SuccessWithETag 成功响应并附带弱 ETag
+// 请求头 If-None-Match 与本次响应内容一致时返回 304，不再下发响应体，适合前端轮询的读接口
+func (r *JsonMsgResponse) SuccessWithETag(data interface{}) {
+	res := JsonMsgResult{}
+	res.Code = SUCCESS_CODE
+	res.Message = SUCCESS_MSG
+	res.Data = data
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		r.Ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	etag := weakETag(body)
+	r.Ctx.Header("ETag", etag)
+	// 响应内容因登录用户而异：只允许客户端私有缓存，且每次使用前都需要回源校验
+	r.Ctx.Header("Cache-Control", "private, no-cache")
+	r.Ctx.Header("Vary", "Authorization, Cookie")
+
+	if etagMatches(r.Ctx.GetHeader("If-None-Match"), etag) {
+		r.Ctx.Status(http.StatusNotModified)
+		r.Ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	r.Ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// weakETag 根据响应体计算弱 ETag（同样的 JSON 语义不保证字节级一致，因此使用弱校验）
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches 按 RFC 7232 的弱比较判断 If-None-Match 是否命中
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == target {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,15 @@ type JsonMsgResult struct {
 	Code    int
 	Message string
 	Data    interface{}
+	// Errors 参数校验失败时各字段的错误，其余情况不返回
+	Errors []FieldError `json:",omitempty"`
+}
+
+// FieldError 单个字段的校验错误，Field 为请求中的字段名
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`    // 未通过的校验规则，如 required、email
+	Message string `json:"message"` // 可直接展示的提示
 }
 type nilStruct struct{}
 
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Attribute span 的属性，属性名参考 OpenTelemetry 语义约定，如 http.request.method、db.system
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// otlpValue 转为 OTLP 的 AnyValue，64 位整数按 JSON 编码规则使用字符串
+func (a Attribute) otlpValue() otlpAnyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,235 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// 上报失败时最多读取的响应体长度
+const exportErrorBodyLimit = 1024
+
+// exporter 批量上报 span：攒够 batch 条或到达上报间隔时发送一次
+// 上报使用单独的 http.Client，不经过 Transport，避免上报请求本身产生 span
+type exporter struct {
+	url       string
+	headers   map[string]string
+	client    *http.Client
+	resource  otlpResource
+	batchSize int
+	interval  time.Duration
+
+	queue    chan *Span
+	dropped  atomic.Int64
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newExporter(cfg configs.TracingConfig) *exporter {
+	service := cfg.Service()
+	e := &exporter{
+		url:       strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/traces",
+		headers:   cfg.Headers,
+		client:    &http.Client{Timeout: cfg.Timeout()},
+		resource:  otlpResource{Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: &service}}}},
+		batchSize: cfg.Batch(),
+		interval:  cfg.FlushInterval(),
+		queue:     make(chan *Span, cfg.Queue()),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// enqueue 队列已满时丢弃，不阻塞请求
+func (e *exporter) enqueue(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			zlog.Warnf("export %d spans failed: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+			if dropped := e.dropped.Swap(0); dropped > 0 {
+				zlog.Warnf("span queue is full, dropped %d spans", dropped)
+			}
+		case <-e.stop:
+			// 上报队列中剩余的 span 后退出
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) >= e.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// shutdown 通知上报协程发送剩余的 span，ctx 到期后不再等待
+func (e *exporter) shutdown(ctx context.Context) {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		zlog.Warnf("flush spans timeout: %v", ctx.Err())
+	}
+}
+
+func (e *exporter) export(spans []*Span) error {
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   e.resource,
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "forge"}, Spans: toOTLPSpans(spans)}},
+	}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, exportErrorBodyLimit))
+		return fmt.Errorf("collector returned %d: %s", resp.StatusCode, msg)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func toOTLPSpans(spans []*Span) []otlpSpan {
+	result := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.sc.TraceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.SpanID[:]),
+			Name:              s.name,
+			Kind:              int(s.kind),
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: s.status, Message: s.message},
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		// 同名属性以后添加的为准
+		seen := make(map[string]int, len(s.attrs))
+		for _, attr := range s.attrs {
+			kv := otlpKeyValue{Key: attr.Key, Value: attr.otlpValue()}
+			if i, ok := seen[attr.Key]; ok {
+				span.Attributes[i] = kv
+				continue
+			}
+			seen[attr.Key] = len(span.Attributes)
+			span.Attributes = append(span.Attributes, kv)
+		}
+		s.mu.Unlock()
+		result = append(result, span)
+	}
+	return result
+}
+
+// OTLP/HTTP JSON 请求体，字段名与 opentelemetry-proto 的 JSON 映射一致
+// ref: https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context 请求头，格式：{version}-{trace-id}-{parent-id}-{trace-flags}
+// ref: https://www.w3.org/TR/trace-context/
+const traceparentHeader = "traceparent"
+
+// traceparent 的采样标记位
+const flagSampled = 0x01
+
+type remoteKey struct{}
+
+// Extract 取出上游请求头中的链路信息，之后在 ctx 上开始的 span 属于同一链路
+// 没有或格式不对时返回原 ctx
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if exp == nil {
+		return ctx
+	}
+	sc, ok := parseTraceparent(header.Get(traceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Inject 将 ctx 中当前 span 写入请求头，下游服务据此延续链路
+func Inject(ctx context.Context, header http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	flags := "00"
+	if span.sc.Sampled {
+		flags = "01"
+	}
+	header.Set(traceparentHeader, "00-"+hex.EncodeToString(span.sc.TraceID[:])+"-"+hex.EncodeToString(span.sc.SpanID[:])+"-"+flags)
+}
+
+func parseTraceparent(value string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	// 未知版本只要求前四段格式正确
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, false
+	}
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || len(traceID) != len(sc.TraceID) {
+		return sc, false
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil || len(spanID) != len(sc.SpanID) {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return sc, false
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&flagSampled != 0
+	return sc, sc.valid()
+}
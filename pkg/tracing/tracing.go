@@ -0,0 +1,189 @@
+// Package tracing OpenTelemetry 链路追踪
+// 按 W3C Trace Context 在 HTTP 请求头中传递 traceparent，span 以 OTLP/HTTP（JSON）格式批量上报，
+// 可以直接接入 OpenTelemetry Collector、Jaeger、Tempo 等后端
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+// SpanKind span 类型，取值与 OTLP 一致
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// statusError span 失败的状态，取值与 OTLP 一致
+const statusError = 2
+
+var (
+	// exp 未开启时为 nil，Start 返回 nil span，所有打点都是空操作
+	exp *exporter
+	// sampleRatio 新链路的采样比例
+	sampleRatio = 1.0
+)
+
+// Init 根据配置初始化上报，未开启时直接返回
+func Init(cfg configs.TracingConfig) {
+	if !cfg.Enabled {
+		zlog.Infof("opentelemetry tracing disabled")
+		return
+	}
+	if cfg.Endpoint == "" {
+		zlog.Warnf("tracing.endpoint is empty, opentelemetry tracing disabled")
+		return
+	}
+	sampleRatio = cfg.Ratio()
+	exp = newExporter(cfg)
+	zlog.Infof("opentelemetry tracing enabled, endpoint: %s, sample ratio: %.2f", cfg.Endpoint, sampleRatio)
+}
+
+// Enabled 是否开启链路追踪
+func Enabled() bool {
+	return exp != nil
+}
+
+// Shutdown 退出前上报剩余的 span
+func Shutdown(ctx context.Context) {
+	if exp == nil {
+		return
+	}
+	exp.shutdown(ctx)
+}
+
+// SpanContext 跨进程传递的链路信息
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+func (sc SpanContext) valid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Span 一次操作的耗时与属性，方法都可以在 nil 上调用
+type Span struct {
+	sc       SpanContext
+	parentID [8]byte
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu      sync.Mutex
+	end     time.Time
+	attrs   []Attribute
+	status  int
+	message string
+	ended   bool
+}
+
+type spanKey struct{}
+
+// Start 在 ctx 当前的链路下开始一个 span，返回带有该 span 的 ctx
+// ctx 中没有 span 时延续 Extract 取出的上游链路，都没有时开始新的链路并按比例采样
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	if exp == nil {
+		return ctx, nil
+	}
+	span := &Span{name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent, ok := parentContext(ctx); ok {
+		span.sc.TraceID = parent.TraceID
+		span.sc.Sampled = parent.Sampled
+		span.parentID = parent.SpanID
+	} else {
+		_, _ = rand.Read(span.sc.TraceID[:])
+		span.sc.Sampled = sampled(span.sc.TraceID)
+	}
+	_, _ = rand.Read(span.sc.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext ctx 中当前的 span，没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func parentContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc, true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok && sc.valid()
+}
+
+// sampled 按 trace id 决定是否采样，同一链路在各服务中的结果一致
+func sampled(traceID [16]byte) bool {
+	if sampleRatio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])>>11)/(1<<53) < sampleRatio
+}
+
+// TraceID 十六进制的 trace id，用于关联日志
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.sc.TraceID[:])
+}
+
+// SetAttributes 添加属性，同名属性以后添加的为准
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError 记录错误并将 span 标记为失败，err 为 nil 时不做处理
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetError(err.Error())
+}
+
+// SetError 将 span 标记为失败
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.status = statusError
+	s.message = message
+	s.mu.Unlock()
+}
+
+// End 结束 span，采样的 span 加入上报队列，重复调用只生效一次
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sc.Sampled && exp != nil {
+		exp.enqueue(s)
+	}
+}
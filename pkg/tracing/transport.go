@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+// Transport 包装外部调用的 RoundTripper：每次请求创建 client span，并在请求头中传递 traceparent
+// span 在收到响应头时结束，不包含读取响应体的耗时；不记录查询参数，避免签名等敏感信息进入链路
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if exp == nil {
+		return t.base.RoundTrip(req)
+	}
+	ctx, span := Start(req.Context(), "HTTP "+req.Method, SpanKindClient,
+		String("http.request.method", req.Method),
+		String("server.address", req.URL.Host),
+		String("url.path", req.URL.Path),
+	)
+	defer span.End()
+
+	// RoundTripper 不能修改调用方的请求，复制后再写入请求头
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(resp.Status)
+	}
+	return resp, nil
+}
@@ -1,6 +1,13 @@
 package email
 
-import _ "embed"
+import (
+	"embed"
+)
 
-//go:embed verification_code.html
-var VerificationCodeTemplate string
+// Templates 按语言分目录的邮件模板，目录结构为 {语言}/{模板名}.html
+//
+//go:embed zh en
+var Templates embed.FS
+
+//go:embed contact_reminder.html
+var ContactReminderTemplate string
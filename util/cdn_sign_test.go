@@ -0,0 +1,67 @@
+package util
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignCDNURL(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	const rawURL = "https://cdn.example.com/avatar/u1.png?sign=old"
+
+	t.Run("type-A", func(t *testing.T) {
+		signed, err := SignCDNURL(rawURL, "a", "key", ts, "sign", "t")
+		if err != nil {
+			t.Fatalf("SignCDNURL() error = %v", err)
+		}
+		// 同一链接同一时间戳的签名相同
+		again, err := SignCDNURL(rawURL, "a", "key", ts, "sign", "t")
+		if err != nil || again != signed {
+			t.Errorf("SignCDNURL() = %s, then %s, want identical", signed, again)
+		}
+		sign := mustQuery(t, signed).Get("sign")
+		parts := strings.Split(sign, "-")
+		if len(parts) != 4 || parts[0] != "1700000000" || parts[2] != "0" {
+			t.Fatalf("sign = %s, want timestamp-rand-0-md5", sign)
+		}
+		if want := md5Hex("/avatar/u1.png-1700000000-" + parts[1] + "-0-key"); parts[3] != want {
+			t.Errorf("sign hash = %s, want %s", parts[3], want)
+		}
+		// 时间戳或路径不同时 rand 不同
+		other, err := SignCDNURL("https://cdn.example.com/avatar/u2.png", "a", "key", ts, "sign", "t")
+		if err != nil {
+			t.Fatalf("SignCDNURL() error = %v", err)
+		}
+		if strings.Split(mustQuery(t, other).Get("sign"), "-")[1] == parts[1] {
+			t.Error("rand is the same for different paths")
+		}
+	})
+
+	t.Run("type-D", func(t *testing.T) {
+		signed, err := SignCDNURL(rawURL, "D", "key", ts, "sign", "t")
+		if err != nil {
+			t.Fatalf("SignCDNURL() error = %v", err)
+		}
+		q := mustQuery(t, signed)
+		if q.Get("t") != "1700000000" || q.Get("sign") != md5Hex("key/avatar/u1.png1700000000") {
+			t.Errorf("SignCDNURL() = %s", signed)
+		}
+	})
+
+	t.Run("不支持的鉴权方式", func(t *testing.T) {
+		if _, err := SignCDNURL(rawURL, "b", "key", ts, "sign", "t"); err == nil {
+			t.Error("SignCDNURL() error = nil, want unsupported sign type")
+		}
+	})
+}
+
+func mustQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parse %s: %v", rawURL, err)
+	}
+	return u.Query()
+}
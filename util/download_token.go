@@ -0,0 +1,55 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 下载令牌签名的用途前缀，避免与其他使用同一密钥的签名混用
+const downloadTokenPurpose = "cos_download:"
+
+// SignDownloadToken 生成转发下载令牌，格式：base64url(路径).过期时间戳.签名
+func SignDownloadToken(secret, resourcePath string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(resourcePath)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + downloadSignature(secret, payload)
+}
+
+// ParseDownloadToken 校验签名与过期时间并取出文件路径，密钥为空时一律无效
+func ParseDownloadToken(secret, token string, now time.Time) (string, bool) {
+	if secret == "" {
+		return "", false
+	}
+	index := strings.LastIndex(token, ".")
+	if index < 0 {
+		return "", false
+	}
+	payload, signature := token[:index], token[index+1:]
+	if !hmac.Equal([]byte(signature), []byte(downloadSignature(secret, payload))) {
+		return "", false
+	}
+
+	encodedPath, expires, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return "", false
+	}
+	resourcePath, err := base64.RawURLEncoding.DecodeString(encodedPath)
+	if err != nil || len(resourcePath) == 0 {
+		return "", false
+	}
+	return string(resourcePath), true
+}
+
+// downloadSignature HMAC-SHA256(secret, purpose+payload)
+func downloadSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(downloadTokenPurpose + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
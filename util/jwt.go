@@ -1,9 +1,11 @@
 package util
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
-	"unicode"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -15,6 +17,7 @@ var (
 	ErrPasswordTooShort    = errors.New("password is too short")
 	ErrPasswordTooWeak     = errors.New("password is too weak")
 	ErrPasswordTooLong     = errors.New("password is too long")
+	ErrPasswordTooCommon   = errors.New("password is too common")
 	ErrInvalidToken        = errors.New("invalid token")
 	ErrTokenExpired        = errors.New("token is expired")
 	ErrTokenEmpty          = errors.New("token is empty")
@@ -36,51 +39,6 @@ func HashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
-// ValidatePasswordStrength 验证密码强度
-// 密码要求：长度8-16，包含大小写字母、数字、特殊字符中的至少3种 常规要求
-func ValidatePasswordStrength(password string) error {
-	if len(password) < 8 {
-		return ErrPasswordTooShort
-	}
-	if len(password) > 16 {
-		return ErrPasswordTooLong
-	}
-
-	var hasUpper, hasLower, hasDigit, hasSpecial bool
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsDigit(char):
-			hasDigit = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-
-	// 至少包含3种类型
-	count := 0
-	if hasUpper {
-		count++
-	}
-	if hasLower {
-		count++
-	}
-	if hasDigit {
-		count++
-	}
-	if hasSpecial {
-		count++
-	}
-
-	if count < 3 {
-		return ErrPasswordTooWeak
-	}
-	return nil
-}
-
 // ComparePassword 校验明文密码与哈希是否匹配
 func ComparePassword(hash string, plain string) (bool, error) {
 	if hash == "" || plain == "" {
@@ -101,52 +59,186 @@ func ComparePassword(hash string, plain string) (bool, error) {
 // jwt  后续登录给到token
 // JWT工具类
 type JWTUtil struct {
-	secretKey   []byte //密钥
-	expireHours int    //过期时间
+	secretKey   []byte        //密钥
+	expireHours int           //过期时间
+	refreshTTL  time.Duration //刷新令牌有效期
+	issuer      string        //签发方，非空时签发并校验 iss
+	audience    string        //接收方，非空时签发并校验 aud
+	leeway      time.Duration //校验 exp/nbf/iat 时允许的时钟偏差
+}
+
+// 令牌类型，刷新令牌不能当作访问令牌使用，反之亦然
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeMagicLink = "magic_link" // 免密登录链接中的一次性令牌，只能用于换取登录令牌
+)
+
+// 刷新令牌默认有效期
+const defaultRefreshTTL = 7 * 24 * time.Hour
+
+// JWTClaimsOptions 签发与校验时使用的注册声明，
+// 不同环境/服务配置不同的 iss、aud，令牌不能跨环境重放
+type JWTClaimsOptions struct {
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+	RefreshTTL time.Duration // 刷新令牌有效期，0 表示使用默认的7天
 }
 
 // JWT声明
 type Claims struct {
-	UserID string `json:"user_id"` //用户唯一标识  解析token识别用户
+	UserID    string `json:"user_id"`              //用户唯一标识  解析token识别用户
+	TokenType string `json:"token_type,omitempty"` //令牌类型 access/refresh/magic_link，旧令牌没有该字段视为 access
+	SessionID string `json:"sid,omitempty"`        //登录会话ID，同一次登录签发及续签的令牌相同，旧令牌没有该字段
 	jwt.RegisteredClaims
 }
 
 // 创建JWT工具实例
-func NewJWTUtil(secretKey string, expireHours int) *JWTUtil {
+func NewJWTUtil(secretKey string, expireHours int, opts JWTClaimsOptions) *JWTUtil {
 	// 如果过期时间为0或负数，设置默认值为24小时
 	if expireHours <= 0 {
 		expireHours = 24
 	}
+	refreshTTL := opts.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTTL
+	}
 	return &JWTUtil{
 		secretKey:   []byte(secretKey),
 		expireHours: expireHours,
+		refreshTTL:  refreshTTL,
+		issuer:      opts.Issuer,
+		audience:    opts.Audience,
+		leeway:      opts.Leeway,
 	}
 }
 
-// GenerateToken 生成jwt令牌
-func (j *JWTUtil) GenerateToken(userID string) (string, error) {
+// TokenTTL 令牌有效期
+func (j *JWTUtil) TokenTTL() time.Duration {
+	return time.Duration(j.expireHours) * time.Hour
+}
+
+// RefreshTokenTTL 刷新令牌有效期
+func (j *JWTUtil) RefreshTokenTTL() time.Duration {
+	return j.refreshTTL
+}
+
+// GenerateToken 生成jwt访问令牌，sessionID 为所属的登录会话
+func (j *JWTUtil) GenerateToken(userID, sessionID string) (string, error) {
+	token, _, err := j.generate(userID, sessionID, TokenTypeAccess, j.TokenTTL())
+	return token, err
+}
+
+// GenerateRefreshToken 生成刷新令牌，返回令牌与声明（jti 用于服务端登记与吊销）
+func (j *JWTUtil) GenerateRefreshToken(userID, sessionID string) (string, *Claims, error) {
+	return j.generate(userID, sessionID, TokenTypeRefresh, j.refreshTTL)
+}
+
+// GenerateMagicLinkToken 生成免密登录链接使用的一次性令牌，jti 用于服务端保证只能使用一次
+func (j *JWTUtil) GenerateMagicLinkToken(userID string, ttl time.Duration) (string, *Claims, error) {
+	return j.generate(userID, "", TokenTypeMagicLink, ttl)
+}
+
+func (j *JWTUtil) generate(userID, sessionID, tokenType string, ttl time.Duration) (string, *Claims, error) {
 	if userID == "" {
-		return "", ErrUserIDEmpty
+		return "", nil, ErrUserIDEmpty
+	}
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
 	}
 
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(j.expireHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			Issuer:    j.issuer,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	signed, err := token.SignedString(j.secretKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// newTokenID 生成随机的令牌ID（jti）
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
-// ValidateToken 验证JWT令牌
+// ValidateToken 验证JWT访问令牌
+// 除签名和 exp 外，还会校验 iat、nbf，以及配置了的 iss、aud；刷新令牌不能用于访问接口
 func (j *JWTUtil) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != "" && claims.TokenType != TokenTypeAccess {
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidToken, claims.TokenType)
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken 验证刷新令牌
+func (j *JWTUtil) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh || claims.ID == "" {
+		return nil, fmt.Errorf("%w: not a refresh token", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// ValidateMagicLinkToken 验证免密登录令牌，是否已使用由调用方检查
+func (j *JWTUtil) ValidateMagicLinkToken(tokenString string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeMagicLink || claims.ID == "" {
+		return nil, fmt.Errorf("%w: not a magic link token", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func (j *JWTUtil) parse(tokenString string) (*Claims, error) {
 	if tokenString == "" {
 		return nil, ErrTokenEmpty
 	}
 
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(j.leeway),
+	}
+	if j.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(j.audience))
+	}
+
 	// 解析令牌
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// 验证签名方法
@@ -154,14 +246,15 @@ func (j *JWTUtil) ValidateToken(tokenString string) (*Claims, error) {
 			return nil, ErrInvalidSignMethod
 		}
 		return j.secretKey, nil
-	})
+	}, parserOpts...)
 
 	if err != nil {
 		// jwt库返回的过期错误特殊处理
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
 		}
-		return nil, err
+		// iss/aud 不匹配、尚未生效等统一视为无效令牌
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
 	// 获取声明
@@ -187,7 +280,7 @@ func (j *JWTUtil) RefreshToken(tokenString string) (string, error) {
 	}
 	if remainingTime < time.Hour {
 		// 小于1小时，重新生成
-		return j.GenerateToken(claims.UserID)
+		return j.GenerateToken(claims.UserID, claims.SessionID)
 	}
 
 	// 还有超过1小时才过期，不需要刷新
@@ -0,0 +1,156 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newTestJWTUtil(issuer, audience string) *JWTUtil {
+	return NewJWTUtil(testSecret, 1, JWTClaimsOptions{Issuer: issuer, Audience: audience})
+}
+
+// signTestClaims 用测试密钥签名任意声明，构造库函数不会签发的令牌
+func signTestClaims(t *testing.T, claims *Claims) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestValidateTokenRegisteredClaims(t *testing.T) {
+	j := newTestJWTUtil("forge", "forge-api")
+	token, err := j.GenerateToken("u1", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	claims, err := j.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.UserID != "u1" || claims.SessionID != "s1" || claims.Issuer != "forge" {
+		t.Errorf("ValidateToken() claims = %+v", claims)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "forge-api" {
+		t.Errorf("ValidateToken() audience = %v, want [forge-api]", claims.Audience)
+	}
+	if claims.IssuedAt == nil || claims.NotBefore == nil {
+		t.Error("ValidateToken() claims missing iat or nbf")
+	}
+}
+
+func TestValidateTokenRejectsOtherEnvironments(t *testing.T) {
+	token, err := newTestJWTUtil("forge-staging", "forge-api").GenerateToken("u1", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		j    *JWTUtil
+	}{
+		{name: "iss 不同", j: newTestJWTUtil("forge", "forge-api")},
+		{name: "aud 不同", j: newTestJWTUtil("forge-staging", "forge-admin")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.j.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestValidateTokenTimeClaims(t *testing.T) {
+	j := newTestJWTUtil("", "")
+	now := time.Now()
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		wantErr error
+	}{
+		{
+			name:    "已过期",
+			claims:  jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(-time.Minute))},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "缺少 exp",
+			claims:  jwt.RegisteredClaims{},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "尚未生效",
+			claims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				NotBefore: jwt.NewNumericDate(now.Add(10 * time.Minute)),
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "签发时间在未来",
+			claims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(now.Add(10 * time.Minute)),
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signTestClaims(t, &Claims{UserID: "u1", RegisteredClaims: tt.claims})
+			if _, err := j.ValidateToken(token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTokenLeeway(t *testing.T) {
+	j := NewJWTUtil(testSecret, 1, JWTClaimsOptions{Leeway: time.Minute})
+	token := signTestClaims(t, &Claims{UserID: "u1", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		NotBefore: jwt.NewNumericDate(time.Now().Add(10 * time.Second)),
+	}})
+	if _, err := j.ValidateToken(token); err != nil {
+		t.Errorf("ValidateToken() within leeway error = %v", err)
+	}
+}
+
+func TestValidateTokenType(t *testing.T) {
+	j := newTestJWTUtil("", "")
+	refresh, _, err := j.GenerateRefreshToken("u1", "s1")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	if _, err := j.ValidateToken(refresh); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(refresh) error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := j.ValidateRefreshToken(refresh); err != nil {
+		t.Errorf("ValidateRefreshToken() error = %v", err)
+	}
+
+	access, err := j.GenerateToken("u1", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := j.ValidateRefreshToken(access); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateRefreshToken(access) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateTokenWrongSecret(t *testing.T) {
+	token, err := NewJWTUtil("other-secret", 1, JWTClaimsOptions{}).GenerateToken("u1", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := newTestJWTUtil("", "").ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken() error = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// bcrypt 只使用密码的前 72 个字节，更长的部分不参与校验
+const maxBcryptPasswordLength = 72
+
+// PasswordPolicy 密码规则，启动时由配置初始化，未初始化时使用 DefaultPasswordPolicy
+type PasswordPolicy struct {
+	MinLength       int      // 最小长度（字节）
+	MaxLength       int      // 最大长度（字节），不能超过 72
+	MinClasses      int      // 大写字母、小写字母、数字、特殊字符中至少包含的种类数
+	RequireUpper    bool     // 必须包含大写字母
+	RequireLower    bool     // 必须包含小写字母
+	RequireDigit    bool     // 必须包含数字
+	RequireSpecial  bool     // 必须包含特殊字符
+	CommonPasswords []string // 额外禁止使用的常见密码，不区分大小写
+}
+
+// DefaultPasswordPolicy 默认规则：长度8-16，包含大小写字母、数字、特殊字符中的至少3种
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:  8,
+		MaxLength:  16,
+		MinClasses: 3,
+	}
+}
+
+// 内置的常见密码，均满足默认的字符种类要求但极易被猜中
+var builtinCommonPasswords = []string{
+	"password1!", "password@1", "password123!", "p@ssw0rd", "p@ssword1", "passw0rd!",
+	"p@ssw0rd1", "p@ssw0rd123", "qwerty123!", "qwerty@123", "qwer1234!", "1qaz@wsx",
+	"1qaz!qaz", "1q2w3e4r!", "admin@123", "admin123!", "admin@1234", "abc@1234",
+	"abc123!@#", "aa123456!", "welcome1!", "welcome@123", "iloveyou1!", "test@1234",
+	"root@123", "changeme1!", "letmein1!", "zaq1@wsx", "asdf1234!", "a1234567!",
+}
+
+var (
+	passwordPolicy  = DefaultPasswordPolicy()
+	commonPasswords = buildCommonPasswords(nil)
+)
+
+// InitPasswordPolicy 设置密码规则，需在程序启动时调用
+func InitPasswordPolicy(policy PasswordPolicy) error {
+	if policy.MinLength <= 0 || policy.MaxLength < policy.MinLength {
+		return fmt.Errorf("密码长度范围无效: %d-%d", policy.MinLength, policy.MaxLength)
+	}
+	if policy.MaxLength > maxBcryptPasswordLength {
+		return fmt.Errorf("密码最大长度不能超过 %d", maxBcryptPasswordLength)
+	}
+	if policy.MinClasses < 0 || policy.MinClasses > 4 {
+		return fmt.Errorf("密码字符种类数必须在0-4之间: %d", policy.MinClasses)
+	}
+
+	passwordPolicy = policy
+	commonPasswords = buildCommonPasswords(policy.CommonPasswords)
+	return nil
+}
+
+// CurrentPasswordPolicy 当前生效的密码规则
+func CurrentPasswordPolicy() PasswordPolicy {
+	return passwordPolicy
+}
+
+func buildCommonPasswords(extra []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(builtinCommonPasswords)+len(extra))
+	for _, list := range [][]string{builtinCommonPasswords, extra} {
+		for _, password := range list {
+			if password = strings.TrimSpace(password); password != "" {
+				set[strings.ToLower(password)] = struct{}{}
+			}
+		}
+	}
+	return set
+}
+
+// ValidatePasswordStrength 按当前密码规则验证密码强度
+func ValidatePasswordStrength(password string) error {
+	policy := passwordPolicy
+	if len(password) < policy.MinLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > policy.MaxLength {
+		return ErrPasswordTooLong
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+
+	if (policy.RequireUpper && !hasUpper) || (policy.RequireLower && !hasLower) ||
+		(policy.RequireDigit && !hasDigit) || (policy.RequireSpecial && !hasSpecial) {
+		return ErrPasswordTooWeak
+	}
+
+	count := 0
+	for _, has := range []bool{hasUpper, hasLower, hasDigit, hasSpecial} {
+		if has {
+			count++
+		}
+	}
+	if count < policy.MinClasses {
+		return ErrPasswordTooWeak
+	}
+
+	if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+		return ErrPasswordTooCommon
+	}
+	return nil
+}
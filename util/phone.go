@@ -0,0 +1,70 @@
+package util
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultPhoneCountryCode 未带国际区号的手机号视为中国大陆号码
+const DefaultPhoneCountryCode = "86"
+
+// ErrPhoneInvalid 手机号或国际区号格式不正确
+var ErrPhoneInvalid = errors.New("phone number is invalid")
+
+var (
+	// 中国大陆手机号：11 位，1 开头，第二位 3-9
+	mainlandPhoneRegex = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	// E.164：+ 加 1-3 位国家码和用户号码，总位数不超过 15 位
+	e164PhoneRegex  = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// WithCountryCode 将国际区号与本地号码拼接为 +区号号码
+// 区号为空或号码已带 + 时原样返回；本地号码的长途前缀 0 会被去掉（如英国 07911... -> +447911...）
+// 只做拼接，不校验格式，区号或号码不合法时由 NormalizePhone 拒绝
+func WithCountryCode(phone, countryCode string) string {
+	phone = cleanPhone(phone)
+	countryCode = strings.TrimPrefix(strings.TrimSpace(countryCode), "+")
+	if countryCode == "" || strings.HasPrefix(phone, "+") {
+		return phone
+	}
+	return "+" + countryCode + strings.TrimLeft(phone, "0")
+}
+
+// NormalizePhone 校验手机号并统一为存储格式：
+//   - 中国大陆号码（不带区号或 +86）保存为 11 位号码，与已有数据保持一致
+//   - 其他国家和地区的号码保存为 E.164 格式，如 +447911123456
+func NormalizePhone(phone string) (string, error) {
+	phone = cleanPhone(phone)
+	if phone == "" {
+		return "", ErrPhoneInvalid
+	}
+
+	if !strings.HasPrefix(phone, "+") {
+		if !mainlandPhoneRegex.MatchString(phone) {
+			return "", ErrPhoneInvalid
+		}
+		return phone, nil
+	}
+
+	if national, ok := strings.CutPrefix(phone, "+"+DefaultPhoneCountryCode); ok {
+		if !mainlandPhoneRegex.MatchString(national) {
+			return "", ErrPhoneInvalid
+		}
+		return national, nil
+	}
+	if !e164PhoneRegex.MatchString(phone) {
+		return "", ErrPhoneInvalid
+	}
+	return phone, nil
+}
+
+// cleanPhone 去掉号码中的空格、横线、括号等分隔符，国际拨号前缀 00 转换为 +
+func cleanPhone(phone string) string {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if rest, ok := strings.CutPrefix(phone, "00"); ok {
+		return "+" + rest
+	}
+	return phone
+}
@@ -0,0 +1,52 @@
+package util
+
+import "strings"
+
+// 按顺序匹配，越具体的特征越靠前（Edge、微信的 UA 中同时包含 Chrome/Safari）
+var (
+	uaBrowsers = []struct{ token, name string }{
+		{"MicroMessenger", "WeChat"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android App"},
+		{"CFNetwork", "iOS App"},
+	}
+	uaSystems = []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// ParseDevice 从 User-Agent 中识别浏览器与操作系统，如 "Chrome / Windows"，无法识别时返回空
+// 只用于登录记录展示，不做精确的版本解析
+func ParseDevice(userAgent string) string {
+	var browser, system string
+	for _, b := range uaBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range uaSystems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " / " + system
+	case browser != "":
+		return browser
+	default:
+		return system
+	}
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"errors"
+	"regexp"
+)
+
+// ErrUserNameInvalid 用户名格式不正确
+var ErrUserNameInvalid = errors.New("username is invalid")
+
+// 用户名规则：以字母开头，4~20 位字母、数字或下划线
+// 不允许 @ 与纯数字，避免与邮箱、手机号混淆
+var userNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,19}$`)
+
+// ValidateUserName 校验用于登录的用户名是否符合规则
+func ValidateUserName(userName string) error {
+	if !userNamePattern.MatchString(userName) {
+		return ErrUserNameInvalid
+	}
+	return nil
+}